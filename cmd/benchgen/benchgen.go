@@ -0,0 +1,67 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package benchgen
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/pkg/bench"
+	"github.com/ynqa/wego/pkg/util/remote"
+)
+
+const (
+	defaultOutputFile = "bench.txt"
+)
+
+var (
+	force      bool
+	outputFile string
+)
+
+func New() *cobra.Command {
+	var opts bench.Options
+	cmd := &cobra.Command{
+		Use:   "benchgen",
+		Short: "Generate a synthetic Zipfian corpus for benchmarks",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return execute(opts)
+		},
+	}
+	cmd.Flags().StringVarP(&outputFile, "output", "o", defaultOutputFile, "output file path to save corpus, - for stdout")
+	cmd.Flags().BoolVar(&force, "force", false, "overwrite the existing output file")
+	addOptionsFlags(cmd, &opts)
+	return cmd
+}
+
+// addOptionsFlags binds opts to the flags of cmd, whose defaults are bench.DefaultOptions().
+func addOptionsFlags(cmd *cobra.Command, opts *bench.Options) {
+	def := bench.DefaultOptions()
+	cmd.Flags().Float64Var(&opts.Exponent, "exponent", def.Exponent, "exponent of Zipf's law, must be > 1")
+	cmd.Flags().IntVar(&opts.LineWords, "line-words", def.LineWords, "number of words per line")
+	cmd.Flags().Int64Var(&opts.Seed, "seed", def.Seed, "seed for random number generator")
+	cmd.Flags().IntVar(&opts.Tokens, "tokens", def.Tokens, "number of words in corpus")
+	cmd.Flags().IntVar(&opts.Vocab, "vocab", def.Vocab, "number of distinct words")
+}
+
+func execute(opts bench.Options) error {
+	if err := remote.CheckOverwrite(outputFile, force); err != nil {
+		return err
+	}
+	return remote.WriteAtomic(outputFile, func(w io.Writer) error {
+		return bench.Generate(w, opts)
+	})
+}
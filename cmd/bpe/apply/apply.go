@@ -0,0 +1,74 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apply
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/cmd/model/cmdutil"
+	"github.com/ynqa/wego/pkg/corpus/bpe"
+	"github.com/ynqa/wego/pkg/util/remote"
+)
+
+var (
+	force      bool
+	inputFile  string
+	mergesFile string
+	outputFile string
+	toLower    bool
+)
+
+func New() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "apply",
+		Short: "Encode the corpus into the subword units by the merges",
+		Example: "  wego bpe apply -i text8 -m merges.txt -o text8.bpe\n" +
+			"  wego bpe apply -i text8 -m merges.txt | wego word2vec -i - -o subword_vectors.txt",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return execute()
+		},
+	}
+	cmdutil.AddForceFlags(cmd, &force)
+	cmdutil.AddInputFlags(cmd, &inputFile)
+	cmd.Flags().StringVarP(&mergesFile, "merges", "m", "merges.txt", "file path for the merges which bpe learn saves")
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "-", "output file path to save the encoded corpus, - for stdout")
+	cmd.Flags().BoolVar(&toLower, "to-lower", false, "whether the words on corpus convert to lowercase or not")
+	return cmd
+}
+
+func execute() error {
+	if err := remote.CheckOverwrite(outputFile, force); err != nil {
+		return err
+	}
+	merges, err := remote.OpenFile(mergesFile)
+	if err != nil {
+		return err
+	}
+	defer merges.Close()
+	b, err := bpe.Load(merges)
+	if err != nil {
+		return err
+	}
+	input, err := remote.OpenFile(inputFile)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	return remote.WriteAtomic(outputFile, func(w io.Writer) error {
+		return b.EncodeText(w, input, toLower)
+	})
+}
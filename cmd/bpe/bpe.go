@@ -0,0 +1,39 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bpe
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/cmd/bpe/apply"
+	"github.com/ynqa/wego/cmd/bpe/learn"
+)
+
+func New() *cobra.Command {
+	learn := learn.New()
+	apply := apply.New()
+
+	cmd := &cobra.Command{
+		Use:   "bpe",
+		Short: "Learn and apply byte-pair encoding to train subword units",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return errors.Errorf("Set sub-command. One of %s|%s", learn.Name(), apply.Name())
+		},
+	}
+	cmd.AddCommand(learn)
+	cmd.AddCommand(apply)
+	return cmd
+}
@@ -0,0 +1,71 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package learn
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/cmd/model/cmdutil"
+	"github.com/ynqa/wego/pkg/corpus/bpe"
+	"github.com/ynqa/wego/pkg/util/remote"
+)
+
+var (
+	force      bool
+	inputFile  string
+	outputFile string
+	size       int
+	toLower    bool
+)
+
+func New() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "learn",
+		Short:   "Learn the merges of byte-pair encoding",
+		Example: "  wego bpe learn -i text8 -o merges.txt --size 32000",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return execute()
+		},
+	}
+	cmdutil.AddForceFlags(cmd, &force)
+	cmdutil.AddInputFlags(cmd, &inputFile)
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "merges.txt", "output file path to save the merges, - for stdout")
+	cmd.Flags().IntVar(&size, "size", 32000, "target number of the units, i.e. the characters and the merges")
+	cmd.Flags().BoolVar(&toLower, "to-lower", false, "whether the words on corpus convert to lowercase or not")
+	return cmd
+}
+
+func execute() error {
+	if size <= 0 {
+		return errors.Errorf("size must be > 0, got %d", size)
+	} else if err := remote.CheckOverwrite(outputFile, force); err != nil {
+		return err
+	}
+	input, err := remote.OpenFile(inputFile)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	b, err := bpe.Learn(input, size, toLower)
+	if err != nil {
+		return err
+	}
+	return remote.WriteAtomic(outputFile, func(w io.Writer) error {
+		return b.Write(w)
+	})
+}
@@ -0,0 +1,89 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package combine
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/util/remote"
+)
+
+const (
+	defaultOutputFile = "combined_vectors.txt"
+	defaultMethod     = embedding.Concat
+	defaultDim        = 300
+)
+
+var (
+	force      bool
+	inputFiles []string
+	outputFile string
+	method     string
+	dim        int
+)
+
+func New() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "combine",
+		Short: "Combine word vectors of several models into meta-embeddings",
+		Example: "  wego combine --inputs glove.txt,word2vec.txt -o combined.txt\n" +
+			"  wego combine --method svd --dim 300 --inputs glove.txt,word2vec.txt -o combined.txt",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return execute()
+		},
+	}
+	cmd.Flags().StringSliceVar(&inputFiles, "inputs", nil, "comma-separated input file paths for trained word vectors")
+	cmd.Flags().StringVarP(&outputFile, "output", "o", defaultOutputFile, "output file path to save word vectors, - for stdout")
+	cmd.Flags().BoolVar(&force, "force", false, "overwrite the existing output file")
+	cmd.Flags().StringVar(&method, "method", defaultMethod, fmt.Sprintf("method to combine the unit vectors. One of %s|%s|%s", embedding.Concat, embedding.Avg, embedding.SVD))
+	cmd.Flags().IntVarP(&dim, "dim", "d", defaultDim, "dimension to reduce the concatenated vectors for svd")
+	return cmd
+}
+
+func execute() error {
+	if len(inputFiles) < 2 {
+		return errors.Errorf("Input at least two files %v", inputFiles)
+	}
+	switch method {
+	case embedding.Concat, embedding.Avg, embedding.SVD:
+	default:
+		return embedding.InvalidCombineMethodError(method)
+	}
+	if err := remote.CheckOverwrite(outputFile, force); err != nil {
+		return err
+	}
+	aligned, err := embedding.AlignFiles(inputFiles...)
+	if err != nil {
+		return err
+	}
+	for s, missing := range aligned.Missing {
+		if len(missing) > 0 {
+			fmt.Fprintf(os.Stderr, "%d words are missing in %s\n", len(missing), inputFiles[s])
+		}
+	}
+	embs, err := embedding.Combine(aligned, method, dim)
+	if err != nil {
+		return err
+	}
+	return remote.WriteAtomic(outputFile, func(w io.Writer) error {
+		return embedding.Save(w, embs)
+	})
+}
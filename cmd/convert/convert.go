@@ -0,0 +1,267 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convert
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/cmd/query/cmdutil"
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/annoy"
+	"github.com/ynqa/wego/pkg/embedding/arrow"
+	"github.com/ynqa/wego/pkg/embedding/faiss"
+	"github.com/ynqa/wego/pkg/embedding/onnx"
+	"github.com/ynqa/wego/pkg/embedding/safetensors"
+	"github.com/ynqa/wego/pkg/embedding/sqlite"
+	"github.com/ynqa/wego/pkg/util/fileutil"
+	"github.com/ynqa/wego/pkg/util/remote"
+)
+
+type Format = string
+
+const (
+	Text        Format = "text"
+	ONNX        Format = "onnx"
+	Safetensors Format = "safetensors"
+	Arrow       Format = "arrow"
+	SQLite      Format = "sqlite"
+	Annoy       Format = "annoy"
+	FAISS       Format = "faiss"
+)
+
+const (
+	defaultOutputFile = "word_vectors.onnx"
+	defaultFrom       = Text
+	defaultTo         = ONNX
+	defaultMetric     = faiss.Cosine
+)
+
+var (
+	force      bool
+	inputFile  string
+	outputFile string
+	vocabFile  string
+	from       Format
+	to         Format
+	annoyOpts  = annoy.DefaultOptions()
+	metric     faiss.Metric
+)
+
+func New() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "convert",
+		Short:   "Convert word vectors into other formats",
+		Example: "  wego convert -i example/word_vectors.txt -o word_vectors.onnx --to onnx",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return execute()
+		},
+	}
+	cmdutil.AddInputFlags(cmd, &inputFile)
+	cmd.Flags().StringVarP(&outputFile, "output", "o", defaultOutputFile, "output file path to save the converted word vectors, - for stdout")
+	cmd.Flags().BoolVar(&force, "force", false, "overwrite the existing output files")
+	cmd.Flags().StringVar(&from, "from", defaultFrom, "format to convert from. One of: "+strings.Join(fromFormats(), "|"))
+	cmd.Flags().StringVar(&to, "to", defaultTo, "format to convert into. One of: "+strings.Join(toFormats(), "|")+" (arrow is the IPC stream)")
+	cmd.Flags().StringVar(&vocabFile, "vocab", "", "output file path to save the words in the order of rows for onnx, safetensors, annoy and faiss (default output path + .vocab or .vocab.json)")
+	addOptionsFlags(cmd, &annoyOpts)
+	cmd.Flags().StringVar(&metric, "metric", defaultMetric, "metric of the faiss flat index. One of: "+strings.Join([]string{faiss.IP, faiss.L2, faiss.Cosine}, "|")+" (cosine is IndexFlatIP over the unit vectors)")
+	return cmd
+}
+
+// addOptionsFlags binds opts to the flags of cmd, whose defaults are annoy.DefaultOptions().
+func addOptionsFlags(cmd *cobra.Command, opts *annoy.Options) {
+	def := annoy.DefaultOptions()
+	cmd.Flags().IntVar(&opts.Goroutines, "goroutines", def.Goroutines, "number of goroutine to build the annoy index")
+	cmd.Flags().Int64Var(&opts.Seed, "seed", def.Seed, "seed to build the annoy index")
+	cmd.Flags().IntVar(&opts.Trees, "trees", def.Trees, "number of trees to build the annoy index")
+	cmd.Flags().BoolVar(&opts.Verbose, "verbose", def.Verbose, "verbose mode")
+}
+
+// vocabPath is the adjacent file of the vocabulary, e.g. vectors.vocab.json for vectors.safetensors.
+func vocabPath(path string, format Format) string {
+	if format == Safetensors {
+		return strings.TrimSuffix(path, ".safetensors") + ".vocab.json"
+	}
+	return path + ".vocab"
+}
+
+// fromFormats returns the formats of --from, where sqlite is only in the build linking its driver.
+func fromFormats() []Format {
+	res := []Format{Text, Safetensors}
+	if sqlite.Available() {
+		res = append(res, SQLite)
+	}
+	return append(res, Annoy)
+}
+
+// toFormats returns the formats of --to, where sqlite is only in the build linking its driver.
+func toFormats() []Format {
+	res := []Format{Text, ONNX, Safetensors, Arrow}
+	if sqlite.Available() {
+		res = append(res, SQLite)
+	}
+	return append(res, Annoy, FAISS)
+}
+
+func contains(formats []Format, format Format) bool {
+	for _, f := range formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+func execute() error {
+	if (from == SQLite || to == SQLite) && !sqlite.Available() {
+		return errors.New("sqlite is not linked into this build, build wego with cgo and -tags sqlite")
+	}
+	if !contains(fromFormats(), from) {
+		return errors.Errorf("invalid format: %s not in %s", from, strings.Join(fromFormats(), "|"))
+	}
+	if to == "parquet" {
+		return errors.Errorf("parquet is not supported, write the stream by --to %s and convert it by pyarrow.parquet.write_table", Arrow)
+	}
+	if !contains(toFormats(), to) {
+		return errors.Errorf("invalid format: %s not in %s", to, strings.Join(toFormats(), "|"))
+	}
+	if to == FAISS && metric != faiss.IP && metric != faiss.L2 && metric != faiss.Cosine {
+		return errors.Errorf("invalid metric: %s not in %s|%s|%s", metric, faiss.IP, faiss.L2, faiss.Cosine)
+	}
+	local := func(path string) bool {
+		return path != fileutil.Stdio && !remote.IsRemote(path)
+	}
+	if (from == SQLite && !local(inputFile)) || (to == SQLite && !local(outputFile)) {
+		return errors.New("sqlite requires the local file")
+	}
+	if (from == Safetensors || from == Annoy) && inputFile == fileutil.Stdio {
+		return errors.Errorf("%s can't be read from stdin as the vocabulary is in the adjacent file", from)
+	}
+	outputs := []string{outputFile}
+	if to == ONNX || to == Safetensors || to == Annoy || to == FAISS {
+		if vocabFile == "" && outputFile == fileutil.Stdio {
+			return errors.Errorf("set --vocab to write %s into stdout", to)
+		}
+		if vocabFile == "" {
+			vocabFile = vocabPath(outputFile, to)
+		}
+		outputs = append(outputs, vocabFile)
+	}
+	for _, path := range outputs {
+		if err := remote.CheckOverwrite(path, force); err != nil {
+			return err
+		}
+	}
+
+	embs, err := load()
+	if err != nil {
+		return err
+	}
+	switch to {
+	case Text:
+		return remote.WriteAtomic(outputFile, func(w io.Writer) error {
+			return embedding.Save(w, embs)
+		})
+	case ONNX, Annoy, FAISS:
+		if err := remote.WriteAtomic(outputFile, func(w io.Writer) error {
+			switch to {
+			case Annoy:
+				return annoy.Write(w, embs, annoyOpts)
+			case FAISS:
+				return faiss.Write(w, embs, metric)
+			default:
+				return onnx.Write(w, embs)
+			}
+		}); err != nil {
+			return err
+		}
+		return remote.WriteAtomic(vocabFile, func(w io.Writer) error {
+			return onnx.WriteVocab(w, embs)
+		})
+	case Arrow:
+		return remote.WriteAtomic(outputFile, func(w io.Writer) error {
+			return arrow.Write(w, embs)
+		})
+	case SQLite:
+		return saveSQLite(outputFile, embs)
+	default:
+		// the vocabulary is written into the temporary buffer not to leave it without the tensors.
+		var vocab strings.Builder
+		if err := remote.WriteAtomic(outputFile, func(w io.Writer) error {
+			return safetensors.Write(w, &vocab, embs)
+		}); err != nil {
+			return err
+		}
+		return remote.WriteAtomic(vocabFile, func(w io.Writer) error {
+			_, err := io.WriteString(w, vocab.String())
+			return err
+		})
+	}
+}
+
+// saveSQLite creates the database in the temporary file, which replaces path on success.
+func saveSQLite(path string, embs embedding.Embeddings) error {
+	tmp := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err := os.Remove(tmp); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := sqlite.Save(tmp, embs); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func load() (embedding.Embeddings, error) {
+	if from == SQLite {
+		store, err := sqlite.Open(inputFile)
+		if err != nil {
+			return nil, err
+		}
+		defer store.Close()
+		var embs embedding.Embeddings
+		err = store.Each(func(emb embedding.Embedding) error {
+			embs = append(embs, emb)
+			return nil
+		})
+		return embs, err
+	}
+	input, err := remote.OpenFile(inputFile)
+	if err != nil {
+		return nil, err
+	}
+	defer input.Close()
+	if from == Text {
+		return embedding.Load(input)
+	}
+	vocab, err := remote.OpenFile(vocabPath(inputFile, from))
+	if err != nil {
+		return nil, err
+	}
+	defer vocab.Close()
+	if from == Annoy {
+		words, err := fileutil.LoadWords(vocab)
+		if err != nil {
+			return nil, err
+		}
+		return annoy.Read(input, words)
+	}
+	return safetensors.Read(input, vocab)
+}
@@ -0,0 +1,150 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cooc
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/cmd/model/cmdutil"
+	"github.com/ynqa/wego/pkg/corpus"
+	co "github.com/ynqa/wego/pkg/corpus/cooccurrence"
+	"github.com/ynqa/wego/pkg/corpus/counts"
+	"github.com/ynqa/wego/pkg/corpus/cpsutil"
+	"github.com/ynqa/wego/pkg/corpus/fs"
+	"github.com/ynqa/wego/pkg/corpus/memory"
+	"github.com/ynqa/wego/pkg/util/remote"
+	"github.com/ynqa/wego/pkg/util/verbose"
+)
+
+const (
+	defaultOutputFile = "cooccurrence.mtx"
+	defaultVocabFile  = "vocab.txt"
+	defaultFormat     = counts.MatrixMarket
+	defaultCountType  = co.Increment
+	defaultWindow     = 5
+	defaultLogBatch   = 100000
+)
+
+var (
+	countType   string
+	docInMemory bool
+	force       bool
+	format      string
+	inputFile   string
+	logBatch    int
+	maxVocab    int
+	mergeCase   bool
+	outputFile  string
+	symmetric   bool
+	toLower     bool
+	verboseMode bool
+	vocabFile   string
+	window      int
+)
+
+func New() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cooc",
+		Short: "Count the co-occurrences of the corpus and export them as the sparse matrix",
+		Example: "  wego cooc -i text8 -o cooccurrence.mtx --vocab vocab.txt\n" +
+			"  wego cooc -i text8 --format bin -o cooccurrence.bin.gz --vocab vocab.txt",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return execute(cmdutil.Progress(cmd, outputFile))
+		},
+	}
+	cmdutil.AddForceFlags(cmd, &force)
+	cmdutil.AddInputFlags(cmd, &inputFile)
+	cmd.Flags().StringVarP(&outputFile, "output", "o", defaultOutputFile, "output file path to save the counts, which is compressed by gzip if it ends with .gz, - for stdout")
+	cmd.Flags().StringVar(&vocabFile, "vocab", defaultVocabFile, "output file path to save the lines of word and count, whose lines are the 1-based ids of the counts")
+	cmd.Flags().StringVar(&format, "format", defaultFormat, fmt.Sprintf("format of the counts, %s is the lines of word, context and count, %s is cooccurrence.bin of GloVe and %s is MatrixMarket. One of %s|%s|%s", counts.Text, counts.Binary, counts.MatrixMarket, counts.Text, counts.Binary, counts.MatrixMarket))
+	cmd.Flags().StringVar(&countType, "cnt", defaultCountType, fmt.Sprintf("count type for co-occurrence words, %s weights by 1/distance and %s by (window-distance+1)/window. One of %s|%s|%s", co.Proximity, co.Linear, co.Increment, co.Proximity, co.Linear))
+	cmd.Flags().BoolVar(&docInMemory, "in-memory", false, "whether to store the doc in memory")
+	cmd.Flags().IntVar(&logBatch, "log-batch", defaultLogBatch, "batch size to log for counting words")
+	cmd.Flags().IntVar(&maxVocab, "max-vocab", 0, "upper limit of the vocabulary size which keeps the most frequent words (0 means unlimited)")
+	cmd.Flags().BoolVar(&mergeCase, "merge-case", false, "whether to merge the case variants of words into the most frequent surface form or not")
+	cmd.Flags().BoolVar(&symmetric, "symmetric", true, "whether to count both left and right contexts, or only left contexts")
+	cmd.Flags().BoolVar(&toLower, "to-lower", false, "whether the words on corpus convert to lowercase or not")
+	cmd.Flags().BoolVar(&verboseMode, "verbose", false, "verbose mode")
+	cmd.Flags().IntVarP(&window, "window", "w", defaultWindow, "context window size")
+	return cmd
+}
+
+func execute(progress io.Writer) error {
+	if !counts.ValidFormat(format) {
+		return counts.InvalidFormatError(format)
+	} else if window < 1 {
+		return errors.Errorf("window must be >= 1, got %d", window)
+	} else if logBatch <= 0 {
+		return errors.Errorf("log-batch must be > 0, got %d", logBatch)
+	} else if toLower && mergeCase {
+		return errors.New("to-lower and merge-case are exclusive, merge-case keeps the surface forms")
+	}
+	for _, path := range []string{outputFile, vocabFile} {
+		if err := remote.CheckOverwrite(path, force); err != nil {
+			return err
+		}
+	}
+	input, err := remote.OpenFile(inputFile)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	rs, cleanup, err := cpsutil.ReadSeeker(input)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	var c corpus.Corpus
+	if docInMemory {
+		c = memory.New(rs, toLower, mergeCase, -1, 0, maxVocab, 0)
+	} else {
+		c = fs.New(rs, toLower, mergeCase, -1, 0, maxVocab, 0)
+	}
+	if err := c.Load(&corpus.WithCooccurrence{
+		CountType:  countType,
+		Window:     window,
+		Asymmetric: !symmetric,
+	}, verbose.NewWriter(verboseMode, progress), logBatch); err != nil {
+		return err
+	}
+
+	if err := remote.WriteAtomic(outputFile, func(w io.Writer) error {
+		if !strings.HasSuffix(outputFile, ".gz") {
+			return counts.Write(w, format, c.Dictionary(), c.Cooccurrence())
+		}
+		zw := gzip.NewWriter(w)
+		if err := counts.Write(zw, format, c.Dictionary(), c.Cooccurrence()); err != nil {
+			return err
+		}
+		return zw.Close()
+	}); err != nil {
+		return err
+	}
+	if err := remote.WriteAtomic(vocabFile, func(w io.Writer) error {
+		return counts.WriteVocab(w, c.Dictionary())
+	}); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "%d words and %d cells\n", c.Dictionary().Len(), len(c.Cooccurrence().EncodedMatrix()))
+	return nil
+}
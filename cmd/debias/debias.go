@@ -0,0 +1,121 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debias
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/cmd/query/cmdutil"
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/debias"
+	"github.com/ynqa/wego/pkg/util/remote"
+)
+
+const (
+	defaultOutputFile = "debiased_vectors.txt"
+)
+
+var (
+	force            bool
+	inputFile        string
+	outputFile       string
+	definitionalFile string
+	equalizeFile     string
+	neutralFile      string
+)
+
+func New() *cobra.Command {
+	opts := debias.DefaultOptions()
+	cmd := &cobra.Command{
+		Use:     "debias",
+		Short:   "Hard debiasing to remove bias subspace from word vectors",
+		Example: "  wego debias -i example/word_vectors.txt -o debiased.txt --neutral professions.txt",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return execute(opts)
+		},
+	}
+	cmdutil.AddInputFlags(cmd, &inputFile)
+	cmd.Flags().StringVarP(&outputFile, "output", "o", defaultOutputFile, "output file path to save word vectors, - for stdout")
+	cmd.Flags().BoolVar(&force, "force", false, "overwrite the existing output file")
+	cmd.Flags().StringVar(&definitionalFile, "definitional", "", "file path for definitional pairs of 'word1 word2' to identify bias subspace (default gender pairs)")
+	cmd.Flags().StringVar(&equalizeFile, "equalize", "", "file path for pairs of 'word1 word2' to be equalized (default gender pairs)")
+	cmd.Flags().StringVar(&neutralFile, "neutral", "", "file path for words to be neutralized (default all words except the pairs)")
+	addOptionsFlags(cmd, &opts)
+	return cmd
+}
+
+// addOptionsFlags binds opts to the flags of cmd, whose defaults are debias.DefaultOptions().
+func addOptionsFlags(cmd *cobra.Command, opts *debias.Options) {
+	def := debias.DefaultOptions()
+	cmd.Flags().IntVar(&opts.Components, "components", def.Components, "dimension of bias subspace")
+}
+
+func execute(opts debias.Options) error {
+	if err := remote.CheckOverwrite(outputFile, force); err != nil {
+		return err
+	}
+
+	definitional, equalize := debias.GenderDefinitional(), debias.GenderEqualize()
+	var neutral []string
+	if err := loadFile(definitionalFile, func(r io.Reader) (err error) {
+		definitional, err = debias.LoadPairs(r)
+		return
+	}); err != nil {
+		return err
+	}
+	if err := loadFile(equalizeFile, func(r io.Reader) (err error) {
+		equalize, err = debias.LoadPairs(r)
+		return
+	}); err != nil {
+		return err
+	}
+	if err := loadFile(neutralFile, func(r io.Reader) (err error) {
+		neutral, err = debias.LoadWords(r)
+		return
+	}); err != nil {
+		return err
+	}
+
+	var embs embedding.Embeddings
+	if err := loadFile(inputFile, func(r io.Reader) (err error) {
+		embs, err = embedding.Load(r)
+		return
+	}); err != nil {
+		return err
+	}
+	res, err := debias.Debias(embs, definitional, equalize, neutral, opts)
+	if err != nil {
+		return err
+	}
+
+	return remote.WriteAtomic(outputFile, func(w io.Writer) error {
+		return embedding.Save(w, res)
+	})
+}
+
+// loadFile calls fn with the file if path is set.
+func loadFile(path string, fn func(io.Reader) error) error {
+	if path == "" {
+		return nil
+	}
+	f, err := remote.OpenFile(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return fn(f)
+}
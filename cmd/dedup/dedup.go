@@ -0,0 +1,83 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dedup
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/cmd/model/cmdutil"
+	"github.com/ynqa/wego/pkg/corpus/dedup"
+	"github.com/ynqa/wego/pkg/util/remote"
+)
+
+var (
+	force      bool
+	inputFile  string
+	outputFile string
+)
+
+func New() *cobra.Command {
+	opts := dedup.DefaultOptions()
+	cmd := &cobra.Command{
+		Use:   "dedup",
+		Short: "Drop the exact and near-duplicate lines of the corpus",
+		Example: "  wego dedup -i crawl.txt -o crawl.dedup.txt\n" +
+			"  wego dedup -i crawl.txt --hashes 0 | wego word2vec -i - -o word_vectors.txt",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return execute(opts)
+		},
+	}
+	cmdutil.AddForceFlags(cmd, &force)
+	cmdutil.AddInputFlags(cmd, &inputFile)
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "-", "output file path to save the deduplicated corpus, - for stdout")
+	addOptionsFlags(cmd, &opts)
+	return cmd
+}
+
+// addOptionsFlags binds opts to the flags of cmd, whose defaults are dedup.DefaultOptions().
+func addOptionsFlags(cmd *cobra.Command, opts *dedup.Options) {
+	def := dedup.DefaultOptions()
+	cmd.Flags().IntVar(&opts.Bands, "bands", def.Bands, "number of the LSH bands of the MinHash signature, the more detect the less similar lines")
+	cmd.Flags().IntVar(&opts.Hashes, "hashes", def.Hashes, "size of the MinHash signature to detect near-duplicates (0 means exact duplicates only)")
+	cmd.Flags().IntVar(&opts.MaxLines, "max-lines", def.MaxLines, "upper limit of the lines remembered at once, over which the oldest are forgotten to bound the memory (0 means unlimited)")
+	cmd.Flags().IntVar(&opts.Shingle, "shingle", def.Shingle, "number of the words in a shingle for MinHash")
+	cmd.Flags().BoolVar(&opts.ToLower, "to-lower", def.ToLower, "whether to compare the lines in lowercase or not")
+}
+
+func execute(opts dedup.Options) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	} else if err := remote.CheckOverwrite(outputFile, force); err != nil {
+		return err
+	}
+	input, err := remote.OpenFile(inputFile)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	var stats dedup.Stats
+	if err := remote.WriteAtomic(outputFile, func(w io.Writer) error {
+		stats, err = dedup.Write(w, input, opts)
+		return err
+	}); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "dropped %d exact and %d near-duplicate lines of %d lines\n", stats.Exact, stats.Near, stats.Lines)
+	return nil
+}
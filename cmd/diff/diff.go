@@ -0,0 +1,92 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/drift"
+	"github.com/ynqa/wego/pkg/search"
+	"github.com/ynqa/wego/pkg/util/remote"
+)
+
+var (
+	format search.Format
+)
+
+func New() *cobra.Command {
+	opts := drift.DefaultOptions()
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Report the drift of word vectors between two models",
+		Example: "  wego diff old.txt new.txt\n" +
+			"  wego diff old.txt new.txt --format json --max-drift 0.2 --min-jaccard 0.5",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return execute(args, opts)
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", search.Table, fmt.Sprintf("output format. One of %s|%s", search.Table, search.JSON))
+	addOptionsFlags(cmd, &opts)
+	return cmd
+}
+
+// addOptionsFlags binds opts to the flags of cmd, whose defaults are drift.DefaultOptions().
+func addOptionsFlags(cmd *cobra.Command, opts *drift.Options) {
+	def := drift.DefaultOptions()
+	cmd.Flags().IntVarP(&opts.K, "k", "k", def.K, "number of nearest neighbors to compare")
+	cmd.Flags().Float64Var(&opts.MaxDrift, "max-drift", def.MaxDrift, "fail if the mean drift is over it, 0 means disabled")
+	cmd.Flags().Float64Var(&opts.MinJaccard, "min-jaccard", def.MinJaccard, "fail if the mean Jaccard overlap of the neighbors is under it, 0 means disabled")
+	cmd.Flags().IntVar(&opts.Sample, "sample", def.Sample, "number of shared words sampled for the neighbor overlap, 0 uses all words")
+	cmd.Flags().Int64Var(&opts.Seed, "seed", def.Seed, "seed for random number generator")
+	cmd.Flags().IntVar(&opts.Top, "top", def.Top, "number of the most moved words to report")
+}
+
+func execute(args []string, opts drift.Options) error {
+	if len(args) != 2 {
+		return errors.Errorf("Input the old and new files for word vectors %v", args)
+	} else if format != search.Table && format != search.JSON {
+		return errors.Errorf("invalid format: %s not in %s|%s", format, search.Table, search.JSON)
+	}
+	old, err := load(args[0])
+	if err != nil {
+		return err
+	}
+	new, err := load(args[1])
+	if err != nil {
+		return err
+	}
+	report, err := drift.Compare(old, new, opts)
+	if err != nil {
+		return err
+	}
+	if err := report.Write(os.Stdout, format); err != nil {
+		return err
+	}
+	return report.Check(opts)
+}
+
+func load(path string) (embedding.Embeddings, error) {
+	r, err := remote.OpenFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return embedding.Load(r)
+}
@@ -0,0 +1,75 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package doesntmatch
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/cmd/query/cmdutil"
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/search"
+	"github.com/ynqa/wego/pkg/util/remote"
+)
+
+var (
+	format    search.Format
+	inputFile string
+)
+
+func New() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "doesnt-match",
+		Short:   "Find the word which doesn't match the others",
+		Example: "  wego doesnt-match -i example/word_vectors.txt breakfast cereal dinner lunch",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return execute(args)
+		},
+	}
+	cmdutil.AddInputFlags(cmd, &inputFile)
+	cmd.Flags().StringVar(&format, "format", search.Table, fmt.Sprintf("output format. One of %s|%s|%s|%s", search.Table, search.JSON, search.CSV, search.TSV))
+	return cmd
+}
+
+func execute(args []string) error {
+	if len(args) < 2 {
+		return errors.Errorf("Input at least 2 words %v", args)
+	}
+	if err := (search.Neighbors{}).Write(ioutil.Discard, format); err != nil {
+		return err
+	}
+	input, err := remote.OpenFile(inputFile)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	embs, err := embedding.Load(input)
+	if err != nil {
+		return err
+	}
+	searcher, err := search.New(embs...)
+	if err != nil {
+		return err
+	}
+	neighbors, err := searcher.DoesntMatch(args...)
+	if err != nil {
+		return err
+	}
+	return neighbors.Write(os.Stdout, format)
+}
@@ -0,0 +1,91 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analogy
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/cmd/query/cmdutil"
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/eval/analogy"
+	"github.com/ynqa/wego/pkg/search"
+	"github.com/ynqa/wego/pkg/util/remote"
+)
+
+var (
+	inputFile     string
+	questionsFile string
+	format        string
+)
+
+func New() *cobra.Command {
+	opts := analogy.DefaultOptions()
+	cmd := &cobra.Command{
+		Use:   "analogy",
+		Short: "Precision of the word analogies per category",
+		Example: "  wego eval analogy -i example/word_vectors.txt --questions questions-words.txt\n" +
+			"  wego eval analogy -i example/word_vectors.txt --questions domain.tsv --restrict 30000 --format json",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return execute(opts)
+		},
+	}
+	cmdutil.AddInputFlags(cmd, &inputFile)
+	cmd.Flags().StringVar(&questionsFile, "questions", "", "file path for the lines of 'a b c d' under the headers of ': category'")
+	cmd.Flags().StringVar(&format, "format", search.Table, fmt.Sprintf("output format. One of %s|%s", search.Table, search.JSON))
+	cmd.MarkFlagRequired("questions")
+	addOptionsFlags(cmd, &opts)
+	return cmd
+}
+
+// addOptionsFlags binds opts to the flags of cmd, whose defaults are analogy.DefaultOptions().
+func addOptionsFlags(cmd *cobra.Command, opts *analogy.Options) {
+	def := analogy.DefaultOptions()
+	cmd.Flags().IntVar(&opts.Goroutines, "goroutines", def.Goroutines, "number of goroutine")
+	cmd.Flags().IntVar(&opts.Restrict, "restrict", def.Restrict, "number of the first words in the vectors to answer from, 0 means all words")
+}
+
+func execute(opts analogy.Options) error {
+	// validate the format before loading the vectors.
+	if err := (analogy.Report{}).Write(ioutil.Discard, format); err != nil {
+		return err
+	}
+	f, err := remote.OpenFile(questionsFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	questions, err := analogy.Load(f)
+	if err != nil {
+		return err
+	}
+	input, err := remote.OpenFile(inputFile)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	embs, err := embedding.Load(input)
+	if err != nil {
+		return err
+	}
+	report, err := analogy.Evaluate(embs, questions, opts)
+	if err != nil {
+		return err
+	}
+	return report.Write(os.Stdout, format)
+}
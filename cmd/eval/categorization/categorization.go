@@ -0,0 +1,111 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package categorization
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/cmd/query/cmdutil"
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/eval/categorization"
+	"github.com/ynqa/wego/pkg/search"
+	"github.com/ynqa/wego/pkg/util/remote"
+)
+
+var (
+	inputFile    string
+	datasetFiles []string
+	format       string
+)
+
+func New() *cobra.Command {
+	opts := categorization.DefaultOptions()
+	cmd := &cobra.Command{
+		Use:     "categorization",
+		Short:   "Purity of the k-means clusters of the words against their categories",
+		Example: "  wego eval categorization -i example/word_vectors.txt --datasets ap.txt,bless.txt,bm.txt",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return execute(opts)
+		},
+	}
+	cmdutil.AddInputFlags(cmd, &inputFile)
+	cmd.Flags().StringSliceVar(&datasetFiles, "datasets", nil, "file paths for the lines of 'word category'")
+	cmd.Flags().StringVar(&format, "format", search.Table, fmt.Sprintf("output format. One of %s|%s", search.Table, search.JSON))
+	cmd.MarkFlagRequired("datasets")
+	addOptionsFlags(cmd, &opts)
+	return cmd
+}
+
+// addOptionsFlags binds opts to the flags of cmd, whose defaults are categorization.DefaultOptions().
+func addOptionsFlags(cmd *cobra.Command, opts *categorization.Options) {
+	def := categorization.DefaultOptions()
+	cmd.Flags().IntVar(&opts.Iter, "iter", def.Iter, "number of iterations of k-means")
+	cmd.Flags().IntVar(&opts.Restarts, "restarts", def.Restarts, "number of runs of k-means to keep the most compact clusters")
+	cmd.Flags().Int64Var(&opts.Seed, "seed", def.Seed, "seed for random number generator")
+}
+
+func execute(opts categorization.Options) error {
+	// validate the format before loading the vectors.
+	if err := (categorization.Results{}).Write(ioutil.Discard, format); err != nil {
+		return err
+	}
+	datasets := make([][]categorization.Item, len(datasetFiles))
+	for i, path := range datasetFiles {
+		items, err := loadItems(path)
+		if err != nil {
+			return err
+		}
+		datasets[i] = items
+	}
+	input, err := remote.OpenFile(inputFile)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	embs, err := embedding.Load(input)
+	if err != nil {
+		return err
+	}
+
+	var results categorization.Results
+	for i, items := range datasets {
+		result, err := categorization.Evaluate(embs, items, filepath.Base(datasetFiles[i]), opts)
+		if err != nil {
+			// the datasets may not be covered by the vocabulary of the domain corpus.
+			fmt.Fprintf(os.Stderr, "skip: %v\n", err)
+			continue
+		}
+		results = append(results, result)
+	}
+	if len(results) == 0 {
+		return errors.New("no datasets are covered by word vectors")
+	}
+	return results.Write(os.Stdout, format)
+}
+
+func loadItems(path string) ([]categorization.Item, error) {
+	f, err := remote.OpenFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return categorization.Load(f)
+}
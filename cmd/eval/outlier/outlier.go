@@ -0,0 +1,118 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outlier
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/cmd/query/cmdutil"
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/eval/outlier"
+	"github.com/ynqa/wego/pkg/search"
+	"github.com/ynqa/wego/pkg/util/remote"
+)
+
+var (
+	inputFile string
+	setFiles  []string
+	format    string
+)
+
+func New() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "outlier",
+		Short:   "Outlier detection by the compactness of the clusters of words",
+		Example: "  wego eval outlier -i example/word_vectors.txt --sets 8-8-8/",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return execute()
+		},
+	}
+	cmdutil.AddInputFlags(cmd, &inputFile)
+	cmd.Flags().StringSliceVar(&setFiles, "sets", nil, "file paths or directories of them for the words of the cluster, an empty line, and the outliers")
+	cmd.Flags().StringVar(&format, "format", search.Table, fmt.Sprintf("output format. One of %s|%s", search.Table, search.JSON))
+	cmd.MarkFlagRequired("sets")
+	return cmd
+}
+
+func execute() error {
+	// validate the format before loading the vectors.
+	if err := (outlier.Report{}).Write(ioutil.Discard, format); err != nil {
+		return err
+	}
+	var sets []outlier.Set
+	for _, path := range setFiles {
+		paths, err := expand(path)
+		if err != nil {
+			return err
+		}
+		for _, p := range paths {
+			set, err := loadSet(p)
+			if err != nil {
+				return err
+			}
+			sets = append(sets, set)
+		}
+	}
+	input, err := remote.OpenFile(inputFile)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	embs, err := embedding.Load(input)
+	if err != nil {
+		return err
+	}
+	report, err := outlier.Evaluate(embs, sets)
+	if err != nil {
+		return err
+	}
+	return report.Write(os.Stdout, format)
+}
+
+// expand returns the files in the directory by name, or the path itself.
+func expand(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil || !info.IsDir() {
+		return []string{path}, nil
+	}
+	infos, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, info := range infos {
+		if !info.IsDir() {
+			paths = append(paths, filepath.Join(path, info.Name()))
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func loadSet(path string) (outlier.Set, error) {
+	f, err := remote.OpenFile(path)
+	if err != nil {
+		return outlier.Set{}, err
+	}
+	defer f.Close()
+	name := filepath.Base(path)
+	return outlier.Load(f, name[:len(name)-len(filepath.Ext(name))])
+}
@@ -0,0 +1,87 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package qvec
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/cmd/query/cmdutil"
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/eval/qvec"
+	"github.com/ynqa/wego/pkg/search"
+	"github.com/ynqa/wego/pkg/util/remote"
+)
+
+const (
+	defaultTop = 10
+)
+
+var (
+	inputFile    string
+	featuresFile string
+	format       string
+	top          int
+)
+
+func New() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "qvec",
+		Short:   "QVEC and QVEC-CCA against the linguistic features of words",
+		Example: "  wego eval qvec -i example/word_vectors.txt --features semcor_noun_verb.supersenses.en",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return execute()
+		},
+	}
+	cmdutil.AddInputFlags(cmd, &inputFile)
+	cmd.Flags().StringVar(&featuresFile, "features", "", "file path for the lines of 'word feature:value ...' or the oracle files of QVEC")
+	cmd.Flags().StringVar(&format, "format", search.Table, fmt.Sprintf("output format. One of %s|%s", search.Table, search.JSON))
+	cmd.Flags().IntVar(&top, "top", defaultTop, "number of the dimensions of the best alignments to report")
+	cmd.MarkFlagRequired("features")
+	return cmd
+}
+
+func execute() error {
+	// validate the format before loading the vectors.
+	if err := (qvec.Report{}).Write(ioutil.Discard, format, top); err != nil {
+		return err
+	}
+	f, err := remote.OpenFile(featuresFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	features, err := qvec.Load(f)
+	if err != nil {
+		return err
+	}
+	input, err := remote.OpenFile(inputFile)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	embs, err := embedding.Load(input)
+	if err != nil {
+		return err
+	}
+	report, err := qvec.Evaluate(embs, features)
+	if err != nil {
+		return err
+	}
+	return report.Write(os.Stdout, format, top)
+}
@@ -0,0 +1,117 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stability
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/cmd/model/cmdutil"
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/eval/stability"
+	"github.com/ynqa/wego/pkg/model"
+	"github.com/ynqa/wego/pkg/model/modelutil/vector"
+	"github.com/ynqa/wego/pkg/search"
+	"github.com/ynqa/wego/pkg/util/remote"
+)
+
+var (
+	inputFile string
+	modelName string
+	flags     map[string]string
+	format    search.Format
+)
+
+func New() *cobra.Command {
+	opts := stability.DefaultOptions()
+	cmd := &cobra.Command{
+		Use:   "stability",
+		Short: "Neighbor stability of words over the models trained on bootstrap subsamples",
+		Example: "  wego eval stability -i example/input.txt --runs 5 --fraction 0.8\n" +
+			"  wego eval stability -i example/input.txt --model glove --flags dim=50,iter=5 --parallel 2 --format json",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return execute(opts)
+		},
+	}
+	cmdutil.AddInputFlags(cmd, &inputFile)
+	cmd.Flags().StringVar(&modelName, "model", "word2vec", "model to train. One of word2vec|glove|lexvec")
+	cmd.Flags().StringToStringVar(&flags, "flags", nil, "flags of the model sub-command, e.g. dim=100,min-count=1")
+	cmd.Flags().StringVar(&format, "format", search.Table, fmt.Sprintf("output format. One of %s|%s", search.Table, search.JSON))
+	addOptionsFlags(cmd, &opts)
+	return cmd
+}
+
+// addOptionsFlags binds opts to the flags of cmd, whose defaults are stability.DefaultOptions().
+func addOptionsFlags(cmd *cobra.Command, opts *stability.Options) {
+	def := stability.DefaultOptions()
+	cmd.Flags().IntVar(&opts.Chunk, "chunk", def.Chunk, "number of words of the documents to subsample, the longer lines are split")
+	cmd.Flags().Float64Var(&opts.Fraction, "fraction", def.Fraction, "fraction of the documents to subsample for each run")
+	cmd.Flags().IntVarP(&opts.K, "k", "k", def.K, "number of nearest neighbors to compare")
+	cmd.Flags().IntVar(&opts.Parallel, "parallel", def.Parallel, "number of runs trained at the same time")
+	cmd.Flags().IntVar(&opts.Runs, "runs", def.Runs, "number of models trained with the different seeds and subsamples")
+	cmd.Flags().IntVar(&opts.Sample, "sample", def.Sample, "number of words sampled for the stability, 0 uses all words")
+	cmd.Flags().Int64Var(&opts.Seed, "seed", def.Seed, "seed of the first run, which is incremented for the others")
+	cmd.Flags().Float64Var(&opts.Threshold, "threshold", def.Threshold, "stability under which the words and the frequency bands are flagged as unstable")
+	cmd.Flags().IntVar(&opts.Top, "top", def.Top, "number of the least stable words to report")
+}
+
+func execute(opts stability.Options) error {
+	if format != search.Table && format != search.JSON {
+		return errors.Errorf("invalid format: %s not in %s|%s", format, search.Table, search.JSON)
+	} else if _, ok := flags["seed"]; ok {
+		return errors.New("seed is set by --seed for each run, remove it from --flags")
+	}
+	// validate the model and the flags before training.
+	if _, err := cmdutil.NewModel(modelName, flags); err != nil {
+		return err
+	}
+	input, err := remote.OpenFile(inputFile)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	report, err := stability.Evaluate(context.Background(), input, train, opts)
+	if err != nil {
+		return err
+	}
+	return report.Write(os.Stdout, format)
+}
+
+func train(ctx context.Context, seed int64, corpus io.Reader) (embedding.Embeddings, error) {
+	runFlags := make(map[string]string, len(flags)+1)
+	for k, v := range flags {
+		runFlags[k] = v
+	}
+	runFlags["seed"] = fmt.Sprintf("%d", seed)
+	mod, err := cmdutil.NewModel(modelName, runFlags)
+	if err != nil {
+		return nil, err
+	}
+	defer model.Close(mod)
+	if err := mod.Train(ctx, corpus); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := mod.Save(&buf, vector.Single); err != nil {
+		return nil, err
+	}
+	return embedding.Load(&buf)
+}
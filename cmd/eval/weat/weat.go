@@ -0,0 +1,107 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weat
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/cmd/query/cmdutil"
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/eval/weat"
+	"github.com/ynqa/wego/pkg/util/remote"
+)
+
+var (
+	inputFile string
+	setFiles  []string
+	standard  bool
+)
+
+func New() *cobra.Command {
+	opts := weat.DefaultOptions()
+	cmd := &cobra.Command{
+		Use:     "weat",
+		Short:   "Word Embedding Association Test to audit social bias",
+		Example: "  wego eval weat -i example/word_vectors.txt --sets custom.txt",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return execute(opts)
+		},
+	}
+	cmdutil.AddInputFlags(cmd, &inputFile)
+	cmd.Flags().StringSliceVar(&setFiles, "sets", nil, "file paths for custom tests with the lines of '<X|Y|A|B>: word1 word2 ...'")
+	cmd.Flags().BoolVar(&standard, "standard", true, "whether to run the standard tests by Caliskan et al.")
+	addOptionsFlags(cmd, &opts)
+	return cmd
+}
+
+// addOptionsFlags binds opts to the flags of cmd, whose defaults are weat.DefaultOptions().
+func addOptionsFlags(cmd *cobra.Command, opts *weat.Options) {
+	def := weat.DefaultOptions()
+	cmd.Flags().IntVar(&opts.Permutations, "permutations", def.Permutations, "number of random partitions of X and Y for p-value")
+	cmd.Flags().Int64Var(&opts.Seed, "seed", def.Seed, "seed for random number generator")
+}
+
+func execute(opts weat.Options) error {
+	var tests []weat.Test
+	if standard {
+		tests = append(tests, weat.Standard()...)
+	}
+	for _, path := range setFiles {
+		test, err := loadTest(path)
+		if err != nil {
+			return err
+		}
+		tests = append(tests, test)
+	}
+
+	input, err := remote.OpenFile(inputFile)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	embs, err := embedding.Load(input)
+	if err != nil {
+		return err
+	}
+
+	results := make(weat.Results, 0, len(tests))
+	for _, test := range tests {
+		result, err := weat.Evaluate(embs, test, opts)
+		if err != nil {
+			// the tests may not be covered by the vocabulary of the domain corpus.
+			fmt.Printf("skip: %v\n", err)
+			continue
+		}
+		results = append(results, result)
+	}
+	if len(results) == 0 {
+		return errors.New("no tests are covered by word vectors")
+	}
+	results.Describe()
+	return nil
+}
+
+func loadTest(path string) (weat.Test, error) {
+	f, err := remote.OpenFile(path)
+	if err != nil {
+		return weat.Test{}, err
+	}
+	defer f.Close()
+	return weat.Load(f, filepath.Base(path))
+}
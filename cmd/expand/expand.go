@@ -0,0 +1,110 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expand
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/cmd/query/cmdutil"
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/search"
+	"github.com/ynqa/wego/pkg/util/remote"
+)
+
+// Lucene is the output format of the boosted query.
+const Lucene search.Format = "lucene"
+
+var (
+	addr      string
+	format    search.Format
+	inputFile string
+	maxK      int
+	minSim    float64
+	rank      int
+)
+
+func New() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "expand",
+		Short: "Expand a term into the weighted terms for search queries",
+		Example: "  wego expand -i example/word_vectors.txt --rank 5 --min-sim 0.6 --format lucene laptop\n" +
+			"  wego expand -i example/word_vectors.txt --serve :8080",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return execute(args)
+		},
+	}
+	cmdutil.AddInputFlags(cmd, &inputFile)
+	cmdutil.AddRankFlags(cmd, &rank)
+	cmd.Flags().Float64Var(&minSim, "min-sim", 0.5, "lower limit of the similarity of the expanded terms")
+	cmd.Flags().StringVar(&format, "format", search.Table, fmt.Sprintf("output format. One of %s|%s|%s", search.Table, search.JSON, Lucene))
+	cmd.Flags().StringVar(&addr, "serve", "", "address to serve the expansion on /expand over HTTP, e.g. :8080")
+	cmd.Flags().IntVar(&maxK, "max-k", search.DefaultMaxK, "largest k of the requests to --serve")
+	return cmd
+}
+
+func execute(args []string) error {
+	if addr == "" && len(args) != 1 {
+		return errors.Errorf("Input a single term %v", args)
+	} else if format != search.Table && format != search.JSON && format != Lucene {
+		return errors.Errorf("invalid format: %s not in %s|%s|%s", format, search.Table, search.JSON, Lucene)
+	}
+	input, err := remote.OpenFile(inputFile)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	embs, err := embedding.Load(input)
+	if err != nil {
+		return err
+	}
+	s, err := search.New(embs...)
+	if err != nil {
+		return err
+	}
+
+	if addr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/expand", &search.Expander{Searcher: s, K: rank, MinSim: minSim, MaxK: maxK})
+		fmt.Fprintf(os.Stderr, "serving on %s/expand\n", addr)
+		return http.ListenAndServe(addr, mux)
+	}
+	terms, err := s.Expand(args[0], rank, minSim)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case search.Table:
+		writer := tablewriter.NewWriter(os.Stdout)
+		writer.SetHeader([]string{"Term", "Weight"})
+		writer.SetBorder(false)
+		for _, t := range terms {
+			writer.Append([]string{t.Term, fmt.Sprintf("%f", t.Weight)})
+		}
+		writer.Render()
+		return nil
+	case search.JSON:
+		return json.NewEncoder(os.Stdout).Encode(terms)
+	default:
+		fmt.Println(terms.Lucene())
+		return nil
+	}
+}
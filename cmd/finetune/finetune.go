@@ -0,0 +1,116 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package finetune
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/cmd/query/cmdutil"
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/finetune"
+	"github.com/ynqa/wego/pkg/util/fileutil"
+	"github.com/ynqa/wego/pkg/util/remote"
+)
+
+const (
+	defaultOutputFile = "finetuned_vectors.txt"
+)
+
+var (
+	force      bool
+	freezeFile string
+	inputFile  string
+	outputFile string
+	pairsFile  string
+)
+
+func New() *cobra.Command {
+	opts := finetune.DefaultOptions()
+	cmd := &cobra.Command{
+		Use:     "finetune",
+		Short:   "Fine-tune word vectors on similar and dissimilar pairs",
+		Example: "  wego finetune -i example/word_vectors.txt -o finetuned.txt --pairs pairs.txt",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return execute(opts)
+		},
+	}
+	cmdutil.AddInputFlags(cmd, &inputFile)
+	cmd.Flags().StringVarP(&outputFile, "output", "o", defaultOutputFile, "output file path to save word vectors, - for stdout")
+	cmd.Flags().BoolVar(&force, "force", false, "overwrite the existing output file")
+	cmd.Flags().StringVar(&pairsFile, "pairs", "", "file path for the lines of 'word1 word2 similar|dissimilar'")
+	cmd.MarkFlagRequired("pairs")
+	cmd.Flags().StringVar(&freezeFile, "freeze-words", "", "file path for the words whose vectors are kept unchanged")
+	addOptionsFlags(cmd, &opts)
+	return cmd
+}
+
+// addOptionsFlags binds opts to the flags of cmd, whose defaults are finetune.DefaultOptions().
+func addOptionsFlags(cmd *cobra.Command, opts *finetune.Options) {
+	def := finetune.DefaultOptions()
+	cmd.Flags().Float64Var(&opts.Initlr, "initlr", def.Initlr, "learning rate")
+	cmd.Flags().IntVar(&opts.Iter, "iter", def.Iter, "number of iteration over the pairs")
+	cmd.Flags().Float64Var(&opts.Margin, "margin", def.Margin, "cosine similarity under which dissimilar pairs are not pushed apart")
+	cmd.Flags().Float64Var(&opts.Reg, "reg", def.Reg, "strength to keep the vectors close to the original ones")
+	cmd.Flags().Int64Var(&opts.Seed, "seed", def.Seed, "seed to shuffle the pairs")
+}
+
+func execute(opts finetune.Options) error {
+	if err := remote.CheckOverwrite(outputFile, force); err != nil {
+		return err
+	}
+
+	var pairs []finetune.Pair
+	if err := loadFile(pairsFile, func(r io.Reader) (err error) {
+		pairs, err = finetune.LoadPairs(r)
+		return
+	}); err != nil {
+		return err
+	}
+	if freezeFile != "" {
+		if err := loadFile(freezeFile, func(r io.Reader) error {
+			words, err := fileutil.LoadWords(r)
+			opts.Freeze = append(opts.Freeze, words...)
+			return err
+		}); err != nil {
+			return err
+		}
+	}
+	var embs embedding.Embeddings
+	if err := loadFile(inputFile, func(r io.Reader) (err error) {
+		embs, err = embedding.Load(r)
+		return
+	}); err != nil {
+		return err
+	}
+	res, err := finetune.Finetune(embs, pairs, opts)
+	if err != nil {
+		return err
+	}
+
+	return remote.WriteAtomic(outputFile, func(w io.Writer) error {
+		return embedding.Save(w, res)
+	})
+}
+
+func loadFile(path string, fn func(io.Reader) error) error {
+	f, err := remote.OpenFile(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return fn(f)
+}
@@ -0,0 +1,68 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inspect
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/inspect"
+	"github.com/ynqa/wego/pkg/util/remote"
+)
+
+func New() *cobra.Command {
+	opts := inspect.DefaultOptions()
+	cmd := &cobra.Command{
+		Use:     "inspect",
+		Short:   "Report the health of word vectors",
+		Example: "  wego inspect example/word_vectors.txt",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return execute(args, opts)
+		},
+	}
+	addOptionsFlags(cmd, &opts)
+	return cmd
+}
+
+// addOptionsFlags binds opts to the flags of cmd, whose defaults are inspect.DefaultOptions().
+func addOptionsFlags(cmd *cobra.Command, opts *inspect.Options) {
+	def := inspect.DefaultOptions()
+	cmd.Flags().Float64Var(&opts.Duplicate, "duplicate", def.Duplicate, "cosine similarity threshold to regard vectors as near-duplicate")
+	cmd.Flags().IntVarP(&opts.K, "k", "k", def.K, "number of nearest neighbors for hubness")
+	cmd.Flags().IntVar(&opts.Sample, "sample", def.Sample, "number of words sampled for near-duplicate, isotropy, and hubness, 0 uses all words")
+	cmd.Flags().Int64Var(&opts.Seed, "seed", def.Seed, "seed for random number generator")
+}
+
+func execute(args []string, opts inspect.Options) error {
+	if len(args) != 1 {
+		return errors.Errorf("Input a single file for word vectors %v", args)
+	}
+	input, err := remote.OpenFile(args[0])
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	embs, err := embedding.Load(input)
+	if err != nil {
+		return err
+	}
+	report, err := inspect.Inspect(embs, opts)
+	if err != nil {
+		return err
+	}
+	report.Describe()
+	return nil
+}
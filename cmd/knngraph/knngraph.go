@@ -0,0 +1,80 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package knngraph
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/cmd/query/cmdutil"
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/search/graph"
+	"github.com/ynqa/wego/pkg/util/remote"
+)
+
+const (
+	defaultOutputFile = "graph.txt"
+)
+
+var (
+	force      bool
+	inputFile  string
+	outputFile string
+)
+
+func New() *cobra.Command {
+	opts := graph.DefaultOptions()
+	cmd := &cobra.Command{
+		Use:     "knn-graph",
+		Short:   "Export the k-nearest neighbor graph over the vocabulary",
+		Example: "  wego knn-graph -i example/word_vectors.txt -o graph.graphml --k 10 --format graphml",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return execute(opts)
+		},
+	}
+	cmdutil.AddInputFlags(cmd, &inputFile)
+	cmd.Flags().StringVarP(&outputFile, "output", "o", defaultOutputFile, "output file path to save graph, - for stdout")
+	cmd.Flags().BoolVar(&force, "force", false, "overwrite the existing output file")
+	addOptionsFlags(cmd, &opts)
+	return cmd
+}
+
+// addOptionsFlags binds opts to the flags of cmd, whose defaults are graph.DefaultOptions().
+func addOptionsFlags(cmd *cobra.Command, opts *graph.Options) {
+	def := graph.DefaultOptions()
+	cmd.Flags().StringVar(&opts.Format, "format", def.Format, fmt.Sprintf("output format. One of %s|%s", graph.EdgeList, graph.GraphML))
+	cmd.Flags().IntVar(&opts.Goroutines, "goroutines", def.Goroutines, "number of goroutine")
+	cmd.Flags().IntVarP(&opts.K, "k", "k", def.K, "number of neighbors for each word")
+}
+
+func execute(opts graph.Options) error {
+	if err := remote.CheckOverwrite(outputFile, force); err != nil {
+		return err
+	}
+	input, err := remote.OpenFile(inputFile)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	embs, err := embedding.Load(input)
+	if err != nil {
+		return err
+	}
+	return remote.WriteAtomic(outputFile, func(w io.Writer) error {
+		return graph.Write(w, embs, opts)
+	})
+}
@@ -0,0 +1,100 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migrate
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/pkg/model/modelutil/matrix"
+)
+
+var (
+	dryRun bool
+)
+
+func New() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate PATH...",
+		Short: "Migrate the files of the matrices trained by the older wego into the current layout",
+		Example: "  wego migrate /data/wego-mat\n" +
+			"  wego migrate --dry-run /data/wego-mat/word.mat",
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return execute(args)
+		},
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "report the versions of the files without rewriting them")
+	return cmd
+}
+
+// matFiles returns path itself for the file, or the .mat files in the directory, e.g. of --matrix-backing mmap.
+func matFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+	infos, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var res []string
+	for _, info := range infos {
+		if !info.IsDir() && filepath.Ext(info.Name()) == ".mat" {
+			res = append(res, filepath.Join(path, info.Name()))
+		}
+	}
+	if len(res) == 0 {
+		return nil, errors.Errorf("no .mat files in %s", path)
+	}
+	return res, nil
+}
+
+func execute(paths []string) error {
+	for _, path := range paths {
+		files, err := matFiles(path)
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			if dryRun {
+				version, err := matrix.ReadVersion(file)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(os.Stderr, "%s: version %d, the current is %d\n", file, version, matrix.Version)
+				continue
+			}
+			version, err := matrix.Migrate(file)
+			if err != nil {
+				return err
+			}
+			if version == matrix.Version {
+				fmt.Fprintf(os.Stderr, "%s: version %d is up to date\n", file, version)
+			} else {
+				fmt.Fprintf(os.Stderr, "%s: migrated from version %d to %d\n", file, version, matrix.Version)
+			}
+		}
+	}
+	return nil
+}
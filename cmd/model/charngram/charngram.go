@@ -0,0 +1,142 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package charngram
+
+import (
+	"context"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/cmd/model/cmdutil"
+	"github.com/ynqa/wego/pkg/model"
+	"github.com/ynqa/wego/pkg/model/charngram"
+	"github.com/ynqa/wego/pkg/model/cli"
+	"github.com/ynqa/wego/pkg/model/manifest"
+	"github.com/ynqa/wego/pkg/model/modelutil/vector"
+	"github.com/ynqa/wego/pkg/util/fileutil"
+	"github.com/ynqa/wego/pkg/util/profile"
+	"github.com/ynqa/wego/pkg/util/remote"
+)
+
+var (
+	configFile   string
+	force        bool
+	prof         bool
+	pprofAddr    string
+	traceFile    string
+	inputFile    string
+	manifestFile string
+	printConfig  bool
+	outputFile   string
+	vectorType   vector.Type
+)
+
+func New() *cobra.Command {
+	var opts charngram.Options
+	cmd := &cobra.Command{
+		Use:   "charngram",
+		Short: "Character n-gram embeddings by Skip-gram to encode arbitrary strings",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cmdutil.LoadConfig(cmd, configFile); err != nil {
+				return err
+			}
+			if printConfig {
+				if outputFile == fileutil.Stdio {
+					return errors.New("--print-config writes into stdout, which -o - is also written into")
+				}
+				if err := cmdutil.PrintConfig(cmd.OutOrStdout(), cmd); err != nil {
+					return err
+				}
+			}
+			opts.Word2Vec.Progress = cmdutil.Progress(cmd, outputFile)
+			return execute(opts, cmdutil.Config(cmd))
+		},
+	}
+
+	cmdutil.AddConfigFlags(cmd, &configFile)
+	cmdutil.AddForceFlags(cmd, &force)
+	cmdutil.AddInputFlags(cmd, &inputFile)
+	cmdutil.AddManifestFlags(cmd, &manifestFile)
+	cmdutil.AddOutputFlags(cmd, &outputFile)
+	cmdutil.AddPprofAddrFlags(cmd, &pprofAddr)
+	cmdutil.AddPrintConfigFlags(cmd, &printConfig)
+	cmdutil.AddProfFlags(cmd, &prof)
+	cmdutil.AddTraceFlags(cmd, &traceFile)
+	cmdutil.AddVectorTypeFlags(cmd, &vectorType)
+	cli.CharNGram(cmd, &opts)
+	return cmd
+}
+
+func execute(opts charngram.Options, flags map[string]interface{}) error {
+	profiler := &profile.Profiler{
+		Trace: traceFile,
+	}
+	if prof {
+		profiler.CPUProfile = "cpu.prof"
+	}
+	opts.Word2Vec.Hooks = append(opts.Word2Vec.Hooks, profiler)
+	if pprofAddr != "" {
+		srv, err := profile.Serve(pprofAddr)
+		if err != nil {
+			return err
+		}
+		defer srv.Close()
+	}
+
+	if err := remote.CheckOverwrite(outputFile, force); err != nil {
+		return err
+	} else if !cmdutil.InputExists(inputFile) {
+		return errors.Errorf("%s is not found", inputFile)
+	}
+	if manifestFile != "" {
+		if err := remote.CheckOverwrite(manifestFile, force); err != nil {
+			return err
+		}
+	}
+	input, err := cmdutil.OpenInput(inputFile)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	var rec *manifest.Recorder
+	if manifestFile != "" {
+		rec = manifest.NewRecorder(inputFile, outputFile)
+		rec.Manifest.Config = configFile
+		rec.Manifest.Flags = flags
+		opts.Word2Vec.Hooks = append(opts.Word2Vec.Hooks, rec)
+	}
+	mod, err := charngram.NewForOptions(opts)
+	if err != nil {
+		return err
+	}
+	defer model.Close(mod)
+	if err := mod.Train(context.Background(), input); err != nil {
+		return err
+	}
+	if err := profiler.Err(); err != nil {
+		return err
+	}
+	if err := remote.WriteAtomic(outputFile, func(w io.Writer) error {
+		return mod.Save(w, vectorType)
+	}); err != nil {
+		return err
+	}
+	if rec != nil {
+		return rec.WriteFile(manifestFile)
+	}
+	return nil
+}
@@ -15,32 +15,309 @@
 package cmdutil
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
+	"github.com/ynqa/wego/pkg/corpus/langid"
+	"github.com/ynqa/wego/pkg/corpus/markup"
+	"github.com/ynqa/wego/pkg/corpus/source"
+	"github.com/ynqa/wego/pkg/model"
+	"github.com/ynqa/wego/pkg/model/cli"
+	"github.com/ynqa/wego/pkg/model/glove"
+	"github.com/ynqa/wego/pkg/model/lexvec"
 	"github.com/ynqa/wego/pkg/model/modelutil/vector"
+	"github.com/ynqa/wego/pkg/model/word2vec"
+	"github.com/ynqa/wego/pkg/util/fileutil"
+	"github.com/ynqa/wego/pkg/util/remote"
 )
 
 const (
+	defaultCaseMap    = ""
+	defaultConfig     = ""
+	defaultCurveFile  = ""
+	defaultDashboard  = ""
+	defaultDedup      = false
+	defaultForce      = false
+	defaultFreeze     = ""
 	defaultInputFile  = "example/input.txt"
+	defaultLRWeights  = ""
+	defaultManifest   = ""
+	defaultMarkup     = ""
 	defaultOutputFile = "example/word_vectors.txt"
+	defaultPprofAddr  = ""
+	defaultPrintConf  = false
+	defaultProbeFile  = ""
 	defaultProf       = false
+	defaultSaveFilter = ""
+	defaultTraceFile  = ""
+	defaultTUI        = false
 	defaultVectorType = vector.Single
 )
 
+func AddCaseMapFlags(cmd *cobra.Command, caseMap *string) {
+	cmd.Flags().StringVar(caseMap, "case-map", defaultCaseMap, "file path to write the case variants merged by --merge-case as the lines of form and word")
+}
+
+func AddConfigFlags(cmd *cobra.Command, config *string) {
+	cmd.Flags().StringVar(config, "config", defaultConfig, "JSON file path of the flag names and values, e.g. '{\"dim\": 100}', which the flags in the command line override")
+}
+
+func AddCurveFlags(cmd *cobra.Command, curve *string) {
+	cmd.Flags().StringVar(curve, "curve-out", defaultCurveFile, "file path to write the training curve of (epoch, tokens, loss, lr, elapsed) as CSV, or JSON for .json")
+}
+
+func AddDashboardFlags(cmd *cobra.Command, addr *string) {
+	cmd.Flags().StringVar(addr, "dashboard", defaultDashboard, "address to serve the web page of the live charts and the test query during training, e.g. :8080")
+}
+
+func AddDedupFlags(cmd *cobra.Command, dedup *bool) {
+	cmd.Flags().BoolVar(dedup, "dedup", defaultDedup, "whether to drop the exact and near-duplicate lines of the corpus while reading it or not, as the dedup command does by default")
+}
+
+func AddForceFlags(cmd *cobra.Command, force *bool) {
+	cmd.Flags().BoolVar(force, "force", defaultForce, "overwrite the existing output files")
+}
+
+func AddFreezeWordsFlags(cmd *cobra.Command, freeze *string) {
+	cmd.Flags().StringVar(freeze, "freeze-words", defaultFreeze, "file path for the words whose vectors are kept unchanged during training")
+}
+
 func AddInputFlags(cmd *cobra.Command, input *string) {
-	cmd.Flags().StringVarP(input, "input", "i", defaultInputFile, "input file path for corpus")
+	cmd.Flags().StringVarP(input, "input", "i", defaultInputFile, "input file path for corpus, - for stdin")
+}
+
+func AddLangFlags(cmd *cobra.Command, langs *[]string) {
+	cmd.Flags().StringSliceVar(langs, "lang", nil, fmt.Sprintf("languages to keep only the lines identified as them by the character trigrams, e.g. en,de. Any of: %s", strings.Join(langid.New().Languages(), "|")))
+}
+
+func AddLRWeightsFlags(cmd *cobra.Command, weights *string) {
+	cmd.Flags().StringVar(weights, "lr-weights", defaultLRWeights, "file path for the lines of 'word weight' to scale the learning rate of each word")
+}
+
+func AddManifestFlags(cmd *cobra.Command, manifest *string) {
+	cmd.Flags().StringVar(manifest, "manifest", defaultManifest, "file path to write the run manifest (options hash, corpus checksum, times and metrics) as JSON")
+}
+
+func AddMarkupFlags(cmd *cobra.Command, typ *markup.Type) {
+	cmd.Flags().StringVar(typ, "markup", defaultMarkup, fmt.Sprintf("markup to strip from the corpus while reading it. One of: %s|%s", markup.HTML, markup.Markdown))
 }
 
 func AddOutputFlags(cmd *cobra.Command, output *string) {
-	cmd.Flags().StringVarP(output, "output", "o", defaultOutputFile, "output file path to save word vectors")
+	cmd.Flags().StringVarP(output, "output", "o", defaultOutputFile, "output file path to save word vectors, - for stdout")
+}
+
+func AddPrintConfigFlags(cmd *cobra.Command, print *bool) {
+	cmd.Flags().BoolVar(print, "print-config", defaultPrintConf, "print the resolved flags into stdout as the JSON of --config before training")
+}
+
+func AddProbeFlags(cmd *cobra.Command, probe *string) {
+	cmd.Flags().StringVar(probe, "probe", defaultProbeFile, "file path for the lines of 'word' (neighbors) or 'word1 word2' (similarity) to evaluate and log into stderr at the end of each iteration")
 }
 
 func AddProfFlags(cmd *cobra.Command, prof *bool) {
 	cmd.Flags().BoolVar(prof, "prof", defaultProf, "profiling mode to check the performances")
 }
 
+func AddPprofAddrFlags(cmd *cobra.Command, addr *string) {
+	cmd.Flags().StringVar(addr, "pprof-addr", defaultPprofAddr, "address to serve net/http/pprof during training, e.g. :6060")
+}
+
+func AddSaveFilterFlags(cmd *cobra.Command, filter *string) {
+	cmd.Flags().StringVar(filter, "save-filter", defaultSaveFilter, "file path for the words to save only their vectors, separated by space or newline")
+}
+
+func AddTraceFlags(cmd *cobra.Command, trace *string) {
+	cmd.Flags().StringVar(trace, "trace-out", defaultTraceFile, "file path to write the execution trace of training")
+}
+
+func AddTUIFlags(cmd *cobra.Command, tui *bool) {
+	cmd.Flags().BoolVar(tui, "tui", defaultTUI, "show the live dashboard of throughput, loss, memory and ETA on stderr instead of the verbose counter")
+}
+
 func AddVectorTypeFlags(cmd *cobra.Command, typ *vector.Type) {
 	cmd.Flags().StringVar(typ, "vec-type", defaultVectorType, fmt.Sprintf("word vector type. One of: %s|%s", vector.Single, vector.Agg))
 }
+
+// InputExists returns whether the corpus of path exists, where the URI of source is checked on open.
+func InputExists(path string) bool {
+	return source.IsSource(path) || remote.Exists(path)
+}
+
+// OpenInput opens the corpus of path, which may be the URI of source, e.g. sqlite3:///docs.db?query=...
+func OpenInput(path string) (io.ReadCloser, error) {
+	if !source.IsSource(path) {
+		return remote.OpenFile(path)
+	}
+	src, err := source.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return source.NewReader(context.Background(), src), nil
+}
+
+// Progress returns the writer of the progress in verbose, which is stderr of cmd
+// while the output is written to stdout.
+func Progress(cmd *cobra.Command, output string) io.Writer {
+	if output == fileutil.Stdio {
+		return cmd.ErrOrStderr()
+	}
+	return cmd.OutOrStdout()
+}
+
+// LoadWords reads the words of the file of path, one per line, e.g. for --freeze-words.
+func LoadWords(path string) ([]string, error) {
+	f, err := remote.OpenFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return fileutil.LoadWords(f)
+}
+
+// LoadConfig sets the flags by the JSON file of the flag names and values,
+// except the flags given in the command line. The lists are set as the comma-separated values.
+func LoadConfig(cmd *cobra.Command, path string) error {
+	if path == "" {
+		return nil
+	}
+	f, err := remote.OpenFile(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var conf map[string]interface{}
+	dec := json.NewDecoder(f)
+	// the numbers are kept as written, e.g. 1000000 for the int flags.
+	dec.UseNumber()
+	if err := dec.Decode(&conf); err != nil {
+		return errors.Wrapf(err, "failed to decode %s", path)
+	}
+	names := make([]string, 0, len(conf))
+	for name := range conf {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil || ignoreConfig[name] {
+			return errors.Errorf("unknown flag %s in %s", name, path)
+		} else if flag.Changed {
+			continue
+		}
+		if err := cmd.Flags().Set(name, FlagValue(conf[name])); err != nil {
+			return errors.Wrapf(err, "failed to set %s in %s", name, path)
+		}
+	}
+	return nil
+}
+
+// FlagValue returns the value of the flag decoded from JSON, where the lists are the comma-separated values.
+func FlagValue(v interface{}) string {
+	if v == nil {
+		return ""
+	} else if list, ok := v.([]interface{}); ok {
+		values := make([]string, len(list))
+		for i, v := range list {
+			values[i] = fmt.Sprint(v)
+		}
+		return strings.Join(values, ",")
+	}
+	return fmt.Sprint(v)
+}
+
+// ignoreConfig is the flags not to be in the config.
+var ignoreConfig = map[string]bool{
+	"config":       true,
+	"print-config": true,
+	"help":         true,
+}
+
+// Config returns the values of the flags of cmd by the flag names, which LoadConfig reads back.
+func Config(cmd *cobra.Command) map[string]interface{} {
+	conf := make(map[string]interface{})
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if ignoreConfig[flag.Name] {
+			return
+		}
+		if list, ok := flag.Value.(pflag.SliceValue); ok {
+			values := list.GetSlice()
+			if values == nil {
+				values = []string{}
+			}
+			conf[flag.Name] = values
+			return
+		}
+		value := flag.Value.String()
+		conf[flag.Name] = value
+		switch typ := flag.Value.Type(); {
+		case typ == "bool":
+			conf[flag.Name] = value == "true"
+		case strings.HasPrefix(typ, "int") || strings.HasPrefix(typ, "uint") || strings.HasPrefix(typ, "float"):
+			if _, err := strconv.ParseFloat(value, 64); err == nil {
+				conf[flag.Name] = json.Number(value)
+			}
+		}
+	})
+	return conf
+}
+
+// PrintConfig writes Config of cmd into w as JSON.
+func PrintConfig(w io.Writer, cmd *cobra.Command) error {
+	b, err := json.MarshalIndent(Config(cmd), "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode config")
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// NewModel creates the model by setting the flags of the sub-command for the model, e.g. dim=100.
+func NewModel(name string, flags map[string]string) (model.Model, error) {
+	return NewWarmModel(name, flags, nil)
+}
+
+// NewWarmModel is NewModel whose vectors of the words in init are warm-started by them.
+func NewWarmModel(name string, flags map[string]string, init map[string][]float64) (model.Model, error) {
+	cmd := &cobra.Command{}
+	var build func() (model.Model, error)
+	switch name {
+	case "word2vec":
+		var opts word2vec.Options
+		cli.Word2Vec(cmd, &opts)
+		build = func() (model.Model, error) {
+			opts.InitVectors = init
+			return word2vec.NewForOptions(opts)
+		}
+	case "glove":
+		var opts glove.Options
+		cli.GloVe(cmd, &opts)
+		build = func() (model.Model, error) {
+			opts.InitVectors = init
+			return glove.NewForOptions(opts)
+		}
+	case "lexvec":
+		var opts lexvec.Options
+		cli.LexVec(cmd, &opts)
+		build = func() (model.Model, error) {
+			opts.InitVectors = init
+			return lexvec.NewForOptions(opts)
+		}
+	default:
+		return nil, errors.Errorf("invalid model: %s not in word2vec|glove|lexvec", name)
+	}
+	for k, v := range flags {
+		if err := cmd.Flags().Set(k, v); err != nil {
+			return nil, errors.Wrapf(err, "failed to set flag %s=%s", k, v)
+		}
+	}
+	return build()
+}
@@ -0,0 +1,80 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmdutil
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/model/cli"
+	"github.com/ynqa/wego/pkg/model/word2vec"
+)
+
+func newWord2VecCmd(opts *word2vec.Options, langs *[]string, config *string, print *bool) *cobra.Command {
+	cmd := &cobra.Command{}
+	AddConfigFlags(cmd, config)
+	AddLangFlags(cmd, langs)
+	AddPrintConfigFlags(cmd, print)
+	AddOutputFlags(cmd, new(string))
+	cli.Word2Vec(cmd, opts)
+	return cmd
+}
+
+func TestConfigRoundTrip(t *testing.T) {
+	var (
+		opts   word2vec.Options
+		langs  []string
+		config string
+		print  bool
+	)
+	cmd := newWord2VecCmd(&opts, &langs, &config, &print)
+	assert.NoError(t, cmd.ParseFlags([]string{"--dim", "42", "--initlr", "0.0001", "--lang", "en,de", "-o", "out.txt", "--print-config"}))
+
+	var buf bytes.Buffer
+	assert.NoError(t, PrintConfig(&buf, cmd))
+	assert.NotContains(t, buf.String(), "print-config")
+	assert.Contains(t, buf.String(), `"dim": 42`)
+
+	dir, err := ioutil.TempDir("", "cmdutil")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config.json")
+	assert.NoError(t, ioutil.WriteFile(path, buf.Bytes(), 0644))
+
+	var (
+		loaded      word2vec.Options
+		loadedLangs []string
+	)
+	other := newWord2VecCmd(&loaded, &loadedLangs, &config, &print)
+	assert.NoError(t, other.ParseFlags([]string{"--window", "3"}))
+	assert.NoError(t, LoadConfig(other, path))
+	assert.Equal(t, 42, loaded.Dim)
+	assert.Equal(t, 0.0001, loaded.Initlr)
+	assert.Equal(t, 3, loaded.Window, "the command line overrides the config")
+	assert.Equal(t, []string{"en", "de"}, loadedLangs)
+
+	opts.Window = 3
+	assert.Equal(t, opts, loaded)
+	assert.Equal(t, Config(cmd)["output"], Config(other)["output"])
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`{"Dim": 10}`), 0644))
+	assert.EqualError(t, LoadConfig(other, path), "unknown flag Dim in "+path)
+}
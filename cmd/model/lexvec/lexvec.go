@@ -15,23 +15,53 @@
 package lexvec
 
 import (
+	"context"
+	"io"
 	"os"
-	"path/filepath"
-	"runtime/pprof"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"github.com/ynqa/wego/cmd/model/cmdutil"
+	"github.com/ynqa/wego/pkg/corpus/dedup"
+	"github.com/ynqa/wego/pkg/corpus/langid"
+	"github.com/ynqa/wego/pkg/corpus/markup"
+	"github.com/ynqa/wego/pkg/model"
+	"github.com/ynqa/wego/pkg/model/cli"
+	"github.com/ynqa/wego/pkg/model/curve"
+	"github.com/ynqa/wego/pkg/model/dashboard"
 	"github.com/ynqa/wego/pkg/model/lexvec"
+	"github.com/ynqa/wego/pkg/model/manifest"
+	"github.com/ynqa/wego/pkg/model/modelutil/lrscale"
 	"github.com/ynqa/wego/pkg/model/modelutil/vector"
+	"github.com/ynqa/wego/pkg/model/probe"
+	"github.com/ynqa/wego/pkg/util/fileutil"
+	"github.com/ynqa/wego/pkg/util/profile"
+	"github.com/ynqa/wego/pkg/util/remote"
 )
 
 var (
-	prof       bool
-	inputFile  string
-	outputFile string
-	vectorType vector.Type
+	caseMapFile  string
+	configFile   string
+	curveFile    string
+	dashAddr     string
+	dedupLines   bool
+	force        bool
+	freezeFile   string
+	prof         bool
+	pprofAddr    string
+	traceFile    string
+	tui          bool
+	inputFile    string
+	langs        []string
+	lrWeightFile string
+	manifestFile string
+	markupType   markup.Type
+	printConfig  bool
+	probeFile    string
+	outputFile   string
+	saveFilter   string
+	vectorType   vector.Type
 )
 
 func New() *cobra.Command {
@@ -40,56 +70,196 @@ func New() *cobra.Command {
 		Use:   "lexvec",
 		Short: "Lexvec: Matrix Factorization using Window Sampling and Negative Sampling for Improved Word Representations",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return execute(opts)
+			if err := cmdutil.LoadConfig(cmd, configFile); err != nil {
+				return err
+			}
+			if printConfig {
+				if outputFile == fileutil.Stdio {
+					return errors.New("--print-config writes into stdout, which -o - is also written into")
+				}
+				if err := cmdutil.PrintConfig(cmd.OutOrStdout(), cmd); err != nil {
+					return err
+				}
+			}
+			opts.Progress = cmdutil.Progress(cmd, outputFile)
+			return execute(opts, cmdutil.Config(cmd))
 		},
 	}
 
+	cmdutil.AddCaseMapFlags(cmd, &caseMapFile)
+	cmdutil.AddConfigFlags(cmd, &configFile)
+	cmdutil.AddCurveFlags(cmd, &curveFile)
+	cmdutil.AddDashboardFlags(cmd, &dashAddr)
+	cmdutil.AddDedupFlags(cmd, &dedupLines)
+	cmdutil.AddForceFlags(cmd, &force)
+	cmdutil.AddFreezeWordsFlags(cmd, &freezeFile)
 	cmdutil.AddInputFlags(cmd, &inputFile)
+	cmdutil.AddLangFlags(cmd, &langs)
+	cmdutil.AddLRWeightsFlags(cmd, &lrWeightFile)
+	cmdutil.AddManifestFlags(cmd, &manifestFile)
+	cmdutil.AddMarkupFlags(cmd, &markupType)
 	cmdutil.AddOutputFlags(cmd, &outputFile)
+	cmdutil.AddPprofAddrFlags(cmd, &pprofAddr)
+	cmdutil.AddPrintConfigFlags(cmd, &printConfig)
+	cmdutil.AddProbeFlags(cmd, &probeFile)
 	cmdutil.AddProfFlags(cmd, &prof)
+	cmdutil.AddSaveFilterFlags(cmd, &saveFilter)
+	cmdutil.AddTraceFlags(cmd, &traceFile)
+	cmdutil.AddTUIFlags(cmd, &tui)
 	cmdutil.AddVectorTypeFlags(cmd, &vectorType)
-	lexvec.LoadForCmd(cmd, &opts)
+	cli.LexVec(cmd, &opts)
 	return cmd
 }
 
-func fileExists(path string) bool {
-	_, err := os.Stat(path)
-	return err == nil
-}
-
-func execute(opts lexvec.Options) error {
+func execute(opts lexvec.Options, flags map[string]interface{}) error {
+	profiler := &profile.Profiler{
+		Trace: traceFile,
+	}
 	if prof {
-		f, err := os.Create("cpu.prof")
+		profiler.CPUProfile = "cpu.prof"
+	}
+	opts.Hooks = append(opts.Hooks, profiler)
+	if pprofAddr != "" {
+		srv, err := profile.Serve(pprofAddr)
 		if err != nil {
 			return err
 		}
-		pprof.StartCPUProfile(f)
-		defer pprof.StopCPUProfile()
+		defer srv.Close()
 	}
 
-	if fileExists(outputFile) {
-		return errors.Errorf("%s is already existed", outputFile)
-	} else if !fileExists(inputFile) {
+	if err := remote.CheckOverwrite(outputFile, force); err != nil {
+		return err
+	} else if !cmdutil.InputExists(inputFile) {
 		return errors.Errorf("Not such a file %s", inputFile)
 	}
-	if err := os.MkdirAll(filepath.Dir(outputFile), 0777); err != nil {
-		return err
+	for _, path := range []string{manifestFile, caseMapFile, curveFile} {
+		if path == "" {
+			continue
+		}
+		if err := remote.CheckOverwrite(path, force); err != nil {
+			return err
+		}
 	}
-	output, err := os.Create(outputFile)
-	if err != nil {
+	if freezeFile != "" {
+		words, err := cmdutil.LoadWords(freezeFile)
+		if err != nil {
+			return err
+		}
+		opts.FreezeWords = append(opts.FreezeWords, words...)
+	}
+	if saveFilter != "" {
+		words, err := cmdutil.LoadWords(saveFilter)
+		if err != nil {
+			return err
+		}
+		opts.SaveWords = append(opts.SaveWords, words...)
+	}
+	if lrWeightFile != "" {
+		f, err := remote.OpenFile(lrWeightFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if opts.LRWeights, err = lrscale.LoadWeights(f); err != nil {
+			return err
+		}
+	}
+	ident := langid.New()
+	if err := ident.Validate(langs); err != nil {
 		return err
 	}
-	input, err := os.Open(inputFile)
+	if markupType != "" && markupType != markup.HTML && markupType != markup.Markdown {
+		return markup.InvalidTypeError(markupType)
+	}
+	input, err := cmdutil.OpenInput(inputFile)
 	if err != nil {
 		return err
 	}
 	defer input.Close()
+	var corpus io.Reader = input
+	if markupType != "" {
+		corpus = markup.NewReader(corpus, markupType)
+	}
+	if len(langs) > 0 {
+		corpus = langid.NewReader(corpus, ident, langs)
+	}
+	if dedupLines {
+		corpus = dedup.NewReader(corpus, dedup.DefaultOptions())
+	}
+	var rec *manifest.Recorder
+	if manifestFile != "" {
+		rec = manifest.NewRecorder(inputFile, outputFile)
+		rec.Manifest.Config = configFile
+		rec.Manifest.Flags = flags
+		opts.Hooks = append(opts.Hooks, rec)
+	}
+	if tui {
+		opts.Verbose = false
+		opts.Hooks = append(opts.Hooks, dashboard.New(os.Stderr, opts.Iter, opts.Goroutines))
+	}
+	var crv *curve.Recorder
+	if curveFile != "" {
+		crv = curve.NewRecorder()
+		opts.Hooks = append(opts.Hooks, crv)
+	}
+	var prober *probe.Prober
+	if probeFile != "" {
+		f, err := remote.OpenFile(probeFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		probes, err := probe.Load(f)
+		if err != nil {
+			return err
+		}
+		prober = probe.NewProber(os.Stderr, probes)
+		opts.Hooks = append(opts.Hooks, prober)
+	}
+	var web *dashboard.Web
+	if dashAddr != "" {
+		web = dashboard.NewWeb()
+		opts.Hooks = append(opts.Hooks, web)
+	}
 	mod, err := lexvec.NewForOptions(opts)
 	if err != nil {
 		return err
 	}
-	if err := mod.Train(input); err != nil {
+	defer model.Close(mod)
+	if prober != nil {
+		prober.Model = mod
+	}
+	if web != nil {
+		srv, err := web.Serve(dashAddr, mod)
+		if err != nil {
+			return err
+		}
+		defer srv.Close()
+	}
+	if err := mod.Train(context.Background(), corpus); err != nil {
+		return err
+	}
+	if err := profiler.Err(); err != nil {
 		return err
 	}
-	return mod.Save(output, vectorType)
+	if err := remote.WriteAtomic(outputFile, func(w io.Writer) error {
+		return mod.Save(w, vectorType)
+	}); err != nil {
+		return err
+	}
+	if caseMapFile != "" {
+		dic := mod.(model.Vocabulary).Dictionary()
+		if err := remote.WriteAtomic(caseMapFile, dic.WriteCaseMap); err != nil {
+			return err
+		}
+	}
+	if crv != nil {
+		if err := crv.WriteFile(curveFile); err != nil {
+			return err
+		}
+	}
+	if rec != nil {
+		return rec.WriteFile(manifestFile)
+	}
+	return nil
 }
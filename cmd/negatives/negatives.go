@@ -0,0 +1,116 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package negatives
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/cmd/query/cmdutil"
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/search"
+	"github.com/ynqa/wego/pkg/search/negative"
+	"github.com/ynqa/wego/pkg/util/fileutil"
+	"github.com/ynqa/wego/pkg/util/remote"
+)
+
+const (
+	defaultOutputFile = "negatives.tsv"
+)
+
+var (
+	force      bool
+	inputFile  string
+	outputFile string
+	wordsFile  string
+)
+
+func New() *cobra.Command {
+	opts := negative.DefaultOptions()
+	cmd := &cobra.Command{
+		Use:   "hard-negatives",
+		Short: "Export the hard negatives of words for training rankers",
+		Example: "  wego hard-negatives -i example/word_vectors.txt --words queries.txt -o negatives.tsv --min-sim 0.4 --max-sim 0.7\n" +
+			"  wego hard-negatives -i example/word_vectors.txt -o - --format jsonl laptop phone",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return execute(opts, args)
+		},
+	}
+	cmdutil.AddInputFlags(cmd, &inputFile)
+	cmd.Flags().StringVarP(&outputFile, "output", "o", defaultOutputFile, "output file path to save negatives, - for stdout")
+	cmd.Flags().BoolVar(&force, "force", false, "overwrite the existing output file")
+	cmd.Flags().StringVar(&wordsFile, "words", "", "file path for the words separated by space or newline, in addition to the arguments")
+	addOptionsFlags(cmd, &opts)
+	return cmd
+}
+
+// addOptionsFlags binds opts to the flags of cmd, whose defaults are negative.DefaultOptions().
+func addOptionsFlags(cmd *cobra.Command, opts *negative.Options) {
+	def := negative.DefaultOptions()
+	cmd.Flags().StringVar(&opts.Format, "format", def.Format, fmt.Sprintf("output format. One of %s|%s", negative.TSV, negative.JSONL))
+	cmd.Flags().IntVarP(&opts.K, "k", "k", def.K, "upper limit of the negatives for each word")
+	cmd.Flags().Float64Var(&opts.Max, "max-sim", def.Max, "upper limit of the similarity of the negatives, which excludes the synonyms")
+	cmd.Flags().Float64Var(&opts.Min, "min-sim", def.Min, "lower limit of the similarity of the negatives, which excludes the easy ones")
+}
+
+func execute(opts negative.Options, args []string) error {
+	words := args
+	if wordsFile != "" {
+		f, err := remote.OpenFile(wordsFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		loaded, err := fileutil.LoadWords(f)
+		if err != nil {
+			return err
+		}
+		words = append(words, loaded...)
+	}
+	if len(words) == 0 {
+		return errors.New("Input the words by arguments or --words")
+	}
+	if err := remote.CheckOverwrite(outputFile, force); err != nil {
+		return err
+	}
+	input, err := remote.OpenFile(inputFile)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	embs, err := embedding.Load(input)
+	if err != nil {
+		return err
+	}
+	s, err := search.New(embs...)
+	if err != nil {
+		return err
+	}
+	var skipped []string
+	if err := remote.WriteAtomic(outputFile, func(w io.Writer) error {
+		skipped, err = negative.Write(w, s, words, opts)
+		return err
+	}); err != nil {
+		return err
+	}
+	if len(skipped) > 0 {
+		fmt.Fprintf(os.Stderr, "skip %d words not found: %v\n", len(skipped), skipped)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ngram
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/cmd/model/cmdutil"
+	"github.com/ynqa/wego/pkg/corpus/cpsutil"
+	"github.com/ynqa/wego/pkg/corpus/ngram"
+	"github.com/ynqa/wego/pkg/util/remote"
+)
+
+var (
+	force      bool
+	inputFile  string
+	outputFile string
+)
+
+func New() *cobra.Command {
+	opts := ngram.DefaultOptions()
+	cmd := &cobra.Command{
+		Use:   "ngram",
+		Short: "Transform the corpus into the sliding word n-grams as the tokens",
+		Example: "  wego ngram -i text8 -n 2 -o text8.bigram\n" +
+			"  wego ngram -i text8 -n 2 | wego word2vec -i - -o bigram_vectors.txt",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return execute(opts)
+		},
+	}
+	cmdutil.AddForceFlags(cmd, &force)
+	cmdutil.AddInputFlags(cmd, &inputFile)
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "-", "output file path to save the transformed corpus, - for stdout")
+	addOptionsFlags(cmd, &opts)
+	return cmd
+}
+
+// addOptionsFlags binds opts to the flags of cmd, whose defaults are ngram.DefaultOptions().
+func addOptionsFlags(cmd *cobra.Command, opts *ngram.Options) {
+	def := ngram.DefaultOptions()
+	cmd.Flags().IntVar(&opts.MaxVocab, "max-vocab", def.MaxVocab, "upper limit of the n-grams counted at once, which prunes the infrequent ones while counting (0 means unlimited)")
+	cmd.Flags().IntVar(&opts.MinCount, "min-count", def.MinCount, "lower limit to filter n-grams")
+	cmd.Flags().IntVarP(&opts.N, "n", "n", def.N, "number of the words in n-grams")
+	cmd.Flags().StringVar(&opts.Sep, "sep", def.Sep, "separator of the words in n-grams")
+	cmd.Flags().BoolVar(&opts.ToLower, "to-lower", def.ToLower, "whether the words on corpus convert to lowercase or not")
+}
+
+func execute(opts ngram.Options) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	} else if err := remote.CheckOverwrite(outputFile, force); err != nil {
+		return err
+	}
+	input, err := remote.OpenFile(inputFile)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	rs, cleanup, err := cpsutil.ReadSeeker(input)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	return remote.WriteAtomic(outputFile, func(w io.Writer) error {
+		return ngram.Write(w, rs, opts)
+	})
+}
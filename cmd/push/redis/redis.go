@@ -0,0 +1,68 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/cmd/query/cmdutil"
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/redis"
+	"github.com/ynqa/wego/pkg/util/remote"
+)
+
+var (
+	inputFile string
+)
+
+func New() *cobra.Command {
+	opts := redis.DefaultOptions()
+	cmd := &cobra.Command{
+		Use:     "redis",
+		Short:   "Push word vectors into Redis as hashes",
+		Example: "  wego push redis -i example/word_vectors.txt --addr localhost:6379 --index words",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return execute(opts)
+		},
+	}
+	cmdutil.AddInputFlags(cmd, &inputFile)
+	addOptionsFlags(cmd, &opts)
+	return cmd
+}
+
+// addOptionsFlags binds opts to the flags of cmd, whose defaults are redis.DefaultOptions().
+func addOptionsFlags(cmd *cobra.Command, opts *redis.Options) {
+	def := redis.DefaultOptions()
+	cmd.Flags().StringVar(&opts.Addr, "addr", def.Addr, "address of redis server")
+	cmd.Flags().IntVar(&opts.Batch, "batch", def.Batch, "number of commands pipelined at once")
+	cmd.Flags().IntVar(&opts.DB, "db", def.DB, "database number to select")
+	cmd.Flags().StringVar(&opts.Index, "index", def.Index, "name of RediSearch index to create on the vectors, no index if empty")
+	cmd.Flags().StringVar(&opts.Password, "password", "", "password to authenticate (default $REDISCLI_AUTH)")
+	cmd.Flags().StringVar(&opts.Prefix, "prefix", def.Prefix, "prefix of the keys of the hashes")
+	cmd.Flags().DurationVar(&opts.Timeout, "timeout", def.Timeout, "timeout to connect to redis server")
+}
+
+func execute(opts redis.Options) error {
+	input, err := remote.OpenFile(inputFile)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	embs, err := embedding.Load(input)
+	if err != nil {
+		return err
+	}
+	return redis.Push(embs, opts)
+}
@@ -15,7 +15,14 @@
 package cmdutil
 
 import (
+	"io/ioutil"
+	"strings"
+
 	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/pkg/model/modelutil/lrscale"
+	"github.com/ynqa/wego/pkg/search"
+	"github.com/ynqa/wego/pkg/util/remote"
 )
 
 const (
@@ -24,9 +31,43 @@ const (
 )
 
 func AddInputFlags(cmd *cobra.Command, input *string) {
-	cmd.Flags().StringVarP(input, "input", "i", defaultInputFile, "input file path for trained word vector")
+	cmd.Flags().StringVarP(input, "input", "i", defaultInputFile, "input file path for trained word vector, - for stdin")
 }
 
 func AddRankFlags(cmd *cobra.Command, rank *int) {
 	cmd.Flags().IntVarP(rank, "rank", "r", defaultRank, "how many similar words will be displayed")
 }
+
+func AddColumnsFlags(cmd *cobra.Command, columns, counts *string) {
+	cmd.Flags().StringVar(columns, "columns", strings.Join(search.DefaultColumns, ","),
+		"comma-separated columns of the table, csv and tsv. Some of rank|word|similarity|position|count|vector")
+	cmd.Flags().StringVar(counts, "counts", "", "file path for the lines of 'word count' for the count column")
+}
+
+// ParseColumns parses the comma-separated columns, before loading the vectors.
+func ParseColumns(columns string) ([]search.Column, error) {
+	cols := strings.Split(columns, ",")
+	if err := (search.Neighbors{{}}).WriteColumns(ioutil.Discard, search.CSV, cols); err != nil {
+		return nil, err
+	}
+	return cols, nil
+}
+
+// SetupColumns sets the searcher up to annotate the neighbors for the columns.
+func SetupColumns(searcher *search.Searcher, cols []search.Column, counts string) error {
+	for _, col := range cols {
+		if col == search.VectorColumn {
+			searcher.WithVector = true
+		}
+	}
+	if counts == "" {
+		return nil
+	}
+	f, err := remote.OpenFile(counts)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	searcher.Counts, err = lrscale.LoadWeights(f)
+	return err
+}
@@ -15,8 +15,6 @@
 package console
 
 import (
-	"os"
-
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
@@ -24,11 +22,14 @@ import (
 	"github.com/ynqa/wego/pkg/embedding"
 	"github.com/ynqa/wego/pkg/search"
 	"github.com/ynqa/wego/pkg/search/console"
+	"github.com/ynqa/wego/pkg/util/remote"
 )
 
 var (
 	inputFile string
 	rank      int
+	columns   string
+	counts    string
 )
 
 func New() *cobra.Command {
@@ -44,19 +45,19 @@ func New() *cobra.Command {
 	}
 	cmdutil.AddInputFlags(cmd, &inputFile)
 	cmdutil.AddRankFlags(cmd, &rank)
+	cmdutil.AddColumnsFlags(cmd, &columns, &counts)
 	return cmd
 }
 
-func fileExists(path string) bool {
-	_, err := os.Stat(path)
-	return err == nil
-}
-
 func execute() error {
-	if !fileExists(inputFile) {
+	if !remote.Exists(inputFile) {
 		return errors.Errorf("Not such a file %s", inputFile)
 	}
-	input, err := os.Open(inputFile)
+	cols, err := cmdutil.ParseColumns(columns)
+	if err != nil {
+		return err
+	}
+	input, err := remote.OpenFile(inputFile)
 	if err != nil {
 		return err
 	}
@@ -69,7 +70,10 @@ func execute() error {
 	if err != nil {
 		return err
 	}
-	console, err := console.New(searcher, rank)
+	if err := cmdutil.SetupColumns(searcher, cols, counts); err != nil {
+		return err
+	}
+	console, err := console.New(searcher, rank, cols...)
 	if err != nil {
 		return err
 	}
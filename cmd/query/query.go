@@ -15,6 +15,8 @@
 package query
 
 import (
+	"fmt"
+	"io/ioutil"
 	"os"
 
 	"github.com/pkg/errors"
@@ -23,39 +25,109 @@ import (
 	"github.com/ynqa/wego/cmd/query/cmdutil"
 	"github.com/ynqa/wego/pkg/embedding"
 	"github.com/ynqa/wego/pkg/search"
+	"github.com/ynqa/wego/pkg/search/ivf"
+	"github.com/ynqa/wego/pkg/util/fileutil"
+	"github.com/ynqa/wego/pkg/util/remote"
 )
 
 var (
+	format    search.Format
 	inputFile string
 	rank      int
+	useIVF    bool
+	lambda    float64
+	among     string
+	columns   string
+	counts    string
 )
 
+type searcher interface {
+	SearchInternal(word string, k int) (search.Neighbors, error)
+}
+
+// mmrSearcher re-ranks the neighbors by maximal marginal relevance.
+type mmrSearcher struct {
+	*search.Searcher
+	lambda float64
+}
+
+func (s mmrSearcher) SearchInternal(word string, k int) (search.Neighbors, error) {
+	q, ok := s.Items.Find(word)
+	if !ok {
+		return nil, errors.Wrap(search.ErrWordNotFound, word)
+	}
+	return s.MMR(q.Vector, k, s.lambda, word)
+}
+
+// amongSearcher ranks only the candidates.
+type amongSearcher struct {
+	*search.Searcher
+	candidates []string
+}
+
+func (s amongSearcher) SearchInternal(word string, k int) (search.Neighbors, error) {
+	return s.SearchAmong(word, s.candidates, k)
+}
+
 func New() *cobra.Command {
+	opts := ivf.DefaultOptions()
 	cmd := &cobra.Command{
 		Use:     "query",
+		Aliases: []string{"search"},
 		Short:   "Query similar words",
-		Example: "  wego query -i example/word_vectors.txt microsoft",
+		Example: "  wego query -i example/word_vectors.txt microsoft\n" +
+			"  wego query -i example/word_vectors.txt --rank 5 --format json microsoft\n" +
+			"  wego query -i example/word_vectors.txt --ivf --nprobe 4 microsoft\n" +
+			"  wego query -i example/word_vectors.txt --mmr 0.5 microsoft\n" +
+			"  wego query -i example/word_vectors.txt --among categories.txt laptop",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return execute(args)
+			return execute(opts, args)
 		},
 	}
 	cmdutil.AddInputFlags(cmd, &inputFile)
 	cmdutil.AddRankFlags(cmd, &rank)
+	cmd.Flags().StringVar(&format, "format", search.Table, fmt.Sprintf("output format. One of %s|%s|%s|%s", search.Table, search.JSON, search.CSV, search.TSV))
+	cmd.Flags().BoolVar(&useIVF, "ivf", false, "search approximately by the IVF index instead of the brute force")
+	cmd.Flags().Float64Var(&lambda, "mmr", 1, "lambda in [0, 1] to re-rank by maximal marginal relevance, where the lower gives the more diverse words (1 is the plain ranking)")
+	cmd.Flags().StringVar(&among, "among", "", "file path for the candidate words to rank only them, separated by space or newline")
+	cmdutil.AddColumnsFlags(cmd, &columns, &counts)
+	addOptionsFlags(cmd, &opts)
 	return cmd
 }
 
-func fileExists(path string) bool {
-	_, err := os.Stat(path)
-	return err == nil
+// addOptionsFlags binds opts to the flags of cmd, whose defaults are ivf.DefaultOptions().
+func addOptionsFlags(cmd *cobra.Command, opts *ivf.Options) {
+	def := ivf.DefaultOptions()
+	cmd.Flags().IntVar(&opts.Goroutines, "goroutines", def.Goroutines, "number of goroutine to build the index")
+	cmd.Flags().IntVar(&opts.Iter, "ivf-iter", def.Iter, "number of k-means iterations to train the centroids of the IVF index")
+	cmd.Flags().IntVar(&opts.Lists, "ivf-lists", def.Lists, "number of the lists of the IVF index (default sqrt of the vocabulary size)")
+	cmd.Flags().IntVar(&opts.NProbe, "nprobe", def.NProbe, "number of the nearest lists to scan for the query")
+	cmd.Flags().Int64Var(&opts.Seed, "ivf-seed", def.Seed, "seed of k-means for the IVF index")
+	cmd.Flags().IntVar(&opts.LogBatch, "log-batch", def.LogBatch, "batch size to log for counting vectors to build the index")
+	cmd.Flags().BoolVar(&opts.Verbose, "verbose", def.Verbose, "verbose mode")
 }
 
-func execute(args []string) error {
-	if !fileExists(inputFile) {
+func execute(opts ivf.Options, args []string) error {
+	if !remote.Exists(inputFile) {
 		return errors.Errorf("Not such a file %s", inputFile)
 	} else if len(args) != 1 {
 		return errors.Errorf("Input a single word %v", args)
+	} else if lambda < 0 || lambda > 1 {
+		return errors.Errorf("mmr must be in [0, 1], got %v", lambda)
+	} else if useIVF && lambda < 1 {
+		return errors.New("mmr is not supported with ivf")
+	} else if among != "" && (useIVF || lambda < 1) {
+		return errors.New("among is not supported with ivf or mmr")
+	}
+	// validate the format before loading the vectors.
+	if err := (search.Neighbors{}).Write(ioutil.Discard, format); err != nil {
+		return err
 	}
-	input, err := os.Open(inputFile)
+	cols, err := cmdutil.ParseColumns(columns)
+	if err != nil {
+		return err
+	}
+	input, err := remote.OpenFile(inputFile)
 	if err != nil {
 		return err
 	}
@@ -64,14 +136,44 @@ func execute(args []string) error {
 	if err != nil {
 		return err
 	}
-	searcher, err := search.New(embs...)
+	brute, err := search.New(embs...)
 	if err != nil {
 		return err
 	}
-	neighbors, err := searcher.SearchInternal(args[0], rank)
+	if err := cmdutil.SetupColumns(brute, cols, counts); err != nil {
+		return err
+	}
+	var s searcher = brute
+	if useIVF {
+		s, err = ivf.New(embs, opts)
+	} else {
+		if lambda < 1 {
+			s = mmrSearcher{Searcher: brute, lambda: lambda}
+		}
+		if among != "" {
+			candidates, err := loadCandidates(among)
+			if err != nil {
+				return err
+			}
+			s = amongSearcher{Searcher: brute, candidates: candidates}
+		}
+	}
 	if err != nil {
 		return err
 	}
-	neighbors.Describe()
-	return nil
+	neighbors, err := s.SearchInternal(args[0], rank)
+	if err != nil {
+		return err
+	}
+	brute.Annotate(neighbors)
+	return neighbors.WriteColumns(os.Stdout, format, cols)
+}
+
+func loadCandidates(path string) ([]string, error) {
+	f, err := remote.OpenFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return fileutil.LoadWords(f)
 }
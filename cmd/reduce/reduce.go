@@ -0,0 +1,90 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reduce
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/cmd/query/cmdutil"
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/util/remote"
+)
+
+const (
+	defaultOutputFile = "reduced_vectors.txt"
+	defaultDim        = 100
+	defaultTop        = 0
+)
+
+var (
+	force      bool
+	inputFile  string
+	outputFile string
+	dim        int
+	top        int
+)
+
+func New() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "reduce",
+		Short:   "Reduce the dimension of word vectors by PCA",
+		Example: "  wego reduce -i example/word_vectors.txt -o reduced.txt --dim 100 --top 3",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return execute()
+		},
+	}
+	cmdutil.AddInputFlags(cmd, &inputFile)
+	cmd.Flags().StringVarP(&outputFile, "output", "o", defaultOutputFile, "output file path to save word vectors, - for stdout")
+	cmd.Flags().BoolVar(&force, "force", false, "overwrite the existing output file")
+	cmd.Flags().IntVarP(&dim, "dim", "d", defaultDim, "dimension for reduced word vectors")
+	cmd.Flags().IntVar(&top, "top", defaultTop, "number of top components removed by all-but-the-top before and after PCA, 0 disables it")
+	return cmd
+}
+
+func execute() error {
+	if err := remote.CheckOverwrite(outputFile, force); err != nil {
+		return err
+	}
+	input, err := remote.OpenFile(inputFile)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	embs, err := embedding.Load(input)
+	if err != nil {
+		return err
+	}
+
+	// post-processing around PCA by Raunak et al. (2019), "Effective Dimensionality Reduction for Word Embeddings".
+	if top > 0 {
+		if embs, err = embedding.AllButTheTop(embs, top); err != nil {
+			return err
+		}
+	}
+	if embs, err = embedding.Reduce(embs, dim); err != nil {
+		return err
+	}
+	if top > 0 {
+		if embs, err = embedding.AllButTheTop(embs, top); err != nil {
+			return err
+		}
+	}
+
+	return remote.WriteAtomic(outputFile, func(w io.Writer) error {
+		return embedding.Save(w, embs)
+	})
+}
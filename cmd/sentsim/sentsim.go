@@ -0,0 +1,128 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sentsim
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/cmd/query/cmdutil"
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/model/modelutil/lrscale"
+	"github.com/ynqa/wego/pkg/search/sif"
+	"github.com/ynqa/wego/pkg/util/fileutil"
+	"github.com/ynqa/wego/pkg/util/remote"
+)
+
+var (
+	addr          string
+	countsFile    string
+	inputFile     string
+	referenceFile string
+)
+
+func New() *cobra.Command {
+	opts := sif.DefaultOptions()
+	cmd := &cobra.Command{
+		Use:   "sentence-similarity",
+		Short: "Cosine similarity between two sentences by SIF-weighted averages",
+		Example: "  wego sentence-similarity -i example/word_vectors.txt --counts counts.txt \"a man is playing guitar\" \"someone plays music\"\n" +
+			"  wego sentence-similarity -i example/word_vectors.txt --serve :8080",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return execute(opts, args)
+		},
+	}
+	cmdutil.AddInputFlags(cmd, &inputFile)
+	cmd.Flags().StringVar(&countsFile, "counts", "", "file path for the lines of 'word count' (default estimated by Zipf's law on the order of the word vectors)")
+	cmd.Flags().StringVar(&referenceFile, "reference", "", "file path for the sentences per line to estimate the removed components (default the word vectors)")
+	cmd.Flags().StringVar(&addr, "serve", "", "address to serve the similarity on /similarity over HTTP, e.g. :8080")
+	addOptionsFlags(cmd, &opts)
+	return cmd
+}
+
+// addOptionsFlags binds opts to the flags of cmd, whose defaults are sif.DefaultOptions().
+func addOptionsFlags(cmd *cobra.Command, opts *sif.Options) {
+	def := sif.DefaultOptions()
+	cmd.Flags().Float64Var(&opts.Alpha, "alpha", def.Alpha, "smoothing parameter a of the word weight a/(a+p(w))")
+	cmd.Flags().IntVar(&opts.Components, "components", def.Components, "number of the principal components removed from the sentence vectors")
+	cmd.Flags().BoolVar(&opts.ToLower, "lower", def.ToLower, "whether the words in sentences are lower-cased")
+}
+
+func execute(opts sif.Options, args []string) error {
+	if addr == "" && len(args) != 2 {
+		return errors.Errorf("Input two sentences %v", args)
+	}
+	input, err := remote.OpenFile(inputFile)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	embs, err := embedding.Load(input)
+	if err != nil {
+		return err
+	}
+	var counts map[string]float64
+	if countsFile != "" {
+		f, err := remote.OpenFile(countsFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if counts, err = lrscale.LoadWeights(f); err != nil {
+			return err
+		}
+	}
+	encoder, err := sif.New(embs, counts, opts)
+	if err != nil {
+		return err
+	}
+	if referenceFile != "" {
+		f, err := remote.OpenFile(referenceFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		var sentences []string
+		s := fileutil.NewScanner(f, bufio.ScanLines)
+		for s.Scan() {
+			sentences = append(sentences, s.Text())
+		}
+		if err := s.Err(); err != nil && err != io.EOF {
+			return errors.Wrapf(err, "failed to scan %s", referenceFile)
+		}
+		if err := encoder.Fit(sentences); err != nil {
+			return err
+		}
+	}
+
+	if addr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/similarity", encoder)
+		fmt.Fprintf(os.Stderr, "serving on %s/similarity\n", addr)
+		return http.ListenAndServe(addr, mux)
+	}
+	sim, err := encoder.Similarity(args[0], args[1])
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%f\n", sim)
+	return nil
+}
@@ -0,0 +1,70 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package similarity
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/cmd/query/cmdutil"
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/search"
+	"github.com/ynqa/wego/pkg/util/remote"
+)
+
+var (
+	inputFile string
+)
+
+func New() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "similarity",
+		Short: "Cosine similarity between two words or phrases",
+		Example: "  wego similarity -i example/word_vectors.txt microsoft apple\n" +
+			"  wego similarity -i example/word_vectors.txt \"operating system\" windows",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return execute(args)
+		},
+	}
+	cmdutil.AddInputFlags(cmd, &inputFile)
+	return cmd
+}
+
+func execute(args []string) error {
+	if len(args) != 2 {
+		return errors.Errorf("Input two words or phrases %v", args)
+	}
+	input, err := remote.OpenFile(inputFile)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	embs, err := embedding.Load(input)
+	if err != nil {
+		return err
+	}
+	searcher, err := search.New(embs...)
+	if err != nil {
+		return err
+	}
+	sim, err := searcher.Similarity(args[0], args[1])
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%f\n", sim)
+	return nil
+}
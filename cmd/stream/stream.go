@@ -0,0 +1,80 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stream
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/cmd/model/cmdutil"
+	"github.com/ynqa/wego/pkg/model"
+	"github.com/ynqa/wego/pkg/model/modelutil/vector"
+	"github.com/ynqa/wego/pkg/stream"
+	"github.com/ynqa/wego/pkg/util/fileutil"
+	"github.com/ynqa/wego/pkg/util/remote"
+)
+
+var (
+	inputFile  string
+	outputFile string
+	modelName  string
+	flags      map[string]string
+)
+
+func New() *cobra.Command {
+	opts := stream.DefaultOptions()
+	cmd := &cobra.Command{
+		Use:   "stream",
+		Short: "Train word vectors continuously over the stream of documents",
+		Example: "  kafka-console-consumer --bootstrap-server localhost:9092 --topic logs | wego stream -o vectors.txt --every 1000\n" +
+			"  nats sub logs --raw | wego stream --model glove --flags dim=50,iter=5 -o vectors.txt --interval 1m",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return execute(opts)
+		},
+	}
+	cmd.Flags().StringVarP(&inputFile, "input", "i", fileutil.Stdio, "input file path for the documents separated by newline, - for stdin")
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "example/word_vectors.txt", "output file path which is replaced by every snapshot")
+	cmd.Flags().StringVar(&modelName, "model", "word2vec", "model to train. One of word2vec|glove|lexvec")
+	cmd.Flags().StringToStringVar(&flags, "flags", nil, "flags of the model sub-command, e.g. dim=100,min-count=1")
+	addOptionsFlags(cmd, &opts)
+	return cmd
+}
+
+// addOptionsFlags binds opts to the flags of cmd, whose defaults are stream.DefaultOptions().
+func addOptionsFlags(cmd *cobra.Command, opts *stream.Options) {
+	def := stream.DefaultOptions()
+	cmd.Flags().IntVar(&opts.Every, "every", def.Every, "number of new documents to train and publish the snapshot")
+	cmd.Flags().DurationVar(&opts.Interval, "interval", def.Interval, "interval to publish the snapshot even if the new documents are fewer than --every (0 means disabled)")
+	cmd.Flags().StringVar(&opts.VectorType, "vec-type", def.VectorType, fmt.Sprintf("word vector type. One of: %s|%s", vector.Single, vector.Agg))
+	cmd.Flags().BoolVar(&opts.Verbose, "verbose", def.Verbose, "verbose mode")
+}
+
+func execute(opts stream.Options) error {
+	// validate the model and the flags before consuming the stream.
+	if _, err := cmdutil.NewModel(modelName, flags); err != nil {
+		return err
+	}
+	input, err := remote.OpenFile(inputFile)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	newModel := func(init map[string][]float64) (model.Model, error) {
+		return cmdutil.NewWarmModel(modelName, flags, init)
+	}
+	return stream.Run(context.Background(), stream.Lines(input), newModel, stream.File(outputFile), opts)
+}
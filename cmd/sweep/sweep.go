@@ -0,0 +1,234 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sweep
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/ynqa/wego/cmd/model/cmdutil"
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/eval/similarity"
+	"github.com/ynqa/wego/pkg/model"
+	"github.com/ynqa/wego/pkg/model/modelutil/vector"
+	"github.com/ynqa/wego/pkg/sweep"
+	"github.com/ynqa/wego/pkg/util/remote"
+)
+
+var (
+	configFile string
+	force      bool
+)
+
+// Config is the format of config file for sweep, in YAML by the extension of .yaml or .yml and otherwise in JSON, e.g.
+//
+//	{
+//	  "model": "word2vec",
+//	  "input": "text8",
+//	  "benchmark": "wordsim353.txt",
+//	  "output": "results.csv",
+//	  "search": "grid",
+//	  "parallel": 2,
+//	  "flags": {"iter": 1},
+//	  "params": {"dim": [50, 100], "window": [5, 10]}
+//	}
+//
+// flags and params are specified by the flag names of the model sub-command.
+type Config struct {
+	Model      string                   `json:"model" yaml:"model"`
+	Input      string                   `json:"input" yaml:"input"`
+	Benchmark  string                   `json:"benchmark" yaml:"benchmark"`
+	Output     string                   `json:"output" yaml:"output"`
+	Search     sweep.SearchType         `json:"search" yaml:"search"`
+	Trials     int                      `json:"trials" yaml:"trials"`
+	Parallel   int                      `json:"parallel" yaml:"parallel"`
+	Seed       int64                    `json:"seed" yaml:"seed"`
+	VectorType vector.Type              `json:"vec-type" yaml:"vec-type"`
+	Flags      map[string]interface{}   `json:"flags" yaml:"flags"`
+	Params     map[string][]interface{} `json:"params" yaml:"params"`
+}
+
+// Space returns the space of the params.
+func (c Config) Space() sweep.Space {
+	space := make(sweep.Space, len(c.Params))
+	for name, values := range c.Params {
+		for _, v := range values {
+			space[name] = append(space[name], cmdutil.FlagValue(v))
+		}
+	}
+	return space
+}
+
+func defaultConfig() Config {
+	return Config{
+		Output:     "sweep.csv",
+		Search:     sweep.Grid,
+		Parallel:   1,
+		VectorType: vector.Single,
+	}
+}
+
+func New() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sweep",
+		Short: "Search hyperparameters by training and evaluating models",
+		Example: "  wego sweep --config sweep.json\n" +
+			"  wego sweep --config sweep.yaml",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return execute()
+		},
+	}
+	cmd.Flags().StringVarP(&configFile, "config", "c", "sweep.json", "config file path for sweep, in YAML for .yaml or .yml and otherwise in JSON")
+	cmd.Flags().BoolVar(&force, "force", false, "overwrite the existing output file")
+	return cmd
+}
+
+func loadConfig(path string) (Config, error) {
+	conf := defaultConfig()
+	f, err := remote.OpenFile(path)
+	if err != nil {
+		return conf, err
+	}
+	defer f.Close()
+	if err := decodeConfig(f, path, &conf); err != nil {
+		return conf, errors.Wrapf(err, "failed to decode %s", path)
+	}
+	if conf.Input == "" || conf.Benchmark == "" {
+		return conf, errors.New("input and benchmark must be set in config")
+	} else if len(conf.Params) == 0 {
+		return conf, errors.New("params must be set in config")
+	}
+	return conf, nil
+}
+
+func decodeConfig(r io.Reader, path string, conf *Config) error {
+	if ext := filepath.Ext(path); strings.EqualFold(ext, ".yaml") || strings.EqualFold(ext, ".yml") {
+		return yaml.NewDecoder(r).Decode(conf)
+	}
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	return dec.Decode(conf)
+}
+
+func execute() error {
+	conf, err := loadConfig(configFile)
+	if err != nil {
+		return err
+	}
+	if err := remote.CheckOverwrite(conf.Output, force); err != nil {
+		return err
+	} else if !remote.Exists(conf.Input) {
+		return errors.Errorf("Not such a file %s", conf.Input)
+	}
+
+	bench, err := remote.OpenFile(conf.Benchmark)
+	if err != nil {
+		return err
+	}
+	defer bench.Close()
+	pairs, err := similarity.Load(bench)
+	if err != nil {
+		return err
+	}
+
+	space := conf.Space()
+	trials, err := sweep.Trials(space, conf.Search, conf.Trials, rand.New(rand.NewSource(conf.Seed)))
+	if err != nil {
+		return err
+	}
+	names := space.Names()
+	// the trials in parallel send their lines to the single writer, so that the lines don't interleave.
+	lines, done := make(chan string), make(chan struct{})
+	go func() {
+		defer close(done)
+		for line := range lines {
+			fmt.Println(line)
+		}
+	}()
+	results := sweep.Run(context.Background(), trials, conf.Parallel, func(ctx context.Context, trial sweep.Trial) (map[string]float64, error) {
+		metrics, err := trainAndEvaluate(ctx, conf, trial, pairs)
+		lines <- describe(len(trials), names, trial, metrics, err)
+		return metrics, err
+	})
+	close(lines)
+	<-done
+
+	return remote.WriteAtomic(conf.Output, func(w io.Writer) error {
+		return sweep.WriteCSV(w, names, results)
+	})
+}
+
+func trainAndEvaluate(ctx context.Context, conf Config, trial sweep.Trial, pairs []similarity.Pair) (map[string]float64, error) {
+	flags := make(map[string]string, len(conf.Flags)+len(trial.Params))
+	for k, v := range conf.Flags {
+		flags[k] = cmdutil.FlagValue(v)
+	}
+	for k, v := range trial.Params {
+		flags[k] = v
+	}
+	mod, err := cmdutil.NewModel(conf.Model, flags)
+	if err != nil {
+		return nil, err
+	}
+	defer model.Close(mod)
+
+	input, err := remote.OpenFile(conf.Input)
+	if err != nil {
+		return nil, err
+	}
+	defer input.Close()
+	if err := mod.Train(ctx, input); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := mod.Save(&buf, conf.VectorType); err != nil {
+		return nil, err
+	}
+	embs, err := embedding.Load(&buf)
+	if err != nil {
+		return nil, err
+	}
+	report, err := similarity.Evaluate(embs, pairs)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]float64{
+		"spearman": report.Spearman,
+		"coverage": float64(report.Found) / float64(report.Total),
+	}, nil
+}
+
+func describe(size int, names []string, trial sweep.Trial, metrics map[string]float64, err error) string {
+	params := make([]string, len(names))
+	for i, name := range names {
+		params[i] = fmt.Sprintf("%s=%s", name, trial.Params[name])
+	}
+	if err != nil {
+		return fmt.Sprintf("trial %d/%d %s: %v", trial.ID+1, size, strings.Join(params, " "), err)
+	}
+	return fmt.Sprintf("trial %d/%d %s: spearman=%f coverage=%f",
+		trial.ID+1, size, strings.Join(params, " "), metrics["spearman"], metrics["coverage"])
+}
@@ -0,0 +1,53 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package sweep
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/sweep"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sweep")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"sweep.json": `{"model": "word2vec", "input": "text8", "benchmark": "ws.txt", "flags": {"iter": 1, "alpha": 0.05},
+			"params": {"dim": [50, 100], "model": ["skipgram", "cbow"]}}`,
+		"sweep.yaml": "model: word2vec\ninput: text8\nbenchmark: ws.txt\nflags:\n  iter: 1\n  alpha: 0.05\n" +
+			"params:\n  dim: [50, 100]\n  model: [skipgram, cbow]\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+		conf, err := loadConfig(path)
+		assert.NoError(t, err, name)
+		assert.Equal(t, "word2vec", conf.Model, name)
+		assert.Equal(t, "sweep.csv", conf.Output, name)
+		assert.Equal(t, sweep.Grid, conf.Search, name)
+		assert.Equal(t, sweep.Space{"dim": {"50", "100"}, "model": {"skipgram", "cbow"}}, conf.Space(), name)
+	}
+
+	path := filepath.Join(dir, "missing.yml")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("model: word2vec\n"), 0644))
+	_, err = loadConfig(path)
+	assert.Error(t, err)
+}
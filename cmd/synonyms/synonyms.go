@@ -0,0 +1,98 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package synonyms
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/cmd/query/cmdutil"
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/search/synonym"
+	"github.com/ynqa/wego/pkg/util/remote"
+)
+
+const (
+	defaultOutputFile = "synonyms.txt"
+)
+
+var (
+	force      bool
+	inputFile  string
+	outputFile string
+	posFile    string
+)
+
+func New() *cobra.Command {
+	opts := synonym.DefaultOptions()
+	cmd := &cobra.Command{
+		Use:   "synonyms",
+		Short: "Generate the synonyms file for Solr or Elasticsearch",
+		Example: "  wego synonyms -i example/word_vectors.txt -o synonyms.txt --threshold 0.7 --max 5\n" +
+			"  wego synonyms -i example/word_vectors.txt --pos-file tags.txt --pos NOUN,ADJ --min-rank 100 --max-rank 50000",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return execute(opts)
+		},
+	}
+	cmdutil.AddInputFlags(cmd, &inputFile)
+	cmd.Flags().StringVarP(&outputFile, "output", "o", defaultOutputFile, "output file path to save synonyms, - for stdout")
+	cmd.Flags().BoolVar(&force, "force", false, "overwrite the existing output file")
+	cmd.Flags().StringVar(&posFile, "pos-file", "", "file path for the lines of 'word tag' to restrict the synonyms to the same part-of-speech")
+	addOptionsFlags(cmd, &opts)
+	return cmd
+}
+
+// addOptionsFlags binds opts to the flags of cmd, whose defaults are synonym.DefaultOptions().
+func addOptionsFlags(cmd *cobra.Command, opts *synonym.Options) {
+	def := synonym.DefaultOptions()
+	cmd.Flags().StringVar(&opts.Format, "format", def.Format, fmt.Sprintf("synonyms format. One of %s|%s", synonym.Explicit, synonym.Equivalent))
+	cmd.Flags().IntVar(&opts.Goroutines, "goroutines", def.Goroutines, "number of goroutine")
+	cmd.Flags().IntVar(&opts.Max, "max", def.Max, "upper limit of the synonyms for each word")
+	cmd.Flags().IntVar(&opts.MaxRank, "max-rank", def.MaxRank, "upper limit of the frequency rank, i.e. the line number in the word vectors (0 means unlimited)")
+	cmd.Flags().IntVar(&opts.MinRank, "min-rank", def.MinRank, "lower limit of the frequency rank, e.g. to skip the stop words")
+	cmd.Flags().StringSliceVar(&opts.Tags, "pos", nil, "part-of-speech tags to keep (for --pos-file only), e.g. NOUN,ADJ (default all tags)")
+	cmd.Flags().Float64Var(&opts.Threshold, "threshold", def.Threshold, "lower limit of the cosine similarity of the synonyms")
+}
+
+func execute(opts synonym.Options) error {
+	if err := remote.CheckOverwrite(outputFile, force); err != nil {
+		return err
+	}
+	var tags map[string]string
+	if posFile != "" {
+		f, err := remote.OpenFile(posFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if tags, err = synonym.LoadTags(f); err != nil {
+			return err
+		}
+	}
+	input, err := remote.OpenFile(inputFile)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	embs, err := embedding.Load(input)
+	if err != nil {
+		return err
+	}
+	return remote.WriteAtomic(outputFile, func(w io.Writer) error {
+		return synonym.Write(w, embs, tags, opts)
+	})
+}
@@ -15,6 +15,7 @@
 package main
 
 import (
+	"context"
 	"os"
 
 	"github.com/ynqa/wego/pkg/model/modelutil/vector"
@@ -27,7 +28,7 @@ func main() {
 		word2vec.Model(word2vec.Cbow),
 		word2vec.Optimizer(word2vec.NegativeSampling),
 		word2vec.NegativeSampleSize(5),
-		word2vec.Verbose(),
+		word2vec.Verbose(true),
 	)
 	if err != nil {
 		// failed to create word2vec.
@@ -35,7 +36,7 @@ func main() {
 
 	input, _ := os.Open("text8")
 	defer input.Close()
-	if err = model.Train(input); err != nil {
+	if err = model.Train(context.Background(), input); err != nil {
 		// failed to train.
 	}
 
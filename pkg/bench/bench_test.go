@@ -0,0 +1,111 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bench
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/model"
+	"github.com/ynqa/wego/pkg/model/glove"
+	"github.com/ynqa/wego/pkg/model/lexvec"
+	"github.com/ynqa/wego/pkg/model/word2vec"
+)
+
+func TestGenerate(t *testing.T) {
+	opts := Options{
+		Exponent:  1.5,
+		LineWords: 4,
+		Seed:      1,
+		Tokens:    10,
+		Vocab:     5,
+	}
+	var b1, b2 bytes.Buffer
+	assert.NoError(t, Generate(&b1, opts))
+	assert.NoError(t, Generate(&b2, opts))
+	assert.Equal(t, b1.String(), b2.String())
+
+	lines := strings.Split(strings.TrimSuffix(b1.String(), "\n"), "\n")
+	assert.Len(t, lines, 3)
+	assert.Len(t, strings.Fields(b1.String()), 10)
+
+	opts.Exponent = 1
+	assert.Error(t, Generate(&b1, opts))
+}
+
+const benchTokens = 200000
+
+func BenchmarkTrain(b *testing.B) {
+	var corpus bytes.Buffer
+	opts := DefaultOptions()
+	opts.Tokens, opts.Vocab = benchTokens, 10000
+	if err := Generate(&corpus, opts); err != nil {
+		b.Fatal(err)
+	}
+
+	testCases := []struct {
+		name string
+		new  func() (model.Model, error)
+	}{
+		{
+			name: "word2vec/skipgram",
+			new: func() (model.Model, error) {
+				return word2vec.New(word2vec.Iter(1), word2vec.Model(word2vec.SkipGram))
+			},
+		},
+		{
+			name: "word2vec/cbow",
+			new: func() (model.Model, error) {
+				return word2vec.New(word2vec.Iter(1), word2vec.Model(word2vec.Cbow))
+			},
+		},
+		{
+			name: "glove",
+			new: func() (model.Model, error) {
+				return glove.New(glove.Iter(1))
+			},
+		},
+		{
+			name: "lexvec",
+			new: func() (model.Model, error) {
+				return lexvec.New(lexvec.Iter(1))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		b.Run(tc.name, func(b *testing.B) {
+			var elapsed time.Duration
+			for i := 0; i < b.N; i++ {
+				mod, err := tc.new()
+				if err != nil {
+					b.Fatal(err)
+				}
+				start := time.Now()
+				if err := mod.Train(context.Background(), bytes.NewReader(corpus.Bytes())); err != nil {
+					b.Fatal(err)
+				}
+				elapsed += time.Since(start)
+			}
+			b.ReportMetric(float64(benchTokens*b.N)/elapsed.Seconds(), "tokens/s")
+			b.ReportMetric(float64(PeakRSS())/(1<<20), "peak-rss-MB")
+		})
+	}
+}
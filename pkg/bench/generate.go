@@ -0,0 +1,75 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bench
+
+import (
+	"bufio"
+	"io"
+	"math/rand"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	defaultExponent  = 1.1
+	defaultLineWords = 1000
+	defaultSeed      = int64(1)
+	defaultTokens    = 10000000
+	defaultVocab     = 100000
+)
+
+// Options is for the synthetic corpus whose word frequencies follow Zipf's law.
+type Options struct {
+	Exponent  float64
+	LineWords int
+	Seed      int64
+	Tokens    int
+	Vocab     int
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Exponent:  defaultExponent,
+		LineWords: defaultLineWords,
+		Seed:      defaultSeed,
+		Tokens:    defaultTokens,
+		Vocab:     defaultVocab,
+	}
+}
+
+// Generate writes the corpus separated by space, the rank r word is named `w<r>`.
+func Generate(w io.Writer, opts Options) error {
+	if opts.Exponent <= 1 {
+		return errors.Errorf("exponent must be > 1, got %v", opts.Exponent)
+	}
+	if opts.Vocab <= 0 || opts.LineWords <= 0 {
+		return errors.Errorf("vocab and line-words must be positive, got %d and %d", opts.Vocab, opts.LineWords)
+	}
+	zipf := rand.NewZipf(rand.New(rand.NewSource(opts.Seed)), opts.Exponent, 1, uint64(opts.Vocab-1))
+	buf := bufio.NewWriter(w)
+	for i := 0; i < opts.Tokens; i++ {
+		sep := byte(' ')
+		if (i+1)%opts.LineWords == 0 || i == opts.Tokens-1 {
+			sep = '\n'
+		}
+		buf.WriteByte('w')
+		buf.WriteString(strconv.FormatUint(zipf.Uint64(), 10))
+		if err := buf.WriteByte(sep); err != nil {
+			return err
+		}
+	}
+	return buf.Flush()
+}
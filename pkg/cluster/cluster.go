@@ -0,0 +1,82 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cluster is the clustering of the vectors, shared by the index and the evaluations.
+package cluster
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/ynqa/wego/pkg/vector"
+)
+
+// Spherical runs iter iterations of spherical k-means on the unit rows, where the rows are assigned to the nearest
+// centroids by the dot product and the centroids are the unit sums of their rows. The first k rows are the initial
+// centroids, so the rows should be shuffled, and the empty cluster is restarted at the random row of rng.
+// each runs fn for the rows in the iteration it, e.g. in parallel, and the nil runs them in order.
+func Spherical(rows [][]float64, k, iter int, rng *rand.Rand, each func(it, n int, fn func(i int))) [][]float64 {
+	if each == nil {
+		each = func(_, n int, fn func(i int)) {
+			for i := 0; i < n; i++ {
+				fn(i)
+			}
+		}
+	}
+	centroids := make([][]float64, k)
+	for c := range centroids {
+		centroids[c] = rows[c]
+	}
+	assign := make([]int, len(rows))
+	for it := 0; it < iter; it++ {
+		each(it+1, len(rows), func(i int) {
+			assign[i] = Nearest(centroids, rows[i])
+		})
+		sums := make([][]float64, k)
+		for c := range sums {
+			sums[c] = make([]float64, len(rows[0]))
+		}
+		for i, c := range assign {
+			vector.Add(sums[c], rows[i])
+		}
+		for c, sum := range sums {
+			if vector.Norm(sum) == 0 {
+				centroids[c] = rows[rng.Intn(len(rows))]
+				continue
+			}
+			centroids[c] = vector.Unit(sum)
+		}
+	}
+	return centroids
+}
+
+// Nearest returns the centroid of the largest dot product with vec.
+func Nearest(centroids [][]float64, vec []float64) int {
+	best, max := 0, math.Inf(-1)
+	for c, centroid := range centroids {
+		if d := vector.Dot(centroid, vec); d > max {
+			best, max = c, d
+		}
+	}
+	return best
+}
+
+// Assign returns the nearest centroids of the rows.
+func Assign(centroids, rows [][]float64) []int {
+	assign := make([]int, len(rows))
+	for i, row := range rows {
+		assign[i] = Nearest(centroids, row)
+	}
+	return assign
+}
@@ -0,0 +1,37 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/vector"
+)
+
+func TestSpherical(t *testing.T) {
+	rows := [][]float64{
+		vector.Unit([]float64{1, 0.1}),
+		vector.Unit([]float64{0.1, 1}),
+		vector.Unit([]float64{1, -0.1}),
+		vector.Unit([]float64{-0.1, 1}),
+	}
+	centroids := Spherical(rows, 2, 5, rand.New(rand.NewSource(1)), nil)
+	assert.InDeltaSlice(t, []float64{1, 0}, centroids[0], 1e-9)
+	assert.InDeltaSlice(t, []float64{0, 1}, centroids[1], 1e-9)
+	assert.Equal(t, []int{0, 1, 0, 1}, Assign(centroids, rows))
+}
@@ -0,0 +1,238 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bpe
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/util/fileutil"
+)
+
+const (
+	// EndOfWord is appended to the last character of words while learning,
+	// so the units at the ends of words are distinguished from the others.
+	EndOfWord = "</w>"
+	// Continuation is appended to the units except the last one of words in the encoded text, as subword-nmt.
+	Continuation = "@@"
+
+	header = "#version: 0.2"
+)
+
+type Pair struct {
+	Left, Right string
+}
+
+// BPE encodes the words into the subword units by applying the merges in order.
+type BPE struct {
+	Merges []Pair
+	ranks  map[Pair]int
+}
+
+func New(merges []Pair) *BPE {
+	ranks := make(map[Pair]int, len(merges))
+	for i, m := range merges {
+		if _, ok := ranks[m]; !ok {
+			ranks[m] = i
+		}
+	}
+	return &BPE{
+		Merges: merges,
+		ranks:  ranks,
+	}
+}
+
+// Learn learns the merges from the words in r until the number of the units, i.e. the characters and the merges,
+// reaches size, or no pair appears twice. The ties of the frequency are broken by the pairs to be deterministic.
+func Learn(r io.Reader, size int, toLower bool) (*BPE, error) {
+	freq := make(map[string]int)
+	s := fileutil.NewScanner(r, bufio.ScanWords)
+	for s.Scan() {
+		word := s.Text()
+		if toLower {
+			word = strings.ToLower(word)
+		}
+		freq[word]++
+	}
+	if err := s.Err(); err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "failed to scan")
+	}
+
+	words := make([]string, 0, len(freq))
+	for word := range freq {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+	symbols := make([][]string, len(words))
+	units := make(map[string]struct{})
+	for i, word := range words {
+		symbols[i] = split(word)
+		for _, u := range symbols[i] {
+			units[u] = struct{}{}
+		}
+	}
+
+	var merges []Pair
+	for len(units) < size {
+		counts := make(map[Pair]int)
+		for i, syms := range symbols {
+			for j := 0; j+1 < len(syms); j++ {
+				counts[Pair{Left: syms[j], Right: syms[j+1]}] += freq[words[i]]
+			}
+		}
+		var (
+			best  Pair
+			count int
+		)
+		for p, c := range counts {
+			if c > count || (c == count && less(p, best)) {
+				best, count = p, c
+			}
+		}
+		if count < 2 {
+			break
+		}
+		for i, syms := range symbols {
+			symbols[i] = merge(syms, best)
+		}
+		merges = append(merges, best)
+		units[best.Left+best.Right] = struct{}{}
+	}
+	return New(merges), nil
+}
+
+// Load reads the merges file of the lines of 'left right', which Write writes.
+func Load(r io.Reader) (*BPE, error) {
+	var merges []Pair
+	s := fileutil.NewScanner(r, bufio.ScanLines)
+	for n := 1; s.Scan(); n++ {
+		line := s.Text()
+		if line == "" || strings.HasPrefix(line, "#version") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, errors.Errorf("line %d must be 'left right', got %q", n, line)
+		}
+		merges = append(merges, Pair{Left: fields[0], Right: fields[1]})
+	}
+	if err := s.Err(); err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "failed to scan")
+	}
+	return New(merges), nil
+}
+
+// Write writes the merges in the format of subword-nmt, which the other tools apply.
+func (b *BPE) Write(w io.Writer) error {
+	buf := bufio.NewWriter(w)
+	fmt.Fprintln(buf, header)
+	for _, m := range b.Merges {
+		fmt.Fprintf(buf, "%s %s\n", m.Left, m.Right)
+	}
+	return buf.Flush()
+}
+
+// Encode returns the units of the word, where the units except the last one end with Continuation.
+func (b *BPE) Encode(word string) []string {
+	if word == "" {
+		return nil
+	}
+	syms := split(word)
+	for len(syms) > 1 {
+		rank := -1
+		var best Pair
+		for j := 0; j+1 < len(syms); j++ {
+			p := Pair{Left: syms[j], Right: syms[j+1]}
+			if r, ok := b.ranks[p]; ok && (rank < 0 || r < rank) {
+				best, rank = p, r
+			}
+		}
+		if rank < 0 {
+			break
+		}
+		syms = merge(syms, best)
+	}
+	last := len(syms) - 1
+	for i := range syms[:last] {
+		syms[i] += Continuation
+	}
+	syms[last] = strings.TrimSuffix(syms[last], EndOfWord)
+	return syms
+}
+
+// EncodeText writes the lines of r with the words encoded into the units separated by spaces.
+func (b *BPE) EncodeText(w io.Writer, r io.Reader, toLower bool) error {
+	cache := make(map[string]string)
+	buf := bufio.NewWriter(w)
+	s := fileutil.NewScanner(r, bufio.ScanLines)
+	for s.Scan() {
+		for i, word := range strings.Fields(s.Text()) {
+			if toLower {
+				word = strings.ToLower(word)
+			}
+			units, ok := cache[word]
+			if !ok {
+				units = strings.Join(b.Encode(word), " ")
+				cache[word] = units
+			}
+			if i > 0 {
+				buf.WriteByte(' ')
+			}
+			buf.WriteString(units)
+		}
+		if err := buf.WriteByte('\n'); err != nil {
+			return err
+		}
+	}
+	if err := s.Err(); err != nil && err != io.EOF {
+		return errors.Wrap(err, "failed to scan")
+	}
+	return buf.Flush()
+}
+
+// split splits the word into the characters, and the last one ends with EndOfWord.
+func split(word string) []string {
+	syms := make([]string, 0, len(word))
+	for _, c := range word {
+		syms = append(syms, string(c))
+	}
+	syms[len(syms)-1] += EndOfWord
+	return syms
+}
+
+func merge(syms []string, p Pair) []string {
+	merged := syms[:0:0]
+	for j := 0; j < len(syms); j++ {
+		if j+1 < len(syms) && syms[j] == p.Left && syms[j+1] == p.Right {
+			merged = append(merged, p.Left+p.Right)
+			j++
+			continue
+		}
+		merged = append(merged, syms[j])
+	}
+	return merged
+}
+
+func less(a, b Pair) bool {
+	if a.Left != b.Left {
+		return a.Left < b.Left
+	}
+	return a.Right < b.Right
+}
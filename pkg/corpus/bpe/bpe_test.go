@@ -0,0 +1,62 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bpe
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLearn(t *testing.T) {
+	b, err := Learn(strings.NewReader("low low low lower lowest Newest newest"), 12, true)
+	assert.NoError(t, err)
+	// 9 characters and 3 merges, where the ties of lo w</w>, we s and s t</w> are broken by the pairs.
+	assert.Equal(t, []Pair{
+		{Left: "l", Right: "o"},
+		{Left: "w", Right: "e"},
+		{Left: "lo", Right: "w</w>"},
+	}, b.Merges)
+
+	assert.Equal(t, []string{"lo@@", "we@@", "s@@", "t"}, b.Encode("lowest"))
+	assert.Equal(t, []string{"low"}, b.Encode("low"))
+	assert.Equal(t, []string{"x"}, b.Encode("x"))
+	assert.Nil(t, b.Encode(""))
+}
+
+func TestWriteLoad(t *testing.T) {
+	b := New([]Pair{{Left: "l", Right: "o"}, {Left: "lo", Right: "w</w>"}})
+	var buf bytes.Buffer
+	assert.NoError(t, b.Write(&buf))
+	assert.Equal(t, "#version: 0.2\nl o\nlo w</w>\n", buf.String())
+
+	loaded, err := Load(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, b.Merges, loaded.Merges)
+	assert.Equal(t, []string{"low"}, loaded.Encode("low"))
+	assert.Equal(t, []string{"lo@@", "w@@", "s"}, loaded.Encode("lows"))
+
+	_, err = Load(strings.NewReader("l o w\n"))
+	assert.Error(t, err)
+}
+
+func TestEncodeText(t *testing.T) {
+	b := New([]Pair{{Left: "l", Right: "o"}, {Left: "lo", Right: "w</w>"}})
+	var buf bytes.Buffer
+	assert.NoError(t, b.EncodeText(&buf, strings.NewReader("Low slow\nlo\n"), true))
+	assert.Equal(t, "low s@@ low\nl@@ o\n", buf.String())
+}
@@ -0,0 +1,153 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conllu
+
+// inspired by
+// - https://universaldependencies.org/format.html
+// - Dependency-Based Word Embeddings (Levy and Goldberg, 2014)
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/corpus/pairs"
+	"github.com/ynqa/wego/pkg/util/fileutil"
+)
+
+type Token struct {
+	ID     int
+	Form   string
+	Lemma  string
+	UPOS   string
+	Head   int
+	Deprel string
+}
+
+// ReadSentences calls fn for each sentence, skipping comments, multiword tokens and empty nodes.
+func ReadSentences(r io.Reader, fn func([]Token) error) error {
+	s := fileutil.NewScanner(r, bufio.ScanLines)
+	var (
+		sent []Token
+		line int
+	)
+	flush := func() error {
+		if len(sent) == 0 {
+			return nil
+		}
+		err := fn(sent)
+		sent = nil
+		return err
+	}
+	for s.Scan() {
+		line++
+		text := strings.TrimRight(s.Text(), "\r")
+		if text == "" {
+			if err := flush(); err != nil {
+				return err
+			}
+			continue
+		}
+		if strings.HasPrefix(text, "#") {
+			continue
+		}
+		cols := strings.Split(text, "\t")
+		if len(cols) < 8 {
+			return errors.Errorf("line %d: expected 10 tab-separated columns, got %d", line, len(cols))
+		}
+		if strings.ContainsAny(cols[0], "-.") {
+			continue
+		}
+		id, err := strconv.Atoi(cols[0])
+		if err != nil {
+			return errors.Wrapf(err, "line %d: invalid ID", line)
+		}
+		head, err := strconv.Atoi(cols[6])
+		if err != nil {
+			return errors.Wrapf(err, "line %d: invalid HEAD", line)
+		}
+		sent = append(sent, Token{
+			ID:     id,
+			Form:   cols[1],
+			Lemma:  cols[2],
+			UPOS:   cols[3],
+			Head:   head,
+			Deprel: cols[7],
+		})
+	}
+	if err := s.Err(); err != nil {
+		return err
+	}
+	return flush()
+}
+
+// Contexts calls fn with the (word, dependency context) pairs of the sentence.
+// A modifier m attached to the head h by the relation r gives the context "m/r" to h,
+// and the inverse context "h/r-1" to m.
+func Contexts(sent []Token, toLower bool, fn func(word, ctx string) error) error {
+	form := func(t Token) string {
+		if toLower {
+			return strings.ToLower(t.Form)
+		}
+		return t.Form
+	}
+	for _, t := range sent {
+		if t.Head <= 0 || t.Head > len(sent) {
+			continue
+		}
+		head := sent[t.Head-1]
+		if err := fn(form(head), form(t)+"/"+t.Deprel); err != nil {
+			return err
+		}
+		if err := fn(form(t), form(head)+"/"+t.Deprel+"-1"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadPairs reads the (word, dependency context) pairs.
+func LoadPairs(r io.Reader, toLower bool) (*pairs.Pairs, error) {
+	p := pairs.New()
+	if err := ReadSentences(r, func(sent []Token) error {
+		return Contexts(sent, toLower, func(word, ctx string) error {
+			p.Add(word, ctx)
+			return nil
+		})
+	}); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// WriteText writes the forms of the sentences as the plain corpus, one sentence per line.
+func WriteText(w io.Writer, r io.Reader) error {
+	buf := bufio.NewWriter(w)
+	if err := ReadSentences(r, func(sent []Token) error {
+		for i, t := range sent {
+			if i > 0 {
+				buf.WriteByte(' ')
+			}
+			buf.WriteString(t.Form)
+		}
+		return buf.WriteByte('\n')
+	}); err != nil {
+		return err
+	}
+	return buf.Flush()
+}
@@ -0,0 +1,74 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conllu
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const doc = `# text = Australian scientist discovers star
+1	Australian	australian	ADJ	_	_	2	amod	_	_
+2	scientist	scientist	NOUN	_	_	3	nsubj	_	_
+3	discovers	discover	VERB	_	_	0	root	_	_
+4-5	star's	_	_	_	_	_	_	_	_
+4	star	star	NOUN	_	_	3	obj	_	_
+
+1	Hi	hi	INTJ	_	_	0	root	_	_
+`
+
+func TestReadSentences(t *testing.T) {
+	var sents [][]Token
+	assert.NoError(t, ReadSentences(strings.NewReader(doc), func(sent []Token) error {
+		sents = append(sents, sent)
+		return nil
+	}))
+	assert.Len(t, sents, 2)
+	assert.Len(t, sents[0], 4)
+	assert.Equal(t, Token{ID: 4, Form: "star", Lemma: "star", UPOS: "NOUN", Head: 3, Deprel: "obj"}, sents[0][3])
+
+	assert.Error(t, ReadSentences(strings.NewReader("1\tbroken\n"), func([]Token) error { return nil }))
+}
+
+func TestLoadPairs(t *testing.T) {
+	p, err := LoadPairs(strings.NewReader(doc), true)
+	assert.NoError(t, err)
+	assert.Equal(t, 6, p.Len())
+
+	var pairs []string
+	for i := 0; i < p.Len(); i++ {
+		w, c := p.Pair(i)
+		word, _ := p.Dictionary().Word(w)
+		ctx, _ := p.ContextDictionary().Word(c)
+		pairs = append(pairs, word+" "+ctx)
+	}
+	assert.Equal(t, []string{
+		"scientist australian/amod",
+		"australian scientist/amod-1",
+		"discovers scientist/nsubj",
+		"scientist discovers/nsubj-1",
+		"discovers star/obj",
+		"star discovers/obj-1",
+	}, pairs)
+}
+
+func TestWriteText(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, WriteText(&buf, strings.NewReader(doc)))
+	assert.Equal(t, "Australian scientist discovers star\nHi\n", buf.String())
+}
@@ -16,7 +16,6 @@ package co
 
 import (
 	"fmt"
-	"math"
 
 	"github.com/pkg/errors"
 
@@ -28,48 +27,102 @@ type CountType = string
 const (
 	Increment CountType = "inc"
 	Proximity CountType = "prox"
+	// Linear weights by (window-distance+1)/window, which is the expectation of the dynamic window of word2vec.
+	Linear CountType = "linear"
 )
 
 func invalidCountTypeError(typ CountType) error {
-	return fmt.Errorf("invalid relation type: %s not in %s|%s", typ, Increment, Proximity)
+	return fmt.Errorf("invalid relation type: %s not in %s|%s|%s", typ, Increment, Proximity, Linear)
 }
 
 type Cooccurrence struct {
-	typ CountType
+	typ       CountType
+	window    int
+	symmetric bool
 
 	ma map[uint64]float64
+
+	// the runs of the counts spilled into spillDir, see Spill.
+	spillDir   string
+	spillCells int
+	runs       []string
+	err        error
+}
+
+// New counts both left and right contexts, keyed by the unordered pair of words.
+// window is the size of the context window for Linear.
+func New(typ CountType, window int) (*Cooccurrence, error) {
+	return newCooccurrence(typ, window, true)
 }
 
-func New(typ CountType) (*Cooccurrence, error) {
-	if typ != Increment && typ != Proximity {
+// NewAsymmetric counts only left contexts, keyed by the ordered pair of (word, left context).
+func NewAsymmetric(typ CountType, window int) (*Cooccurrence, error) {
+	return newCooccurrence(typ, window, false)
+}
+
+func newCooccurrence(typ CountType, window int, symmetric bool) (*Cooccurrence, error) {
+	if typ != Increment && typ != Proximity && typ != Linear {
 		return nil, invalidCountTypeError(typ)
+	} else if typ == Linear && window <= 0 {
+		return nil, errors.Errorf("window must be > 0 for %s, got %d", Linear, window)
 	}
 	return &Cooccurrence{
-		typ: typ,
+		typ:       typ,
+		window:    window,
+		symmetric: symmetric,
 
 		ma: make(map[uint64]float64),
 	}, nil
 }
 
+// EncodedMatrix returns the counts in memory, which are all of them unless they are spilled, see Spill.
 func (c *Cooccurrence) EncodedMatrix() map[uint64]float64 {
 	return c.ma
 }
 
-func (c *Cooccurrence) Add(left, right int) error {
-	enc := encode.EncodeBigram(uint64(left), uint64(right))
+func (c *Cooccurrence) Symmetric() bool {
+	return c.symmetric
+}
+
+// Add counts the right word which occurs dist words after the left word.
+func (c *Cooccurrence) Add(left, right, dist int) error {
+	var enc uint64
+	if c.symmetric {
+		enc = encode.EncodeBigram(uint64(left), uint64(right))
+	} else {
+		enc = encode.EncodeOrderedBigram(uint64(right), uint64(left))
+	}
 	var val float64
 	switch c.typ {
 	case Increment:
 		val = 1
 	case Proximity:
-		div := left - right
-		if div == 0 {
-			return errors.Errorf("Divide by zero on counting co-occurrence")
+		if dist <= 0 {
+			return errors.Errorf("Distance must be positive on counting co-occurrence, got %d", dist)
+		}
+		val = 1. / float64(dist)
+	case Linear:
+		if dist <= 0 || dist > c.window {
+			return errors.Errorf("Distance must be in [1, %d] on counting co-occurrence, got %d", c.window, dist)
 		}
-		val = 1. / math.Abs(float64(div))
+		val = float64(c.window-dist+1) / float64(c.window)
 	default:
 		return invalidCountTypeError(c.typ)
 	}
 	c.ma[enc] += val
-	return nil
+	return c.spill()
+}
+
+// AddCount adds the precomputed count of the context around the word,
+// whose order is kept only for the asymmetric co-occurrence.
+func (c *Cooccurrence) AddCount(word, context int, count float64) {
+	if c.symmetric {
+		c.ma[encode.EncodeBigram(uint64(word), uint64(context))] += count
+	} else {
+		c.ma[encode.EncodeOrderedBigram(uint64(word), uint64(context))] += count
+	}
+	// the error is returned by Each.
+	if err := c.spill(); err != nil && c.err == nil {
+		c.err = err
+	}
 }
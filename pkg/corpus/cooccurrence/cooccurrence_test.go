@@ -18,16 +18,106 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/corpus/cooccurrence/encode"
 )
 
 func TestCooccurrence(t *testing.T) {
-	pw, err := New(Increment)
+	pw, err := New(Increment, 5)
 	assert.NoError(t, err)
-	assert.NoError(t, pw.Add(1, 2))
+	assert.NoError(t, pw.Add(1, 2, 1))
 	assert.Equal(t, 1, len(pw.EncodedMatrix()))
 }
 
+func TestCooccurrenceWithProximity(t *testing.T) {
+	pw, err := New(Proximity, 5)
+	assert.NoError(t, err)
+	assert.NoError(t, pw.Add(1, 2, 1))
+	assert.NoError(t, pw.Add(2, 1, 2))
+	assert.Equal(t, map[uint64]float64{
+		encode.EncodeBigram(1, 2): 1.5,
+	}, pw.EncodedMatrix())
+	assert.Error(t, pw.Add(1, 2, 0))
+}
+
+func TestCooccurrenceWithLinear(t *testing.T) {
+	pw, err := New(Linear, 4)
+	assert.NoError(t, err)
+	assert.NoError(t, pw.Add(1, 2, 1))
+	assert.NoError(t, pw.Add(2, 1, 4))
+	assert.Equal(t, map[uint64]float64{
+		encode.EncodeBigram(1, 2): 1.25,
+	}, pw.EncodedMatrix())
+	assert.Error(t, pw.Add(1, 2, 5))
+
+	_, err = New(Linear, 0)
+	assert.Error(t, err)
+}
+
+func TestAsymmetricCooccurrence(t *testing.T) {
+	pw, err := NewAsymmetric(Increment, 5)
+	assert.NoError(t, err)
+	assert.NoError(t, pw.Add(1, 2, 1))
+	assert.NoError(t, pw.Add(2, 1, 1))
+	assert.NoError(t, pw.Add(1, 2, 2))
+	assert.Equal(t, map[uint64]float64{
+		encode.EncodeOrderedBigram(2, 1): 2,
+		encode.EncodeOrderedBigram(1, 2): 1,
+	}, pw.EncodedMatrix())
+}
+
+func TestAddCount(t *testing.T) {
+	pw, err := New(Increment, 5)
+	assert.NoError(t, err)
+	pw.AddCount(1, 2, 3)
+	pw.AddCount(2, 1, 0.5)
+	assert.Equal(t, map[uint64]float64{
+		encode.EncodeBigram(1, 2): 3.5,
+	}, pw.EncodedMatrix())
+
+	pw, err = NewAsymmetric(Increment, 5)
+	assert.NoError(t, err)
+	pw.AddCount(1, 2, 3)
+	pw.AddCount(2, 1, 0.5)
+	assert.Equal(t, map[uint64]float64{
+		encode.EncodeOrderedBigram(1, 2): 3,
+		encode.EncodeOrderedBigram(2, 1): 0.5,
+	}, pw.EncodedMatrix())
+}
+
 func TestCooccurrenceWithInvalidCountType(t *testing.T) {
-	_, err := New(CountType("invalid type"))
+	_, err := New(CountType("invalid type"), 5)
 	assert.Error(t, err)
 }
+
+func TestSpill(t *testing.T) {
+	pw, err := New(Increment, 5)
+	assert.NoError(t, err)
+	pw.Spill(t.TempDir(), 2)
+	defer pw.Close()
+	assert.NoError(t, pw.Add(3, 4, 1))
+	assert.NoError(t, pw.Add(1, 2, 1))
+	assert.Equal(t, 0, len(pw.EncodedMatrix()))
+	assert.NoError(t, pw.Add(2, 1, 1))
+	assert.NoError(t, pw.Add(1, 3, 1))
+	assert.NoError(t, pw.Add(4, 3, 1))
+	assert.Equal(t, 1, len(pw.EncodedMatrix()))
+
+	var encs []uint64
+	counts := make(map[uint64]float64)
+	assert.NoError(t, pw.Each(func(enc uint64, count float64) error {
+		encs = append(encs, enc)
+		counts[enc] = count
+		return nil
+	}))
+	assert.Equal(t, []uint64{
+		encode.EncodeBigram(1, 2),
+		encode.EncodeBigram(1, 3),
+		encode.EncodeBigram(3, 4),
+	}, encs)
+	assert.Equal(t, map[uint64]float64{
+		encode.EncodeBigram(1, 2): 2,
+		encode.EncodeBigram(1, 3): 1,
+		encode.EncodeBigram(3, 4): 2,
+	}, counts)
+}
@@ -26,6 +26,11 @@ func EncodeBigram(l1, l2 uint64) uint64 {
 	}
 }
 
+// EncodeOrderedBigram creates id between two words, keeping their order.
+func EncodeOrderedBigram(l1, l2 uint64) uint64 {
+	return encode(l1, l2)
+}
+
 func encode(l1, l2 uint64) uint64 {
 	return l1 | (l2 << 32)
 }
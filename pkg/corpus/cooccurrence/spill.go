@@ -0,0 +1,192 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package co
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// cellSize is the bytes of (enc uint64, count float64) of the runs on disk.
+const cellSize = 8 + 8
+
+// Spill makes the counts be written into the sorted run in dir whenever cells of them are held
+// in memory, so that the counts larger than memory are merged by Each while reading the runs.
+// EncodedMatrix returns only the counts not spilled yet. The runs are removed by Close.
+func (c *Cooccurrence) Spill(dir string, cells int) {
+	c.spillDir, c.spillCells = dir, cells
+}
+
+// spill writes the counts in memory into the new run if they reach the limit.
+func (c *Cooccurrence) spill() error {
+	if c.spillCells <= 0 || len(c.ma) < c.spillCells {
+		return nil
+	}
+	f, err := ioutil.TempFile(c.spillDir, "wego-cooc")
+	if err != nil {
+		return err
+	}
+	c.runs = append(c.runs, f.Name())
+	w := bufio.NewWriter(f)
+	buf := make([]byte, cellSize)
+	for _, enc := range sortedKeys(c.ma) {
+		binary.LittleEndian.PutUint64(buf, enc)
+		binary.LittleEndian.PutUint64(buf[8:], math.Float64bits(c.ma[enc]))
+		if _, err := w.Write(buf); err != nil {
+			f.Close()
+			return errors.Wrap(err, "failed to spill co-occurrence")
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return errors.Wrap(err, "failed to spill co-occurrence")
+	}
+	c.ma = make(map[uint64]float64)
+	return f.Close()
+}
+
+func sortedKeys(ma map[uint64]float64) []uint64 {
+	keys := make([]uint64, 0, len(ma))
+	for enc := range ma {
+		keys = append(keys, enc)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// Each calls fn for each cell in the ascending order of the encoded pairs, where the counts of the
+// same pair in the runs and in memory are summed.
+func (c *Cooccurrence) Each(fn func(enc uint64, count float64) error) error {
+	if c.err != nil {
+		return c.err
+	}
+	h := make(cursors, 0, len(c.runs)+1)
+	defer func() {
+		for _, cur := range h {
+			cur.close()
+		}
+	}()
+	for _, run := range c.runs {
+		f, err := os.Open(run)
+		if err != nil {
+			return err
+		}
+		cur := &cursor{file: f, r: bufio.NewReader(f), buf: make([]byte, cellSize)}
+		if ok, err := cur.next(); err != nil {
+			cur.close()
+			return err
+		} else if ok {
+			h = append(h, cur)
+		} else {
+			cur.close()
+		}
+	}
+	mem := &cursor{keys: sortedKeys(c.ma), ma: c.ma}
+	if ok, _ := mem.next(); ok {
+		h = append(h, mem)
+	}
+	heap.Init(&h)
+	for len(h) > 0 {
+		enc, count := h[0].enc, 0.
+		for len(h) > 0 && h[0].enc == enc {
+			count += h[0].count
+			if ok, err := h[0].next(); err != nil {
+				return err
+			} else if ok {
+				heap.Fix(&h, 0)
+			} else {
+				heap.Pop(&h).(*cursor).close()
+			}
+		}
+		if err := fn(enc, count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close removes the runs spilled into the disk.
+func (c *Cooccurrence) Close() error {
+	var err error
+	for _, run := range c.runs {
+		if e := os.Remove(run); e != nil && err == nil {
+			err = e
+		}
+	}
+	c.runs = nil
+	return err
+}
+
+// cursor reads the cells of the run in the file, or of the sorted keys in memory.
+type cursor struct {
+	enc   uint64
+	count float64
+
+	file *os.File
+	r    *bufio.Reader
+	buf  []byte
+
+	keys []uint64
+	ma   map[uint64]float64
+}
+
+func (c *cursor) next() (bool, error) {
+	if c.file == nil {
+		if len(c.keys) == 0 {
+			return false, nil
+		}
+		c.enc, c.count, c.keys = c.keys[0], c.ma[c.keys[0]], c.keys[1:]
+		return true, nil
+	}
+	if _, err := io.ReadFull(c.r, c.buf); err == io.EOF {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Wrapf(err, "failed to read %s", c.file.Name())
+	}
+	c.enc = binary.LittleEndian.Uint64(c.buf)
+	c.count = math.Float64frombits(binary.LittleEndian.Uint64(c.buf[8:]))
+	return true, nil
+}
+
+func (c *cursor) close() {
+	if c.file != nil {
+		c.file.Close()
+		c.file = nil
+	}
+}
+
+// cursors is the min-heap of the cursors by the current pair.
+type cursors []*cursor
+
+func (h cursors) Len() int            { return len(h) }
+func (h cursors) Less(i, j int) bool  { return h[i].enc < h[j].enc }
+func (h cursors) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *cursors) Push(x interface{}) { *h = append(*h, x.(*cursor)) }
+func (h *cursors) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
@@ -22,6 +22,7 @@ import (
 
 type Corpus interface {
 	IndexedDoc() []int
+	// BatchWords sends the batches of the words into the channel, and closes it at the end.
 	BatchWords(chan []int, int) error
 	Dictionary() *dictionary.Dictionary
 	Cooccurrence() *co.Cooccurrence
@@ -32,4 +33,25 @@ type Corpus interface {
 type WithCooccurrence struct {
 	CountType co.CountType
 	Window    int
+	// Asymmetric counts only left contexts instead of both directions.
+	Asymmetric bool
+	// SpillCells spills the counts into the sorted runs in SpillDir whenever the cells in memory reach it
+	// while counting (0 means disabled), see co.Cooccurrence.Spill.
+	SpillCells int
+	SpillDir   string
+}
+
+func (with *WithCooccurrence) New() (*co.Cooccurrence, error) {
+	newCooc := co.New
+	if with.Asymmetric {
+		newCooc = co.NewAsymmetric
+	}
+	cooc, err := newCooc(with.CountType, with.Window)
+	if err != nil {
+		return nil, err
+	}
+	if with.SpillCells > 0 {
+		cooc.Spill(with.SpillDir, with.SpillCells)
+	}
+	return cooc, nil
 }
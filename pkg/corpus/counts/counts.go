@@ -0,0 +1,372 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package counts
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/corpus"
+	co "github.com/ynqa/wego/pkg/corpus/cooccurrence"
+	"github.com/ynqa/wego/pkg/corpus/cooccurrence/encode"
+	"github.com/ynqa/wego/pkg/corpus/dictionary"
+	"github.com/ynqa/wego/pkg/util/clock"
+	"github.com/ynqa/wego/pkg/util/fileutil"
+	"github.com/ynqa/wego/pkg/util/verbose"
+)
+
+type Format = string
+
+const (
+	// Text is the lines of `word context count`.
+	Text Format = "text"
+	// Binary is the records of int32 word, int32 context and float64 count in little endian,
+	// whose ids are 1-based lines of the vocab, i.e. cooccurrence.bin of GloVe.
+	Binary Format = "bin"
+	// MatrixMarket is the coordinate format of the sparse matrix, whose ids are 1-based lines of the vocab,
+	// and the symmetric one has only the lower triangle.
+	MatrixMarket Format = "mtx"
+)
+
+func InvalidFormatError(format Format) error {
+	return errors.Errorf("invalid co-occurrence format: %s not in %s|%s|%s", format, Text, Binary, MatrixMarket)
+}
+
+// ValidFormat reports whether format is one of Text, Binary or MatrixMarket.
+func ValidFormat(format Format) bool {
+	return format == Text || format == Binary || format == MatrixMarket
+}
+
+// Corpus reads the precomputed co-occurrence counts instead of scanning the doc,
+// so it has neither the doc nor the batches of the words.
+type Corpus struct {
+	r      io.Reader
+	format Format
+	vocab  io.Reader
+
+	dic    *dictionary.Dictionary
+	cooc   *co.Cooccurrence
+	maxLen int
+
+	maxVocab int
+}
+
+// New reads the counts in format from r, which may be compressed by gzip. vocab is the lines of `word count`
+// which gives the frequencies and the order of the words, it is required except for Text. Without vocab, the frequency of a word is
+// the sum of its counts. The counts of the words out of vocab are skipped.
+func New(r io.Reader, format Format, vocab io.Reader, maxVocab int) corpus.Corpus {
+	return &Corpus{
+		r:      r,
+		format: format,
+		vocab:  vocab,
+
+		dic: dictionary.New(),
+
+		maxVocab: maxVocab,
+	}
+}
+
+func (c *Corpus) IndexedDoc() []int {
+	return nil
+}
+
+func (c *Corpus) BatchWords(ch chan []int, _ int) error {
+	close(ch)
+	return nil
+}
+
+func (c *Corpus) Dictionary() *dictionary.Dictionary {
+	return c.dic
+}
+
+func (c *Corpus) Cooccurrence() *co.Cooccurrence {
+	return c.cooc
+}
+
+// Len is the sum of the frequencies of the words.
+func (c *Corpus) Len() int {
+	return c.maxLen
+}
+
+func (c *Corpus) Load(with *corpus.WithCooccurrence, verbose *verbose.Verbose, logBatch int) error {
+	if !ValidFormat(c.format) {
+		return InvalidFormatError(c.format)
+	} else if c.format != Text && c.vocab == nil {
+		return errors.Errorf("%s co-occurrence requires the vocab", c.format)
+	}
+	r, err := decompress(c.r)
+	if err != nil {
+		return err
+	}
+
+	var ids []int
+	if c.vocab != nil {
+		if ids, err = c.readVocab(); err != nil {
+			return err
+		}
+		// the vocab is pruned before reading, so the counts of the pruned words are skipped on reading.
+		if remap := c.dic.Prune(c.maxVocab); remap != nil {
+			for i, id := range ids {
+				ids[i] = remap[id]
+			}
+		}
+	}
+	if with != nil {
+		if c.cooc, err = with.New(); err != nil {
+			return err
+		}
+	}
+
+	clk := clock.New()
+	// the counts are added into the co-occurrence as they are read. The symmetric one takes both of the mirrored cells,
+	// e.g. cooccurrence.bin of GloVe, so they count as their mean, where the missing one is 0.
+	// Without the vocab, the frequencies of the words are the sums of their counts.
+	var sums []float64
+	cursor := 0
+	add := func(word, context int, count float64) {
+		if word < 0 || context < 0 {
+			return
+		}
+		if c.vocab == nil {
+			for len(sums) <= word {
+				sums = append(sums, 0)
+			}
+			sums[word] += count
+		}
+		if c.cooc != nil {
+			if c.cooc.Symmetric() && word != context {
+				count /= 2
+			}
+			c.cooc.AddCount(word, context, count)
+		}
+		cursor++
+		verbose.Do(func() {
+			if cursor%logBatch == 0 {
+				verbose.Printf("read %d counts %v\r", cursor, clk.AllElapsed())
+			}
+		})
+	}
+	switch c.format {
+	case Text:
+		err = c.readText(r, c.vocab != nil, add)
+	case Binary:
+		err = readBinary(r, ids, add)
+	case MatrixMarket:
+		err = readMatrixMarket(r, ids, add)
+	}
+	if err != nil {
+		return err
+	}
+	verbose.Do(func() {
+		verbose.Printf("read %d counts %v\r\n", cursor, clk.AllElapsed())
+	})
+
+	if c.vocab == nil {
+		for id := 0; id < c.dic.Len(); id++ {
+			var sum float64
+			if id < len(sums) {
+				sum = sums[id]
+			}
+			// the words only in the contexts occur at least once.
+			word, _ := c.dic.Word(id)
+			c.dic.AddCount(word, int(math.Max(math.Ceil(sum), 1))-c.dic.IDFreq(id))
+		}
+		if err := c.prune(with); err != nil {
+			return err
+		}
+	}
+	for id := 0; id < c.dic.Len(); id++ {
+		c.maxLen += c.dic.IDFreq(id)
+	}
+	return nil
+}
+
+// prune keeps the top maxVocab words of the frequencies found on reading the counts without the vocab,
+// and moves the counts into the co-occurrence of the new ids.
+func (c *Corpus) prune(with *corpus.WithCooccurrence) error {
+	remap := c.dic.Prune(c.maxVocab)
+	if remap == nil || c.cooc == nil {
+		return nil
+	}
+	old := c.cooc
+	defer old.Close()
+	var err error
+	if c.cooc, err = with.New(); err != nil {
+		return err
+	}
+	return old.Each(func(enc uint64, count float64) error {
+		u1, u2 := encode.DecodeBigram(enc)
+		if word, context := remap[u1], remap[u2]; word >= 0 && context >= 0 {
+			c.cooc.AddCount(word, context, count)
+		}
+		return nil
+	})
+}
+
+func (c *Corpus) readVocab() ([]int, error) {
+	var (
+		ids  []int
+		line int
+	)
+	scanner := fileutil.NewScanner(c.vocab, bufio.ScanLines)
+	for scanner.Scan() {
+		line++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		} else if len(fields) != 2 {
+			return nil, errors.Errorf("vocab line %d: expected `word count`, got %d fields", line, len(fields))
+		}
+		n, err := strconv.Atoi(fields[1])
+		if err != nil || n < 0 {
+			return nil, errors.Errorf("vocab line %d: invalid count %q", line, fields[1])
+		}
+		ids = append(ids, c.dic.AddCount(fields[0], n))
+	}
+	return ids, scanner.Err()
+}
+
+// decompress reads r through gzip if it starts with the magic of gzip.
+func decompress(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		return gzip.NewReader(br)
+	}
+	return br, nil
+}
+
+func (c *Corpus) readText(r io.Reader, fixed bool, add func(int, int, float64)) error {
+	id := func(word string) (int, bool) {
+		if fixed {
+			return c.dic.ID(word)
+		}
+		return c.dic.AddCount(word, 0), true
+	}
+	var line int
+	scanner := fileutil.NewScanner(r, bufio.ScanLines)
+	for scanner.Scan() {
+		line++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		} else if len(fields) != 3 {
+			return errors.Errorf("line %d: expected `word context count`, got %d fields", line, len(fields))
+		}
+		f, err := strconv.ParseFloat(fields[2], 64)
+		if err != nil || f <= 0 {
+			return errors.Errorf("line %d: invalid count %q", line, fields[2])
+		}
+		word, ok1 := id(fields[0])
+		context, ok2 := id(fields[1])
+		if !ok1 || !ok2 {
+			continue
+		}
+		add(word, context, f)
+	}
+	return scanner.Err()
+}
+
+type record struct {
+	Word, Context int32
+	Count         float64
+}
+
+// lookup maps the 1-based ids of the vocab to the ids of the dictionary.
+func lookup(ids []int, word, context int) (int, int, error) {
+	if word < 1 || word > len(ids) || context < 1 || context > len(ids) {
+		return 0, 0, errors.Errorf("ids (%d, %d) out of the vocab [1, %d]", word, context, len(ids))
+	}
+	return ids[word-1], ids[context-1], nil
+}
+
+func readBinary(r io.Reader, ids []int, add func(int, int, float64)) error {
+	br := bufio.NewReader(r)
+	for n := 0; ; n++ {
+		var rec record
+		if err := binary.Read(br, binary.LittleEndian, &rec); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return errors.Wrapf(err, "record %d", n)
+		}
+		word, context, err := lookup(ids, int(rec.Word), int(rec.Context))
+		if err != nil {
+			return errors.Wrapf(err, "record %d", n)
+		}
+		add(word, context, rec.Count)
+	}
+}
+
+func readMatrixMarket(r io.Reader, ids []int, add func(int, int, float64)) error {
+	var (
+		line      int
+		symmetric bool
+		sized     bool
+	)
+	scanner := fileutil.NewScanner(r, bufio.ScanLines)
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		if line == 1 {
+			header := strings.Fields(strings.ToLower(text))
+			if len(header) != 5 || header[0] != "%%matrixmarket" || header[1] != "matrix" || header[2] != "coordinate" {
+				return errors.Errorf("line 1: expected the header of %%%%MatrixMarket matrix coordinate, got %q", text)
+			} else if header[3] != "real" && header[3] != "integer" {
+				return errors.Errorf("line 1: field must be real or integer, got %s", header[3])
+			} else if header[4] != "general" && header[4] != "symmetric" {
+				return errors.Errorf("line 1: symmetry must be general or symmetric, got %s", header[4])
+			}
+			symmetric = header[4] == "symmetric"
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) == 0 || strings.HasPrefix(text, "%") {
+			continue
+		} else if len(fields) != 3 {
+			return errors.Errorf("line %d: expected 3 fields, got %d", line, len(fields))
+		}
+		// the first line after the comments is the size of rows, columns and entries.
+		if !sized {
+			sized = true
+			for _, field := range fields[:2] {
+				if n, err := strconv.Atoi(field); err != nil || n > len(ids) {
+					return errors.Errorf("line %d: invalid size %q of the vocab %d", line, field, len(ids))
+				}
+			}
+			continue
+		}
+		i, err1 := strconv.Atoi(fields[0])
+		j, err2 := strconv.Atoi(fields[1])
+		f, err3 := strconv.ParseFloat(fields[2], 64)
+		if err1 != nil || err2 != nil || err3 != nil || f <= 0 {
+			return errors.Errorf("line %d: invalid entry %q", line, text)
+		}
+		word, context, err := lookup(ids, i, j)
+		if err != nil {
+			return errors.Wrapf(err, "line %d", line)
+		}
+		add(word, context, f)
+		if symmetric && word != context {
+			add(context, word, f)
+		}
+	}
+	return scanner.Err()
+}
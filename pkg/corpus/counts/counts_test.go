@@ -0,0 +1,128 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package counts
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/corpus"
+	co "github.com/ynqa/wego/pkg/corpus/cooccurrence"
+	"github.com/ynqa/wego/pkg/corpus/cooccurrence/encode"
+	"github.com/ynqa/wego/pkg/util/verbose"
+)
+
+func load(t *testing.T, c corpus.Corpus, asymmetric bool) map[uint64]float64 {
+	assert.NoError(t, c.Load(&corpus.WithCooccurrence{
+		CountType:  co.Increment,
+		Asymmetric: asymmetric,
+	}, verbose.New(false), 100))
+	return c.Cooccurrence().EncodedMatrix()
+}
+
+func TestText(t *testing.T) {
+	c := New(strings.NewReader("a b 2\nb a 2\na c 1.5\n\nc c 1\n"), Text, nil, 0)
+	em := load(t, c, false)
+	assert.Equal(t, map[uint64]float64{
+		encode.EncodeBigram(0, 1): 2,
+		// the mirrored cell of (a, c) is missing.
+		encode.EncodeBigram(0, 2): 0.75,
+		encode.EncodeBigram(2, 2): 1,
+	}, em)
+	dic := c.Dictionary()
+	assert.Equal(t, 3, dic.Len())
+	assert.Equal(t, 4, dic.WordFreq("a"))
+	assert.Equal(t, 2, dic.WordFreq("b"))
+	assert.Equal(t, 1, dic.WordFreq("c"))
+	assert.Equal(t, 7, c.Len())
+	assert.Nil(t, c.IndexedDoc())
+
+	em = load(t, New(strings.NewReader("a b 2\nb a 1\n"), Text, nil, 0), true)
+	assert.Equal(t, map[uint64]float64{
+		encode.EncodeOrderedBigram(0, 1): 2,
+		encode.EncodeOrderedBigram(1, 0): 1,
+	}, em)
+}
+
+func TestTextWithVocab(t *testing.T) {
+	c := New(strings.NewReader("a b 2\na x 5\n"), Text, strings.NewReader("b 10\na 20\n"), 0)
+	em := load(t, c, true)
+	assert.Equal(t, map[uint64]float64{
+		encode.EncodeOrderedBigram(1, 0): 2,
+	}, em)
+	assert.Equal(t, 20, c.Dictionary().WordFreq("a"))
+	assert.Equal(t, 30, c.Len())
+}
+
+func TestMaxVocab(t *testing.T) {
+	c := New(strings.NewReader("a b 2\nb c 1\na a 3\n"), Text, strings.NewReader("a 5\nb 4\nc 1\n"), 2)
+	em := load(t, c, true)
+	assert.Equal(t, map[uint64]float64{
+		encode.EncodeOrderedBigram(0, 1): 2,
+		encode.EncodeOrderedBigram(0, 0): 3,
+	}, em)
+	assert.Equal(t, 2, c.Dictionary().Len())
+	assert.Equal(t, 9, c.Len())
+}
+
+func TestMaxVocabWithoutVocab(t *testing.T) {
+	c := New(strings.NewReader("a b 2\nb c 1\na a 3\n"), Text, nil, 2)
+	em := load(t, c, true)
+	assert.Equal(t, map[uint64]float64{
+		encode.EncodeOrderedBigram(0, 1): 2,
+		encode.EncodeOrderedBigram(0, 0): 3,
+	}, em)
+	assert.Equal(t, 2, c.Dictionary().Len())
+	assert.Equal(t, 6, c.Len())
+}
+
+func TestBinary(t *testing.T) {
+	var buf bytes.Buffer
+	for _, rec := range []record{{1, 2, 3}, {2, 1, 3}, {2, 2, 0.5}} {
+		assert.NoError(t, binary.Write(&buf, binary.LittleEndian, rec))
+	}
+	c := New(bytes.NewReader(buf.Bytes()), Binary, strings.NewReader("a 4\nb 2\n"), 0)
+	em := load(t, c, false)
+	assert.Equal(t, map[uint64]float64{
+		encode.EncodeBigram(0, 1): 3,
+		encode.EncodeBigram(1, 1): 0.5,
+	}, em)
+
+	buf.Reset()
+	assert.NoError(t, binary.Write(&buf, binary.LittleEndian, record{1, 3, 1}))
+	c = New(&buf, Binary, strings.NewReader("a 4\nb 2\n"), 0)
+	assert.Error(t, c.Load(&corpus.WithCooccurrence{CountType: co.Increment}, verbose.New(false), 100))
+}
+
+func TestInvalid(t *testing.T) {
+	for _, c := range []corpus.Corpus{
+		New(strings.NewReader("a b\n"), Text, nil, 0),
+		New(strings.NewReader("a b x\n"), Text, nil, 0),
+		New(strings.NewReader("a b -1\n"), Text, nil, 0),
+		New(strings.NewReader("a b 1\n"), Text, strings.NewReader("a\n"), 0),
+		New(strings.NewReader(""), Binary, nil, 0),
+		New(strings.NewReader(""), "csv", nil, 0),
+		New(strings.NewReader("\x01\x00"), Binary, strings.NewReader("a 1\n"), 0),
+		New(strings.NewReader("1 1 1\n"), MatrixMarket, strings.NewReader("a 1\n"), 0),
+		New(strings.NewReader("%%MatrixMarket matrix coordinate real general\n3 3 1\n1 1 1\n"), MatrixMarket, strings.NewReader("a 1\n"), 0),
+		New(strings.NewReader("%%MatrixMarket matrix coordinate real general\n1 1 1\n1 2 1\n"), MatrixMarket, strings.NewReader("a 1\n"), 0),
+	} {
+		assert.Error(t, c.Load(&corpus.WithCooccurrence{CountType: co.Increment}, verbose.New(false), 100))
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package counts
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	co "github.com/ynqa/wego/pkg/corpus/cooccurrence"
+	"github.com/ynqa/wego/pkg/corpus/cooccurrence/encode"
+	"github.com/ynqa/wego/pkg/corpus/dictionary"
+)
+
+type cell struct {
+	word, context int
+	count         float64
+}
+
+// cells returns the counts of cooc in the order of the words and the contexts. The symmetric co-occurrence
+// has both of the mirrored cells unless lower, where it has only the lower triangle, i.e. word >= context.
+func cells(cooc *co.Cooccurrence, lower bool) []cell {
+	em := cooc.EncodedMatrix()
+	res := make([]cell, 0, len(em))
+	for enc, f := range em {
+		u1, u2 := encode.DecodeBigram(enc)
+		word, context := int(u1), int(u2)
+		if !cooc.Symmetric() {
+			res = append(res, cell{word: word, context: context, count: f})
+			continue
+		}
+		if word < context {
+			word, context = context, word
+		}
+		res = append(res, cell{word: word, context: context, count: f})
+		if !lower && word != context {
+			res = append(res, cell{word: context, context: word, count: f})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].word != res[j].word {
+			return res[i].word < res[j].word
+		}
+		return res[i].context < res[j].context
+	})
+	return res
+}
+
+// Write writes the counts of cooc in format, which New reads back with the vocab of WriteVocab.
+func Write(w io.Writer, format Format, dic *dictionary.Dictionary, cooc *co.Cooccurrence) error {
+	if !ValidFormat(format) {
+		return InvalidFormatError(format)
+	}
+	bw := bufio.NewWriter(w)
+	switch format {
+	case Text:
+		for _, c := range cells(cooc, false) {
+			word, _ := dic.Word(c.word)
+			context, _ := dic.Word(c.context)
+			fmt.Fprintf(bw, "%s %s %s\n", word, context, strconv.FormatFloat(c.count, 'g', -1, 64))
+		}
+	case Binary:
+		for _, c := range cells(cooc, false) {
+			if err := binary.Write(bw, binary.LittleEndian, record{
+				Word:    int32(c.word + 1),
+				Context: int32(c.context + 1),
+				Count:   c.count,
+			}); err != nil {
+				return err
+			}
+		}
+	case MatrixMarket:
+		symmetry := "general"
+		if cooc.Symmetric() {
+			symmetry = "symmetric"
+		}
+		cs := cells(cooc, true)
+		fmt.Fprintf(bw, "%%%%MatrixMarket matrix coordinate real %s\n", symmetry)
+		fmt.Fprintf(bw, "%d %d %d\n", dic.Len(), dic.Len(), len(cs))
+		for _, c := range cs {
+			fmt.Fprintf(bw, "%d %d %s\n", c.word+1, c.context+1, strconv.FormatFloat(c.count, 'g', -1, 64))
+		}
+	}
+	return bw.Flush()
+}
+
+// WriteVocab writes the lines of `word count` in the order of the ids.
+func WriteVocab(w io.Writer, dic *dictionary.Dictionary) error {
+	bw := bufio.NewWriter(w)
+	for id := 0; id < dic.Len(); id++ {
+		word, _ := dic.Word(id)
+		fmt.Fprintf(bw, "%s %d\n", word, dic.IDFreq(id))
+	}
+	return bw.Flush()
+}
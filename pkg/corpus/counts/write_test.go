@@ -0,0 +1,87 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package counts
+
+import (
+	"bytes"
+	"compress/gzip"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/ynqa/wego/pkg/corpus/cooccurrence"
+	"github.com/ynqa/wego/pkg/corpus/dictionary"
+)
+
+func TestWrite(t *testing.T) {
+	dic := dictionary.New()
+	dic.AddCount("a", 5)
+	dic.AddCount("b", 3)
+	dic.AddCount("c", 1)
+
+	var vocab bytes.Buffer
+	assert.NoError(t, WriteVocab(&vocab, dic))
+	assert.Equal(t, "a 5\nb 3\nc 1\n", vocab.String())
+
+	for _, asymmetric := range []bool{false, true} {
+		var (
+			cooc *co.Cooccurrence
+			err  error
+		)
+		if asymmetric {
+			cooc, err = co.NewAsymmetric(co.Increment, 5)
+		} else {
+			cooc, err = co.New(co.Increment, 5)
+		}
+		assert.NoError(t, err)
+		cooc.AddCount(0, 1, 2)
+		cooc.AddCount(2, 0, 0.5)
+		cooc.AddCount(1, 1, 1)
+
+		for _, format := range []Format{Text, Binary, MatrixMarket} {
+			var buf bytes.Buffer
+			assert.NoError(t, Write(&buf, format, dic, cooc))
+			c := New(&buf, format, strings.NewReader(vocab.String()), 0)
+			assert.Equal(t, cooc.EncodedMatrix(), load(t, c, asymmetric), "format=%s, asymmetric=%v", format, asymmetric)
+		}
+	}
+}
+
+func TestWriteMatrixMarket(t *testing.T) {
+	dic := dictionary.New()
+	dic.Add("a", "b")
+	cooc, err := co.New(co.Increment, 5)
+	assert.NoError(t, err)
+	cooc.AddCount(0, 1, 2)
+	cooc.AddCount(0, 0, 1)
+
+	var buf bytes.Buffer
+	assert.NoError(t, Write(&buf, MatrixMarket, dic, cooc))
+	assert.Equal(t, "%%MatrixMarket matrix coordinate real symmetric\n2 2 2\n1 1 1\n2 1 2\n", buf.String())
+
+	assert.Error(t, Write(&buf, "csv", dic, cooc))
+}
+
+func TestReadGzip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write([]byte("a b 2\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, zw.Close())
+
+	c := New(&buf, Text, nil, 0)
+	assert.Len(t, load(t, c, false), 1)
+}
@@ -16,19 +16,47 @@ package cpsutil
 
 import (
 	"bufio"
+	"bytes"
 	"io"
+	"io/ioutil"
+	"os"
+	"unicode/utf8"
 
 	"github.com/ynqa/wego/pkg/corpus/dictionary"
+	"github.com/ynqa/wego/pkg/util/fileutil"
 )
 
 func scanner(r io.Reader) *bufio.Scanner {
-	s := bufio.NewScanner(r)
-	s.Split(bufio.ScanWords)
-	return s
+	return fileutil.NewScanner(r, bufio.ScanWords)
+}
+
+// ReadSeeker returns r itself if it is seekable, otherwise copies r into a temporary file
+// because the corpus is read several times. The returned function cleans up the file.
+func ReadSeeker(r io.Reader) (io.ReadSeeker, func() error, error) {
+	if rs, ok := r.(io.ReadSeeker); ok {
+		return rs, func() error { return nil }, nil
+	}
+	f, err := ioutil.TempFile("", "wego-corpus")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() error {
+		if err := f.Close(); err != nil {
+			return err
+		}
+		return os.Remove(f.Name())
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return f, cleanup, nil
 }
 
 func ReadWord(r io.ReadSeeker, fn func(string) error) error {
-	r.Seek(0, 0)
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
 	scanner := scanner(r)
 	for scanner.Scan() {
 		if err := fn(scanner.Text()); err != nil {
@@ -43,16 +71,55 @@ func ReadWord(r io.ReadSeeker, fn func(string) error) error {
 	return nil
 }
 
-func ReadWordWithForwardContext(r io.ReadSeeker, n int, fn func(string, string) error) error {
-	r.Seek(0, 0)
+// ReadWordBytes is ReadWord without allocating the string of each word. The bytes are valid only
+// until fn returns, and fn may modify them, e.g. by LowerBytes.
+func ReadWordBytes(r io.ReadSeeker, fn func([]byte) error) error {
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	scanner := scanner(r)
+	for scanner.Scan() {
+		if err := fn(scanner.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// LowerBytes lowercases the ASCII word in place, and falls back to bytes.ToLower for the others.
+func LowerBytes(word []byte) []byte {
+	for _, c := range word {
+		if c >= utf8.RuneSelf {
+			return bytes.ToLower(word)
+		}
+	}
+	for i, c := range word {
+		if 'A' <= c && c <= 'Z' {
+			word[i] = c + 'a' - 'A'
+		}
+	}
+	return word
+}
+
+// ReadWordWithForwardContext calls fn with each word, the word following it within n words,
+// and the distance between them.
+func ReadWordWithForwardContext(r io.ReadSeeker, n int, fn func(string, string, int) error) error {
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
 	scanner := scanner(r)
 	var (
 		axis string
 		ws   []string = make([]string, n)
 	)
 	postFn := func() error {
-		for _, w := range ws {
-			if err := fn(axis, w); err != nil {
+		for i, w := range ws {
+			if err := fn(axis, w, i+1); err != nil {
 				return err
 			}
 		}
@@ -15,10 +15,16 @@
 package cpsutil
 
 import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/corpus/dictionary"
 )
 
 func TestReadWord(t *testing.T) {
@@ -34,10 +40,28 @@ func TestReadWord(t *testing.T) {
 	assert.Equal(t, expected, dic)
 }
 
-func TestReadWordWithForwardContext(t *testing.T) {
+func TestReadWordBytes(t *testing.T) {
 	var dic []string
-	fn := func(w1, w2 string) (err error) {
+	fn := func(w []byte) error {
+		dic = append(dic, string(LowerBytes(w)))
+		return nil
+	}
+
+	r := strings.NewReader("\ufeffA bC\r\nDÉF")
+	assert.NoError(t, ReadWordBytes(r, fn))
+	assert.Equal(t, []string{"a", "bc", "déf"}, dic)
+
+	assert.Error(t, ReadWordBytes(failSeeker{strings.NewReader("a")}, fn))
+}
+
+func TestReadWordWithForwardContext(t *testing.T) {
+	var (
+		dic   []string
+		dists []int
+	)
+	fn := func(w1, w2 string, dist int) (err error) {
 		dic = append(dic, w1+w2)
+		dists = append(dists, dist)
 		return
 	}
 
@@ -45,4 +69,75 @@ func TestReadWordWithForwardContext(t *testing.T) {
 	expected := []string{"ab", "ac", "bc", "bd", "cd", "ce", "de"}
 	assert.NoError(t, ReadWordWithForwardContext(r, 2, fn))
 	assert.Equal(t, expected, dic)
+	assert.Equal(t, []int{1, 2, 1, 2, 1, 2, 1}, dists)
+}
+
+func TestReadSeeker(t *testing.T) {
+	r, cleanup, err := ReadSeeker(ioutil.NopCloser(strings.NewReader("a bc def")))
+	assert.NoError(t, err)
+	defer cleanup()
+
+	for i := 0; i < 2; i++ {
+		var dic []string
+		assert.NoError(t, ReadWord(r, func(w string) error {
+			dic = append(dic, w)
+			return nil
+		}))
+		assert.Equal(t, []string{"a", "bc", "def"}, dic)
+	}
+}
+
+type failSeeker struct {
+	*strings.Reader
+}
+
+func (failSeeker) Seek(int64, int) (int64, error) {
+	return 0, errors.New("seek failed")
+}
+
+func TestReadWordWindows(t *testing.T) {
+	var dic []string
+	fn := func(w string) error {
+		dic = append(dic, w)
+		return nil
+	}
+	// UTF-8 BOM and CRLF written by editors on Windows.
+	assert.NoError(t, ReadWord(strings.NewReader("\ufeffa bc\r\ndef\r\n"), fn))
+	assert.Equal(t, []string{"a", "bc", "def"}, dic)
+
+	assert.Error(t, ReadWord(failSeeker{strings.NewReader("a")}, fn))
+}
+
+func BenchmarkReadWord(b *testing.B) {
+	var buf strings.Builder
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 200000; i++ {
+		fmt.Fprintf(&buf, "Word%d ", rng.Intn(10000))
+	}
+	r := strings.NewReader(buf.String())
+
+	b.Run("string", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			dic := dictionary.New()
+			if err := ReadWord(r, func(w string) error {
+				dic.Add(strings.ToLower(w))
+				return nil
+			}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("bytes", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			dic := dictionary.New()
+			if err := ReadWordBytes(r, func(w []byte) error {
+				dic.AddBytes(LowerBytes(w))
+				return nil
+			}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
 }
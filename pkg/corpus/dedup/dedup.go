@@ -0,0 +1,265 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dedup
+
+import (
+	"bufio"
+	"encoding/binary"
+	"hash/fnv"
+	"io"
+	"math"
+	"math/rand"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/util/fileutil"
+)
+
+var (
+	defaultBands    = 16
+	defaultHashes   = 128
+	defaultMaxLines = 1000000
+	defaultShingle  = 3
+	defaultToLower  = false
+)
+
+type Options struct {
+	// Bands splits the MinHash signature into the bands of LSH, where the lines sharing any band are
+	// near-duplicate, i.e. the Jaccard similarity of the shingles is about over (1/Bands)^(Bands/Hashes).
+	Bands int
+	// Hashes is the size of the MinHash signature, and 0 drops the exact duplicates only.
+	Hashes int
+	// MaxLines bounds the memory by forgetting the oldest lines seen over it. 0 means unlimited.
+	MaxLines int
+	Shingle  int
+	ToLower  bool
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Bands:    defaultBands,
+		Hashes:   defaultHashes,
+		MaxLines: defaultMaxLines,
+		Shingle:  defaultShingle,
+		ToLower:  defaultToLower,
+	}
+}
+
+func (opts Options) Validate() error {
+	if opts.Hashes < 0 {
+		return errors.Errorf("hashes must be >= 0, got %d", opts.Hashes)
+	} else if opts.Hashes > 0 && (opts.Bands < 1 || opts.Hashes%opts.Bands != 0) {
+		return errors.Errorf("bands must divide hashes %d, got %d", opts.Hashes, opts.Bands)
+	} else if opts.Shingle < 1 {
+		return errors.Errorf("shingle must be >= 1, got %d", opts.Shingle)
+	} else if opts.MaxLines < 0 {
+		return errors.Errorf("max-lines must be >= 0, got %d", opts.MaxLines)
+	}
+	return nil
+}
+
+// Stats is the number of the lines read and dropped.
+type Stats struct {
+	Lines int
+	Exact int
+	Near  int
+}
+
+// Write writes the lines of r except the exact duplicates, after the whitespaces are normalized,
+// and the near-duplicates by MinHash. The empty lines are kept.
+func Write(w io.Writer, r io.Reader, opts Options) (Stats, error) {
+	var stats Stats
+	if err := opts.Validate(); err != nil {
+		return stats, err
+	}
+	d := newDeduper(opts)
+	buf := bufio.NewWriter(w)
+	s := fileutil.NewScanner(r, bufio.ScanLines)
+	for s.Scan() {
+		stats.Lines++
+		line := s.Text()
+		switch d.check(line) {
+		case exact:
+			stats.Exact++
+			continue
+		case near:
+			stats.Near++
+			continue
+		}
+		buf.WriteString(line)
+		if err := buf.WriteByte('\n'); err != nil {
+			return stats, err
+		}
+	}
+	if err := s.Err(); err != nil && err != io.EOF {
+		return stats, errors.Wrap(err, "failed to scan")
+	}
+	return stats, buf.Flush()
+}
+
+type kind int
+
+const (
+	unique kind = iota
+	exact
+	near
+)
+
+// NewReader returns the reader of the lines of r without the duplicates, which are dropped in background.
+func NewReader(r io.Reader, opts Options) io.Reader {
+	pr, pw := io.Pipe()
+	go func() {
+		_, err := Write(pw, r, opts)
+		pw.CloseWithError(err)
+	}()
+	return pr
+}
+
+type deduper struct {
+	opts  Options
+	seeds []uint64
+	lines map[uint64]struct{}
+	// bands counts the lines of each key of the bands, which are shared by the near-duplicates.
+	bands []map[uint64]int
+	// seen is the ring of the lines remembered in order, whose oldest is at next once it is full.
+	seen []entry
+	next int
+}
+
+// entry is the keys of the line and its bands.
+type entry struct {
+	key   uint64
+	bands []uint64
+}
+
+func newDeduper(opts Options) *deduper {
+	// the fixed seed makes the outputs deterministic.
+	rng := rand.New(rand.NewSource(1))
+	seeds := make([]uint64, opts.Hashes)
+	for i := range seeds {
+		seeds[i] = rng.Uint64()
+	}
+	d := &deduper{
+		opts:  opts,
+		seeds: seeds,
+		lines: make(map[uint64]struct{}),
+	}
+	if opts.Hashes > 0 {
+		d.bands = make([]map[uint64]int, opts.Bands)
+		for i := range d.bands {
+			d.bands[i] = make(map[uint64]int)
+		}
+	}
+	return d
+}
+
+// remember adds the line, and forgets the oldest one if over MaxLines.
+func (d *deduper) remember(e entry) {
+	d.lines[e.key] = struct{}{}
+	for i, k := range e.bands {
+		d.bands[i][k]++
+	}
+	if d.opts.MaxLines == 0 {
+		return
+	}
+	if len(d.seen) < d.opts.MaxLines {
+		d.seen = append(d.seen, e)
+		return
+	}
+	old := d.seen[d.next]
+	delete(d.lines, old.key)
+	for i, k := range old.bands {
+		if d.bands[i][k]--; d.bands[i][k] == 0 {
+			delete(d.bands[i], k)
+		}
+	}
+	d.seen[d.next] = e
+	d.next = (d.next + 1) % len(d.seen)
+}
+
+func (d *deduper) check(line string) kind {
+	if d.opts.ToLower {
+		line = strings.ToLower(line)
+	}
+	words := strings.Fields(line)
+	if len(words) == 0 {
+		return unique
+	}
+	key := hash(strings.Join(words, " "))
+	if _, ok := d.lines[key]; ok {
+		return exact
+	}
+	if d.opts.Hashes == 0 {
+		d.remember(entry{key: key})
+		return unique
+	}
+
+	sig := d.signature(words)
+	rows := d.opts.Hashes / d.opts.Bands
+	e := entry{key: key, bands: make([]uint64, d.opts.Bands)}
+	dup := false
+	for i, band := range d.bands {
+		h := fnv.New64a()
+		var b [8]byte
+		for _, v := range sig[i*rows : (i+1)*rows] {
+			binary.LittleEndian.PutUint64(b[:], v)
+			h.Write(b[:])
+		}
+		e.bands[i] = h.Sum64()
+		if band[e.bands[i]] > 0 {
+			dup = true
+		}
+	}
+	d.remember(e)
+	if dup {
+		return near
+	}
+	return unique
+}
+
+// signature returns the minimums of the hashes of the shingles by each hash function, mix(x^seed).
+func (d *deduper) signature(words []string) []uint64 {
+	sig := make([]uint64, d.opts.Hashes)
+	for i := range sig {
+		sig[i] = math.MaxUint64
+	}
+	n := d.opts.Shingle
+	if len(words) < n {
+		n = len(words)
+	}
+	for i := 0; i+n <= len(words); i++ {
+		x := hash(strings.Join(words[i:i+n], " "))
+		for j, seed := range d.seeds {
+			if v := mix(x ^ seed); v < sig[j] {
+				sig[j] = v
+			}
+		}
+	}
+	return sig
+}
+
+func hash(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// mix is the finalizer of SplitMix64.
+func mix(x uint64) uint64 {
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
@@ -0,0 +1,110 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dedup
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func words(from, to int) string {
+	var ws []string
+	for i := from; i < to; i++ {
+		ws = append(ws, fmt.Sprintf("w%d", i))
+	}
+	return strings.Join(ws, " ")
+}
+
+func TestWrite(t *testing.T) {
+	base := words(0, 30)
+	edited := strings.Replace(base, "w15", "x", 1)
+	other := words(100, 130)
+	input := strings.Join([]string{"a  b", "", "a b ", "", base, "A B", edited, other}, "\n")
+
+	testCases := []struct {
+		name     string
+		opts     func(*Options)
+		expected string
+		stats    Stats
+	}{
+		{
+			name:     "exact and near",
+			opts:     func(*Options) {},
+			expected: strings.Join([]string{"a  b", "", "", base, "A B", other}, "\n") + "\n",
+			stats:    Stats{Lines: 8, Exact: 1, Near: 1},
+		},
+		{
+			name:     "exact only",
+			opts:     func(opts *Options) { opts.Hashes = 0 },
+			expected: strings.Join([]string{"a  b", "", "", base, "A B", edited, other}, "\n") + "\n",
+			stats:    Stats{Lines: 8, Exact: 1},
+		},
+		{
+			name:     "lowercase",
+			opts:     func(opts *Options) { opts.Hashes, opts.ToLower = 0, true },
+			expected: strings.Join([]string{"a  b", "", "", base, edited, other}, "\n") + "\n",
+			stats:    Stats{Lines: 8, Exact: 2},
+		},
+		{
+			name:     "bounded",
+			opts:     func(opts *Options) { opts.Hashes, opts.ToLower, opts.MaxLines = 0, true, 1 },
+			expected: strings.Join([]string{"a  b", "", "", base, "A B", edited, other}, "\n") + "\n",
+			stats:    Stats{Lines: 8, Exact: 1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			tc.opts(&opts)
+			buf := &bytes.Buffer{}
+			stats, err := Write(buf, strings.NewReader(input), opts)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, buf.String())
+			assert.Equal(t, tc.stats, stats)
+		})
+	}
+
+	// the oldest line is forgotten instead of all of them.
+	opts := DefaultOptions()
+	opts.Hashes, opts.MaxLines = 0, 2
+	buf := &bytes.Buffer{}
+	stats, err := Write(buf, strings.NewReader("a\nb\nc\nb\na"), opts)
+	assert.NoError(t, err)
+	assert.Equal(t, "a\nb\nc\na\n", buf.String())
+	assert.Equal(t, Stats{Lines: 5, Exact: 1}, stats)
+}
+
+func TestNewReader(t *testing.T) {
+	var buf bytes.Buffer
+	_, err := buf.ReadFrom(NewReader(strings.NewReader("a b\na  b\nc\n"), DefaultOptions()))
+	assert.NoError(t, err)
+	assert.Equal(t, "a b\nc\n", buf.String())
+}
+
+func TestValidate(t *testing.T) {
+	opts := DefaultOptions()
+	assert.NoError(t, opts.Validate())
+	opts.Bands = 3
+	assert.Error(t, opts.Validate())
+	opts.Hashes = 0
+	assert.NoError(t, opts.Validate())
+	opts.Shingle = 0
+	assert.Error(t, opts.Validate())
+}
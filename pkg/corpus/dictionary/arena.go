@@ -0,0 +1,55 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dictionary
+
+import (
+	"unsafe"
+)
+
+const blockSize = 64 << 10
+
+// arena interns the words into the shared blocks, so the heap holds one object per block
+// instead of one per word, which the GC has to mark in every cycle for the large vocabulary.
+// The blocks are only appended, so the interned words are never modified.
+type arena struct {
+	block []byte
+}
+
+func (a *arena) intern(word string) string {
+	if len(word) == 0 || len(word) > blockSize/16 {
+		return word
+	}
+	b := a.alloc(len(word))
+	copy(b, word)
+	return *(*string)(unsafe.Pointer(&b))
+}
+
+func (a *arena) internBytes(word []byte) string {
+	if len(word) == 0 || len(word) > blockSize/16 {
+		return string(word)
+	}
+	b := a.alloc(len(word))
+	copy(b, word)
+	return *(*string)(unsafe.Pointer(&b))
+}
+
+func (a *arena) alloc(n int) []byte {
+	if cap(a.block)-len(a.block) < n {
+		a.block = make([]byte, 0, blockSize)
+	}
+	start := len(a.block)
+	a.block = a.block[:start+n]
+	return a.block[start : start+n : start+n]
+}
@@ -18,13 +18,32 @@ package dictionary
 // - https://github.com/chewxy/lingo/blob/master/corpus/corpus.go
 // - https://github.com/RaRe-Technologies/gensim/blob/3.8.1/gensim/corpora/dictionary.py
 
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
 type Dictionary struct {
 	word2id map[string]int
 	id2word []string
+	words   arena
 
 	cfs []int
 
 	maxid int
+
+	// buckets > 0 means that words are hashed into the buckets instead of the exact ids,
+	// and votes elects the majority word of each bucket as its representative.
+	buckets int
+	votes   []int
+
+	// mergeCase merges the case variants into the id of the lowercase word,
+	// and forms counts their surface forms to elect the most frequent one as the word.
+	mergeCase bool
+	forms     []map[string]int
 }
 
 func New() *Dictionary {
@@ -36,17 +55,87 @@ func New() *Dictionary {
 	}
 }
 
+// NewHashed creates the dictionary which bounds the memory by the number of buckets,
+// the colliding words share the id.
+func NewHashed(buckets int) *Dictionary {
+	return &Dictionary{
+		id2word: make([]string, buckets),
+
+		cfs: make([]int, buckets),
+
+		maxid: buckets,
+
+		buckets: buckets,
+		votes:   make([]int, buckets),
+	}
+}
+
+// hash is FNV-1a not to allocate for each word.
+func (d *Dictionary) hash(word string) int {
+	h := uint32(2166136261)
+	for i := 0; i < len(word); i++ {
+		h ^= uint32(word[i])
+		h *= 16777619
+	}
+	return int(h % uint32(d.buckets))
+}
+
+func (d *Dictionary) hashBytes(word []byte) int {
+	h := uint32(2166136261)
+	for i := 0; i < len(word); i++ {
+		h ^= uint32(word[i])
+		h *= 16777619
+	}
+	return int(h % uint32(d.buckets))
+}
+
+// MergeCase makes the case variants share the id, e.g. "iPhone", "IPHONE", and "iphone",
+// and the most frequent surface form is returned by Word. It must be called before Add.
+// The hashed dictionary elects the majority form of each bucket instead of counting all forms.
+func (d *Dictionary) MergeCase() {
+	d.mergeCase = true
+}
+
+func (d *Dictionary) key(word string) string {
+	if d.mergeCase {
+		return strings.ToLower(word)
+	}
+	return word
+}
+
+func (d *Dictionary) Hashed() bool {
+	return d.buckets > 0
+}
+
 func (d *Dictionary) Len() int {
 	return d.maxid
 }
 
 func (d *Dictionary) ID(word string) (int, bool) {
+	word = d.key(word)
+	if d.Hashed() {
+		id := d.hash(word)
+		return id, d.cfs[id] > 0
+	}
 	id, ok := d.word2id[word]
 	return id, ok
 }
 
+// IDBytes is ID of the word in bytes, which doesn't allocate the string unless the case is merged.
+func (d *Dictionary) IDBytes(word []byte) (int, bool) {
+	if d.mergeCase {
+		return d.ID(string(word))
+	}
+	if d.Hashed() {
+		id := d.hashBytes(word)
+		return id, d.cfs[id] > 0
+	}
+	id, ok := d.word2id[string(word)]
+	return id, ok
+}
+
 func (d *Dictionary) WordFreq(word string) int {
-	id, ok := d.word2id[word]
+	id, ok := d.ID(word)
 	if !ok {
 		return 0
 	}
@@ -57,6 +146,9 @@ func (d *Dictionary) Word(id int) (string, bool) {
 	if id >= d.maxid {
 		return "", false
 	}
+	if d.Hashed() && d.cfs[id] == 0 {
+		return "", false
+	}
 	return d.id2word[id], true
 }
 
@@ -68,14 +160,176 @@ func (d *Dictionary) IDFreq(id int) int {
 }
 
 func (d *Dictionary) Add(words ...string) {
-	for _, word := range words {
-		if id, ok := d.word2id[word]; ok {
+	for _, form := range words {
+		word := d.key(form)
+		if d.Hashed() {
+			d.vote(d.hash(word), form)
+			continue
+		}
+		id, ok := d.word2id[word]
+		if ok {
 			d.cfs[id]++
 		} else {
-			d.word2id[word] = d.maxid
+			id = d.maxid
+			word = d.words.intern(word)
+			d.word2id[word] = id
 			d.id2word = append(d.id2word, word)
 			d.cfs = append(d.cfs, 1)
 			d.maxid++
+			if d.mergeCase {
+				d.forms = append(d.forms, make(map[string]int))
+			}
+		}
+		if d.mergeCase {
+			d.count(id, form)
+		}
+	}
+}
+
+// AddCount adds the word which occurs n times, e.g. from the precomputed counts, and returns its id.
+func (d *Dictionary) AddCount(word string, n int) int {
+	d.Add(word)
+	id, _ := d.ID(word)
+	d.cfs[id] += n - 1
+	return id
+}
+
+// AddBytes adds the word in bytes and returns its id, which converts the word into the string
+// only when it's inserted, so the bytes can be reused by the caller, e.g. the buffer of the scanner.
+func (d *Dictionary) AddBytes(word []byte) int {
+	if d.mergeCase {
+		d.Add(string(word))
+		id, _ := d.ID(string(word))
+		return id
+	}
+	if d.Hashed() {
+		id := d.hashBytes(word)
+		d.cfs[id]++
+		switch {
+		case d.id2word[id] == string(word):
+			d.votes[id]++
+		case d.votes[id] == 0:
+			d.id2word[id], d.votes[id] = string(word), 1
+		default:
+			d.votes[id]--
+		}
+		return id
+	}
+	if id, ok := d.word2id[string(word)]; ok {
+		d.cfs[id]++
+		return id
+	}
+	id := d.maxid
+	w := d.words.internBytes(word)
+	d.word2id[w] = id
+	d.id2word = append(d.id2word, w)
+	d.cfs = append(d.cfs, 1)
+	d.maxid++
+	return id
+}
+
+// count elects the form as the word of id when it gets more frequent than the current one,
+// the ties are broken by the form which reaches the count first.
+func (d *Dictionary) count(id int, form string) {
+	forms := d.forms[id]
+	if _, ok := forms[form]; !ok {
+		form = d.words.intern(form)
+	}
+	forms[form]++
+	if forms[form] > forms[d.id2word[id]] {
+		d.id2word[id] = form
+	}
+}
+
+func (d *Dictionary) vote(id int, word string) {
+	d.cfs[id]++
+	switch {
+	case d.id2word[id] == word:
+		d.votes[id]++
+	case d.votes[id] == 0:
+		d.id2word[id], d.votes[id] = word, 1
+	default:
+		d.votes[id]--
+	}
+}
+
+// Prune keeps the n most frequent words, and the ties are broken by the words to be deterministic.
+// The kept words are renumbered in the same order, and it returns the new ids indexed by the old ones,
+// -1 for the pruned words. It returns nil if nothing is pruned, and the hashed dictionary is never pruned.
+func (d *Dictionary) Prune(n int) []int {
+	if d.Hashed() || n <= 0 || d.maxid <= n {
+		return nil
+	}
+	ids := make([]int, d.maxid)
+	for i := range ids {
+		ids[i] = i
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := ids[i], ids[j]
+		if d.cfs[a] != d.cfs[b] {
+			return d.cfs[a] > d.cfs[b]
+		}
+		return d.id2word[a] < d.id2word[b]
+	})
+	remap := make([]int, d.maxid)
+	for i := range remap {
+		remap[i] = -1
+	}
+	for _, id := range ids[:n] {
+		remap[id] = 0
+	}
+
+	var maxid int
+	for id := range remap {
+		if remap[id] < 0 {
+			continue
+		}
+		remap[id] = maxid
+		d.id2word[maxid], d.cfs[maxid] = d.id2word[id], d.cfs[id]
+		if d.mergeCase {
+			d.forms[maxid] = d.forms[id]
+		}
+		maxid++
+	}
+	for word, id := range d.word2id {
+		if remap[id] < 0 {
+			delete(d.word2id, word)
+		} else {
+			d.word2id[word] = remap[id]
 		}
 	}
+	d.id2word, d.cfs, d.maxid = d.id2word[:maxid], d.cfs[:maxid], maxid
+	if d.mergeCase {
+		d.forms = d.forms[:maxid]
+	}
+	return remap
+}
+
+// CaseMap returns the surface forms which are merged into the other forms as the words,
+// e.g. {"iphone": "iPhone", "IPHONE": "iPhone"}. It's empty unless MergeCase is set.
+func (d *Dictionary) CaseMap() map[string]string {
+	res := make(map[string]string)
+	for id, forms := range d.forms {
+		for form := range forms {
+			if word := d.id2word[id]; form != word {
+				res[form] = word
+			}
+		}
+	}
+	return res
+}
+
+// WriteCaseMap writes CaseMap as the lines of "<form> <word>" sorted by the forms.
+func (d *Dictionary) WriteCaseMap(w io.Writer) error {
+	caseMap := d.CaseMap()
+	forms := make([]string, 0, len(caseMap))
+	for form := range caseMap {
+		forms = append(forms, form)
+	}
+	sort.Strings(forms)
+	bw := bufio.NewWriter(w)
+	for _, form := range forms {
+		fmt.Fprintf(bw, "%s %s\n", form, caseMap[form])
+	}
+	return bw.Flush()
 }
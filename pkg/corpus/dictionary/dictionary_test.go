@@ -0,0 +1,196 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dictionary
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDictionary(t *testing.T) {
+	dic := New()
+	dic.Add("a", "b", "a")
+
+	assert.Equal(t, 2, dic.Len())
+	assert.Equal(t, 2, dic.WordFreq("a"))
+	id, ok := dic.ID("b")
+	assert.True(t, ok)
+	word, ok := dic.Word(id)
+	assert.True(t, ok)
+	assert.Equal(t, "b", word)
+	_, ok = dic.ID("c")
+	assert.False(t, ok)
+}
+
+func TestHashed(t *testing.T) {
+	dic := NewHashed(1)
+	dic.Add("a", "b", "a", "c", "a")
+
+	assert.True(t, dic.Hashed())
+	assert.Equal(t, 1, dic.Len())
+	assert.Equal(t, 5, dic.WordFreq("b"))
+	word, ok := dic.Word(0)
+	assert.True(t, ok)
+	assert.Equal(t, "a", word)
+
+	dic = NewHashed(100)
+	assert.Equal(t, 100, dic.Len())
+	_, ok = dic.ID("a")
+	assert.False(t, ok)
+	dic.Add("a")
+	id, ok := dic.ID("a")
+	assert.True(t, ok)
+	word, _ = dic.Word(id)
+	assert.Equal(t, "a", word)
+	for i := 0; i < dic.Len(); i++ {
+		if i != id {
+			_, ok := dic.Word(i)
+			assert.False(t, ok)
+		}
+	}
+}
+
+func TestMergeCase(t *testing.T) {
+	dic := New()
+	dic.MergeCase()
+	dic.Add("iphone", "iPhone", "IPHONE", "iPhone", "apple", "Apple")
+
+	assert.Equal(t, 2, dic.Len())
+	assert.Equal(t, 4, dic.WordFreq("IPhone"))
+	id, ok := dic.ID("iphone")
+	assert.True(t, ok)
+	word, _ := dic.Word(id)
+	assert.Equal(t, "iPhone", word)
+	// the tie is broken by the form which reaches the count first.
+	word, _ = dic.Word(1)
+	assert.Equal(t, "apple", word)
+	assert.Equal(t, map[string]string{
+		"iphone": "iPhone",
+		"IPHONE": "iPhone",
+		"Apple":  "apple",
+	}, dic.CaseMap())
+
+	dic = NewHashed(1)
+	dic.MergeCase()
+	dic.Add("Go", "go", "Go")
+	word, _ = dic.Word(0)
+	assert.Equal(t, "Go", word)
+	assert.Equal(t, 3, dic.WordFreq("GO"))
+}
+
+func TestAddBytes(t *testing.T) {
+	words := []string{"iphone", "iPhone", "a", "b", "a", "IPHONE", "c", "a"}
+	testCases := []struct {
+		name string
+		new  func() *Dictionary
+	}{
+		{name: "exact", new: New},
+		{name: "hashed", new: func() *Dictionary { return NewHashed(2) }},
+		{name: "merge case", new: func() *Dictionary {
+			dic := New()
+			dic.MergeCase()
+			return dic
+		}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			expected, dic := tc.new(), tc.new()
+			expected.Add(words...)
+			buf := make([]byte, 0, 8)
+			for _, w := range words {
+				buf = append(buf[:0], w...)
+				id := dic.AddBytes(buf)
+				eid, _ := expected.ID(w)
+				assert.Equal(t, eid, id)
+			}
+			// the bytes are reused after they are added.
+			copy(buf, "xxxxxx")
+
+			assert.Equal(t, expected.Len(), dic.Len())
+			for _, w := range words {
+				id, ok := dic.IDBytes([]byte(w))
+				assert.True(t, ok)
+				eid, _ := expected.ID(w)
+				assert.Equal(t, eid, id)
+				word, _ := dic.Word(id)
+				eword, _ := expected.Word(eid)
+				assert.Equal(t, eword, word)
+				assert.Equal(t, expected.IDFreq(eid), dic.IDFreq(id))
+			}
+			_, ok := dic.IDBytes([]byte("d"))
+			assert.Equal(t, dic.Hashed(), ok)
+		})
+	}
+}
+
+func TestAddCount(t *testing.T) {
+	dic := New()
+	assert.Equal(t, 0, dic.AddCount("a", 10))
+	assert.Equal(t, 1, dic.AddCount("b", 3))
+	assert.Equal(t, 0, dic.AddCount("a", 2))
+	assert.Equal(t, 12, dic.WordFreq("a"))
+	assert.Equal(t, 3, dic.WordFreq("b"))
+}
+
+func TestPrune(t *testing.T) {
+	dic := New()
+	dic.Add("c", "b", "a", "a", "d", "d", "b", "e")
+
+	// d is pruned by the tie-breaking on the words.
+	assert.Equal(t, []int{-1, 0, 1, -1, -1}, dic.Prune(2))
+	assert.Equal(t, 2, dic.Len())
+	for id, word := range []string{"b", "a"} {
+		got, ok := dic.Word(id)
+		assert.True(t, ok)
+		assert.Equal(t, word, got)
+		assert.Equal(t, 2, dic.WordFreq(word))
+	}
+	_, ok := dic.ID("d")
+	assert.False(t, ok)
+	assert.Nil(t, dic.Prune(2))
+}
+
+func TestArena(t *testing.T) {
+	var a arena
+	words := make([]string, 0, 10000)
+	for i := 0; i < cap(words); i++ {
+		words = append(words, a.internBytes([]byte(fmt.Sprintf("word%d", i))))
+	}
+	for i, w := range words {
+		assert.Equal(t, fmt.Sprintf("word%d", i), w)
+	}
+	long := strings.Repeat("a", blockSize)
+	assert.Equal(t, long, a.intern(long))
+	assert.Equal(t, "", a.intern(""))
+}
+
+func TestHuffmanTree(t *testing.T) {
+	leaves := HuffmanTree([]int{4, 1, 2, 8}, 3)
+	assert.Len(t, leaves, 4)
+	depths := make([]int, len(leaves))
+	for i, leaf := range leaves {
+		path := leaf.GetPath(10)
+		depths[i] = len(path) - 1
+		assert.Equal(t, 15, path[0].Val)
+		for _, n := range path[:len(path)-1] {
+			assert.Len(t, n.Vector, 3)
+		}
+	}
+	assert.Equal(t, []int{2, 3, 3, 1}, depths)
+	assert.Nil(t, HuffmanTree(nil, 3))
+}
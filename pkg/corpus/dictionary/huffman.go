@@ -21,12 +21,27 @@ import (
 )
 
 func (d *Dictionary) HuffnamTree(dim int) []*node.Node {
-	nodes := make([]*node.Node, d.maxid)
-	set := make([]*node.Node, d.maxid)
-	for i := 0; i < d.maxid; i++ {
-		n := &node.Node{
-			Val: d.IDFreq(i),
-		}
+	freqs := make([]int, d.maxid)
+	for i := range freqs {
+		freqs[i] = d.IDFreq(i)
+	}
+	return HuffmanTree(freqs, dim)
+}
+
+// HuffmanTree returns the leaves of the tree built by the frequencies, e.g. of the hashed buckets of words.
+// All nodes and the vectors of the inner nodes are allocated at once not to scatter them over the heap.
+func HuffmanTree(freqs []int, dim int) []*node.Node {
+	if len(freqs) == 0 {
+		return nil
+	}
+	arena := make([]node.Node, 2*len(freqs)-1)
+	vectors := make([]float64, (len(freqs)-1)*dim)
+
+	nodes := make([]*node.Node, len(freqs))
+	set := make([]*node.Node, len(freqs))
+	for i, freq := range freqs {
+		n := &arena[i]
+		n.Val = freq
 		nodes[i] = n
 		set[i] = n
 	}
@@ -34,12 +49,12 @@ func (d *Dictionary) HuffnamTree(dim int) []*node.Node {
 	sort.SliceStable(nodes, func(i, j int) bool {
 		return nodes[i].Val < nodes[j].Val
 	})
-	for len(nodes) > 1 {
+	for k := len(freqs); len(nodes) > 1; k++ {
 		left, right := nodes[0], nodes[1]
-		merged := &node.Node{
-			Val:    left.Val + right.Val,
-			Vector: make([]float64, dim),
-		}
+		merged := &arena[k]
+		merged.Val = left.Val + right.Val
+		v := (k - len(freqs)) * dim
+		merged.Vector = vectors[v : v+dim : v+dim]
 		left.Code, right.Code = 0, 1
 		left.Parent, right.Parent = merged, merged
 
@@ -48,7 +63,7 @@ func (d *Dictionary) HuffnamTree(dim int) []*node.Node {
 			return nodes[i].Val >= merged.Val
 		})
 
-		nodes = append(nodes, &node.Node{})
+		nodes = append(nodes, nil)
 		copy(nodes[idx+1:], nodes[idx:])
 		nodes[idx] = merged
 	}
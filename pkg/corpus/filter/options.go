@@ -15,8 +15,6 @@
 package filter
 
 import (
-	"github.com/spf13/cobra"
-
 	"github.com/ynqa/wego/pkg/corpus/dictionary"
 )
 
@@ -37,11 +35,6 @@ func DefaultOption() *Options {
 	}
 }
 
-func LoadForCmd(cmd *cobra.Command, opts *Options) {
-	cmd.Flags().IntVar(&opts.MaxCount, "max-count", defaultMaxCount, "upper limit to filter words")
-	cmd.Flags().IntVar(&opts.MinCount, "min-count", defaultMinCount, "lower limit to filter words")
-}
-
 type FilterFn func(id int, dic *dictionary.Dictionary) bool
 
 func MaxCount(v int) FilterFn {
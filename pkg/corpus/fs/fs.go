@@ -15,9 +15,9 @@
 package fs
 
 import (
-	"fmt"
 	"io"
-	"strings"
+
+	"github.com/pkg/errors"
 
 	"github.com/ynqa/wego/pkg/corpus"
 	co "github.com/ynqa/wego/pkg/corpus/cooccurrence"
@@ -27,6 +27,8 @@ import (
 	"github.com/ynqa/wego/pkg/util/verbose"
 )
 
+const maxInt = int(^uint(0) >> 1)
+
 type Corpus struct {
 	doc io.ReadSeeker
 
@@ -34,16 +36,25 @@ type Corpus struct {
 	cooc   *co.Cooccurrence
 	maxLen int
 
-	toLower bool
-	filters cpsutil.Filters
+	toLower  bool
+	maxVocab int
+	filters  cpsutil.Filters
 }
 
-func New(r io.ReadSeeker, toLower bool, maxCount, minCount int) corpus.Corpus {
+func New(r io.ReadSeeker, toLower, mergeCase bool, maxCount, minCount, maxVocab, hashBuckets int) corpus.Corpus {
+	dic := dictionary.New()
+	if hashBuckets > 0 {
+		dic = dictionary.NewHashed(hashBuckets)
+	}
+	if mergeCase {
+		dic.MergeCase()
+	}
 	return &Corpus{
 		doc: r,
-		dic: dictionary.New(),
+		dic: dic,
 
-		toLower: toLower,
+		toLower:  toLower,
+		maxVocab: maxVocab,
 		filters: cpsutil.Filters{
 			cpsutil.MaxCount(maxCount),
 			cpsutil.MinCount(minCount),
@@ -56,14 +67,15 @@ func (c *Corpus) IndexedDoc() []int {
 }
 
 func (c *Corpus) BatchWords(ch chan []int, batchSize int) error {
+	defer close(ch)
 	cursor, ids := 0, make([]int, batchSize)
-	if err := cpsutil.ReadWord(c.doc, func(word string) error {
+	if err := cpsutil.ReadWordBytes(c.doc, func(word []byte) error {
 		if c.toLower {
-			word = strings.ToLower(word)
+			word = cpsutil.LowerBytes(word)
 		}
 
-		id, _ := c.dic.ID(word)
-		if c.filters.Any(id, c.dic) {
+		id, ok := c.dic.IDBytes(word)
+		if !ok || c.filters.Any(id, c.dic) {
 			return nil
 		}
 
@@ -80,7 +92,6 @@ func (c *Corpus) BatchWords(ch chan []int, batchSize int) error {
 
 	// send left words
 	ch <- ids[:cursor]
-	close(ch)
 	return nil
 }
 
@@ -98,16 +109,20 @@ func (c *Corpus) Len() int {
 
 func (c *Corpus) Load(with *corpus.WithCooccurrence, verbose *verbose.Verbose, logBatch int) error {
 	clk := clock.New()
-	if err := cpsutil.ReadWord(c.doc, func(word string) error {
+	if err := cpsutil.ReadWordBytes(c.doc, func(word []byte) error {
 		if c.toLower {
-			word = strings.ToLower(word)
+			word = cpsutil.LowerBytes(word)
 		}
 
-		c.dic.Add(word)
+		// the count of words overflows int for the corpus of several GB on 32-bit platforms.
+		if c.maxLen == maxInt {
+			return errors.Errorf("corpus has over %d words", maxInt)
+		}
+		c.dic.AddBytes(word)
 		c.maxLen++
 		verbose.Do(func() {
 			if c.maxLen%logBatch == 0 {
-				fmt.Printf("read %d words %v\r", c.maxLen, clk.AllElapsed())
+				verbose.Printf("read %d words %v\r", c.maxLen, clk.AllElapsed())
 			}
 		})
 
@@ -116,30 +131,41 @@ func (c *Corpus) Load(with *corpus.WithCooccurrence, verbose *verbose.Verbose, l
 		return err
 	}
 	verbose.Do(func() {
-		fmt.Printf("read %d words %v\r\n", c.maxLen, clk.AllElapsed())
+		verbose.Printf("read %d words %v\r\n", c.maxLen, clk.AllElapsed())
 	})
 
+	// the pruned words are skipped in the following passes.
+	if c.dic.Prune(c.maxVocab) != nil {
+		c.maxLen = 0
+		for id := 0; id < c.dic.Len(); id++ {
+			c.maxLen += c.dic.IDFreq(id)
+		}
+	}
+
 	clk = clock.New()
 	var (
 		err    error
 		cursor int
 	)
 	if with != nil {
-		c.cooc, err = co.New(with.CountType)
+		c.cooc, err = with.New()
 		if err != nil {
 			return err
 		}
 
-		if err = cpsutil.ReadWordWithForwardContext(c.doc, with.Window, func(w1, w2 string) error {
-			id1, _ := c.dic.ID(w1)
-			id2, _ := c.dic.ID(w2)
-			if err := c.cooc.Add(id1, id2); err != nil {
+		if err = cpsutil.ReadWordWithForwardContext(c.doc, with.Window, func(w1, w2 string, dist int) error {
+			id1, ok1 := c.dic.ID(w1)
+			id2, ok2 := c.dic.ID(w2)
+			if !ok1 || !ok2 {
+				return nil
+			}
+			if err := c.cooc.Add(id1, id2, dist); err != nil {
 				return err
 			}
 			cursor++
 			verbose.Do(func() {
 				if cursor%logBatch == 0 {
-					fmt.Printf("read %d tuples %v\r", cursor, clk.AllElapsed())
+					verbose.Printf("read %d tuples %v\r", cursor, clk.AllElapsed())
 				}
 			})
 			return nil
@@ -147,7 +173,7 @@ func (c *Corpus) Load(with *corpus.WithCooccurrence, verbose *verbose.Verbose, l
 			return err
 		}
 		verbose.Do(func() {
-			fmt.Printf("read %d tuples %v\r\n", cursor, clk.AllElapsed())
+			verbose.Printf("read %d tuples %v\r\n", cursor, clk.AllElapsed())
 		})
 	}
 
@@ -0,0 +1,201 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package langid
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/util/fileutil"
+)
+
+type profile struct {
+	counts map[string]int
+	total  int
+}
+
+// DefaultMinCoverage is the share of the trigrams of the text which the profile must know,
+// below which the text is in none of the languages, e.g. in the other scripts or the unprofiled languages.
+const DefaultMinCoverage = 0.6
+
+// Identifier identifies the language of the text by the naive Bayes of the character trigrams.
+type Identifier struct {
+	// MinCoverage rejects the text whose trigrams are known by the most likely profile less than it.
+	MinCoverage float64
+
+	profiles map[string]*profile
+	vocab    map[string]struct{}
+}
+
+// New returns Identifier with the built-in profiles of de, en, es, fr, it, nl and pt.
+func New() *Identifier {
+	id := &Identifier{
+		MinCoverage: DefaultMinCoverage,
+
+		profiles: make(map[string]*profile),
+		vocab:    make(map[string]struct{}),
+	}
+	for _, texts := range []map[string]string{samples, udhr} {
+		for lang, text := range texts {
+			id.Add(lang, strings.NewReader(text))
+		}
+	}
+	return id
+}
+
+// Add learns the profile of lang from the sample text in r, in addition to the one learned before.
+func (id *Identifier) Add(lang string, r io.Reader) error {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read the sample of %s", lang)
+	}
+	p, ok := id.profiles[lang]
+	if !ok {
+		p = &profile{counts: make(map[string]int)}
+		id.profiles[lang] = p
+	}
+	for _, tri := range trigrams(string(b)) {
+		p.counts[tri]++
+		p.total++
+		id.vocab[tri] = struct{}{}
+	}
+	return nil
+}
+
+// Languages returns the languages of the profiles in order.
+func (id *Identifier) Languages() []string {
+	langs := make([]string, 0, len(id.profiles))
+	for lang := range id.profiles {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// Validate returns the error if any of langs has no profile.
+func (id *Identifier) Validate(langs []string) error {
+	for _, lang := range langs {
+		if _, ok := id.profiles[lang]; !ok {
+			return errors.Errorf("invalid language: %s not in %s", lang, strings.Join(id.Languages(), "|"))
+		}
+	}
+	return nil
+}
+
+// Detect returns the most likely language of text, or false if it has no letters
+// or the language has too few of its trigrams to be any of the profiles.
+func (id *Identifier) Detect(text string) (string, bool) {
+	tris := trigrams(text)
+	if len(tris) == 0 {
+		return "", false
+	}
+	var (
+		best  string
+		score = math.Inf(-1)
+	)
+	v := float64(len(id.vocab))
+	for _, lang := range id.Languages() {
+		p := id.profiles[lang]
+		var s float64
+		for _, tri := range tris {
+			s += math.Log((float64(p.counts[tri]) + 1) / (float64(p.total) + v))
+		}
+		if s > score {
+			best, score = lang, s
+		}
+	}
+	var known int
+	for _, tri := range tris {
+		if id.profiles[best].counts[tri] > 0 {
+			known++
+		}
+	}
+	if float64(known) < id.MinCoverage*float64(len(tris)) {
+		return "", false
+	}
+	return best, true
+}
+
+func hasLetter(text string) bool {
+	return strings.IndexFunc(text, unicode.IsLetter) >= 0
+}
+
+// trigrams returns the trigrams of the lowercase words padded by spaces, e.g. " ab", "ab " for ab.
+func trigrams(text string) []string {
+	var tris []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}) {
+		runes := []rune(" " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			tris = append(tris, string(runes[i:i+3]))
+		}
+	}
+	return tris
+}
+
+// Stats is the number of the lines read and dropped.
+type Stats struct {
+	Lines   int
+	Dropped int
+}
+
+// Filter writes the lines of r in langs. The lines without letters, e.g. the empty lines, are kept,
+// and the lines in none of the languages are dropped.
+func Filter(w io.Writer, r io.Reader, id *Identifier, langs []string) (Stats, error) {
+	var stats Stats
+	if err := id.Validate(langs); err != nil {
+		return stats, err
+	}
+	keep := make(map[string]bool, len(langs))
+	for _, lang := range langs {
+		keep[lang] = true
+	}
+	buf := bufio.NewWriter(w)
+	s := fileutil.NewScanner(r, bufio.ScanLines)
+	for s.Scan() {
+		stats.Lines++
+		line := s.Text()
+		if lang, ok := id.Detect(line); hasLetter(line) && (!ok || !keep[lang]) {
+			stats.Dropped++
+			continue
+		}
+		buf.WriteString(line)
+		if err := buf.WriteByte('\n'); err != nil {
+			return stats, err
+		}
+	}
+	if err := s.Err(); err != nil && err != io.EOF {
+		return stats, errors.Wrap(err, "failed to scan")
+	}
+	return stats, buf.Flush()
+}
+
+// NewReader returns the reader of the lines of r in langs, which are filtered in background.
+func NewReader(r io.Reader, id *Identifier, langs []string) io.Reader {
+	pr, pw := io.Pipe()
+	go func() {
+		_, err := Filter(pw, r, id, langs)
+		pw.CloseWithError(err)
+	}()
+	return pr
+}
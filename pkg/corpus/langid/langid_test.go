@@ -0,0 +1,89 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package langid
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetect(t *testing.T) {
+	id := New()
+	assert.Equal(t, []string{"de", "en", "es", "fr", "it", "nl", "pt"}, id.Languages())
+
+	testCases := map[string]string{
+		"the children went to school this morning":            "en",
+		"die Kinder sind heute Morgen in die Schule gegangen": "de",
+		"les enfants sont allés à l'école ce matin":           "fr",
+		"los niños fueron a la escuela esta mañana":           "es",
+		"i bambini sono andati a scuola stamattina":           "it",
+		"de kinderen zijn vanochtend naar school gegaan":      "nl",
+		"as crianças foram para a escola esta manhã":          "pt",
+	}
+	for text, expected := range testCases {
+		lang, ok := id.Detect(text)
+		assert.True(t, ok)
+		assert.Equal(t, expected, lang, text)
+	}
+
+	_, ok := id.Detect("123 !?")
+	assert.False(t, ok)
+}
+
+func TestDetectUnknown(t *testing.T) {
+	id := New()
+	for _, text := range []string{
+		"Все люди рождаются свободными и равными в своем достоинстве и правах",
+		"すべての人間は、生まれながらにして自由であり",
+		"Όλοι οι άνθρωποι γεννιούνται ελεύθεροι και ίσοι στην αξιοπρέπεια",
+		"Bütün insanlar hür, haysiyet ve haklar bakımından eşit doğarlar",
+		"Wszyscy ludzie rodzą się wolni i równi pod względem swej godności i swych praw",
+		"Kaikki ihmiset syntyvät vapaina ja tasavertaisina arvoltaan ja oikeuksiltaan",
+		"Minden emberi lény szabadon születik és egyenlő méltósága és joga van",
+	} {
+		lang, ok := id.Detect(text)
+		assert.False(t, ok, text)
+		assert.Equal(t, "", lang, text)
+	}
+}
+
+func TestAdd(t *testing.T) {
+	id := New()
+	assert.NoError(t, id.Add("xx", strings.NewReader("zzq qzz zqz zzq qzz")))
+	lang, _ := id.Detect("zzq zqz")
+	assert.Equal(t, "xx", lang)
+}
+
+func TestFilter(t *testing.T) {
+	input := "the weather is nice today\n\ndas Wetter ist heute schön\n42\nВсе люди рождаются свободными\n"
+	buf := &bytes.Buffer{}
+	stats, err := Filter(buf, strings.NewReader(input), New(), []string{"en"})
+	assert.NoError(t, err)
+	assert.Equal(t, "the weather is nice today\n\n42\n", buf.String())
+	assert.Equal(t, Stats{Lines: 5, Dropped: 2}, stats)
+
+	_, err = Filter(buf, strings.NewReader(input), New(), []string{"xx"})
+	assert.Error(t, err)
+
+	b, err := ioutil.ReadAll(NewReader(strings.NewReader(input), New(), []string{"en", "de"}))
+	assert.NoError(t, err)
+	assert.Equal(t, "the weather is nice today\n\ndas Wetter ist heute schön\n42\n", string(b))
+	_, err = ioutil.ReadAll(NewReader(strings.NewReader(input), New(), []string{"xx"}))
+	assert.Error(t, err)
+}
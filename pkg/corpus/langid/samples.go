@@ -0,0 +1,73 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package langid
+
+// samples are the texts of the common words to build the built-in profiles.
+var samples = map[string]string{
+	"de": `Der Mensch ist nicht dafür geschaffen, allein zu leben. Wir haben uns heute in der Stadt getroffen und
+über die Arbeit gesprochen. Es gibt viele Gründe, warum die Leute nicht mehr mit dem Zug fahren wollen, aber das
+Wetter ist schön und die Kinder spielen draußen im Garten. Ich habe keine Zeit, weil ich noch einen Brief schreiben
+muss. Sie sagte, dass sie morgen wieder nach Hause kommen würde, wenn es nicht regnet. Die Regierung hat
+beschlossen, die Steuern zu erhöhen, und viele Bürger sind darüber sehr unzufrieden. Auf dem Tisch liegt ein Buch,
+das mein Vater geschrieben hat. Wir werden sehen, was die Zukunft bringt, denn niemand weiß es genau. Zwischen den
+Häusern steht eine alte Kirche, die im letzten Jahrhundert gebaut wurde. Können Sie mir bitte sagen, wie spät es
+ist? Natürlich, es ist gleich zwölf Uhr. Deutschland und Österreich sind Nachbarländer mit einer gemeinsamen
+Sprache und Geschichte.`,
+	"en": `The man is not made to live alone. We met in the city today and talked about the work. There are many
+reasons why people do not want to take the train any more, but the weather is nice and the children are playing
+outside in the garden. I have no time because I still have to write a letter. She said that she would come back home
+tomorrow if it does not rain. The government has decided to raise the taxes, and many citizens are very unhappy
+about that. On the table there is a book which my father has written. We will see what the future brings, because
+nobody knows it exactly. Between the houses there stands an old church that was built in the last century. Could you
+please tell me what time it is? Of course, it is almost twelve o'clock. England and Scotland are neighbouring
+countries with a shared language and history, and they have been through a lot together.`,
+	"es": `El hombre no está hecho para vivir solo. Hoy nos encontramos en la ciudad y hablamos sobre el trabajo.
+Hay muchas razones por las que la gente ya no quiere viajar en tren, pero el tiempo es bueno y los niños juegan
+fuera en el jardín. No tengo tiempo porque todavía tengo que escribir una carta. Ella dijo que volvería a casa
+mañana si no llueve. El gobierno ha decidido subir los impuestos, y muchos ciudadanos están muy descontentos con
+eso. Sobre la mesa hay un libro que escribió mi padre. Veremos lo que trae el futuro, porque nadie lo sabe con
+exactitud. Entre las casas hay una iglesia antigua que fue construida en el siglo pasado. ¿Podría decirme qué hora
+es, por favor? Claro, son casi las doce. España y México son países con una lengua y una historia comunes.`,
+	"fr": `L'homme n'est pas fait pour vivre seul. Nous nous sommes rencontrés en ville aujourd'hui et nous avons
+parlé du travail. Il y a beaucoup de raisons pour lesquelles les gens ne veulent plus prendre le train, mais il fait
+beau et les enfants jouent dehors dans le jardin. Je n'ai pas le temps parce que je dois encore écrire une lettre.
+Elle a dit qu'elle rentrerait à la maison demain s'il ne pleut pas. Le gouvernement a décidé d'augmenter les impôts,
+et beaucoup de citoyens en sont très mécontents. Sur la table il y a un livre que mon père a écrit. Nous verrons ce
+que l'avenir nous apporte, car personne ne le sait exactement. Entre les maisons se trouve une vieille église qui a
+été construite au siècle dernier. Pourriez-vous me dire quelle heure il est, s'il vous plaît? Bien sûr, il est
+presque midi. La France et la Belgique sont des pays voisins avec une langue et une histoire communes.`,
+	"it": `L'uomo non è fatto per vivere da solo. Oggi ci siamo incontrati in città e abbiamo parlato del lavoro.
+Ci sono molte ragioni per cui la gente non vuole più prendere il treno, ma il tempo è bello e i bambini giocano fuori
+nel giardino. Non ho tempo perché devo ancora scrivere una lettera. Lei ha detto che sarebbe tornata a casa domani
+se non piove. Il governo ha deciso di aumentare le tasse, e molti cittadini sono molto scontenti di questo. Sul
+tavolo c'è un libro che ha scritto mio padre. Vedremo cosa porterà il futuro, perché nessuno lo sa con esattezza.
+Tra le case si trova una vecchia chiesa che è stata costruita nel secolo scorso. Potrebbe dirmi che ore sono, per
+favore? Certo, è quasi mezzogiorno. L'Italia e la Svizzera sono paesi vicini con una lingua e una storia comuni.`,
+	"nl": `De mens is niet gemaakt om alleen te leven. We hebben elkaar vandaag in de stad ontmoet en over het
+werk gesproken. Er zijn veel redenen waarom mensen niet meer met de trein willen reizen, maar het weer is mooi en de
+kinderen spelen buiten in de tuin. Ik heb geen tijd omdat ik nog een brief moet schrijven. Ze zei dat ze morgen weer
+naar huis zou komen als het niet regent. De regering heeft besloten de belastingen te verhogen, en veel burgers zijn
+daar erg ontevreden over. Op de tafel ligt een boek dat mijn vader heeft geschreven. We zullen zien wat de toekomst
+brengt, want niemand weet het precies. Tussen de huizen staat een oude kerk die in de vorige eeuw is gebouwd. Kunt u
+mij zeggen hoe laat het is? Natuurlijk, het is bijna twaalf uur. Nederland en België zijn buurlanden met een
+gemeenschappelijke taal en geschiedenis.`,
+	"pt": `O homem não foi feito para viver sozinho. Hoje nos encontramos na cidade e falamos sobre o trabalho.
+Há muitas razões pelas quais as pessoas já não querem viajar de comboio, mas o tempo está bom e as crianças brincam
+lá fora no jardim. Não tenho tempo porque ainda tenho de escrever uma carta. Ela disse que voltaria para casa amanhã
+se não chover. O governo decidiu aumentar os impostos, e muitos cidadãos estão muito descontentes com isso. Em cima
+da mesa há um livro que o meu pai escreveu. Veremos o que o futuro nos traz, porque ninguém sabe ao certo. Entre as
+casas existe uma igreja antiga que foi construída no século passado. Pode dizer-me que horas são, por favor? Claro,
+são quase doze horas. Portugal e o Brasil são países com uma língua e uma história em comum.`,
+}
@@ -0,0 +1,145 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package langid
+
+// udhr are the preamble and the first articles of the Universal Declaration of Human Rights,
+// the text translated into the most languages, to build the built-in profiles with samples.
+var udhr = map[string]string{
+	"de": `Da die Anerkennung der angeborenen Würde und der gleichen und unveräußerlichen Rechte aller Mitglieder
+der Gemeinschaft der Menschen die Grundlage von Freiheit, Gerechtigkeit und Frieden in der Welt bildet, da die
+Nichtanerkennung und Verachtung der Menschenrechte zu Akten der Barbarei geführt haben, die das Gewissen der
+Menschheit mit Empörung erfüllen, und da verkündet worden ist, dass einer Welt, in der die Menschen Rede- und
+Glaubensfreiheit und Freiheit von Furcht und Not genießen, das höchste Streben des Menschen gilt.
+Artikel 1. Alle Menschen sind frei und gleich an Würde und Rechten geboren. Sie sind mit Vernunft und Gewissen
+begabt und sollen einander im Geist der Brüderlichkeit begegnen.
+Artikel 2. Jeder hat Anspruch auf alle in dieser Erklärung verkündeten Rechte und Freiheiten ohne irgendeinen
+Unterschied, etwa nach Rasse, Hautfarbe, Geschlecht, Sprache, Religion, politischer oder sonstiger Überzeugung,
+nationaler oder sozialer Herkunft, Vermögen, Geburt oder sonstigem Stand. Des weiteren darf kein Unterschied
+gemacht werden auf Grund der politischen, rechtlichen oder internationalen Stellung des Landes oder Gebiets, dem
+eine Person angehört, gleichgültig ob dieses unabhängig ist, unter Treuhandschaft steht, keine Selbstregierung
+besitzt oder sonst in seiner Souveränität eingeschränkt ist.
+Artikel 3. Jeder hat das Recht auf Leben, Freiheit und Sicherheit der Person.
+Artikel 4. Niemand darf in Sklaverei oder Leibeigenschaft gehalten werden; Sklaverei und Sklavenhandel in allen
+ihren Formen sind verboten.
+Artikel 5. Niemand darf der Folter oder grausamer, unmenschlicher oder erniedrigender Behandlung oder Strafe
+unterworfen werden.`,
+	"en": `Whereas recognition of the inherent dignity and of the equal and inalienable rights of all members of
+the human family is the foundation of freedom, justice and peace in the world, whereas disregard and contempt for
+human rights have resulted in barbarous acts which have outraged the conscience of mankind, and the advent of a
+world in which human beings shall enjoy freedom of speech and belief and freedom from fear and want has been
+proclaimed as the highest aspiration of the common people, whereas it is essential, if man is not to be compelled
+to have recourse, as a last resort, to rebellion against tyranny and oppression, that human rights should be
+protected by the rule of law.
+Article 1. All human beings are born free and equal in dignity and rights. They are endowed with reason and
+conscience and should act towards one another in a spirit of brotherhood.
+Article 2. Everyone is entitled to all the rights and freedoms set forth in this Declaration, without distinction
+of any kind, such as race, colour, sex, language, religion, political or other opinion, national or social origin,
+property, birth or other status. Furthermore, no distinction shall be made on the basis of the political,
+jurisdictional or international status of the country or territory to which a person belongs, whether it be
+independent, trust, non-self-governing or under any other limitation of sovereignty.
+Article 3. Everyone has the right to life, liberty and security of person.
+Article 4. No one shall be held in slavery or servitude; slavery and the slave trade shall be prohibited in all
+their forms.
+Article 5. No one shall be subjected to torture or to cruel, inhuman or degrading treatment or punishment.`,
+	"es": `Considerando que la libertad, la justicia y la paz en el mundo tienen por base el reconocimiento de la
+dignidad intrínseca y de los derechos iguales e inalienables de todos los miembros de la familia humana;
+considerando que el desconocimiento y el menosprecio de los derechos humanos han originado actos de barbarie
+ultrajantes para la conciencia de la humanidad, y que se ha proclamado, como la aspiración más elevada del hombre,
+el advenimiento de un mundo en que los seres humanos, liberados del temor y de la miseria, disfruten de la libertad
+de palabra y de la libertad de creencias.
+Artículo 1. Todos los seres humanos nacen libres e iguales en dignidad y derechos y, dotados como están de razón y
+conciencia, deben comportarse fraternalmente los unos con los otros.
+Artículo 2. Toda persona tiene todos los derechos y libertades proclamados en esta Declaración, sin distinción
+alguna de raza, color, sexo, idioma, religión, opinión política o de cualquier otra índole, origen nacional o
+social, posición económica, nacimiento o cualquier otra condición. Además, no se hará distinción alguna fundada en
+la condición política, jurídica o internacional del país o territorio de cuya jurisdicción dependa una persona,
+tanto si se trata de un país independiente, como de un territorio bajo administración fiduciaria, no autónomo o
+sometido a cualquier otra limitación de soberanía.
+Artículo 3. Todo individuo tiene derecho a la vida, a la libertad y a la seguridad de su persona.
+Artículo 4. Nadie estará sometido a esclavitud ni a servidumbre; la esclavitud y la trata de esclavos están
+prohibidas en todas sus formas.
+Artículo 5. Nadie será sometido a torturas ni a penas o tratos crueles, inhumanos o degradantes.`,
+	"fr": `Considérant que la reconnaissance de la dignité inhérente à tous les membres de la famille humaine et de
+leurs droits égaux et inaliénables constitue le fondement de la liberté, de la justice et de la paix dans le monde.
+Considérant que la méconnaissance et le mépris des droits de l'homme ont conduit à des actes de barbarie qui
+révoltent la conscience de l'humanité et que l'avènement d'un monde où les êtres humains seront libres de parler et
+de croire, libérés de la terreur et de la misère, a été proclamé comme la plus haute aspiration de l'homme.
+Article premier. Tous les êtres humains naissent libres et égaux en dignité et en droits. Ils sont doués de raison
+et de conscience et doivent agir les uns envers les autres dans un esprit de fraternité.
+Article 2. Chacun peut se prévaloir de tous les droits et de toutes les libertés proclamés dans la présente
+Déclaration, sans distinction aucune, notamment de race, de couleur, de sexe, de langue, de religion, d'opinion
+politique ou de toute autre opinion, d'origine nationale ou sociale, de fortune, de naissance ou de toute autre
+situation. De plus, il ne sera fait aucune distinction fondée sur le statut politique, juridique ou international
+du pays ou du territoire dont une personne est ressortissante, que ce pays ou territoire soit indépendant, sous
+tutelle, non autonome ou soumis à une limitation quelconque de souveraineté.
+Article 3. Tout individu a droit à la vie, à la liberté et à la sûreté de sa personne.
+Article 4. Nul ne sera tenu en esclavage ni en servitude; l'esclavage et la traite des esclaves sont interdits sous
+toutes leurs formes.
+Article 5. Nul ne sera soumis à la torture, ni à des peines ou traitements cruels, inhumains ou dégradants.`,
+	"it": `Considerato che il riconoscimento della dignità inerente a tutti i membri della famiglia umana e dei loro
+diritti, uguali ed inalienabili, costituisce il fondamento della libertà, della giustizia e della pace nel mondo;
+considerato che il disconoscimento e il disprezzo dei diritti umani hanno portato ad atti di barbarie che offendono
+la coscienza dell'umanità, e che l'avvento di un mondo in cui gli esseri umani godano della libertà di parola e di
+credo e della libertà dal timore e dal bisogno è stato proclamato come la più alta aspirazione dell'uomo.
+Articolo 1. Tutti gli esseri umani nascono liberi ed eguali in dignità e diritti. Essi sono dotati di ragione e di
+coscienza e devono agire gli uni verso gli altri in spirito di fratellanza.
+Articolo 2. Ad ogni individuo spettano tutti i diritti e tutte le libertà enunciate nella presente Dichiarazione,
+senza distinzione alcuna, per ragioni di razza, di colore, di sesso, di lingua, di religione, di opinione politica
+o di altro genere, di origine nazionale o sociale, di ricchezza, di nascita o di altra condizione. Nessuna
+distinzione sarà inoltre stabilita sulla base dello statuto politico, giuridico o internazionale del paese o del
+territorio cui una persona appartiene, sia indipendente, o sottoposto ad amministrazione fiduciaria o non autonomo,
+o soggetto a qualsiasi limitazione di sovranità.
+Articolo 3. Ogni individuo ha diritto alla vita, alla libertà ed alla sicurezza della propria persona.
+Articolo 4. Nessun individuo potrà essere tenuto in stato di schiavitù o di servitù; la schiavitù e la tratta degli
+schiavi saranno proibite sotto qualsiasi forma.
+Articolo 5. Nessun individuo potrà essere sottoposto a tortura o a trattamento o punizioni crudeli, inumani o
+degradanti.`,
+	"nl": `Overwegende, dat erkenning van de inherente waardigheid en van de gelijke en onvervreemdbare rechten van
+alle leden van de mensengemeenschap grondslag is voor de vrijheid, gerechtigheid en vrede in de wereld;
+overwegende, dat terzijdestelling van en minachting voor de rechten van de mens geleid hebben tot barbaarse
+handelingen, die het geweten van de mensheid geweld hebben aangedaan en dat de komst van een wereld, waarin de
+mensen vrijheid van meningsuiting en geloof zullen genieten, en vrij zullen zijn van vrees en gebrek, is verkondigd
+als het hoogste ideaal van iedere mens.
+Artikel 1. Alle mensen worden vrij en gelijk in waardigheid en rechten geboren. Zij zijn begiftigd met verstand en
+geweten, en behoren zich jegens elkander in een geest van broederschap te gedragen.
+Artikel 2. Een ieder heeft aanspraak op alle rechten en vrijheden, in deze Verklaring opgesomd, zonder enig
+onderscheid van welke aard ook, zoals ras, kleur, geslacht, taal, godsdienst, politieke of andere overtuiging,
+nationale of maatschappelijke afkomst, eigendom, geboorte of andere status. Verder zal geen onderscheid worden
+gemaakt op grond van de politieke, juridische of internationale status van het land of gebied, waartoe iemand
+behoort, onverschillig of het een onafhankelijk land, trustgebied of een niet-zelfbesturend gebied betreft, dan
+wel of de soevereiniteit van het land op enigerlei andere wijze beperkt is.
+Artikel 3. Een ieder heeft het recht op leven, vrijheid en onschendbaarheid van zijn persoon.
+Artikel 4. Niemand zal in slavernij of horigheid gehouden worden. Slavernij en slavenhandel in welke vorm dan ook
+zijn verboden.
+Artikel 5. Niemand zal onderworpen worden aan folteringen, noch aan wrede, onmenselijke of onterende behandeling
+of bestraffing.`,
+	"pt": `Considerando que o reconhecimento da dignidade inerente a todos os membros da família humana e dos seus
+direitos iguais e inalienáveis constitui o fundamento da liberdade, da justiça e da paz no mundo; considerando que
+o desconhecimento e o desprezo dos direitos do homem conduziram a actos de barbárie que revoltam a consciência da
+Humanidade e que o advento de um mundo em que os seres humanos sejam livres de falar e de crer, libertos do terror
+e da miséria, foi proclamado como a mais alta inspiração do homem.
+Artigo 1. Todos os seres humanos nascem livres e iguais em dignidade e em direitos. Dotados de razão e de
+consciência, devem agir uns para com os outros em espírito de fraternidade.
+Artigo 2. Todos os seres humanos podem invocar os direitos e as liberdades proclamados na presente Declaração, sem
+distinção alguma, nomeadamente de raça, de cor, de sexo, de língua, de religião, de opinião política ou outra, de
+origem nacional ou social, de fortuna, de nascimento ou de qualquer outra situação. Além disso, não será feita
+nenhuma distinção fundada no estatuto político, jurídico ou internacional do país ou do território da naturalidade
+da pessoa, seja esse país ou território independente, sob tutela, autónomo ou sujeito a alguma limitação de
+soberania.
+Artigo 3. Todo o indivíduo tem direito à vida, à liberdade e à segurança pessoal.
+Artigo 4. Ninguém será mantido em escravatura ou em servidão; a escravatura e o trato dos escravos, sob todas as
+formas, são proibidos.
+Artigo 5. Ninguém será submetido a tortura nem a penas ou tratamentos cruéis, desumanos ou degradantes.`,
+}
@@ -0,0 +1,238 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package markup
+
+import (
+	"bufio"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/util/fileutil"
+)
+
+// Type is the markup to strip from the corpus.
+type Type = string
+
+const (
+	HTML     Type = "html"
+	Markdown Type = "markdown"
+)
+
+// InvalidTypeError is the error for the unknown markup.
+func InvalidTypeError(typ Type) error {
+	return errors.Errorf("invalid markup: %s not in %s|%s", typ, HTML, Markdown)
+}
+
+// Strip writes the text of r without the markup of typ.
+func Strip(w io.Writer, r io.Reader, typ Type) error {
+	switch typ {
+	case HTML:
+		return stripHTML(w, r)
+	case Markdown:
+		return stripMarkdown(w, r)
+	default:
+		return InvalidTypeError(typ)
+	}
+}
+
+// NewReader returns the reader of the text of r without the markup of typ, which is stripped in background.
+func NewReader(r io.Reader, typ Type) io.Reader {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(Strip(pw, r, typ))
+	}()
+	return pr
+}
+
+// blocks are the tags which break the lines, and the others are removed without spaces, e.g. <b>.
+var blocks = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "br": true, "dd": true, "div": true,
+	"dl": true, "dt": true, "figcaption": true, "footer": true, "h1": true, "h2": true, "h3": true, "h4": true,
+	"h5": true, "h6": true, "header": true, "hr": true, "li": true, "nav": true, "ol": true, "p": true,
+	"pre": true, "section": true, "table": true, "td": true, "th": true, "title": true, "tr": true, "ul": true,
+}
+
+// skips are the tags whose contents are not the text.
+var skips = map[string]bool{
+	"script": true, "style": true, "noscript": true, "template": true,
+}
+
+func stripHTML(w io.Writer, r io.Reader) error {
+	br := bufio.NewReader(r)
+	buf := bufio.NewWriter(w)
+	var text strings.Builder
+	flush := func() {
+		buf.WriteString(html.UnescapeString(text.String()))
+		text.Reset()
+	}
+	for {
+		c, _, err := br.ReadRune()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		switch c {
+		case '<':
+			flush()
+			tag, err := readTag(br)
+			if err != nil {
+				return err
+			}
+			name := tagName(tag)
+			if strings.HasPrefix(tag, "!--") && !strings.HasSuffix(tag, "--") {
+				if err := skipUntil(br, "-->"); err != nil {
+					return err
+				}
+			} else if skips[name] && !strings.HasPrefix(tag, "/") && !strings.HasSuffix(tag, "/") {
+				if err := skipUntil(br, "</"+name); err != nil {
+					return err
+				}
+				if _, err := readTag(br); err != nil {
+					return err
+				}
+			} else if blocks[name] {
+				buf.WriteByte('\n')
+			}
+		case '\n':
+			flush()
+			buf.WriteByte('\n')
+		default:
+			text.WriteRune(c)
+		}
+	}
+	flush()
+	return buf.Flush()
+}
+
+// readTag reads the tag after <, with the quoted values of the attributes, until >.
+func readTag(br *bufio.Reader) (string, error) {
+	var (
+		tag   strings.Builder
+		quote rune
+	)
+	for {
+		c, _, err := br.ReadRune()
+		if err == io.EOF {
+			return tag.String(), nil
+		} else if err != nil {
+			return "", err
+		}
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return tag.String(), nil
+		}
+		tag.WriteRune(c)
+	}
+}
+
+func tagName(tag string) string {
+	tag = strings.TrimPrefix(tag, "/")
+	if end := strings.IndexAny(tag, " \t\r\n/"); end >= 0 {
+		tag = tag[:end]
+	}
+	return strings.ToLower(tag)
+}
+
+// skipUntil discards the runes until the end of s, case-insensitively.
+func skipUntil(br *bufio.Reader, s string) error {
+	s = strings.ToLower(s)
+	var window []byte
+	for {
+		b, err := br.ReadByte()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if 'A' <= b && b <= 'Z' {
+			b += 'a' - 'A'
+		}
+		window = append(window, b)
+		if len(window) > len(s) {
+			window = window[1:]
+		}
+		if string(window) == s {
+			return nil
+		}
+	}
+}
+
+var (
+	fenceRe     = regexp.MustCompile("^\\s*(```|~~~)")
+	headingRe   = regexp.MustCompile(`^\s{0,3}#{1,6}\s+|\s+#+\s*$`)
+	ruleRe      = regexp.MustCompile(`^\s{0,3}([-*_]\s*){3,}$`)
+	tableRe     = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+	prefixRe    = regexp.MustCompile(`^\s*((>\s*)+|[-*+]\s+(\[[ xX]\]\s+)?|\d+[.)]\s+)`)
+	refRe       = regexp.MustCompile(`^\s{0,3}\[[^\]]+\]:\s+\S+`)
+	imageRe     = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	linkRe      = regexp.MustCompile(`\[([^\]]*)\](\([^)]*\)|\[[^\]]*\])`)
+	autolinkRe  = regexp.MustCompile(`<(https?://|mailto:)[^>]*>`)
+	tagRe       = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+	codeRe      = regexp.MustCompile("`+")
+	emphOpenRe  = regexp.MustCompile(`(^|[^\w*~])(\*{1,3}|_{1,3}|~~)([^\s*_~])`)
+	emphCloseRe = regexp.MustCompile(`([^\s*_~])(\*{1,3}|_{1,3}|~~)([^\w*~]|$)`)
+)
+
+func stripMarkdown(w io.Writer, r io.Reader) error {
+	buf := bufio.NewWriter(w)
+	s := fileutil.NewScanner(r, bufio.ScanLines)
+	var fenced bool
+	for s.Scan() {
+		line := s.Text()
+		if fenceRe.MatchString(line) {
+			fenced = !fenced
+			line = ""
+		} else if fenced || ruleRe.MatchString(line) || tableRe.MatchString(line) || refRe.MatchString(line) {
+			line = ""
+		} else {
+			line = stripMarkdownLine(line)
+		}
+		buf.WriteString(line)
+		if err := buf.WriteByte('\n'); err != nil {
+			return err
+		}
+	}
+	if err := s.Err(); err != nil && err != io.EOF {
+		return errors.Wrap(err, "failed to scan")
+	}
+	return buf.Flush()
+}
+
+func stripMarkdownLine(line string) string {
+	line = headingRe.ReplaceAllString(line, "")
+	line = prefixRe.ReplaceAllString(line, "")
+	line = imageRe.ReplaceAllString(line, "$1")
+	line = linkRe.ReplaceAllString(line, "$1")
+	line = autolinkRe.ReplaceAllString(line, "")
+	line = tagRe.ReplaceAllString(line, "")
+	line = codeRe.ReplaceAllString(line, "")
+	line = emphOpenRe.ReplaceAllString(line, "$1$3")
+	line = emphCloseRe.ReplaceAllString(line, "$1$3")
+	if strings.Contains(line, "|") {
+		line = strings.Join(strings.Fields(strings.Replace(line, "|", " ", -1)), " ")
+	}
+	return html.UnescapeString(line)
+}
@@ -0,0 +1,78 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package markup
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStripHTML(t *testing.T) {
+	input := `<!DOCTYPE html>
+<html><head><title>Fish &amp; Chips</title>
+<style>p { color: red; }</style>
+<script type="text/javascript">if (a < b) { x = "</p>"; }</script></head>
+<body><!-- a
+comment --><p class="x>y">The <b>quick</b> brown&nbsp;fox</p><p>jumps&#33;<br/>over</p></body></html>
+`
+	buf := &bytes.Buffer{}
+	assert.NoError(t, Strip(buf, strings.NewReader(input), HTML))
+	assert.Equal(t, []string{"Fish & Chips", "The quick brown fox", "jumps!", "over"}, strings.FieldsFunc(buf.String(), func(r rune) bool {
+		return r == '\n'
+	}))
+}
+
+func TestStripMarkdown(t *testing.T) {
+	input := "# Getting *started* #\n" +
+		"\n" +
+		"> Read the [docs](https://example.com) and ![the logo](logo.png) first.\n" +
+		"- **bold** and _emphasis_ with snake_case and `code`\n" +
+		"1. ~~old~~ <https://example.com> <em>new</em> &amp; more\n" +
+		"```go\n" +
+		"fmt.Println(\"code\")\n" +
+		"```\n" +
+		"| a | b |\n" +
+		"|---|:-:|\n" +
+		"---\n" +
+		"[docs]: https://example.com\n"
+	expected := "Getting started\n" +
+		"\n" +
+		"Read the docs and the logo first.\n" +
+		"bold and emphasis with snake_case and code\n" +
+		"old  new & more\n" +
+		"\n" +
+		"\n" +
+		"\n" +
+		"a b\n" +
+		"\n" +
+		"\n" +
+		"\n"
+	buf := &bytes.Buffer{}
+	assert.NoError(t, Strip(buf, strings.NewReader(input), Markdown))
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestNewReader(t *testing.T) {
+	b, err := ioutil.ReadAll(NewReader(strings.NewReader("<p>a</p>"), HTML))
+	assert.NoError(t, err)
+	assert.Equal(t, "\na\n", string(b))
+
+	_, err = ioutil.ReadAll(NewReader(strings.NewReader("a"), "rst"))
+	assert.Error(t, err)
+}
@@ -15,9 +15,7 @@
 package memory
 
 import (
-	"fmt"
 	"io"
-	"strings"
 
 	"github.com/ynqa/wego/pkg/corpus"
 	co "github.com/ynqa/wego/pkg/corpus/cooccurrence"
@@ -35,17 +33,26 @@ type Corpus struct {
 	maxLen int
 	idoc   []int
 
-	toLower bool
-	filters cpsutil.Filters
+	toLower  bool
+	maxVocab int
+	filters  cpsutil.Filters
 }
 
-func New(doc io.ReadSeeker, toLower bool, maxCount, minCount int) corpus.Corpus {
+func New(doc io.ReadSeeker, toLower, mergeCase bool, maxCount, minCount, maxVocab, hashBuckets int) corpus.Corpus {
+	dic := dictionary.New()
+	if hashBuckets > 0 {
+		dic = dictionary.NewHashed(hashBuckets)
+	}
+	if mergeCase {
+		dic.MergeCase()
+	}
 	return &Corpus{
 		doc:  doc,
-		dic:  dictionary.New(),
+		dic:  dic,
 		idoc: make([]int, 0),
 
-		toLower: toLower,
+		toLower:  toLower,
+		maxVocab: maxVocab,
 		filters: cpsutil.Filters{
 			cpsutil.MaxCount(maxCount),
 			cpsutil.MinCount(minCount),
@@ -56,7 +63,7 @@ func New(doc io.ReadSeeker, toLower bool, maxCount, minCount int) corpus.Corpus
 func (c *Corpus) IndexedDoc() []int {
 	var res []int
 	for _, id := range c.idoc {
-		if c.filters.Any(id, c.dic) {
+		if id < 0 || c.filters.Any(id, c.dic) {
 			continue
 		}
 		res = append(res, id)
@@ -64,7 +71,8 @@ func (c *Corpus) IndexedDoc() []int {
 	return res
 }
 
-func (c *Corpus) BatchWords(chan []int, int) error {
+func (c *Corpus) BatchWords(ch chan []int, _ int) error {
+	close(ch)
 	return nil
 }
 
@@ -82,18 +90,17 @@ func (c *Corpus) Len() int {
 
 func (c *Corpus) Load(with *corpus.WithCooccurrence, verbose *verbose.Verbose, logBatch int) error {
 	clk := clock.New()
-	if err := cpsutil.ReadWord(c.doc, func(word string) error {
+	if err := cpsutil.ReadWordBytes(c.doc, func(word []byte) error {
 		if c.toLower {
-			word = strings.ToLower(word)
+			word = cpsutil.LowerBytes(word)
 		}
 
-		c.dic.Add(word)
-		id, _ := c.dic.ID(word)
+		id := c.dic.AddBytes(word)
 		c.maxLen++
 		c.idoc = append(c.idoc, id)
 		verbose.Do(func() {
 			if c.maxLen%logBatch == 0 {
-				fmt.Printf("read %d words %v\r", c.maxLen, clk.AllElapsed())
+				verbose.Printf("read %d words %v\r", c.maxLen, clk.AllElapsed())
 			}
 		})
 
@@ -102,35 +109,52 @@ func (c *Corpus) Load(with *corpus.WithCooccurrence, verbose *verbose.Verbose, l
 		return err
 	}
 	verbose.Do(func() {
-		fmt.Printf("read %d words %v\r\n", c.maxLen, clk.AllElapsed())
+		verbose.Printf("read %d words %v\r\n", c.maxLen, clk.AllElapsed())
 	})
 
+	// the pruned words remain as -1 in the doc to keep the distances of the others.
+	if remap := c.dic.Prune(c.maxVocab); remap != nil {
+		c.maxLen = 0
+		for i, id := range c.idoc {
+			c.idoc[i] = remap[id]
+			if remap[id] >= 0 {
+				c.maxLen++
+			}
+		}
+	}
+
 	clk = clock.New()
 	var (
 		err    error
 		cursor int
 	)
 	if with != nil {
-		c.cooc, err = co.New(with.CountType)
+		c.cooc, err = with.New()
 		if err != nil {
 			return err
 		}
 
 		for i := 0; i < len(c.idoc); i++ {
+			if c.idoc[i] < 0 {
+				continue
+			}
 			for j := i + 1; j < len(c.idoc) && j <= i+with.Window; j++ {
-				if err = c.cooc.Add(c.idoc[i], c.idoc[j]); err != nil {
+				if c.idoc[j] < 0 {
+					continue
+				}
+				if err = c.cooc.Add(c.idoc[i], c.idoc[j], j-i); err != nil {
 					return err
 				}
 				cursor++
 				verbose.Do(func() {
 					if cursor%logBatch == 0 {
-						fmt.Printf("read %d tuples %v\r", cursor, clk.AllElapsed())
+						verbose.Printf("read %d tuples %v\r", cursor, clk.AllElapsed())
 					}
 				})
 			}
 		}
 		verbose.Do(func() {
-			fmt.Printf("read %d tuples %v\r\n", cursor, clk.AllElapsed())
+			verbose.Printf("read %d tuples %v\r\n", cursor, clk.AllElapsed())
 		})
 	}
 
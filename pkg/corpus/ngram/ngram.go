@@ -0,0 +1,139 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ngram
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/util/fileutil"
+)
+
+var (
+	defaultMaxVocab = 10000000
+	defaultMinCount = 5
+	defaultN        = 2
+	defaultSep      = "_"
+	defaultToLower  = false
+)
+
+type Options struct {
+	// MaxVocab bounds the number of the n-grams counted at once, by pruning the infrequent ones
+	// while counting as word2phrase. 0 means unlimited.
+	MaxVocab int
+	MinCount int
+	N        int
+	Sep      string
+	ToLower  bool
+}
+
+func DefaultOptions() Options {
+	return Options{
+		MaxVocab: defaultMaxVocab,
+		MinCount: defaultMinCount,
+		N:        defaultN,
+		Sep:      defaultSep,
+		ToLower:  defaultToLower,
+	}
+}
+
+func (opts Options) Validate() error {
+	if opts.N < 1 {
+		return errors.Errorf("n must be >= 1, got %d", opts.N)
+	} else if opts.MaxVocab < 0 {
+		return errors.Errorf("max-vocab must be >= 0, got %d", opts.MaxVocab)
+	}
+	return nil
+}
+
+// Write writes the lines of r with the sliding n-grams of the words as the tokens, e.g. new_york york_city
+// for new york city, where the n-grams don't cross the lines and the ones under MinCount are dropped.
+func Write(w io.Writer, r io.ReadSeeker, opts Options) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	counts, err := count(r, opts)
+	if err != nil {
+		return err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	buf := bufio.NewWriter(w)
+	if err := scan(r, opts, func(ngrams []string) error {
+		var n int
+		for _, ng := range ngrams {
+			if counts[ng] < opts.MinCount {
+				continue
+			}
+			if n > 0 {
+				buf.WriteByte(' ')
+			}
+			buf.WriteString(ng)
+			n++
+		}
+		return buf.WriteByte('\n')
+	}); err != nil {
+		return err
+	}
+	return buf.Flush()
+}
+
+func count(r io.Reader, opts Options) (map[string]int, error) {
+	counts := make(map[string]int)
+	reduce := 1
+	if err := scan(r, opts, func(ngrams []string) error {
+		for _, ng := range ngrams {
+			counts[ng]++
+		}
+		if opts.MaxVocab > 0 && len(counts) > opts.MaxVocab {
+			for ng, c := range counts {
+				if c <= reduce {
+					delete(counts, ng)
+				}
+			}
+			reduce++
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+func scan(r io.Reader, opts Options, fn func([]string) error) error {
+	s := fileutil.NewScanner(r, bufio.ScanLines)
+	for s.Scan() {
+		line := s.Text()
+		if opts.ToLower {
+			line = strings.ToLower(line)
+		}
+		words := strings.Fields(line)
+		var ngrams []string
+		for i := 0; i+opts.N <= len(words); i++ {
+			ngrams = append(ngrams, strings.Join(words[i:i+opts.N], opts.Sep))
+		}
+		if err := fn(ngrams); err != nil {
+			return err
+		}
+	}
+	if err := s.Err(); err != nil && err != io.EOF {
+		return errors.Wrap(err, "failed to scan")
+	}
+	return nil
+}
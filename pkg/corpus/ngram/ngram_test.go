@@ -0,0 +1,64 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ngram
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite(t *testing.T) {
+	text := "New York city\nnew york times\nin new york\n"
+	testCases := []struct {
+		name   string
+		opts   Options
+		expect string
+	}{
+		{
+			name:   "bigram",
+			opts:   Options{MinCount: 1, N: 2, Sep: "_"},
+			expect: "New_York York_city\nnew_york york_times\nin_new new_york\n",
+		},
+		{
+			name:   "min count",
+			opts:   Options{MinCount: 2, N: 2, Sep: "_", ToLower: true},
+			expect: "new_york\nnew_york\nnew_york\n",
+		},
+		{
+			name:   "trigram",
+			opts:   Options{MinCount: 1, N: 3, Sep: "+", ToLower: true},
+			expect: "new+york+city\nnew+york+times\nin+new+york\n",
+		},
+		// york_city and york_times are pruned when york_times exceeds MaxVocab.
+		{
+			name:   "pruned while counting",
+			opts:   Options{MaxVocab: 2, MinCount: 1, N: 2, Sep: "_", ToLower: true},
+			expect: "new_york\nnew_york\nin_new new_york\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			assert.NoError(t, Write(&buf, strings.NewReader(text), tc.opts))
+			assert.Equal(t, tc.expect, buf.String())
+		})
+	}
+
+	assert.Error(t, Write(&bytes.Buffer{}, strings.NewReader(text), Options{N: 0}))
+}
@@ -0,0 +1,100 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pairs
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/ynqa/wego/pkg/corpus/dictionary"
+	"github.com/ynqa/wego/pkg/util/fileutil"
+)
+
+// Pairs is the (word, context) pairs to train like word2vecf,
+// the contexts are tracked by the separate dictionary.
+type Pairs struct {
+	dic    *dictionary.Dictionary
+	ctxDic *dictionary.Dictionary
+	ids    []int
+}
+
+func New() *Pairs {
+	return &Pairs{
+		dic:    dictionary.New(),
+		ctxDic: dictionary.New(),
+	}
+}
+
+func (p *Pairs) Add(word, ctx string) {
+	p.dic.Add(word)
+	p.ctxDic.Add(ctx)
+	id, _ := p.dic.ID(word)
+	cid, _ := p.ctxDic.ID(ctx)
+	p.ids = append(p.ids, id, cid)
+}
+
+func (p *Pairs) Dictionary() *dictionary.Dictionary {
+	return p.dic
+}
+
+func (p *Pairs) ContextDictionary() *dictionary.Dictionary {
+	return p.ctxDic
+}
+
+func (p *Pairs) Len() int {
+	return len(p.ids) / 2
+}
+
+// Pair returns the ids of word and context.
+func (p *Pairs) Pair(i int) (int, int) {
+	return p.ids[2*i], p.ids[2*i+1]
+}
+
+// LoadLabeled reads the lines mixing words and the labeled entities (e.g. `__label__sports`),
+// like StarSpace. The words are paired with the words in the window,
+// and the entities are paired with all words in the line, so both are embedded into the same space.
+func LoadLabeled(r io.Reader, prefix string, window int, toLower bool) (*Pairs, error) {
+	p := New()
+	s := fileutil.NewScanner(r, bufio.ScanLines)
+	for s.Scan() {
+		var words, labels []string
+		for _, tok := range strings.Fields(s.Text()) {
+			if strings.HasPrefix(tok, prefix) {
+				labels = append(labels, tok)
+				continue
+			}
+			if toLower {
+				tok = strings.ToLower(tok)
+			}
+			words = append(words, tok)
+		}
+		for i, word := range words {
+			for j := i - window; j <= i+window; j++ {
+				if j == i || j < 0 || j >= len(words) {
+					continue
+				}
+				p.Add(word, words[j])
+			}
+			for _, label := range labels {
+				p.Add(label, word)
+			}
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
@@ -0,0 +1,41 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pairs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadLabeled(t *testing.T) {
+	p, err := LoadLabeled(strings.NewReader("__label__x A b\nc\n"), "__label__", 1, true)
+	assert.NoError(t, err)
+
+	var res []string
+	for i := 0; i < p.Len(); i++ {
+		w, c := p.Pair(i)
+		word, _ := p.Dictionary().Word(w)
+		ctx, _ := p.ContextDictionary().Word(c)
+		res = append(res, word+" "+ctx)
+	}
+	assert.Equal(t, []string{
+		"a b",
+		"__label__x a",
+		"b a",
+		"__label__x b",
+	}, res)
+}
@@ -0,0 +1,466 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// FlightScheme is the scheme of Arrow Flight over TLS,
+// e.g. grpc+tls://store:443?ticket=docs&column=body.
+const FlightScheme = "grpc+tls"
+
+// FlightTokenEnv is the environment variable of the bearer token of Arrow Flight, which is kept out of the URI
+// so that it isn't leaked by the errors, the manifests or the process list.
+const FlightTokenEnv = "WEGO_FLIGHT_TOKEN"
+
+func init() {
+	Register(FlightScheme, func(u *url.URL) (Source, error) {
+		return openFlight(u, &http.Client{
+			Transport: &http.Transport{
+				ForceAttemptHTTP2: true,
+				TLSClientConfig:   &tls.Config{NextProtos: []string{"h2"}},
+			},
+		})
+	})
+}
+
+// the enums of Schema.fbs and Message.fbs.
+const (
+	headerSchema      = 1
+	headerRecordBatch = 3
+
+	typeNull          = 1
+	typeBinary        = 4
+	typeUtf8          = 5
+	typeList          = 12
+	typeStruct        = 13
+	typeUnion         = 14
+	typeFixedSizeList = 16
+	typeMap           = 17
+	typeLargeBinary   = 19
+	typeLargeUtf8     = 20
+	typeLargeList     = 21
+	typeRunEndEncoded = 22
+	typeBinaryView    = 23
+	typeUtf8View      = 24
+	typeListView      = 25
+	typeLargeListView = 26
+)
+
+// the fields of Flight.proto.
+const (
+	flightServiceDoGet = "/arrow.flight.protocol.FlightService/DoGet"
+
+	flightTicket     = 1
+	flightDataHeader = 2
+	flightDataBody   = 1000
+
+	grpcFrameHeaderSize = 5
+)
+
+// flightSource reads the string column of the record batches of DoGet by the ticket.
+type flightSource struct {
+	client *http.Client
+	url    string
+	ticket []byte
+	column string
+	token  string
+}
+
+// openFlight opens the stream of grpc+tls://host:port?ticket=...&column=..., where the optional token of
+// FlightTokenEnv is sent as the bearer token.
+func openFlight(u *url.URL, client *http.Client) (Source, error) {
+	params := u.Query()
+	if _, ok := params["token"]; ok {
+		return nil, errors.Errorf("token must not be set in the URI of %s source, set $%s instead", u.Scheme, FlightTokenEnv)
+	}
+	src := &flightSource{
+		client: client,
+		url:    "https://" + u.Host + flightServiceDoGet,
+		ticket: []byte(params.Get("ticket")),
+		column: params.Get("column"),
+		token:  os.Getenv(FlightTokenEnv),
+	}
+	if len(src.ticket) == 0 {
+		return nil, errors.Errorf("ticket must be set in %s source", u.Scheme)
+	}
+	if src.column == "" {
+		return nil, errors.Errorf("column must be set in %s source", u.Scheme)
+	}
+	return src, nil
+}
+
+func (s *flightSource) Read(ctx context.Context, fn func(string) error) error {
+	// Ticket is the message of the bytes field 1.
+	ticket := appendBytes(nil, flightTicket, s.ticket)
+	body := make([]byte, grpcFrameHeaderSize, grpcFrameHeaderSize+len(ticket))
+	binary.BigEndian.PutUint32(body[1:], uint32(len(ticket)))
+	body = append(body, ticket...)
+
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	res, err := s.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to call DoGet")
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return errors.Errorf("failed to call DoGet: %s", res.Status)
+	}
+	// the error without the messages is returned in the headers.
+	if err := grpcStatus(res.Header); err != nil {
+		return err
+	}
+
+	dec := &flightDecoder{column: s.column, fn: fn}
+	header := make([]byte, grpcFrameHeaderSize)
+	for {
+		if _, err := io.ReadFull(res.Body, header); err == io.EOF {
+			break
+		} else if err != nil {
+			return errors.Wrap(err, "failed to read FlightData")
+		}
+		if header[0] != 0 {
+			return errors.New("compressed FlightData is not supported")
+		}
+		msg := make([]byte, binary.BigEndian.Uint32(header[1:]))
+		if _, err := io.ReadFull(res.Body, msg); err != nil {
+			return errors.Wrap(err, "failed to read FlightData")
+		}
+		if err := dec.decode(msg); err != nil {
+			return err
+		}
+	}
+	return grpcStatus(res.Trailer)
+}
+
+func (s *flightSource) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+func grpcStatus(h http.Header) error {
+	status := h.Get("Grpc-Status")
+	if status == "" || status == "0" {
+		return nil
+	}
+	msg, _ := url.PathUnescape(h.Get("Grpc-Message"))
+	return errors.Errorf("DoGet failed with status %s: %s", status, msg)
+}
+
+// flightDecoder converts the column of FlightData of the Arrow IPC messages into the texts.
+type flightDecoder struct {
+	column string
+	fn     func(string) error
+
+	// node and buffer are the indices of the column in the flattened fields, which are set by the schema.
+	node, buffer int
+	large, found bool
+}
+
+func (d *flightDecoder) decode(data []byte) (err error) {
+	// the flatbuffers are read without the bounds checks of the offsets.
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("invalid Arrow message: %v", r)
+		}
+	}()
+	var header, body []byte
+	for len(data) > 0 {
+		field, wire, n := protoKey(data)
+		if n <= 0 {
+			return errors.New("invalid FlightData")
+		}
+		data = data[n:]
+		var v []byte
+		if v, data, n = protoSkip(data, wire); n < 0 {
+			return errors.New("invalid FlightData")
+		}
+		switch field {
+		case flightDataHeader:
+			header = v
+		case flightDataBody:
+			body = v
+		}
+	}
+	if len(header) == 0 {
+		// app_metadata only.
+		return nil
+	}
+	msg, err := root(header)
+	if err != nil {
+		return err
+	}
+	switch msg.uint(1, 1) {
+	case headerSchema:
+		return d.schema(msg.table(2))
+	case headerRecordBatch:
+		if !d.found {
+			return errors.New("record batch precedes schema")
+		}
+		return d.recordBatch(msg.table(2), body)
+	}
+	// the dictionary batches are not referred by the string column.
+	return nil
+}
+
+func (d *flightDecoder) schema(schema fbTable) error {
+	d.node, d.buffer, d.found = 0, 0, false
+	for _, f := range schema.tables(1) {
+		typ := f.uint(2, 1)
+		if f.string(0) == d.column {
+			if typ != typeUtf8 && typ != typeLargeUtf8 {
+				return errors.Errorf("column %s must be utf8 or large_utf8, but type %d", d.column, typ)
+			}
+			if f.field(4) != 0 {
+				return errors.Errorf("dictionary-encoded column %s is not supported", d.column)
+			}
+			d.large, d.found = typ == typeLargeUtf8, true
+			return nil
+		}
+		nodes, buffers, err := layout(f)
+		if err != nil {
+			return err
+		}
+		d.node += nodes
+		d.buffer += buffers
+	}
+	return errors.Errorf("column %s is not in the schema", d.column)
+}
+
+// layout returns the number of the field nodes and the buffers of the field and its children in pre-order.
+func layout(f fbTable) (int, int, error) {
+	var buffers int
+	switch typ := f.uint(2, 1); typ {
+	case typeNull:
+	case typeBinary, typeUtf8, typeLargeBinary, typeLargeUtf8:
+		buffers = 3
+	case typeStruct, typeFixedSizeList:
+		buffers = 1
+	case typeList, typeMap, typeLargeList:
+		buffers = 2
+	case typeUnion, typeRunEndEncoded, typeBinaryView, typeUtf8View, typeListView, typeLargeListView:
+		return 0, 0, errors.Errorf("column %s of type %d can't be skipped", f.string(0), typ)
+	default:
+		// the primitives of the validity and the values.
+		buffers = 2
+	}
+	nodes := 1
+	for _, c := range f.tables(5) {
+		n, b, err := layout(c)
+		if err != nil {
+			return 0, 0, err
+		}
+		nodes += n
+		buffers += b
+	}
+	return nodes, buffers, nil
+}
+
+func (d *flightDecoder) recordBatch(batch fbTable, body []byte) error {
+	if batch.field(3) != 0 {
+		return errors.New("compressed record batch is not supported")
+	}
+	nodes, n := batch.vector(1)
+	if d.node >= n {
+		return errors.New("invalid record batch: the field node is missing")
+	}
+	rows := int(batch.int64(nodes + 16*d.node))
+	nulls := batch.int64(nodes + 16*d.node + 8)
+
+	buffers, n := batch.vector(2)
+	if d.buffer+2 >= n {
+		return errors.New("invalid record batch: the buffers are missing")
+	}
+	bufs := make([][]byte, 3)
+	for i := range bufs {
+		pos := buffers + 16*(d.buffer+i)
+		offset, length := batch.int64(pos), batch.int64(pos+8)
+		if offset < 0 || length < 0 || offset+length > int64(len(body)) {
+			return errors.New("invalid record batch: the buffer is out of the body")
+		}
+		bufs[i] = body[offset : offset+length]
+	}
+	validity, offsets, values := bufs[0], bufs[1], bufs[2]
+
+	width := 4
+	if d.large {
+		width = 8
+	}
+	if len(offsets) < width*(rows+1) {
+		return errors.New("invalid record batch: the offsets are short")
+	}
+	offset := func(i int) int64 {
+		if d.large {
+			return int64(binary.LittleEndian.Uint64(offsets[8*i:]))
+		}
+		return int64(int32(binary.LittleEndian.Uint32(offsets[4*i:])))
+	}
+	for i := 0; i < rows; i++ {
+		if nulls > 0 && len(validity) > i/8 && validity[i/8]&(1<<uint(i%8)) == 0 {
+			continue
+		}
+		s, e := offset(i), offset(i+1)
+		if s < 0 || s > e || e > int64(len(values)) {
+			return errors.New("invalid record batch: the offset is out of the values")
+		}
+		if err := d.fn(string(values[s:e])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fbTable is the table in the flatbuffers.
+type fbTable struct {
+	buf []byte
+	pos int
+}
+
+func root(buf []byte) (fbTable, error) {
+	if len(buf) < 4 {
+		return fbTable{}, errors.New("invalid Arrow message")
+	}
+	t := fbTable{buf: buf, pos: int(binary.LittleEndian.Uint32(buf))}
+	if t.pos+4 > len(buf) {
+		return fbTable{}, errors.New("invalid Arrow message")
+	}
+	return t, nil
+}
+
+// field returns the position of the field, or 0 if absent.
+func (t fbTable) field(id int) int {
+	vtable := t.pos - int(int32(binary.LittleEndian.Uint32(t.buf[t.pos:])))
+	if 4+2*id >= int(binary.LittleEndian.Uint16(t.buf[vtable:])) {
+		return 0
+	}
+	off := int(binary.LittleEndian.Uint16(t.buf[vtable+4+2*id:]))
+	if off == 0 {
+		return 0
+	}
+	return t.pos + off
+}
+
+func (t fbTable) uint(id, size int) uint64 {
+	pos := t.field(id)
+	if pos == 0 {
+		return 0
+	}
+	var v uint64
+	for i := 0; i < size; i++ {
+		v |= uint64(t.buf[pos+i]) << (8 * uint(i))
+	}
+	return v
+}
+
+func (t fbTable) int64(pos int) int64 {
+	return int64(binary.LittleEndian.Uint64(t.buf[pos:]))
+}
+
+func (t fbTable) deref(pos int) int {
+	return pos + int(binary.LittleEndian.Uint32(t.buf[pos:]))
+}
+
+func (t fbTable) table(id int) fbTable {
+	return fbTable{buf: t.buf, pos: t.deref(t.field(id))}
+}
+
+// vector returns the position of the elements and the length, or 0s if absent.
+func (t fbTable) vector(id int) (int, int) {
+	pos := t.field(id)
+	if pos == 0 {
+		return 0, 0
+	}
+	pos = t.deref(pos)
+	return pos + 4, int(binary.LittleEndian.Uint32(t.buf[pos:]))
+}
+
+func (t fbTable) tables(id int) []fbTable {
+	pos, n := t.vector(id)
+	res := make([]fbTable, n)
+	for i := range res {
+		res[i] = fbTable{buf: t.buf, pos: t.deref(pos + 4*i)}
+	}
+	return res
+}
+
+func (t fbTable) string(id int) string {
+	pos, n := t.vector(id)
+	return string(t.buf[pos : pos+n])
+}
+
+// protoKey returns the field number, the wire type, and the size of the key, which is <= 0 if invalid.
+func protoKey(b []byte) (int, int, int) {
+	v, n := binary.Uvarint(b)
+	return int(v >> 3), int(v & 7), n
+}
+
+// protoSkip returns the value of the wire type and the rest, with the size which is < 0 if invalid.
+func protoSkip(b []byte, wire int) ([]byte, []byte, int) {
+	switch wire {
+	case 0:
+		_, n := binary.Uvarint(b)
+		if n <= 0 {
+			return nil, nil, -1
+		}
+		return b[:n], b[n:], n
+	case 1, 5:
+		n := 8
+		if wire == 5 {
+			n = 4
+		}
+		if len(b) < n {
+			return nil, nil, -1
+		}
+		return b[:n], b[n:], n
+	case 2:
+		l, n := binary.Uvarint(b)
+		if n <= 0 || uint64(len(b)-n) < l {
+			return nil, nil, -1
+		}
+		e := n + int(l)
+		return b[n:e], b[e:], e
+	}
+	return nil, nil, -1
+}
+
+func appendBytes(b []byte, field int, v []byte) []byte {
+	b = appendVarint(b, uint64(field<<3|2))
+	b = appendVarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+func appendVarint(b []byte, v uint64) []byte {
+	buf := make([]byte, binary.MaxVarintLen64)
+	return append(b, buf[:binary.PutUvarint(buf, v)]...)
+}
@@ -0,0 +1,143 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/arrow"
+)
+
+// flightServer serves the IPC stream of the embeddings by DoGet of the ticket with the bearer token.
+func flightServer(t *testing.T, ticket, token string, embs embedding.Embeddings) *httptest.Server {
+	var stream bytes.Buffer
+	assert.NoError(t, arrow.Write(&stream, embs))
+
+	var frames []byte
+	b := stream.Bytes()
+	for {
+		size := int(binary.LittleEndian.Uint32(b[4:]))
+		if size == 0 {
+			break
+		}
+		header := b[8 : 8+size]
+		msg, err := root(header)
+		assert.NoError(t, err)
+		body := b[8+size : 8+size+int(msg.uint(3, 8))]
+		b = b[8+size+len(body):]
+
+		data := appendBytes(appendBytes(nil, flightDataHeader, header), flightDataBody, body)
+		frame := make([]byte, grpcFrameHeaderSize)
+		binary.BigEndian.PutUint32(frame[1:], uint32(len(data)))
+		frames = append(append(frames, frame...), data...)
+	}
+
+	s := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, 2, r.ProtoMajor)
+		assert.Equal(t, flightServiceDoGet, r.URL.Path)
+		assert.Equal(t, "application/grpc", r.Header.Get("Content-Type"))
+		req, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			w.Header().Set("Grpc-Status", "16")
+			w.Header().Set("Grpc-Message", "unauthenticated")
+			return
+		}
+		if !bytes.Equal(req[grpcFrameHeaderSize:], appendBytes(nil, flightTicket, []byte(ticket))) {
+			w.Header().Set("Grpc-Status", "5")
+			w.Header().Set("Grpc-Message", "unknown ticket")
+			return
+		}
+		w.Write(frames)
+		w.Header().Set("Grpc-Status", "0")
+	}))
+	s.EnableHTTP2 = true
+	s.StartTLS()
+	return s
+}
+
+func TestFlight(t *testing.T) {
+	embs := embedding.Embeddings{
+		{Word: "the quick fox", Dim: 2, Vector: []float64{1, 2}},
+		{Word: "jumps", Dim: 2, Vector: []float64{3, 4}},
+	}
+	s := flightServer(t, "docs", "secret", embs)
+	defer s.Close()
+	host := s.Listener.Addr().String()
+	defer os.Setenv(FlightTokenEnv, os.Getenv(FlightTokenEnv))
+	os.Setenv(FlightTokenEnv, "secret")
+
+	assert.True(t, IsSource("grpc+tls://"+host+"?ticket=docs&column=word"))
+
+	read := func(uri string) ([]string, error) {
+		u, err := url.Parse(uri)
+		assert.NoError(t, err)
+		src, err := openFlight(u, s.Client())
+		if err != nil {
+			return nil, err
+		}
+		defer src.Close()
+		var texts []string
+		err = src.Read(context.Background(), func(text string) error {
+			texts = append(texts, text)
+			return nil
+		})
+		return texts, err
+	}
+
+	texts, err := read("grpc+tls://" + host + "?ticket=docs&column=word")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"the quick fox", "jumps"}, texts)
+
+	for _, uri := range []string{
+		"grpc+tls://" + host + "?column=word",
+		"grpc+tls://" + host + "?ticket=docs",
+	} {
+		_, err = read(uri)
+		assert.Error(t, err, uri)
+	}
+	_, err = read("grpc+tls://" + host + "?ticket=other&column=word")
+	assert.EqualError(t, err, "DoGet failed with status 5: unknown ticket")
+	_, err = read("grpc+tls://" + host + "?ticket=docs&column=vector")
+	assert.Error(t, err)
+	_, err = read("grpc+tls://" + host + "?ticket=docs&column=missing")
+	assert.Error(t, err)
+
+	// the token in the URI is rejected without echoing it.
+	_, err = read("grpc+tls://" + host + "?ticket=docs&column=word&token=leaked")
+	assert.Error(t, err)
+	assert.NotContains(t, err.Error(), "leaked")
+	_, err = Open("grpc+tls://store:port?ticket=docs&column=word&token=leaked")
+	assert.Error(t, err)
+	assert.NotContains(t, err.Error(), "leaked")
+
+	os.Unsetenv(FlightTokenEnv)
+	_, err = read("grpc+tls://" + host + "?ticket=docs&column=word")
+	assert.EqualError(t, err, "DoGet failed with status 16: unauthenticated")
+}
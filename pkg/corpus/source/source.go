@@ -0,0 +1,197 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package source pulls the corpus from the datastores by the URI of the registered schemes,
+// e.g. sqlite3:///path/to/docs.db?query=SELECT+body+FROM+docs, converting the texts of the records into the lines.
+//
+// The database/sql drivers linked into the build (e.g. sqlite3 imported by the user) are available as the schemes,
+// as well as Arrow Flight over TLS by grpc+tls://host:port?ticket=...&column=..., and the other datastores are
+// plugged in by Register.
+package source
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Source reads the texts of the records.
+type Source interface {
+	// Read calls fn for each text in order until the end of the records.
+	Read(ctx context.Context, fn func(text string) error) error
+	Close() error
+}
+
+// Opener opens Source of uri.
+type Opener func(uri *url.URL) (Source, error)
+
+var (
+	mu      sync.RWMutex
+	openers = make(map[string]Opener)
+)
+
+// Register makes the scheme available to Open. The schemes of the database/sql drivers are registered implicitly.
+func Register(scheme string, open Opener) {
+	mu.Lock()
+	defer mu.Unlock()
+	openers[scheme] = open
+}
+
+// Schemes returns the schemes of the registered sources and the database/sql drivers.
+func Schemes() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	set := make(map[string]bool)
+	for scheme := range openers {
+		set[scheme] = true
+	}
+	for _, d := range sql.Drivers() {
+		set[d] = true
+	}
+	schemes := make([]string, 0, len(set))
+	for scheme := range set {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// IsSource returns whether path is the URI of the available schemes.
+func IsSource(path string) bool {
+	for _, scheme := range Schemes() {
+		if strings.HasPrefix(path, scheme+"://") {
+			return true
+		}
+	}
+	return false
+}
+
+// Open opens Source of uri by the scheme.
+func Open(uri string) (Source, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		// url.Error has the whole URI, which may have the credentials.
+		if uerr, ok := err.(*url.Error); ok {
+			err = uerr.Err
+		}
+		return nil, errors.Wrap(err, "failed to parse the URI of the source")
+	}
+	mu.RLock()
+	open, ok := openers[u.Scheme]
+	mu.RUnlock()
+	if ok {
+		return open(u)
+	}
+	for _, d := range sql.Drivers() {
+		if d == u.Scheme {
+			return openSQL(u)
+		}
+	}
+	return nil, errors.Errorf("invalid source: %s not in %s", u.Scheme, strings.Join(Schemes(), "|"))
+}
+
+// NewReader returns the reader of the texts of src as the lines, where the newlines in the texts are replaced
+// with the spaces. src is closed with the reader.
+func NewReader(ctx context.Context, src Source) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		buf := bufio.NewWriter(pw)
+		err := src.Read(ctx, func(text string) error {
+			buf.WriteString(strings.Join(strings.Fields(text), " "))
+			return buf.WriteByte('\n')
+		})
+		if err == nil {
+			err = buf.Flush()
+		}
+		pw.CloseWithError(err)
+	}()
+	return &reader{PipeReader: pr, src: src}
+}
+
+type reader struct {
+	*io.PipeReader
+	src Source
+}
+
+func (r *reader) Close() error {
+	r.PipeReader.Close()
+	return r.src.Close()
+}
+
+// sqlSource reads the first column of the rows of the query.
+type sqlSource struct {
+	db    *sql.DB
+	query string
+}
+
+// openSQL opens the database of driver://dsn?query=..., where the dsn is the host and the path, and the rest of
+// the parameters are passed to the driver, e.g. sqlite3:///path/to/docs.db?query=SELECT+body+FROM+docs.
+func openSQL(u *url.URL) (Source, error) {
+	params := u.Query()
+	query := params.Get("query")
+	if query == "" {
+		return nil, errors.Errorf("query must be set in %s source", u.Scheme)
+	}
+	params.Del("query")
+	dsn := u.Host + u.Path
+	if len(params) > 0 {
+		dsn += "?" + params.Encode()
+	}
+	db, err := sql.Open(u.Scheme, dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlSource{db: db, query: query}, nil
+}
+
+func (s *sqlSource) Read(ctx context.Context, fn func(string) error) error {
+	rows, err := s.db.QueryContext(ctx, s.query)
+	if err != nil {
+		return errors.Wrap(err, "failed to query")
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	var text sql.NullString
+	dest := make([]interface{}, len(cols))
+	dest[0] = &text
+	for i := 1; i < len(dest); i++ {
+		dest[i] = new(sql.RawBytes)
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return errors.Wrap(err, "failed to scan the first column")
+		}
+		if !text.Valid {
+			continue
+		}
+		if err := fn(text.String); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (s *sqlSource) Close() error {
+	return s.db.Close()
+}
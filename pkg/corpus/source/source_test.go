@@ -0,0 +1,71 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"context"
+	"io/ioutil"
+	"net/url"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type sliceSource struct {
+	texts  []string
+	closed bool
+}
+
+func (s *sliceSource) Read(ctx context.Context, fn func(string) error) error {
+	for _, text := range s.texts {
+		if err := fn(text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sliceSource) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestOpen(t *testing.T) {
+	src := &sliceSource{texts: []string{"a b", "c\nd  e"}}
+	Register("test", func(u *url.URL) (Source, error) {
+		if u.Query().Get("column") != "text" {
+			return nil, errors.New("column must be text")
+		}
+		return src, nil
+	})
+	assert.Contains(t, Schemes(), "test")
+	assert.True(t, IsSource("test://store/table?column=text"))
+	assert.False(t, IsSource("example/input.txt"))
+
+	_, err := Open("test://store/table")
+	assert.Error(t, err)
+	_, err = Open("unknown://store/table")
+	assert.Error(t, err)
+
+	opened, err := Open("test://store/table?column=text")
+	assert.NoError(t, err)
+	r := NewReader(context.Background(), opened)
+	b, err := ioutil.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, "a b\nc d e\n", string(b))
+	assert.NoError(t, r.Close())
+	assert.True(t, src.closed)
+}
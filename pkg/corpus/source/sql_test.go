@@ -0,0 +1,86 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// docsDriver returns the rows of (body, id) for any query, recording the dsn and the query.
+type docsDriver struct {
+	dsn, query string
+}
+
+func (d *docsDriver) Open(dsn string) (driver.Conn, error) {
+	d.dsn = dsn
+	return d, nil
+}
+
+func (d *docsDriver) Prepare(query string) (driver.Stmt, error) {
+	d.query = query
+	return d, nil
+}
+
+func (d *docsDriver) Close() error              { return nil }
+func (d *docsDriver) Begin() (driver.Tx, error) { return nil, driver.ErrSkip }
+func (d *docsDriver) NumInput() int             { return 0 }
+
+func (d *docsDriver) Exec([]driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+
+func (d *docsDriver) Query([]driver.Value) (driver.Rows, error) {
+	return &docsRows{values: [][]driver.Value{{"a b", int64(1)}, {nil, int64(2)}, {"c", int64(3)}}}, nil
+}
+
+type docsRows struct {
+	values [][]driver.Value
+}
+
+func (r *docsRows) Columns() []string { return []string{"body", "id"} }
+func (r *docsRows) Close() error      { return nil }
+
+func (r *docsRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func TestOpenSQL(t *testing.T) {
+	d := &docsDriver{}
+	sql.Register("docs", d)
+
+	assert.True(t, IsSource("docs:///path/to/docs.db"))
+	_, err := Open("docs:///path/to/docs.db")
+	assert.Error(t, err)
+
+	src, err := Open("docs:///path/to/docs.db?query=SELECT+body,+id+FROM+docs&mode=ro")
+	assert.NoError(t, err)
+	r := NewReader(context.Background(), src)
+	defer r.Close()
+	b, err := ioutil.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, "a b\nc\n", string(b))
+	assert.Equal(t, "/path/to/docs.db?mode=ro", d.dsn)
+	assert.Equal(t, "SELECT body, id FROM docs", d.query)
+}
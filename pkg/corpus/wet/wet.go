@@ -0,0 +1,143 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wet
+
+// inspired by
+// - https://iipc.github.io/warc-specifications/specifications/warc-format/warc-1.1/
+// - https://commoncrawl.org/get-started
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Record is the document of the conversion record in WET.
+type Record struct {
+	URI string
+	// Language is WARC-Identified-Content-Language, e.g. eng,deu, which may be empty.
+	Language string
+	Content  []byte
+}
+
+// ReadRecords calls fn for each conversion record of WARC/WET, skipping the other types, e.g. warcinfo.
+// The input may be gzip compressed, including the concatenated shards.
+func ReadRecords(r io.Reader, fn func(Record) error) error {
+	br, err := decompress(r)
+	if err != nil {
+		return err
+	}
+	for {
+		header, err := readHeader(br)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		size, err := strconv.ParseInt(header["content-length"], 10, 64)
+		if err != nil {
+			return errors.Wrapf(err, "invalid Content-Length of %s", header["warc-record-id"])
+		}
+		if header["warc-type"] != "conversion" {
+			if _, err := io.CopyN(ioutil.Discard, br, size); err != nil {
+				return errors.Wrap(err, "failed to read record")
+			}
+			continue
+		}
+		content := make([]byte, size)
+		if _, err := io.ReadFull(br, content); err != nil {
+			return errors.Wrap(err, "failed to read record")
+		}
+		if err := fn(Record{
+			URI:      header["warc-target-uri"],
+			Language: header["warc-identified-content-language"],
+			Content:  content,
+		}); err != nil {
+			return err
+		}
+	}
+}
+
+func decompress(r io.Reader) (*bufio.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(2)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		return bufio.NewReader(zr), nil
+	}
+	return br, nil
+}
+
+// readHeader reads the version line and the fields in lowercase until the empty line,
+// skipping the empty lines between the records.
+func readHeader(br *bufio.Reader) (map[string]string, error) {
+	var version string
+	for version == "" {
+		line, err := br.ReadString('\n')
+		if err == io.EOF && strings.TrimSpace(line) == "" {
+			return nil, io.EOF
+		} else if err != nil && err != io.EOF {
+			return nil, err
+		}
+		version = strings.TrimSpace(line)
+	}
+	if !strings.HasPrefix(version, "WARC/") {
+		return nil, errors.Errorf("invalid record: %q is not WARC version", version)
+	}
+	header := make(map[string]string)
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read header")
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			return header, nil
+		}
+		if i := strings.Index(line, ":"); i > 0 {
+			header[strings.ToLower(line[:i])] = strings.TrimSpace(line[i+1:])
+		}
+	}
+}
+
+// WriteText writes the lines of the documents as the corpus, where the documents are separated by the empty line.
+func WriteText(w io.Writer, r io.Reader) error {
+	buf := bufio.NewWriter(w)
+	if err := ReadRecords(r, func(rec Record) error {
+		for _, line := range strings.Split(string(rec.Content), "\n") {
+			if line = strings.TrimSpace(line); line == "" {
+				continue
+			}
+			buf.WriteString(line)
+			buf.WriteByte('\n')
+		}
+		return buf.WriteByte('\n')
+	}); err != nil {
+		return err
+	}
+	return buf.Flush()
+}
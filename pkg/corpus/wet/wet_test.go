@@ -0,0 +1,62 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func record(typ, uri, lang, content string) string {
+	return fmt.Sprintf("WARC/1.0\r\nWARC-Type: %s\r\nWARC-Target-URI: %s\r\nWARC-Identified-Content-Language: %s\r\n"+
+		"Content-Type: text/plain\r\nContent-Length: %d\r\n\r\n%s\r\n\r\n", typ, uri, lang, len(content), content)
+}
+
+var shard = record("warcinfo", "", "", "software: test\r\n") +
+	record("conversion", "https://example.com/a", "eng", "Hello world\n\nsecond line") +
+	record("conversion", "https://example.com/b", "deu", "Hallo Welt")
+
+func TestReadRecords(t *testing.T) {
+	var recs []Record
+	assert.NoError(t, ReadRecords(strings.NewReader(shard), func(rec Record) error {
+		recs = append(recs, rec)
+		return nil
+	}))
+	assert.Equal(t, []Record{
+		{URI: "https://example.com/a", Language: "eng", Content: []byte("Hello world\n\nsecond line")},
+		{URI: "https://example.com/b", Language: "deu", Content: []byte("Hallo Welt")},
+	}, recs)
+
+	assert.Error(t, ReadRecords(strings.NewReader("HTTP/1.1 200 OK\r\n\r\n"), func(Record) error { return nil }))
+}
+
+func TestWriteText(t *testing.T) {
+	// the concatenated gzip members as the shards.
+	gz := &bytes.Buffer{}
+	for i := 0; i < 2; i++ {
+		zw := gzip.NewWriter(gz)
+		zw.Write([]byte(shard))
+		assert.NoError(t, zw.Close())
+	}
+	buf := &bytes.Buffer{}
+	assert.NoError(t, WriteText(buf, gz))
+	doc := "Hello world\nsecond line\n\nHallo Welt\n\n"
+	assert.Equal(t, doc+doc, buf.String())
+}
@@ -0,0 +1,218 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wiki
+
+// inspired by
+// - https://www.mediawiki.org/wiki/Help:Export
+// - https://github.com/attardi/wikiextractor
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"encoding/xml"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Page is the article in the dump.
+type Page struct {
+	Title    string    `xml:"title"`
+	NS       int       `xml:"ns"`
+	Redirect *struct{} `xml:"redirect"`
+	Text     string    `xml:"revision>text"`
+}
+
+// ReadPages calls fn for each article of the MediaWiki XML dump, i.e. the pages in the main namespace
+// except the redirects. The dump may be compressed by bzip2 or gzip.
+func ReadPages(r io.Reader, fn func(Page) error) error {
+	r, err := decompress(r)
+	if err != nil {
+		return err
+	}
+	d := xml.NewDecoder(r)
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return errors.Wrap(err, "failed to decode dump")
+		}
+		se, ok := tok.(xml.StartElement)
+		if !ok || se.Name.Local != "page" {
+			continue
+		}
+		var p Page
+		if err := d.DecodeElement(&p, &se); err != nil {
+			return errors.Wrap(err, "failed to decode page")
+		}
+		if p.NS != 0 || p.Redirect != nil {
+			continue
+		}
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+}
+
+func decompress(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(3)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	switch {
+	case bytes.HasPrefix(magic, []byte("BZh")):
+		return bzip2.NewReader(br), nil
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		return gzip.NewReader(br)
+	default:
+		return br, nil
+	}
+}
+
+// WriteText writes the plain text of the articles as the corpus, one paragraph per line.
+func WriteText(w io.Writer, r io.Reader) error {
+	buf := bufio.NewWriter(w)
+	if err := ReadPages(r, func(p Page) error {
+		for _, line := range strings.Split(Clean(p.Text), "\n") {
+			if line = strings.TrimSpace(line); line == "" {
+				continue
+			}
+			buf.WriteString(line)
+			if err := buf.WriteByte('\n'); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	return buf.Flush()
+}
+
+var (
+	commentRe  = regexp.MustCompile(`(?s)<!--.*?-->`)
+	refRe      = regexp.MustCompile(`(?is)<ref[^>]*/>|<ref[^>]*>.*?</ref>`)
+	skipTagRe  = regexp.MustCompile(`(?is)<(math|gallery|timeline|syntaxhighlight|source|pre|score|graph)[^>]*>.*?</(math|gallery|timeline|syntaxhighlight|source|pre|score|graph)>`)
+	tagRe      = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+	extLinkRe  = regexp.MustCompile(`\[(https?:|ftp:)?//[^\s\]]*\s*([^\]]*)\]`)
+	urlRe      = regexp.MustCompile(`https?://\S+`)
+	quoteRe    = regexp.MustCompile(`'{2,}`)
+	headingRe  = regexp.MustCompile(`(?m)^=+\s*(.*?)\s*=+\s*$`)
+	listRe     = regexp.MustCompile(`(?m)^[*#:;]+\s*`)
+	magicRe    = regexp.MustCompile(`__[A-Z]+__`)
+	ruleRe     = regexp.MustCompile(`(?m)^-{4,}\s*$`)
+	linkPrefix = []string{"file:", "image:", "category:", "media:"}
+)
+
+// Clean converts the wikitext into the plain text, removing the templates, the tables, the references,
+// the files and the categories, and keeping the text of the links.
+func Clean(text string) string {
+	text = commentRe.ReplaceAllString(text, "")
+	text = refRe.ReplaceAllString(text, "")
+	text = skipTagRe.ReplaceAllString(text, "")
+	text = removeNested(text, "{{", "}}")
+	text = removeNested(text, "{|", "|}")
+	text = replaceLinks(text)
+	text = extLinkRe.ReplaceAllString(text, "$2")
+	text = urlRe.ReplaceAllString(text, "")
+	text = tagRe.ReplaceAllString(text, "")
+	text = quoteRe.ReplaceAllString(text, "")
+	text = headingRe.ReplaceAllString(text, "$1")
+	text = listRe.ReplaceAllString(text, "")
+	text = magicRe.ReplaceAllString(text, "")
+	text = ruleRe.ReplaceAllString(text, "")
+	return html.UnescapeString(text)
+}
+
+// removeNested removes the spans between open and close, which may be nested.
+func removeNested(text, open, close string) string {
+	var (
+		b     strings.Builder
+		depth int
+	)
+	for i := 0; i < len(text); {
+		switch {
+		case strings.HasPrefix(text[i:], open):
+			depth++
+			i += len(open)
+		case depth > 0 && strings.HasPrefix(text[i:], close):
+			depth--
+			i += len(close)
+		default:
+			if depth == 0 {
+				b.WriteByte(text[i])
+			}
+			i++
+		}
+	}
+	return b.String()
+}
+
+// replaceLinks replaces [[target|label]] with label, or target without label,
+// and removes the links to the files and the categories with their nested links.
+func replaceLinks(text string) string {
+	var b strings.Builder
+	for {
+		start := strings.Index(text, "[[")
+		if start < 0 {
+			b.WriteString(text)
+			return b.String()
+		}
+		b.WriteString(text[:start])
+		end, depth := -1, 0
+		for i := start; i+1 < len(text); i++ {
+			if strings.HasPrefix(text[i:], "[[") {
+				depth++
+				i++
+			} else if strings.HasPrefix(text[i:], "]]") {
+				depth--
+				i++
+				if depth == 0 {
+					end = i + 1
+					break
+				}
+			}
+		}
+		if end < 0 {
+			b.WriteString(text[start:])
+			return b.String()
+		}
+		b.WriteString(linkText(text[start+2 : end-2]))
+		text = text[end:]
+	}
+}
+
+func linkText(link string) string {
+	lower := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(link, ":")))
+	for _, prefix := range linkPrefix {
+		if strings.HasPrefix(lower, prefix) {
+			return ""
+		}
+	}
+	if i := strings.LastIndex(link, "|"); i >= 0 {
+		return replaceLinks(link[i+1:])
+	}
+	if i := strings.Index(link, "#"); i > 0 {
+		link = link[:i]
+	}
+	return link
+}
@@ -0,0 +1,100 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wiki
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const dump = `<mediawiki xmlns="http://www.mediawiki.org/xml/export-0.10/">
+  <siteinfo><sitename>Wikipedia</sitename></siteinfo>
+  <page>
+    <title>Go</title>
+    <ns>0</ns>
+    <revision>
+      <text xml:space="preserve">{{Infobox language
+| name = Go
+| paradigm = {{hlist|concurrent|imperative}}
+}}
+'''Go''' is a [[programming language]] designed at [[Google]]&lt;ref&gt;{{cite web|url=https://go.dev}}&lt;/ref&gt;.
+
+== History ==
+* It was announced in [[2009|November 2009]].&lt;!-- comment --&gt;
+[[File:Go gopher.png|thumb|The [[gopher]] mascot]]
+{| class="wikitable"
+| a || b
+|}
+See [https://go.dev the website] &amp;amp; more.
+[[Category:Programming languages]]</text>
+    </revision>
+  </page>
+  <page>
+    <title>Golang</title>
+    <ns>0</ns>
+    <redirect title="Go" />
+    <revision><text>#REDIRECT [[Go]]</text></revision>
+  </page>
+  <page>
+    <title>Talk:Go</title>
+    <ns>1</ns>
+    <revision><text>talk</text></revision>
+  </page>
+</mediawiki>
+`
+
+const expected = "Go is a programming language designed at Google.\n" +
+	"History\n" +
+	"It was announced in November 2009.\n" +
+	"See the website & more.\n"
+
+func TestWriteText(t *testing.T) {
+	buf := &bytes.Buffer{}
+	assert.NoError(t, WriteText(buf, strings.NewReader(dump)))
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestWriteTextCompressed(t *testing.T) {
+	gz := &bytes.Buffer{}
+	zw := gzip.NewWriter(gz)
+	zw.Write([]byte(dump))
+	assert.NoError(t, zw.Close())
+	buf := &bytes.Buffer{}
+	assert.NoError(t, WriteText(buf, gz))
+	assert.Equal(t, expected, buf.String())
+
+	// bzip2 of <page> with the text "Hello [[world]]".
+	bz, err := base64.StdEncoding.DecodeString("QlpoOTFBWSZTWWcDlvEAAAcfgEAAwAUgQAAKJq/dwCAAcFAAAGTIEqngqZoahmnpNHLmUEIUzUymtr8Mjuw8G32n+TU6oWVEktcqSXNjByYPhU0UrvjvAitmas6JFyDwiERjQ/Eqei7kinChIM4HLeI=")
+	assert.NoError(t, err)
+	buf.Reset()
+	assert.NoError(t, WriteText(buf, bytes.NewReader(bz)))
+	assert.Equal(t, "Hello world\n", buf.String())
+}
+
+func TestReadPages(t *testing.T) {
+	var titles []string
+	assert.NoError(t, ReadPages(strings.NewReader(dump), func(p Page) error {
+		titles = append(titles, p.Title)
+		return nil
+	}))
+	assert.Equal(t, []string{"Go"}, titles)
+
+	assert.Error(t, ReadPages(strings.NewReader("<mediawiki><page>"), func(Page) error { return nil }))
+}
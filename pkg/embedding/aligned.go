@@ -0,0 +1,127 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embedding
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Aligned is the view of the several spaces of embeddings in the shared index space, e.g. GloVe and word2vec
+// trained on the same corpus for the ensembling. Rows[s][i] is the vector of Words[i] in the space s, which is nil
+// if the space lacks the word, and Missing[s] are such words. The spaces may have the different dimensions.
+type Aligned struct {
+	Words   []string
+	Rows    [][][]float64
+	Missing [][]string
+	Dims    []int
+
+	index map[string]int
+}
+
+// Align maps the spaces to the union of their words, in the order of the first appearance from the first space.
+// The duplicated words in a space keep the first vector like Embeddings.Find.
+func Align(spaces ...Embeddings) (*Aligned, error) {
+	if len(spaces) == 0 {
+		return nil, errors.New("no embeddings to align")
+	}
+	a := &Aligned{
+		Rows:    make([][][]float64, len(spaces)),
+		Missing: make([][]string, len(spaces)),
+		Dims:    make([]int, len(spaces)),
+		index:   make(map[string]int),
+	}
+	for s, embs := range spaces {
+		if embs.Empty() {
+			return nil, errors.Errorf("embeddings of space %d are empty", s)
+		}
+		if err := embs.Validate(); err != nil {
+			return nil, errors.Wrapf(err, "space %d", s)
+		}
+		a.Dims[s] = embs[0].Dim
+		for _, emb := range embs {
+			if _, ok := a.index[emb.Word]; !ok {
+				a.index[emb.Word] = len(a.Words)
+				a.Words = append(a.Words, emb.Word)
+			}
+		}
+	}
+	for s, embs := range spaces {
+		a.Rows[s] = make([][]float64, len(a.Words))
+		for _, emb := range embs {
+			if i := a.index[emb.Word]; a.Rows[s][i] == nil {
+				a.Rows[s][i] = emb.Vector
+			}
+		}
+		for i, row := range a.Rows[s] {
+			if row == nil {
+				a.Missing[s] = append(a.Missing[s], a.Words[i])
+			}
+		}
+	}
+	return a, nil
+}
+
+// AlignFiles loads the embeddings of the paths and aligns them.
+func AlignFiles(paths ...string) (*Aligned, error) {
+	spaces := make([]Embeddings, len(paths))
+	for s, path := range paths {
+		embs, err := LoadFile(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load %s", path)
+		}
+		spaces[s] = embs
+	}
+	return Align(spaces...)
+}
+
+// Index returns the index of the word in the shared space.
+func (a *Aligned) Index(word string) (int, bool) {
+	i, ok := a.index[word]
+	return i, ok
+}
+
+// Vector returns the vector of the word in the space s.
+func (a *Aligned) Vector(s int, word string) ([]float64, bool) {
+	i, ok := a.index[word]
+	if !ok || a.Rows[s][i] == nil {
+		return nil, false
+	}
+	return a.Rows[s][i], true
+}
+
+// Shared returns the view of only the words in all spaces, in the same order, whose Missing are empty.
+func (a *Aligned) Shared() *Aligned {
+	shared := &Aligned{
+		Rows:    make([][][]float64, len(a.Rows)),
+		Missing: make([][]string, len(a.Rows)),
+		Dims:    a.Dims,
+		index:   make(map[string]int),
+	}
+	for i, word := range a.Words {
+		ok := true
+		for _, rows := range a.Rows {
+			ok = ok && rows[i] != nil
+		}
+		if !ok {
+			continue
+		}
+		shared.index[word] = len(shared.Words)
+		shared.Words = append(shared.Words, word)
+		for s, rows := range a.Rows {
+			shared.Rows[s] = append(shared.Rows[s], rows[i])
+		}
+	}
+	return shared
+}
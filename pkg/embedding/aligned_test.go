@@ -0,0 +1,53 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embedding
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlign(t *testing.T) {
+	glove := Embeddings{
+		{Word: "apple", Dim: 2, Vector: []float64{1, 2}},
+		{Word: "banana", Dim: 2, Vector: []float64{3, 4}},
+	}
+	word2vec := Embeddings{
+		{Word: "banana", Dim: 3, Vector: []float64{5, 6, 7}},
+		{Word: "cherry", Dim: 3, Vector: []float64{8, 9, 10}},
+		{Word: "apple", Dim: 3, Vector: []float64{11, 12, 13}},
+	}
+	a, err := Align(glove, word2vec)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"apple", "banana", "cherry"}, a.Words)
+	assert.Equal(t, []int{2, 3}, a.Dims)
+	assert.Equal(t, [][]string{{"cherry"}, nil}, a.Missing)
+	vec, ok := a.Vector(1, "apple")
+	assert.True(t, ok)
+	assert.Equal(t, []float64{11, 12, 13}, vec)
+	_, ok = a.Vector(0, "cherry")
+	assert.False(t, ok)
+
+	shared := a.Shared()
+	assert.Equal(t, []string{"apple", "banana"}, shared.Words)
+	assert.Equal(t, [][]float64{{5, 6, 7}}, shared.Rows[1][1:])
+	i, ok := shared.Index("banana")
+	assert.True(t, ok)
+	assert.Equal(t, 1, i)
+
+	_, err = Align(glove, Embeddings{})
+	assert.Error(t, err)
+}
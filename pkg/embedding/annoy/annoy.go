@@ -0,0 +1,306 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package annoy reads and writes the index of Annoy (https://github.com/spotify/annoy)
+// with the angular metric, which is loadable by AnnoyIndex(dim, "angular").load(path).
+// Annoy identifies the items by the integers, so the words are kept in the adjacent vocabulary.
+package annoy
+
+import (
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"runtime"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/embutil"
+	"github.com/ynqa/wego/pkg/model"
+	"github.com/ynqa/wego/pkg/util/parallel"
+	"github.com/ynqa/wego/pkg/vector"
+)
+
+var (
+	defaultGoroutines = runtime.NumCPU()
+	defaultSeed       = int64(0)
+	defaultTrees      = 10
+	defaultVerbose    = false
+)
+
+// Options is for the build of the index.
+type Options struct {
+	Goroutines int
+	Seed       int64
+	Trees      int
+	Verbose    bool
+
+	// Hooks is notified of the build as training, where a tree is the unit of the progress.
+	Hooks model.Hooks `json:"-"`
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Goroutines: defaultGoroutines,
+		Seed:       defaultSeed,
+		Trees:      defaultTrees,
+		Verbose:    defaultVerbose,
+	}
+}
+
+// node is Angular::Node of annoylib.h: n_descendants, children[2] and v[f] in int32 and float32.
+// The leaf holds up to k item ids in children, overflowing into v.
+type node struct {
+	descendants int32
+	children    []int32
+	v           []float64
+}
+
+// splitTrials is the number of trials to find the hyperplane which separates the items
+// before they are split at random, as Annoy does.
+const splitTrials = 3
+
+// builder builds a tree, whose nodes are numbered from the offset after the items.
+type builder struct {
+	vecs   [][]float64
+	k      int
+	offset int
+	nodes  []node
+	rand   *rand.Rand
+}
+
+// Write builds the random projection trees over embs and writes the index, whose item i is embs[i].
+// More trees give the higher precision of the search in the larger index.
+func Write(w io.Writer, embs embedding.Embeddings, opts Options) (err error) {
+	opts.Hooks.BeforeTrain(opts)
+	defer func() {
+		opts.Hooks.AfterTrain(err)
+	}()
+
+	if embs.Empty() {
+		return errors.New("embeddings are empty")
+	}
+	if err := embs.Validate(); err != nil {
+		return err
+	}
+	if opts.Trees <= 0 || opts.Goroutines <= 0 {
+		return errors.Errorf("trees and goroutines must be positive, got %d and %d", opts.Trees, opts.Goroutines)
+	}
+	dim := embs[0].Dim
+	vecs := make([][]float64, len(embs))
+	nodes := make([]node, len(embs))
+	indices := make([]int32, len(embs))
+	for i, emb := range embs {
+		nodes[i] = node{descendants: 1, v: emb.Vector}
+		vecs[i] = vector.Unit(emb.Vector)
+		indices[i] = int32(i)
+	}
+
+	// the trees are built independently with the seeds of their own, and concatenated.
+	builders := make([]*builder, opts.Trees)
+	roots := make([]int32, opts.Trees)
+	parallel.Runner{
+		Goroutines: opts.Goroutines,
+		LogBatch:   1,
+		Hooks:      opts.Hooks,
+		Verbose:    opts.Verbose,
+		Unit:       "trees",
+	}.Each(1, opts.Trees, func(t int) {
+		builders[t] = &builder{
+			vecs:   vecs,
+			k:      dim + 2,
+			offset: len(embs),
+			rand:   rand.New(rand.NewSource(opts.Seed + int64(t))),
+		}
+		roots[t] = builders[t].build(indices, true)
+	})
+	for t, b := range builders {
+		shift := int32(len(nodes) - len(embs))
+		for _, n := range b.nodes {
+			if n.v != nil {
+				for c, child := range n.children {
+					if int(child) >= len(embs) {
+						n.children[c] = child + shift
+					}
+				}
+			}
+			nodes = append(nodes, n)
+		}
+		roots[t] += shift
+	}
+	// Annoy finds the roots by the copies at the end of the index.
+	for _, root := range roots {
+		nodes = append(nodes, nodes[root])
+	}
+
+	size := 12 + 4*dim
+	buf := make([]byte, size)
+	for _, n := range nodes {
+		for i := range buf {
+			buf[i] = 0
+		}
+		binary.LittleEndian.PutUint32(buf, uint32(n.descendants))
+		for i, c := range n.children {
+			binary.LittleEndian.PutUint32(buf[4+4*i:], uint32(c))
+		}
+		if n.v != nil {
+			for i, v := range n.v {
+				binary.LittleEndian.PutUint32(buf[12+4*i:], math.Float32bits(float32(v)))
+			}
+		}
+		if _, err := w.Write(buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// build is _make_tree of annoylib.h, and returns the node of the tree over indices.
+func (b *builder) build(indices []int32, root bool) int32 {
+	items := len(b.vecs)
+	if len(indices) == 1 && !root {
+		return indices[0]
+	}
+	descendants := int32(len(indices))
+	if root {
+		descendants = int32(items)
+	}
+	if len(indices) <= b.k && (!root || items <= b.k || len(indices) == 1) {
+		b.nodes = append(b.nodes, node{
+			descendants: descendants,
+			children:    append([]int32(nil), indices...),
+		})
+		return int32(b.offset + len(b.nodes) - 1)
+	}
+
+	var normal []float64
+	var sides [2][]int32
+	for t := 0; t < splitTrials; t++ {
+		normal = b.split(indices)
+		sides = [2][]int32{}
+		for _, i := range indices {
+			s := b.side(normal, b.vecs[i])
+			sides[s] = append(sides[s], i)
+		}
+		if len(sides[0]) > 0 && len(sides[1]) > 0 {
+			break
+		}
+	}
+	if len(sides[0]) == 0 || len(sides[1]) == 0 {
+		normal = make([]float64, len(normal))
+		sides = [2][]int32{}
+		for _, i := range indices {
+			s := b.rand.Intn(2)
+			sides[s] = append(sides[s], i)
+		}
+	}
+
+	children := make([]int32, 2)
+	for s := range sides {
+		children[s] = b.build(sides[s], false)
+	}
+	b.nodes = append(b.nodes, node{
+		descendants: descendants,
+		children:    children,
+		v:           normal,
+	})
+	return int32(b.offset + len(b.nodes) - 1)
+}
+
+// split returns the normal of the hyperplane between two random items.
+func (b *builder) split(indices []int32) []float64 {
+	i := b.rand.Intn(len(indices))
+	j := b.rand.Intn(len(indices) - 1)
+	if j >= i {
+		j++
+	}
+	p, q := b.vecs[indices[i]], b.vecs[indices[j]]
+	normal := make([]float64, len(p))
+	for d := range normal {
+		normal[d] = p[d] - q[d]
+	}
+	return vector.Unit(normal)
+}
+
+// side is 1 for the items in the direction of normal, which Annoy searches by children[1].
+func (b *builder) side(normal, vec []float64) int {
+	var dot float64
+	for d, v := range normal {
+		dot += v * vec[d]
+	}
+	if dot == 0 {
+		return b.rand.Intn(2)
+	}
+	if dot > 0 {
+		return 1
+	}
+	return 0
+}
+
+// Read reads the vectors of the items in the index, whose item i is words[i].
+// The dimension is inferred by the layout of the nodes, where the items are
+// followed by the trees and the copies of the roots of len(words) descendants.
+func Read(r io.Reader, words []string) (embedding.Embeddings, error) {
+	if len(words) == 0 {
+		return nil, errors.New("vocabulary is empty")
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read index")
+	}
+	dim := inferDim(data, len(words))
+	if dim == 0 {
+		return nil, errors.Errorf("index of %d bytes doesn't have %d items", len(data), len(words))
+	}
+	size := 12 + 4*dim
+	embs := make(embedding.Embeddings, len(words))
+	for i, word := range words {
+		vec := make([]float64, dim)
+		for d := range vec {
+			vec[d] = float64(math.Float32frombits(binary.LittleEndian.Uint32(data[i*size+12+4*d:])))
+		}
+		embs[i] = embedding.Embedding{
+			Word:   word,
+			Dim:    dim,
+			Vector: vec,
+			Norm:   embutil.Norm(vec),
+		}
+	}
+	return embs, nil
+}
+
+func inferDim(data []byte, items int) int {
+	descendants := func(i, size int) int {
+		return int(int32(binary.LittleEndian.Uint32(data[i*size:])))
+	}
+	for dim := 1; 12+4*dim <= len(data); dim++ {
+		size := 12 + 4*dim
+		if len(data)%size != 0 || len(data)/size <= items {
+			continue
+		}
+		if descendants(len(data)/size-1, size) != items {
+			continue
+		}
+		ok := true
+		for i := 0; i < items && ok; i++ {
+			ok = descendants(i, size) == 1
+		}
+		if ok {
+			return dim
+		}
+	}
+	return 0
+}
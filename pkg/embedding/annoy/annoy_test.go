@@ -0,0 +1,139 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package annoy
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/embutil"
+	"github.com/ynqa/wego/pkg/model"
+)
+
+func randomEmbeddings(n, dim int) embedding.Embeddings {
+	rnd := rand.New(rand.NewSource(0))
+	embs := make(embedding.Embeddings, n)
+	for i := range embs {
+		vec := make([]float64, dim)
+		for d := range vec {
+			vec[d] = float64(rnd.Intn(200)-100) / 8
+		}
+		embs[i] = embedding.Embedding{
+			Word:   fmt.Sprintf("w%d", i),
+			Dim:    dim,
+			Vector: vec,
+			Norm:   embutil.Norm(vec),
+		}
+	}
+	return embs
+}
+
+// items collects the items under the node as _get_all_nns of annoylib.h traverses.
+func items(data []byte, size, n, i int) []int {
+	desc := func(i int) int {
+		return int(int32(binary.LittleEndian.Uint32(data[i*size:])))
+	}
+	child := func(i, c int) int {
+		return int(int32(binary.LittleEndian.Uint32(data[i*size+4+4*c:])))
+	}
+	if desc(i) == 1 && i < n {
+		return []int{i}
+	}
+	k := size/4 - 1
+	if desc(i) <= k && !(desc(i) == n && n > k) {
+		var res []int
+		for c := 0; c < desc(i); c++ {
+			res = append(res, child(i, c))
+		}
+		return res
+	}
+	return append(items(data, size, n, child(i, 0)), items(data, size, n, child(i, 1))...)
+}
+
+func TestWriteRead(t *testing.T) {
+	for _, n := range []int{1, 3, 200} {
+		embs := randomEmbeddings(n, 4)
+		opts := DefaultOptions()
+		opts.Trees = 3
+		var buf bytes.Buffer
+		assert.NoError(t, Write(&buf, embs, opts))
+		data := buf.Bytes()
+
+		// the index doesn't depend on the number of goroutines.
+		opts.Goroutines = 1
+		var single bytes.Buffer
+		assert.NoError(t, Write(&single, embs, opts))
+		assert.Equal(t, data, single.Bytes())
+
+		// the roots are found from the end as AnnoyIndex.load.
+		size := 12 + 4*4
+		nodes := len(data) / size
+		assert.Equal(t, 0, len(data)%size)
+		for r := nodes - 3; r < nodes; r++ {
+			got := items(data, size, n, r)
+			sort.Ints(got)
+			want := make([]int, n)
+			for i := range want {
+				want[i] = i
+			}
+			assert.Equal(t, want, got)
+		}
+
+		words := make([]string, n)
+		for i, emb := range embs {
+			words[i] = emb.Word
+		}
+		res, err := Read(bytes.NewReader(data), words)
+		assert.NoError(t, err)
+		assert.Equal(t, embs, res)
+	}
+
+	_, err := Read(bytes.NewReader(make([]byte, 64)), []string{"a", "b"})
+	assert.Error(t, err)
+	assert.Error(t, Write(&bytes.Buffer{}, randomEmbeddings(2, 2), Options{Goroutines: 1}))
+}
+
+type counter struct {
+	model.BaseHook
+	progress []model.Progress
+	err      error
+}
+
+func (c *counter) AfterIter(p model.Progress) {
+	c.progress = append(c.progress, p)
+}
+
+func (c *counter) AfterTrain(err error) {
+	c.err = err
+}
+
+func TestWriteHooks(t *testing.T) {
+	c := &counter{}
+	opts := DefaultOptions()
+	opts.Trees = 4
+	opts.Hooks = model.Hooks{c}
+	assert.NoError(t, Write(&bytes.Buffer{}, randomEmbeddings(50, 3), opts))
+	assert.Len(t, c.progress, 1)
+	assert.Equal(t, 4, c.progress[0].Trained)
+	assert.Equal(t, 4, c.progress[0].Total)
+	assert.NoError(t, c.err)
+}
@@ -0,0 +1,170 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package arrow writes the embeddings as the Arrow IPC stream of (word string, vector list<float>) rows
+// (https://arrow.apache.org/docs/format/Columnar.html#ipc-streaming-format).
+package arrow
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/embedding"
+)
+
+const (
+	// Word is the name of the column of the words, string.
+	Word = "word"
+	// Vector is the name of the column of the vectors, list<float>.
+	Vector = "vector"
+
+	// batchSize is the number of rows in a record batch, which is held in memory while writing.
+	batchSize = 1 << 14
+
+	continuation = 0xffffffff
+)
+
+// the enums of Schema.fbs and Message.fbs.
+const (
+	metadataV5 = 4
+
+	headerSchema      = 1
+	headerRecordBatch = 3
+
+	typeFloatingPoint = 3
+	typeUtf8          = 5
+	typeList          = 12
+
+	precisionSingle = 1
+)
+
+func field(name string, typ uint64, t table, children ...object) table {
+	return table{
+		ref(str(name)),
+		scalar(1, 0),
+		scalar(1, typ),
+		ref(t),
+		{},
+		ref(vector(children)),
+	}
+}
+
+func message(header uint64, t table, bodyLength int) table {
+	return table{
+		scalar(2, metadataV5),
+		scalar(1, header),
+		ref(t),
+		scalar(8, uint64(bodyLength)),
+	}
+}
+
+// Write writes the embeddings into w as the Arrow IPC stream in the order of embs.
+func Write(w io.Writer, embs embedding.Embeddings) error {
+	if embs.Empty() {
+		return errors.New("embeddings are empty")
+	}
+	if err := embs.Validate(); err != nil {
+		return err
+	}
+
+	buf := bufio.NewWriter(w)
+	schema := table{
+		scalar(2, 0),
+		ref(vector{
+			field(Word, typeUtf8, table{}),
+			field(Vector, typeList, table{},
+				field("item", typeFloatingPoint, table{scalar(2, precisionSingle)}),
+			),
+		}),
+	}
+	if err := writeMessage(buf, message(headerSchema, schema, 0), nil); err != nil {
+		return err
+	}
+	for s := 0; s < len(embs); s += batchSize {
+		e := s + batchSize
+		if e > len(embs) {
+			e = len(embs)
+		}
+		if err := writeRecordBatch(buf, embs[s:e]); err != nil {
+			return err
+		}
+	}
+	// end of the stream.
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint32(b, continuation)
+	if _, err := buf.Write(b); err != nil {
+		return err
+	}
+	return buf.Flush()
+}
+
+func writeRecordBatch(w io.Writer, embs embedding.Embeddings) error {
+	rows, dim := len(embs), embs[0].Dim
+
+	wordOffsets := make([]byte, 4*(rows+1))
+	vectorOffsets := make([]byte, 4*(rows+1))
+	var words []byte
+	values := make([]byte, 0, 4*rows*dim)
+	b := make([]byte, 4)
+	for i, emb := range embs {
+		words = append(words, emb.Word...)
+		if len(words) > math.MaxInt32 {
+			return errors.Errorf("the words of %d bytes exceed the limit of Arrow string %d bytes", len(words), math.MaxInt32)
+		}
+		binary.LittleEndian.PutUint32(wordOffsets[4*(i+1):], uint32(len(words)))
+		binary.LittleEndian.PutUint32(vectorOffsets[4*(i+1):], uint32((i+1)*dim))
+		for _, v := range emb.Vector {
+			binary.LittleEndian.PutUint32(b, math.Float32bits(float32(v)))
+			values = append(values, b...)
+		}
+	}
+
+	// the buffers of each column in pre-order: the validity bitmaps are empty as there are no nulls.
+	var body []byte
+	var buffers structs
+	for _, data := range [][]byte{nil, wordOffsets, words, nil, vectorOffsets, nil, values} {
+		buffers = append(buffers, [2]int64{int64(len(body)), int64(len(data))})
+		body = append(body, data...)
+		for len(body)%8 != 0 {
+			body = append(body, 0)
+		}
+	}
+	batch := table{
+		scalar(8, uint64(rows)),
+		ref(structs{{int64(rows), 0}, {int64(rows), 0}, {int64(rows * dim), 0}}),
+		ref(buffers),
+	}
+	return writeMessage(w, message(headerRecordBatch, batch, len(body)), body)
+}
+
+// writeMessage writes the encapsulated message of the metadata padded to 8 bytes, followed by the body.
+func writeMessage(w io.Writer, msg table, body []byte) error {
+	meta := finish(msg)
+	for len(meta)%8 != 0 {
+		meta = append(meta, 0)
+	}
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint32(b, continuation)
+	binary.LittleEndian.PutUint32(b[4:], uint32(len(meta)))
+	for _, p := range [][]byte{b, meta, body} {
+		if _, err := w.Write(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrow
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/embedding"
+)
+
+// reader is the table in the flatbuffers.
+type reader struct {
+	buf []byte
+	pos int
+}
+
+func root(buf []byte) reader {
+	return reader{buf: buf, pos: int(binary.LittleEndian.Uint32(buf))}
+}
+
+// field returns the position of the field, or 0 if absent.
+func (r reader) field(id int) int {
+	vtable := r.pos - int(int32(binary.LittleEndian.Uint32(r.buf[r.pos:])))
+	if 4+2*id >= int(binary.LittleEndian.Uint16(r.buf[vtable:])) {
+		return 0
+	}
+	off := int(binary.LittleEndian.Uint16(r.buf[vtable+4+2*id:]))
+	if off == 0 {
+		return 0
+	}
+	return r.pos + off
+}
+
+func (r reader) uint(id, size int) uint64 {
+	pos := r.field(id)
+	if pos == 0 {
+		return 0
+	}
+	var v uint64
+	for i := 0; i < size; i++ {
+		v |= uint64(r.buf[pos+i]) << (8 * i)
+	}
+	return v
+}
+
+func (r reader) deref(pos int) int {
+	return pos + int(binary.LittleEndian.Uint32(r.buf[pos:]))
+}
+
+func (r reader) table(id int) reader {
+	return reader{buf: r.buf, pos: r.deref(r.field(id))}
+}
+
+// vector returns the position of the elements and the length.
+func (r reader) vector(id int) (int, int) {
+	pos := r.deref(r.field(id))
+	return pos + 4, int(binary.LittleEndian.Uint32(r.buf[pos:]))
+}
+
+func (r reader) tables(id int) []reader {
+	pos, n := r.vector(id)
+	var res []reader
+	for i := 0; i < n; i++ {
+		res = append(res, reader{buf: r.buf, pos: r.deref(pos + 4*i)})
+	}
+	return res
+}
+
+func (r reader) string(id int) string {
+	pos, n := r.vector(id)
+	return string(r.buf[pos : pos+n])
+}
+
+func (r reader) longs(id int) []int64 {
+	pos, n := r.vector(id)
+	if pos%8 != 0 {
+		panic("unaligned structs")
+	}
+	var res []int64
+	for i := 0; i < 2*n; i++ {
+		res = append(res, int64(binary.LittleEndian.Uint64(r.buf[pos+8*i:])))
+	}
+	return res
+}
+
+// next reads the encapsulated message.
+func next(t *testing.T, b []byte) (reader, []byte, []byte) {
+	assert.Equal(t, uint32(continuation), binary.LittleEndian.Uint32(b))
+	size := int(binary.LittleEndian.Uint32(b[4:]))
+	assert.Zero(t, size%8)
+	msg := root(b[8 : 8+size])
+	assert.Equal(t, uint64(metadataV5), msg.uint(0, 2))
+	bodyLength := int(msg.uint(3, 8))
+	return msg, b[8+size : 8+size+bodyLength], b[8+size+bodyLength:]
+}
+
+func TestWrite(t *testing.T) {
+	embs := embedding.Embeddings{
+		{Word: "a", Dim: 2, Vector: []float64{1, 2}},
+		{Word: "bc", Dim: 2, Vector: []float64{-0.5, 3}},
+		{Word: "d", Dim: 2, Vector: []float64{0, 1}},
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, Write(&buf, embs))
+
+	msg, _, rest := next(t, buf.Bytes())
+	assert.Equal(t, uint64(headerSchema), msg.uint(1, 1))
+	fields := msg.table(2).tables(1)
+	assert.Len(t, fields, 2)
+	assert.Equal(t, Word, fields[0].string(0))
+	assert.Equal(t, uint64(typeUtf8), fields[0].uint(2, 1))
+	assert.Empty(t, fields[0].tables(5))
+	assert.Equal(t, Vector, fields[1].string(0))
+	assert.Equal(t, uint64(typeList), fields[1].uint(2, 1))
+	items := fields[1].tables(5)
+	assert.Len(t, items, 1)
+	assert.Equal(t, uint64(typeFloatingPoint), items[0].uint(2, 1))
+	assert.Equal(t, uint64(precisionSingle), items[0].table(3).uint(0, 2))
+
+	msg, body, rest := next(t, rest)
+	assert.Equal(t, uint64(headerRecordBatch), msg.uint(1, 1))
+	batch := msg.table(2)
+	assert.Equal(t, uint64(3), batch.uint(0, 8))
+	assert.Equal(t, []int64{3, 0, 3, 0, 6, 0}, batch.longs(1))
+	buffers := batch.longs(2)
+	assert.Len(t, buffers, 14)
+	data := func(i int) []byte {
+		assert.Zero(t, buffers[2*i]%8)
+		return body[buffers[2*i] : buffers[2*i]+buffers[2*i+1]]
+	}
+	assert.Equal(t, []byte{0, 0, 0, 0, 1, 0, 0, 0, 3, 0, 0, 0, 4, 0, 0, 0}, data(1))
+	assert.Equal(t, "abcd", string(data(2)))
+	assert.Equal(t, []byte{0, 0, 0, 0, 2, 0, 0, 0, 4, 0, 0, 0, 6, 0, 0, 0}, data(4))
+	var got []float64
+	values := data(6)
+	for i := 0; i < len(values); i += 4 {
+		got = append(got, float64(math.Float32frombits(binary.LittleEndian.Uint32(values[i:]))))
+	}
+	assert.Equal(t, []float64{1, 2, -0.5, 3, 0, 1}, got)
+
+	assert.Equal(t, []byte{0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0}, rest)
+}
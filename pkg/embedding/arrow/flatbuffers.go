@@ -0,0 +1,158 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arrow
+
+import (
+	"encoding/binary"
+)
+
+// builder lays out the flatbuffers front to back, so that the offsets to the children always point forward.
+type builder struct {
+	buf []byte
+}
+
+// finish returns the buffer of the root object.
+func finish(root object) []byte {
+	b := &builder{buf: make([]byte, 4)}
+	pos := root.build(b)
+	binary.LittleEndian.PutUint32(b.buf, uint32(pos))
+	return b.buf
+}
+
+func (b *builder) align(n int) {
+	for len(b.buf)%n != 0 {
+		b.buf = append(b.buf, 0)
+	}
+}
+
+func (b *builder) uint32(v uint32) {
+	b.buf = append(b.buf, 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(b.buf[len(b.buf)-4:], v)
+}
+
+// ref points the offset at pos to the child.
+func (b *builder) ref(pos int, child object) {
+	// the child is built before slicing the buffer which may grow.
+	c := child.build(b)
+	binary.LittleEndian.PutUint32(b.buf[pos:], uint32(c-pos))
+}
+
+// object is the table, the vector, or the string, which is built at the returned position.
+type object interface {
+	build(b *builder) int
+}
+
+// slot is the field of the table, which is the scalar of size bytes, the offset to child, or absent.
+type slot struct {
+	size  int
+	value uint64
+	child object
+}
+
+func scalar(size int, v uint64) slot {
+	return slot{size: size, value: v}
+}
+
+func ref(child object) slot {
+	return slot{size: 4, child: child}
+}
+
+// table is indexed by the field ids.
+type table []slot
+
+func (t table) build(b *builder) int {
+	// the fields follow the offset to the vtable in descending order of size to be aligned.
+	offsets := make([]int, len(t))
+	size := 4
+	for _, n := range []int{8, 4, 2, 1} {
+		for i, s := range t {
+			if s.size == n {
+				size = (size + n - 1) / n * n
+				offsets[i] = size
+				size += n
+			}
+		}
+	}
+
+	b.align(2)
+	vtable := len(b.buf)
+	for _, v := range append([]int{4 + 2*len(t), size}, offsets...) {
+		b.buf = append(b.buf, byte(v), byte(v>>8))
+	}
+
+	b.align(8)
+	pos := len(b.buf)
+	b.buf = append(b.buf, make([]byte, size)...)
+	binary.LittleEndian.PutUint32(b.buf[pos:], uint32(pos-vtable))
+	for i, s := range t {
+		if s.size == 0 || s.child != nil {
+			continue
+		}
+		for j := 0; j < s.size; j++ {
+			b.buf[pos+offsets[i]+j] = byte(s.value >> (8 * j))
+		}
+	}
+	for i, s := range t {
+		if s.child != nil {
+			b.ref(pos+offsets[i], s.child)
+		}
+	}
+	return pos
+}
+
+type vector []object
+
+func (v vector) build(b *builder) int {
+	b.align(4)
+	pos := len(b.buf)
+	b.uint32(uint32(len(v)))
+	for range v {
+		b.uint32(0)
+	}
+	for i, child := range v {
+		b.ref(pos+4+4*i, child)
+	}
+	return pos
+}
+
+// structs is the vector of the structs of two longs, e.g. FieldNode and Buffer.
+type structs [][2]int64
+
+func (v structs) build(b *builder) int {
+	// the length precedes the elements aligned to 8 bytes.
+	for len(b.buf)%8 != 4 {
+		b.buf = append(b.buf, 0)
+	}
+	pos := len(b.buf)
+	b.uint32(uint32(len(v)))
+	for _, s := range v {
+		for _, l := range s {
+			b.buf = append(b.buf, make([]byte, 8)...)
+			binary.LittleEndian.PutUint64(b.buf[len(b.buf)-8:], uint64(l))
+		}
+	}
+	return pos
+}
+
+type str string
+
+func (s str) build(b *builder) int {
+	b.align(4)
+	pos := len(b.buf)
+	b.uint32(uint32(len(s)))
+	b.buf = append(b.buf, s...)
+	b.buf = append(b.buf, 0)
+	return pos
+}
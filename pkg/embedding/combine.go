@@ -0,0 +1,92 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embedding
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/embedding/embutil"
+	"github.com/ynqa/wego/pkg/vector"
+)
+
+// CombineMethod is how to merge the spaces into the meta-embeddings.
+type CombineMethod = string
+
+const (
+	// Concat concatenates the unit vectors of the spaces.
+	Concat CombineMethod = "concat"
+	// Avg averages the unit vectors of the spaces, where the shorter ones are padded by zeros
+	// (Coates and Bollegala, 2018, "Frustratingly Easy Meta-Embedding").
+	Avg CombineMethod = "avg"
+	// SVD reduces the concatenation by PCA to the dimension.
+	SVD CombineMethod = "svd"
+)
+
+func InvalidCombineMethodError(method CombineMethod) error {
+	return errors.Errorf("invalid method: %s not in %s|%s|%s", method, Concat, Avg, SVD)
+}
+
+// Combine merges the words shared by all spaces of a into the meta-embeddings by method.
+// dim is the dimension of SVD, and ignored by the others.
+func Combine(a *Aligned, method CombineMethod, dim int) (Embeddings, error) {
+	shared := a.Shared()
+	if len(shared.Words) == 0 {
+		return nil, errors.New("no shared words between embeddings")
+	}
+	var size int
+	switch method {
+	case Concat, SVD:
+		for _, d := range shared.Dims {
+			size += d
+		}
+	case Avg:
+		for _, d := range shared.Dims {
+			if d > size {
+				size = d
+			}
+		}
+	default:
+		return nil, InvalidCombineMethodError(method)
+	}
+
+	res := make(Embeddings, len(shared.Words))
+	for i, word := range shared.Words {
+		vec := make([]float64, size)
+		var off int
+		for _, rows := range shared.Rows {
+			unit := vector.Unit(rows[i])
+			switch method {
+			case Concat, SVD:
+				copy(vec[off:], unit)
+				off += len(unit)
+			case Avg:
+				vector.Add(vec, unit)
+			}
+		}
+		if method == Avg {
+			vector.Scale(vec, 1/float64(len(shared.Rows)))
+		}
+		res[i] = Embedding{
+			Word:   word,
+			Dim:    size,
+			Vector: vec,
+			Norm:   embutil.Norm(vec),
+		}
+	}
+	if method == SVD {
+		return Reduce(res, dim)
+	}
+	return res, nil
+}
@@ -0,0 +1,55 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embedding
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCombine(t *testing.T) {
+	a, err := Align(
+		Embeddings{
+			{Word: "apple", Dim: 2, Vector: []float64{3, 4}},
+			{Word: "banana", Dim: 2, Vector: []float64{0, 2}},
+			{Word: "cherry", Dim: 2, Vector: []float64{1, 0}},
+		},
+		Embeddings{
+			{Word: "banana", Dim: 1, Vector: []float64{-2}},
+			{Word: "apple", Dim: 1, Vector: []float64{5}},
+		},
+	)
+	assert.NoError(t, err)
+
+	concat, err := Combine(a, Concat, 0)
+	assert.NoError(t, err)
+	assert.Len(t, concat, 2)
+	assert.Equal(t, "apple", concat[0].Word)
+	assert.InDeltaSlice(t, []float64{0.6, 0.8, 1}, concat[0].Vector, 1e-9)
+	assert.InDelta(t, math.Sqrt(2), concat[0].Norm, 1e-9)
+
+	avg, err := Combine(a, Avg, 0)
+	assert.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{-0.5, 0.5}, avg[1].Vector, 1e-9)
+
+	svd, err := Combine(a, SVD, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, svd[0].Dim)
+
+	_, err = Combine(a, "max", 0)
+	assert.Error(t, err)
+}
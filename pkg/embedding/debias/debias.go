@@ -0,0 +1,218 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debias
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/embutil"
+	"github.com/ynqa/wego/pkg/util/fileutil"
+	"github.com/ynqa/wego/pkg/vector"
+)
+
+// Pair is a pair of words which differ only in the bias direction, e.g. she and he.
+type Pair struct {
+	Word1, Word2 string
+}
+
+// LoadPairs reads the lines of `word1 word2`. Empty lines and lines starting with # are skipped.
+func LoadPairs(r io.Reader) ([]Pair, error) {
+	var pairs []Pair
+	s := fileutil.NewScanner(r, bufio.ScanLines)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, errors.Errorf("line %d must be `word1 word2`, got %q", n, line)
+		}
+		pairs = append(pairs, Pair{Word1: fields[0], Word2: fields[1]})
+	}
+	if err := s.Err(); err != nil && err != io.EOF {
+		return nil, errors.Wrapf(err, "failed to scan")
+	}
+	return pairs, nil
+}
+
+// LoadWords reads the words separated by space or newline. Lines starting with # are skipped.
+func LoadWords(r io.Reader) ([]string, error) {
+	return fileutil.LoadWords(r)
+}
+
+var (
+	defaultComponents = 1
+)
+
+type Options struct {
+	Components int
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Components: defaultComponents,
+	}
+}
+
+// Debias is the hard debiasing by Bolukbasi et al. (2016), "Man is to Computer Programmer as Woman
+// is to Homemaker? Debiasing Word Embeddings". The bias subspace is the principal components of
+// the definitional pairs. The neutral words are projected out of the subspace, and the words of
+// each equalize pair are made equidistant to the neutral words. If neutral is nil, all words except
+// the ones in the pairs are neutral. All vectors are normalized, and embs are not modified.
+func Debias(embs embedding.Embeddings, definitional, equalize []Pair, neutral []string, opts Options) (embedding.Embeddings, error) {
+	if embs.Empty() {
+		return nil, errors.New("embeddings are empty")
+	}
+	if err := embs.Validate(); err != nil {
+		return nil, err
+	}
+	dim := embs[0].Dim
+	if opts.Components <= 0 || opts.Components > dim {
+		return nil, errors.Errorf("components must be in [1, %d], got %d", dim, opts.Components)
+	}
+
+	res := make(embedding.Embeddings, len(embs))
+	index := make(map[string]int, len(embs))
+	for i, emb := range embs {
+		vec := make([]float64, dim)
+		copy(vec, emb.Vector)
+		normalize(vec)
+		res[i] = embedding.Embedding{
+			Word:   emb.Word,
+			Dim:    dim,
+			Vector: vec,
+		}
+		index[emb.Word] = i
+	}
+	lookup := func(p Pair) ([]float64, []float64, bool) {
+		i1, ok1 := index[p.Word1]
+		i2, ok2 := index[p.Word2]
+		if !ok1 || !ok2 {
+			return nil, nil, false
+		}
+		return res[i1].Vector, res[i2].Vector, true
+	}
+
+	var centered [][]float64
+	for _, p := range definitional {
+		v1, v2, ok := lookup(p)
+		if !ok {
+			continue
+		}
+		d1, d2 := make([]float64, dim), make([]float64, dim)
+		for i := 0; i < dim; i++ {
+			mu := (v1[i] + v2[i]) / 2
+			d1[i], d2[i] = v1[i]-mu, v2[i]-mu
+		}
+		centered = append(centered, d1, d2)
+	}
+	if len(centered) == 0 {
+		return nil, errors.New("no definitional pairs are found in embeddings")
+	}
+	subspace, err := embutil.Principal(centered, opts.Components)
+	if err != nil {
+		return nil, err
+	}
+
+	if neutral == nil {
+		specific := make(map[string]struct{})
+		for _, pairs := range [][]Pair{definitional, equalize} {
+			for _, p := range pairs {
+				specific[p.Word1] = struct{}{}
+				specific[p.Word2] = struct{}{}
+			}
+		}
+		for _, emb := range embs {
+			if _, ok := specific[emb.Word]; !ok {
+				neutral = append(neutral, emb.Word)
+			}
+		}
+	}
+	for _, word := range neutral {
+		i, ok := index[word]
+		if !ok {
+			continue
+		}
+		vec := res[i].Vector
+		proj := project(vec, subspace)
+		for j := range vec {
+			vec[j] -= proj[j]
+		}
+		normalize(vec)
+	}
+
+	for _, p := range equalize {
+		v1, v2, ok := lookup(p)
+		if !ok {
+			continue
+		}
+		mu := make([]float64, dim)
+		for i := range mu {
+			mu[i] = (v1[i] + v2[i]) / 2
+		}
+		muB := project(mu, subspace)
+		nu := make([]float64, dim)
+		for i := range nu {
+			nu[i] = mu[i] - muB[i]
+		}
+		scale := math.Sqrt(math.Max(0, 1-vector.Dot(nu, nu)))
+		for _, vec := range [][]float64{v1, v2} {
+			vecB := project(vec, subspace)
+			for i := range vecB {
+				vecB[i] -= muB[i]
+			}
+			normalize(vecB)
+			for i := range vec {
+				vec[i] = nu[i] + scale*vecB[i]
+			}
+		}
+	}
+
+	for i := range res {
+		res[i].Norm = embutil.Norm(res[i].Vector)
+	}
+	return res, nil
+}
+
+// project returns the projection of vec onto the subspace spanned by the orthonormal basis.
+func project(vec []float64, basis [][]float64) []float64 {
+	res := make([]float64, len(vec))
+	for _, b := range basis {
+		d := vector.Dot(vec, b)
+		for i := range res {
+			res[i] += d * b[i]
+		}
+	}
+	return res
+}
+
+// normalize scales vec to unit length in place, and returns false for zero vector.
+func normalize(vec []float64) bool {
+	n := embutil.Norm(vec)
+	if n == 0 {
+		return false
+	}
+	for i := range vec {
+		vec[i] /= n
+	}
+	return true
+}
@@ -0,0 +1,86 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debias
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/embutil"
+	"github.com/ynqa/wego/pkg/search/searchutil"
+)
+
+func TestLoadPairs(t *testing.T) {
+	pairs, err := LoadPairs(strings.NewReader("# comment\nshe he\n\nher his\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []Pair{{"she", "he"}, {"her", "his"}}, pairs)
+
+	_, err = LoadPairs(strings.NewReader("she he her\n"))
+	assert.Error(t, err)
+}
+
+func TestDebias(t *testing.T) {
+	vecs := map[string][]float64{
+		"he":     {1, 1, 0},
+		"she":    {-1, 1, 0},
+		"king":   {0.8, 0.3, 0.5},
+		"queen":  {-0.6, 0.4, 0.5},
+		"doctor": {0.5, 0.2, 1},
+		"nurse":  {-0.5, 0.2, 1},
+	}
+	var embs embedding.Embeddings
+	for _, w := range []string{"he", "she", "king", "queen", "doctor", "nurse"} {
+		embs = append(embs, embedding.Embedding{
+			Word:   w,
+			Dim:    3,
+			Vector: vecs[w],
+			Norm:   embutil.Norm(vecs[w]),
+		})
+	}
+
+	res, err := Debias(embs, []Pair{{"she", "he"}}, []Pair{{"queen", "king"}}, nil, DefaultOptions())
+	assert.NoError(t, err)
+	get := func(word string) embedding.Embedding {
+		emb, _ := res.Find(word)
+		return emb
+	}
+	cos := func(w1, w2 string) float64 {
+		e1, e2 := get(w1), get(w2)
+		return searchutil.Cosine(e1.Vector, e2.Vector, e1.Norm, e2.Norm)
+	}
+
+	// the bias direction is the first axis.
+	for _, w := range []string{"doctor", "nurse"} {
+		assert.InDelta(t, 0, get(w).Vector[0], 1e-9)
+		assert.InDelta(t, 1, get(w).Norm, 1e-9)
+	}
+	assert.InDelta(t, 1, cos("doctor", "nurse"), 1e-9)
+	assert.InDelta(t, cos("doctor", "king"), cos("doctor", "queen"), 1e-9)
+	assert.InDelta(t, 1, get("king").Norm, 1e-9)
+	assert.Equal(t, []float64{0.5, 0.2, 1}, embs[4].Vector)
+
+	// only the given neutral words are neutralized.
+	res, err = Debias(embs, []Pair{{"she", "he"}}, nil, []string{"doctor"}, DefaultOptions())
+	assert.NoError(t, err)
+	assert.InDelta(t, 0, get("doctor").Vector[0], 1e-9)
+	assert.True(t, math.Abs(get("nurse").Vector[0]) > 0.1)
+
+	_, err = Debias(embs, []Pair{{"unknown", "he"}}, nil, nil, DefaultOptions())
+	assert.Error(t, err)
+}
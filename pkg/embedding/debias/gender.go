@@ -0,0 +1,82 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debias
+
+// GenderDefinitional returns the definitional pairs of gender from Bolukbasi et al. (2016).
+func GenderDefinitional() []Pair {
+	return pairs(
+		"woman", "man",
+		"girl", "boy",
+		"she", "he",
+		"mother", "father",
+		"daughter", "son",
+		"gal", "guy",
+		"female", "male",
+		"her", "his",
+		"herself", "himself",
+		"mary", "john",
+	)
+}
+
+// GenderEqualize returns the pairs of gender to be equalized from Bolukbasi et al. (2016).
+func GenderEqualize() []Pair {
+	return pairs(
+		"woman", "man",
+		"women", "men",
+		"girl", "boy",
+		"girls", "boys",
+		"she", "he",
+		"her", "his",
+		"herself", "himself",
+		"mother", "father",
+		"mothers", "fathers",
+		"daughter", "son",
+		"daughters", "sons",
+		"sister", "brother",
+		"sisters", "brothers",
+		"aunt", "uncle",
+		"niece", "nephew",
+		"grandmother", "grandfather",
+		"granddaughter", "grandson",
+		"queen", "king",
+		"queens", "kings",
+		"princess", "prince",
+		"lady", "gentleman",
+		"ladies", "gentlemen",
+		"female", "male",
+		"females", "males",
+		"mom", "dad",
+		"moms", "dads",
+		"wife", "husband",
+		"wives", "husbands",
+		"businesswoman", "businessman",
+		"chairwoman", "chairman",
+		"congresswoman", "congressman",
+		"spokeswoman", "spokesman",
+		"schoolgirl", "schoolboy",
+		"sorority", "fraternity",
+		"motherhood", "fatherhood",
+		"convent", "monastery",
+		"estrogen", "testosterone",
+	)
+}
+
+func pairs(words ...string) []Pair {
+	res := make([]Pair, len(words)/2)
+	for i := range res {
+		res[i] = Pair{Word1: words[2*i], Word2: words[2*i+1]}
+	}
+	return res
+}
@@ -0,0 +1,302 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drift
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/embutil"
+	"github.com/ynqa/wego/pkg/search"
+	"github.com/ynqa/wego/pkg/search/searchutil"
+)
+
+var (
+	defaultK          = 10
+	defaultMaxDrift   = 0.0
+	defaultMinJaccard = 0.0
+	defaultSample     = 2000
+	defaultSeed       = int64(1)
+	defaultTop        = 20
+)
+
+type Options struct {
+	K int
+	// MaxDrift and MinJaccard are the thresholds of the mean drift and the mean Jaccard overlap
+	// which Check fails beyond, 0 means disabled.
+	MaxDrift   float64
+	MinJaccard float64
+	Sample     int
+	Seed       int64
+	Top        int
+}
+
+func DefaultOptions() Options {
+	return Options{
+		K:          defaultK,
+		MaxDrift:   defaultMaxDrift,
+		MinJaccard: defaultMinJaccard,
+		Sample:     defaultSample,
+		Seed:       defaultSeed,
+		Top:        defaultTop,
+	}
+}
+
+// Moved is the drift of the word, the cosine distance between the aligned old vector and the new one,
+// and the Jaccard overlap of the k nearest neighbors in both spaces.
+type Moved struct {
+	Word    string  `json:"word"`
+	Drift   float64 `json:"drift"`
+	Jaccard float64 `json:"jaccard"`
+}
+
+// Report is the drift from the old space to the new one over the shared vocabulary.
+// The old space is aligned by the orthogonal Procrustes onto the new one, so the drift is not caused by the rotation.
+// JaccardMean is estimated on the Sampled words, and Moved is the Top most moved words.
+type Report struct {
+	Old         int     `json:"old"`
+	New         int     `json:"new"`
+	Shared      int     `json:"shared"`
+	Added       int     `json:"added"`
+	Removed     int     `json:"removed"`
+	DriftMean   float64 `json:"drift_mean"`
+	DriftMedian float64 `json:"drift_median"`
+	DriftP90    float64 `json:"drift_p90"`
+	DriftMax    float64 `json:"drift_max"`
+	Sampled     int     `json:"sampled"`
+	JaccardMean float64 `json:"jaccard_mean"`
+	Moved       []Moved `json:"moved"`
+}
+
+// Write writes the report in format, search.Table or search.JSON.
+func (r Report) Write(w io.Writer, format search.Format) error {
+	switch format {
+	case search.Table:
+		writer := tablewriter.NewWriter(w)
+		writer.SetHeader([]string{"Metric", "Value"})
+		writer.SetBorder(false)
+		writer.AppendBulk([][]string{
+			{"old vocab", fmt.Sprintf("%d", r.Old)},
+			{"new vocab", fmt.Sprintf("%d", r.New)},
+			{"shared", fmt.Sprintf("%d", r.Shared)},
+			{"added", fmt.Sprintf("%d", r.Added)},
+			{"removed", fmt.Sprintf("%d", r.Removed)},
+			{"drift mean", fmt.Sprintf("%f", r.DriftMean)},
+			{"drift median", fmt.Sprintf("%f", r.DriftMedian)},
+			{"drift p90", fmt.Sprintf("%f", r.DriftP90)},
+			{"drift max", fmt.Sprintf("%f", r.DriftMax)},
+			{"sampled", fmt.Sprintf("%d", r.Sampled)},
+			{"jaccard mean", fmt.Sprintf("%f", r.JaccardMean)},
+		})
+		writer.Render()
+		fmt.Fprintln(w)
+
+		writer = tablewriter.NewWriter(w)
+		writer.SetHeader([]string{"Rank", "Word", "Drift", "Jaccard"})
+		writer.SetBorder(false)
+		for i, m := range r.Moved {
+			writer.Append([]string{
+				fmt.Sprintf("%d", i+1),
+				m.Word,
+				fmt.Sprintf("%f", m.Drift),
+				fmt.Sprintf("%f", m.Jaccard),
+			})
+		}
+		writer.Render()
+		return nil
+	case search.JSON:
+		if r.Moved == nil {
+			r.Moved = []Moved{}
+		}
+		return json.NewEncoder(w).Encode(r)
+	default:
+		return errors.Errorf("invalid format: %s not in %s|%s", format, search.Table, search.JSON)
+	}
+}
+
+// Check returns the error if the report is beyond the thresholds of opts, e.g. to gate the deployment.
+func (r Report) Check(opts Options) error {
+	if opts.MaxDrift > 0 && r.DriftMean > opts.MaxDrift {
+		return errors.Errorf("mean drift %f is over %f", r.DriftMean, opts.MaxDrift)
+	} else if opts.MinJaccard > 0 && r.JaccardMean < opts.MinJaccard {
+		return errors.Errorf("mean jaccard %f is under %f", r.JaccardMean, opts.MinJaccard)
+	}
+	return nil
+}
+
+// Compare reports the drift from old to new embeddings, which must have the same dimension.
+func Compare(old, new embedding.Embeddings, opts Options) (Report, error) {
+	if old.Empty() || new.Empty() {
+		return Report{}, errors.New("embeddings are empty")
+	}
+	if err := old.Validate(); err != nil {
+		return Report{}, err
+	}
+	if err := new.Validate(); err != nil {
+		return Report{}, err
+	}
+	if old[0].Dim != new[0].Dim {
+		return Report{}, errors.Errorf("dimension must be the same, got %d and %d", old[0].Dim, new[0].Dim)
+	}
+	if opts.K <= 0 || opts.Top < 0 || opts.Sample < 0 {
+		return Report{}, errors.Errorf("k must be positive, top and sample >= 0, got %d, %d, %d", opts.K, opts.Top, opts.Sample)
+	}
+
+	index := make(map[string]int, len(old))
+	for i, emb := range old {
+		index[emb.Word] = i
+	}
+	// x and y are the unit vectors of the shared words in the order of new.
+	var words []string
+	var x, y [][]float64
+	for _, emb := range new {
+		i, ok := index[emb.Word]
+		if !ok {
+			continue
+		}
+		words = append(words, emb.Word)
+		x = append(x, unit(old[i]))
+		y = append(y, unit(emb))
+	}
+	report := Report{
+		Old:     len(old),
+		New:     len(new),
+		Shared:  len(words),
+		Added:   len(new) - len(words),
+		Removed: len(old) - len(words),
+	}
+	if len(words) == 0 {
+		return Report{}, errors.New("no shared words between embeddings")
+	}
+
+	w := embutil.Procrustes(x, y)
+	drifts := make([]float64, len(words))
+	for r := range x {
+		aligned := make([]float64, len(w))
+		for i, xi := range x[r] {
+			for j, wij := range w[i] {
+				aligned[j] += xi * wij
+			}
+		}
+		drifts[r] = 1 - searchutil.Cosine(aligned, y[r], embutil.Norm(aligned), embutil.Norm(y[r]))
+	}
+	sorted := append([]float64(nil), drifts...)
+	sort.Float64s(sorted)
+	for _, d := range sorted {
+		report.DriftMean += d
+	}
+	report.DriftMean /= float64(len(sorted))
+	report.DriftMedian = quantile(sorted, 0.5)
+	report.DriftP90 = quantile(sorted, 0.9)
+	report.DriftMax = sorted[len(sorted)-1]
+
+	order := make([]int, len(words))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return drifts[order[i]] > drifts[order[j]]
+	})
+	top := opts.Top
+	if top > len(order) {
+		top = len(order)
+	}
+
+	sample := make([]int, len(words))
+	for i := range sample {
+		sample[i] = i
+	}
+	if opts.Sample > 0 && opts.Sample < len(words) {
+		sample = rand.New(rand.NewSource(opts.Seed)).Perm(len(words))[:opts.Sample]
+	}
+	queries := append(append([]int(nil), sample...), order[:top]...)
+	jaccards := overlap(x, y, queries, opts.K)
+
+	report.Sampled = len(sample)
+	for _, q := range sample {
+		report.JaccardMean += jaccards[q]
+	}
+	report.JaccardMean /= float64(len(sample))
+	report.Moved = make([]Moved, top)
+	for i, r := range order[:top] {
+		report.Moved[i] = Moved{
+			Word:    words[r],
+			Drift:   drifts[r],
+			Jaccard: jaccards[r],
+		}
+	}
+	return report, nil
+}
+
+func unit(emb embedding.Embedding) []float64 {
+	vec := make([]float64, emb.Dim)
+	if emb.Norm == 0 {
+		return vec
+	}
+	for i, v := range emb.Vector {
+		vec[i] = v / emb.Norm
+	}
+	return vec
+}
+
+func quantile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(pos-float64(lo))
+}
+
+// overlap returns the Jaccard overlap of the k nearest neighbors in x and in y for the queries,
+// which is indexed by the rows.
+func overlap(x, y [][]float64, queries []int, k int) map[int]float64 {
+	if k > len(x)-1 {
+		k = len(x) - 1
+	}
+	res := make(map[int]float64, len(queries))
+	if k <= 0 {
+		for _, q := range queries {
+			res[q] = 1
+		}
+		return res
+	}
+	jaccards := make([]float64, len(queries))
+	threads := runtime.NumCPU()
+	wg := &sync.WaitGroup{}
+	for t := 0; t < threads; t++ {
+		wg.Add(1)
+		go func(t int) {
+			defer wg.Done()
+			for n := t; n < len(queries); n += threads {
+				q := queries[n]
+				jaccards[n] = searchutil.Jaccard(searchutil.Nearest(x, q, k), searchutil.Nearest(y, q, k))
+			}
+		}(t)
+	}
+	wg.Wait()
+	for n, q := range queries {
+		res[q] = jaccards[n]
+	}
+	return res
+}
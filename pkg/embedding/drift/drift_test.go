@@ -0,0 +1,116 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drift
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/embutil"
+	"github.com/ynqa/wego/pkg/search"
+)
+
+func newEmbeddings(words []string, vecs [][]float64) embedding.Embeddings {
+	embs := make(embedding.Embeddings, len(vecs))
+	for i, vec := range vecs {
+		embs[i] = embedding.Embedding{
+			Word:   words[i],
+			Dim:    len(vec),
+			Vector: vec,
+			Norm:   embutil.Norm(vec),
+		}
+	}
+	return embs
+}
+
+func TestCompareRotation(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	words := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+	old, rotated := make([][]float64, len(words)), make([][]float64, len(words))
+	c, s := math.Cos(1), math.Sin(1)
+	for i := range words {
+		v := []float64{rng.NormFloat64(), rng.NormFloat64(), rng.NormFloat64()}
+		old[i] = v
+		rotated[i] = []float64{c*v[0] - s*v[1], s*v[0] + c*v[1], v[2]}
+	}
+	// c is moved in the new space, and h is replaced by i.
+	rotated[2] = []float64{-rotated[2][0], -rotated[2][1], -rotated[2][2]}
+	newWords := append(append([]string(nil), words[:7]...), "i")
+
+	opts := DefaultOptions()
+	opts.K = 2
+	opts.Top = 1
+	report, err := Compare(newEmbeddings(words, old), newEmbeddings(newWords, rotated), opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, report.Shared)
+	assert.Equal(t, 1, report.Added)
+	assert.Equal(t, 1, report.Removed)
+	assert.Equal(t, 7, report.Sampled)
+	assert.Len(t, report.Moved, 1)
+	assert.Equal(t, "c", report.Moved[0].Word)
+	assert.Equal(t, report.DriftMax, report.Moved[0].Drift)
+	assert.True(t, report.DriftMedian < 0.1)
+	assert.True(t, report.JaccardMean > 0 && report.JaccardMean <= 1)
+}
+
+func TestCompareIdentical(t *testing.T) {
+	words := []string{"a", "b", "c", "d"}
+	embs := newEmbeddings(words, [][]float64{{1, 0}, {0, 1}, {1, 1}, {-1, 2}})
+	report, err := Compare(embs, embs, DefaultOptions())
+	assert.NoError(t, err)
+	assert.InDelta(t, 0, report.DriftMean, 1e-9)
+	assert.InDelta(t, 0, report.DriftMax, 1e-9)
+	assert.Equal(t, 1., report.JaccardMean)
+	assert.NoError(t, report.Check(Options{MaxDrift: 0.01, MinJaccard: 0.9}))
+}
+
+func TestCompareInvalid(t *testing.T) {
+	a := newEmbeddings([]string{"a"}, [][]float64{{1, 0}})
+	b := newEmbeddings([]string{"a"}, [][]float64{{1, 0, 0}})
+	c := newEmbeddings([]string{"c"}, [][]float64{{1, 0}})
+	_, err := Compare(a, b, DefaultOptions())
+	assert.Error(t, err)
+	_, err = Compare(a, c, DefaultOptions())
+	assert.Error(t, err)
+	_, err = Compare(nil, a, DefaultOptions())
+	assert.Error(t, err)
+}
+
+func TestCheck(t *testing.T) {
+	r := Report{DriftMean: 0.3, JaccardMean: 0.5}
+	assert.NoError(t, r.Check(DefaultOptions()))
+	assert.Error(t, r.Check(Options{MaxDrift: 0.2}))
+	assert.Error(t, r.Check(Options{MinJaccard: 0.6}))
+}
+
+func TestWrite(t *testing.T) {
+	r := Report{Shared: 2, Moved: []Moved{{Word: "a", Drift: 0.5, Jaccard: 0.25}}}
+	var buf bytes.Buffer
+	assert.NoError(t, r.Write(&buf, search.JSON))
+	var got Report
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, r, got)
+
+	buf.Reset()
+	assert.NoError(t, r.Write(&buf, search.Table))
+	assert.Contains(t, buf.String(), "0.250000")
+	assert.Error(t, r.Write(&buf, search.CSV))
+}
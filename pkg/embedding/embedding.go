@@ -16,15 +16,30 @@ package embedding
 
 import (
 	"bufio"
+	"fmt"
 	"io"
 	"strconv"
-	"strings"
 
 	"github.com/pkg/errors"
 
 	"github.com/ynqa/wego/pkg/embedding/embutil"
+	"github.com/ynqa/wego/pkg/util/fileutil"
 )
 
+// ParseError is the error of the line which is not the embedding, at Line from 1.
+type ParseError struct {
+	Line int
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
 type Embedding struct {
 	Word   string
 	Dim    int
@@ -84,47 +99,52 @@ func Load(r io.Reader) (Embeddings, error) {
 	return embs, nil
 }
 
-func parse(r io.Reader, op func(Embedding) error) error {
-	s := bufio.NewScanner(r)
-	for s.Scan() {
-		line := s.Text()
-		if strings.HasPrefix(line, " ") {
-			continue
-		}
-		emb, err := parseLine(line)
-		if err != nil {
-			return err
+// LoadFile reads the embeddings from the local file, or stdin for fileutil.Stdio.
+func LoadFile(path string) (Embeddings, error) {
+	r, err := fileutil.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return Load(r)
+}
+
+// Save writes the embeddings in the same format as the models save.
+func Save(w io.Writer, embs Embeddings) error {
+	buf := bufio.NewWriter(w)
+	for _, emb := range embs {
+		buf.WriteString(emb.Word)
+		for _, v := range emb.Vector {
+			buf.WriteByte(' ')
+			buf.WriteString(strconv.FormatFloat(v, 'f', 6, 64))
 		}
-		if err := op(emb); err != nil {
+		if err := buf.WriteByte('\n'); err != nil {
 			return err
 		}
 	}
-	if err := s.Err(); err != nil && err != io.EOF {
-		return errors.Wrapf(err, "failed to scan")
-	}
-	return nil
+	return buf.Flush()
+}
+
+func parse(r io.Reader, op func(Embedding) error) error {
+	return Parse(r, func(word string, vec []float64) error {
+		vec = append([]float64(nil), vec...)
+		return op(Embedding{
+			Word:   word,
+			Dim:    len(vec),
+			Vector: vec,
+			Norm:   embutil.Norm(vec),
+		})
+	})
 }
 
 func parseLine(line string) (Embedding, error) {
-	slice := strings.Fields(line)
-	if len(slice) < 2 {
-		return Embedding{}, errors.New("Must be over 2 lenghth for word and vector elems")
-	}
-	word := slice[0]
-	vector := slice[1:]
-	dim := len(vector)
-
-	vec := make([]float64, dim)
-	for k, elem := range vector {
-		val, err := strconv.ParseFloat(elem, 64)
-		if err != nil {
-			return Embedding{}, err
-		}
-		vec[k] = val
+	word, vec, err := parseFields(line, nil)
+	if err != nil {
+		return Embedding{}, err
 	}
 	return Embedding{
 		Word:   word,
-		Dim:    dim,
+		Dim:    len(vec),
 		Vector: vec,
 		Norm:   embutil.Norm(vec),
 	}, nil
@@ -20,6 +20,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/ynqa/wego/pkg/embedding/embutil"
 )
@@ -48,6 +49,23 @@ func TestLoad(t *testing.T) {
 	}
 }
 
+func TestSave(t *testing.T) {
+	contents := "apple 1.000000 -0.500000\nbanana 0.000000 0.250000\n"
+	embs, err := Load(bytes.NewReader([]byte(contents)))
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, Save(&buf, embs))
+	assert.Equal(t, contents, buf.String())
+}
+
+func TestLoadCRLF(t *testing.T) {
+	embs, err := Load(bytes.NewReader([]byte("\ufeffapple 1 0\r\nbanana 0 1\r\n")))
+	assert.NoError(t, err)
+	assert.Equal(t, "apple", embs[0].Word)
+	assert.Equal(t, []float64{0, 1}, embs[1].Vector)
+}
+
 func TestParse(t *testing.T) {
 	testNumVector := 4
 	testVectorStr := `apple 1 1 1 1 1
@@ -66,6 +84,11 @@ dragon -1 -1 -1 -1 -1`
 
 	assert.NoError(t, parse(f, op))
 	assert.Equal(t, testNumVector, len(embs))
+
+	err := parse(bytes.NewReader([]byte("apple 1 1\nbanana 1 x")), op)
+	var perr *ParseError
+	assert.True(t, errors.As(err, &perr))
+	assert.Equal(t, 2, perr.Line)
 }
 
 func TestParseLine(t *testing.T) {
@@ -0,0 +1,109 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embutil
+
+import (
+	"math"
+)
+
+// CCA returns the first canonical correlation between the pairs of the rows of x and y, the largest singular value
+// of Cxx^(-1/2) Cxy Cyy^(-1/2) of the centered rows. The directions which the rows don't span are dropped.
+func CCA(x, y [][]float64) float64 {
+	x, y = centered(x), centered(y)
+	dx, dy := len(x[0]), len(y[0])
+	cxy := make([][]float64, dx)
+	for i := range cxy {
+		cxy[i] = make([]float64, dy)
+	}
+	for r := range x {
+		for i, xi := range x[r] {
+			for j, yj := range y[r] {
+				cxy[i][j] += xi * yj
+			}
+		}
+	}
+	wx, wy := invSqrt(scatter(x)), invSqrt(scatter(y))
+
+	// a = wx cxy wy, and the singular values of a are the square roots of the eigenvalues of a^T a.
+	a := mul(mul(wx, cxy), wy)
+	ata := newSquare(dy)
+	for i := 0; i < dy; i++ {
+		for j := 0; j < dy; j++ {
+			for k := range a {
+				ata[i][j] += a[k][i] * a[k][j]
+			}
+		}
+	}
+	vals, _ := eigen(ata)
+	var max float64
+	for _, v := range vals {
+		max = math.Max(max, v)
+	}
+	return math.Sqrt(math.Min(max, 1))
+}
+
+func centered(rows [][]float64) [][]float64 {
+	mean := make([]float64, len(rows[0]))
+	for _, row := range rows {
+		for i, v := range row {
+			mean[i] += v / float64(len(rows))
+		}
+	}
+	res := make([][]float64, len(rows))
+	for r, row := range rows {
+		res[r] = make([]float64, len(row))
+		for i, v := range row {
+			res[r][i] = v - mean[i]
+		}
+	}
+	return res
+}
+
+// invSqrt returns the inverse square root of the symmetric matrix, where the eigenvalues under 1e-12 of the
+// largest are dropped.
+func invSqrt(a [][]float64) [][]float64 {
+	n := len(a)
+	vals, vecs := eigen(a)
+	var max float64
+	for _, v := range vals {
+		max = math.Max(max, v)
+	}
+	res := newSquare(n)
+	for c, v := range vals {
+		if v <= 1e-12*max {
+			continue
+		}
+		s := 1 / math.Sqrt(v)
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				res[i][j] += vecs[i][c] * s * vecs[j][c]
+			}
+		}
+	}
+	return res
+}
+
+func mul(a, b [][]float64) [][]float64 {
+	res := make([][]float64, len(a))
+	for i := range res {
+		res[i] = make([]float64, len(b[0]))
+		for k, aik := range a[i] {
+			for j, bkj := range b[k] {
+				res[i][j] += aik * bkj
+			}
+		}
+	}
+	return res
+}
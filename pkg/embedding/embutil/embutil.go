@@ -15,13 +15,10 @@
 package embutil
 
 import (
-	"math"
+	"github.com/ynqa/wego/pkg/vector"
 )
 
+// Norm returns the Euclidean norm of vec, as vector.Norm.
 func Norm(vec []float64) float64 {
-	var n float64
-	for _, v := range vec {
-		n += v * v
-	}
-	return math.Sqrt(n)
+	return vector.Norm(vec)
 }
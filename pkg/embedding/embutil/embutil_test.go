@@ -15,6 +15,7 @@
 package embutil
 
 import (
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -39,3 +40,79 @@ func TestNorm(t *testing.T) {
 		})
 	}
 }
+
+func TestPrincipal(t *testing.T) {
+	rows := [][]float64{
+		{2, 1, 0},
+		{-2, -1, 0},
+		{0.1, -0.2, 0.3},
+		{-0.1, 0.2, -0.3},
+	}
+	comps, err := Principal(rows, 2)
+	assert.NoError(t, err)
+	assert.Len(t, comps, 2)
+
+	// the first component is along (2, 1, 0) up to the sign.
+	sign := 1.
+	if comps[0][0] < 0 {
+		sign = -1
+	}
+	assert.InDelta(t, 2/math.Sqrt(5), sign*comps[0][0], 1e-6)
+	assert.InDelta(t, 1/math.Sqrt(5), sign*comps[0][1], 1e-6)
+	assert.InDelta(t, 0, comps[0][2], 1e-6)
+
+	var dot float64
+	for i := range comps[0] {
+		dot += comps[0][i] * comps[1][i]
+	}
+	assert.InDelta(t, 0, dot, 1e-9)
+	assert.InDelta(t, 1, Norm(comps[1]), 1e-9)
+
+	// the rows span only 2 dimensions.
+	_, err = Principal(rows, 3)
+	assert.Error(t, err)
+	assert.Len(t, Components([][]float64{{0, 0}, {0, 0}}), 0)
+}
+
+func TestProcrustes(t *testing.T) {
+	c, s := math.Cos(0.3), math.Sin(0.3)
+	rot := [][]float64{
+		{c, -s, 0},
+		{s, c, 0},
+		{0, 0, 1},
+	}
+	x := [][]float64{
+		{1, 0, 0},
+		{0, 2, 0},
+		{0, 0, 3},
+		{1, 1, 1},
+	}
+	y := make([][]float64, len(x))
+	for r := range x {
+		y[r] = make([]float64, 3)
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				y[r][j] += x[r][k] * rot[k][j]
+			}
+		}
+	}
+	w := Procrustes(x, y)
+	for i := range rot {
+		for j := range rot[i] {
+			assert.InDelta(t, rot[i][j], w[i][j], 1e-9)
+		}
+	}
+}
+
+func TestCCA(t *testing.T) {
+	x := [][]float64{{1, 0}, {2, 1}, {3, 0}, {4, 1}, {5, 3}}
+	// y is the linear map of x, and z is independent of x on the first direction.
+	y := make([][]float64, len(x))
+	for r, row := range x {
+		y[r] = []float64{2*row[0] - row[1], row[1]}
+	}
+	assert.InDelta(t, 1, CCA(x, y), 1e-9)
+
+	z := [][]float64{{1}, {-1}, {-1}, {1}, {0}}
+	assert.True(t, CCA([][]float64{{1}, {2}, {3}, {4}, {5}}, z) < 0.5)
+}
@@ -0,0 +1,166 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embutil
+
+import (
+	"math"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// eigenEpsilon is the ratio to the largest eigenvalue below which the eigenvalues are regarded as zero,
+// whose eigenvectors are arbitrary directions in the null space rather than components.
+const eigenEpsilon = 1e-9
+
+// Principal returns the top k principal components of the rows, which are assumed to be centered.
+// It fails if the rows span fewer than k dimensions, see Components.
+func Principal(rows [][]float64, k int) ([][]float64, error) {
+	comps := Components(rows)
+	if len(comps) < k {
+		return nil, errors.Errorf("rows span only %d dimensions, fewer than %d components", len(comps), k)
+	}
+	return comps[:k], nil
+}
+
+// Components returns the principal components of the rows, which are assumed to be centered.
+// The components are the unit eigenvectors of the scatter matrix in descending order of the eigenvalues,
+// except the ones whose eigenvalues are negligible to the largest.
+func Components(rows [][]float64) [][]float64 {
+	vals, vecs := eigen(scatter(rows))
+	idx := make([]int, len(vals))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool {
+		return vals[idx[i]] > vals[idx[j]]
+	})
+	var comps [][]float64
+	for _, c := range idx {
+		if vals[c] <= eigenEpsilon*vals[idx[0]] {
+			break
+		}
+		comp := make([]float64, len(vecs))
+		for i := range vecs {
+			comp[i] = vecs[i][c]
+		}
+		comps = append(comps, comp)
+	}
+	return comps
+}
+
+// scatter returns the upper triangle and diagonal of X^T X in parallel over the rows.
+func scatter(rows [][]float64) [][]float64 {
+	dim := len(rows[0])
+	threads := runtime.NumCPU()
+	partials := make([][][]float64, threads)
+	wg := &sync.WaitGroup{}
+	for t := 0; t < threads; t++ {
+		wg.Add(1)
+		go func(t int) {
+			defer wg.Done()
+			s := newSquare(dim)
+			for r := t; r < len(rows); r += threads {
+				row := rows[r]
+				for i := 0; i < dim; i++ {
+					ri, si := row[i], s[i]
+					for j := i; j < dim; j++ {
+						si[j] += ri * row[j]
+					}
+				}
+			}
+			partials[t] = s
+		}(t)
+	}
+	wg.Wait()
+
+	res := newSquare(dim)
+	for _, s := range partials {
+		for i := 0; i < dim; i++ {
+			for j := i; j < dim; j++ {
+				res[i][j] += s[i][j]
+			}
+		}
+	}
+	for i := 0; i < dim; i++ {
+		for j := 0; j < i; j++ {
+			res[i][j] = res[j][i]
+		}
+	}
+	return res
+}
+
+// eigen decomposes the symmetric matrix by cyclic Jacobi rotations, a is overwritten.
+// The eigenvectors are the columns of vecs.
+func eigen(a [][]float64) (vals []float64, vecs [][]float64) {
+	n := len(a)
+	vecs = newSquare(n)
+	for i := range vecs {
+		vecs[i][i] = 1
+	}
+	for sweep := 0; sweep < 100; sweep++ {
+		var off, diag float64
+		for i := 0; i < n; i++ {
+			diag += a[i][i] * a[i][i]
+			for j := i + 1; j < n; j++ {
+				off += a[i][j] * a[i][j]
+			}
+		}
+		if off <= 1e-30*diag || off == 0 {
+			break
+		}
+		for p := 0; p < n; p++ {
+			for q := p + 1; q < n; q++ {
+				if a[p][q] == 0 {
+					continue
+				}
+				theta := (a[q][q] - a[p][p]) / (2 * a[p][q])
+				t := 1 / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				if theta < 0 {
+					t = -t
+				}
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+				for k := 0; k < n; k++ {
+					akp, akq := a[k][p], a[k][q]
+					a[k][p], a[k][q] = c*akp-s*akq, s*akp+c*akq
+				}
+				for k := 0; k < n; k++ {
+					apk, aqk := a[p][k], a[q][k]
+					a[p][k], a[q][k] = c*apk-s*aqk, s*apk+c*aqk
+				}
+				for k := 0; k < n; k++ {
+					vkp, vkq := vecs[k][p], vecs[k][q]
+					vecs[k][p], vecs[k][q] = c*vkp-s*vkq, s*vkp+c*vkq
+				}
+			}
+		}
+	}
+	vals = make([]float64, n)
+	for i := range vals {
+		vals[i] = a[i][i]
+	}
+	return vals, vecs
+}
+
+func newSquare(n int) [][]float64 {
+	res := make([][]float64, n)
+	for i := range res {
+		res[i] = make([]float64, n)
+	}
+	return res
+}
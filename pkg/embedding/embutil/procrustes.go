@@ -0,0 +1,70 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embutil
+
+import (
+	"math"
+)
+
+// Procrustes returns the orthogonal matrix W which minimizes |XW - Y| over the pairs of the rows of x and y,
+// by W = M (M^T M)^(-1/2) where M = X^T Y. The directions which the rows don't span are dropped.
+func Procrustes(x, y [][]float64) [][]float64 {
+	dim := len(x[0])
+	m := newSquare(dim)
+	for r := range x {
+		for i, xi := range x[r] {
+			mi := m[i]
+			for j, yj := range y[r] {
+				mi[j] += xi * yj
+			}
+		}
+	}
+
+	mtm := newSquare(dim)
+	for i := 0; i < dim; i++ {
+		for j := 0; j < dim; j++ {
+			for k := 0; k < dim; k++ {
+				mtm[i][j] += m[k][i] * m[k][j]
+			}
+		}
+	}
+	vals, vecs := eigen(mtm)
+	var max float64
+	for _, v := range vals {
+		max = math.Max(max, v)
+	}
+	invSqrt := newSquare(dim)
+	for c, v := range vals {
+		if v <= 1e-12*max {
+			continue
+		}
+		s := 1 / math.Sqrt(v)
+		for i := 0; i < dim; i++ {
+			for j := 0; j < dim; j++ {
+				invSqrt[i][j] += vecs[i][c] * s * vecs[j][c]
+			}
+		}
+	}
+
+	w := newSquare(dim)
+	for i := 0; i < dim; i++ {
+		for j := 0; j < dim; j++ {
+			for k := 0; k < dim; k++ {
+				w[i][j] += m[i][k] * invSqrt[k][j]
+			}
+		}
+	}
+	return w
+}
@@ -0,0 +1,100 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package faiss writes the flat index of FAISS (https://github.com/facebookresearch/faiss),
+// which is loadable by faiss.read_index(path). FAISS identifies the vectors by the integers,
+// so the words are kept in the adjacent vocabulary.
+package faiss
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/embedding"
+)
+
+// Metric is the metric of the flat index.
+type Metric = string
+
+const (
+	// IP is IndexFlatIP over the raw vectors.
+	IP Metric = "ip"
+	// L2 is IndexFlatL2 over the raw vectors.
+	L2 Metric = "l2"
+	// Cosine is IndexFlatIP over the unit vectors, as FAISS searches by cosine similarity.
+	Cosine Metric = "cosine"
+)
+
+// metric_type of faiss::MetricType.
+const (
+	metricInnerProduct = 0
+	metricL2           = 1
+)
+
+// Write writes embs as IndexFlatIP or IndexFlatL2 by write_index of index_write.cpp,
+// whose id i is embs[i].
+func Write(w io.Writer, embs embedding.Embeddings, metric Metric) error {
+	if embs.Empty() {
+		return errors.New("embeddings are empty")
+	}
+	if err := embs.Validate(); err != nil {
+		return err
+	}
+	var (
+		fourcc     string
+		metricType uint32
+	)
+	switch metric {
+	case IP, Cosine:
+		fourcc, metricType = "IxFI", metricInnerProduct
+	case L2:
+		fourcc, metricType = "IxF2", metricL2
+	default:
+		return errors.Errorf("invalid metric: %s not in %s|%s|%s", metric, IP, L2, Cosine)
+	}
+	dim := embs[0].Dim
+
+	buf := bufio.NewWriter(w)
+	le := func(v interface{}) {
+		binary.Write(buf, binary.LittleEndian, v)
+	}
+	buf.WriteString(fourcc)
+	// write_index_header: d, ntotal, two dummies, is_trained and metric_type.
+	le(int32(dim))
+	le(int64(len(embs)))
+	le(int64(1 << 20))
+	le(int64(1 << 20))
+	buf.WriteByte(1)
+	le(metricType)
+	// the codes of the vectors with the number of floats.
+	le(uint64(len(embs) * dim))
+	b := make([]byte, 4)
+	for _, emb := range embs {
+		scale := 1.
+		if metric == Cosine && emb.Norm > 0 {
+			scale = 1 / emb.Norm
+		}
+		for _, v := range emb.Vector {
+			binary.LittleEndian.PutUint32(b, math.Float32bits(float32(v*scale)))
+			if _, err := buf.Write(b); err != nil {
+				return err
+			}
+		}
+	}
+	return buf.Flush()
+}
@@ -0,0 +1,63 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package faiss
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/embedding"
+)
+
+func TestWrite(t *testing.T) {
+	embs := embedding.Embeddings{
+		{Word: "a", Dim: 2, Vector: []float64{3, 4}, Norm: 5},
+		{Word: "b", Dim: 2, Vector: []float64{1, 0}, Norm: 1},
+	}
+	floats := func(b []byte) []float32 {
+		res := make([]float32, len(b)/4)
+		for i := range res {
+			res[i] = math.Float32frombits(binary.LittleEndian.Uint32(b[4*i:]))
+		}
+		return res
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, Write(&buf, embs, L2))
+	b := buf.Bytes()
+	assert.Equal(t, "IxF2", string(b[:4]))
+	assert.Equal(t, uint32(2), binary.LittleEndian.Uint32(b[4:]))
+	assert.Equal(t, uint64(2), binary.LittleEndian.Uint64(b[8:]))
+	assert.Equal(t, uint64(1<<20), binary.LittleEndian.Uint64(b[16:]))
+	assert.Equal(t, uint64(1<<20), binary.LittleEndian.Uint64(b[24:]))
+	assert.Equal(t, byte(1), b[32])
+	assert.Equal(t, uint32(metricL2), binary.LittleEndian.Uint32(b[33:]))
+	assert.Equal(t, uint64(4), binary.LittleEndian.Uint64(b[37:]))
+	assert.Equal(t, []float32{3, 4, 1, 0}, floats(b[45:]))
+
+	buf.Reset()
+	assert.NoError(t, Write(&buf, embs, Cosine))
+	b = buf.Bytes()
+	assert.Equal(t, "IxFI", string(b[:4]))
+	assert.Equal(t, uint32(metricInnerProduct), binary.LittleEndian.Uint32(b[33:]))
+	assert.Equal(t, []float32{0.6, 0.8, 1, 0}, floats(b[45:]))
+
+	assert.Error(t, Write(&buf, embs, "hnsw"))
+	assert.Error(t, Write(&buf, nil, IP))
+}
@@ -0,0 +1,200 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package finetune
+
+import (
+	"bufio"
+	"io"
+	"math/rand"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/embutil"
+	"github.com/ynqa/wego/pkg/util/fileutil"
+	"github.com/ynqa/wego/pkg/vector"
+)
+
+type Label = string
+
+const (
+	Similar    Label = "similar"
+	Dissimilar Label = "dissimilar"
+)
+
+// Pair is a pair of words labeled as similar or dissimilar in the target domain.
+type Pair struct {
+	Word1, Word2 string
+	Label        Label
+}
+
+// LoadPairs reads the lines of `word1 word2 label`. Empty lines and lines starting with # are skipped.
+func LoadPairs(r io.Reader) ([]Pair, error) {
+	var pairs []Pair
+	s := fileutil.NewScanner(r, bufio.ScanLines)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return nil, errors.Errorf("line %d must be `word1 word2 label`, got %q", n, line)
+		}
+		if fields[2] != Similar && fields[2] != Dissimilar {
+			return nil, errors.Errorf("label of line %d must be %s|%s, got %q", n, Similar, Dissimilar, fields[2])
+		}
+		pairs = append(pairs, Pair{Word1: fields[0], Word2: fields[1], Label: fields[2]})
+	}
+	if err := s.Err(); err != nil && err != io.EOF {
+		return nil, errors.Wrapf(err, "failed to scan")
+	}
+	return pairs, nil
+}
+
+var (
+	defaultInitlr = 0.05
+	defaultIter   = 20
+	defaultMargin = 0.0
+	defaultReg    = 0.1
+	defaultSeed   = int64(1)
+)
+
+type Options struct {
+	// Freeze is the words whose vectors are kept unchanged, e.g. the anchor words aligned with another space.
+	Freeze []string
+	Initlr float64
+	Iter   int
+	Margin float64
+	Reg    float64
+	Seed   int64
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Initlr: defaultInitlr,
+		Iter:   defaultIter,
+		Margin: defaultMargin,
+		Reg:    defaultReg,
+		Seed:   defaultSeed,
+	}
+}
+
+// Finetune adapts the embeddings to the domain by SGD on the contrastive loss over the labeled pairs,
+// 1-cos(u, v) for the similar pairs and max(0, cos(u, v)-margin) for the dissimilar ones,
+// with the penalty reg*|u-u0|^2 which keeps the vectors close to the original ones.
+// The pairs whose words are not found are skipped, and embs are not modified.
+func Finetune(embs embedding.Embeddings, pairs []Pair, opts Options) (embedding.Embeddings, error) {
+	if embs.Empty() {
+		return nil, errors.New("embeddings are empty")
+	}
+	if err := embs.Validate(); err != nil {
+		return nil, err
+	}
+	if opts.Iter < 1 || opts.Initlr <= 0 || opts.Reg < 0 {
+		return nil, errors.Errorf("iter must be >= 1, initlr > 0, and reg >= 0, got %d, %v, %v", opts.Iter, opts.Initlr, opts.Reg)
+	}
+	dim := embs[0].Dim
+
+	res := make(embedding.Embeddings, len(embs))
+	index := make(map[string]int, len(embs))
+	for i, emb := range embs {
+		vec := make([]float64, dim)
+		copy(vec, emb.Vector)
+		res[i] = embedding.Embedding{
+			Word:   emb.Word,
+			Dim:    dim,
+			Vector: vec,
+		}
+		index[emb.Word] = i
+	}
+
+	frozen := make([]bool, len(embs))
+	for _, word := range opts.Freeze {
+		if i, ok := index[word]; ok {
+			frozen[i] = true
+		}
+	}
+
+	type item struct {
+		i1, i2  int
+		similar bool
+	}
+	var items []item
+	for _, p := range pairs {
+		i1, ok1 := index[p.Word1]
+		i2, ok2 := index[p.Word2]
+		if !ok1 || !ok2 || i1 == i2 || frozen[i1] && frozen[i2] {
+			continue
+		}
+		items = append(items, item{i1: i1, i2: i2, similar: p.Label == Similar})
+	}
+	if len(items) == 0 {
+		return nil, errors.New("no pairs are found in embeddings")
+	}
+
+	rng := rand.New(rand.NewSource(opts.Seed))
+	g1, g2 := make([]float64, dim), make([]float64, dim)
+	for iter := 0; iter < opts.Iter; iter++ {
+		rng.Shuffle(len(items), func(i, j int) {
+			items[i], items[j] = items[j], items[i]
+		})
+		for _, it := range items {
+			u, v := res[it.i1].Vector, res[it.i2].Vector
+			nu, nv := embutil.Norm(u), embutil.Norm(v)
+			if nu == 0 || nv == 0 {
+				continue
+			}
+			cos := vector.Dot(u, v) / (nu * nv)
+			// the similar pairs ascend the cosine, and the dissimilar ones descend it over the margin.
+			sign := 1.0
+			if !it.similar {
+				if cos <= opts.Margin {
+					continue
+				}
+				sign = -1
+			}
+			for i := 0; i < dim; i++ {
+				g1[i] = v[i]/(nu*nv) - cos*u[i]/(nu*nu)
+				g2[i] = u[i]/(nu*nv) - cos*v[i]/(nv*nv)
+			}
+			if !frozen[it.i1] {
+				for i := 0; i < dim; i++ {
+					u[i] += opts.Initlr * sign * g1[i]
+				}
+				regularize(u, embs[it.i1].Vector, opts.Initlr*opts.Reg)
+			}
+			if !frozen[it.i2] {
+				for i := 0; i < dim; i++ {
+					v[i] += opts.Initlr * sign * g2[i]
+				}
+				regularize(v, embs[it.i2].Vector, opts.Initlr*opts.Reg)
+			}
+		}
+	}
+
+	for i := range res {
+		res[i].Norm = embutil.Norm(res[i].Vector)
+	}
+	return res, nil
+}
+
+// regularize moves vec toward orig by the gradient of |vec-orig|^2.
+func regularize(vec, orig []float64, rate float64) {
+	for i := range vec {
+		vec[i] -= 2 * rate * (vec[i] - orig[i])
+	}
+}
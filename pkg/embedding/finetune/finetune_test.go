@@ -0,0 +1,84 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package finetune
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/embutil"
+	"github.com/ynqa/wego/pkg/search/searchutil"
+)
+
+func TestLoadPairs(t *testing.T) {
+	pairs, err := LoadPairs(strings.NewReader("# comment\ncar auto similar\n\ncar banana dissimilar\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []Pair{{"car", "auto", Similar}, {"car", "banana", Dissimilar}}, pairs)
+
+	_, err = LoadPairs(strings.NewReader("car auto\n"))
+	assert.Error(t, err)
+	_, err = LoadPairs(strings.NewReader("car auto same\n"))
+	assert.Error(t, err)
+}
+
+func TestFinetune(t *testing.T) {
+	vecs := map[string][]float64{
+		"car":    {1, 0, 0},
+		"auto":   {0, 1, 0},
+		"banana": {0.9, 0.1, 0.1},
+		"apple":  {0, 0, 1},
+	}
+	var embs embedding.Embeddings
+	for _, w := range []string{"car", "auto", "banana", "apple"} {
+		embs = append(embs, embedding.Embedding{
+			Word:   w,
+			Dim:    3,
+			Vector: vecs[w],
+			Norm:   embutil.Norm(vecs[w]),
+		})
+	}
+	cos := func(embs embedding.Embeddings, w1, w2 string) float64 {
+		e1, _ := embs.Find(w1)
+		e2, _ := embs.Find(w2)
+		return searchutil.Cosine(e1.Vector, e2.Vector, e1.Norm, e2.Norm)
+	}
+
+	pairs := []Pair{
+		{"car", "auto", Similar},
+		{"car", "banana", Dissimilar},
+		{"car", "unknown", Similar},
+	}
+	res, err := Finetune(embs, pairs, DefaultOptions())
+	assert.NoError(t, err)
+	assert.Greater(t, cos(res, "car", "auto"), cos(embs, "car", "auto")+0.3)
+	assert.Less(t, cos(res, "car", "banana"), cos(embs, "car", "banana")-0.3)
+	// the words out of the pairs are kept.
+	assert.Equal(t, []float64{0, 0, 1}, res[3].Vector)
+	assert.Equal(t, []float64{1, 0, 0}, embs[0].Vector)
+
+	// the frozen words are kept, and the others move toward them.
+	opts := DefaultOptions()
+	opts.Freeze = []string{"car"}
+	res, err = Finetune(embs, pairs, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{1, 0, 0}, res[0].Vector)
+	assert.Greater(t, cos(res, "car", "auto"), cos(embs, "car", "auto")+0.3)
+
+	_, err = Finetune(embs, []Pair{{"car", "unknown", Similar}}, DefaultOptions())
+	assert.Error(t, err)
+}
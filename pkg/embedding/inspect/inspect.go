@@ -0,0 +1,257 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inspect
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/embutil"
+	"github.com/ynqa/wego/pkg/search/searchutil"
+)
+
+var (
+	defaultDuplicate = 0.999
+	defaultK         = 10
+	defaultSample    = 2000
+	defaultSeed      = int64(1)
+)
+
+type Options struct {
+	Duplicate float64
+	K         int
+	Sample    int
+	Seed      int64
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Duplicate: defaultDuplicate,
+		K:         defaultK,
+		Sample:    defaultSample,
+		Seed:      defaultSeed,
+	}
+}
+
+// Report is the health of the vector space.
+// Duplicates is the fraction of the words whose nearest neighbor is near-duplicate.
+// Isotropy is I(V) by Mu and Viswanath (2018), 1 for the isotropic space.
+// Hubness is the skewness of the k-occurrence by Radovanović et al. (2010), the larger value means
+// the more words appear as the nearest neighbors of many others.
+// Duplicates, Isotropy, and Hubness are estimated on the Sampled words.
+type Report struct {
+	Dim        int
+	Vocab      int
+	NormMin    float64
+	NormMean   float64
+	NormMedian float64
+	NormMax    float64
+	NormStd    float64
+	Zeros      int
+	Sampled    int
+	Duplicates float64
+	Isotropy   float64
+	Hubness    float64
+}
+
+func (r Report) Describe() {
+	table := [][]string{
+		{"dim", fmt.Sprintf("%d", r.Dim)},
+		{"vocab", fmt.Sprintf("%d", r.Vocab)},
+		{"norm min", fmt.Sprintf("%f", r.NormMin)},
+		{"norm mean", fmt.Sprintf("%f", r.NormMean)},
+		{"norm median", fmt.Sprintf("%f", r.NormMedian)},
+		{"norm max", fmt.Sprintf("%f", r.NormMax)},
+		{"norm std", fmt.Sprintf("%f", r.NormStd)},
+		{"zero vectors", fmt.Sprintf("%d", r.Zeros)},
+		{"sampled", fmt.Sprintf("%d", r.Sampled)},
+		{"near-duplicates", fmt.Sprintf("%f", r.Duplicates)},
+		{"isotropy", fmt.Sprintf("%f", r.Isotropy)},
+		{"hubness", fmt.Sprintf("%f", r.Hubness)},
+	}
+
+	writer := tablewriter.NewWriter(os.Stdout)
+	writer.SetHeader([]string{"Metric", "Value"})
+	writer.SetBorder(false)
+	writer.AppendBulk(table)
+	writer.Render()
+}
+
+func Inspect(embs embedding.Embeddings, opts Options) (Report, error) {
+	if embs.Empty() {
+		return Report{}, errors.New("embeddings are empty")
+	}
+	if err := embs.Validate(); err != nil {
+		return Report{}, err
+	}
+	if opts.K <= 0 {
+		return Report{}, errors.Errorf("k must be positive, got %d", opts.K)
+	}
+
+	report := Report{
+		Dim:   embs[0].Dim,
+		Vocab: len(embs),
+	}
+
+	norms := make([]float64, len(embs))
+	for i, emb := range embs {
+		norms[i] = emb.Norm
+		if emb.Norm == 0 {
+			report.Zeros++
+		}
+	}
+	sort.Float64s(norms)
+	report.NormMin, report.NormMax = norms[0], norms[len(norms)-1]
+	report.NormMean, report.NormStd = meanStd(norms)
+	if n := len(norms); n%2 == 0 {
+		report.NormMedian = (norms[n/2-1] + norms[n/2]) / 2
+	} else {
+		report.NormMedian = norms[n/2]
+	}
+
+	sample := embs
+	if opts.Sample > 0 && opts.Sample < len(embs) {
+		rng := rand.New(rand.NewSource(opts.Seed))
+		sample = make(embedding.Embeddings, opts.Sample)
+		for i, j := range rng.Perm(len(embs))[:opts.Sample] {
+			sample[i] = embs[j]
+		}
+	}
+	report.Sampled = len(sample)
+	report.Isotropy = isotropy(sample)
+	report.Duplicates, report.Hubness = neighborhood(sample, opts.K, opts.Duplicate)
+	return report, nil
+}
+
+// isotropy is min_c Z(c) / max_c Z(c) where Z(c) = sum_w exp(c^T v_w) and c are the eigenvectors of V^T V,
+// except the ones of the null space, see embutil.Components.
+func isotropy(embs embedding.Embeddings) float64 {
+	rows := make([][]float64, len(embs))
+	for i, emb := range embs {
+		rows[i] = emb.Vector
+	}
+	comps := embutil.Components(rows)
+	minZ, maxZ := math.Inf(1), math.Inf(-1)
+	dots := make([]float64, len(rows))
+	for _, c := range comps {
+		// log Z(c) by log-sum-exp to avoid the overflow.
+		max := math.Inf(-1)
+		for i, row := range rows {
+			dots[i] = 0
+			for j, v := range row {
+				dots[i] += c[j] * v
+			}
+			max = math.Max(max, dots[i])
+		}
+		var sum float64
+		for _, d := range dots {
+			sum += math.Exp(d - max)
+		}
+		logZ := max + math.Log(sum)
+		minZ, maxZ = math.Min(minZ, logZ), math.Max(maxZ, logZ)
+	}
+	return math.Exp(minZ - maxZ)
+}
+
+// neighborhood returns the fraction of near-duplicates and the hubness by the k nearest neighbors.
+func neighborhood(embs embedding.Embeddings, k int, threshold float64) (float64, float64) {
+	if k > len(embs)-1 {
+		k = len(embs) - 1
+	}
+	if k <= 0 {
+		return 0, 0
+	}
+	knn := make([][]int, len(embs))
+	nearest := make([]float64, len(embs))
+	threads := runtime.NumCPU()
+	wg := &sync.WaitGroup{}
+	for t := 0; t < threads; t++ {
+		wg.Add(1)
+		go func(t int) {
+			defer wg.Done()
+			ids, sims := make([]int, k), make([]float64, k)
+			for i := t; i < len(embs); i += threads {
+				for n := range ids {
+					ids[n], sims[n] = -1, math.Inf(-1)
+				}
+				for j := range embs {
+					if i == j {
+						continue
+					}
+					sim := searchutil.Cosine(embs[i].Vector, embs[j].Vector, embs[i].Norm, embs[j].Norm)
+					if sim <= sims[k-1] {
+						continue
+					}
+					// insert into the sorted top k.
+					n := k - 1
+					for ; n > 0 && sims[n-1] < sim; n-- {
+						ids[n], sims[n] = ids[n-1], sims[n-1]
+					}
+					ids[n], sims[n] = j, sim
+				}
+				knn[i] = append([]int(nil), ids...)
+				nearest[i] = sims[0]
+			}
+		}(t)
+	}
+	wg.Wait()
+
+	var dups int
+	occurrence := make([]float64, len(embs))
+	for i := range embs {
+		if nearest[i] >= threshold {
+			dups++
+		}
+		for _, j := range knn[i] {
+			occurrence[j]++
+		}
+	}
+	return float64(dups) / float64(len(embs)), skewness(occurrence)
+}
+
+func meanStd(v []float64) (float64, float64) {
+	var mean float64
+	for _, a := range v {
+		mean += a
+	}
+	mean /= float64(len(v))
+	var variance float64
+	for _, a := range v {
+		variance += (a - mean) * (a - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(v)))
+}
+
+func skewness(v []float64) float64 {
+	mean, std := meanStd(v)
+	if std == 0 {
+		return 0
+	}
+	var sum float64
+	for _, a := range v {
+		d := (a - mean) / std
+		sum += d * d * d
+	}
+	return sum / float64(len(v))
+}
@@ -0,0 +1,91 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inspect
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/embutil"
+)
+
+func newEmbeddings(vecs [][]float64) embedding.Embeddings {
+	embs := make(embedding.Embeddings, len(vecs))
+	for i, vec := range vecs {
+		embs[i] = embedding.Embedding{
+			Word:   fmt.Sprintf("w%d", i),
+			Dim:    len(vec),
+			Vector: vec,
+			Norm:   embutil.Norm(vec),
+		}
+	}
+	return embs
+}
+
+func TestInspect(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	var isotropic, anisotropic [][]float64
+	for i := 0; i < 500; i++ {
+		vec := make([]float64, 5)
+		shifted := make([]float64, 5)
+		for j := range vec {
+			vec[j] = rng.NormFloat64()
+			shifted[j] = vec[j] + 5
+		}
+		isotropic = append(isotropic, vec)
+		anisotropic = append(anisotropic, shifted)
+	}
+
+	report, err := Inspect(newEmbeddings(isotropic), DefaultOptions())
+	assert.NoError(t, err)
+	assert.Equal(t, 5, report.Dim)
+	assert.Equal(t, 500, report.Vocab)
+	assert.Equal(t, 500, report.Sampled)
+	assert.True(t, report.NormMin <= report.NormMedian && report.NormMedian <= report.NormMax)
+	assert.Equal(t, 0., report.Duplicates)
+	iso := report.Isotropy
+
+	report, err = Inspect(newEmbeddings(anisotropic), DefaultOptions())
+	assert.NoError(t, err)
+	assert.True(t, report.Isotropy < iso)
+
+	opts := DefaultOptions()
+	opts.Sample = 100
+	report, err = Inspect(newEmbeddings(append(isotropic, isotropic[0], make([]float64, 5))), opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 502, report.Vocab)
+	assert.Equal(t, 100, report.Sampled)
+	assert.Equal(t, 1, report.Zeros)
+}
+
+func TestNeighborhood(t *testing.T) {
+	var vecs [][]float64
+	// the middle one at 45 degrees is the hub among the vectors on the arc.
+	for _, deg := range []float64{10, 30, 45, 60, 80} {
+		rad := deg * math.Pi / 180
+		vecs = append(vecs, []float64{math.Cos(rad), math.Sin(rad)})
+	}
+	dups, hubness := neighborhood(newEmbeddings(vecs), 2, 0.999)
+	assert.Equal(t, 0., dups)
+	assert.True(t, hubness > 0)
+
+	dups, _ = neighborhood(newEmbeddings(append(vecs, []float64{1, 1.0001})), 2, 0.999)
+	assert.InDelta(t, 2./6, dups, 1e-9)
+}
@@ -0,0 +1,185 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package onnx writes the embeddings as the ONNX model of the embedding layer,
+// which maps the indices of words to their vectors by Gather.
+package onnx
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/embedding"
+)
+
+const (
+	irVersion = 7
+	opset     = 13
+
+	// TensorProto.DataType
+	typeFloat = 1
+	typeInt64 = 7
+
+	// Input is the name of the input which are the indices of words, int64 of shape [N].
+	Input = "indices"
+	// Output is the name of the output which are the vectors, float of shape [N, dim].
+	Output = "embeddings"
+	// Weight is the name of the initializer which is the matrix of the vectors, float of shape [vocab, dim].
+	Weight = "weight"
+
+	// maxSize is the limit of protobuf, the larger table requires the external data.
+	maxSize = math.MaxInt32
+)
+
+// message is the protobuf encoding of the fields, the subset used by ONNX.
+type message []byte
+
+func (m *message) varint(v uint64) {
+	*m = append(*m, varint(v)...)
+}
+
+func (m *message) tag(field, wire int) {
+	m.varint(uint64(field<<3 | wire))
+}
+
+func (m *message) int64(field int, v int64) {
+	m.tag(field, 0)
+	m.varint(uint64(v))
+}
+
+func (m *message) bytes(field int, v []byte) {
+	m.tag(field, 2)
+	m.varint(uint64(len(v)))
+	*m = append(*m, v...)
+}
+
+func (m *message) string(field int, v string) {
+	m.bytes(field, []byte(v))
+}
+
+func varint(v uint64) []byte {
+	buf := make([]byte, binary.MaxVarintLen64)
+	return buf[:binary.PutUvarint(buf, v)]
+}
+
+// valueInfo is ValueInfoProto of the tensor whose dimensions are fixed if > 0, or named by "N" otherwise.
+func valueInfo(name string, elemType int, dims ...int) message {
+	var shape message
+	for _, d := range dims {
+		var dim message
+		if d > 0 {
+			dim.int64(1, int64(d))
+		} else {
+			dim.string(2, "N")
+		}
+		shape.bytes(1, dim)
+	}
+	var tensor message
+	tensor.int64(1, int64(elemType))
+	tensor.bytes(2, shape)
+	var typ message
+	typ.bytes(1, tensor)
+	var info message
+	info.string(1, name)
+	info.bytes(2, typ)
+	return info
+}
+
+// Write writes the embeddings as the ONNX model. The row of each vector is the index of the word in embs,
+// so the vocabulary should be saved with the model, e.g. by WriteVocab.
+// The raw data of the weight is streamed into w instead of being held in memory.
+func Write(w io.Writer, embs embedding.Embeddings) error {
+	if embs.Empty() {
+		return errors.New("embeddings are empty")
+	}
+	if err := embs.Validate(); err != nil {
+		return err
+	}
+	vocab, dim := len(embs), embs[0].Dim
+	rawSize := int64(vocab) * int64(dim) * 4
+	if rawSize > maxSize {
+		return errors.Errorf("the weight of %d bytes exceeds the limit of ONNX %d bytes", rawSize, maxSize)
+	}
+
+	// the fields of the large messages are written at the last to stream the raw data:
+	// ModelProto.graph > GraphProto.initializer > TensorProto.raw_data.
+	var tensor message
+	tensor.int64(1, int64(vocab))
+	tensor.int64(1, int64(dim))
+	tensor.int64(2, typeFloat)
+	tensor.string(8, Weight)
+	tensor.tag(9, 2)
+	tensor.varint(uint64(rawSize))
+	tensorSize := int64(len(tensor)) + rawSize
+
+	var node message
+	node.string(1, Weight)
+	node.string(1, Input)
+	node.string(2, Output)
+	node.string(3, "embedding")
+	node.string(4, "Gather")
+
+	var graph message
+	graph.bytes(1, node)
+	graph.string(2, "wego")
+	graph.bytes(11, valueInfo(Input, typeInt64, 0))
+	graph.bytes(12, valueInfo(Output, typeFloat, 0, dim))
+	graph.tag(5, 2)
+	graph.varint(uint64(tensorSize))
+	graphSize := int64(len(graph)) + tensorSize
+
+	var opsetID message
+	opsetID.string(1, "")
+	opsetID.int64(2, opset)
+
+	var model message
+	model.int64(1, irVersion)
+	model.string(2, "wego")
+	model.bytes(8, opsetID)
+	model.tag(7, 2)
+	model.varint(uint64(graphSize))
+
+	buf := bufio.NewWriter(w)
+	for _, m := range []message{model, graph, tensor} {
+		if _, err := buf.Write(m); err != nil {
+			return err
+		}
+	}
+	b := make([]byte, 4)
+	for _, emb := range embs {
+		for _, v := range emb.Vector {
+			binary.LittleEndian.PutUint32(b, math.Float32bits(float32(v)))
+			if _, err := buf.Write(b); err != nil {
+				return err
+			}
+		}
+	}
+	return buf.Flush()
+}
+
+// WriteVocab writes the words line by line in the order of the rows.
+func WriteVocab(w io.Writer, embs embedding.Embeddings) error {
+	buf := bufio.NewWriter(w)
+	for _, emb := range embs {
+		buf.WriteString(emb.Word)
+		if err := buf.WriteByte('\n'); err != nil {
+			return err
+		}
+	}
+	return buf.Flush()
+}
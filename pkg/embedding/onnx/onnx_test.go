@@ -0,0 +1,86 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package onnx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/embedding"
+)
+
+// decode parses the protobuf message into the values by the fields, which are uint64 or []byte.
+func decode(t *testing.T, b []byte) map[int][]interface{} {
+	res := make(map[int][]interface{})
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		assert.True(t, n > 0)
+		b = b[n:]
+		field := int(key >> 3)
+		switch key & 7 {
+		case 0:
+			v, n := binary.Uvarint(b)
+			b = b[n:]
+			res[field] = append(res[field], v)
+		case 2:
+			l, n := binary.Uvarint(b)
+			b = b[n:]
+			res[field] = append(res[field], b[:l])
+			b = b[l:]
+		default:
+			t.Fatalf("unexpected wire type: %d", key&7)
+		}
+	}
+	return res
+}
+
+func TestWrite(t *testing.T) {
+	embs := embedding.Embeddings{
+		{Word: "a", Dim: 2, Vector: []float64{1, 2}},
+		{Word: "b", Dim: 2, Vector: []float64{-0.5, 3}},
+		{Word: "c", Dim: 2, Vector: []float64{0, 1}},
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, Write(&buf, embs))
+
+	model := decode(t, buf.Bytes())
+	assert.Equal(t, []interface{}{uint64(irVersion)}, model[1])
+	opsetID := decode(t, model[8][0].([]byte))
+	assert.Equal(t, []interface{}{uint64(opset)}, opsetID[2])
+
+	graph := decode(t, model[7][0].([]byte))
+	node := decode(t, graph[1][0].([]byte))
+	assert.Equal(t, []interface{}{[]byte(Weight), []byte(Input)}, node[1])
+	assert.Equal(t, []interface{}{[]byte(Output)}, node[2])
+	assert.Equal(t, []interface{}{[]byte("Gather")}, node[4])
+
+	tensor := decode(t, graph[5][0].([]byte))
+	assert.Equal(t, []interface{}{uint64(3), uint64(2)}, tensor[1])
+	assert.Equal(t, []interface{}{uint64(typeFloat)}, tensor[2])
+	raw := tensor[9][0].([]byte)
+	var got []float64
+	for i := 0; i < len(raw); i += 4 {
+		got = append(got, float64(math.Float32frombits(binary.LittleEndian.Uint32(raw[i:]))))
+	}
+	assert.Equal(t, []float64{1, 2, -0.5, 3, 0, 1}, got)
+
+	buf.Reset()
+	assert.NoError(t, WriteVocab(&buf, embs))
+	assert.Equal(t, "a\nb\nc\n", buf.String())
+}
@@ -0,0 +1,237 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package redis pushes the embeddings into Redis as the hashes of the word and the vector,
+// which RediSearch can index as the VECTOR field, and pulls them back.
+package redis
+
+import (
+	"encoding/binary"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/embutil"
+)
+
+const (
+	// Word is the field of the hash for the word.
+	Word = "word"
+	// Vector is the field of the hash for the vector, the blob of float32 in little endian.
+	Vector = "vector"
+)
+
+var (
+	defaultAddr    = "localhost:6379"
+	defaultBatch   = 1000
+	defaultDB      = 0
+	defaultIndex   = ""
+	defaultPrefix  = "wego:"
+	defaultTimeout = 10 * time.Second
+)
+
+type Options struct {
+	Addr  string
+	Batch int
+	DB    int
+	// Index is the name of the RediSearch index created over the hashes, no index is created if empty.
+	Index    string
+	Password string
+	// Prefix is prepended to the words for the keys of the hashes.
+	Prefix  string
+	Timeout time.Duration
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Addr:    defaultAddr,
+		Batch:   defaultBatch,
+		DB:      defaultDB,
+		Index:   defaultIndex,
+		Prefix:  defaultPrefix,
+		Timeout: defaultTimeout,
+	}
+}
+
+// Push writes the embeddings as the hashes of Word and Vector at the keys of the prefixed words,
+// after creating the index with the cosine distance if Index is set.
+func Push(embs embedding.Embeddings, opts Options) error {
+	if embs.Empty() {
+		return errors.New("embeddings are empty")
+	}
+	if err := embs.Validate(); err != nil {
+		return err
+	}
+	if opts.Batch < 1 {
+		return errors.Errorf("batch must be >= 1, got %d", opts.Batch)
+	}
+	c, err := dial(opts)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if opts.Index != "" {
+		if _, err := c.do(
+			"FT.CREATE", opts.Index, "ON", "HASH", "PREFIX", "1", opts.Prefix,
+			"SCHEMA", Word, "TAG", Vector, "VECTOR", "FLAT", "6",
+			"TYPE", "FLOAT32", "DIM", strconv.Itoa(embs[0].Dim), "DISTANCE_METRIC", "COSINE",
+		); err != nil {
+			return errors.Wrapf(err, "failed to create index %s", opts.Index)
+		}
+	}
+
+	for s := 0; s < len(embs); s += opts.Batch {
+		e := s + opts.Batch
+		if e > len(embs) {
+			e = len(embs)
+		}
+		for _, emb := range embs[s:e] {
+			c.send("HSET", opts.Prefix+emb.Word, Word, emb.Word, Vector, encode(emb.Vector))
+		}
+		if err := c.flush(); err != nil {
+			return err
+		}
+		for _, emb := range embs[s:e] {
+			if _, err := c.receive(); err != nil {
+				return errors.Wrapf(err, "failed to push %s", emb.Word)
+			}
+		}
+	}
+	return nil
+}
+
+// Pull reads the hashes whose keys have the prefix, in the order of the words.
+func Pull(opts Options) (embedding.Embeddings, error) {
+	if opts.Batch < 1 {
+		return nil, errors.Errorf("batch must be >= 1, got %d", opts.Batch)
+	}
+	c, err := dial(opts)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	var embs embedding.Embeddings
+	// SCAN may return the same key more than once.
+	seen := make(map[string]struct{})
+	cursor := "0"
+	for {
+		reply, err := c.do("SCAN", cursor, "MATCH", escape(opts.Prefix)+"*", "COUNT", strconv.Itoa(opts.Batch))
+		if err != nil {
+			return nil, err
+		}
+		res, ok := reply.([]interface{})
+		if !ok || len(res) != 2 {
+			return nil, errors.Errorf("invalid reply of SCAN: %v", reply)
+		}
+		next, ok := res[0].([]byte)
+		if !ok {
+			return nil, errors.Errorf("invalid cursor of SCAN: %v", res[0])
+		}
+		replies, ok := res[1].([]interface{})
+		if !ok {
+			return nil, errors.Errorf("invalid keys of SCAN: %v", res[1])
+		}
+		var keys []string
+		for _, reply := range replies {
+			key, ok := reply.([]byte)
+			if !ok {
+				return nil, errors.Errorf("invalid key of SCAN: %v", reply)
+			}
+			if _, ok := seen[string(key)]; ok {
+				continue
+			}
+			seen[string(key)] = struct{}{}
+			keys = append(keys, string(key))
+		}
+		for _, key := range keys {
+			c.send("HMGET", key, Word, Vector)
+		}
+		if err := c.flush(); err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			reply, err := c.receive()
+			if err != nil {
+				return nil, err
+			}
+			fields, _ := reply.([]interface{})
+			if len(fields) != 2 {
+				return nil, errors.Errorf("%s has no fields of %s and %s", key, Word, Vector)
+			}
+			word, ok := fields[0].([]byte)
+			if !ok {
+				return nil, errors.Errorf("%s has no field of %s", key, Word)
+			}
+			vec, ok := fields[1].([]byte)
+			if !ok {
+				return nil, errors.Errorf("%s has no field of %s", key, Vector)
+			}
+			emb, err := decode(string(word), vec)
+			if err != nil {
+				return nil, err
+			}
+			embs = append(embs, emb)
+		}
+		if cursor = string(next); cursor == "0" || cursor == "" {
+			break
+		}
+	}
+	sort.Slice(embs, func(i, j int) bool {
+		return embs[i].Word < embs[j].Word
+	})
+	return embs, nil
+}
+
+// escape quotes the special characters of the glob-style pattern.
+func escape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`*?[]\`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func encode(vec []float64) string {
+	b := make([]byte, 4*len(vec))
+	for i, v := range vec {
+		binary.LittleEndian.PutUint32(b[4*i:], math.Float32bits(float32(v)))
+	}
+	return string(b)
+}
+
+func decode(word string, b []byte) (embedding.Embedding, error) {
+	if len(b)%4 != 0 {
+		return embedding.Embedding{}, errors.Errorf("invalid vector of %s: %d bytes", word, len(b))
+	}
+	vec := make([]float64, len(b)/4)
+	for i := range vec {
+		vec[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(b[4*i:])))
+	}
+	return embedding.Embedding{
+		Word:   word,
+		Dim:    len(vec),
+		Vector: vec,
+		Norm:   embutil.Norm(vec),
+	}, nil
+}
@@ -0,0 +1,149 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis
+
+import (
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/embedding"
+)
+
+// server is the fake of redis which serves the hashes in memory.
+type server struct {
+	l      net.Listener
+	hashes map[string]map[string]string
+	cmds   [][]string
+	// dup returns the keys twice on SCAN, which redis may do during rehashing.
+	dup bool
+}
+
+func newServer(t *testing.T) *server {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	s := &server{l: l, hashes: make(map[string]map[string]string)}
+	go s.serve()
+	return s
+}
+
+func (s *server) serve() {
+	for {
+		c, err := s.l.Accept()
+		if err != nil {
+			return
+		}
+		conn := newConn(c)
+		for {
+			req, err := conn.receive()
+			if err != nil {
+				c.Close()
+				break
+			}
+			var args []string
+			for _, arg := range req.([]interface{}) {
+				args = append(args, string(arg.([]byte)))
+			}
+			s.cmds = append(s.cmds, args)
+			conn.w.WriteString(s.reply(args))
+			conn.flush()
+		}
+	}
+}
+
+func bulk(s string) string {
+	return "$" + strconv.Itoa(len(s)) + "\r\n" + s + "\r\n"
+}
+
+func (s *server) reply(args []string) string {
+	switch args[0] {
+	case "HSET":
+		h := make(map[string]string)
+		for i := 2; i < len(args); i += 2 {
+			h[args[i]] = args[i+1]
+		}
+		s.hashes[args[1]] = h
+		return ":2\r\n"
+	case "SCAN":
+		var keys []string
+		for key := range s.hashes {
+			if strings.HasPrefix(key, strings.TrimSuffix(args[3], "*")) {
+				keys = append(keys, bulk(key))
+				if s.dup {
+					keys = append(keys, bulk(key))
+				}
+			}
+		}
+		sort.Strings(keys)
+		return "*2\r\n" + bulk("0") + "*" + strconv.Itoa(len(keys)) + "\r\n" + strings.Join(keys, "")
+	case "HMGET":
+		res := "*" + strconv.Itoa(len(args)-2) + "\r\n"
+		for _, f := range args[2:] {
+			res += bulk(s.hashes[args[1]][f])
+		}
+		return res
+	case "FT.CREATE", "AUTH":
+		return "+OK\r\n"
+	default:
+		return "-ERR unknown command\r\n"
+	}
+}
+
+func TestPushPull(t *testing.T) {
+	s := newServer(t)
+	defer s.l.Close()
+
+	opts := DefaultOptions()
+	opts.Addr = s.l.Addr().String()
+	opts.Batch = 2
+	opts.Index = "idx"
+	opts.Password = "secret"
+	embs := embedding.Embeddings{
+		{Word: "b", Dim: 2, Vector: []float64{1, 0}},
+		{Word: "a", Dim: 2, Vector: []float64{0.5, -0.5}},
+		{Word: "c", Dim: 2, Vector: []float64{0, 1}},
+	}
+	assert.NoError(t, Push(embs, opts))
+	assert.Equal(t, []string{"AUTH", "secret"}, s.cmds[0])
+	assert.Equal(t, []string{"FT.CREATE", "idx", "ON", "HASH", "PREFIX", "1", "wego:"}, s.cmds[1][:7])
+	assert.Contains(t, s.cmds[1], "DIM")
+	assert.Len(t, s.hashes, 3)
+	assert.Equal(t, "a", s.hashes["wego:a"][Word])
+	assert.Len(t, s.hashes["wego:a"][Vector], 8)
+
+	s.hashes["other:x"] = map[string]string{Word: "x", Vector: encode([]float64{1, 1})}
+	got, err := Pull(opts)
+	assert.NoError(t, err)
+	var words []string
+	for _, emb := range got {
+		words = append(words, emb.Word)
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, words)
+	assert.Equal(t, []float64{0.5, -0.5}, got[0].Vector)
+	assert.InDelta(t, 0.7071, got[0].Norm, 1e-4)
+
+	s.dup = true
+	got, err = Pull(opts)
+	assert.NoError(t, err)
+	assert.Len(t, got, 3)
+}
+
+func TestEscape(t *testing.T) {
+	assert.Equal(t, `wego\*\[x\]:`, escape("wego*[x]:"))
+}
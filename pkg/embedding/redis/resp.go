@@ -0,0 +1,137 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"os"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// conn is the client of RESP, the protocol of Redis, which pipelines the commands by send and receive.
+type conn struct {
+	c net.Conn
+	r *bufio.Reader
+	w *bufio.Writer
+}
+
+func newConn(c net.Conn) *conn {
+	return &conn{
+		c: c,
+		r: bufio.NewReader(c),
+		w: bufio.NewWriter(c),
+	}
+}
+
+// dial connects to the server, and authenticates by the password or $REDISCLI_AUTH if any.
+func dial(opts Options) (*conn, error) {
+	c, err := net.DialTimeout("tcp", opts.Addr, opts.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	conn := newConn(c)
+	password := opts.Password
+	if password == "" {
+		password = os.Getenv("REDISCLI_AUTH")
+	}
+	if password != "" {
+		if _, err := conn.do("AUTH", password); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	if opts.DB != 0 {
+		if _, err := conn.do("SELECT", strconv.Itoa(opts.DB)); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+func (c *conn) Close() error {
+	return c.c.Close()
+}
+
+// send buffers the command of the bulk strings, which may be binary.
+func (c *conn) send(args ...string) {
+	c.w.WriteString("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, arg := range args {
+		c.w.WriteString("$" + strconv.Itoa(len(arg)) + "\r\n")
+		c.w.WriteString(arg)
+		c.w.WriteString("\r\n")
+	}
+}
+
+func (c *conn) flush() error {
+	return c.w.Flush()
+}
+
+// receive reads the reply, which is string, int64, []byte, []interface{}, or nil.
+// The error reply is returned as the error.
+func (c *conn) receive() (interface{}, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, errors.Errorf("invalid reply: %q", line)
+	}
+	typ, body := line[0], line[1:len(line)-2]
+	switch typ {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, errors.Errorf("redis: %s", body)
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(body)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		b := make([]byte, n+2)
+		if _, err := io.ReadFull(c.r, b); err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	case '*':
+		n, err := strconv.Atoi(body)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		res := make([]interface{}, n)
+		for i := range res {
+			if res[i], err = c.receive(); err != nil {
+				return nil, err
+			}
+		}
+		return res, nil
+	default:
+		return nil, errors.Errorf("invalid reply: %q", line)
+	}
+}
+
+func (c *conn) do(args ...string) (interface{}, error) {
+	c.send(args...)
+	if err := c.flush(); err != nil {
+		return nil, err
+	}
+	return c.receive()
+}
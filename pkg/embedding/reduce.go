@@ -0,0 +1,121 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embedding
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/embedding/embutil"
+)
+
+// Reduce projects the embeddings onto the top dim principal components by PCA.
+func Reduce(embs Embeddings, dim int) (Embeddings, error) {
+	centered, err := center(embs)
+	if err != nil {
+		return nil, err
+	}
+	if dim <= 0 || dim > embs[0].Dim {
+		return nil, errors.Errorf("dim must be in [1, %d], got %d", embs[0].Dim, dim)
+	}
+	comps, err := embutil.Principal(centered, dim)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(Embeddings, len(embs))
+	for i, emb := range embs {
+		vec := make([]float64, len(comps))
+		for c, comp := range comps {
+			for j, v := range centered[i] {
+				vec[c] += v * comp[j]
+			}
+		}
+		res[i] = Embedding{
+			Word:   emb.Word,
+			Dim:    len(vec),
+			Vector: vec,
+			Norm:   embutil.Norm(vec),
+		}
+	}
+	return res, nil
+}
+
+// AllButTheTop is the post-processing by Mu and Viswanath (2018), "All-but-the-Top: Simple and
+// Effective Postprocessing for Word Representations". It removes the mean and the top d principal
+// components, which are dominated by word frequency. d is about dim/100 in the paper.
+func AllButTheTop(embs Embeddings, d int) (Embeddings, error) {
+	centered, err := center(embs)
+	if err != nil {
+		return nil, err
+	}
+	if d < 0 || d >= embs[0].Dim {
+		return nil, errors.Errorf("d must be in [0, %d), got %d", embs[0].Dim, d)
+	}
+	var comps [][]float64
+	if d > 0 {
+		if comps, err = embutil.Principal(centered, d); err != nil {
+			return nil, err
+		}
+	}
+
+	res := make(Embeddings, len(embs))
+	for i, emb := range embs {
+		vec := centered[i]
+		for _, comp := range comps {
+			var dot float64
+			for j, v := range vec {
+				dot += v * comp[j]
+			}
+			for j := range vec {
+				vec[j] -= dot * comp[j]
+			}
+		}
+		res[i] = Embedding{
+			Word:   emb.Word,
+			Dim:    len(vec),
+			Vector: vec,
+			Norm:   embutil.Norm(vec),
+		}
+	}
+	return res, nil
+}
+
+// center returns the copy of the vectors subtracted by their mean.
+func center(embs Embeddings) ([][]float64, error) {
+	if embs.Empty() {
+		return nil, errors.New("embeddings are empty")
+	}
+	if err := embs.Validate(); err != nil {
+		return nil, err
+	}
+	dim := embs[0].Dim
+	mean := make([]float64, dim)
+	for _, emb := range embs {
+		for j, v := range emb.Vector {
+			mean[j] += v
+		}
+	}
+	for j := range mean {
+		mean[j] /= float64(len(embs))
+	}
+	res := make([][]float64, len(embs))
+	for i, emb := range embs {
+		res[i] = make([]float64, dim)
+		for j, v := range emb.Vector {
+			res[i][j] = v - mean[j]
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,77 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embedding
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/embedding/embutil"
+)
+
+func testEmbeddings() Embeddings {
+	vecs := [][]float64{
+		{3, 1, 0.1},
+		{-3, -1, 0.1},
+		{1, 0.4, -0.2},
+		{-1, -0.4, 0},
+	}
+	embs := make(Embeddings, len(vecs))
+	for i, vec := range vecs {
+		embs[i] = Embedding{
+			Word:   string(rune('a' + i)),
+			Dim:    len(vec),
+			Vector: vec,
+			Norm:   embutil.Norm(vec),
+		}
+	}
+	return embs
+}
+
+func TestReduce(t *testing.T) {
+	embs := testEmbeddings()
+	res, err := Reduce(embs, 2)
+	assert.NoError(t, err)
+	assert.Len(t, res, 4)
+	assert.Equal(t, "a", res[0].Word)
+	assert.Equal(t, 2, res[0].Dim)
+
+	// the distances are almost kept because the third axis has little variance.
+	dist := func(v1, v2 []float64) float64 {
+		d := make([]float64, len(v1))
+		for i := range d {
+			d[i] = v1[i] - v2[i]
+		}
+		return embutil.Norm(d)
+	}
+	assert.InDelta(t, dist(embs[0].Vector, embs[1].Vector), dist(res[0].Vector, res[1].Vector), 0.05)
+
+	_, err = Reduce(embs, 4)
+	assert.Error(t, err)
+}
+
+func TestAllButTheTop(t *testing.T) {
+	embs := testEmbeddings()
+	res, err := AllButTheTop(embs, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, res[0].Dim)
+
+	// the dominant direction (3, 1, 0) is removed.
+	for _, emb := range res {
+		assert.InDelta(t, 0, emb.Vector[0]*3+emb.Vector[1], 0.2)
+	}
+	assert.Equal(t, []float64{3, 1, 0.1}, embs[0].Vector)
+}
@@ -0,0 +1,191 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package safetensors reads and writes the embeddings in the safetensors format
+// (https://github.com/huggingface/safetensors), with the vocabulary in the adjacent JSON.
+package safetensors
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/embutil"
+)
+
+// Tensor is the name of the matrix of the vectors, F32 of shape [vocab, dim].
+const Tensor = "embeddings"
+
+const (
+	dtypeF32 = "F32"
+	dtypeF64 = "F64"
+
+	// maxHeaderSize guards against allocating the header by the corrupted size.
+	maxHeaderSize = 100 << 20
+)
+
+type tensorInfo struct {
+	DType       string   `json:"dtype"`
+	Shape       []int    `json:"shape"`
+	DataOffsets [2]int64 `json:"data_offsets"`
+}
+
+// Write writes the vectors of embs into w as the tensor named Tensor, and the words into vocab as the JSON array
+// in the order of the rows.
+func Write(w, vocab io.Writer, embs embedding.Embeddings) error {
+	if embs.Empty() {
+		return errors.New("embeddings are empty")
+	}
+	if err := embs.Validate(); err != nil {
+		return err
+	}
+	rows, dim := len(embs), embs[0].Dim
+	header, err := json.Marshal(map[string]interface{}{
+		Tensor: tensorInfo{
+			DType:       dtypeF32,
+			Shape:       []int{rows, dim},
+			DataOffsets: [2]int64{0, int64(rows) * int64(dim) * 4},
+		},
+		"__metadata__": map[string]string{
+			"format": "wego",
+		},
+	})
+	if err != nil {
+		return err
+	}
+	// pad the header by spaces to align the data to 8 bytes.
+	for len(header)%8 != 0 {
+		header = append(header, ' ')
+	}
+
+	buf := bufio.NewWriter(w)
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, uint64(len(header)))
+	buf.Write(b)
+	buf.Write(header)
+	for _, emb := range embs {
+		for _, v := range emb.Vector {
+			binary.LittleEndian.PutUint32(b, math.Float32bits(float32(v)))
+			if _, err := buf.Write(b[:4]); err != nil {
+				return err
+			}
+		}
+	}
+	if err := buf.Flush(); err != nil {
+		return err
+	}
+
+	words := make([]string, rows)
+	for i, emb := range embs {
+		words[i] = emb.Word
+	}
+	return json.NewEncoder(vocab).Encode(words)
+}
+
+// Read reads the embeddings from the safetensors file with the vocabulary of the JSON array.
+// The tensor named Tensor is read, or the only tensor in the file otherwise. F32 and F64 are supported.
+func Read(r, vocab io.Reader) (embedding.Embeddings, error) {
+	var words []string
+	if err := json.NewDecoder(vocab).Decode(&words); err != nil {
+		return nil, errors.Wrap(err, "failed to decode vocabulary")
+	}
+
+	br := bufio.NewReader(r)
+	b := make([]byte, 8)
+	if _, err := io.ReadFull(br, b); err != nil {
+		return nil, errors.Wrap(err, "failed to read header size")
+	}
+	size := binary.LittleEndian.Uint64(b)
+	if size > maxHeaderSize {
+		return nil, errors.Errorf("header size %d exceeds %d bytes", size, maxHeaderSize)
+	}
+	header := make([]byte, size)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, errors.Wrap(err, "failed to read header")
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(header, &raw); err != nil {
+		return nil, errors.Wrap(err, "failed to decode header")
+	}
+	delete(raw, "__metadata__")
+	name := Tensor
+	if _, ok := raw[name]; !ok {
+		if len(raw) != 1 {
+			names := make([]string, 0, len(raw))
+			for n := range raw {
+				names = append(names, n)
+			}
+			sort.Strings(names)
+			return nil, errors.Errorf("tensor %s is not found in %v", Tensor, names)
+		}
+		for n := range raw {
+			name = n
+		}
+	}
+	var info tensorInfo
+	if err := json.Unmarshal(raw[name], &info); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode tensor %s", name)
+	}
+
+	var width int
+	switch info.DType {
+	case dtypeF32:
+		width = 4
+	case dtypeF64:
+		width = 8
+	default:
+		return nil, errors.Errorf("dtype of tensor %s must be %s|%s, got %s", name, dtypeF32, dtypeF64, info.DType)
+	}
+	if len(info.Shape) != 2 {
+		return nil, errors.Errorf("tensor %s must be 2-dimensional, got shape %v", name, info.Shape)
+	}
+	rows, dim := info.Shape[0], info.Shape[1]
+	if rows != len(words) {
+		return nil, errors.Errorf("tensor %s has %d rows but vocabulary has %d words", name, rows, len(words))
+	}
+	if dim <= 0 || info.DataOffsets[1]-info.DataOffsets[0] != int64(rows)*int64(dim)*int64(width) {
+		return nil, errors.Errorf("data offsets %v of tensor %s mismatch shape %v", info.DataOffsets, name, info.Shape)
+	}
+	if _, err := br.Discard(int(info.DataOffsets[0])); err != nil {
+		return nil, errors.Wrap(err, "failed to seek data")
+	}
+
+	embs := make(embedding.Embeddings, rows)
+	for i, word := range words {
+		vec := make([]float64, dim)
+		for j := range vec {
+			if _, err := io.ReadFull(br, b[:width]); err != nil {
+				return nil, errors.Wrapf(err, "failed to read row %d", i)
+			}
+			if width == 4 {
+				vec[j] = float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+			} else {
+				vec[j] = math.Float64frombits(binary.LittleEndian.Uint64(b))
+			}
+		}
+		embs[i] = embedding.Embedding{
+			Word:   word,
+			Dim:    dim,
+			Vector: vec,
+			Norm:   embutil.Norm(vec),
+		}
+	}
+	return embs, nil
+}
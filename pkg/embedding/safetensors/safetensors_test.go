@@ -0,0 +1,66 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safetensors
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/embedding"
+)
+
+func TestWriteRead(t *testing.T) {
+	embs := embedding.Embeddings{
+		{Word: "a", Dim: 2, Vector: []float64{1, 2}},
+		{Word: "b", Dim: 2, Vector: []float64{-0.5, 3}},
+	}
+	var buf, vocab bytes.Buffer
+	assert.NoError(t, Write(&buf, &vocab, embs))
+	assert.Equal(t, "[\"a\",\"b\"]\n", vocab.String())
+	assert.Equal(t, uint64(0), binary.LittleEndian.Uint64(buf.Bytes())%8)
+
+	res, err := Read(&buf, &vocab)
+	assert.NoError(t, err)
+	assert.Len(t, res, 2)
+	for i := range embs {
+		assert.Equal(t, embs[i].Word, res[i].Word)
+		assert.Equal(t, embs[i].Vector, res[i].Vector)
+	}
+
+	_, err = Read(bytes.NewReader(buf.Bytes()), strings.NewReader(`["a"]`))
+	assert.Error(t, err)
+}
+
+func TestReadF64(t *testing.T) {
+	header := `{"__metadata__":{},"w":{"dtype":"F64","shape":[1,2],"data_offsets":[0,16]}}`
+	var buf bytes.Buffer
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, uint64(len(header)))
+	buf.Write(b)
+	buf.WriteString(header)
+	for _, v := range []float64{0.1, -2} {
+		binary.LittleEndian.PutUint64(b, math.Float64bits(v))
+		buf.Write(b)
+	}
+
+	res, err := Read(&buf, strings.NewReader(`["x"]`))
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{0.1, -2}, res[0].Vector)
+}
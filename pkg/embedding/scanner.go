@@ -0,0 +1,115 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embedding
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/util/fileutil"
+)
+
+// Scanner reads the embeddings line by line like bufio.Scanner, so the huge files are filtered or transformed
+// in constant memory without Load:
+//
+//	s := embedding.NewScanner(r)
+//	for s.Scan() {
+//		use(s.Word(), s.Vector())
+//	}
+//	if err := s.Err(); err != nil { ... }
+type Scanner struct {
+	s    *bufio.Scanner
+	line int
+	word string
+	vec  []float64
+	err  error
+}
+
+func NewScanner(r io.Reader) *Scanner {
+	return &Scanner{
+		s: fileutil.NewScanner(r, bufio.ScanLines),
+	}
+}
+
+// Scan advances to the next embedding, and returns false at the end or on the error.
+func (s *Scanner) Scan() bool {
+	if s.err != nil {
+		return false
+	}
+	for s.s.Scan() {
+		s.line++
+		line := s.s.Text()
+		if strings.HasPrefix(line, " ") {
+			continue
+		}
+		word, vec, err := parseFields(line, s.vec[:0])
+		if err != nil {
+			s.err = &ParseError{Line: s.line, Err: err}
+			return false
+		}
+		s.word, s.vec = word, vec
+		return true
+	}
+	if err := s.s.Err(); err != nil && err != io.EOF {
+		s.err = errors.Wrapf(err, "failed to scan")
+	}
+	return false
+}
+
+// Word returns the word of the current embedding.
+func (s *Scanner) Word() string {
+	return s.word
+}
+
+// Vector returns the vector of the current embedding, which is overwritten by the next Scan.
+func (s *Scanner) Vector() []float64 {
+	return s.vec
+}
+
+// Err returns the first error except io.EOF.
+func (s *Scanner) Err() error {
+	return s.err
+}
+
+// Parse calls fn with the word and the vector of each line in order, and stops at the first error of fn.
+// The vector is overwritten after fn returns, so fn copies it to keep.
+func Parse(r io.Reader, fn func(word string, vec []float64) error) error {
+	s := NewScanner(r)
+	for s.Scan() {
+		if err := fn(s.Word(), s.Vector()); err != nil {
+			return err
+		}
+	}
+	return s.Err()
+}
+
+func parseFields(line string, vec []float64) (string, []float64, error) {
+	slice := strings.Fields(line)
+	if len(slice) < 2 {
+		return "", nil, errors.New("Must be over 2 lenghth for word and vector elems")
+	}
+	for _, elem := range slice[1:] {
+		val, err := strconv.ParseFloat(elem, 64)
+		if err != nil {
+			return "", nil, err
+		}
+		vec = append(vec, val)
+	}
+	return slice[0], vec, nil
+}
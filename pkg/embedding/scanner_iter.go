@@ -0,0 +1,39 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//go:build go1.23
+// +build go1.23
+
+package embedding
+
+import (
+	"iter"
+)
+
+// All returns the iterator of the words and the vectors for range over func, where the vector is
+// overwritten by the next iteration as Vector. Breaking the loop stops scanning, and Err reports the error after it:
+//
+//	s := embedding.NewScanner(r)
+//	for word, vec := range s.All() {
+//		use(word, vec)
+//	}
+//	if err := s.Err(); err != nil { ... }
+func (s *Scanner) All() iter.Seq2[string, []float64] {
+	return func(yield func(string, []float64) bool) {
+		for s.Scan() {
+			if !yield(s.Word(), s.Vector()) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//go:build go1.23
+// +build go1.23
+
+package embedding
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScannerAll(t *testing.T) {
+	s := NewScanner(strings.NewReader("apple 1 2\n  header\nbanana 3 4\nchocolate 5 6\n"))
+	var words []string
+	for word, vec := range s.All() {
+		words = append(words, word)
+		if vec[0] == 3 {
+			break
+		}
+	}
+	assert.Equal(t, []string{"apple", "banana"}, words)
+	assert.NoError(t, s.Err())
+
+	s = NewScanner(strings.NewReader("apple 1 2\nbanana 3 x\n"))
+	for range s.All() {
+	}
+	var perr *ParseError
+	assert.True(t, errors.As(s.Err(), &perr))
+	assert.Equal(t, 2, perr.Line)
+}
@@ -0,0 +1,56 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embedding
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFunc(t *testing.T) {
+	r := strings.NewReader("apple 1 2\n  header\nbanana 3 4\nchocolate 5 6\n")
+
+	var words []string
+	var sum float64
+	assert.NoError(t, Parse(r, func(word string, vec []float64) error {
+		words = append(words, word)
+		sum += vec[0] + vec[1]
+		return nil
+	}))
+	assert.Equal(t, []string{"apple", "banana", "chocolate"}, words)
+	assert.Equal(t, 21., sum)
+
+	stop := errors.New("stop")
+	err := Parse(strings.NewReader("apple 1 2\nbanana 3 4\n"), func(string, []float64) error {
+		return stop
+	})
+	assert.Equal(t, stop, err)
+}
+
+func TestScanner(t *testing.T) {
+	s := NewScanner(strings.NewReader("apple 1 2\nbanana 3 x\nchocolate 5 6\n"))
+	assert.True(t, s.Scan())
+	assert.Equal(t, "apple", s.Word())
+	assert.Equal(t, []float64{1, 2}, s.Vector())
+	assert.False(t, s.Scan())
+	assert.False(t, s.Scan())
+
+	var perr *ParseError
+	assert.True(t, errors.As(s.Err(), &perr))
+	assert.Equal(t, 2, perr.Line)
+}
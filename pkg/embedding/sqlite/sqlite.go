@@ -0,0 +1,207 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sqlite stores the embeddings in the SQLite file of the table (word TEXT PRIMARY KEY, vector BLOB),
+// from which the vectors are loaded lazily by the words.
+// The driver is not linked into this package, and the database/sql driver of sqlite3 or sqlite
+// (e.g. github.com/mattn/go-sqlite3 or modernc.org/sqlite) is registered by importing it into the build,
+// as the wego binary does with `-tags sqlite`.
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/binary"
+	"math"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/embutil"
+)
+
+// Table is the name of the table, whose vectors are the blobs of float32 in little endian.
+const Table = "embeddings"
+
+// drivers are the names of the database/sql drivers of SQLite in the order of preference.
+var drivers = []string{"sqlite3", "sqlite"}
+
+// registered returns the name of the registered driver, or false if none is.
+func registered() (string, bool) {
+	linked := sql.Drivers()
+	for _, name := range drivers {
+		for _, d := range linked {
+			if d == name {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Available reports whether the database/sql driver of SQLite is registered.
+func Available() bool {
+	_, ok := registered()
+	return ok
+}
+
+func open(path string) (*sql.DB, error) {
+	name, ok := registered()
+	if !ok {
+		return nil, errors.Errorf("no database/sql driver of %s is registered, build with cgo and -tags sqlite or import it into the build", strings.Join(drivers, "|"))
+	}
+	return sql.Open(name, path)
+}
+
+// Save writes embs into the table created in the file of path.
+func Save(path string, embs embedding.Embeddings) error {
+	if err := embs.Validate(); err != nil {
+		return err
+	}
+	db, err := open(path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec("CREATE TABLE " + Table + " (word TEXT PRIMARY KEY, vector BLOB NOT NULL)"); err != nil {
+		return errors.Wrapf(err, "failed to create table in %s", path)
+	}
+	stmt, err := tx.Prepare("INSERT INTO " + Table + " (word, vector) VALUES (?, ?)")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, emb := range embs {
+		if _, err := stmt.Exec(emb.Word, encode(emb.Vector)); err != nil {
+			return errors.Wrapf(err, "failed to insert %s", emb.Word)
+		}
+	}
+	return tx.Commit()
+}
+
+func encode(vec []float64) []byte {
+	b := make([]byte, 4*len(vec))
+	for i, v := range vec {
+		binary.LittleEndian.PutUint32(b[4*i:], math.Float32bits(float32(v)))
+	}
+	return b
+}
+
+func decode(word string, b []byte) (embedding.Embedding, error) {
+	if len(b)%4 != 0 {
+		return embedding.Embedding{}, errors.Errorf("invalid vector of %s: %d bytes", word, len(b))
+	}
+	vec := make([]float64, len(b)/4)
+	for i := range vec {
+		vec[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(b[4*i:])))
+	}
+	return embedding.Embedding{
+		Word:   word,
+		Dim:    len(vec),
+		Vector: vec,
+		Norm:   embutil.Norm(vec),
+	}, nil
+}
+
+// Store reads the vectors from the SQLite file row by row, so that only the requested words are held in memory.
+type Store struct {
+	db     *sql.DB
+	lookup *sql.Stmt
+}
+
+func Open(path string) (*Store, error) {
+	db, err := open(path)
+	if err != nil {
+		return nil, err
+	}
+	lookup, err := db.Prepare("SELECT vector FROM " + Table + " WHERE word = ?")
+	if err != nil {
+		db.Close()
+		return nil, errors.Wrapf(err, "failed to open %s", path)
+	}
+	return &Store{
+		db:     db,
+		lookup: lookup,
+	}, nil
+}
+
+func (s *Store) Close() error {
+	s.lookup.Close()
+	return s.db.Close()
+}
+
+// Len returns the number of the words.
+func (s *Store) Len() (int, error) {
+	var n int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM " + Table).Scan(&n)
+	return n, err
+}
+
+// Lookup returns the embedding of word, and false if word is not found.
+func (s *Store) Lookup(word string) (embedding.Embedding, bool, error) {
+	var b []byte
+	if err := s.lookup.QueryRow(word).Scan(&b); err == sql.ErrNoRows {
+		return embedding.Embedding{}, false, nil
+	} else if err != nil {
+		return embedding.Embedding{}, false, err
+	}
+	emb, err := decode(word, b)
+	return emb, err == nil, err
+}
+
+// Load returns the embeddings of words in the order, skipping the words not found,
+// e.g. to build the searcher over the subset of the vocabulary.
+func (s *Store) Load(words ...string) (embedding.Embeddings, error) {
+	var embs embedding.Embeddings
+	for _, word := range words {
+		emb, ok, err := s.Lookup(word)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			embs = append(embs, emb)
+		}
+	}
+	return embs, nil
+}
+
+// Each calls fn for all the embeddings in the order of Save.
+func (s *Store) Each(fn func(embedding.Embedding) error) error {
+	rows, err := s.db.Query("SELECT word, vector FROM " + Table + " ORDER BY rowid")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var word string
+		var b []byte
+		if err := rows.Scan(&word, &b); err != nil {
+			return err
+		}
+		emb, err := decode(word, b)
+		if err != nil {
+			return err
+		}
+		if err := fn(emb); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,143 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !cgo || !sqlite
+// +build !cgo !sqlite
+
+package sqlite
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeDriver serves the statements of the store over the rows in memory by the path.
+type fakeDriver struct {
+	mu     sync.Mutex
+	tables map[string]*fakeTable
+}
+
+type fakeTable struct {
+	words   []string
+	vectors [][]byte
+}
+
+func (d *fakeDriver) Open(path string) (driver.Conn, error) {
+	return &fakeConn{driver: d, path: path}, nil
+}
+
+type fakeConn struct {
+	driver *fakeDriver
+	path   string
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{conn: c, query: query}, nil
+}
+
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return c, nil }
+func (c *fakeConn) Commit() error             { return nil }
+func (c *fakeConn) Rollback() error           { return nil }
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return strings.Count(s.query, "?") }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	d := s.conn.driver
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	switch {
+	case strings.HasPrefix(s.query, "CREATE TABLE "+Table):
+		if _, ok := d.tables[s.conn.path]; ok {
+			return nil, errors.New("table already exists")
+		}
+		d.tables[s.conn.path] = &fakeTable{}
+	case strings.HasPrefix(s.query, "INSERT INTO "+Table):
+		t := d.tables[s.conn.path]
+		t.words = append(t.words, args[0].(string))
+		t.vectors = append(t.vectors, args[1].([]byte))
+	default:
+		return nil, errors.Errorf("unexpected exec: %s", s.query)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	d := s.conn.driver
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	t, ok := d.tables[s.conn.path]
+	if !ok {
+		return nil, errors.New("no such table")
+	}
+	rows := &fakeRows{}
+	switch {
+	case strings.HasPrefix(s.query, "SELECT COUNT(*)"):
+		rows.cols = []string{"n"}
+		rows.values = [][]driver.Value{{int64(len(t.words))}}
+	case strings.HasPrefix(s.query, "SELECT vector"):
+		rows.cols = []string{"vector"}
+		for i, word := range t.words {
+			if word == args[0].(string) {
+				rows.values = append(rows.values, []driver.Value{t.vectors[i]})
+			}
+		}
+	case strings.HasPrefix(s.query, "SELECT word, vector"):
+		rows.cols = []string{"word", "vector"}
+		for i, word := range t.words {
+			rows.values = append(rows.values, []driver.Value{word, t.vectors[i]})
+		}
+	default:
+		return nil, errors.Errorf("unexpected query: %s", s.query)
+	}
+	return rows, nil
+}
+
+type fakeRows struct {
+	cols   []string
+	values [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.cols }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func TestStore(t *testing.T) {
+	path := "vectors.sqlite"
+	_, err := Open(path)
+	assert.Error(t, err, "no driver")
+	sql.Register(drivers[0], &fakeDriver{tables: make(map[string]*fakeTable)})
+	testStore(t, path)
+}
@@ -0,0 +1,68 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/search"
+)
+
+// testStore runs the store over the driver registered by the build.
+func testStore(t *testing.T, path string) {
+
+	embs := embedding.Embeddings{
+		{Word: "b", Dim: 2, Vector: []float64{1, 0.25}},
+		{Word: "a", Dim: 2, Vector: []float64{0.5, 0.5}},
+		{Word: "c", Dim: 2, Vector: []float64{0.25, 1}},
+	}
+	assert.NoError(t, Save(path, embs))
+	assert.Error(t, Save(path, embs), "table exists")
+
+	s, err := Open(path)
+	assert.NoError(t, err)
+	defer s.Close()
+
+	n, err := s.Len()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	emb, ok, err := s.Lookup("a")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []float64{0.5, 0.5}, emb.Vector)
+	assert.InDelta(t, 0.7071, emb.Norm, 1e-4)
+	_, ok, err = s.Lookup("x")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	loaded, err := s.Load("c", "x", "b")
+	assert.NoError(t, err)
+	searcher, err := search.New(loaded...)
+	assert.NoError(t, err)
+	neighbors, err := searcher.SearchInternal("c", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "b", neighbors[0].Word)
+
+	var words []string
+	assert.NoError(t, s.Each(func(emb embedding.Embedding) error {
+		words = append(words, emb.Word)
+		return nil
+	}))
+	assert.Equal(t, []string{"b", "a", "c"}, words)
+}
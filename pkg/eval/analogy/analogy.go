@@ -0,0 +1,255 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analogy
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/search"
+	"github.com/ynqa/wego/pkg/util/fileutil"
+	"github.com/ynqa/wego/pkg/vector"
+)
+
+const (
+	// top is the largest k of the precisions.
+	top = 5
+)
+
+var (
+	defaultGoroutines = runtime.NumCPU()
+	defaultRestrict   = 0
+)
+
+type Options struct {
+	Goroutines int
+	// Restrict is the number of the first words in the vectors to answer from, 0 means all words.
+	// The questions of the other words are not found.
+	Restrict int
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Goroutines: defaultGoroutines,
+		Restrict:   defaultRestrict,
+	}
+}
+
+// Question is a is to b as c is to d in the category.
+type Question struct {
+	Category   string
+	A, B, C, D string
+}
+
+// Load reads the questions of `a b c d` separated by tabs or spaces, e.g. questions-words.txt of Mikolov et al.
+// The lines of `: name` start the category of the following questions. Empty lines and lines starting with # are skipped.
+func Load(r io.Reader) ([]Question, error) {
+	var questions []Question
+	var category string
+	s := fileutil.NewScanner(r, bufio.ScanLines)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			category = strings.TrimSpace(line[1:])
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 4 {
+			return nil, errors.Errorf("line %d must be `a b c d`, got %q", n, line)
+		}
+		questions = append(questions, Question{
+			Category: category,
+			A:        fields[0],
+			B:        fields[1],
+			C:        fields[2],
+			D:        fields[3],
+		})
+	}
+	if err := s.Err(); err != nil && err != io.EOF {
+		return nil, errors.Wrapf(err, "failed to scan")
+	}
+	return questions, nil
+}
+
+// Category is the result of the questions in the category. Found is the number of the questions whose words are all
+// in the vectors, and P1 and P5 are the precisions at 1 and 5 over them, i.e. the fractions of the questions whose d
+// is the nearest or in the 5 nearest words to b - a + c except a, b and c.
+type Category struct {
+	Name  string  `json:"name"`
+	Found int     `json:"found"`
+	Total int     `json:"total"`
+	P1    float64 `json:"p@1"`
+	P5    float64 `json:"p@5"`
+}
+
+// Report is the results per category in the order of the questions, and Overall over all questions.
+type Report struct {
+	Categories []Category `json:"categories"`
+	Overall    Category   `json:"overall"`
+}
+
+// Write writes the report in format, search.Table or search.JSON.
+func (r Report) Write(w io.Writer, format search.Format) error {
+	switch format {
+	case search.Table:
+		writer := tablewriter.NewWriter(w)
+		writer.SetHeader([]string{"Category", "P@1", "P@5", "Found"})
+		writer.SetBorder(false)
+		for _, c := range append(r.Categories, r.Overall) {
+			writer.Append([]string{
+				c.Name,
+				fmt.Sprintf("%f", c.P1),
+				fmt.Sprintf("%f", c.P5),
+				fmt.Sprintf("%d/%d", c.Found, c.Total),
+			})
+		}
+		writer.Render()
+		return nil
+	case search.JSON:
+		if r.Categories == nil {
+			r.Categories = []Category{}
+		}
+		return json.NewEncoder(w).Encode(r)
+	default:
+		return errors.Errorf("invalid format: %s not in %s|%s", format, search.Table, search.JSON)
+	}
+}
+
+// Evaluate answers the questions by the 3CosAdd of the unit vectors.
+func Evaluate(embs embedding.Embeddings, questions []Question, opts Options) (Report, error) {
+	if opts.Goroutines <= 0 || opts.Restrict < 0 {
+		return Report{}, errors.Errorf("goroutines must be positive and restrict >= 0, got %d and %d", opts.Goroutines, opts.Restrict)
+	}
+	if opts.Restrict > 0 && len(embs) > opts.Restrict {
+		embs = embs[:opts.Restrict]
+	}
+	index := make(map[string]int, len(embs))
+	rows := make([][]float64, len(embs))
+	for i, emb := range embs {
+		if _, ok := index[emb.Word]; !ok {
+			index[emb.Word] = i
+		}
+		rows[i] = vector.Unit(emb.Vector)
+	}
+
+	// ranks[q] is the rank of d from 1 in the answers of the question q, 0 if it is not found or not in the top.
+	ranks := make([]int, len(questions))
+	found := make([]bool, len(questions))
+	var wg sync.WaitGroup
+	for t := 0; t < opts.Goroutines; t++ {
+		wg.Add(1)
+		go func(t int) {
+			defer wg.Done()
+			for q := t; q < len(questions); q += opts.Goroutines {
+				ranks[q], found[q] = answer(rows, index, questions[q])
+			}
+		}(t)
+	}
+	wg.Wait()
+
+	var report Report
+	report.Overall.Name = "overall"
+	categories := make(map[string]int)
+	for q, question := range questions {
+		c, ok := categories[question.Category]
+		if !ok {
+			c = len(report.Categories)
+			categories[question.Category] = c
+			report.Categories = append(report.Categories, Category{Name: question.Category})
+		}
+		for _, cat := range []*Category{&report.Categories[c], &report.Overall} {
+			cat.Total++
+			if !found[q] {
+				continue
+			}
+			cat.Found++
+			if ranks[q] == 1 {
+				cat.P1++
+			}
+			if ranks[q] >= 1 {
+				cat.P5++
+			}
+		}
+	}
+	if report.Overall.Found == 0 {
+		return Report{}, errors.Errorf("found no questions out of %d in embeddings", len(questions))
+	}
+	for i := range report.Categories {
+		report.Categories[i].precision()
+	}
+	report.Overall.precision()
+	return report, nil
+}
+
+func (c *Category) precision() {
+	if c.Found > 0 {
+		c.P1 /= float64(c.Found)
+		c.P5 /= float64(c.Found)
+	}
+}
+
+// answer returns the rank of d in the top nearest words to b - a + c, and whether the words are all found.
+func answer(rows [][]float64, index map[string]int, question Question) (int, bool) {
+	ids := make([]int, 4)
+	for i, word := range []string{question.A, question.B, question.C, question.D} {
+		id, ok := index[word]
+		if !ok {
+			return 0, false
+		}
+		ids[i] = id
+	}
+	query := make([]float64, len(rows[0]))
+	for i := range query {
+		query[i] = rows[ids[1]][i] - rows[ids[0]][i] + rows[ids[2]][i]
+	}
+
+	var nearest [top]int
+	var sims [top]float64
+	for n := range nearest {
+		nearest[n], sims[n] = -1, -2
+	}
+	for j, row := range rows {
+		if j == ids[0] || j == ids[1] || j == ids[2] {
+			continue
+		}
+		sim := vector.Dot(query, row)
+		if sim <= sims[top-1] {
+			continue
+		}
+		n := top - 1
+		for ; n > 0 && sims[n-1] < sim; n-- {
+			nearest[n], sims[n] = nearest[n-1], sims[n-1]
+		}
+		nearest[n], sims[n] = j, sim
+	}
+	for n, id := range nearest {
+		if id == ids[3] {
+			return n + 1, true
+		}
+	}
+	return 0, true
+}
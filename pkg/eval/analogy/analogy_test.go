@@ -0,0 +1,72 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analogy
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/search"
+)
+
+func TestLoad(t *testing.T) {
+	questions, err := Load(strings.NewReader("# comment\n: capital-common-countries\nAthens Greece Baghdad Iraq\n\n: gram-plural\nman\tmen\twoman\twomen\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []Question{
+		{Category: "capital-common-countries", A: "Athens", B: "Greece", C: "Baghdad", D: "Iraq"},
+		{Category: "gram-plural", A: "man", B: "men", C: "woman", D: "women"},
+	}, questions)
+
+	_, err = Load(strings.NewReader("man men woman\n"))
+	assert.Error(t, err)
+}
+
+func TestEvaluate(t *testing.T) {
+	embs := embedding.Embeddings{
+		{Word: "man", Dim: 3, Vector: []float64{1, 0, 0}},
+		{Word: "king", Dim: 3, Vector: []float64{1, 1, 0}},
+		{Word: "woman", Dim: 3, Vector: []float64{0, 0, 1}},
+		{Word: "queen", Dim: 3, Vector: []float64{0, 1, 1}},
+		{Word: "apple", Dim: 3, Vector: []float64{0, 1, 0.9}},
+		{Word: "pear", Dim: 3, Vector: []float64{-1, -1, -1}},
+	}
+	questions := []Question{
+		{Category: "royal", A: "man", B: "king", C: "woman", D: "queen"},
+		{Category: "royal", A: "man", B: "king", C: "woman", D: "pear"},
+		{Category: "royal", A: "man", B: "king", C: "woman", D: "prince"},
+		{Category: "fruit", A: "woman", B: "queen", C: "man", D: "apple"},
+	}
+	opts := DefaultOptions()
+	opts.Goroutines = 2
+	report, err := Evaluate(embs, questions, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []Category{
+		{Name: "royal", Found: 2, Total: 3, P1: 0.5, P5: 1},
+		{Name: "fruit", Found: 1, Total: 1, P1: 0, P5: 1},
+	}, report.Categories)
+	assert.Equal(t, Category{Name: "overall", Found: 3, Total: 4, P1: 1. / 3, P5: 1}, report.Overall)
+
+	opts.Restrict = 2
+	_, err = Evaluate(embs, questions, opts)
+	assert.Error(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, report.Write(&buf, search.JSON))
+	assert.Contains(t, buf.String(), `"p@1":0.5`)
+}
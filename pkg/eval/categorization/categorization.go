@@ -0,0 +1,208 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package categorization is the concept categorization, e.g. AP (Almuhareb and Poesio, 2005), BLESS and BM
+// (Battig and Montague, 1969): the words are clustered by k-means into as many clusters as the categories,
+// and the purity of the clusters against the categories is reported.
+package categorization
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/cluster"
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/search"
+	"github.com/ynqa/wego/pkg/util/fileutil"
+	"github.com/ynqa/wego/pkg/vector"
+)
+
+var (
+	defaultIter     = 100
+	defaultRestarts = 10
+	defaultSeed     = int64(1)
+)
+
+type Options struct {
+	Iter int
+	// Restarts is the number of the runs of k-means from the random initial centroids, which keeps the most compact.
+	Restarts int
+	Seed     int64
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Iter:     defaultIter,
+		Restarts: defaultRestarts,
+		Seed:     defaultSeed,
+	}
+}
+
+// Item is the word of the category.
+type Item struct {
+	Word     string
+	Category string
+}
+
+// Load reads the lines of `word category` separated by a tab, or by spaces if the line has no tabs.
+// Empty lines and lines starting with # are skipped.
+func Load(r io.Reader) ([]Item, error) {
+	var items []Item
+	s := fileutil.NewScanner(r, bufio.ScanLines)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) == 1 {
+			fields = strings.Fields(line)
+		}
+		if len(fields) != 2 {
+			return nil, errors.Errorf("line %d must be `word category`, got %q", n, line)
+		}
+		items = append(items, Item{
+			Word:     strings.TrimSpace(fields[0]),
+			Category: strings.TrimSpace(fields[1]),
+		})
+	}
+	if err := s.Err(); err != nil && err != io.EOF {
+		return nil, errors.Wrapf(err, "failed to scan")
+	}
+	return items, nil
+}
+
+// Result is the purity of the clusters of the Found words, i.e. the fraction of the words in the majority category
+// of their clusters. The words not in the vectors are skipped, and Clusters is the number of the categories.
+type Result struct {
+	Name     string  `json:"name"`
+	Purity   float64 `json:"purity"`
+	Clusters int     `json:"clusters"`
+	Found    int     `json:"found"`
+	Total    int     `json:"total"`
+}
+
+type Results []Result
+
+// Write writes the results in format, search.Table or search.JSON.
+func (results Results) Write(w io.Writer, format search.Format) error {
+	switch format {
+	case search.Table:
+		writer := tablewriter.NewWriter(w)
+		writer.SetHeader([]string{"Dataset", "Purity", "Clusters", "Found"})
+		writer.SetBorder(false)
+		for _, r := range results {
+			writer.Append([]string{
+				r.Name,
+				fmt.Sprintf("%f", r.Purity),
+				fmt.Sprintf("%d", r.Clusters),
+				fmt.Sprintf("%d/%d", r.Found, r.Total),
+			})
+		}
+		writer.Render()
+		return nil
+	case search.JSON:
+		if results == nil {
+			results = Results{}
+		}
+		return json.NewEncoder(w).Encode(results)
+	default:
+		return errors.Errorf("invalid format: %s not in %s|%s", format, search.Table, search.JSON)
+	}
+}
+
+// Evaluate clusters the words of the items by spherical k-means, and returns the purity against their categories.
+func Evaluate(embs embedding.Embeddings, items []Item, name string, opts Options) (Result, error) {
+	if opts.Iter <= 0 || opts.Restarts <= 0 {
+		return Result{}, errors.Errorf("iter and restarts must be positive, got %d and %d", opts.Iter, opts.Restarts)
+	}
+	index := make(map[string]embedding.Embedding, len(embs))
+	for _, emb := range embs {
+		if _, ok := index[emb.Word]; !ok {
+			index[emb.Word] = emb
+		}
+	}
+	var rows [][]float64
+	var labels []int
+	categories := make(map[string]int)
+	for _, item := range items {
+		emb, ok := index[item.Word]
+		if !ok {
+			continue
+		}
+		c, ok := categories[item.Category]
+		if !ok {
+			c = len(categories)
+			categories[item.Category] = c
+		}
+		rows = append(rows, vector.Unit(emb.Vector))
+		labels = append(labels, c)
+	}
+	k := len(categories)
+	if len(rows) < k || k < 2 {
+		return Result{}, errors.Errorf("%s has %d words of %d categories in embeddings, at least 2 categories are required", name, len(rows), k)
+	}
+
+	rng := rand.New(rand.NewSource(opts.Seed))
+	var best []int
+	bestScore := -1.
+	for r := 0; r < opts.Restarts; r++ {
+		perm := rng.Perm(len(rows))
+		shuffled := make([][]float64, len(rows))
+		for i, p := range perm {
+			shuffled[i] = rows[p]
+		}
+		centroids := cluster.Spherical(shuffled, k, opts.Iter, rng, nil)
+		assign := cluster.Assign(centroids, rows)
+		var score float64
+		for i, c := range assign {
+			score += vector.Dot(centroids[c], rows[i])
+		}
+		if score > bestScore {
+			best, bestScore = assign, score
+		}
+	}
+
+	counts := make([][]int, k)
+	for c := range counts {
+		counts[c] = make([]int, k)
+	}
+	for i, c := range best {
+		counts[c][labels[i]]++
+	}
+	var majority int
+	for _, count := range counts {
+		var max int
+		for _, n := range count {
+			if n > max {
+				max = n
+			}
+		}
+		majority += max
+	}
+	return Result{
+		Name:     name,
+		Purity:   float64(majority) / float64(len(rows)),
+		Clusters: k,
+		Found:    len(rows),
+		Total:    len(items),
+	}, nil
+}
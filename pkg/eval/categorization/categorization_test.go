@@ -0,0 +1,61 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package categorization
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/embedding"
+)
+
+func TestLoad(t *testing.T) {
+	items, err := Load(strings.NewReader("# AP\napple\tfruit\nnew york\tcity\nparis city\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []Item{
+		{Word: "apple", Category: "fruit"},
+		{Word: "new york", Category: "city"},
+		{Word: "paris", Category: "city"},
+	}, items)
+
+	_, err = Load(strings.NewReader("apple\n"))
+	assert.Error(t, err)
+}
+
+func TestEvaluate(t *testing.T) {
+	embs := embedding.Embeddings{
+		{Word: "apple", Dim: 2, Vector: []float64{1, 0.1}},
+		{Word: "banana", Dim: 2, Vector: []float64{1, -0.1}},
+		{Word: "cherry", Dim: 2, Vector: []float64{0.2, 1}},
+		{Word: "paris", Dim: 2, Vector: []float64{-0.1, 1}},
+		{Word: "tokyo", Dim: 2, Vector: []float64{0.1, 1}},
+	}
+	items := []Item{
+		{Word: "apple", Category: "fruit"},
+		{Word: "banana", Category: "fruit"},
+		{Word: "cherry", Category: "fruit"},
+		{Word: "paris", Category: "city"},
+		{Word: "tokyo", Category: "city"},
+		{Word: "rome", Category: "city"},
+	}
+	res, err := Evaluate(embs, items, "test", DefaultOptions())
+	assert.NoError(t, err)
+	assert.Equal(t, Result{Name: "test", Purity: 0.8, Clusters: 2, Found: 5, Total: 6}, res)
+
+	_, err = Evaluate(embs, items[:3], "test", DefaultOptions())
+	assert.Error(t, err)
+}
@@ -0,0 +1,195 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package outlier is the outlier detection of Camacho-Collados and Navigli (2016), "Find the word that does not
+// belong: A Framework for an Intrinsic Evaluation of Word Vector Representations", e.g. the 8-8-8 dataset.
+package outlier
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/search"
+	"github.com/ynqa/wego/pkg/search/searchutil"
+	"github.com/ynqa/wego/pkg/util/fileutil"
+	"github.com/ynqa/wego/pkg/vector"
+)
+
+// Set is the words of Cluster in the category and its Outliers.
+type Set struct {
+	Name     string
+	Cluster  []string
+	Outliers []string
+}
+
+// Load reads the set of the words of the cluster line by line, then an empty line and the outliers line by line,
+// as the files of the 8-8-8 dataset. The words may be the phrases separated by spaces.
+func Load(r io.Reader, name string) (Set, error) {
+	set := Set{Name: name}
+	outliers := false
+	s := fileutil.NewScanner(r, bufio.ScanLines)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		switch {
+		case line == "":
+			outliers = outliers || len(set.Cluster) > 0
+		case outliers:
+			set.Outliers = append(set.Outliers, line)
+		default:
+			set.Cluster = append(set.Cluster, line)
+		}
+	}
+	if err := s.Err(); err != nil && err != io.EOF {
+		return Set{}, errors.Wrapf(err, "failed to scan")
+	}
+	if len(set.Cluster) < 2 || len(set.Outliers) == 0 {
+		return Set{}, errors.Errorf("%s must have at least 2 words of the cluster and 1 outlier separated by an empty line, got %d and %d",
+			name, len(set.Cluster), len(set.Outliers))
+	}
+	return set, nil
+}
+
+// Result is the detection of the outliers in the set. Found is the number of the outliers in the vectors, and the
+// words of the cluster not in the vectors are skipped. OPP is the mean of the outlier positions divided by the size
+// of the cluster, where the position is the number of the words scored below the outlier by the compactness, and
+// Accuracy is the fraction of the outliers at the last position, i.e. detected.
+type Result struct {
+	Name     string  `json:"name"`
+	Found    int     `json:"found"`
+	Total    int     `json:"total"`
+	OPP      float64 `json:"opp"`
+	Accuracy float64 `json:"accuracy"`
+}
+
+// Report is the results per set, and Overall over all outliers.
+type Report struct {
+	Sets    []Result `json:"sets"`
+	Overall Result   `json:"overall"`
+}
+
+// Write writes the report in format, search.Table or search.JSON.
+func (r Report) Write(w io.Writer, format search.Format) error {
+	switch format {
+	case search.Table:
+		writer := tablewriter.NewWriter(w)
+		writer.SetHeader([]string{"Set", "OPP", "Accuracy", "Found"})
+		writer.SetBorder(false)
+		for _, res := range append(r.Sets, r.Overall) {
+			writer.Append([]string{
+				res.Name,
+				fmt.Sprintf("%f", res.OPP),
+				fmt.Sprintf("%f", res.Accuracy),
+				fmt.Sprintf("%d/%d", res.Found, res.Total),
+			})
+		}
+		writer.Render()
+		return nil
+	case search.JSON:
+		if r.Sets == nil {
+			r.Sets = []Result{}
+		}
+		return json.NewEncoder(w).Encode(r)
+	default:
+		return errors.Errorf("invalid format: %s not in %s|%s", format, search.Table, search.JSON)
+	}
+}
+
+// Evaluate detects the outliers of the sets. The phrases are the mean of the word vectors as Searcher.Vector.
+func Evaluate(embs embedding.Embeddings, sets []Set) (Report, error) {
+	searcher, err := search.New(embs...)
+	if err != nil {
+		return Report{}, err
+	}
+	lookup := func(words []string) [][]float64 {
+		var res [][]float64
+		for _, word := range words {
+			if vec, err := searcher.Vector(word); err == nil {
+				res = append(res, vec)
+			}
+		}
+		return res
+	}
+
+	report := Report{Overall: Result{Name: "overall"}}
+	var opp, acc float64
+	for _, set := range sets {
+		res := Result{Name: set.Name, Total: len(set.Outliers)}
+		cluster := lookup(set.Cluster)
+		if len(cluster) >= 2 {
+			for _, outlier := range lookup(set.Outliers) {
+				pos := position(append(cluster[:len(cluster):len(cluster)], outlier))
+				res.Found++
+				res.OPP += float64(pos) / float64(len(cluster))
+				if pos == len(cluster) {
+					res.Accuracy++
+				}
+			}
+		}
+		report.Overall.Total += res.Total
+		report.Overall.Found += res.Found
+		opp += res.OPP
+		acc += res.Accuracy
+		if res.Found > 0 {
+			res.OPP /= float64(res.Found)
+			res.Accuracy /= float64(res.Found)
+		}
+		report.Sets = append(report.Sets, res)
+	}
+	if report.Overall.Found == 0 {
+		return Report{}, errors.Errorf("found no outliers of %d sets in embeddings", len(sets))
+	}
+	report.Overall.OPP = opp / float64(report.Overall.Found)
+	report.Overall.Accuracy = acc / float64(report.Overall.Found)
+	return report, nil
+}
+
+// position returns the number of the words scored below the last word by the compactness, where the score of the
+// word is the mean similarity between the pairs of the other words.
+func position(vecs [][]float64) int {
+	n := len(vecs)
+	sims := make([][]float64, n)
+	for i := range sims {
+		sims[i] = make([]float64, n)
+	}
+	var total float64
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			sim := searchutil.Cosine(vecs[i], vecs[j], vector.Norm(vecs[i]), vector.Norm(vecs[j]))
+			sims[i][j], sims[j][i] = sim, sim
+			total += sim
+		}
+	}
+	scores := make([]float64, n)
+	for i := range scores {
+		rest := total
+		for j := range sims[i] {
+			rest -= sims[i][j]
+		}
+		scores[i] = rest / float64((n-1)*(n-2)/2)
+	}
+	var pos int
+	for _, score := range scores[:n-1] {
+		if score < scores[n-1] {
+			pos++
+		}
+	}
+	return pos
+}
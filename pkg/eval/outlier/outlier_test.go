@@ -0,0 +1,63 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package outlier
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/embedding"
+)
+
+func TestLoad(t *testing.T) {
+	set, err := Load(strings.NewReader("apple\nbanana\ncherry\n\nparis\nnew york\n"), "fruits")
+	assert.NoError(t, err)
+	assert.Equal(t, Set{
+		Name:     "fruits",
+		Cluster:  []string{"apple", "banana", "cherry"},
+		Outliers: []string{"paris", "new york"},
+	}, set)
+
+	_, err = Load(strings.NewReader("apple\nbanana\n"), "fruits")
+	assert.Error(t, err)
+}
+
+func TestEvaluate(t *testing.T) {
+	embs := embedding.Embeddings{
+		{Word: "apple", Dim: 2, Vector: []float64{1, 0.1}},
+		{Word: "banana", Dim: 2, Vector: []float64{1, 0}},
+		{Word: "cherry", Dim: 2, Vector: []float64{1, -0.1}},
+		{Word: "paris", Dim: 2, Vector: []float64{0, 1}},
+		{Word: "new", Dim: 2, Vector: []float64{1, 0.05}},
+		{Word: "york", Dim: 2, Vector: []float64{1, -0.05}},
+	}
+	sets := []Set{
+		{Name: "fruits", Cluster: []string{"apple", "banana", "cherry", "durian"}, Outliers: []string{"paris", "new york", "tokyo"}},
+	}
+	report, err := Evaluate(embs, sets)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.Sets[0].Found)
+	assert.Equal(t, 3, report.Sets[0].Total)
+	// paris is detected at the position 3 of 3, and new york is the mean of new and york as banana,
+	// so no words are scored below it.
+	assert.Equal(t, 0.5, report.Sets[0].Accuracy)
+	assert.InDelta(t, 0.5, report.Sets[0].OPP, 1e-9)
+	assert.Equal(t, report.Sets[0].OPP, report.Overall.OPP)
+
+	_, err = Evaluate(embs, []Set{{Name: "none", Cluster: []string{"apple", "banana"}, Outliers: []string{"tokyo"}}})
+	assert.Error(t, err)
+}
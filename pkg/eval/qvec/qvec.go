@@ -0,0 +1,210 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package qvec is QVEC of Tsvetkov et al. (2015), "Evaluation of Word Vector Representations by Subspace Alignment",
+// and QVEC-CCA of Tsvetkov et al. (2016), "Correlation-based Intrinsic Evaluation of Word Vector Representations",
+// which correlate the dimensions of the word vectors with the linguistic features, e.g. the supersenses of SemCor.
+package qvec
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/embutil"
+	"github.com/ynqa/wego/pkg/eval/similarity"
+	"github.com/ynqa/wego/pkg/search"
+	"github.com/ynqa/wego/pkg/util/fileutil"
+)
+
+// Features are the linguistic features of the words, e.g. {"noun.time": 0.5, "noun.act": 0.5}.
+type Features map[string]map[string]float64
+
+// Load reads the lines of the word and its features, as `word feature:value feature:value ...` or
+// `word {"feature": value, ...}` of the oracle files of QVEC, separated by a tab or spaces.
+// The features which the word lacks are 0. Empty lines and lines starting with # are skipped.
+func Load(r io.Reader) (Features, error) {
+	features := make(Features)
+	s := fileutil.NewScanner(r, bufio.ScanLines)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.IndexAny(line, " \t")
+		if i < 0 {
+			return nil, errors.Errorf("line %d must be the word and its features, got %q", n, line)
+		}
+		word, rest := line[:i], strings.TrimSpace(line[i:])
+		feats := make(map[string]float64)
+		if strings.HasPrefix(rest, "{") {
+			if err := json.Unmarshal([]byte(strings.Replace(rest, "'", `"`, -1)), &feats); err != nil {
+				return nil, errors.Wrapf(err, "failed to parse the features on line %d", n)
+			}
+		} else {
+			for _, field := range strings.Fields(rest) {
+				j := strings.LastIndex(field, ":")
+				if j < 0 {
+					return nil, errors.Errorf("feature of line %d must be `feature:value`, got %q", n, field)
+				}
+				v, err := strconv.ParseFloat(field[j+1:], 64)
+				if err != nil {
+					return nil, errors.Wrapf(err, "failed to parse the feature on line %d", n)
+				}
+				feats[field[:j]] = v
+			}
+		}
+		features[word] = feats
+	}
+	if err := s.Err(); err != nil && err != io.EOF {
+		return nil, errors.Wrapf(err, "failed to scan")
+	}
+	return features, nil
+}
+
+// Alignment is the dimension of the vectors aligned to the feature of the largest correlation.
+type Alignment struct {
+	Dim         int     `json:"dim"`
+	Feature     string  `json:"feature"`
+	Correlation float64 `json:"correlation"`
+}
+
+// Report is QVEC, the sum of the correlations of the alignments over the dimensions, and QVEC-CCA,
+// the first canonical correlation between the vectors and the features, over the Found words.
+// Alignments are in descending order of the correlations.
+type Report struct {
+	QVEC       float64     `json:"qvec"`
+	CCA        float64     `json:"qvec_cca"`
+	Found      int         `json:"found"`
+	Total      int         `json:"total"`
+	Alignments []Alignment `json:"alignments"`
+}
+
+// Write writes the report and the top alignments in format, search.Table or search.JSON.
+func (r Report) Write(w io.Writer, format search.Format, top int) error {
+	if top < len(r.Alignments) {
+		r.Alignments = r.Alignments[:top]
+	}
+	switch format {
+	case search.Table:
+		writer := tablewriter.NewWriter(w)
+		writer.SetHeader([]string{"Metric", "Value"})
+		writer.SetBorder(false)
+		writer.AppendBulk([][]string{
+			{"qvec", fmt.Sprintf("%f", r.QVEC)},
+			{"qvec-cca", fmt.Sprintf("%f", r.CCA)},
+			{"found", fmt.Sprintf("%d/%d", r.Found, r.Total)},
+		})
+		writer.Render()
+		if len(r.Alignments) == 0 {
+			return nil
+		}
+		fmt.Fprintln(w)
+
+		writer = tablewriter.NewWriter(w)
+		writer.SetHeader([]string{"Dim", "Feature", "Correlation"})
+		writer.SetBorder(false)
+		for _, a := range r.Alignments {
+			writer.Append([]string{
+				fmt.Sprintf("%d", a.Dim),
+				a.Feature,
+				fmt.Sprintf("%f", a.Correlation),
+			})
+		}
+		writer.Render()
+		return nil
+	case search.JSON:
+		if r.Alignments == nil {
+			r.Alignments = []Alignment{}
+		}
+		return json.NewEncoder(w).Encode(r)
+	default:
+		return errors.Errorf("invalid format: %s not in %s|%s", format, search.Table, search.JSON)
+	}
+}
+
+// Evaluate correlates the vectors of the words with their features.
+func Evaluate(embs embedding.Embeddings, features Features) (Report, error) {
+	var names []string
+	seen := make(map[string]bool)
+	for _, feats := range features {
+		for name := range feats {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	// x and y are the vectors and the features of the found words.
+	var x, y [][]float64
+	found := make(map[string]bool)
+	for _, emb := range embs {
+		feats, ok := features[emb.Word]
+		if !ok || found[emb.Word] {
+			continue
+		}
+		found[emb.Word] = true
+		row := make([]float64, len(names))
+		for j, name := range names {
+			row[j] = feats[name]
+		}
+		x = append(x, emb.Vector)
+		y = append(y, row)
+	}
+	if len(x) < 2 || len(names) == 0 {
+		return Report{}, errors.Errorf("found %d words of %d features in embeddings, at least 2 words are required", len(x), len(names))
+	}
+
+	report := Report{
+		CCA:   embutil.CCA(x, y),
+		Found: len(x),
+		Total: len(features),
+	}
+	cols := func(rows [][]float64, j int) []float64 {
+		col := make([]float64, len(rows))
+		for i, row := range rows {
+			col[i] = row[j]
+		}
+		return col
+	}
+	featCols := make([][]float64, len(names))
+	for j := range names {
+		featCols[j] = cols(y, j)
+	}
+	for d := range x[0] {
+		dim := cols(x, d)
+		best := Alignment{Dim: d, Correlation: -1}
+		for j, name := range names {
+			if r := similarity.Pearson(dim, featCols[j]); r > best.Correlation {
+				best.Feature, best.Correlation = name, r
+			}
+		}
+		report.QVEC += best.Correlation
+		report.Alignments = append(report.Alignments, best)
+	}
+	sort.SliceStable(report.Alignments, func(i, j int) bool {
+		return report.Alignments[i].Correlation > report.Alignments[j].Correlation
+	})
+	return report, nil
+}
@@ -0,0 +1,69 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package qvec
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/search"
+)
+
+func TestLoad(t *testing.T) {
+	features, err := Load(strings.NewReader("# supersenses\nday\tnoun.time:1\nrun verb.motion:0.5 verb.competition:0.5\nwalk\t{'verb.motion': 1.0}\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, Features{
+		"day":  {"noun.time": 1},
+		"run":  {"verb.motion": 0.5, "verb.competition": 0.5},
+		"walk": {"verb.motion": 1},
+	}, features)
+
+	_, err = Load(strings.NewReader("day noun.time\n"))
+	assert.Error(t, err)
+}
+
+func TestEvaluate(t *testing.T) {
+	embs := embedding.Embeddings{
+		{Word: "day", Dim: 2, Vector: []float64{1, 0.2}},
+		{Word: "week", Dim: 2, Vector: []float64{0.9, -0.1}},
+		{Word: "run", Dim: 2, Vector: []float64{0.1, 1}},
+		{Word: "walk", Dim: 2, Vector: []float64{-0.1, 0.8}},
+	}
+	features := Features{
+		"day":   {"noun.time": 1},
+		"week":  {"noun.time": 1},
+		"run":   {"verb.motion": 1},
+		"walk":  {"verb.motion": 1},
+		"apple": {"noun.food": 1},
+	}
+	report, err := Evaluate(embs, features)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, report.Found)
+	assert.Equal(t, 5, report.Total)
+	assert.Len(t, report.Alignments, 2)
+	assert.Equal(t, "noun.time", report.Alignments[0].Feature)
+	assert.Equal(t, 0, report.Alignments[0].Dim)
+	assert.Equal(t, "verb.motion", report.Alignments[1].Feature)
+	assert.InDelta(t, report.Alignments[0].Correlation+report.Alignments[1].Correlation, report.QVEC, 1e-9)
+	assert.True(t, report.CCA > 0.95)
+
+	var buf bytes.Buffer
+	assert.NoError(t, report.Write(&buf, search.JSON, 1))
+	assert.Contains(t, buf.String(), `"alignments":[{"dim":0,"feature":"noun.time"`)
+}
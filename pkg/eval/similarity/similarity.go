@@ -0,0 +1,149 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package similarity
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/search/searchutil"
+	"github.com/ynqa/wego/pkg/util/fileutil"
+)
+
+// Pair is a pair of words with the similarity score annotated by human, e.g. WordSim353.
+type Pair struct {
+	Word1, Word2 string
+	Score        float64
+}
+
+// Load reads the lines of `word1 word2 score`. Empty lines and lines starting with # are skipped.
+func Load(r io.Reader) ([]Pair, error) {
+	var pairs []Pair
+	s := fileutil.NewScanner(r, bufio.ScanLines)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return nil, errors.Errorf("line %d must be `word1 word2 score`, got %q", n, line)
+		}
+		score, err := strconv.ParseFloat(fields[2], 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse score on line %d", n)
+		}
+		pairs = append(pairs, Pair{
+			Word1: fields[0],
+			Word2: fields[1],
+			Score: score,
+		})
+	}
+	if err := s.Err(); err != nil && err != io.EOF {
+		return nil, errors.Wrapf(err, "failed to scan")
+	}
+	return pairs, nil
+}
+
+// Report is the result of evaluation.
+// Spearman is the rank correlation between cosine similarities and human scores over the found pairs.
+type Report struct {
+	Spearman float64
+	Found    int
+	Total    int
+}
+
+func Evaluate(embs embedding.Embeddings, pairs []Pair) (Report, error) {
+	index := make(map[string]embedding.Embedding, len(embs))
+	for _, emb := range embs {
+		index[emb.Word] = emb
+	}
+
+	var human, model []float64
+	for _, p := range pairs {
+		e1, ok1 := index[p.Word1]
+		e2, ok2 := index[p.Word2]
+		if !ok1 || !ok2 {
+			continue
+		}
+		human = append(human, p.Score)
+		model = append(model, searchutil.Cosine(e1.Vector, e2.Vector, e1.Norm, e2.Norm))
+	}
+	if len(human) < 2 {
+		return Report{}, errors.Errorf("found only %d pairs out of %d in embeddings", len(human), len(pairs))
+	}
+	return Report{
+		Spearman: Spearman(human, model),
+		Found:    len(human),
+		Total:    len(pairs),
+	}, nil
+}
+
+// Spearman returns the rank correlation coefficient, ties are assigned the average rank.
+func Spearman(x, y []float64) float64 {
+	return Pearson(rank(x), rank(y))
+}
+
+func rank(v []float64) []float64 {
+	idx := make([]int, len(v))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool {
+		return v[idx[i]] < v[idx[j]]
+	})
+	res := make([]float64, len(v))
+	for i := 0; i < len(idx); {
+		j := i
+		for j+1 < len(idx) && v[idx[j+1]] == v[idx[i]] {
+			j++
+		}
+		r := float64(i+j)/2 + 1
+		for k := i; k <= j; k++ {
+			res[idx[k]] = r
+		}
+		i = j + 1
+	}
+	return res
+}
+
+// Pearson returns the correlation coefficient, or 0 if either is constant.
+func Pearson(x, y []float64) float64 {
+	var mx, my float64
+	for i := range x {
+		mx += x[i]
+		my += y[i]
+	}
+	mx /= float64(len(x))
+	my /= float64(len(y))
+	var cov, vx, vy float64
+	for i := range x {
+		dx, dy := x[i]-mx, y[i]-my
+		cov += dx * dy
+		vx += dx * dx
+		vy += dy * dy
+	}
+	if vx == 0 || vy == 0 {
+		return 0
+	}
+	return cov / math.Sqrt(vx*vy)
+}
@@ -0,0 +1,73 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package similarity
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/embedding"
+)
+
+func TestSpearman(t *testing.T) {
+	testCases := []struct {
+		name   string
+		x, y   []float64
+		expect float64
+	}{
+		{
+			name:   "same order",
+			x:      []float64{1, 2, 3, 4},
+			y:      []float64{10, 20, 30, 400},
+			expect: 1,
+		},
+		{
+			name:   "reverse order",
+			x:      []float64{1, 2, 3, 4},
+			y:      []float64{4, 3, 2, 1},
+			expect: -1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.InDelta(t, tc.expect, Spearman(tc.x, tc.y), 1e-9)
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	embs, err := embedding.Load(strings.NewReader(`apple 1 1 0
+banana 1 0.9 0
+car 0 0 1
+train 0 0.1 1`))
+	assert.NoError(t, err)
+
+	pairs, err := Load(strings.NewReader(`# comment
+apple banana 9.0
+apple car 1.0
+car train 8.5
+apple unknown 5.0`))
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(pairs))
+
+	report, err := Evaluate(embs, pairs)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, report.Found)
+	assert.Equal(t, 4, report.Total)
+	assert.InDelta(t, 1., report.Spearman, 1e-9)
+}
@@ -0,0 +1,373 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stability
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/search"
+	"github.com/ynqa/wego/pkg/search/searchutil"
+	"github.com/ynqa/wego/pkg/sweep"
+	"github.com/ynqa/wego/pkg/util/fileutil"
+)
+
+var (
+	defaultChunk     = 1000
+	defaultFraction  = 0.8
+	defaultK         = 10
+	defaultParallel  = 1
+	defaultRuns      = 5
+	defaultSample    = 2000
+	defaultSeed      = int64(1)
+	defaultThreshold = 0.3
+	defaultTop       = 20
+)
+
+type Options struct {
+	// Chunk is the number of words of the documents to subsample, the longer lines are split into chunks.
+	Chunk int
+	// Fraction is the probability to keep each document in the subsample of a run.
+	Fraction float64
+	K        int
+	Parallel int
+	Runs     int
+	Sample   int
+	Seed     int64
+	// Threshold is the stability under which the words and the frequency bands are flagged as unstable.
+	Threshold float64
+	Top       int
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Chunk:     defaultChunk,
+		Fraction:  defaultFraction,
+		K:         defaultK,
+		Parallel:  defaultParallel,
+		Runs:      defaultRuns,
+		Sample:    defaultSample,
+		Seed:      defaultSeed,
+		Threshold: defaultThreshold,
+		Top:       defaultTop,
+	}
+}
+
+// TrainFunc trains the model with seed on the corpus, and returns the word vectors in the order of frequency.
+type TrainFunc func(ctx context.Context, seed int64, corpus io.Reader) (embedding.Embeddings, error)
+
+// Word is the stability of the word, the mean Jaccard overlap of its k nearest neighbors between all pairs of runs.
+type Word struct {
+	Word      string  `json:"word"`
+	Rank      int     `json:"rank"`
+	Stability float64 `json:"stability"`
+}
+
+// Band is the mean stability of the sampled words whose frequency ranks are in [From, To].
+type Band struct {
+	From      int     `json:"from"`
+	To        int     `json:"to"`
+	Words     int     `json:"words"`
+	Stability float64 `json:"stability"`
+	Unstable  bool    `json:"unstable"`
+}
+
+// Report is the stability over the vocabulary shared by all runs, where the rank is in the first run.
+// Unstable is the fraction of the sampled words under the threshold, and Least is the Top least stable words.
+type Report struct {
+	Runs      int     `json:"runs"`
+	Vocab     int     `json:"vocab"`
+	Sampled   int     `json:"sampled"`
+	Stability float64 `json:"stability"`
+	Unstable  float64 `json:"unstable"`
+	Bands     []Band  `json:"bands"`
+	Least     []Word  `json:"least"`
+}
+
+// Write writes the report in format, search.Table or search.JSON.
+func (r Report) Write(w io.Writer, format search.Format) error {
+	switch format {
+	case search.Table:
+		writer := tablewriter.NewWriter(w)
+		writer.SetHeader([]string{"Metric", "Value"})
+		writer.SetBorder(false)
+		writer.AppendBulk([][]string{
+			{"runs", fmt.Sprintf("%d", r.Runs)},
+			{"shared vocab", fmt.Sprintf("%d", r.Vocab)},
+			{"sampled", fmt.Sprintf("%d", r.Sampled)},
+			{"stability", fmt.Sprintf("%f", r.Stability)},
+			{"unstable", fmt.Sprintf("%f", r.Unstable)},
+		})
+		writer.Render()
+		fmt.Fprintln(w)
+
+		writer = tablewriter.NewWriter(w)
+		writer.SetHeader([]string{"Rank", "Words", "Stability", "Unstable"})
+		writer.SetBorder(false)
+		for _, b := range r.Bands {
+			writer.Append([]string{
+				fmt.Sprintf("%d-%d", b.From, b.To),
+				fmt.Sprintf("%d", b.Words),
+				fmt.Sprintf("%f", b.Stability),
+				fmt.Sprintf("%t", b.Unstable),
+			})
+		}
+		writer.Render()
+		fmt.Fprintln(w)
+
+		writer = tablewriter.NewWriter(w)
+		writer.SetHeader([]string{"Word", "Rank", "Stability"})
+		writer.SetBorder(false)
+		for _, word := range r.Least {
+			writer.Append([]string{
+				word.Word,
+				fmt.Sprintf("%d", word.Rank),
+				fmt.Sprintf("%f", word.Stability),
+			})
+		}
+		writer.Render()
+		return nil
+	case search.JSON:
+		if r.Bands == nil {
+			r.Bands = []Band{}
+		}
+		if r.Least == nil {
+			r.Least = []Word{}
+		}
+		return json.NewEncoder(w).Encode(r)
+	default:
+		return errors.Errorf("invalid format: %s not in %s|%s", format, search.Table, search.JSON)
+	}
+}
+
+func (opts Options) validate() error {
+	if opts.Runs < 2 {
+		return errors.Errorf("runs must be >= 2, got %d", opts.Runs)
+	} else if opts.Fraction <= 0 || opts.Fraction > 1 {
+		return errors.Errorf("fraction must be in (0, 1], got %v", opts.Fraction)
+	} else if opts.K <= 0 || opts.Chunk <= 0 {
+		return errors.Errorf("k and chunk must be positive, got %d, %d", opts.K, opts.Chunk)
+	} else if opts.Sample < 0 || opts.Top < 0 {
+		return errors.Errorf("sample and top must be >= 0, got %d, %d", opts.Sample, opts.Top)
+	}
+	return nil
+}
+
+// Evaluate trains the models by train on the subsamples of the documents in r with the different seeds,
+// by sweep.Run with the runs as the trials, and reports the stability of the nearest neighbors of the words.
+func Evaluate(ctx context.Context, r io.Reader, train TrainFunc, opts Options) (Report, error) {
+	if err := opts.validate(); err != nil {
+		return Report{}, err
+	}
+	docs, err := documents(r, opts.Chunk)
+	if err != nil {
+		return Report{}, err
+	}
+	if len(docs) == 0 {
+		return Report{}, errors.New("corpus is empty")
+	}
+
+	trials := make([]sweep.Trial, opts.Runs)
+	for i := range trials {
+		trials[i] = sweep.Trial{
+			ID:     i,
+			Params: map[string]string{"seed": fmt.Sprintf("%d", opts.Seed+int64(i))},
+		}
+	}
+	runs := make([]embedding.Embeddings, opts.Runs)
+	results := sweep.Run(ctx, trials, opts.Parallel, func(ctx context.Context, trial sweep.Trial) (map[string]float64, error) {
+		seed := opts.Seed + int64(trial.ID)
+		embs, err := train(ctx, seed, subsample(docs, opts.Fraction, rand.New(rand.NewSource(seed))))
+		if err != nil {
+			return nil, err
+		}
+		runs[trial.ID] = embs
+		return map[string]float64{"vocab": float64(len(embs))}, nil
+	})
+	for _, res := range results {
+		if res.Err != nil {
+			return Report{}, errors.Wrapf(res.Err, "failed to train run %d", res.Trial.ID)
+		}
+	}
+	return compare(runs, opts)
+}
+
+// documents splits the lines of r into the documents of at most chunk words.
+func documents(r io.Reader, chunk int) ([]string, error) {
+	var docs []string
+	s := fileutil.NewScanner(r, bufio.ScanLines)
+	for s.Scan() {
+		words := strings.Fields(s.Text())
+		for i := 0; i < len(words); i += chunk {
+			e := i + chunk
+			if e > len(words) {
+				e = len(words)
+			}
+			docs = append(docs, strings.Join(words[i:e], " "))
+		}
+	}
+	if err := s.Err(); err != nil && err != io.EOF {
+		return nil, errors.Wrapf(err, "failed to scan")
+	}
+	return docs, nil
+}
+
+func subsample(docs []string, fraction float64, rng *rand.Rand) io.Reader {
+	var b strings.Builder
+	for _, doc := range docs {
+		if rng.Float64() < fraction {
+			b.WriteString(doc)
+			b.WriteByte('\n')
+		}
+	}
+	return strings.NewReader(b.String())
+}
+
+// compare reports the stability of the words in all runs.
+func compare(runs []embedding.Embeddings, opts Options) (Report, error) {
+	counts := make(map[string]int)
+	for _, embs := range runs {
+		for _, emb := range embs {
+			counts[emb.Word]++
+		}
+	}
+	var words []string
+	for _, emb := range runs[0] {
+		if counts[emb.Word] == len(runs) {
+			words = append(words, emb.Word)
+		}
+	}
+	if len(words) < 2 {
+		return Report{}, errors.Errorf("%d words are shared by all runs, lower min-count or raise fraction", len(words))
+	}
+
+	// rows[r] are the unit vectors of the shared words in run r.
+	rows := make([][][]float64, len(runs))
+	for r, embs := range runs {
+		index := make(map[string]embedding.Embedding, len(embs))
+		for _, emb := range embs {
+			index[emb.Word] = emb
+		}
+		rows[r] = make([][]float64, len(words))
+		for i, word := range words {
+			emb := index[word]
+			vec := make([]float64, emb.Dim)
+			if emb.Norm != 0 {
+				for j, v := range emb.Vector {
+					vec[j] = v / emb.Norm
+				}
+			}
+			rows[r][i] = vec
+		}
+	}
+
+	sample := make([]int, len(words))
+	for i := range sample {
+		sample[i] = i
+	}
+	if opts.Sample > 0 && opts.Sample < len(words) {
+		sample = rand.New(rand.NewSource(opts.Seed)).Perm(len(words))[:opts.Sample]
+		sort.Ints(sample)
+	}
+	k := opts.K
+	if k > len(words)-1 {
+		k = len(words) - 1
+	}
+
+	stabilities := make([]float64, len(sample))
+	threads := runtime.NumCPU()
+	wg := &sync.WaitGroup{}
+	for t := 0; t < threads; t++ {
+		wg.Add(1)
+		go func(t int) {
+			defer wg.Done()
+			knn := make([][]int, len(runs))
+			for n := t; n < len(sample); n += threads {
+				for r := range runs {
+					knn[r] = searchutil.Nearest(rows[r], sample[n], k)
+				}
+				var sum float64
+				var pairs int
+				for a := 0; a < len(runs); a++ {
+					for b := a + 1; b < len(runs); b++ {
+						sum += searchutil.Jaccard(knn[a], knn[b])
+						pairs++
+					}
+				}
+				stabilities[n] = sum / float64(pairs)
+			}
+		}(t)
+	}
+	wg.Wait()
+
+	report := Report{
+		Runs:    len(runs),
+		Vocab:   len(words),
+		Sampled: len(sample),
+	}
+	results := make([]Word, len(sample))
+	for n, i := range sample {
+		results[n] = Word{Word: words[i], Rank: i + 1, Stability: stabilities[n]}
+		report.Stability += stabilities[n]
+		if stabilities[n] < opts.Threshold {
+			report.Unstable++
+		}
+	}
+	report.Stability /= float64(len(sample))
+	report.Unstable /= float64(len(sample))
+	report.Bands = bands(results, opts.Threshold)
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Stability < results[j].Stability
+	})
+	top := opts.Top
+	if top > len(results) {
+		top = len(results)
+	}
+	report.Least = results[:top]
+	return report, nil
+}
+
+// bands groups the words sorted by rank into the frequency bands of 1-10, 11-100, 101-1000, and so on.
+func bands(words []Word, threshold float64) []Band {
+	var res []Band
+	for from, to := 1, 10; len(words) > 0; from, to = to+1, to*10 {
+		band := Band{From: from, To: to}
+		for len(words) > 0 && words[0].Rank <= to {
+			band.Words++
+			band.Stability += words[0].Stability
+			words = words[1:]
+		}
+		if band.Words == 0 {
+			continue
+		}
+		band.Stability /= float64(band.Words)
+		band.Unstable = band.Stability < threshold
+		res = append(res, band)
+	}
+	return res
+}
@@ -0,0 +1,102 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stability
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/embutil"
+)
+
+// fakeTrain returns the fixed vectors except the last word, which is random by seed.
+func fakeTrain(_ context.Context, seed int64, corpus io.Reader) (embedding.Embeddings, error) {
+	if _, err := ioutil.ReadAll(corpus); err != nil {
+		return nil, err
+	}
+	vecs := [][]float64{{1, 0}, {0.9, 0.1}, {0, 1}, {0.1, 0.9}, {-1, 0}, {-0.9, -0.1}}
+	rng := rand.New(rand.NewSource(seed))
+	vecs = append(vecs, []float64{rng.NormFloat64(), rng.NormFloat64()})
+	embs := make(embedding.Embeddings, len(vecs))
+	for i, vec := range vecs {
+		embs[i] = embedding.Embedding{
+			Word:   string(rune('a' + i)),
+			Dim:    2,
+			Vector: vec,
+			Norm:   embutil.Norm(vec),
+		}
+	}
+	return embs, nil
+}
+
+func TestEvaluate(t *testing.T) {
+	opts := DefaultOptions()
+	opts.K = 1
+	opts.Runs = 4
+	opts.Parallel = 2
+	opts.Top = 1
+	report, err := Evaluate(context.Background(), strings.NewReader("a b c\nd e f g\n"), fakeTrain, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, report.Runs)
+	assert.Equal(t, 7, report.Vocab)
+	assert.Equal(t, 7, report.Sampled)
+	assert.Len(t, report.Least, 1)
+	assert.Equal(t, "g", report.Least[0].Word)
+	assert.Equal(t, 7, report.Least[0].Rank)
+	assert.True(t, report.Least[0].Stability < 1)
+	assert.Equal(t, []Band{{From: 1, To: 10, Words: 7, Stability: report.Stability, Unstable: report.Stability < opts.Threshold}}, report.Bands)
+}
+
+func TestEvaluateError(t *testing.T) {
+	train := func(context.Context, int64, io.Reader) (embedding.Embeddings, error) {
+		return nil, errors.New("fail")
+	}
+	_, err := Evaluate(context.Background(), strings.NewReader("a b c"), train, DefaultOptions())
+	assert.Error(t, err)
+
+	opts := DefaultOptions()
+	opts.Runs = 1
+	_, err = Evaluate(context.Background(), strings.NewReader("a b c"), fakeTrain, opts)
+	assert.Error(t, err)
+
+	_, err = Evaluate(context.Background(), strings.NewReader(""), fakeTrain, DefaultOptions())
+	assert.Error(t, err)
+}
+
+func TestDocuments(t *testing.T) {
+	docs, err := documents(strings.NewReader("a b c d e\n\nf\n"), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a b", "c d", "e", "f"}, docs)
+}
+
+func TestBands(t *testing.T) {
+	words := []Word{
+		{Rank: 1, Stability: 1},
+		{Rank: 5, Stability: 0.5},
+		{Rank: 120, Stability: 0.1},
+	}
+	assert.Equal(t, []Band{
+		{From: 1, To: 10, Words: 2, Stability: 0.75},
+		{From: 101, To: 1000, Words: 1, Stability: 0.1, Unstable: true},
+	}, bands(words, 0.3))
+}
@@ -0,0 +1,70 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weat
+
+import (
+	"strings"
+)
+
+// Standard returns the tests from Caliskan et al. (2017), "Semantics derived automatically
+// from language corpora contain human-like biases", whose words are lowercased.
+func Standard() []Test {
+	return []Test{
+		{
+			Name: "flowers-insects/pleasant-unpleasant",
+			X:    words("aster clover hyacinth marigold poppy azalea crocus iris orchid rose bluebell daffodil lilac pansy tulip buttercup daisy lily peony violet carnation gladiola magnolia petunia zinnia"),
+			Y:    words("ant caterpillar flea locust spider bedbug centipede fly maggot tarantula bee cockroach gnat mosquito termite beetle cricket hornet moth wasp blackfly dragonfly horsefly roach weevil"),
+			A:    pleasant,
+			B:    unpleasant,
+		},
+		{
+			Name: "instruments-weapons/pleasant-unpleasant",
+			X:    words("bagpipe cello guitar lute trombone banjo clarinet harmonica mandolin trumpet bassoon drum harp oboe tuba bell fiddle harpsichord piano viola bongo flute horn saxophone violin"),
+			Y:    words("arrow club gun missile spear axe dagger harpoon pistol sword blade dynamite hatchet rifle tank bomb firearm knife shotgun teargas cannon grenade mace slingshot whip"),
+			A:    pleasant,
+			B:    unpleasant,
+		},
+		{
+			Name: "male-female-names/career-family",
+			X:    words("john paul mike kevin steve greg jeff bill"),
+			Y:    words("amy joan lisa sarah diana kate ann donna"),
+			A:    words("executive management professional corporation salary office business career"),
+			B:    words("home parents children family cousins marriage wedding relatives"),
+		},
+		{
+			Name: "math-arts/male-female",
+			X:    words("math algebra geometry calculus equations computation numbers addition"),
+			Y:    words("poetry art dance literature novel symphony drama sculpture"),
+			A:    words("male man boy brother he him his son"),
+			B:    words("female woman girl sister she her hers daughter"),
+		},
+		{
+			Name: "science-arts/male-female",
+			X:    words("science technology physics chemistry einstein nasa experiment astronomy"),
+			Y:    words("poetry art shakespeare dance literature novel symphony drama"),
+			A:    words("brother father uncle grandfather son he his him"),
+			B:    words("sister mother aunt grandmother daughter she hers her"),
+		},
+	}
+}
+
+var (
+	pleasant   = words("caress freedom health love peace cheer friend heaven loyal pleasure diamond gentle honest lucky rainbow diploma gift honor miracle sunrise family happy laughter paradise vacation")
+	unpleasant = words("abuse crash filth murder sickness accident death grief poison stink assault disaster hatred pollute tragedy divorce jail poverty ugly cancer kill rotten vomit agony prison")
+)
+
+func words(s string) []string {
+	return strings.Fields(s)
+}
@@ -0,0 +1,229 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weat
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/search/searchutil"
+	"github.com/ynqa/wego/pkg/util/fileutil"
+)
+
+// Test is the Word Embedding Association Test on whether the target words X and Y are
+// differently associated with the attribute words A and B.
+type Test struct {
+	Name string
+	X, Y []string
+	A, B []string
+}
+
+// Load reads the test from the lines of `<set>: word1 word2 ...` where set is one of X, Y, A, B.
+// The words of the same set may span several lines. Empty lines and lines starting with # are skipped.
+func Load(r io.Reader, name string) (Test, error) {
+	test := Test{Name: name}
+	sets := map[string]*[]string{
+		"X": &test.X,
+		"Y": &test.Y,
+		"A": &test.A,
+		"B": &test.B,
+	}
+	s := fileutil.NewScanner(r, bufio.ScanLines)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		kv := strings.SplitN(line, ":", 2)
+		set, ok := sets[strings.TrimSpace(kv[0])]
+		if len(kv) != 2 || !ok {
+			return Test{}, errors.Errorf("line %d must be `<X|Y|A|B>: word1 word2 ...`, got %q", n, line)
+		}
+		*set = append(*set, strings.Fields(kv[1])...)
+	}
+	if err := s.Err(); err != nil && err != io.EOF {
+		return Test{}, errors.Wrapf(err, "failed to scan")
+	}
+	for k, set := range sets {
+		if len(*set) == 0 {
+			return Test{}, errors.Errorf("%s of %s is empty", k, name)
+		}
+	}
+	return test, nil
+}
+
+var (
+	defaultPermutations = 10000
+	defaultSeed         = int64(1)
+)
+
+type Options struct {
+	Permutations int
+	Seed         int64
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Permutations: defaultPermutations,
+		Seed:         defaultSeed,
+	}
+}
+
+// Result is the result of the test.
+// EffectSize is Cohen's d of the association between X and Y, and PValue is the one-sided p-value
+// of the permutation test. Found and Total are the number of the words in the embeddings and the test.
+type Result struct {
+	Name       string
+	EffectSize float64
+	PValue     float64
+	Found      int
+	Total      int
+}
+
+type Results []Result
+
+func (results Results) Describe() {
+	table := make([][]string, len(results))
+	for i, r := range results {
+		table[i] = []string{
+			r.Name,
+			fmt.Sprintf("%f", r.EffectSize),
+			fmt.Sprintf("%f", r.PValue),
+			fmt.Sprintf("%d/%d", r.Found, r.Total),
+		}
+	}
+
+	writer := tablewriter.NewWriter(os.Stdout)
+	writer.SetHeader([]string{"Test", "Effect Size", "P-Value", "Found"})
+	writer.SetBorder(false)
+	writer.AppendBulk(table)
+	writer.Render()
+}
+
+// Evaluate runs the test, the words not in the embeddings are ignored.
+func Evaluate(embs embedding.Embeddings, test Test, opts Options) (Result, error) {
+	index := make(map[string]embedding.Embedding, len(embs))
+	for _, emb := range embs {
+		index[emb.Word] = emb
+	}
+	lookup := func(words []string) []embedding.Embedding {
+		var res []embedding.Embedding
+		for _, w := range words {
+			if emb, ok := index[w]; ok {
+				res = append(res, emb)
+			}
+		}
+		return res
+	}
+	x, y, a, b := lookup(test.X), lookup(test.Y), lookup(test.A), lookup(test.B)
+	if len(x) < 1 || len(y) < 1 || len(a) < 1 || len(b) < 1 {
+		return Result{}, errors.Errorf("%s has the set without words in embeddings: X=%d Y=%d A=%d B=%d",
+			test.Name, len(x), len(y), len(a), len(b))
+	}
+
+	// s holds the associations of X followed by Y.
+	s := make([]float64, 0, len(x)+len(y))
+	for _, set := range [][]embedding.Embedding{x, y} {
+		for _, w := range set {
+			s = append(s, association(w, a, b))
+		}
+	}
+	sx, sy := s[:len(x)], s[len(x):]
+
+	result := Result{
+		Name:       test.Name,
+		EffectSize: (mean(sx) - mean(sy)) / stddev(s),
+		PValue:     pvalue(s, len(x), opts),
+		Found:      len(x) + len(y) + len(a) + len(b),
+		Total:      len(test.X) + len(test.Y) + len(test.A) + len(test.B),
+	}
+	return result, nil
+}
+
+// association is s(w, A, B), the difference of the mean cosine similarities of w with A and B.
+func association(w embedding.Embedding, a, b []embedding.Embedding) float64 {
+	cos := func(set []embedding.Embedding) float64 {
+		var sum float64
+		for _, e := range set {
+			sum += searchutil.Cosine(w.Vector, e.Vector, w.Norm, e.Norm)
+		}
+		return sum / float64(len(set))
+	}
+	return cos(a) - cos(b)
+}
+
+// pvalue is the ratio of the random partitions of s into the size of X and Y whose statistic
+// exceeds the statistic on X and Y, where the statistic is the sum of X minus the sum of Y.
+func pvalue(s []float64, nx int, opts Options) float64 {
+	stat := func(v []float64) float64 {
+		var res float64
+		for i, a := range v {
+			if i < nx {
+				res += a
+			} else {
+				res -= a
+			}
+		}
+		return res
+	}
+	observed := stat(s)
+
+	rng := rand.New(rand.NewSource(opts.Seed))
+	perm := make([]float64, len(s))
+	copy(perm, s)
+	var exceeded int
+	for i := 0; i < opts.Permutations; i++ {
+		rng.Shuffle(len(perm), func(i, j int) {
+			perm[i], perm[j] = perm[j], perm[i]
+		})
+		if stat(perm) > observed {
+			exceeded++
+		}
+	}
+	if opts.Permutations <= 0 {
+		return math.NaN()
+	}
+	return float64(exceeded) / float64(opts.Permutations)
+}
+
+func mean(v []float64) float64 {
+	var sum float64
+	for _, a := range v {
+		sum += a
+	}
+	return sum / float64(len(v))
+}
+
+// stddev is the sample standard deviation.
+func stddev(v []float64) float64 {
+	if len(v) < 2 {
+		return math.NaN()
+	}
+	m := mean(v)
+	var sum float64
+	for _, a := range v {
+		sum += (a - m) * (a - m)
+	}
+	return math.Sqrt(sum / float64(len(v)-1))
+}
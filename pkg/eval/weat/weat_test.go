@@ -0,0 +1,94 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package weat
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/embutil"
+)
+
+func TestLoad(t *testing.T) {
+	test, err := Load(strings.NewReader(`
+# custom test
+X: rose tulip
+X: lily
+Y: ant
+A: love
+B: hate
+`), "custom")
+	assert.NoError(t, err)
+	assert.Equal(t, Test{
+		Name: "custom",
+		X:    []string{"rose", "tulip", "lily"},
+		Y:    []string{"ant"},
+		A:    []string{"love"},
+		B:    []string{"hate"},
+	}, test)
+
+	_, err = Load(strings.NewReader("X: rose\nZ: ant\n"), "invalid")
+	assert.Error(t, err)
+	_, err = Load(strings.NewReader("X: rose\nY: ant\nA: love\n"), "missing")
+	assert.Error(t, err)
+}
+
+func TestEvaluate(t *testing.T) {
+	vecs := map[string][]float64{
+		"rose":  {1, 0.1},
+		"tulip": {0.9, 0.2},
+		"ant":   {0.1, 1},
+		"wasp":  {0.2, 0.9},
+		"love":  {1, 0},
+		"hate":  {0, 1},
+	}
+	var embs embedding.Embeddings
+	for w, v := range vecs {
+		embs = append(embs, embedding.Embedding{
+			Word:   w,
+			Dim:    2,
+			Vector: v,
+			Norm:   embutil.Norm(v),
+		})
+	}
+	test := Test{
+		Name: "biased",
+		X:    []string{"rose", "tulip", "unknown"},
+		Y:    []string{"ant", "wasp"},
+		A:    []string{"love"},
+		B:    []string{"hate"},
+	}
+	res, err := Evaluate(embs, test, DefaultOptions())
+	assert.NoError(t, err)
+	assert.True(t, res.EffectSize > 1.5)
+	// no other partition of the 4 words exceeds the observed statistic.
+	assert.True(t, res.PValue < 0.05)
+	assert.Equal(t, 6, res.Found)
+	assert.Equal(t, 7, res.Total)
+
+	// swapping the attributes reverses the direction.
+	test.A, test.B = test.B, test.A
+	res, err = Evaluate(embs, test, DefaultOptions())
+	assert.NoError(t, err)
+	assert.True(t, res.EffectSize < -1.5)
+	assert.True(t, res.PValue > 0.5)
+
+	test.B = []string{"unknown"}
+	_, err = Evaluate(embs, test, DefaultOptions())
+	assert.Error(t, err)
+}
@@ -0,0 +1,116 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package golden
+
+import (
+	"bytes"
+	"context"
+	"os"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/eval/similarity"
+	"github.com/ynqa/wego/pkg/model"
+	"github.com/ynqa/wego/pkg/model/modelutil/vector"
+	"github.com/ynqa/wego/pkg/search/searchutil"
+)
+
+// Case is the training on the fixed corpus and seed whose output is compared with the golden output,
+// i.e. the previous output of wego in the text format of word vectors.
+type Case struct {
+	Name   string
+	New    func() (model.Model, error)
+	Corpus string
+	Golden string
+	// MinCorrelation is the tolerance for Compare.
+	MinCorrelation float64
+}
+
+// Train trains the model on the corpus and returns the word vectors.
+func (c Case) Train(ctx context.Context) (embedding.Embeddings, error) {
+	mod, err := c.New()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(c.Corpus)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if err := mod.Train(ctx, f); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := mod.Save(&buf, vector.Single); err != nil {
+		return nil, err
+	}
+	return embedding.Load(&buf)
+}
+
+// Check trains the model and compares the output with the golden output.
+func (c Case) Check(ctx context.Context) (float64, error) {
+	embs, err := c.Train(ctx)
+	if err != nil {
+		return 0, err
+	}
+	f, err := os.Open(c.Golden)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	ref, err := embedding.Load(f)
+	if err != nil {
+		return 0, err
+	}
+	corr, err := Compare(embs, ref)
+	if err != nil {
+		return 0, err
+	}
+	if corr < c.MinCorrelation {
+		return corr, errors.Errorf("%s drifts from %s: correlation %f < %f", c.Name, c.Golden, corr, c.MinCorrelation)
+	}
+	return corr, nil
+}
+
+// Compare returns the Spearman correlation between the cosine similarities of all pairs of the
+// words shared by embs and ref. The vectors of the different implementations are not comparable
+// element-wise because of the random initialization, but the similarities between words are.
+func Compare(embs, ref embedding.Embeddings) (float64, error) {
+	index := make(map[string]embedding.Embedding, len(ref))
+	for _, emb := range ref {
+		index[emb.Word] = emb
+	}
+	var shared [][2]embedding.Embedding
+	for _, emb := range embs {
+		if r, ok := index[emb.Word]; ok {
+			shared = append(shared, [2]embedding.Embedding{emb, r})
+		}
+	}
+	if len(shared) < 3 {
+		return 0, errors.Errorf("found only %d shared words", len(shared))
+	}
+
+	var x, y []float64
+	for i := 0; i < len(shared); i++ {
+		for j := i + 1; j < len(shared); j++ {
+			e1, e2 := shared[i][0], shared[j][0]
+			r1, r2 := shared[i][1], shared[j][1]
+			x = append(x, searchutil.Cosine(e1.Vector, e2.Vector, e1.Norm, e2.Norm))
+			y = append(y, searchutil.Cosine(r1.Vector, r2.Vector, r1.Norm, r2.Norm))
+		}
+	}
+	return similarity.Spearman(x, y), nil
+}
@@ -0,0 +1,131 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package golden
+
+import (
+	"context"
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/model"
+	"github.com/ynqa/wego/pkg/model/glove"
+	"github.com/ynqa/wego/pkg/model/word2vec"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+const corpus = "testdata/corpus.txt"
+
+func cases() []Case {
+	return []Case{
+		{
+			Name: "word2vec skipgram",
+			New: func() (model.Model, error) {
+				return word2vec.New(
+					word2vec.Dim(10),
+					word2vec.Goroutines(1),
+					word2vec.Iter(5),
+					word2vec.MinCount(1),
+					word2vec.Model(word2vec.SkipGram),
+					word2vec.Seed(1),
+				)
+			},
+			Corpus:         corpus,
+			Golden:         "testdata/word2vec_skipgram.txt",
+			MinCorrelation: 0.95,
+		},
+		{
+			Name: "word2vec cbow",
+			New: func() (model.Model, error) {
+				return word2vec.New(
+					word2vec.Dim(10),
+					word2vec.Goroutines(1),
+					word2vec.Iter(5),
+					word2vec.MinCount(1),
+					word2vec.Model(word2vec.Cbow),
+					word2vec.Seed(1),
+				)
+			},
+			Corpus:         corpus,
+			Golden:         "testdata/word2vec_cbow.txt",
+			MinCorrelation: 0.95,
+		},
+		{
+			Name: "glove",
+			New: func() (model.Model, error) {
+				return glove.New(
+					glove.Dim(10),
+					glove.Goroutines(1),
+					glove.Iter(10),
+					glove.MinCount(1),
+					glove.Seed(1),
+				)
+			},
+			Corpus:         corpus,
+			Golden:         "testdata/glove.txt",
+			MinCorrelation: 0.95,
+		},
+	}
+}
+
+func TestGolden(t *testing.T) {
+	for _, c := range cases() {
+		t.Run(c.Name, func(t *testing.T) {
+			if *update {
+				embs, err := c.Train(context.Background())
+				assert.NoError(t, err)
+				f, err := os.Create(c.Golden)
+				assert.NoError(t, err)
+				defer f.Close()
+				assert.NoError(t, embedding.Save(f, embs))
+				return
+			}
+			corr, err := c.Check(context.Background())
+			assert.NoError(t, err)
+			t.Logf("correlation: %f", corr)
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	f, err := os.Open("testdata/glove.txt")
+	assert.NoError(t, err)
+	defer f.Close()
+	ref, err := embedding.Load(f)
+	assert.NoError(t, err)
+
+	corr, err := Compare(ref, ref)
+	assert.NoError(t, err)
+	assert.InDelta(t, 1, corr, 1e-9)
+
+	// the drift of window size is caught.
+	c := cases()[2]
+	c.New = func() (model.Model, error) {
+		return glove.New(
+			glove.Dim(10),
+			glove.Goroutines(1),
+			glove.Iter(10),
+			glove.MinCount(1),
+			glove.Seed(1),
+			glove.Window(1),
+		)
+	}
+	_, err = c.Check(context.Background())
+	assert.Error(t, err)
+}
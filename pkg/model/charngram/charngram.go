@@ -0,0 +1,103 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package charngram
+
+import (
+	"bufio"
+	"context"
+	"io"
+
+	"github.com/ynqa/wego/pkg/corpus/cpsutil"
+	"github.com/ynqa/wego/pkg/model"
+	"github.com/ynqa/wego/pkg/model/modelutil/matrix"
+	"github.com/ynqa/wego/pkg/model/modelutil/vector"
+	"github.com/ynqa/wego/pkg/model/word2vec"
+)
+
+// charNgram trains the embeddings of character n-grams only,
+// by skip-gram over the stream which replaces each word with its n-grams.
+type charNgram struct {
+	opts Options
+
+	w2v model.Model
+}
+
+func New(opts ...ModelOption) (model.Model, error) {
+	options := DefaultOptions()
+	for _, fn := range opts {
+		fn(&options)
+	}
+
+	return NewForOptions(options)
+}
+
+func NewForOptions(opts Options) (model.Model, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	w2v, err := word2vec.NewForOptions(opts.Word2Vec)
+	if err != nil {
+		return nil, err
+	}
+	return &charNgram{
+		opts: opts,
+
+		w2v: w2v,
+	}, nil
+}
+
+func (c *charNgram) Options() interface{} {
+	return c.opts
+}
+
+func (c *charNgram) Train(ctx context.Context, r io.Reader) error {
+	rs, cleanup, err := cpsutil.ReadSeeker(r)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	pr, pw := io.Pipe()
+	go func() {
+		buf := bufio.NewWriter(pw)
+		err := cpsutil.ReadWord(rs, func(word string) error {
+			for _, ngram := range Ngrams(word, c.opts.MinN, c.opts.MaxN) {
+				buf.WriteString(ngram)
+				if err := buf.WriteByte(' '); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err == nil {
+			err = buf.Flush()
+		}
+		pw.CloseWithError(err)
+	}()
+	defer pr.Close()
+	return c.w2v.Train(ctx, pr)
+}
+
+func (c *charNgram) Save(f io.Writer, typ vector.Type) error {
+	return c.w2v.Save(f, typ)
+}
+
+func (c *charNgram) Close() error {
+	return model.Close(c.w2v)
+}
+
+func (c *charNgram) WordVector(typ vector.Type) *matrix.Matrix {
+	return c.w2v.WordVector(typ)
+}
@@ -0,0 +1,98 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package charngram
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/embedding"
+)
+
+const (
+	bow = "<"
+	eow = ">"
+)
+
+// Ngrams returns the character n-grams of the word wrapped by "<" and ">".
+func Ngrams(word string, minN, maxN int) []string {
+	runes := []rune(bow + word + eow)
+	var res []string
+	for n := minN; n <= maxN; n++ {
+		for i := 0; i+n <= len(runes); i++ {
+			res = append(res, string(runes[i:i+n]))
+		}
+	}
+	return res
+}
+
+// Encoder embeds arbitrary strings by averaging the vectors of their known n-grams.
+type Encoder struct {
+	vecs       map[string][]float64
+	dim        int
+	minN, maxN int
+}
+
+func NewEncoder(embs embedding.Embeddings, minN, maxN int) (*Encoder, error) {
+	if err := embs.Validate(); err != nil {
+		return nil, err
+	}
+	if embs.Empty() {
+		return nil, errors.New("no n-gram vectors")
+	}
+	vecs := make(map[string][]float64, len(embs))
+	for _, emb := range embs {
+		vecs[emb.Word] = emb.Vector
+	}
+	return &Encoder{
+		vecs: vecs,
+		dim:  embs[0].Dim,
+		minN: minN,
+		maxN: maxN,
+	}, nil
+}
+
+// LoadEncoder reads the n-gram vectors saved by the model.
+func LoadEncoder(r io.Reader, minN, maxN int) (*Encoder, error) {
+	embs, err := embedding.Load(r)
+	if err != nil {
+		return nil, err
+	}
+	return NewEncoder(embs, minN, maxN)
+}
+
+// Encode returns false if no n-gram of s is known.
+func (e *Encoder) Encode(s string) ([]float64, bool) {
+	res := make([]float64, e.dim)
+	var cnt int
+	for _, ngram := range Ngrams(s, e.minN, e.maxN) {
+		vec, ok := e.vecs[ngram]
+		if !ok {
+			continue
+		}
+		for i := range res {
+			res[i] += vec[i]
+		}
+		cnt++
+	}
+	if cnt == 0 {
+		return nil, false
+	}
+	for i := range res {
+		res[i] /= float64(cnt)
+	}
+	return res, true
+}
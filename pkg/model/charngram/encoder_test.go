@@ -0,0 +1,47 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package charngram
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/embedding"
+)
+
+func TestNgrams(t *testing.T) {
+	assert.Equal(t, []string{"<ab", "ab>", "<ab>"}, Ngrams("ab", 3, 4))
+	assert.Equal(t, []string{"<é", "é>"}, Ngrams("é", 2, 2))
+}
+
+func TestEncoder(t *testing.T) {
+	enc, err := NewEncoder(embedding.Embeddings{
+		{Word: "<ab", Dim: 2, Vector: []float64{1, 0}},
+		{Word: "ab>", Dim: 2, Vector: []float64{0, 1}},
+	}, 3, 3)
+	assert.NoError(t, err)
+
+	vec, ok := enc.Encode("ab")
+	assert.True(t, ok)
+	assert.Equal(t, []float64{0.5, 0.5}, vec)
+
+	vec, ok = enc.Encode("abc")
+	assert.True(t, ok)
+	assert.Equal(t, []float64{1, 0}, vec)
+
+	_, ok = enc.Encode("xyz")
+	assert.False(t, ok)
+}
@@ -0,0 +1,83 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package charngram
+
+import (
+	"github.com/ynqa/wego/pkg/model"
+	"github.com/ynqa/wego/pkg/model/word2vec"
+)
+
+var (
+	defaultMaxN = 5
+	defaultMinN = 3
+)
+
+type Options struct {
+	MaxN     int
+	MinN     int
+	Word2Vec word2vec.Options
+}
+
+func DefaultOptions() Options {
+	w2v := word2vec.DefaultOptions()
+	w2v.ModelType = word2vec.SkipGram
+	w2v.MinCount = 1
+	return Options{
+		MaxN:     defaultMaxN,
+		MinN:     defaultMinN,
+		Word2Vec: w2v,
+	}
+}
+
+// Validate reports all impossible options including the ones of Word2Vec at once.
+func (opts Options) Validate() error {
+	var e model.OptionsError
+	e.Require(0 < opts.MinN && opts.MinN <= opts.MaxN, "min-n", "n-gram range must be 0 < min-n <= max-n, got min-n=%d, max-n=%d", opts.MinN, opts.MaxN)
+	if err := opts.Word2Vec.Validate(); err != nil {
+		e = append(e, err.(model.OptionsError)...)
+	}
+	return e.Err()
+}
+
+type ModelOption func(*Options)
+
+// WithOptions replaces all options by opts, and the following ModelOptions override them.
+// New(WithOptions(opts)) is the same as NewForOptions(opts).
+func WithOptions(v Options) ModelOption {
+	return ModelOption(func(opts *Options) {
+		*opts = v
+	})
+}
+
+func MaxN(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.MaxN = v
+	})
+}
+
+func MinN(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.MinN = v
+	})
+}
+
+// Word2Vec applies the options for the underlying skip-gram model.
+func Word2Vec(fns ...word2vec.ModelOption) ModelOption {
+	return ModelOption(func(opts *Options) {
+		for _, fn := range fns {
+			fn(&opts.Word2Vec)
+		}
+	})
+}
@@ -0,0 +1,38 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/pkg/model/charngram"
+)
+
+// CharNGram binds opts to the flags of cmd, whose defaults are charngram.DefaultOptions().
+func CharNGram(cmd *cobra.Command, opts *charngram.Options) {
+	def := charngram.DefaultOptions()
+	cmd.Flags().IntVar(&opts.MaxN, "max-n", def.MaxN, "max length of character n-grams")
+	cmd.Flags().IntVar(&opts.MinN, "min-n", def.MinN, "min length of character n-grams")
+	Word2Vec(cmd, &opts.Word2Vec)
+	// n-grams are trained by skip-gram and rare n-grams still compose the strings.
+	for name, v := range map[string]string{
+		"model":     def.Word2Vec.ModelType,
+		"min-count": "1",
+	} {
+		f := cmd.Flags().Lookup(name)
+		f.Value.Set(v)
+		f.DefValue = v
+	}
+}
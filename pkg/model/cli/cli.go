@@ -0,0 +1,38 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cli binds the options of the models to the flags of cobra commands,
+// so that the packages of the models and the corpus don't depend on cobra.
+package cli
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/pkg/corpus"
+	"github.com/ynqa/wego/pkg/corpus/filter"
+)
+
+// Corpus binds opts to the flags of cmd, whose defaults are corpus.DefaultOptions().
+func Corpus(cmd *cobra.Command, opts *corpus.Options) {
+	def := corpus.DefaultOptions()
+	cmd.Flags().BoolVar(&opts.DocInMemory, "in-memory", def.DocInMemory, "whether to store the doc in memory")
+	cmd.Flags().BoolVar(&opts.ToLower, "lower", def.ToLower, "whether the words on corpus convert to lowercase or not")
+}
+
+// Filter binds opts to the flags of cmd, whose defaults are filter.DefaultOption().
+func Filter(cmd *cobra.Command, opts *filter.Options) {
+	def := filter.DefaultOption()
+	cmd.Flags().IntVar(&opts.MaxCount, "max-count", def.MaxCount, "upper limit to filter words")
+	cmd.Flags().IntVar(&opts.MinCount, "min-count", def.MinCount, "lower limit to filter words")
+}
@@ -0,0 +1,60 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/corpus"
+	"github.com/ynqa/wego/pkg/model/charngram"
+	"github.com/ynqa/wego/pkg/model/glove"
+	"github.com/ynqa/wego/pkg/model/lexvec"
+	"github.com/ynqa/wego/pkg/model/word2vec"
+)
+
+func TestDefaults(t *testing.T) {
+	var (
+		w2v word2vec.Options
+		gl  glove.Options
+		lv  lexvec.Options
+		cng charngram.Options
+		cps corpus.Options
+	)
+	for _, bind := range []func(*cobra.Command){
+		func(cmd *cobra.Command) { Word2Vec(cmd, &w2v) },
+		func(cmd *cobra.Command) { GloVe(cmd, &gl) },
+		func(cmd *cobra.Command) { LexVec(cmd, &lv) },
+		func(cmd *cobra.Command) { CharNGram(cmd, &cng) },
+		func(cmd *cobra.Command) { Corpus(cmd, &cps) },
+	} {
+		cmd := &cobra.Command{}
+		bind(cmd)
+		assert.NoError(t, cmd.ParseFlags(nil))
+	}
+	assert.Equal(t, word2vec.DefaultOptions(), w2v)
+	assert.Equal(t, glove.DefaultOptions(), gl)
+	assert.Equal(t, lexvec.DefaultOptions(), lv)
+	assert.Equal(t, charngram.DefaultOptions(), cng)
+	assert.Equal(t, corpus.DefaultOptions(), cps)
+
+	cmd := &cobra.Command{}
+	Word2Vec(cmd, &w2v)
+	assert.NoError(t, cmd.ParseFlags([]string{"--dim", "100", "--model", word2vec.SkipGram}))
+	assert.Equal(t, 100, w2v.Dim)
+	assert.Equal(t, word2vec.SkipGram, w2v.ModelType)
+}
@@ -0,0 +1,73 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	co "github.com/ynqa/wego/pkg/corpus/cooccurrence"
+	"github.com/ynqa/wego/pkg/corpus/counts"
+	"github.com/ynqa/wego/pkg/model/glove"
+	"github.com/ynqa/wego/pkg/model/modelutil/kernel"
+	"github.com/ynqa/wego/pkg/model/modelutil/matrix"
+	"github.com/ynqa/wego/pkg/model/modelutil/vector"
+	"github.com/ynqa/wego/pkg/model/modelutil/window"
+)
+
+// GloVe binds opts to the flags of cmd, whose defaults are glove.DefaultOptions().
+func GloVe(cmd *cobra.Command, opts *glove.Options) {
+	def := glove.DefaultOptions()
+	cmd.Flags().IntVar(&opts.AccumBatch, "accum-batch", def.AccumBatch, "number of the items to accumulate the gradients per row before applying them at once, which writes the frequent rows less often (0 means disabled)")
+	cmd.Flags().Float64Var(&opts.Alpha, "alpha", def.Alpha, "exponent of weighting function")
+	cmd.Flags().StringVar(&opts.Backend, "backend", def.Backend, fmt.Sprintf("backend to compute the dense updates. One of: %v (build with -tags=blas for %s)", kernel.Available(), kernel.BLAS))
+	cmd.Flags().BoolVar(&opts.Autotune, "autotune", def.Autotune, "whether to tune the number of the goroutines working at once from 1 up to --goroutines by the measured throughput")
+	cmd.Flags().IntVar(&opts.BatchSize, "batch", def.BatchSize, "batch size to train")
+	cmd.Flags().StringVar(&opts.CountType, "cnt", def.CountType, fmt.Sprintf("count type for co-occurrence words, %s weights by 1/distance and %s by (window-distance+1)/window. One of %s|%s|%s", co.Proximity, co.Linear, co.Increment, co.Proximity, co.Linear))
+	cmd.Flags().StringVar(&opts.Cooc, "cooc", def.Cooc, fmt.Sprintf("format of the input as the precomputed co-occurrence counts instead of the corpus, %s is the lines of word, context and count, %s is cooccurrence.bin of GloVe and %s is MatrixMarket, which may be compressed by gzip. One of %s|%s|%s", counts.Text, counts.Binary, counts.MatrixMarket, counts.Text, counts.Binary, counts.MatrixMarket))
+	cmd.Flags().StringVar(&opts.CoocVocab, "cooc-vocab", def.CoocVocab, "vocab file of the lines of word and count for --cooc, which gives the ids of --cooc=bin|mtx and the frequencies of the words (the sums of the counts without it)")
+	cmd.Flags().IntVarP(&opts.Dim, "dim", "d", def.Dim, "dimension for word vector")
+	cmd.Flags().IntVar(&opts.Goroutines, "goroutines", def.Goroutines, "number of goroutine")
+	cmd.Flags().IntVar(&opts.HashBuckets, "hash-buckets", def.HashBuckets, "number of buckets to hash words into instead of the exact dictionary, which bounds memory regardless of vocabulary size (0 means disabled)")
+	cmd.Flags().BoolVar(&opts.DocInMemory, "in-memory", def.DocInMemory, "whether to store the doc in memory")
+	cmd.Flags().Float64Var(&opts.Initlr, "initlr", def.Initlr, "initial learning rate")
+	cmd.Flags().IntVar(&opts.Iter, "iter", def.Iter, "number of iteration")
+	cmd.Flags().IntVar(&opts.LogBatch, "log-batch", def.LogBatch, "batch size to log for counting words")
+	cmd.Flags().Float64Var(&opts.LRFreqPower, "lr-freq-power", def.LRFreqPower, "power p to scale the learning rate of each word by (minimum frequency/frequency)^p, which damps the updates of frequent words (0 means disabled)")
+	cmd.Flags().StringVar(&opts.MatrixBacking, "matrix-backing", def.MatrixBacking, fmt.Sprintf("where to allocate the matrices of the parameters. One of: %s|%s:<dir>, which maps them to the files in dir for the models larger than RAM", matrix.Memory, matrix.Mmap))
+	cmd.Flags().IntVar(&opts.MaxCount, "max-count", def.MaxCount, "upper limit to filter words")
+	cmd.Flags().DurationVar(&opts.MaxDuration, "max-duration", def.MaxDuration, "upper limit of wall-clock time to train, e.g. 1h30m, which stops training regardless of iter (0 means unlimited)")
+	cmd.Flags().IntVar(&opts.MaxTokens, "max-tokens", def.MaxTokens, "upper limit of co-occurrence items to train over all iterations, which stops training regardless of iter (0 means unlimited)")
+	cmd.Flags().IntVar(&opts.MaxVocab, "max-vocab", def.MaxVocab, "upper limit of the vocabulary size which keeps the most frequent words (0 means unlimited)")
+	cmd.Flags().BoolVar(&opts.MergeCase, "merge-case", def.MergeCase, "whether to merge the case variants of words into the most frequent surface form or not")
+	cmd.Flags().IntVar(&opts.MinCount, "min-count", def.MinCount, "lower limit to filter words")
+	cmd.Flags().StringVar(&opts.Notation, "float-format", def.Notation, fmt.Sprintf("notation of the values to save, %%f or %%g. One of %s|%s", vector.Fixed, vector.General))
+	cmd.Flags().StringVar(&opts.Order, "order", def.Order, fmt.Sprintf("order of the words to save. One of %s|%s", vector.ID, vector.Freq))
+	cmd.Flags().StringVar(&opts.PhraseDelim, "phrase-delim", def.PhraseDelim, "delimiter of the words merged into the phrases in the corpus, e.g. _ for new_york, to save the phrases")
+	cmd.Flags().StringVar(&opts.PhraseJoin, "phrase-join", def.PhraseJoin, "delimiter to save the phrases with instead of --phrase-delim")
+	cmd.Flags().BoolVar(&opts.PhraseMean, "phrase-mean", def.PhraseMean, "whether to save the mean of the word vectors in the phrases as well as the phrase vectors or not")
+	cmd.Flags().IntVar(&opts.Precision, "precision", def.Precision, "number of digits to save by --float-format")
+	cmd.Flags().IntVar(&opts.SaveTop, "save-top", def.SaveTop, "number of the most frequent words to save (0 means all)")
+	cmd.Flags().Int64Var(&opts.Seed, "seed", def.Seed, "seed for random number generator")
+	cmd.Flags().StringVar(&opts.SolverType, "solver", def.SolverType, fmt.Sprintf("solver for GloVe objective. One of: %s|%s", glove.Stochastic, glove.AdaGrad))
+	cmd.Flags().Float64Var(&opts.SubsampleThreshold, "threshold", def.SubsampleThreshold, "threshold for subsampling")
+	cmd.Flags().BoolVar(&opts.Symmetric, "symmetric", def.Symmetric, "whether to count both left and right contexts, or only left contexts")
+	cmd.Flags().BoolVar(&opts.ToLower, "to-lower", def.ToLower, "whether the words on corpus convert to lowercase or not")
+	cmd.Flags().BoolVar(&opts.Verbose, "verbose", def.Verbose, "verbose mode")
+	cmd.Flags().IntVarP(&opts.Window, "window", "w", def.Window, "context window size")
+	cmd.Flags().StringVar(&opts.WindowType, "window-type", def.WindowType, fmt.Sprintf("weighting for contexts by distance in window, which overrides --cnt by %s=%s, %s=%s and %s=%s. One of %s|%s|%s", window.Dynamic, co.Linear, window.Uniform, co.Increment, window.Harmonic, co.Proximity, window.Dynamic, window.Uniform, window.Harmonic))
+	cmd.Flags().IntVar(&opts.Xmax, "xmax", def.Xmax, "specifying cutoff in weighting function")
+}
@@ -0,0 +1,76 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/pkg/model/modelutil/kernel"
+	"github.com/ynqa/wego/pkg/model/modelutil/matrix"
+	"github.com/ynqa/wego/pkg/model/modelutil/vector"
+	"github.com/ynqa/wego/pkg/model/modelutil/window"
+	"github.com/ynqa/wego/pkg/model/word2vec"
+)
+
+// Word2Vec binds opts to the flags of cmd, whose defaults are word2vec.DefaultOptions().
+func Word2Vec(cmd *cobra.Command, opts *word2vec.Options) {
+	def := word2vec.DefaultOptions()
+	cmd.Flags().StringVar(&opts.Backend, "backend", def.Backend, fmt.Sprintf("backend to compute the dense updates of negative sampling. One of: %v (build with -tags=blas for %s)", kernel.Available(), kernel.BLAS))
+	cmd.Flags().BoolVar(&opts.Autotune, "autotune", def.Autotune, "whether to tune the number of the goroutines working at once from 1 up to --goroutines by the measured throughput")
+	cmd.Flags().IntVar(&opts.BatchSize, "batch", def.BatchSize, "batch size to train")
+	cmd.Flags().IntVar(&opts.ContextBuckets, "context-buckets", def.ContextBuckets, "number of buckets to hash the contexts (the output vectors) into, fewer than the vocabulary to save memory (0 means disabled)")
+	cmd.Flags().StringVar(&opts.ContextType, "context", def.ContextType, fmt.Sprintf("which contexts does it train with? one of: %s|%s (%s requires --input-format=%s)", word2vec.WindowContext, word2vec.DepContext, word2vec.DepContext, word2vec.CoNLLU))
+	cmd.Flags().IntVarP(&opts.Dim, "dim", "d", def.Dim, "dimension for word vector")
+	cmd.Flags().IntVar(&opts.Goroutines, "goroutines", def.Goroutines, "number of goroutine")
+	cmd.Flags().IntVar(&opts.HashBuckets, "hash-buckets", def.HashBuckets, "number of buckets to hash words into instead of the exact dictionary, which bounds memory regardless of vocabulary size (0 means disabled)")
+	cmd.Flags().BoolVar(&opts.DocInMemory, "in-memory", def.DocInMemory, "whether to store the doc in memory")
+	cmd.Flags().Float64Var(&opts.Initlr, "initlr", def.Initlr, "initial learning rate")
+	cmd.Flags().StringVar(&opts.InputFormat, "input-format", def.InputFormat, fmt.Sprintf("format of input corpus. One of: %s|%s|%s|%s|%s", word2vec.Text, word2vec.CoNLLU, word2vec.Labeled, word2vec.Wikipedia, word2vec.WET))
+	cmd.Flags().IntVar(&opts.Iter, "iter", def.Iter, "number of iteration")
+	cmd.Flags().StringVar(&opts.LabelPrefix, "label-prefix", def.LabelPrefix, "prefix of the entities in the lines (for labeled input only)")
+	cmd.Flags().IntVar(&opts.LogBatch, "log-batch", def.LogBatch, "batch size to log for counting words")
+	cmd.Flags().Float64Var(&opts.LRFreqPower, "lr-freq-power", def.LRFreqPower, "power p to scale the learning rate of each word by (minimum frequency/frequency)^p, which damps the updates of frequent words (0 means disabled)")
+	cmd.Flags().StringVar(&opts.MatrixBacking, "matrix-backing", def.MatrixBacking, fmt.Sprintf("where to allocate the matrices of the parameters. One of: %s|%s:<dir>, which maps them to the files in dir for the models larger than RAM", matrix.Memory, matrix.Mmap))
+	cmd.Flags().IntVar(&opts.MaxCount, "max-count", def.MaxCount, "upper limit to filter words")
+	cmd.Flags().IntVar(&opts.MaxDepth, "max-depth", def.MaxDepth, "times to track huffman tree, max-depth=0 means to track full path from root to word (for hierarchical softmax only)")
+	cmd.Flags().DurationVar(&opts.MaxDuration, "max-duration", def.MaxDuration, "upper limit of wall-clock time to train, e.g. 1h30m, which stops training regardless of iter (0 means unlimited)")
+	cmd.Flags().IntVar(&opts.MaxTokens, "max-tokens", def.MaxTokens, "upper limit of words to train over all iterations, which stops training regardless of iter (0 means unlimited)")
+	cmd.Flags().IntVar(&opts.MaxVocab, "max-vocab", def.MaxVocab, "upper limit of the vocabulary size which keeps the most frequent words (0 means unlimited)")
+	cmd.Flags().BoolVar(&opts.MergeCase, "merge-case", def.MergeCase, "whether to merge the case variants of words into the most frequent surface form or not")
+	cmd.Flags().IntVar(&opts.MinCount, "min-count", def.MinCount, "lower limit to filter words")
+	cmd.Flags().Float64Var(&opts.MinLR, "min-lr", def.MinLR, "lower limit of learning rate")
+	cmd.Flags().StringVar(&opts.ModelType, "model", def.ModelType, fmt.Sprintf("which model does it use? one of: %s|%s", word2vec.Cbow, word2vec.SkipGram))
+	cmd.Flags().IntVar(&opts.NegativeSampleSize, "sample", def.NegativeSampleSize, "negative sample size(for negative sampling only)")
+	cmd.Flags().StringVar(&opts.OptimizerType, "optimizer", def.OptimizerType, fmt.Sprintf("which optimizer does it use? one of: %s|%s", word2vec.HierarchicalSoftmax, word2vec.NegativeSampling))
+	cmd.Flags().StringVar(&opts.Notation, "float-format", def.Notation, fmt.Sprintf("notation of the values to save, %%f or %%g. One of %s|%s", vector.Fixed, vector.General))
+	cmd.Flags().StringVar(&opts.Order, "order", def.Order, fmt.Sprintf("order of the words to save. One of %s|%s", vector.ID, vector.Freq))
+	cmd.Flags().StringVar(&opts.PhraseDelim, "phrase-delim", def.PhraseDelim, "delimiter of the words merged into the phrases in the corpus, e.g. _ for new_york, to save the phrases")
+	cmd.Flags().StringVar(&opts.PhraseJoin, "phrase-join", def.PhraseJoin, "delimiter to save the phrases with instead of --phrase-delim")
+	cmd.Flags().BoolVar(&opts.PhraseMean, "phrase-mean", def.PhraseMean, "whether to save the mean of the word vectors in the phrases as well as the phrase vectors or not")
+	cmd.Flags().IntVar(&opts.Precision, "precision", def.Precision, "number of digits to save by --float-format")
+	cmd.Flags().IntVar(&opts.Prefetch, "prefetch", def.Prefetch, "number of the batches read ahead for the goroutines without --in-memory, which bounds the memory by (prefetch+goroutines)*batch words")
+	cmd.Flags().IntVar(&opts.SaveTop, "save-top", def.SaveTop, "number of the most frequent words to save (0 means all)")
+	cmd.Flags().Int64Var(&opts.Seed, "seed", def.Seed, "seed for random number generator")
+	cmd.Flags().BoolVar(&opts.SharedNegatives, "shared-negatives", def.SharedNegatives, "whether to share the negative samples among the contexts in a window of skipgram or not, which updates them at once in cache but draws fewer distinct samples")
+	cmd.Flags().StringVar(&opts.Sigmoid, "sigmoid", def.Sigmoid, fmt.Sprintf("how to compute the sigmoid in the optimizers. One of: %s|%s|%s", word2vec.SigmoidTable, word2vec.SigmoidPoly, word2vec.SigmoidExact))
+	cmd.Flags().Float64Var(&opts.SubsampleThreshold, "threshold", def.SubsampleThreshold, "threshold for subsampling")
+	cmd.Flags().BoolVar(&opts.ToLower, "to-lower", def.ToLower, "whether the words on corpus convert to lowercase or not")
+	cmd.Flags().IntVar(&opts.UpdateLRBatch, "update-lr-batch", def.UpdateLRBatch, "batch size to update learning rate")
+	cmd.Flags().BoolVar(&opts.Verbose, "verbose", def.Verbose, "verbose mode")
+	cmd.Flags().IntVarP(&opts.Window, "window", "w", def.Window, "context window size")
+	cmd.Flags().StringVar(&opts.WindowType, "window-type", def.WindowType, fmt.Sprintf("weighting for contexts by distance in window. One of %s|%s|%s", window.Dynamic, window.Uniform, window.Harmonic))
+}
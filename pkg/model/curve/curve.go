@@ -0,0 +1,116 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package curve
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/model"
+	"github.com/ynqa/wego/pkg/util/fileutil"
+)
+
+// Sample is a point of the training curve.
+type Sample struct {
+	Epoch int `json:"epoch"`
+	// Tokens is the number of items trained from the beginning of training.
+	Tokens  int     `json:"tokens"`
+	Loss    float64 `json:"loss"`
+	LR      float64 `json:"lr"`
+	Elapsed float64 `json:"elapsed_seconds"`
+}
+
+var header = []string{"epoch", "tokens", "loss", "lr", "elapsed_seconds"}
+
+// Recorder is the model.Hook to sample the training curve at every progress and iteration.
+type Recorder struct {
+	model.BaseHook
+	Samples []Sample
+
+	tokens  int
+	elapsed time.Duration
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+func (r *Recorder) OnProgress(p model.Progress) {
+	r.add(p)
+}
+
+func (r *Recorder) AfterIter(p model.Progress) {
+	r.add(p)
+	r.tokens += p.Trained
+	r.elapsed += p.Elapsed
+}
+
+func (r *Recorder) add(p model.Progress) {
+	r.Samples = append(r.Samples, Sample{
+		Epoch:   p.Iter,
+		Tokens:  r.tokens + p.Trained,
+		Loss:    p.Loss,
+		LR:      p.LR,
+		Elapsed: (r.elapsed + p.Elapsed).Seconds(),
+	})
+}
+
+func (r *Recorder) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, s := range r.Samples {
+		if err := cw.Write([]string{
+			strconv.Itoa(s.Epoch),
+			strconv.Itoa(s.Tokens),
+			strconv.FormatFloat(s.Loss, 'g', -1, 64),
+			strconv.FormatFloat(s.LR, 'g', -1, 64),
+			strconv.FormatFloat(s.Elapsed, 'g', -1, 64),
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (r *Recorder) WriteJSON(w io.Writer) error {
+	samples := r.Samples
+	if samples == nil {
+		samples = []Sample{}
+	}
+	b, err := json.MarshalIndent(samples, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode curve")
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// WriteFile writes the samples into path, in JSON if the extension is .json, otherwise in CSV.
+func (r *Recorder) WriteFile(path string) error {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return fileutil.WriteAtomic(path, r.WriteJSON)
+	}
+	return fileutil.WriteAtomic(path, r.WriteCSV)
+}
@@ -0,0 +1,65 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package curve
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/model"
+)
+
+func newTestRecorder() *Recorder {
+	r := NewRecorder()
+	var hook model.Hook = r
+	hook.OnProgress(model.Progress{Iter: 1, Trained: 2, LR: 0.02, Loss: 0.5, Elapsed: time.Second})
+	hook.AfterIter(model.Progress{Iter: 1, Trained: 4, LR: 0.01, Loss: 0.25, Elapsed: 2 * time.Second})
+	hook.OnProgress(model.Progress{Iter: 2, Trained: 2, LR: 0.005, Loss: 0.125, Elapsed: time.Second})
+	return r
+}
+
+func TestRecorder(t *testing.T) {
+	r := newTestRecorder()
+	assert.Equal(t, []Sample{
+		{Epoch: 1, Tokens: 2, Loss: 0.5, LR: 0.02, Elapsed: 1},
+		{Epoch: 1, Tokens: 4, Loss: 0.25, LR: 0.01, Elapsed: 2},
+		{Epoch: 2, Tokens: 6, Loss: 0.125, LR: 0.005, Elapsed: 3},
+	}, r.Samples)
+}
+
+func TestWriteCSV(t *testing.T) {
+	buf := &bytes.Buffer{}
+	assert.NoError(t, newTestRecorder().WriteCSV(buf))
+	assert.Equal(t, "epoch,tokens,loss,lr,elapsed_seconds\n"+
+		"1,2,0.5,0.02,1\n"+
+		"1,4,0.25,0.01,2\n"+
+		"2,6,0.125,0.005,3\n", buf.String())
+}
+
+func TestWriteJSON(t *testing.T) {
+	buf := &bytes.Buffer{}
+	assert.NoError(t, newTestRecorder().WriteJSON(buf))
+	var samples []Sample
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &samples))
+	assert.Equal(t, newTestRecorder().Samples, samples)
+
+	buf.Reset()
+	assert.NoError(t, NewRecorder().WriteJSON(buf))
+	assert.Equal(t, "[]\n", buf.String())
+}
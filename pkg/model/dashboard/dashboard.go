@@ -0,0 +1,158 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dashboard
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/ynqa/wego/pkg/model"
+)
+
+const (
+	sparkWidth = 40
+	barWidth   = 30
+)
+
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// Dashboard is the model.Hook to redraw the state of training on the terminal at every progress,
+// by the ANSI escape sequences.
+type Dashboard struct {
+	model.BaseHook
+
+	w          io.Writer
+	iters      int
+	goroutines int
+	losses     []float64
+	lines      int
+}
+
+func New(w io.Writer, iters, goroutines int) *Dashboard {
+	return &Dashboard{
+		w:          w,
+		iters:      iters,
+		goroutines: goroutines,
+	}
+}
+
+func (d *Dashboard) OnProgress(p model.Progress) {
+	d.draw(p)
+}
+
+func (d *Dashboard) AfterIter(p model.Progress) {
+	d.draw(p)
+}
+
+func (d *Dashboard) draw(p model.Progress) {
+	d.losses = append(d.losses, p.Loss)
+	if len(d.losses) > sparkWidth {
+		d.losses = d.losses[len(d.losses)-sparkWidth:]
+	}
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	lines := Render(p, d.iters, d.goroutines, d.losses, mem)
+	var b strings.Builder
+	if d.lines > 0 {
+		fmt.Fprintf(&b, "\x1b[%dA", d.lines)
+	}
+	for _, line := range lines {
+		b.WriteString("\x1b[2K")
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	d.lines = len(lines)
+	io.WriteString(d.w, b.String())
+}
+
+// Render returns the lines of the dashboard.
+func Render(p model.Progress, iters, goroutines int, losses []float64, mem runtime.MemStats) []string {
+	var ratio float64
+	if p.Total > 0 {
+		ratio = math.Min(float64(p.Trained)/float64(p.Total), 1)
+	}
+	filled := int(ratio * barWidth)
+	bar := strings.Repeat("#", filled) + strings.Repeat(".", barWidth-filled)
+
+	// autotune changes the number of the goroutines during training.
+	if p.Goroutines > 0 {
+		goroutines = p.Goroutines
+	}
+	var throughput float64
+	if p.Elapsed > 0 {
+		throughput = float64(p.Trained) / p.Elapsed.Seconds()
+	}
+	eta := "-"
+	if p.Total > 0 && throughput > 0 {
+		rest := p.Total - p.Trained + (iters-p.Iter)*p.Total
+		if rest < 0 {
+			rest = 0
+		}
+		eta = (time.Duration(float64(rest)/throughput) * time.Second).Round(time.Second).String()
+	}
+	var last float64
+	if len(losses) > 0 {
+		last = losses[len(losses)-1]
+	}
+	rate := fmt.Sprintf("throughput %.0f items/s, %.0f items/s per goroutine (%d)", throughput, throughput/float64(goroutines), goroutines)
+	if p.QueueCap > 0 {
+		rate += fmt.Sprintf(", queue %d/%d", p.Queue, p.QueueCap)
+	}
+	return []string{
+		fmt.Sprintf("iter %d/%d [%s] %5.1f%% %d/%d", p.Iter, iters, bar, ratio*100, p.Trained, p.Total),
+		rate,
+		fmt.Sprintf("loss %.6f %s", last, Sparkline(losses)),
+		fmt.Sprintf("lr %.6f elapsed %v eta %s", p.LR, p.Elapsed.Round(time.Second), eta),
+		fmt.Sprintf("memory heap %s sys %s gc %d", size(mem.HeapAlloc), size(mem.Sys), mem.NumGC),
+	}
+}
+
+// Sparkline scales the values between the min and the max into the block characters.
+func Sparkline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+	min, max := values[0], values[0]
+	for _, v := range values {
+		min, max = math.Min(min, v), math.Max(max, v)
+	}
+	runes := make([]rune, len(values))
+	for i, v := range values {
+		var level int
+		if max > min {
+			level = int((v - min) / (max - min) * float64(len(sparks)-1))
+		}
+		runes[i] = sparks[level]
+	}
+	return string(runes)
+}
+
+func size(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
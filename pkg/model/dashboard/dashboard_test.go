@@ -0,0 +1,78 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dashboard
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/model"
+)
+
+func TestSparkline(t *testing.T) {
+	assert.Equal(t, "", Sparkline(nil))
+	assert.Equal(t, "▁▁", Sparkline([]float64{1, 1}))
+	assert.Equal(t, "█▄▁", Sparkline([]float64{3, 1.6, 0}))
+}
+
+func TestRender(t *testing.T) {
+	lines := Render(model.Progress{
+		Iter:    1,
+		Trained: 50,
+		Total:   100,
+		LR:      0.025,
+		Loss:    0.5,
+		Elapsed: 5 * time.Second,
+	}, 2, 2, []float64{1, 0.5}, runtime.MemStats{HeapAlloc: 1536, Sys: 3 << 20})
+	assert.Equal(t, []string{
+		"iter 1/2 [###############...............]  50.0% 50/100",
+		"throughput 10 items/s, 5 items/s per goroutine (2)",
+		"loss 0.500000 █▁",
+		"lr 0.025000 elapsed 5s eta 15s",
+		"memory heap 1.5KiB sys 3.0MiB gc 0",
+	}, lines)
+}
+
+func TestRenderQueue(t *testing.T) {
+	lines := Render(model.Progress{
+		Iter:     1,
+		Trained:  50,
+		Total:    100,
+		Elapsed:  5 * time.Second,
+		Queue:    3,
+		QueueCap: 8,
+	}, 1, 2, nil, runtime.MemStats{})
+	assert.Equal(t, "throughput 10 items/s, 5 items/s per goroutine (2), queue 3/8", lines[1])
+
+	lines = Render(model.Progress{Trained: 50, Elapsed: 5 * time.Second, Goroutines: 5}, 1, 8, nil, runtime.MemStats{})
+	assert.Equal(t, "throughput 10 items/s, 2 items/s per goroutine (5)", lines[1])
+}
+
+func TestDashboard(t *testing.T) {
+	buf := &bytes.Buffer{}
+	var hook model.Hook = New(buf, 1, 1)
+	hook.OnProgress(model.Progress{Iter: 1, Trained: 1, Total: 2, Elapsed: time.Second})
+	assert.False(t, strings.HasPrefix(buf.String(), "\x1b[5A"))
+
+	buf.Reset()
+	hook.AfterIter(model.Progress{Iter: 1, Trained: 2, Total: 2, Elapsed: time.Second})
+	assert.True(t, strings.HasPrefix(buf.String(), "\x1b[5A"))
+	assert.Equal(t, 5, strings.Count(buf.String(), "\n"))
+}
@@ -0,0 +1,88 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dashboard
+
+// page polls /curve every second to redraw the charts, and calls /query by the form.
+const page = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>wego</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+svg { border: 1px solid #ccc; margin-right: 1em; }
+polyline { fill: none; stroke: #36c; stroke-width: 1.5; }
+td { padding: 0 1em 0 0; }
+</style>
+</head>
+<body>
+<h1>wego</h1>
+<p id="status">waiting for training...</p>
+<div>
+<figure style="display:inline-block"><svg id="loss" width="480" height="200"><polyline/></svg><figcaption>loss</figcaption></figure>
+<figure style="display:inline-block"><svg id="throughput" width="480" height="200"><polyline/></svg><figcaption>throughput (items/s)</figcaption></figure>
+</div>
+<form id="query">
+<input id="word" placeholder="word"> <input id="k" type="number" value="10" min="1" style="width:4em"> <button>query</button>
+</form>
+<table id="neighbors"></table>
+<script>
+function plot(id, xs, ys) {
+  var svg = document.getElementById(id), w = svg.width.baseVal.value, h = svg.height.baseVal.value;
+  var xmax = Math.max.apply(null, xs.concat([1])), ymin = Math.min.apply(null, ys), ymax = Math.max.apply(null, ys);
+  var span = ymax - ymin || 1;
+  svg.querySelector("polyline").setAttribute("points", xs.map(function(x, i) {
+    return (x / xmax * w) + "," + (h - (ys[i] - ymin) / span * (h - 10) - 5);
+  }).join(" "));
+}
+function refresh() {
+  fetch("curve").then(function(r) { return r.json(); }).then(function(samples) {
+    if (samples.length == 0) return;
+    var xs = samples.map(function(s) { return s.tokens; });
+    var throughput = samples.map(function(s, i) {
+      var prev = i > 0 ? samples[i - 1] : {tokens: 0, elapsed_seconds: 0};
+      var dt = s.elapsed_seconds - prev.elapsed_seconds;
+      return dt > 0 ? (s.tokens - prev.tokens) / dt : 0;
+    });
+    plot("loss", xs, samples.map(function(s) { return s.loss; }));
+    plot("throughput", xs, throughput);
+    var last = samples[samples.length - 1];
+    document.getElementById("status").textContent = "epoch " + last.epoch + ", tokens " + last.tokens +
+      ", loss " + last.loss.toFixed(6) + ", lr " + last.lr.toFixed(6) + ", elapsed " + last.elapsed_seconds.toFixed(1) + "s";
+  });
+}
+document.getElementById("query").onsubmit = function(e) {
+  e.preventDefault();
+  var table = document.getElementById("neighbors");
+  var q = "query?word=" + encodeURIComponent(document.getElementById("word").value) + "&k=" + document.getElementById("k").value;
+  fetch(q).then(function(r) {
+    if (!r.ok) return r.text().then(function(t) { throw new Error(t); });
+    return r.json();
+  }).then(function(neighbors) {
+    table.innerHTML = "";
+    neighbors.forEach(function(n) {
+      var row = table.insertRow();
+      row.insertCell().textContent = n.rank;
+      row.insertCell().textContent = n.word;
+      row.insertCell().textContent = n.similarity.toFixed(6);
+    });
+  }).catch(function(err) { table.innerHTML = ""; table.insertRow().insertCell().textContent = err.message; });
+};
+refresh();
+setInterval(refresh, 1000);
+</script>
+</body>
+</html>
+`
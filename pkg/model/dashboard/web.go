@@ -0,0 +1,167 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dashboard
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/model"
+	"github.com/ynqa/wego/pkg/model/curve"
+	"github.com/ynqa/wego/pkg/search"
+)
+
+const defaultRank = 10
+
+// Web is the model.Hook to serve the web page of the live charts of loss and throughput,
+// and the nearest neighbors by the vectors in training.
+type Web struct {
+	model.BaseHook
+
+	mu      sync.Mutex
+	rec     *curve.Recorder
+	started bool
+	iters   int
+	mod     model.Model
+
+	// searcher is built over the snapshot in the iteration of searched, and reused until it ends.
+	build    sync.Mutex
+	searcher *search.Searcher
+	searched int
+}
+
+func NewWeb() *Web {
+	return &Web{
+		rec: curve.NewRecorder(),
+	}
+}
+
+func (d *Web) OnProgress(p model.Progress) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.started = true
+	d.rec.OnProgress(p)
+}
+
+func (d *Web) AfterIter(p model.Progress) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.started = true
+	d.iters++
+	d.rec.AfterIter(p)
+}
+
+// Handler returns the handler of the page on /, the samples of curve.Sample on /curve,
+// and the neighbors in JSON on /query?word=word&k=k of mod which the hook is passed to.
+func (d *Web) Handler(mod model.Model) http.Handler {
+	d.mu.Lock()
+	d.mod = mod
+	d.mu.Unlock()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", d.page)
+	mux.HandleFunc("/curve", d.curve)
+	mux.HandleFunc("/query", d.query)
+	return mux
+}
+
+// Serve serves Handler on addr in background.
+func (d *Web) Serve(addr string, mod model.Model) (*http.Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to listen %s", addr)
+	}
+	srv := &http.Server{Addr: ln.Addr().String(), Handler: d.Handler(mod)}
+	go srv.Serve(ln)
+	return srv, nil
+}
+
+func (d *Web) page(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	io.WriteString(w, page)
+}
+
+func (d *Web) curve(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	samples := append([]curve.Sample{}, d.rec.Samples...)
+	d.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(samples)
+}
+
+func (d *Web) query(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	k := defaultRank
+	if v := q.Get("k"); v != "" {
+		var err error
+		if k, err = strconv.Atoi(v); err != nil {
+			http.Error(w, "invalid k: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	d.mu.Lock()
+	started, iters, mod := d.started, d.iters, d.mod
+	d.mu.Unlock()
+	if !started || mod == nil {
+		http.Error(w, "training is not started yet", http.StatusServiceUnavailable)
+		return
+	}
+	s, status, err := d.search(mod, iters)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	if k < 1 || k > len(s.Items) {
+		http.Error(w, fmt.Sprintf("k must be in [1, %d], got %d", len(s.Items), k), http.StatusBadRequest)
+		return
+	}
+	neighbors, err := s.SearchInternal(q.Get("word"), k)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	neighbors.Write(w, search.JSON)
+}
+
+// search returns the searcher over the snapshot of mod in the iteration of iters,
+// which is built once per iteration and shared by the queries.
+func (d *Web) search(mod model.Model, iters int) (*search.Searcher, int, error) {
+	d.build.Lock()
+	defer d.build.Unlock()
+	if d.searcher != nil && d.searched == iters {
+		return d.searcher, http.StatusOK, nil
+	}
+	embs, err := model.Snapshot(mod)
+	if err != nil {
+		return nil, http.StatusNotImplemented, err
+	}
+	s, err := search.New(embs...)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	d.searcher, d.searched = s, iters
+	return s, http.StatusOK, nil
+}
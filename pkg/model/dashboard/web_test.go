@@ -0,0 +1,103 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/model"
+	"github.com/ynqa/wego/pkg/model/curve"
+	"github.com/ynqa/wego/pkg/model/word2vec"
+	"github.com/ynqa/wego/pkg/search"
+)
+
+func TestWeb(t *testing.T) {
+	web := NewWeb()
+	mod, err := word2vec.New(word2vec.Dim(3), word2vec.MinCount(1), word2vec.Hooks(web))
+	assert.NoError(t, err)
+	srv := httptest.NewServer(web.Handler(mod))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/query?word=a")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	corpus := strings.Repeat("a b c d e f g h i j ", 5)
+	assert.NoError(t, mod.Train(context.Background(), strings.NewReader(corpus)))
+
+	resp, err = http.Get(srv.URL + "/")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = http.Get(srv.URL + "/curve")
+	assert.NoError(t, err)
+	var samples []curve.Sample
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&samples))
+	resp.Body.Close()
+	assert.NotEmpty(t, samples)
+
+	resp, err = http.Get(srv.URL + "/query?word=a&k=2")
+	assert.NoError(t, err)
+	var neighbors search.Neighbors
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&neighbors))
+	resp.Body.Close()
+	assert.Len(t, neighbors, 2)
+
+	// the searcher is reused until the next iteration ends.
+	s, _, err := web.search(mod, web.iters)
+	assert.NoError(t, err)
+	cached, _, err := web.search(mod, web.iters)
+	assert.NoError(t, err)
+	assert.True(t, s == cached)
+	web.AfterIter(model.Progress{Iter: 2})
+	next, _, err := web.search(mod, web.iters)
+	assert.NoError(t, err)
+	assert.False(t, s == next)
+
+	resp, err = http.Get(srv.URL + "/query?word=z")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+
+	for _, k := range []string{"0", "-1", "1000000000000"} {
+		resp, err = http.Get(srv.URL + "/query?word=a&k=" + k)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "k=%s", k)
+	}
+}
+
+func TestWebServe(t *testing.T) {
+	web := NewWeb()
+	web.AfterIter(model.Progress{Iter: 1, Trained: 1, Elapsed: time.Second})
+	srv, err := web.Serve("127.0.0.1:0", nil)
+	assert.NoError(t, err)
+	defer srv.Close()
+
+	resp, err := http.Get("http://" + srv.Addr + "/query?word=a")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
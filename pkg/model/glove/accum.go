@@ -0,0 +1,82 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package glove
+
+import (
+	"github.com/ynqa/wego/pkg/model/modelutil/kernel"
+	"github.com/ynqa/wego/pkg/model/modelutil/matrix"
+)
+
+// accumulator sums the gradients of the items in a batch per row, which the solver applies once per row,
+// so that the frequent rows of the skewed co-occurrences are written once instead of once per item.
+// It is owned by a goroutine.
+type accumulator struct {
+	kernel kernel.Kernel
+	size   int
+	index  map[int]int
+	rows   []int
+	grads  []float64
+}
+
+func newAccumulator(k kernel.Kernel, dim int) *accumulator {
+	return &accumulator{
+		kernel: k,
+		size:   dim + 1,
+		index:  make(map[int]int),
+	}
+}
+
+// add computes the gradients of the item by the parameters at the beginning of the batch,
+// and returns the weighted squared error.
+func (acc *accumulator) add(l1, l2 int, param *matrix.Matrix, f, coef float64) float64 {
+	v1, v2 := param.Slice(l1), param.Slice(l2)
+	dim := len(v1) - 1
+	diff := acc.kernel.Dot(v1[:dim], v2[:dim])
+	diff += v1[dim] + v2[dim] - f
+	loss := 0.5 * coef * diff * diff
+	diff *= coef
+	i1, i2 := acc.offset(l1), acc.offset(l2)
+	g1, g2 := acc.grads[i1:i1+acc.size], acc.grads[i2:i2+acc.size]
+	acc.kernel.Axpy(diff, v2[:dim], g1[:dim])
+	acc.kernel.Axpy(diff, v1[:dim], g2[:dim])
+	g1[dim] += diff
+	g2[dim] += diff
+	return loss
+}
+
+// offset returns the offset of the gradient of row in grads, which is appended by zero on the first time.
+func (acc *accumulator) offset(row int) int {
+	if i, ok := acc.index[row]; ok {
+		return i
+	}
+	i := len(acc.grads)
+	acc.index[row] = i
+	acc.rows = append(acc.rows, row)
+	for j := 0; j < acc.size; j++ {
+		acc.grads = append(acc.grads, 0)
+	}
+	return i
+}
+
+// each calls fn with the rows and their gradients in the order of the first appearance, and resets acc.
+func (acc *accumulator) each(fn func(row int, grad []float64)) {
+	for n, row := range acc.rows {
+		fn(row, acc.grads[n*acc.size:(n+1)*acc.size])
+	}
+	for row := range acc.index {
+		delete(acc.index, row)
+	}
+	acc.rows, acc.grads = acc.rows[:0], acc.grads[:0]
+}
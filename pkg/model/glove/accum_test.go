@@ -0,0 +1,57 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package glove
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/model/modelutil/kernel"
+	"github.com/ynqa/wego/pkg/model/modelutil/matrix"
+)
+
+func TestAccumulator(t *testing.T) {
+	k, _ := kernel.Get(kernel.Go)
+	param := matrix.New(3, 2, func(row int, vec []float64) {
+		vec[0], vec[1] = float64(row+1), 0
+	})
+	acc := newAccumulator(k, 1)
+
+	// diff = 1*2 - 1 = 1 and 1*3 - 1 = 2 by the coef 1.
+	assert.Equal(t, 0.5, acc.add(0, 1, param, 1, 1))
+	assert.Equal(t, 2., acc.add(0, 2, param, 1, 1))
+
+	var rows []int
+	grads := make(map[int][]float64)
+	acc.each(func(row int, grad []float64) {
+		rows = append(rows, row)
+		grads[row] = append([]float64{}, grad...)
+	})
+	assert.Equal(t, []int{0, 1, 2}, rows)
+	assert.Equal(t, []float64{1*2 + 2*3, 1 + 2}, grads[0])
+	assert.Equal(t, []float64{1, 1}, grads[1])
+	assert.Equal(t, []float64{2, 2}, grads[2])
+
+	acc.each(func(int, []float64) {
+		t.Error("each must reset the accumulator")
+	})
+
+	sol := newStochastic(k, nil, Options{Initlr: 0.1})
+	acc.add(0, 1, param, 1, 1)
+	sol.apply(param, acc)
+	assert.InDeltaSlice(t, []float64{1 - 0.2, -0.1}, param.Slice(0), 1e-12)
+	assert.InDeltaSlice(t, []float64{2 - 0.1, -0.1}, param.Slice(1), 1e-12)
+}
@@ -16,22 +16,27 @@ package glove
 
 import (
 	"context"
-	"fmt"
 	"io"
-	"math/rand"
 	"sync"
 
 	"golang.org/x/sync/semaphore"
 
-	"github.com/pkg/errors"
 	"github.com/ynqa/wego/pkg/corpus"
+	"github.com/ynqa/wego/pkg/corpus/counts"
+	"github.com/ynqa/wego/pkg/corpus/cpsutil"
+	"github.com/ynqa/wego/pkg/corpus/dictionary"
 	"github.com/ynqa/wego/pkg/corpus/fs"
 	"github.com/ynqa/wego/pkg/corpus/memory"
 	"github.com/ynqa/wego/pkg/model"
 	"github.com/ynqa/wego/pkg/model/modelutil"
+	"github.com/ynqa/wego/pkg/model/modelutil/autotune"
+	"github.com/ynqa/wego/pkg/model/modelutil/budget"
+	"github.com/ynqa/wego/pkg/model/modelutil/kernel"
+	"github.com/ynqa/wego/pkg/model/modelutil/lrscale"
 	"github.com/ynqa/wego/pkg/model/modelutil/matrix"
 	"github.com/ynqa/wego/pkg/model/modelutil/vector"
 	"github.com/ynqa/wego/pkg/util/clock"
+	"github.com/ynqa/wego/pkg/util/fileutil"
 	"github.com/ynqa/wego/pkg/util/verbose"
 )
 
@@ -40,10 +45,14 @@ type glove struct {
 
 	corpus corpus.Corpus
 
-	param  *matrix.Matrix
-	solver solver
+	backing *matrix.Backing
+	param   *matrix.Matrix
+	kernel  kernel.Kernel
+	solver  solver
+	tuner   *autotune.Tuner
 
 	verbose *verbose.Verbose
+	budget  *budget.Budget
 }
 
 func New(opts ...ModelOption) (model.Model, error) {
@@ -56,8 +65,10 @@ func New(opts ...ModelOption) (model.Model, error) {
 }
 
 func NewForOptions(opts Options) (model.Model, error) {
-	// TODO: validate Options
-	v := verbose.New(opts.Verbose)
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	v := verbose.NewWriter(opts.Verbose, opts.Progress)
 	return &glove{
 		opts: opts,
 
@@ -65,17 +76,50 @@ func NewForOptions(opts Options) (model.Model, error) {
 	}, nil
 }
 
-func (g *glove) Train(r io.ReadSeeker) error {
-	if g.opts.DocInMemory {
-		g.corpus = memory.New(r, g.opts.ToLower, g.opts.MaxCount, g.opts.MinCount)
+func (g *glove) Options() interface{} {
+	return g.opts
+}
+
+func (g *glove) Train(ctx context.Context, r io.Reader) (err error) {
+	g.opts.Hooks.BeforeTrain(g.opts)
+	defer func() {
+		g.opts.Hooks.AfterTrain(err)
+	}()
+	ctx, g.budget = budget.New(ctx, g.opts.MaxDuration, g.opts.MaxTokens)
+	defer func() {
+		err = g.budget.Result(err)
+	}()
+
+	if g.opts.Cooc != "" {
+		var vocab io.Reader
+		if g.opts.CoocVocab != "" {
+			f, err := fileutil.Open(g.opts.CoocVocab)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			vocab = f
+		}
+		g.corpus = counts.New(r, g.opts.Cooc, vocab, g.opts.MaxVocab)
 	} else {
-		g.corpus = fs.New(r, g.opts.ToLower, g.opts.MaxCount, g.opts.MinCount)
+		rs, cleanup, err := cpsutil.ReadSeeker(r)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		if g.opts.DocInMemory {
+			g.corpus = memory.New(rs, g.opts.ToLower, g.opts.MergeCase, g.opts.MaxCount, g.opts.MinCount, g.opts.MaxVocab, g.opts.HashBuckets)
+		} else {
+			g.corpus = fs.New(rs, g.opts.ToLower, g.opts.MergeCase, g.opts.MaxCount, g.opts.MinCount, g.opts.MaxVocab, g.opts.HashBuckets)
+		}
 	}
 
 	if err := g.corpus.Load(
 		&corpus.WithCooccurrence{
-			CountType: g.opts.CountType,
-			Window:    g.opts.Window,
+			CountType:  g.opts.countType(),
+			Window:     g.opts.Window,
+			Asymmetric: !g.opts.Symmetric,
 		},
 		g.verbose, g.opts.LogBatch,
 	); err != nil {
@@ -84,98 +128,199 @@ func (g *glove) Train(r io.ReadSeeker) error {
 
 	dic, dim := g.corpus.Dictionary(), g.opts.Dim
 
+	rnd := modelutil.SourceRand(g.opts.Source, g.opts.Seed)
 	dimAndBias := dim + 1
-	g.param = matrix.New(
+	backing, err := matrix.ParseBacking(g.opts.MatrixBacking)
+	if err != nil {
+		return err
+	}
+	g.backing = backing
+	if g.opts.Autotune {
+		g.tuner = autotune.New(g.opts.Goroutines, autotune.DefaultInterval)
+	}
+	g.param, err = backing.New(
+		"word",
 		dic.Len()*2,
 		dimAndBias,
-		func(_ int, vec []float64) {
+		func(row int, vec []float64) {
 			for i := 0; i < dim+1; i++ {
-				vec[i] = rand.Float64() / float64(dim)
+				vec[i] = rnd.Float64() / float64(dim)
 			}
+			modelutil.WarmStart(g.opts.InitVectors, dic, row, vec)
 		},
 	)
+	if err != nil {
+		return err
+	}
 
+	k, err := kernel.Get(g.opts.Backend)
+	if err != nil {
+		return err
+	}
+	g.kernel = k
+	scale := lrscale.New(dic, g.opts.LRFreqPower, g.opts.LRWeights, g.opts.FreezeWords)
 	switch g.opts.SolverType {
 	case Stochastic:
-		g.solver = newStochastic(g.opts)
+		g.solver = newStochastic(k, scale, g.opts)
 	case AdaGrad:
-		g.solver = newAdaGrad(dic, g.opts)
+		g.solver, err = newAdaGrad(dic, k, scale, backing, g.opts)
+		if err != nil {
+			return err
+		}
 	default:
-		return errors.Errorf("invalid solver: %s not in %s|%s", g.opts.SolverType, Stochastic, AdaGrad)
+		return model.InvalidOption("solver", "invalid solver: %s not in %s|%s", g.opts.SolverType, Stochastic, AdaGrad)
 	}
 
-	return g.train()
+	return g.train(ctx)
 }
 
-func (g *glove) train() error {
+func (g *glove) train(ctx context.Context) error {
 	items := g.makeItems(g.corpus.Cooccurrence())
 	itemSize := len(items)
+	parts := g.tuner.Parts(g.opts.Goroutines, itemSize, g.opts.BatchSize)
 	indexPerThread := modelutil.IndexPerThread(
-		g.opts.Goroutines,
+		parts,
 		itemSize,
 	)
 
 	for i := 0; i < g.opts.Iter; i++ {
-		trained, clk := make(chan struct{}), clock.New()
-		go g.observe(trained, clk)
+		trained, observed, clk := make(chan float64), make(chan struct{}), clock.New()
+		go g.observe(i+1, itemSize, trained, observed, clk)
 
 		sem := semaphore.NewWeighted(int64(g.opts.Goroutines))
 		wg := &sync.WaitGroup{}
 
-		for i := 0; i < g.opts.Goroutines; i++ {
+		for i := 0; i < parts; i++ {
 			wg.Add(1)
 			s, e := indexPerThread[i], indexPerThread[i+1]
-			go g.trainPerThread(items[s:e], trained, sem, wg)
+			go g.trainPerThread(ctx, items[s:e], trained, sem, wg)
 		}
 
 		wg.Wait()
 		close(trained)
+		<-observed
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
 func (g *glove) trainPerThread(
+	ctx context.Context,
 	items []item,
-	trained chan struct{},
+	trained chan float64,
 	sem *semaphore.Weighted,
 	wg *sync.WaitGroup,
 ) error {
-	defer func() {
-		wg.Done()
-		sem.Release(1)
-	}()
+	defer wg.Done()
 
-	if err := sem.Acquire(context.Background(), 1); err != nil {
+	if err := sem.Acquire(ctx, 1); err != nil {
 		return err
 	}
+	defer sem.Release(1)
+	defer g.tuner.Acquire(len(items))()
 
+	if g.opts.AccumBatch > 0 {
+		return g.trainAccumPerThread(ctx, items, trained)
+	}
 	dic := g.corpus.Dictionary()
 	for _, item := range items {
-		g.solver.trainOne(item.l1, item.l2+dic.Len(), g.param, item.f, item.coef)
-		g.solver.trainOne(item.l1+dic.Len(), item.l2, g.param, item.f, item.coef)
-		trained <- struct{}{}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		loss := g.solver.trainOne(item.l1, item.l2+dic.Len(), g.param, item.f, item.coef)
+		if g.opts.Symmetric {
+			loss += g.solver.trainOne(item.l1+dic.Len(), item.l2, g.param, item.f, item.coef)
+		}
+		trained <- loss
 	}
 
 	return nil
 }
 
-func (g *glove) observe(trained chan struct{}, clk *clock.Clock) {
-	var cnt int
-	for range trained {
-		g.verbose.Do(func() {
-			cnt++
-			if cnt%g.opts.LogBatch == 0 {
-				fmt.Printf("trained %d items %v\r", cnt, clk.AllElapsed())
+// trainAccumPerThread accumulates the gradients of every AccumBatch items per row, and applies them at once.
+func (g *glove) trainAccumPerThread(ctx context.Context, items []item, trained chan float64) error {
+	dic := g.corpus.Dictionary()
+	acc := newAccumulator(g.kernel, g.opts.Dim)
+	for s := 0; s < len(items); s += g.opts.AccumBatch {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		e := s + g.opts.AccumBatch
+		if e > len(items) {
+			e = len(items)
+		}
+		for _, item := range items[s:e] {
+			loss := acc.add(item.l1, item.l2+dic.Len(), g.param, item.f, item.coef)
+			if g.opts.Symmetric {
+				loss += acc.add(item.l1+dic.Len(), item.l2, g.param, item.f, item.coef)
 			}
-		})
+			trained <- loss
+		}
+		g.solver.apply(g.param, acc)
 	}
+	return nil
+}
+
+func (g *glove) observe(iter, total int, trained chan float64, observed chan struct{}, clk *clock.Clock) {
+	defer close(observed)
+	var (
+		cnt  int
+		loss model.LossMeter
+	)
+	progress := func() model.Progress {
+		return model.Progress{
+			Iter:       iter,
+			Trained:    cnt,
+			Total:      total,
+			LR:         g.opts.Initlr,
+			Loss:       loss.Mean(),
+			Elapsed:    clk.AllElapsed(),
+			Goroutines: g.tuner.Limit(),
+		}
+	}
+	for v := range trained {
+		cnt++
+		loss.Add(v)
+		g.budget.Add(1)
+		if cnt%g.opts.LogBatch == 0 {
+			g.opts.Hooks.OnProgress(progress())
+			g.verbose.Do(func() {
+				g.verbose.Printf("trained %d items %v\r", cnt, clk.AllElapsed())
+			})
+		}
+	}
+	g.opts.Hooks.AfterIter(progress())
 	g.verbose.Do(func() {
-		fmt.Printf("trained %d items %v\r\n", cnt, clk.AllElapsed())
+		g.verbose.Printf("trained %d items %v\r\n", cnt, clk.AllElapsed())
 	})
 }
 
+func (g *glove) Dictionary() *dictionary.Dictionary {
+	return g.corpus.Dictionary()
+}
+
 func (g *glove) Save(f io.Writer, typ vector.Type) error {
-	return vector.Save(f, g.corpus.Dictionary(), g.WordVector(typ), g.verbose, g.opts.LogBatch)
+	return vector.Save(f, g.corpus.Dictionary(), g.WordVector(typ), vector.Format{
+		Order:       g.opts.Order,
+		Notation:    g.opts.Notation,
+		Precision:   g.opts.Precision,
+		Words:       g.opts.SaveWords,
+		Top:         g.opts.SaveTop,
+		PhraseDelim: g.opts.PhraseDelim,
+		PhraseJoin:  g.opts.PhraseJoin,
+		PhraseMean:  g.opts.PhraseMean,
+	}, g.verbose, g.opts.LogBatch)
+}
+
+// Close unmaps the matrices of the mmap backing, see matrix.Backing.
+func (g *glove) Close() error {
+	return g.backing.Close()
 }
 
 func (g *glove) WordVector(typ vector.Type) *matrix.Matrix {
@@ -14,11 +14,18 @@
 package glove
 
 import (
-	"fmt"
+	"io"
+	"math/rand"
 	"runtime"
+	"time"
 
-	"github.com/spf13/cobra"
 	co "github.com/ynqa/wego/pkg/corpus/cooccurrence"
+	"github.com/ynqa/wego/pkg/corpus/counts"
+	"github.com/ynqa/wego/pkg/model"
+	"github.com/ynqa/wego/pkg/model/modelutil/kernel"
+	"github.com/ynqa/wego/pkg/model/modelutil/matrix"
+	"github.com/ynqa/wego/pkg/model/modelutil/vector"
+	"github.com/ynqa/wego/pkg/model/modelutil/window"
 )
 
 type SolverType = string
@@ -29,104 +36,265 @@ const (
 )
 
 var (
+	defaultAccumBatch         = 0
 	defaultAlpha              = 0.75
+	defaultBackend            = kernel.Go
+	defaultAutotune           = false
 	defaultBatchSize          = 10000
+	defaultCooc               = ""
+	defaultCoocVocab          = ""
 	defaultCountType          = co.Increment
 	defaultDim                = 10
 	defaultDocInMemory        = false
 	defaultGoroutines         = runtime.NumCPU()
+	defaultHashBuckets        = 0
 	defaultInitlr             = 0.025
 	defaultIter               = 15
 	defaultLogBatch           = 100000
+	defaultLRFreqPower        = 0.0
+	defaultMatrixBacking      = matrix.Memory
 	defaultMaxCount           = -1
+	defaultMaxDuration        = time.Duration(0)
+	defaultMaxTokens          = 0
+	defaultMaxVocab           = 0
+	defaultMergeCase          = false
 	defaultMinCount           = 5
+	defaultNotation           = vector.Fixed
+	defaultOrder              = vector.ID
+	defaultPhraseDelim        = ""
+	defaultPhraseJoin         = ""
+	defaultPhraseMean         = false
+	defaultPrecision          = 6
+	defaultSaveTop            = 0
+	defaultSeed               = int64(1)
 	defaultSolverType         = Stochastic
 	defaultSubsampleThreshold = 1.0e-3
+	defaultSymmetric          = true
 	defaultToLower            = false
 	defaultVerbose            = false
 	defaultWindow             = 5
+	defaultWindowType         = ""
 	defaultXmax               = 100
 )
 
 type Options struct {
+	AccumBatch         int
 	Alpha              float64
+	Backend            kernel.Type
+	Autotune           bool
 	BatchSize          int
+	Cooc               counts.Format
+	CoocVocab          string
 	CountType          co.CountType
 	Dim                int
 	DocInMemory        bool
+	FreezeWords        []string
 	Goroutines         int
+	HashBuckets        int
+	Hooks              model.Hooks          `json:"-"`
+	InitVectors        map[string][]float64 `json:"-"`
 	Initlr             float64
 	Iter               int
+	LRFreqPower        float64
+	LRWeights          map[string]float64
 	LogBatch           int
+	MatrixBacking      string
 	MaxCount           int
+	MaxDuration        time.Duration
+	MaxTokens          int
+	MaxVocab           int
+	MergeCase          bool
 	MinCount           int
+	Notation           vector.Notation
+	Order              vector.Order
+	PhraseDelim        string
+	PhraseJoin         string
+	PhraseMean         bool
+	Precision          int
+	Progress           io.Writer `json:"-"`
+	SaveTop            int
+	SaveWords          []string
+	Seed               int64
 	SolverType         SolverType
+	Source             rand.Source `json:"-"`
 	SubsampleThreshold float64
+	Symmetric          bool
 	ToLower            bool
 	Verbose            bool
 	Window             int
+	WindowType         window.Type
 	Xmax               int
 }
 
 func DefaultOptions() Options {
 	return Options{
+		AccumBatch:         defaultAccumBatch,
 		Alpha:              defaultAlpha,
+		Backend:            defaultBackend,
+		Autotune:           defaultAutotune,
 		BatchSize:          defaultBatchSize,
+		Cooc:               defaultCooc,
+		CoocVocab:          defaultCoocVocab,
 		CountType:          defaultCountType,
 		Dim:                defaultDim,
 		DocInMemory:        defaultDocInMemory,
 		Goroutines:         defaultGoroutines,
+		HashBuckets:        defaultHashBuckets,
 		Initlr:             defaultInitlr,
 		Iter:               defaultIter,
+		LRFreqPower:        defaultLRFreqPower,
 		LogBatch:           defaultLogBatch,
+		MatrixBacking:      defaultMatrixBacking,
 		MaxCount:           defaultMaxCount,
+		MaxDuration:        defaultMaxDuration,
+		MaxTokens:          defaultMaxTokens,
+		MaxVocab:           defaultMaxVocab,
+		MergeCase:          defaultMergeCase,
 		MinCount:           defaultMinCount,
+		Notation:           defaultNotation,
+		Order:              defaultOrder,
+		PhraseDelim:        defaultPhraseDelim,
+		PhraseJoin:         defaultPhraseJoin,
+		PhraseMean:         defaultPhraseMean,
+		Precision:          defaultPrecision,
+		SaveTop:            defaultSaveTop,
+		Seed:               defaultSeed,
 		SolverType:         defaultSolverType,
 		SubsampleThreshold: defaultSubsampleThreshold,
+		Symmetric:          defaultSymmetric,
 		ToLower:            defaultToLower,
 		Verbose:            defaultVerbose,
 		Window:             defaultWindow,
+		WindowType:         defaultWindowType,
 		Xmax:               defaultXmax,
 	}
 }
 
-func LoadForCmd(cmd *cobra.Command, opts *Options) {
-	cmd.Flags().Float64Var(&opts.Alpha, "alpha", defaultAlpha, "exponent of weighting function")
-	cmd.Flags().IntVar(&opts.BatchSize, "batch", defaultBatchSize, "batch size to train")
-	cmd.Flags().StringVar(&opts.CountType, "cnt", defaultCountType, fmt.Sprintf("count type for co-occurrence words. One of %s|%s", co.Increment, co.Proximity))
-	cmd.Flags().IntVarP(&opts.Dim, "dim", "d", defaultDim, "dimension for word vector")
-	cmd.Flags().IntVar(&opts.Goroutines, "goroutines", defaultGoroutines, "number of goroutine")
-	cmd.Flags().BoolVar(&opts.DocInMemory, "in-memory", defaultDocInMemory, "whether to store the doc in memory")
-	cmd.Flags().Float64Var(&opts.Initlr, "initlr", defaultInitlr, "initial learning rate")
-	cmd.Flags().IntVar(&opts.Iter, "iter", defaultIter, "number of iteration")
-	cmd.Flags().IntVar(&opts.LogBatch, "log-batch", defaultLogBatch, "batch size to log for counting words")
-	cmd.Flags().IntVar(&opts.MaxCount, "max-count", defaultMaxCount, "upper limit to filter words")
-	cmd.Flags().IntVar(&opts.MinCount, "min-count", defaultMinCount, "lower limit to filter words")
-	cmd.Flags().StringVar(&opts.SolverType, "solver", defaultSolverType, fmt.Sprintf("solver for GloVe objective. One of: %s|%s", Stochastic, AdaGrad))
-	cmd.Flags().Float64Var(&opts.SubsampleThreshold, "threshold", defaultSubsampleThreshold, "threshold for subsampling")
-	cmd.Flags().BoolVar(&opts.ToLower, "to-lower", defaultToLower, "whether the words on corpus convert to lowercase or not")
-	cmd.Flags().BoolVar(&opts.Verbose, "verbose", defaultVerbose, "verbose mode")
-	cmd.Flags().IntVarP(&opts.Window, "window", "w", defaultWindow, "context window size")
-	cmd.Flags().IntVar(&opts.Xmax, "xmax", defaultXmax, "specifying cutoff in weighting function")
+// Validate reports all impossible options and combinations at once.
+func (opts Options) Validate() error {
+	var e model.OptionsError
+	e.Require(opts.Dim > 0, "dim", "dim must be > 0, got %d", opts.Dim)
+	for word, vec := range opts.InitVectors {
+		if len(vec) != opts.Dim {
+			e.Require(false, "init-vectors", "init vector of %q has dim %d, expected dim %d", word, len(vec), opts.Dim)
+			break
+		}
+	}
+	e.Require(opts.Window >= 1, "window", "window must be >= 1, got %d", opts.Window)
+	e.Require(opts.Iter >= 1, "iter", "iter must be >= 1, got %d", opts.Iter)
+	e.Require(opts.Goroutines >= 1, "goroutines", "goroutines must be >= 1, got %d", opts.Goroutines)
+	e.Require(opts.BatchSize >= opts.Goroutines, "batch", "batch %d must be >= goroutines %d, otherwise some goroutines have no items", opts.BatchSize, opts.Goroutines)
+	e.Require(opts.AccumBatch >= 0, "accum-batch", "accum-batch must be >= 0, got %d", opts.AccumBatch)
+	e.Require(opts.LogBatch > 0, "log-batch", "log-batch must be > 0, got %d", opts.LogBatch)
+	e.Require(opts.Initlr > 0, "initlr", "initlr must be > 0, got %v", opts.Initlr)
+	e.Require(opts.LRFreqPower >= 0, "lr-freq-power", "lr-freq-power must be >= 0, got %v", opts.LRFreqPower)
+	e.Require(0 <= opts.SubsampleThreshold && opts.SubsampleThreshold < 1, "threshold", "threshold must be in [0, 1), got %v", opts.SubsampleThreshold)
+	e.Require(opts.Cooc == "" || counts.ValidFormat(opts.Cooc), "cooc", "cooc must be one of %s|%s|%s, got %q", counts.Text, counts.Binary, counts.MatrixMarket, opts.Cooc)
+	e.Require(opts.Cooc == "" || opts.Cooc == counts.Text || opts.CoocVocab != "", "cooc-vocab", "cooc-vocab is required for %s co-occurrence", opts.Cooc)
+	e.Require(opts.Cooc == "" || opts.HashBuckets == 0, "hash-buckets", "hash-buckets and cooc are exclusive, the words are given by the counts")
+	e.Require(opts.HashBuckets >= 0, "hash-buckets", "hash-buckets must be >= 0, got %d", opts.HashBuckets)
+	e.Require(opts.MaxVocab >= 0, "max-vocab", "max-vocab must be >= 0, got %d", opts.MaxVocab)
+	e.Require(opts.MaxVocab == 0 || opts.HashBuckets == 0, "max-vocab", "max-vocab and hash-buckets are exclusive, hash-buckets bounds the vocabulary already")
+	e.Require(!(opts.ToLower && opts.MergeCase), "to-lower", "to-lower and merge-case are exclusive, merge-case keeps the surface forms")
+	e.Require(opts.MaxCount < 0 || opts.MinCount <= opts.MaxCount, "max-count", "max-count %d must be >= min-count %d, or < 0 to disable it", opts.MaxCount, opts.MinCount)
+	e.Require(opts.MaxDuration >= 0, "max-duration", "max-duration must be >= 0, got %v", opts.MaxDuration)
+	e.Require(opts.MaxTokens >= 0, "max-tokens", "max-tokens must be >= 0, got %d", opts.MaxTokens)
+	e.Require(opts.Order == vector.ID || opts.Order == vector.Freq, "order", "order must be one of %s|%s, got %q", vector.ID, vector.Freq, opts.Order)
+	e.Require(opts.Notation == vector.Fixed || opts.Notation == vector.General, "float-format", "float-format must be one of %s|%s, got %q", vector.Fixed, vector.General, opts.Notation)
+	e.Require(vector.ValidPhraseJoin(opts.PhraseJoin), "phrase-join", "phrase-join must not contain spaces, got %q", opts.PhraseJoin)
+	e.Require(0 <= opts.Precision && opts.Precision <= 17, "precision", "precision must be in [0, 17], got %d", opts.Precision)
+	e.Require(opts.SaveTop >= 0, "save-top", "save-top must be >= 0, got %d", opts.SaveTop)
+	e.Require(0 < opts.Alpha && opts.Alpha <= 1, "alpha", "alpha must be in (0, 1], got %v", opts.Alpha)
+	e.Require(opts.Xmax > 0, "xmax", "xmax must be > 0, got %d", opts.Xmax)
+	e.Require(opts.CountType == co.Increment || opts.CountType == co.Proximity || opts.CountType == co.Linear, "cnt", "cnt must be one of %s|%s|%s, got %q", co.Increment, co.Proximity, co.Linear, opts.CountType)
+	e.Require(opts.WindowType == "" || window.Valid(opts.WindowType), "window-type", "window-type must be one of %s|%s|%s, got %q", window.Dynamic, window.Uniform, window.Harmonic, opts.WindowType)
+	e.Require(opts.SolverType == Stochastic || opts.SolverType == AdaGrad, "solver", "solver must be one of %s|%s, got %q", Stochastic, AdaGrad, opts.SolverType)
+	if _, err := kernel.Get(opts.Backend); err != nil {
+		e.Require(false, "backend", "%v", err)
+	}
+	if _, err := matrix.ParseBacking(opts.MatrixBacking); err != nil {
+		e.Require(false, "matrix-backing", "%v", err)
+	}
+	return e.Err()
 }
 
 type ModelOption func(*Options)
 
+// WithOptions replaces all options by opts, and the following ModelOptions override them.
+// New(WithOptions(opts)) is the same as NewForOptions(opts).
+func WithOptions(v Options) ModelOption {
+	return ModelOption(func(opts *Options) {
+		*opts = v
+	})
+}
+
+func AccumBatch(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.AccumBatch = v
+	})
+}
+
 func Alpha(v float64) ModelOption {
 	return ModelOption(func(opts *Options) {
 		opts.Alpha = v
 	})
 }
 
+// Deprecated: use Symmetric(false) instead.
+func Asymmetric() ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Symmetric = false
+	})
+}
+
+func Backend(typ kernel.Type) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Backend = typ
+	})
+}
+
+func Autotune(v bool) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Autotune = v
+	})
+}
+
 func BatchSize(v int) ModelOption {
 	return ModelOption(func(opts *Options) {
 		opts.BatchSize = v
 	})
 }
 
-func DocInMemory() ModelOption {
+// Cooc reads the input as the precomputed co-occurrence counts in format instead of the corpus.
+func Cooc(format counts.Format) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Cooc = format
+	})
+}
+
+// CoocVocab gives the words and their frequencies of the counts by the lines of `word count`.
+func CoocVocab(path string) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.CoocVocab = path
+	})
+}
+
+func Count(typ co.CountType) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.CountType = typ
+	})
+}
+
+func DocInMemory(v bool) ModelOption {
 	return ModelOption(func(opts *Options) {
-		opts.DocInMemory = true
+		opts.DocInMemory = v
+	})
+}
+
+// FreezeWords keeps the vectors of words unchanged during training.
+func FreezeWords(words ...string) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.FreezeWords = append(opts.FreezeWords, words...)
 	})
 }
 
@@ -136,6 +304,26 @@ func Goroutines(v int) ModelOption {
 	})
 }
 
+func HashBuckets(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.HashBuckets = v
+	})
+}
+
+// InitVectors warm-starts the vectors of the words in v instead of the random ones,
+// e.g. by the vectors of the previous training.
+func InitVectors(v map[string][]float64) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.InitVectors = v
+	})
+}
+
+func Hooks(hs ...model.Hook) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Hooks = append(opts.Hooks, hs...)
+	})
+}
+
 func Dim(v int) ModelOption {
 	return ModelOption(func(opts *Options) {
 		opts.Dim = v
@@ -154,18 +342,139 @@ func Iter(v int) ModelOption {
 	})
 }
 
+func LRFreqPower(v float64) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.LRFreqPower = v
+	})
+}
+
+// LRWeights scales the learning rate of each word by its weight.
+func LRWeights(v map[string]float64) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.LRWeights = v
+	})
+}
+
+func LogBatch(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.LogBatch = v
+	})
+}
+
+func MatrixBacking(v string) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.MatrixBacking = v
+	})
+}
+
 func MaxCount(v int) ModelOption {
 	return ModelOption(func(opts *Options) {
 		opts.MaxCount = v
 	})
 }
 
+func MaxDuration(v time.Duration) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.MaxDuration = v
+	})
+}
+
+func MaxTokens(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.MaxTokens = v
+	})
+}
+
+func MaxVocab(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.MaxVocab = v
+	})
+}
+
+func MergeCase(v bool) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.MergeCase = v
+	})
+}
+
 func MinCount(v int) ModelOption {
 	return ModelOption(func(opts *Options) {
 		opts.MinCount = v
 	})
 }
 
+func Notation(v vector.Notation) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Notation = v
+	})
+}
+
+func Order(v vector.Order) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Order = v
+	})
+}
+
+// PhraseDelim is the delimiter of the words merged into the phrases in the corpus, e.g. _ for new_york.
+func PhraseDelim(v string) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.PhraseDelim = v
+	})
+}
+
+// PhraseJoin is the delimiter to save the phrases with instead of PhraseDelim, e.g. - for new-york.
+func PhraseJoin(v string) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.PhraseJoin = v
+	})
+}
+
+func PhraseMean(v bool) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.PhraseMean = v
+	})
+}
+
+func Precision(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Precision = v
+	})
+}
+
+// Progress prints the progress in verbose into w, e.g. os.Stderr while the output is written to stdout.
+func Progress(w io.Writer) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Progress = w
+	})
+}
+
+// RandSource injects the source to initialize parameters and to seed the generators per goroutine.
+// It takes priority over Seed.
+func RandSource(src rand.Source) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Source = src
+	})
+}
+
+func SaveTop(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.SaveTop = v
+	})
+}
+
+// SaveWords saves only the vectors of words.
+func SaveWords(words ...string) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.SaveWords = append(opts.SaveWords, words...)
+	})
+}
+
+func Seed(v int64) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Seed = v
+	})
+}
+
 func Solver(typ SolverType) ModelOption {
 	return ModelOption(func(opts *Options) {
 		opts.SolverType = typ
@@ -178,15 +487,21 @@ func SubsampleThreshold(v float64) ModelOption {
 	})
 }
 
-func ToLower() ModelOption {
+func Symmetric(v bool) ModelOption {
 	return ModelOption(func(opts *Options) {
-		opts.ToLower = true
+		opts.Symmetric = v
 	})
 }
 
-func Verbose() ModelOption {
+func ToLower(v bool) ModelOption {
 	return ModelOption(func(opts *Options) {
-		opts.Verbose = true
+		opts.ToLower = v
+	})
+}
+
+func Verbose(v bool) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Verbose = v
 	})
 }
 
@@ -196,6 +511,25 @@ func Window(v int) ModelOption {
 	})
 }
 
+func WindowType(typ window.Type) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.WindowType = typ
+	})
+}
+
+// countType returns the count type for co-occurrence words by WindowType if set, otherwise CountType.
+func (opts Options) countType() co.CountType {
+	switch opts.WindowType {
+	case window.Dynamic:
+		return co.Linear
+	case window.Uniform:
+		return co.Increment
+	case window.Harmonic:
+		return co.Proximity
+	}
+	return opts.CountType
+}
+
 func Xmax(v int) ModelOption {
 	return ModelOption(func(opts *Options) {
 		opts.Xmax = v
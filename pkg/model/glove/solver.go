@@ -18,82 +18,121 @@ import (
 	"math"
 
 	"github.com/ynqa/wego/pkg/corpus/dictionary"
+	"github.com/ynqa/wego/pkg/model/modelutil/kernel"
+	"github.com/ynqa/wego/pkg/model/modelutil/lrscale"
 	"github.com/ynqa/wego/pkg/model/modelutil/matrix"
 )
 
 type solver interface {
-	trainOne(l1, l2 int, param *matrix.Matrix, f, coef float64)
+	// trainOne updates the parameters for the item and returns the weighted squared error.
+	trainOne(l1, l2 int, param *matrix.Matrix, f, coef float64) float64
+	// apply updates the parameters by the gradients accumulated per row, and resets acc.
+	apply(param *matrix.Matrix, acc *accumulator)
 }
 
 type stochastic struct {
 	initlr float64
+	kernel kernel.Kernel
+	scale  *lrscale.Scale
 }
 
-func newStochastic(opts Options) solver {
+func newStochastic(k kernel.Kernel, scale *lrscale.Scale, opts Options) solver {
 	return &stochastic{
 		initlr: opts.Initlr,
+		kernel: k,
+		scale:  scale,
 	}
 }
 
-func (sol *stochastic) trainOne(l1, l2 int, param *matrix.Matrix, f, coef float64) {
+func (sol *stochastic) trainOne(l1, l2 int, param *matrix.Matrix, f, coef float64) float64 {
 	v1, v2 := param.Slice(l1), param.Slice(l2)
-	dim, diff := len(v1)-1, 0.
-	for i := 0; i < dim; i++ {
-		diff += v1[i] * v2[i]
-	}
+	dim := len(v1) - 1
+	diff := sol.kernel.Dot(v1[:dim], v2[:dim])
 	diff += v1[dim] + v2[dim] - f
+	loss := 0.5 * coef * diff * diff
 	diff *= coef * sol.initlr
+	s1, s2 := sol.scale.Of(l1), sol.scale.Of(l2)
 	for i := 0; i < dim; i++ {
 		t1, t2 := diff*v2[i], diff*v1[i]
-		v1[i] -= t1
-		v2[i] -= t2
+		v1[i] -= s1 * t1
+		v2[i] -= s2 * t2
 	}
-	v1[dim] -= diff
-	v2[dim] -= diff
+	v1[dim] -= s1 * diff
+	v2[dim] -= s2 * diff
+	return loss
+}
+
+func (sol *stochastic) apply(param *matrix.Matrix, acc *accumulator) {
+	acc.each(func(row int, grad []float64) {
+		sol.kernel.Axpy(-sol.scale.Of(row)*sol.initlr, grad, param.Slice(row))
+	})
 }
 
 type adaGrad struct {
 	initlr float64
+	kernel kernel.Kernel
 	gradsq *matrix.Matrix
+	scale  *lrscale.Scale
 }
 
-func newAdaGrad(dic *dictionary.Dictionary, opts Options) solver {
+// newAdaGrad allocates the squared gradients by backing as well as the parameters, which are the same size.
+func newAdaGrad(dic *dictionary.Dictionary, k kernel.Kernel, scale *lrscale.Scale, backing *matrix.Backing, opts Options) (solver, error) {
 	dimAndBias := opts.Dim + 1
+	gradsq, err := backing.New(
+		"gradsq",
+		dic.Len()*2,
+		dimAndBias,
+		func(_ int, vec []float64) {
+			for i := 0; i < dimAndBias; i++ {
+				vec[i] = 1.
+			}
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
 	return &adaGrad{
 		initlr: opts.Initlr,
-		gradsq: matrix.New(
-			dic.Len()*2,
-			dimAndBias,
-			func(_ int, vec []float64) {
-				for i := 0; i < dimAndBias; i++ {
-					vec[i] = 1.
-				}
-			},
-		),
-	}
+		kernel: k,
+		scale:  scale,
+		gradsq: gradsq,
+	}, nil
 }
 
-func (sol *adaGrad) trainOne(l1, l2 int, param *matrix.Matrix, f, coef float64) {
+func (sol *adaGrad) trainOne(l1, l2 int, param *matrix.Matrix, f, coef float64) float64 {
 	v1, v2 := param.Slice(l1), param.Slice(l2)
 	g1, g2 := sol.gradsq.Slice(l1), sol.gradsq.Slice(l2)
-	dim, diff := len(v1)-1, 0.
-	for i := 0; i < dim; i++ {
-		diff += v1[i] * v2[i]
-	}
+	dim := len(v1) - 1
+	diff := sol.kernel.Dot(v1[:dim], v2[:dim])
 	diff += v1[dim] + v2[dim] - f
+	loss := 0.5 * coef * diff * diff
 	diff *= coef * sol.initlr
+	s1, s2 := sol.scale.Of(l1), sol.scale.Of(l2)
 	for i := 0; i < dim; i++ {
 		t1, t2 := diff*v2[i], diff*v1[i]
 		g1[i] += t1 * t1
 		g2[i] += t2 * t2
 		t1 /= math.Sqrt(g1[i])
 		t2 /= math.Sqrt(g2[i])
-		v1[i] -= t1
-		v2[i] -= t2
+		v1[i] -= s1 * t1
+		v2[i] -= s2 * t2
 	}
-	v1[dim] -= diff / math.Sqrt(g1[dim])
-	v2[dim] -= diff / math.Sqrt(g2[dim])
+	v1[dim] -= s1 * diff / math.Sqrt(g1[dim])
+	v2[dim] -= s2 * diff / math.Sqrt(g2[dim])
 	diff *= diff
 	g1[dim] += diff
 	g2[dim] += diff
+	return loss
+}
+
+func (sol *adaGrad) apply(param *matrix.Matrix, acc *accumulator) {
+	acc.each(func(row int, grad []float64) {
+		v, g := param.Slice(row), sol.gradsq.Slice(row)
+		s := sol.scale.Of(row)
+		for i := range grad {
+			t := grad[i] * sol.initlr
+			g[i] += t * t
+			v[i] -= s * t / math.Sqrt(g[i])
+		}
+	})
 }
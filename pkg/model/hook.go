@@ -0,0 +1,108 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"time"
+)
+
+// Progress is the state of training in an iteration.
+type Progress struct {
+	// Iter starts from 1.
+	Iter int
+	// Trained is the number of words (or co-occurrence items for GloVe) trained in the iteration.
+	Trained int
+	Total   int
+	LR      float64
+	// Loss is the mean loss of the items trained since the last progress, e.g. the negative log-likelihood of word2vec.
+	Loss    float64
+	Elapsed time.Duration
+	// Queue is the number of the batches prefetched for the goroutines in streaming, of QueueCap.
+	Queue    int
+	QueueCap int
+	// Goroutines is the number of the goroutines working at once tuned by autotune, 0 unless it's enabled.
+	Goroutines int
+}
+
+// Skipped is the loss of the items which are not trained, e.g. by subsampling.
+const Skipped = -1.
+
+// LossMeter averages the losses of the trained items.
+type LossMeter struct {
+	sum  float64
+	n    int
+	last float64
+}
+
+// Add adds the loss of an item unless it is Skipped.
+func (m *LossMeter) Add(loss float64) {
+	if loss == Skipped {
+		return
+	}
+	m.sum += loss
+	m.n++
+}
+
+// Mean returns the mean loss since the last Mean, or the last mean if no items are trained since then.
+func (m *LossMeter) Mean() float64 {
+	if m.n > 0 {
+		m.last = m.sum / float64(m.n)
+	}
+	m.sum, m.n = 0, 0
+	return m.last
+}
+
+// Hook is notified of the lifecycle of training, e.g. to track the experiments.
+// Hook must not block for a long time because OnProgress is called during training.
+type Hook interface {
+	BeforeTrain(opts interface{})
+	OnProgress(Progress)
+	AfterIter(Progress)
+	AfterTrain(error)
+}
+
+// BaseHook does nothing, embed it to implement only some methods of Hook.
+type BaseHook struct{}
+
+func (BaseHook) BeforeTrain(interface{}) {}
+func (BaseHook) OnProgress(Progress)     {}
+func (BaseHook) AfterIter(Progress)      {}
+func (BaseHook) AfterTrain(error)        {}
+
+type Hooks []Hook
+
+func (hs Hooks) BeforeTrain(opts interface{}) {
+	for _, h := range hs {
+		h.BeforeTrain(opts)
+	}
+}
+
+func (hs Hooks) OnProgress(p Progress) {
+	for _, h := range hs {
+		h.OnProgress(p)
+	}
+}
+
+func (hs Hooks) AfterIter(p Progress) {
+	for _, h := range hs {
+		h.AfterIter(p)
+	}
+}
+
+func (hs Hooks) AfterTrain(err error) {
+	for _, h := range hs {
+		h.AfterTrain(err)
+	}
+}
@@ -15,20 +15,18 @@
 package lexvec
 
 import (
-	"fmt"
 	"math"
 
-	"github.com/pkg/errors"
 	co "github.com/ynqa/wego/pkg/corpus/cooccurrence"
 	"github.com/ynqa/wego/pkg/corpus/cooccurrence/encode"
+	"github.com/ynqa/wego/pkg/model"
 	"github.com/ynqa/wego/pkg/util/clock"
 )
 
-func (l *lexvec) makeItems(cooc *co.Cooccurrence) (map[uint64]float64, error) {
-	em := cooc.EncodedMatrix()
-	res, idx, clk := make(map[uint64]float64), 0, clock.New()
+func (l *lexvec) makeItems(cooc *co.Cooccurrence) (relations, error) {
+	idx, clk := 0, clock.New()
 	logTotalFreq := math.Log(math.Pow(float64(l.corpus.Len()), l.opts.Smooth))
-	for enc, f := range em {
+	fn := func(enc uint64, f float64) (float64, error) {
 		u1, u2 := encode.DecodeBigram(enc)
 		l1, l2 := int(u1), int(u2)
 		v, err := l.calculateRelation(
@@ -37,18 +35,49 @@ func (l *lexvec) makeItems(cooc *co.Cooccurrence) (map[uint64]float64, error) {
 			f, logTotalFreq,
 		)
 		if err != nil {
-			return nil, err
+			return 0, err
 		}
-		res[enc] = v
 		idx++
 		l.verbose.Do(func() {
 			if idx%l.opts.LogBatch == 0 {
-				fmt.Printf("build %d items %v\r", idx, clk.AllElapsed())
+				l.verbose.Printf("build %d items %v\r", idx, clk.AllElapsed())
 			}
 		})
+		return v, nil
+	}
+
+	var (
+		res relations
+		err error
+	)
+	if l.opts.ExternalMemory {
+		res, err = newExternalRelations(
+			l.opts.TempDir,
+			l.corpus.Dictionary().Len(),
+			l.opts.CacheRows,
+			cooc.Each, fn,
+		)
+		if err != nil {
+			cooc.Close()
+			return nil, err
+		}
+		if err := cooc.Close(); err != nil {
+			res.close()
+			return nil, err
+		}
+	} else {
+		mem := make(memoryRelations)
+		for enc, f := range cooc.EncodedMatrix() {
+			v, err := fn(enc, f)
+			if err != nil {
+				return nil, err
+			}
+			mem[enc] = v
+		}
+		res = mem
 	}
 	l.verbose.Do(func() {
-		fmt.Printf("build %d items %v\r\n", idx, clk.AllElapsed())
+		l.verbose.Printf("build %d items %v\r\n", idx, clk.AllElapsed())
 	})
 	return res, nil
 }
@@ -81,6 +110,6 @@ func (l *lexvec) calculateRelation(
 	case LogCollocation:
 		return math.Log(co), nil
 	default:
-		return 0, errors.Errorf("invalid measure type")
+		return 0, model.InvalidOption("rel", "invalid relation type: %s not in %s|%s|%s|%s", typ, PPMI, PMI, Collocation, LogCollocation)
 	}
 }
@@ -16,24 +16,35 @@ package lexvec
 
 import (
 	"context"
-	"fmt"
 	"io"
 	"math/rand"
+	"sort"
 	"sync"
 
 	"golang.org/x/sync/semaphore"
 
+	"github.com/pkg/errors"
 	"github.com/ynqa/wego/pkg/corpus"
 	co "github.com/ynqa/wego/pkg/corpus/cooccurrence"
 	"github.com/ynqa/wego/pkg/corpus/cooccurrence/encode"
+	"github.com/ynqa/wego/pkg/corpus/counts"
+	"github.com/ynqa/wego/pkg/corpus/cpsutil"
+	"github.com/ynqa/wego/pkg/corpus/dictionary"
 	"github.com/ynqa/wego/pkg/corpus/fs"
 	"github.com/ynqa/wego/pkg/corpus/memory"
 	"github.com/ynqa/wego/pkg/model"
 	"github.com/ynqa/wego/pkg/model/modelutil"
+	"github.com/ynqa/wego/pkg/model/modelutil/autotune"
+	"github.com/ynqa/wego/pkg/model/modelutil/budget"
+	"github.com/ynqa/wego/pkg/model/modelutil/lrscale"
 	"github.com/ynqa/wego/pkg/model/modelutil/matrix"
+	"github.com/ynqa/wego/pkg/model/modelutil/prefetch"
 	"github.com/ynqa/wego/pkg/model/modelutil/subsample"
+	"github.com/ynqa/wego/pkg/model/modelutil/unigram"
 	"github.com/ynqa/wego/pkg/model/modelutil/vector"
+	"github.com/ynqa/wego/pkg/model/modelutil/window"
 	"github.com/ynqa/wego/pkg/util/clock"
+	"github.com/ynqa/wego/pkg/util/fileutil"
 	"github.com/ynqa/wego/pkg/util/verbose"
 )
 
@@ -42,11 +53,21 @@ type lexvec struct {
 
 	corpus corpus.Corpus
 
+	backing    *matrix.Backing
 	param      *matrix.Matrix
+	rand       *rand.Rand
 	subsampler *subsample.Subsampler
+	negative   *unigram.Sampler
+	scale      *lrscale.Scale
 	currentlr  float64
+	// total is the number of the items to train per iteration, which decays the learning rate.
+	total int
+	// queue is the prefetch of the current iteration in streaming.
+	queue *prefetch.Queue
+	tuner *autotune.Tuner
 
 	verbose *verbose.Verbose
+	budget  *budget.Budget
 }
 
 func New(opts ...ModelOption) (model.Model, error) {
@@ -59,8 +80,10 @@ func New(opts ...ModelOption) (model.Model, error) {
 }
 
 func NewForOptions(opts Options) (model.Model, error) {
-	// TODO: validate Options
-	v := verbose.New(opts.Verbose)
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	v := verbose.NewWriter(opts.Verbose, opts.Progress)
 	return &lexvec{
 		opts: opts,
 
@@ -70,135 +93,323 @@ func NewForOptions(opts Options) (model.Model, error) {
 	}, nil
 }
 
-func (l *lexvec) Train(r io.ReadSeeker) error {
-	if l.opts.DocInMemory {
-		l.corpus = memory.New(r, l.opts.ToLower, l.opts.MaxCount, l.opts.MinCount)
+func (l *lexvec) Options() interface{} {
+	return l.opts
+}
+
+func (l *lexvec) Train(ctx context.Context, r io.Reader) (err error) {
+	l.opts.Hooks.BeforeTrain(l.opts)
+	defer func() {
+		l.opts.Hooks.AfterTrain(err)
+	}()
+	ctx, l.budget = budget.New(ctx, l.opts.MaxDuration, l.opts.MaxTokens)
+	defer func() {
+		err = l.budget.Result(err)
+	}()
+
+	if l.opts.Cooc != "" {
+		var vocab io.Reader
+		if l.opts.CoocVocab != "" {
+			f, err := fileutil.Open(l.opts.CoocVocab)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			vocab = f
+		}
+		l.corpus = counts.New(r, l.opts.Cooc, vocab, l.opts.MaxVocab)
 	} else {
-		l.corpus = fs.New(r, l.opts.ToLower, l.opts.MaxCount, l.opts.MinCount)
+		rs, cleanup, err := cpsutil.ReadSeeker(r)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		if l.opts.DocInMemory {
+			l.corpus = memory.New(rs, l.opts.ToLower, l.opts.MergeCase, l.opts.MaxCount, l.opts.MinCount, l.opts.MaxVocab, l.opts.HashBuckets)
+		} else {
+			l.corpus = fs.New(rs, l.opts.ToLower, l.opts.MergeCase, l.opts.MaxCount, l.opts.MinCount, l.opts.MaxVocab, l.opts.HashBuckets)
+		}
 	}
 
-	if err := l.corpus.Load(
-		&corpus.WithCooccurrence{
-			CountType: co.Increment,
-			Window:    l.opts.Window,
-		},
-		l.verbose, l.opts.BatchSize,
-	); err != nil {
+	with := &corpus.WithCooccurrence{
+		CountType: co.Increment,
+		Window:    l.opts.Window,
+	}
+	// the counts of the corpus are spilled while counting for the external relations,
+	// whereas the precomputed counts are held as the cells to train over.
+	if l.opts.ExternalMemory && l.opts.Cooc == "" {
+		with.SpillCells, with.SpillDir = l.opts.SpillCells, l.opts.TempDir
+	}
+	if err := l.corpus.Load(with, l.verbose, l.opts.BatchSize); err != nil {
 		return err
 	}
 
 	dic, dim := l.corpus.Dictionary(), l.opts.Dim
+	if l.opts.NegativeSampleSize >= dic.Len() {
+		return errors.Errorf("sample %d must be < the vocabulary size %d, lower --sample or --min-count", l.opts.NegativeSampleSize, dic.Len())
+	}
 
-	l.param = matrix.New(
+	l.rand = modelutil.SourceRand(l.opts.Source, l.opts.Seed)
+	backing, err := matrix.ParseBacking(l.opts.MatrixBacking)
+	if err != nil {
+		return err
+	}
+	l.backing = backing
+	if l.opts.Autotune {
+		l.tuner = autotune.New(l.opts.Goroutines, autotune.DefaultInterval)
+	}
+	l.param, err = backing.New(
+		"word",
 		dic.Len()*2,
 		dim,
-		func(_ int, vec []float64) {
+		func(row int, vec []float64) {
 			for i := 0; i < dim; i++ {
-				vec[i] = (rand.Float64() - 0.5) / float64(dim)
+				vec[i] = (l.rand.Float64() - 0.5) / float64(dim)
 			}
+			modelutil.WarmStart(l.opts.InitVectors, dic, row, vec)
 		},
 	)
+	if err != nil {
+		return err
+	}
 
 	l.subsampler = subsample.New(dic, l.opts.SubsampleThreshold)
+	l.negative = unigram.New(dic, l.opts.NegativeSmooth)
+	l.scale = lrscale.New(dic, l.opts.LRFreqPower, l.opts.LRWeights, l.opts.FreezeWords)
 
-	if l.opts.DocInMemory {
-		if err := l.train(); err != nil {
+	l.total = l.corpus.Len()
+	if l.opts.Cooc != "" {
+		if err := l.trainCells(ctx); err != nil {
+			return err
+		}
+	} else if l.opts.DocInMemory {
+		if err := l.train(ctx); err != nil {
 			return err
 		}
 	} else {
-		if err := l.batchTrain(); err != nil {
+		if err := l.batchTrain(ctx); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func (l *lexvec) train() error {
+func (l *lexvec) train(ctx context.Context) error {
 	items, err := l.makeItems(l.corpus.Cooccurrence())
 	if err != nil {
 		return err
 	}
+	defer items.close()
 
 	doc := l.corpus.IndexedDoc()
+	parts := l.tuner.Parts(l.opts.Goroutines, len(doc), l.opts.BatchSize)
 	indexPerThread := modelutil.IndexPerThread(
-		l.opts.Goroutines,
+		parts,
 		len(doc),
 	)
 
 	for i := 1; i <= l.opts.Iter; i++ {
-		trained, clk := make(chan struct{}), clock.New()
-		go l.observe(trained, clk)
+		trained, observed, clk := make(chan float64), make(chan struct{}), clock.New()
+		go l.observe(i, trained, observed, clk)
 
 		sem := semaphore.NewWeighted(int64(l.opts.Goroutines))
 		wg := &sync.WaitGroup{}
 
-		for i := 0; i < l.opts.Goroutines; i++ {
+		for i := 0; i < parts; i++ {
 			wg.Add(1)
 			s, e := indexPerThread[i], indexPerThread[i+1]
-			go l.trainPerThread(doc[s:e], items, trained, sem, wg)
+			go l.trainPerThread(ctx, doc[s:e], items, modelutil.NewRandFrom(l.rand), trained, sem, wg)
 		}
 
 		wg.Wait()
 		close(trained)
+		<-observed
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := items.err(); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-func (l *lexvec) batchTrain() error {
+func (l *lexvec) batchTrain(ctx context.Context) error {
 	items, err := l.makeItems(l.corpus.Cooccurrence())
 	if err != nil {
 		return err
 	}
+	defer items.close()
 
 	for i := 1; i <= l.opts.Iter; i++ {
-		trained, clk := make(chan struct{}), clock.New()
-		go l.observe(trained, clk)
+		l.queue = prefetch.New(l.opts.Prefetch)
+		l.queue.RandSource(l.rand)
+		trained, observed, clk := make(chan float64), make(chan struct{}), clock.New()
+		go l.observe(i, trained, observed, clk)
+
+		err := l.queue.Run(ctx, l.opts.Goroutines, func(in chan []int) error {
+			return l.corpus.BatchWords(in, l.opts.BatchSize)
+		}, func(doc []int, rng *modelutil.Rand) {
+			defer l.tuner.Acquire(len(doc))()
+			l.trainDoc(ctx, doc, items, rng, trained)
+		})
+
+		close(trained)
+		<-observed
+		if err != nil {
+			return err
+		} else if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := items.err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// trainCells trains over the cells of the co-occurrence instead of the windows of the doc,
+// because the precomputed counts have no doc.
+func (l *lexvec) trainCells(ctx context.Context) error {
+	items, err := l.makeItems(l.corpus.Cooccurrence())
+	if err != nil {
+		return err
+	}
+	defer items.close()
+
+	em := l.corpus.Cooccurrence().EncodedMatrix()
+	cells := make([]uint64, 0, len(em))
+	for enc := range em {
+		cells = append(cells, enc)
+	}
+	sort.Slice(cells, func(i, j int) bool {
+		return cells[i] < cells[j]
+	})
+	l.total = len(cells)
+	parts := l.tuner.Parts(l.opts.Goroutines, len(cells), l.opts.BatchSize)
+	indexPerThread := modelutil.IndexPerThread(
+		parts,
+		len(cells),
+	)
+
+	for i := 1; i <= l.opts.Iter; i++ {
+		trained, observed, clk := make(chan float64), make(chan struct{}), clock.New()
+		go l.observe(i, trained, observed, clk)
 
 		sem := semaphore.NewWeighted(int64(l.opts.Goroutines))
 		wg := &sync.WaitGroup{}
 
-		in := make(chan []int, l.opts.Goroutines)
-		go l.corpus.BatchWords(in, l.opts.BatchSize)
-		for doc := range in {
+		for i := 0; i < parts; i++ {
 			wg.Add(1)
-			go l.trainPerThread(doc, items, trained, sem, wg)
+			s, e := indexPerThread[i], indexPerThread[i+1]
+			go l.trainCellsPerThread(ctx, cells[s:e], items, modelutil.NewRandFrom(l.rand), trained, sem, wg)
 		}
 
 		wg.Wait()
 		close(trained)
+		<-observed
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := items.err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *lexvec) trainCellsPerThread(
+	ctx context.Context,
+	cells []uint64,
+	items relations,
+	rng *modelutil.Rand,
+	trained chan float64,
+	sem *semaphore.Weighted,
+	wg *sync.WaitGroup,
+) error {
+	defer wg.Done()
+
+	if err := sem.Acquire(ctx, 1); err != nil {
+		return err
+	}
+	defer sem.Release(1)
+	defer l.tuner.Acquire(len(cells))()
+
+	for _, enc := range cells {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		trained <- l.trainCell(enc, items, rng)
 	}
 	return nil
 }
 
+// trainCell updates both of the words in the cell as the word and the context in turn,
+// as the window of the doc sees the pair from both sides.
+func (l *lexvec) trainCell(enc uint64, items relations, rng *modelutil.Rand) float64 {
+	var loss float64
+	dic := l.corpus.Dictionary()
+	u1, u2 := encode.DecodeBigram(enc)
+	pairs := [][2]int{{int(u1), int(u2)}}
+	if u1 != u2 {
+		pairs = append(pairs, [2]int{int(u2), int(u1)})
+	}
+	for _, pair := range pairs {
+		word, context := pair[0], pair[1]
+		loss += l.update(word, context, items.lookup(enc), 1)
+		for n := 0; n < l.opts.NegativeSampleSize; n++ {
+			sample := l.negative.Sample(rng)
+			enc := encode.EncodeBigram(uint64(word), uint64(sample))
+			loss += l.update(word, sample+dic.Len(), items.lookup(enc), 1)
+		}
+	}
+	return loss
+}
+
 func (l *lexvec) trainPerThread(
+	ctx context.Context,
 	doc []int,
-	items map[uint64]float64,
-	trained chan struct{},
+	items relations,
+	rng *modelutil.Rand,
+	trained chan float64,
 	sem *semaphore.Weighted,
 	wg *sync.WaitGroup,
 ) error {
-	defer func() {
-		wg.Done()
-		sem.Release(1)
-	}()
+	defer wg.Done()
 
-	if err := sem.Acquire(context.Background(), 1); err != nil {
+	if err := sem.Acquire(ctx, 1); err != nil {
 		return err
 	}
+	defer sem.Release(1)
+	defer l.tuner.Acquire(len(doc))()
+
+	return l.trainDoc(ctx, doc, items, rng, trained)
+}
 
+func (l *lexvec) trainDoc(ctx context.Context, doc []int, items relations, rng *modelutil.Rand, trained chan float64) error {
 	for pos, id := range doc {
-		if l.subsampler.Trial(id) {
-			l.trainOne(doc, pos, items)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
 		}
-		trained <- struct{}{}
+		loss := model.Skipped
+		if l.subsampler.Trial(id, rng) {
+			loss = l.trainOne(doc, pos, items, rng)
+		}
+		trained <- loss
 	}
 
 	return nil
 }
 
-func (l *lexvec) trainOne(doc []int, pos int, items map[uint64]float64) {
+func (l *lexvec) trainOne(doc []int, pos int, items relations, rng *modelutil.Rand) float64 {
+	var loss float64
 	dic := l.corpus.Dictionary()
-	del := modelutil.NextRandom(l.opts.Window)
+	del := window.Shrink(l.opts.WindowType, l.opts.Window, rng)
 	for a := del; a < l.opts.Window*2+1-del; a++ {
 		if a == l.opts.Window {
 			continue
@@ -207,54 +418,105 @@ func (l *lexvec) trainOne(doc []int, pos int, items map[uint64]float64) {
 		if c < 0 || c >= len(doc) {
 			continue
 		}
+		if l.opts.SubsampleContexts && !l.subsampler.Trial(doc[c], rng) {
+			continue
+		}
+		weight := window.Weight(l.opts.WindowType, a-l.opts.Window)
 		enc := encode.EncodeBigram(uint64(doc[pos]), uint64(doc[c]))
-		l.update(doc[pos], doc[c], items[enc])
+		loss += l.update(doc[pos], doc[c], items.lookup(enc), weight)
 		for n := 0; n < l.opts.NegativeSampleSize; n++ {
-			sample := modelutil.NextRandom(dic.Len())
+			sample := l.negative.Sample(rng)
 			enc := encode.EncodeBigram(uint64(doc[pos]), uint64(sample))
-			l.update(doc[pos], sample+dic.Len(), items[enc])
+			loss += l.update(doc[pos], sample+dic.Len(), items.lookup(enc), weight)
 		}
 	}
+	return loss
 }
 
-func (l *lexvec) update(l1, l2 int, f float64) {
+// update returns the weighted squared error of the item.
+func (l *lexvec) update(l1, l2 int, f, weight float64) float64 {
 	var diff float64
 	for i := 0; i < l.opts.Dim; i++ {
 		diff += l.param.Slice(l1)[i] * l.param.Slice(l2)[i]
 	}
-	diff = (diff - f) * l.currentlr
+	diff -= f
+	loss := 0.5 * weight * diff * diff
+	diff *= l.currentlr * weight
+	s1, s2 := l.scale.Of(l1), l.scale.Of(l2)
 	for i := 0; i < l.opts.Dim; i++ {
 		t1 := diff * l.param.Slice(l2)[i]
 		t2 := diff * l.param.Slice(l1)[i]
-		l.param.Slice(l1)[i] -= t1
-		l.param.Slice(l2)[i] -= t2
+		l.param.Slice(l1)[i] -= s1 * t1
+		l.param.Slice(l2)[i] -= s2 * t2
 	}
+	return loss
 }
 
-func (l *lexvec) observe(trained chan struct{}, clk *clock.Clock) {
-	var cnt int
-	for range trained {
+func (l *lexvec) observe(iter int, trained chan float64, observed chan struct{}, clk *clock.Clock) {
+	defer close(observed)
+	var (
+		cnt  int
+		loss model.LossMeter
+	)
+	progress := func() model.Progress {
+		p := model.Progress{
+			Iter:    iter,
+			Trained: cnt,
+			Total:   l.total,
+			LR:      l.currentlr,
+			Loss:    loss.Mean(),
+			Elapsed: clk.AllElapsed(),
+		}
+		if l.queue != nil {
+			p.Queue, p.QueueCap = l.queue.Depth(), l.queue.Cap()
+		}
+		p.Goroutines = l.tuner.Limit()
+		return p
+	}
+	for v := range trained {
 		cnt++
+		loss.Add(v)
+		l.budget.Add(1)
 		if cnt%l.opts.UpdateLRBatch == 0 {
 			if l.currentlr < l.opts.MinLR {
 				l.currentlr = l.opts.MinLR
 			} else {
-				l.currentlr = l.opts.Initlr * (1.0 - float64(cnt)/float64(l.corpus.Len()))
+				l.currentlr = l.opts.Initlr * (1.0 - float64(cnt)/float64(l.total))
 			}
 		}
-		l.verbose.Do(func() {
-			if cnt%l.opts.LogBatch == 0 {
-				fmt.Printf("trained %d words %v\r", cnt, clk.AllElapsed())
-			}
-		})
+		if cnt%l.opts.LogBatch == 0 {
+			l.opts.Hooks.OnProgress(progress())
+			l.verbose.Do(func() {
+				l.verbose.Printf("trained %d words %v\r", cnt, clk.AllElapsed())
+			})
+		}
 	}
+	l.opts.Hooks.AfterIter(progress())
 	l.verbose.Do(func() {
-		fmt.Printf("trained %d words %v\r\n", cnt, clk.AllElapsed())
+		l.verbose.Printf("trained %d words %v\r\n", cnt, clk.AllElapsed())
 	})
 }
 
+func (l *lexvec) Dictionary() *dictionary.Dictionary {
+	return l.corpus.Dictionary()
+}
+
 func (l *lexvec) Save(f io.Writer, typ vector.Type) error {
-	return vector.Save(f, l.corpus.Dictionary(), l.WordVector(typ), l.verbose, l.opts.LogBatch)
+	return vector.Save(f, l.corpus.Dictionary(), l.WordVector(typ), vector.Format{
+		Order:       l.opts.Order,
+		Notation:    l.opts.Notation,
+		Precision:   l.opts.Precision,
+		Words:       l.opts.SaveWords,
+		Top:         l.opts.SaveTop,
+		PhraseDelim: l.opts.PhraseDelim,
+		PhraseJoin:  l.opts.PhraseJoin,
+		PhraseMean:  l.opts.PhraseMean,
+	}, l.verbose, l.opts.LogBatch)
+}
+
+// Close unmaps the matrices of the mmap backing, see matrix.Backing.
+func (l *lexvec) Close() error {
+	return l.backing.Close()
 }
 
 func (l *lexvec) WordVector(typ vector.Type) *matrix.Matrix {
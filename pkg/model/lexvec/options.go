@@ -15,10 +15,16 @@
 package lexvec
 
 import (
-	"fmt"
+	"io"
+	"math/rand"
 	"runtime"
+	"time"
 
-	"github.com/spf13/cobra"
+	"github.com/ynqa/wego/pkg/corpus/counts"
+	"github.com/ynqa/wego/pkg/model"
+	"github.com/ynqa/wego/pkg/model/modelutil/matrix"
+	"github.com/ynqa/wego/pkg/model/modelutil/vector"
+	"github.com/ynqa/wego/pkg/model/modelutil/window"
 )
 
 type RelationType = string
@@ -31,102 +37,268 @@ const (
 )
 
 var (
+	defaultAutotune           = false
 	defaultBatchSize          = 10000
+	defaultCacheRows          = 100000
+	defaultCooc               = ""
+	defaultCoocVocab          = ""
 	defaultDim                = 10
 	defaultDocInMemory        = false
+	defaultExternalMemory     = false
 	defaultGoroutines         = runtime.NumCPU()
+	defaultHashBuckets        = 0
 	defaultInitlr             = 0.025
 	defaultIter               = 15
 	defaultLogBatch           = 100000
+	defaultLRFreqPower        = 0.0
+	defaultMatrixBacking      = matrix.Memory
 	defaultMaxCount           = -1
+	defaultMaxDuration        = time.Duration(0)
+	defaultMaxTokens          = 0
+	defaultMaxVocab           = 0
+	defaultMergeCase          = false
 	defaultMinCount           = 5
 	defaultMinLR              = defaultInitlr * 1.0e-4
 	defaultNegativeSampleSize = 5
+	defaultNegativeSmooth     = 0.
 	defaultRelationType       = PPMI
+	defaultNotation           = vector.Fixed
+	defaultOrder              = vector.ID
+	defaultPhraseDelim        = ""
+	defaultPhraseJoin         = ""
+	defaultPhraseMean         = false
+	defaultPrecision          = 6
+	defaultPrefetch           = 8
+	defaultSaveTop            = 0
+	defaultSeed               = int64(1)
 	defaultSmooth             = 0.75
+	defaultSpillCells         = 1 << 22
+	defaultSubsampleContexts  = false
 	defaultSubsampleThreshold = 1.0e-3
+	defaultTempDir            = ""
 	defaultToLower            = false
 	defaultUpdateLRBatch      = 100000
 	defaultVerbose            = false
 	defaultWindow             = 5
+	defaultWindowType         = window.Dynamic
 )
 
 type Options struct {
+	Autotune           bool
 	BatchSize          int
+	CacheRows          int
+	Cooc               counts.Format
+	CoocVocab          string
 	Dim                int
 	DocInMemory        bool
+	ExternalMemory     bool
+	FreezeWords        []string
 	Goroutines         int
+	HashBuckets        int
+	Hooks              model.Hooks          `json:"-"`
+	InitVectors        map[string][]float64 `json:"-"`
 	Initlr             float64
 	Iter               int
+	LRFreqPower        float64
+	LRWeights          map[string]float64
 	LogBatch           int
+	MatrixBacking      string
 	MaxCount           int
+	MaxDuration        time.Duration
+	MaxTokens          int
+	MaxVocab           int
+	MergeCase          bool
 	MinCount           int
 	MinLR              float64
 	NegativeSampleSize int
+	NegativeSmooth     float64
 	RelationType       RelationType
+	Notation           vector.Notation
+	Order              vector.Order
+	PhraseDelim        string
+	PhraseJoin         string
+	PhraseMean         bool
+	Precision          int
+	// Prefetch is the number of the batches read ahead for the goroutines in streaming.
+	Prefetch           int
+	Progress           io.Writer `json:"-"`
+	SaveTop            int
+	SaveWords          []string
+	Seed               int64
 	Smooth             float64
+	Source             rand.Source `json:"-"`
+	SpillCells         int
+	SubsampleContexts  bool
 	SubsampleThreshold float64
+	TempDir            string
 	ToLower            bool
 	UpdateLRBatch      int
 	Verbose            bool
 	Window             int
+	WindowType         window.Type
 }
 
 func DefaultOptions() Options {
 	return Options{
+		Autotune:           defaultAutotune,
 		BatchSize:          defaultBatchSize,
+		CacheRows:          defaultCacheRows,
+		Cooc:               defaultCooc,
+		CoocVocab:          defaultCoocVocab,
 		Dim:                defaultDim,
 		DocInMemory:        defaultDocInMemory,
+		ExternalMemory:     defaultExternalMemory,
 		Goroutines:         defaultGoroutines,
+		HashBuckets:        defaultHashBuckets,
 		Initlr:             defaultInitlr,
 		Iter:               defaultIter,
+		LRFreqPower:        defaultLRFreqPower,
 		LogBatch:           defaultLogBatch,
+		MatrixBacking:      defaultMatrixBacking,
 		MaxCount:           defaultMaxCount,
+		MaxDuration:        defaultMaxDuration,
+		MaxTokens:          defaultMaxTokens,
+		MaxVocab:           defaultMaxVocab,
+		MergeCase:          defaultMergeCase,
 		MinCount:           defaultMinCount,
 		MinLR:              defaultMinLR,
 		NegativeSampleSize: defaultNegativeSampleSize,
+		NegativeSmooth:     defaultNegativeSmooth,
 		RelationType:       defaultRelationType,
+		Notation:           defaultNotation,
+		Order:              defaultOrder,
+		PhraseDelim:        defaultPhraseDelim,
+		PhraseJoin:         defaultPhraseJoin,
+		PhraseMean:         defaultPhraseMean,
+		Precision:          defaultPrecision,
+		Prefetch:           defaultPrefetch,
+		SaveTop:            defaultSaveTop,
+		Seed:               defaultSeed,
 		Smooth:             defaultSmooth,
+		SpillCells:         defaultSpillCells,
+		SubsampleContexts:  defaultSubsampleContexts,
 		SubsampleThreshold: defaultSubsampleThreshold,
+		TempDir:            defaultTempDir,
 		ToLower:            defaultToLower,
 		UpdateLRBatch:      defaultUpdateLRBatch,
 		Verbose:            defaultVerbose,
 		Window:             defaultWindow,
+		WindowType:         defaultWindowType,
 	}
 }
-func LoadForCmd(cmd *cobra.Command, opts *Options) {
-	cmd.Flags().IntVar(&opts.BatchSize, "batch", defaultBatchSize, "batch size to train")
-	cmd.Flags().IntVarP(&opts.Dim, "dim", "d", defaultDim, "dimension for word vector")
-	cmd.Flags().IntVar(&opts.Goroutines, "goroutines", defaultGoroutines, "number of goroutine")
-	cmd.Flags().BoolVar(&opts.DocInMemory, "in-memory", defaultDocInMemory, "whether to store the doc in memory")
-	cmd.Flags().Float64Var(&opts.Initlr, "initlr", defaultInitlr, "initial learning rate")
-	cmd.Flags().IntVar(&opts.Iter, "iter", defaultIter, "number of iteration")
-	cmd.Flags().IntVar(&opts.LogBatch, "log-batch", defaultLogBatch, "batch size to log for counting words")
-	cmd.Flags().IntVar(&opts.MaxCount, "max-count", defaultMaxCount, "upper limit to filter words")
-	cmd.Flags().IntVar(&opts.MinCount, "min-count", defaultMinCount, "lower limit to filter words")
-	cmd.Flags().Float64Var(&opts.MinLR, "min-lr", defaultMinLR, "lower limit of learning rate")
-	cmd.Flags().IntVar(&opts.NegativeSampleSize, "sample", defaultNegativeSampleSize, "negative sample size")
-	cmd.Flags().StringVar(&opts.RelationType, "rel", defaultRelationType, fmt.Sprintf("relation type for co-occurrence words. One of %s|%s|%s|%s", PPMI, PMI, Collocation, LogCollocation))
-	cmd.Flags().Float64Var(&opts.Smooth, "smooth", defaultSmooth, "smoothing value for co-occurence value")
-	cmd.Flags().Float64Var(&opts.SubsampleThreshold, "threshold", defaultSubsampleThreshold, "threshold for subsampling")
-	cmd.Flags().BoolVar(&opts.ToLower, "to-lower", defaultToLower, "whether the words on corpus convert to lowercase or not")
-	cmd.Flags().IntVar(&opts.UpdateLRBatch, "update-lr-batch", defaultUpdateLRBatch, "batch size to update learning rate")
-	cmd.Flags().BoolVar(&opts.Verbose, "verbose", defaultVerbose, "verbose mode")
-	cmd.Flags().IntVarP(&opts.Window, "window", "w", defaultWindow, "context window size")
 
+// Validate reports all impossible options and combinations at once.
+func (opts Options) Validate() error {
+	var e model.OptionsError
+	e.Require(opts.Dim > 0, "dim", "dim must be > 0, got %d", opts.Dim)
+	for word, vec := range opts.InitVectors {
+		if len(vec) != opts.Dim {
+			e.Require(false, "init-vectors", "init vector of %q has dim %d, expected dim %d", word, len(vec), opts.Dim)
+			break
+		}
+	}
+	e.Require(opts.Window >= 1, "window", "window must be >= 1, got %d", opts.Window)
+	e.Require(opts.Iter >= 1, "iter", "iter must be >= 1, got %d", opts.Iter)
+	e.Require(opts.Goroutines >= 1, "goroutines", "goroutines must be >= 1, got %d", opts.Goroutines)
+	e.Require(opts.BatchSize >= opts.Goroutines, "batch", "batch %d must be >= goroutines %d, otherwise some goroutines have no words", opts.BatchSize, opts.Goroutines)
+	e.Require(opts.LogBatch > 0, "log-batch", "log-batch must be > 0, got %d", opts.LogBatch)
+	e.Require(opts.UpdateLRBatch > 0, "update-lr-batch", "update-lr-batch must be > 0, got %d", opts.UpdateLRBatch)
+	e.Require(opts.Initlr > 0, "initlr", "initlr must be > 0, got %v", opts.Initlr)
+	e.Require(opts.LRFreqPower >= 0, "lr-freq-power", "lr-freq-power must be >= 0, got %v", opts.LRFreqPower)
+	e.Require(0 <= opts.MinLR && opts.MinLR <= opts.Initlr, "min-lr", "min-lr must be in [0, initlr=%v], got %v", opts.Initlr, opts.MinLR)
+	e.Require(0 <= opts.SubsampleThreshold && opts.SubsampleThreshold < 1, "threshold", "threshold must be in [0, 1), got %v", opts.SubsampleThreshold)
+	e.Require(opts.Cooc == "" || counts.ValidFormat(opts.Cooc), "cooc", "cooc must be one of %s|%s|%s, got %q", counts.Text, counts.Binary, counts.MatrixMarket, opts.Cooc)
+	e.Require(opts.Cooc == "" || opts.Cooc == counts.Text || opts.CoocVocab != "", "cooc-vocab", "cooc-vocab is required for %s co-occurrence", opts.Cooc)
+	e.Require(opts.Cooc == "" || opts.HashBuckets == 0, "hash-buckets", "hash-buckets and cooc are exclusive, the words are given by the counts")
+	e.Require(opts.HashBuckets >= 0, "hash-buckets", "hash-buckets must be >= 0, got %d", opts.HashBuckets)
+	e.Require(opts.MaxVocab >= 0, "max-vocab", "max-vocab must be >= 0, got %d", opts.MaxVocab)
+	e.Require(opts.MaxVocab == 0 || opts.HashBuckets == 0, "max-vocab", "max-vocab and hash-buckets are exclusive, hash-buckets bounds the vocabulary already")
+	e.Require(!(opts.ToLower && opts.MergeCase), "to-lower", "to-lower and merge-case are exclusive, merge-case keeps the surface forms")
+	e.Require(opts.MaxCount < 0 || opts.MinCount <= opts.MaxCount, "max-count", "max-count %d must be >= min-count %d, or < 0 to disable it", opts.MaxCount, opts.MinCount)
+	e.Require(opts.MaxDuration >= 0, "max-duration", "max-duration must be >= 0, got %v", opts.MaxDuration)
+	e.Require(opts.MaxTokens >= 0, "max-tokens", "max-tokens must be >= 0, got %d", opts.MaxTokens)
+	e.Require(opts.Order == vector.ID || opts.Order == vector.Freq, "order", "order must be one of %s|%s, got %q", vector.ID, vector.Freq, opts.Order)
+	e.Require(opts.Notation == vector.Fixed || opts.Notation == vector.General, "float-format", "float-format must be one of %s|%s, got %q", vector.Fixed, vector.General, opts.Notation)
+	e.Require(vector.ValidPhraseJoin(opts.PhraseJoin), "phrase-join", "phrase-join must not contain spaces, got %q", opts.PhraseJoin)
+	e.Require(0 <= opts.Precision && opts.Precision <= 17, "precision", "precision must be in [0, 17], got %d", opts.Precision)
+	e.Require(opts.Prefetch >= 0, "prefetch", "prefetch must be >= 0, got %d", opts.Prefetch)
+	e.Require(opts.SaveTop >= 0, "save-top", "save-top must be >= 0, got %d", opts.SaveTop)
+	e.Require(opts.NegativeSampleSize > 0, "sample", "sample must be > 0, got %d", opts.NegativeSampleSize)
+	e.Require(opts.NegativeSmooth >= 0, "negative-smooth", "negative-smooth must be >= 0, got %v", opts.NegativeSmooth)
+	e.Require(opts.Smooth >= 0, "smooth", "smooth must be >= 0, got %v", opts.Smooth)
+	e.Require(!opts.ExternalMemory || opts.CacheRows > 0, "cache-rows", "cache-rows must be > 0 with external-memory, got %d", opts.CacheRows)
+	e.Require(!opts.ExternalMemory || opts.SpillCells > 0, "spill-cells", "spill-cells must be > 0 with external-memory, got %d", opts.SpillCells)
+	switch opts.RelationType {
+	case PPMI, PMI, Collocation, LogCollocation:
+	default:
+		e.Require(false, "rel", "rel must be one of %s|%s|%s|%s, got %q", PPMI, PMI, Collocation, LogCollocation, opts.RelationType)
+	}
+	e.Require(window.Valid(opts.WindowType), "window-type", "window-type must be one of %s|%s|%s, got %q", window.Dynamic, window.Uniform, window.Harmonic, opts.WindowType)
+	if _, err := matrix.ParseBacking(opts.MatrixBacking); err != nil {
+		e.Require(false, "matrix-backing", "%v", err)
+	}
+	return e.Err()
 }
 
 type ModelOption func(*Options)
 
+// WithOptions replaces all options by opts, and the following ModelOptions override them.
+// New(WithOptions(opts)) is the same as NewForOptions(opts).
+func WithOptions(v Options) ModelOption {
+	return ModelOption(func(opts *Options) {
+		*opts = v
+	})
+}
+
+func Autotune(v bool) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Autotune = v
+	})
+}
+
 func BatchSize(v int) ModelOption {
 	return ModelOption(func(opts *Options) {
 		opts.BatchSize = v
 	})
 }
 
-func DocInMemory() ModelOption {
+func CacheRows(v int) ModelOption {
 	return ModelOption(func(opts *Options) {
-		opts.DocInMemory = true
+		opts.CacheRows = v
+	})
+}
+
+// Cooc reads the input as the precomputed co-occurrence counts in format instead of the corpus,
+// and trains over the cells of the counts instead of the windows of the doc.
+func Cooc(format counts.Format) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Cooc = format
+	})
+}
+
+// CoocVocab gives the words and their frequencies of the counts by the lines of `word count`.
+func CoocVocab(path string) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.CoocVocab = path
+	})
+}
+
+func DocInMemory(v bool) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.DocInMemory = v
+	})
+}
+
+func ExternalMemory(v bool) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.ExternalMemory = v
+	})
+}
+
+// FreezeWords keeps the vectors of words unchanged during training.
+func FreezeWords(words ...string) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.FreezeWords = append(opts.FreezeWords, words...)
 	})
 }
 
@@ -136,6 +308,26 @@ func Goroutines(v int) ModelOption {
 	})
 }
 
+func HashBuckets(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.HashBuckets = v
+	})
+}
+
+// InitVectors warm-starts the vectors of the words in v instead of the random ones,
+// e.g. by the vectors of the previous training.
+func InitVectors(v map[string][]float64) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.InitVectors = v
+	})
+}
+
+func Hooks(hs ...model.Hook) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Hooks = append(opts.Hooks, hs...)
+	})
+}
+
 func Dim(v int) ModelOption {
 	return ModelOption(func(opts *Options) {
 		opts.Dim = v
@@ -154,18 +346,61 @@ func Iter(v int) ModelOption {
 	})
 }
 
+func LRFreqPower(v float64) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.LRFreqPower = v
+	})
+}
+
+// LRWeights scales the learning rate of each word by its weight.
+func LRWeights(v map[string]float64) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.LRWeights = v
+	})
+}
+
 func LogBatch(v int) ModelOption {
 	return ModelOption(func(opts *Options) {
 		opts.LogBatch = v
 	})
 }
 
+func MatrixBacking(v string) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.MatrixBacking = v
+	})
+}
+
 func MaxCount(v int) ModelOption {
 	return ModelOption(func(opts *Options) {
 		opts.MaxCount = v
 	})
 }
 
+func MaxDuration(v time.Duration) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.MaxDuration = v
+	})
+}
+
+func MaxTokens(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.MaxTokens = v
+	})
+}
+
+func MaxVocab(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.MaxVocab = v
+	})
+}
+
+func MergeCase(v bool) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.MergeCase = v
+	})
+}
+
 func MinCount(v int) ModelOption {
 	return ModelOption(func(opts *Options) {
 		opts.MinCount = v
@@ -184,27 +419,129 @@ func NegativeSampleSize(v int) ModelOption {
 	})
 }
 
+func NegativeSmooth(v float64) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.NegativeSmooth = v
+	})
+}
+
+func Notation(v vector.Notation) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Notation = v
+	})
+}
+
+func Order(v vector.Order) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Order = v
+	})
+}
+
+// PhraseDelim is the delimiter of the words merged into the phrases in the corpus, e.g. _ for new_york.
+func PhraseDelim(v string) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.PhraseDelim = v
+	})
+}
+
+// PhraseJoin is the delimiter to save the phrases with instead of PhraseDelim, e.g. - for new-york.
+func PhraseJoin(v string) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.PhraseJoin = v
+	})
+}
+
+func PhraseMean(v bool) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.PhraseMean = v
+	})
+}
+
+func Precision(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Precision = v
+	})
+}
+
+func Prefetch(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Prefetch = v
+	})
+}
+
+// Progress prints the progress in verbose into w, e.g. os.Stderr while the output is written to stdout.
+func Progress(w io.Writer) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Progress = w
+	})
+}
+
+// RandSource injects the source to initialize parameters and to seed the generators per goroutine.
+// It takes priority over Seed.
+func RandSource(src rand.Source) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Source = src
+	})
+}
+
 func Relation(typ RelationType) ModelOption {
 	return ModelOption(func(opts *Options) {
 		opts.RelationType = typ
 	})
 }
 
+func SaveTop(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.SaveTop = v
+	})
+}
+
+// SaveWords saves only the vectors of words.
+func SaveWords(words ...string) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.SaveWords = append(opts.SaveWords, words...)
+	})
+}
+
+func Seed(v int64) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Seed = v
+	})
+}
+
 func Smooth(v float64) ModelOption {
 	return ModelOption(func(opts *Options) {
 		opts.Smooth = v
 	})
 }
 
+func SpillCells(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.SpillCells = v
+	})
+}
+
+func SubsampleContexts(v bool) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.SubsampleContexts = v
+	})
+}
+
 func SubsampleThreshold(v float64) ModelOption {
 	return ModelOption(func(opts *Options) {
 		opts.SubsampleThreshold = v
 	})
 }
 
-func ToLower() ModelOption {
+func TempDir(v string) ModelOption {
 	return ModelOption(func(opts *Options) {
-		opts.ToLower = true
+		opts.TempDir = v
+	})
+}
+
+func ToLower(v bool) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.ToLower = v
 	})
 }
 
@@ -214,9 +551,9 @@ func UpdateLRBatch(v int) ModelOption {
 	})
 }
 
-func Verbose() ModelOption {
+func Verbose(v bool) ModelOption {
 	return ModelOption(func(opts *Options) {
-		opts.Verbose = true
+		opts.Verbose = v
 	})
 }
 
@@ -225,3 +562,9 @@ func Window(v int) ModelOption {
 		opts.Window = v
 	})
 }
+
+func WindowType(typ window.Type) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.WindowType = typ
+	})
+}
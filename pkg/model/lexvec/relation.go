@@ -0,0 +1,263 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lexvec
+
+import (
+	"bufio"
+	"container/list"
+	"encoding/binary"
+	"io/ioutil"
+	"math"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/corpus/cooccurrence/encode"
+)
+
+// relations looks up the relation value of the pair encoded by encode.EncodeBigram.
+type relations interface {
+	lookup(enc uint64) float64
+	err() error
+	close() error
+}
+
+type memoryRelations map[uint64]float64
+
+func (r memoryRelations) lookup(enc uint64) float64 {
+	return r[enc]
+}
+
+func (r memoryRelations) err() error {
+	return nil
+}
+
+func (r memoryRelations) close() error {
+	return nil
+}
+
+// recordSize is the bytes of (column uint32, value float64) on disk.
+const recordSize = 4 + 8
+
+// cacheShards is the number of the shards of the cached rows, each of which is locked on its own
+// so that the goroutines looking up the different rows don't wait for each other.
+const cacheShards = 64
+
+type row struct {
+	id   int
+	cols []uint32
+	vals []float64
+}
+
+func (r *row) find(col uint32) float64 {
+	i := sort.Search(len(r.cols), func(i int) bool {
+		return r.cols[i] >= col
+	})
+	if i < len(r.cols) && r.cols[i] == col {
+		return r.vals[i]
+	}
+	return 0
+}
+
+// rowCache keeps the most recently used rows up to capacity.
+type rowCache struct {
+	mu       sync.Mutex
+	capacity int
+	rows     map[int]*list.Element
+	lru      *list.List
+}
+
+func (c *rowCache) get(id int) (*row, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.rows[id]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(e)
+	return e.Value.(*row), true
+}
+
+func (c *rowCache) put(r *row) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.rows[r.id]; ok {
+		return
+	}
+	c.rows[r.id] = c.lru.PushFront(r)
+	if c.lru.Len() > c.capacity {
+		last := c.lru.Back()
+		c.lru.Remove(last)
+		delete(c.rows, last.Value.(*row).id)
+	}
+}
+
+// externalRelations stores the relations on disk grouped by the larger word id,
+// and keeps only the offsets of rows and the most recently used rows in memory.
+type externalRelations struct {
+	file    *os.File
+	offsets []int64
+	shards  []*rowCache
+
+	errMu   sync.Mutex
+	lastErr error
+}
+
+// newExternalRelations writes the relations of the cells given by each in the ascending order of
+// the encoded pairs, i.e. row by row, so that the file is written sequentially without them in memory.
+func newExternalRelations(
+	dir string,
+	rowSize, capacity int,
+	each func(func(enc uint64, f float64) error) error,
+	fn func(enc uint64, f float64) (float64, error),
+) (res *externalRelations, err error) {
+	if capacity <= 0 {
+		return nil, errors.Errorf("capacity of cached rows must be positive, got %d", capacity)
+	}
+
+	file, err := ioutil.TempFile(dir, "wego-lexvec")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			file.Close()
+			os.Remove(file.Name())
+		}
+	}()
+
+	var (
+		offsets = make([]int64, rowSize+1)
+		w       = bufio.NewWriter(file)
+		buf     = make([]byte, recordSize)
+		next    int
+		pos     int64
+		last    uint64
+	)
+	if err := each(func(enc uint64, f float64) error {
+		if pos > 0 && enc <= last {
+			return errors.Errorf("relations must be built in the ascending order of pairs, got %d after %d", enc, last)
+		}
+		last = enc
+		l1, l2 := encode.DecodeBigram(enc)
+		if int(l2) >= rowSize {
+			return errors.Errorf("id=%d is out of %d rows", l2, rowSize)
+		}
+		v, err := fn(enc, f)
+		if err != nil {
+			return err
+		}
+		for ; next <= int(l2); next++ {
+			offsets[next] = pos
+		}
+		binary.LittleEndian.PutUint32(buf[:4], uint32(l1))
+		binary.LittleEndian.PutUint64(buf[4:], math.Float64bits(v))
+		if _, err := w.Write(buf); err != nil {
+			return err
+		}
+		pos += recordSize
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	for ; next <= rowSize; next++ {
+		offsets[next] = pos
+	}
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+
+	n := cacheShards
+	if capacity < n {
+		n = capacity
+	}
+	shards := make([]*rowCache, n)
+	for i := range shards {
+		c := capacity / n
+		if i < capacity%n {
+			c++
+		}
+		shards[i] = &rowCache{
+			capacity: c,
+			rows:     make(map[int]*list.Element),
+			lru:      list.New(),
+		}
+	}
+	return &externalRelations{
+		file:    file,
+		offsets: offsets,
+		shards:  shards,
+	}, nil
+}
+
+func (r *externalRelations) lookup(enc uint64) float64 {
+	l1, l2 := encode.DecodeBigram(enc)
+	id := int(l2)
+	if id+1 >= len(r.offsets) {
+		return 0
+	}
+
+	shard := r.shards[id%len(r.shards)]
+	if cached, ok := shard.get(id); ok {
+		return cached.find(uint32(l1))
+	}
+	loaded, err := r.load(id)
+	if err != nil {
+		r.errMu.Lock()
+		if r.lastErr == nil {
+			r.lastErr = err
+		}
+		r.errMu.Unlock()
+		return 0
+	}
+	shard.put(loaded)
+	return loaded.find(uint32(l1))
+}
+
+// load reads the row of id, whose columns are already sorted on disk.
+func (r *externalRelations) load(id int) (*row, error) {
+	s, e := r.offsets[id], r.offsets[id+1]
+	buf := make([]byte, e-s)
+	if _, err := r.file.ReadAt(buf, s); err != nil {
+		return nil, errors.Wrapf(err, "failed to read relations of id=%d", id)
+	}
+	size := len(buf) / recordSize
+	res := &row{
+		id:   id,
+		cols: make([]uint32, size),
+		vals: make([]float64, size),
+	}
+	for i := 0; i < size; i++ {
+		rec := buf[i*recordSize : (i+1)*recordSize]
+		res.cols[i] = binary.LittleEndian.Uint32(rec[:4])
+		res.vals[i] = math.Float64frombits(binary.LittleEndian.Uint64(rec[4:]))
+	}
+	return res, nil
+}
+
+func (r *externalRelations) err() error {
+	r.errMu.Lock()
+	defer r.errMu.Unlock()
+	return r.lastErr
+}
+
+func (r *externalRelations) close() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+	return os.Remove(r.file.Name())
+}
@@ -0,0 +1,75 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lexvec
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/corpus/cooccurrence/encode"
+)
+
+func TestExternalRelations(t *testing.T) {
+	em := map[uint64]float64{
+		encode.EncodeBigram(0, 1): 1,
+		encode.EncodeBigram(0, 3): 2,
+		encode.EncodeBigram(2, 1): 3,
+		encode.EncodeBigram(3, 3): 4,
+	}
+	double := func(_ uint64, f float64) (float64, error) {
+		return f * 2, nil
+	}
+
+	each := func(fn func(uint64, float64) error) error {
+		encs := make([]uint64, 0, len(em))
+		for enc := range em {
+			encs = append(encs, enc)
+		}
+		sort.Slice(encs, func(i, j int) bool {
+			return encs[i] < encs[j]
+		})
+		for _, enc := range encs {
+			if err := fn(enc, em[enc]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	rel, err := newExternalRelations("", 4, 1, each, double)
+	assert.NoError(t, err)
+	defer rel.close()
+
+	for i := 0; i < 2; i++ {
+		for enc, f := range em {
+			assert.Equal(t, f*2, rel.lookup(enc))
+		}
+	}
+	assert.Equal(t, 0., rel.lookup(encode.EncodeBigram(1, 3)))
+	assert.Equal(t, 0., rel.lookup(encode.EncodeBigram(10, 11)))
+	assert.Equal(t, 1, rel.shards[0].lru.Len())
+	assert.NoError(t, rel.err())
+
+	unordered := func(fn func(uint64, float64) error) error {
+		if err := fn(encode.EncodeBigram(2, 1), 1); err != nil {
+			return err
+		}
+		return fn(encode.EncodeBigram(0, 1), 1)
+	}
+	_, err = newExternalRelations("", 4, 1, unordered, double)
+	assert.Error(t, err)
+}
@@ -0,0 +1,140 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manifest
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/model"
+	"github.com/ynqa/wego/pkg/util/fileutil"
+)
+
+// Manifest is the machine-readable record of a training run.
+type Manifest struct {
+	OptionsHash string                 `json:"options_hash"`
+	Options     interface{}            `json:"options"`
+	Config      string                 `json:"config,omitempty"`
+	Flags       map[string]interface{} `json:"flags,omitempty"`
+	Corpus      Corpus                 `json:"corpus"`
+	StartTime   time.Time              `json:"start_time"`
+	EndTime     time.Time              `json:"end_time"`
+	Metrics     map[string]float64     `json:"metrics"`
+	Output      string                 `json:"output"`
+	Error       string                 `json:"error,omitempty"`
+}
+
+type Corpus struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+}
+
+func (m *Manifest) Write(w io.Writer) error {
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode manifest")
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// HashOptions returns the git-style (blob) SHA-1 of the options encoded in JSON.
+func HashOptions(opts interface{}) (string, error) {
+	b, err := json.Marshal(opts)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to encode options")
+	}
+	h := sha1.New()
+	fmt.Fprintf(h, "blob %d\x00", len(b))
+	h.Write(b)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Checksum returns the SHA-256 of r.
+func Checksum(r io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Recorder is the model.Hook to fill Manifest during training.
+type Recorder struct {
+	model.BaseHook
+	Manifest Manifest
+
+	elapsed time.Duration
+	err     error
+}
+
+func NewRecorder(corpusPath, outputPath string) *Recorder {
+	return &Recorder{
+		Manifest: Manifest{
+			Corpus: Corpus{
+				Path: corpusPath,
+			},
+			Metrics: make(map[string]float64),
+			Output:  outputPath,
+		},
+	}
+}
+
+func (r *Recorder) BeforeTrain(opts interface{}) {
+	r.Manifest.StartTime = time.Now()
+	r.Manifest.Options = opts
+	r.Manifest.OptionsHash, r.err = HashOptions(opts)
+}
+
+func (r *Recorder) AfterIter(p model.Progress) {
+	r.elapsed += p.Elapsed
+	r.Manifest.Metrics["iter"] = float64(p.Iter)
+	r.Manifest.Metrics["trained"] = float64(p.Trained)
+	r.Manifest.Metrics["lr"] = p.LR
+	r.Manifest.Metrics["elapsed_seconds"] = r.elapsed.Seconds()
+}
+
+func (r *Recorder) AfterTrain(err error) {
+	r.Manifest.EndTime = time.Now()
+	if err != nil {
+		r.Manifest.Error = err.Error()
+	}
+}
+
+// WriteFile computes the checksum of the corpus and writes the manifest into path.
+func (r *Recorder) WriteFile(path string) error {
+	if r.err != nil {
+		return r.err
+	}
+	if r.Manifest.Corpus.Path != "" {
+		f, err := os.Open(r.Manifest.Corpus.Path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if r.Manifest.Corpus.SHA256, err = Checksum(f); err != nil {
+			return errors.Wrapf(err, "failed to compute checksum of %s", r.Manifest.Corpus.Path)
+		}
+	}
+	return fileutil.WriteAtomic(path, r.Manifest.Write)
+}
@@ -0,0 +1,74 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/model"
+)
+
+func TestHashOptions(t *testing.T) {
+	type options struct {
+		Dim   int
+		Hooks model.Hooks `json:"-"`
+	}
+
+	// equals to `echo -n '{"Dim":10}' | git hash-object --stdin`
+	h, err := HashOptions(options{Dim: 10, Hooks: model.Hooks{model.BaseHook{}}})
+	assert.NoError(t, err)
+	assert.Equal(t, "a8b327bcdc69e739108fd3d8f45894c4ef00aa81", h)
+
+	other, err := HashOptions(options{Dim: 20})
+	assert.NoError(t, err)
+	assert.NotEqual(t, h, other)
+}
+
+func TestChecksum(t *testing.T) {
+	sum, err := Checksum(strings.NewReader("a b c"))
+	assert.NoError(t, err)
+	assert.Len(t, sum, 64)
+}
+
+func TestRecorder(t *testing.T) {
+	r := NewRecorder("input.txt", "output.txt")
+	var hook model.Hook = r
+	hook.BeforeTrain(struct{ Dim int }{Dim: 10})
+	hook.AfterIter(model.Progress{Iter: 1, Trained: 5, LR: 0.02, Elapsed: time.Second})
+	hook.AfterIter(model.Progress{Iter: 2, Trained: 5, LR: 0.01, Elapsed: time.Second})
+	hook.AfterTrain(nil)
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, r.Manifest.Write(buf))
+
+	var m Manifest
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &m))
+	assert.Equal(t, "output.txt", m.Output)
+	assert.Equal(t, "input.txt", m.Corpus.Path)
+	assert.Equal(t, map[string]float64{
+		"iter":            2,
+		"trained":         5,
+		"lr":              0.01,
+		"elapsed_seconds": 2,
+	}, m.Metrics)
+	assert.False(t, m.EndTime.Before(m.StartTime))
+	assert.Empty(t, m.Error)
+}
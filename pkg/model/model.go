@@ -15,14 +15,66 @@
 package model
 
 import (
+	"context"
 	"io"
 
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/corpus/dictionary"
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/embutil"
 	"github.com/ynqa/wego/pkg/model/modelutil/matrix"
 	"github.com/ynqa/wego/pkg/model/modelutil/vector"
 )
 
-type Model interface {
-	Train(io.ReadSeeker) error
+// Trainer is the common interface to train and save word vectors for all models.
+type Trainer interface {
+	Train(context.Context, io.Reader) error
 	Save(io.Writer, vector.Type) error
+	// Options returns the options of the model, e.g. word2vec.Options.
+	Options() interface{}
+}
+
+type Model interface {
+	Trainer
 	WordVector(vector.Type) *matrix.Matrix
 }
+
+// Close releases the resources held by mod after training, e.g. the matrices mapped into memory,
+// if mod implements io.Closer.
+func Close(mod Trainer) error {
+	if c, ok := mod.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Vocabulary is implemented by the models which expose the dictionary of the trained words.
+type Vocabulary interface {
+	Dictionary() *dictionary.Dictionary
+}
+
+// Snapshot copies the word vectors of mod with the words, e.g. to evaluate them in training
+// while the other goroutines update them meanwhile.
+func Snapshot(mod Model) (embedding.Embeddings, error) {
+	voc, ok := mod.(Vocabulary)
+	if !ok {
+		return nil, errors.New("the model has no vocabulary")
+	}
+	dic, mat := voc.Dictionary(), mod.WordVector(vector.Single)
+	embs := make(embedding.Embeddings, 0, mat.Row())
+	for id := 0; id < mat.Row(); id++ {
+		word, ok := dic.Word(id)
+		if !ok {
+			continue
+		}
+		vec := append([]float64(nil), mat.Slice(id)...)
+		embs = append(embs, embedding.Embedding{
+			Word:   word,
+			Dim:    len(vec),
+			Vector: vec,
+			Norm:   embutil.Norm(vec),
+		})
+	}
+	return embs, nil
+}
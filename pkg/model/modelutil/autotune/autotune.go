@@ -0,0 +1,128 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotune
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultInterval is the interval to measure the throughput.
+const DefaultInterval = time.Second
+
+// tolerance is the ratio of the throughput to be regarded as improved, not the noise.
+const tolerance = 0.05
+
+// Tuner limits the number of the goroutines working at once, which starts from 1 and is tuned up to max
+// by hill climbing on the throughput measured in every interval: it's doubled while the throughput improves,
+// and then moved by one toward the better throughput, so that it settles around the number where more goroutines
+// only add the contention, e.g. on the hyperthreads or the shared machines.
+type Tuner struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+	now  func() time.Time
+
+	max, limit, active int
+	interval           time.Duration
+
+	start    time.Time
+	items    int
+	last     float64
+	dir      int
+	doubling bool
+}
+
+func New(max int, interval time.Duration) *Tuner {
+	t := &Tuner{
+		now:      time.Now,
+		max:      max,
+		limit:    1,
+		interval: interval,
+		start:    time.Now(),
+		dir:      1,
+		doubling: true,
+	}
+	t.cond = sync.NewCond(&t.mu)
+	return t
+}
+
+// Acquire blocks while the limit of the goroutines are working, and the returned function releases it
+// with n items done. It's no-op for the nil tuner.
+func (t *Tuner) Acquire(n int) func() {
+	if t == nil {
+		return func() {}
+	}
+	t.mu.Lock()
+	for t.active >= t.limit {
+		t.cond.Wait()
+	}
+	t.active++
+	t.mu.Unlock()
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.active--
+		t.items += n
+		t.tune()
+		t.cond.Broadcast()
+	}
+}
+
+// Parts returns the number of the parts to split size items into for the goroutines, which is goroutines
+// for the nil tuner, otherwise the parts of batch items so that the tuner can change the number in the iteration.
+func (t *Tuner) Parts(goroutines, size, batch int) int {
+	if t == nil {
+		return goroutines
+	}
+	if parts := (size + batch - 1) / batch; parts > goroutines {
+		return parts
+	}
+	return goroutines
+}
+
+// Limit returns the current number of the goroutines to work at once, 0 for the nil tuner.
+func (t *Tuner) Limit() int {
+	if t == nil {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.limit
+}
+
+func (t *Tuner) tune() {
+	now := t.now()
+	elapsed := now.Sub(t.start)
+	if elapsed < t.interval {
+		return
+	}
+	rate := float64(t.items) / elapsed.Seconds()
+	if rate <= t.last*(1+tolerance) {
+		t.dir, t.doubling = -t.dir, false
+	}
+	t.last, t.items, t.start = rate, 0, now
+
+	next := t.limit + t.dir
+	if t.doubling {
+		next = t.limit * 2
+	}
+	switch {
+	case next > t.max:
+		next, t.dir, t.doubling = t.max, -1, false
+	case next < 1:
+		next, t.dir = 1, 1
+	}
+	t.limit = next
+}
@@ -0,0 +1,79 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autotune
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTune(t *testing.T) {
+	tuner := New(16, time.Second)
+	clock := time.Unix(0, 0)
+	tuner.now, tuner.start = func() time.Time { return clock }, clock
+
+	// the throughput is the best on 5 goroutines, and gets worse by the contention over them.
+	throughput := func(n int) int {
+		if n <= 5 {
+			return 100 * n
+		}
+		return 500 - 50*(n-5)
+	}
+	var limits []int
+	for i := 0; i < 20; i++ {
+		clock = clock.Add(time.Second)
+		tuner.Acquire(throughput(tuner.Limit()))()
+		limits = append(limits, tuner.Limit())
+	}
+	assert.Equal(t, []int{2, 4, 8, 7, 6, 5, 4, 5, 6, 5, 4, 5, 6, 5, 4, 5, 6, 5, 4, 5}, limits)
+}
+
+func TestAcquire(t *testing.T) {
+	tuner := New(4, time.Hour)
+	tuner.limit = 2
+
+	var (
+		mu           sync.Mutex
+		active, peak int
+		wg           sync.WaitGroup
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer tuner.Acquire(1)()
+			mu.Lock()
+			active++
+			if active > peak {
+				peak = active
+			}
+			mu.Unlock()
+			time.Sleep(time.Millisecond)
+			mu.Lock()
+			active--
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 2, peak)
+	assert.Equal(t, 10, tuner.items)
+
+	var nilTuner *Tuner
+	nilTuner.Acquire(1)()
+	assert.Equal(t, 0, nilTuner.Limit())
+}
@@ -0,0 +1,82 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package budget
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Budget stops training by the derived context once the wall-clock or the processed tokens run out.
+type Budget struct {
+	parent    context.Context
+	cancel    context.CancelFunc
+	maxTokens int64
+	tokens    int64
+	exhausted int32
+}
+
+// New derives the context from ctx which is done after maxDuration or maxTokens.
+// Zero means unlimited for both.
+func New(ctx context.Context, maxDuration time.Duration, maxTokens int) (context.Context, *Budget) {
+	b := &Budget{
+		parent:    ctx,
+		maxTokens: int64(maxTokens),
+	}
+	ctx, b.cancel = context.WithCancel(ctx)
+	if maxDuration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, maxDuration)
+		parentCancel := b.cancel
+		b.cancel = func() {
+			cancel()
+			parentCancel()
+		}
+	}
+	return ctx, b
+}
+
+// Add counts n processed tokens, and cancels the context if they reach the limit.
+func (b *Budget) Add(n int) {
+	if b.maxTokens <= 0 {
+		return
+	}
+	if atomic.AddInt64(&b.tokens, int64(n)) >= b.maxTokens {
+		atomic.StoreInt32(&b.exhausted, 1)
+		b.cancel()
+	}
+}
+
+// Tokens returns the number of processed tokens.
+func (b *Budget) Tokens() int {
+	return int(atomic.LoadInt64(&b.tokens))
+}
+
+// Result releases the context and returns err, or nil if err is caused by running out of the budget
+// rather than by the parent context.
+func (b *Budget) Result(err error) error {
+	b.cancel()
+	if err == nil || b.parent.Err() != nil {
+		return err
+	}
+	cause := errors.Cause(err)
+	if cause == context.DeadlineExceeded || (cause == context.Canceled && atomic.LoadInt32(&b.exhausted) == 1) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,63 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package budget
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBudgetTokens(t *testing.T) {
+	ctx, b := New(context.Background(), 0, 3)
+	b.Add(1)
+	b.Add(1)
+	assert.NoError(t, ctx.Err())
+	b.Add(1)
+	assert.Equal(t, context.Canceled, ctx.Err())
+	assert.Equal(t, 3, b.Tokens())
+	assert.NoError(t, b.Result(ctx.Err()))
+}
+
+func TestBudgetDuration(t *testing.T) {
+	ctx, b := New(context.Background(), time.Millisecond, 0)
+	<-ctx.Done()
+	assert.NoError(t, b.Result(ctx.Err()))
+}
+
+func TestBudgetUnlimited(t *testing.T) {
+	ctx, b := New(context.Background(), 0, 0)
+	b.Add(100)
+	assert.NoError(t, ctx.Err())
+	assert.NoError(t, b.Result(nil))
+	assert.Equal(t, context.Canceled, ctx.Err())
+}
+
+func TestBudgetParentCanceled(t *testing.T) {
+	parent, cancel := context.WithCancel(context.Background())
+	ctx, b := New(parent, time.Hour, 10)
+	cancel()
+	assert.Equal(t, context.Canceled, b.Result(ctx.Err()))
+}
+
+func TestBudgetOtherError(t *testing.T) {
+	_, b := New(context.Background(), 0, 1)
+	b.Add(1)
+	err := errors.New("fail")
+	assert.Equal(t, err, b.Result(err))
+}
@@ -0,0 +1,80 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build cgo && blas
+// +build cgo,blas
+
+package kernel
+
+// Build with `-tags blas` to route the kernel through CBLAS,
+// e.g. OpenBLAS on linux or Accelerate on darwin.
+
+/*
+#cgo linux LDFLAGS: -lopenblas
+#cgo darwin LDFLAGS: -framework Accelerate
+#cgo darwin CFLAGS: -DACCELERATE_NEW_LAPACK
+
+#ifdef __APPLE__
+#include <Accelerate/Accelerate.h>
+#else
+#include <cblas.h>
+#endif
+*/
+import "C"
+
+import (
+	"unsafe"
+)
+
+func init() {
+	Register(BLAS, Kernel{
+		Dot:  cblasDot,
+		Axpy: cblasAxpy,
+		Scal: cblasScal,
+	})
+}
+
+func cblasDot(x, y []float64) float64 {
+	if len(x) == 0 {
+		return 0
+	}
+	return float64(C.cblas_ddot(
+		C.int(len(x)),
+		(*C.double)(unsafe.Pointer(&x[0])), 1,
+		(*C.double)(unsafe.Pointer(&y[0])), 1,
+	))
+}
+
+func cblasAxpy(alpha float64, x, y []float64) {
+	if len(x) == 0 {
+		return
+	}
+	C.cblas_daxpy(
+		C.int(len(x)),
+		C.double(alpha),
+		(*C.double)(unsafe.Pointer(&x[0])), 1,
+		(*C.double)(unsafe.Pointer(&y[0])), 1,
+	)
+}
+
+func cblasScal(alpha float64, x []float64) {
+	if len(x) == 0 {
+		return
+	}
+	C.cblas_dscal(
+		C.int(len(x)),
+		C.double(alpha),
+		(*C.double)(unsafe.Pointer(&x[0])), 1,
+	)
+}
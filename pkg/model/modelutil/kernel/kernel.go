@@ -0,0 +1,105 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kernel
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Type is the name of backend to compute the dense operations of updates.
+type Type = string
+
+const (
+	Go   Type = "go"
+	BLAS Type = "blas"
+)
+
+// Kernel is the dense operations on vectors, which must be safe for concurrent use.
+// The operations are chosen once by the backend when the model is built.
+type Kernel struct {
+	// Dot returns x・y.
+	Dot func(x, y []float64) float64
+	// Axpy computes y += alpha*x.
+	Axpy func(alpha float64, x, y []float64)
+	// Scal computes x *= alpha.
+	Scal func(alpha float64, x []float64)
+}
+
+var (
+	mu       sync.RWMutex
+	registry = map[Type]Kernel{
+		Go: {
+			Dot:  dot,
+			Axpy: axpy,
+			Scal: scal,
+		},
+	}
+)
+
+// Register makes the kernel available by typ, it is called from init of the optional backends.
+func Register(typ Type, k Kernel) {
+	mu.Lock()
+	defer mu.Unlock()
+	registry[typ] = k
+}
+
+func Get(typ Type) (Kernel, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	k, ok := registry[typ]
+	if !ok {
+		return Kernel{}, errors.Errorf("backend %s is not available in this build, one of: %v", typ, available())
+	}
+	return k, nil
+}
+
+// Available returns the backends compiled into the binary.
+func Available() []Type {
+	mu.RLock()
+	defer mu.RUnlock()
+	return available()
+}
+
+func available() []Type {
+	res := make([]Type, 0, len(registry))
+	for typ := range registry {
+		res = append(res, typ)
+	}
+	sort.Strings(res)
+	return res
+}
+
+func dot(x, y []float64) float64 {
+	var res float64
+	for i := range x {
+		res += x[i] * y[i]
+	}
+	return res
+}
+
+func axpy(alpha float64, x, y []float64) {
+	for i := range x {
+		y[i] += alpha * x[i]
+	}
+}
+
+func scal(alpha float64, x []float64) {
+	for i := range x {
+		x[i] *= alpha
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kernel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKernel(t *testing.T) {
+	for _, typ := range Available() {
+		t.Run(typ, func(t *testing.T) {
+			k, err := Get(typ)
+			assert.NoError(t, err)
+
+			x, y := []float64{1, 2, 3}, []float64{4, 5, 6}
+			assert.InDelta(t, 32., k.Dot(x, y), 1e-12)
+			k.Axpy(-2, x, y)
+			assert.InDeltaSlice(t, []float64{2, 1, 0}, y, 1e-12)
+			k.Scal(0.5, y)
+			assert.InDeltaSlice(t, []float64{1, 0.5, 0}, y, 1e-12)
+		})
+	}
+}
+
+func TestGetUnavailable(t *testing.T) {
+	_, err := Get("unknown")
+	assert.Error(t, err)
+}
@@ -0,0 +1,112 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lrscale
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/corpus/dictionary"
+	"github.com/ynqa/wego/pkg/util/fileutil"
+)
+
+// Scale is the per-word scale of the learning rate, which is shared by the optimizers of all models
+// and applies to the updates of the word vectors and the context vectors of each word.
+// The nil Scale is 1 for all words.
+type Scale struct {
+	scales []float64
+}
+
+// New returns the scale of words in dic, and nil if it's 1 for all words.
+// The scale of each word is the product of (minFreq/freq)^power, which damps the updates of
+// the frequent words, its weight if given, and 0 if it's frozen.
+func New(dic *dictionary.Dictionary, power float64, weights map[string]float64, frozen []string) *Scale {
+	scales := make([]float64, dic.Len())
+	for i := range scales {
+		scales[i] = 1
+	}
+	var scaled bool
+	if power != 0 {
+		minFreq := math.MaxInt64
+		for id := 0; id < dic.Len(); id++ {
+			if freq := dic.IDFreq(id); freq > 0 && freq < minFreq {
+				minFreq = freq
+			}
+		}
+		for id := range scales {
+			if freq := dic.IDFreq(id); freq > 0 {
+				scales[id] = math.Pow(float64(minFreq)/float64(freq), power)
+				scaled = true
+			}
+		}
+	}
+	for word, weight := range weights {
+		if id, ok := dic.ID(word); ok {
+			scales[id] *= weight
+			scaled = true
+		}
+	}
+	for _, word := range frozen {
+		if id, ok := dic.ID(word); ok {
+			scales[id] = 0
+			scaled = true
+		}
+	}
+	if !scaled {
+		return nil
+	}
+	return &Scale{
+		scales: scales,
+	}
+}
+
+// Of returns the scale of the row. The rows of the matrix which stacks the word vectors and
+// the context vectors (e.g. GloVe and LexVec) are mapped to the ids of words by modulo.
+func (s *Scale) Of(row int) float64 {
+	if s == nil {
+		return 1
+	}
+	return s.scales[row%len(s.scales)]
+}
+
+// LoadWeights reads the lines of `word weight`. Empty lines and lines starting with # are skipped.
+func LoadWeights(r io.Reader) (map[string]float64, error) {
+	weights := make(map[string]float64)
+	s := fileutil.NewScanner(r, bufio.ScanLines)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, errors.Errorf("line %d must be `word weight`, got %q", n, line)
+		}
+		weight, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil || weight < 0 {
+			return nil, errors.Errorf("weight of line %d must be a non-negative number, got %q", n, fields[1])
+		}
+		weights[fields[0]] = weight
+	}
+	if err := s.Err(); err != nil && err != io.EOF {
+		return nil, errors.Wrapf(err, "failed to scan")
+	}
+	return weights, nil
+}
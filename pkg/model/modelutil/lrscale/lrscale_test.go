@@ -0,0 +1,51 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lrscale
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/corpus/dictionary"
+)
+
+func TestScale(t *testing.T) {
+	dic := dictionary.New()
+	dic.Add("a", "a", "a", "a", "b", "c")
+
+	assert.Nil(t, New(dic, 0, nil, []string{"unknown"}))
+	var s *Scale
+	assert.Equal(t, 1., s.Of(0))
+
+	s = New(dic, 0.5, map[string]float64{"c": 2}, []string{"b"})
+	assert.InDelta(t, 0.5, s.Of(0), 1e-9)
+	assert.Equal(t, 0., s.Of(1))
+	assert.Equal(t, 2., s.Of(2))
+	// the context vector of a in the stacked matrix.
+	assert.InDelta(t, 0.5, s.Of(3), 1e-9)
+}
+
+func TestLoadWeights(t *testing.T) {
+	weights, err := LoadWeights(strings.NewReader("# comment\nthe 0.1\n\nrare 2\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]float64{"the": 0.1, "rare": 2}, weights)
+
+	_, err = LoadWeights(strings.NewReader("the\n"))
+	assert.Error(t, err)
+	_, err = LoadWeights(strings.NewReader("the -1\n"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,118 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package matrix
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"unsafe"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// Memory allocates the matrices on the heap.
+	Memory = "memory"
+	// Mmap allocates the matrices in the files mapped into memory, as mmap:<dir>.
+	Mmap = "mmap"
+)
+
+// Backing allocates the matrices, in memory or in the files mapped into memory under dir.
+// The mapped files are shared with the page cache, so the models larger than RAM can be trained
+// (slowly, by paging), and the matrices trained so far remain in the files if the process crashes,
+// which the next run on the same dir resumes from. Close unmaps them.
+type Backing struct {
+	dir    string
+	mapped []*Matrix
+}
+
+// ParseBacking parses the spec of Backing, "memory" (or empty) or "mmap:<dir>".
+func ParseBacking(spec string) (*Backing, error) {
+	if spec == "" || spec == Memory {
+		return &Backing{}, nil
+	}
+	if !strings.HasPrefix(spec, Mmap+":") {
+		return nil, errors.Errorf("invalid matrix backing: %s not in %s|%s:<dir>", spec, Memory, Mmap)
+	}
+	dir := strings.TrimPrefix(spec, Mmap+":")
+	if dir == "" {
+		return nil, errors.Errorf("directory is required for %s backing", Mmap)
+	}
+	return &Backing{dir: dir}, nil
+}
+
+// New returns the matrix initialized by fn, which is mapped to <dir>/<name>.mat of Version for mmap.
+// The matrix in the existing file of the same shape is resumed as it is instead of initialized by fn.
+func (b *Backing) New(name string, row, col int, fn func(int, []float64)) (*Matrix, error) {
+	if b == nil || b.dir == "" {
+		return New(row, col, fn), nil
+	}
+	if err := os.MkdirAll(b.dir, 0755); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(b.dir, name+".mat")
+	data, existed, err := mapFile(path, headerSize+row*col*8)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to map %s", path)
+	}
+	array := floats(data[headerSize:])
+	if existed && resumable(data, row, col) {
+		fn = nil
+	} else {
+		putHeader(data, row, col, nativeOrder())
+	}
+	mat := newMatrix(array, row, col, fn)
+	mat.unmap = func() error {
+		return unmapFile(data)
+	}
+	b.mapped = append(b.mapped, mat)
+	return mat, nil
+}
+
+// resumable reports whether data is the matrix of row x col written by this build.
+func resumable(data []byte, row, col int) bool {
+	h, err := readHeader(bytes.NewReader(data), int64(len(data)))
+	return err == nil && h.version == Version && h.size == headerSize &&
+		h.row == row && h.col == col && h.order == nativeOrder()
+}
+
+// Close unmaps the matrices allocated by b, which must not be used after.
+func (b *Backing) Close() error {
+	if b == nil {
+		return nil
+	}
+	var err error
+	for _, mat := range b.mapped {
+		if e := mat.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	b.mapped = nil
+	return err
+}
+
+func floats(b []byte) []float64 {
+	var res []float64
+	if len(b) == 0 {
+		return res
+	}
+	h := (*reflect.SliceHeader)(unsafe.Pointer(&res))
+	h.Data = uintptr(unsafe.Pointer(&b[0]))
+	h.Len, h.Cap = len(b)/8, len(b)/8
+	return res
+}
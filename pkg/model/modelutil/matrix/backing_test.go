@@ -0,0 +1,82 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package matrix
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBacking(t *testing.T) {
+	for _, spec := range []string{"", "memory", "mmap:/tmp/wego"} {
+		_, err := ParseBacking(spec)
+		assert.NoError(t, err, spec)
+	}
+	for _, spec := range []string{"disk", "mmap", "mmap:"} {
+		_, err := ParseBacking(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestBacking(t *testing.T) {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		t.Skip("mmap is not supported")
+	}
+	dir, err := ioutil.TempDir("", "wego-matrix")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	b, err := ParseBacking("mmap:" + dir)
+	assert.NoError(t, err)
+	mat, err := b.New("word", 3, 2, func(i int, vec []float64) {
+		vec[0], vec[1] = float64(i), float64(i)/2
+	})
+	assert.NoError(t, err)
+	mat.Slice(2)[1] = 5
+
+	// the updates are in the file without closing it as if the process crashed.
+	loaded, err := Load(filepath.Join(dir, "word.mat"))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, loaded.Row())
+	assert.Equal(t, 2, loaded.Col())
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, mat.Slice(i), loaded.Slice(i))
+	}
+	assert.Equal(t, []float64{2, 5}, loaded.Slice(2))
+	assert.NoError(t, b.Close())
+
+	// the next run resumes the matrix of the same shape instead of initializing it.
+	resumed, err := b.New("word", 3, 2, func(_ int, vec []float64) {
+		vec[0], vec[1] = -1, -1
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{2, 5}, resumed.Slice(2))
+	assert.NoError(t, resumed.Close())
+	reshaped, err := b.New("word", 2, 2, func(_ int, vec []float64) {
+		vec[0], vec[1] = -1, -1
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{-1, -1}, reshaped.Slice(1))
+	assert.NoError(t, b.Close())
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "broken.mat"), []byte("broken"), 0644))
+	_, err = Load(filepath.Join(dir, "broken.mat"))
+	assert.Error(t, err)
+}
@@ -18,20 +18,39 @@ type Matrix struct {
 	array []float64
 	row   int
 	col   int
+	// unmap releases the array mapped from the file, see Backing.
+	unmap func() error
 }
 
 func New(row, col int, fn func(int, []float64)) *Matrix {
+	return newMatrix(make([]float64, row*col), row, col, fn)
+}
+
+func newMatrix(array []float64, row, col int, fn func(int, []float64)) *Matrix {
 	mat := &Matrix{
-		array: make([]float64, row*col),
+		array: array,
 		row:   row,
 		col:   col,
 	}
+	if fn == nil {
+		return mat
+	}
 	for i := 0; i < row; i++ {
 		fn(i, mat.Slice(i))
 	}
 	return mat
 }
 
+// Close unmaps the matrix mapped from the file, which must not be used after. It does nothing in memory.
+func (m *Matrix) Close() error {
+	if m.unmap == nil {
+		return nil
+	}
+	unmap := m.unmap
+	m.array, m.unmap = nil, nil
+	return unmap()
+}
+
 func (m *Matrix) startIndex(id int) int {
 	return id * m.col
 }
@@ -0,0 +1,52 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux || darwin
+// +build linux darwin
+
+package matrix
+
+import (
+	"os"
+	"syscall"
+)
+
+// mapFile maps the file of size bytes as shared with the page cache, and reports whether the file
+// already existed in the size, e.g. of the previous run to resume. Otherwise it's resized into size.
+func mapFile(path string, size int) ([]byte, bool, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, false, err
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, false, err
+	}
+	existed := stat.Size() == int64(size)
+	if !existed {
+		if err := f.Truncate(int64(size)); err != nil {
+			return nil, false, err
+		}
+	}
+	data, err := syscall.Mmap(int(f.Fd()), 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, false, err
+	}
+	return data, existed, nil
+}
+
+func unmapFile(data []byte) error {
+	return syscall.Munmap(data)
+}
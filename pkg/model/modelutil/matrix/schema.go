@@ -0,0 +1,218 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package matrix
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"unsafe"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/util/fileutil"
+)
+
+// Version is the layout of the files of the matrices written by this build, which is the last byte
+// of the magic, "WEGOMAT<version>". The fields of the header are in little endian.
+//
+//   - 1: the rows and the columns, followed by the values in the native byte order at 24.
+//   - 2: the rows, the columns, the size of the header and the byte order mark (1.0 in the native
+//     byte order of the writer), followed by the values at the size of the header.
+//
+// Load reads all versions up to Version, and Migrate rewrites the files of the older versions.
+// Since 2, the new fields are appended to the header within the same version, which the older
+// readers skip by the size of the header, and the version is bumped only when they can't read
+// the values any more, in which case the older readers refuse the file instead of misreading it.
+const Version = 2
+
+const (
+	magicPrefix = "WEGOMAT"
+	headerSize  = 40
+	v1Size      = 24
+)
+
+type header struct {
+	version  int
+	row, col int
+	size     int
+	order    binary.ByteOrder
+}
+
+// readHeader reads the header of any version up to Version from r, and checks n, the size of the file.
+func readHeader(r io.Reader, n int64) (header, error) {
+	data := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, data[:v1Size]); err != nil || string(data[:len(magicPrefix)]) != magicPrefix {
+		return header{}, errors.New("not the file of the matrix")
+	}
+	h := header{
+		version: int(data[len(magicPrefix)] - '0'),
+		row:     int(binary.LittleEndian.Uint64(data[8:])),
+		col:     int(binary.LittleEndian.Uint64(data[16:])),
+	}
+	switch {
+	case h.version < 1 || h.version > 9:
+		return header{}, errors.Errorf("invalid version %q of the matrix", data[len(magicPrefix)])
+	case h.version > Version:
+		return header{}, errors.Errorf("version %d of the matrix is newer than %d supported by this build, upgrade wego", h.version, Version)
+	case h.version == 1:
+		h.size, h.order = v1Size, nativeOrder()
+	default:
+		if _, err := io.ReadFull(r, data[v1Size:]); err != nil {
+			return header{}, errors.New("truncated header of the matrix")
+		}
+		h.size = int(binary.LittleEndian.Uint64(data[24:]))
+		switch bom := data[32:40]; {
+		case math.Float64frombits(binary.LittleEndian.Uint64(bom)) == 1:
+			h.order = binary.LittleEndian
+		case math.Float64frombits(binary.BigEndian.Uint64(bom)) == 1:
+			h.order = binary.BigEndian
+		default:
+			return header{}, errors.New("invalid byte order mark of the matrix")
+		}
+		if h.size < headerSize || h.size%8 != 0 || int64(h.size) > n {
+			return header{}, errors.Errorf("invalid size %d of the header of the matrix", h.size)
+		}
+		// skip the fields appended by the newer builds.
+		if _, err := io.CopyN(ioutil.Discard, r, int64(h.size-headerSize)); err != nil {
+			return header{}, errors.New("truncated header of the matrix")
+		}
+	}
+	if h.row < 0 || h.col < 0 || (h.col > 0 && h.row > math.MaxInt64/8/h.col) || n != int64(h.size)+int64(h.row*h.col*8) {
+		return header{}, errors.Errorf("%d bytes, expected %dx%d matrix", n, h.row, h.col)
+	}
+	return h, nil
+}
+
+// putHeader writes the header of Version into data, whose values are in order.
+func putHeader(data []byte, row, col int, order binary.ByteOrder) {
+	copy(data, magicPrefix)
+	data[len(magicPrefix)] = byte('0' + Version)
+	binary.LittleEndian.PutUint64(data[8:], uint64(row))
+	binary.LittleEndian.PutUint64(data[16:], uint64(col))
+	binary.LittleEndian.PutUint64(data[24:], headerSize)
+	order.PutUint64(data[32:], math.Float64bits(1))
+}
+
+func nativeOrder() binary.ByteOrder {
+	x := uint16(1)
+	if *(*byte)(unsafe.Pointer(&x)) == 1 {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}
+
+// Load reads the matrix from the file of any version, e.g. to recover it after the crash.
+func Load(path string) (*Matrix, error) {
+	mat, _, err := LoadVersion(path)
+	return mat, err
+}
+
+// openMatrix opens the file of the matrix and reads its header, leaving the file at the values.
+func openMatrix(path string) (*os.File, header, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, header{}, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, header{}, err
+	}
+	h, err := readHeader(f, info.Size())
+	if err != nil {
+		f.Close()
+		return nil, header{}, errors.Wrap(err, path)
+	}
+	return f, h, nil
+}
+
+// ReadVersion returns the version of the file of the matrix, reading only the header.
+func ReadVersion(path string) (int, error) {
+	f, h, err := openMatrix(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	return h.version, nil
+}
+
+// LoadVersion reads the matrix as Load, and returns the version of the file as well.
+// The values are decoded while reading, so the file isn't held in memory besides the matrix.
+func LoadVersion(path string) (*Matrix, int, error) {
+	f, h, err := openMatrix(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	array := make([]float64, h.row*h.col)
+	buf := make([]byte, 8)
+	for i := range array {
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, 0, errors.Wrapf(err, "failed to read %s", path)
+		}
+		array[i] = math.Float64frombits(h.order.Uint64(buf))
+	}
+	return &Matrix{
+		array: array,
+		row:   h.row,
+		col:   h.col,
+	}, h.version, nil
+}
+
+// Write writes mat in the layout of Version.
+func Write(w io.Writer, mat *Matrix) error {
+	bw := bufio.NewWriter(w)
+	data := make([]byte, headerSize)
+	putHeader(data, mat.row, mat.col, nativeOrder())
+	if _, err := bw.Write(data); err != nil {
+		return err
+	}
+	order, buf := nativeOrder(), make([]byte, 8)
+	for _, v := range mat.array {
+		order.PutUint64(buf, math.Float64bits(v))
+		if _, err := bw.Write(buf); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// Migrate rewrites the file of the older version into Version in place, and returns the version
+// of the file before. The file of Version is left untouched. The values are copied as they are
+// in the byte order of the file, so the matrix isn't loaded into memory.
+func Migrate(path string) (int, error) {
+	f, h, err := openMatrix(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	if h.version == Version {
+		return h.version, nil
+	}
+	return h.version, fileutil.WriteAtomic(path, func(w io.Writer) error {
+		data := make([]byte, headerSize)
+		putHeader(data, h.row, h.col, h.order)
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+		_, err := io.Copy(w, f)
+		return err
+	})
+}
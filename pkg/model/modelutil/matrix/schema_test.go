@@ -0,0 +1,108 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package matrix
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wego-matrix")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	values := []float64{0, 0.5, 1, 1.5, 2, 2.5}
+	v1 := make([]byte, v1Size+len(values)*8)
+	copy(v1, "WEGOMAT1")
+	binary.LittleEndian.PutUint64(v1[8:], 3)
+	binary.LittleEndian.PutUint64(v1[16:], 2)
+	for i, v := range values {
+		nativeOrder().PutUint64(v1[v1Size+i*8:], math.Float64bits(v))
+	}
+	path := filepath.Join(dir, "word.mat")
+	assert.NoError(t, ioutil.WriteFile(path, v1, 0644))
+
+	mat, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{2, 2.5}, mat.Slice(2))
+
+	version, err := ReadVersion(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, version)
+	version, err = Migrate(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, version)
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "WEGOMAT2", string(data[:8]))
+	migrated, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, mat, migrated)
+
+	version, err = Migrate(path)
+	assert.NoError(t, err)
+	assert.Equal(t, Version, version)
+}
+
+func TestParseHeader(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, Write(&buf, New(1, 2, func(_ int, vec []float64) {
+		vec[0], vec[1] = 1, 2
+	})))
+	parseHeader := func(data []byte) (header, error) {
+		return readHeader(bytes.NewReader(data), int64(len(data)))
+	}
+	h, err := parseHeader(buf.Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, header{version: Version, row: 1, col: 2, size: headerSize, order: nativeOrder()}, h)
+
+	// the values written on the machine of the other byte order.
+	swapped := append([]byte{}, buf.Bytes()...)
+	other := binary.ByteOrder(binary.BigEndian)
+	if nativeOrder() == binary.BigEndian {
+		other = binary.LittleEndian
+	}
+	other.PutUint64(swapped[32:], math.Float64bits(1))
+	other.PutUint64(swapped[headerSize:], math.Float64bits(1))
+	other.PutUint64(swapped[headerSize+8:], math.Float64bits(2))
+	h, err = parseHeader(swapped)
+	assert.NoError(t, err)
+	assert.Equal(t, other, h.order)
+
+	// the fields appended to the header within the same version are skipped.
+	extended := append([]byte{}, buf.Bytes()[:headerSize]...)
+	binary.LittleEndian.PutUint64(extended[24:], headerSize+8)
+	extended = append(extended, make([]byte, 8)...)
+	extended = append(extended, buf.Bytes()[headerSize:]...)
+	h, err = parseHeader(extended)
+	assert.NoError(t, err)
+	assert.Equal(t, headerSize+8, h.size)
+
+	newer := append([]byte{}, buf.Bytes()...)
+	newer[7] = '0' + Version + 1
+	_, err = parseHeader(newer)
+	assert.Error(t, err)
+
+	_, err = parseHeader(buf.Bytes()[:buf.Len()-1])
+	assert.Error(t, err)
+}
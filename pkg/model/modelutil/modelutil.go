@@ -16,16 +16,68 @@ package modelutil
 
 import (
 	"math"
-)
+	"math/rand"
 
-var (
-	next uint64 = 1
+	"github.com/ynqa/wego/pkg/corpus/dictionary"
 )
 
-// NextRandom is linear congruential generator (rand.Intn).
-func NextRandom(value int) int {
-	next = next*uint64(25214903917) + 11
-	return int(next % uint64(value))
+// Rand is xorshift64* generator implementing rand.Source64.
+// It is not safe for concurrent use, each goroutine should own the one
+// instead of contending on the lock of the global source in math/rand.
+type Rand struct {
+	state uint64
+}
+
+func NewRand(seed int64) *Rand {
+	r := &Rand{}
+	r.Seed(seed)
+	return r
+}
+
+// SourceRand returns rand.Rand of src, or of the source for seed if src is nil.
+func SourceRand(src rand.Source, seed int64) *rand.Rand {
+	if src == nil {
+		src = rand.NewSource(seed)
+	}
+	return rand.New(src)
+}
+
+// NewRandFrom seeds Rand by the source which is injected to the model.
+func NewRandFrom(src rand.Source) *Rand {
+	return NewRand(src.Int63())
+}
+
+func (r *Rand) Seed(seed int64) {
+	// splitmix64 to avoid the zero state and the correlation between near seeds.
+	z := uint64(seed) + 0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	z ^= z >> 31
+	if z == 0 {
+		z = 1
+	}
+	r.state = z
+}
+
+func (r *Rand) Uint64() uint64 {
+	r.state ^= r.state >> 12
+	r.state ^= r.state << 25
+	r.state ^= r.state >> 27
+	return r.state * 2685821657736338717
+}
+
+func (r *Rand) Int63() int64 {
+	return int64(r.Uint64() >> 1)
+}
+
+// Intn returns the number in [0, n).
+func (r *Rand) Intn(n int) int {
+	return int(r.Uint64() % uint64(n))
+}
+
+// Float64 returns the number in [0.0, 1.0).
+func (r *Rand) Float64() float64 {
+	return float64(r.Uint64()>>11) / (1 << 53)
 }
 
 // IndexPerThread creates interval of indices per thread.
@@ -39,3 +91,16 @@ func IndexPerThread(threadSize, dataSize int) []int {
 	}
 	return indexPerThread
 }
+
+// WarmStart copies the vector of the word of row in init into vec, e.g. of the previous training.
+// The rows out of dic, e.g. of the contexts, and the words not in init are kept.
+func WarmStart(init map[string][]float64, dic *dictionary.Dictionary, row int, vec []float64) {
+	if len(init) == 0 || row >= dic.Len() {
+		return
+	}
+	if word, ok := dic.Word(row); ok {
+		if v, ok := init[word]; ok {
+			copy(vec, v)
+		}
+	}
+}
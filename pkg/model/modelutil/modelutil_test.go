@@ -0,0 +1,56 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modelutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/corpus/dictionary"
+)
+
+func TestRand(t *testing.T) {
+	r1, r2 := NewRand(1), NewRand(1)
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, r1.Uint64(), r2.Uint64())
+	}
+	assert.NotEqual(t, NewRand(1).Uint64(), NewRand(2).Uint64())
+
+	r := NewRand(0)
+	for i := 0; i < 1000; i++ {
+		f := r.Float64()
+		assert.True(t, 0 <= f && f < 1)
+		n := r.Intn(7)
+		assert.True(t, 0 <= n && n < 7)
+		assert.True(t, r.Int63() >= 0)
+	}
+}
+
+func TestWarmStart(t *testing.T) {
+	dic := dictionary.New()
+	dic.Add("a", "b")
+	init := map[string][]float64{"b": {1, 2}}
+
+	vec := []float64{0, 0, 9}
+	WarmStart(init, dic, 1, vec)
+	assert.Equal(t, []float64{1, 2, 9}, vec)
+
+	for _, row := range []int{0, 3} {
+		vec := []float64{0, 0}
+		WarmStart(init, dic, row, vec)
+		assert.Equal(t, []float64{0, 0}, vec)
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prefetch
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+
+	"github.com/ynqa/wego/pkg/model/modelutil"
+)
+
+// Queue hands off the batches from the reader to the workers, which blocks the reader while it is full,
+// so the memory is bounded by the batches in the queue and the workers.
+type Queue struct {
+	ch  chan job
+	src rand.Source
+}
+
+// job is the batch with its generator drawn in the order of the reader.
+type job struct {
+	batch []int
+	rng   *modelutil.Rand
+}
+
+// New returns Queue of size batches read ahead.
+func New(size int) *Queue {
+	return &Queue{
+		ch: make(chan job, size),
+	}
+}
+
+// Depth returns the number of the batches waiting for the workers, e.g. 0 means the reader is the bottleneck.
+func (q *Queue) Depth() int {
+	return len(q.ch)
+}
+
+func (q *Queue) Cap() int {
+	return cap(q.ch)
+}
+
+// RandSource sets src to seed the generator of each batch for work. They are drawn in the order of the reader
+// rather than of the workers, so that a single worker is reproducible.
+func (q *Queue) RandSource(src rand.Source) {
+	q.src = src
+}
+
+// Run reads the batches by read, which must close the channel at the end even if it fails,
+// and calls work of each batch by workers goroutines, where rng is nil without RandSource.
+// The batches are discarded once ctx is done, so that read stops.
+func (q *Queue) Run(ctx context.Context, workers int, read func(chan []int) error, work func(batch []int, rng *modelutil.Rand)) error {
+	in, errc := make(chan []int), make(chan error, 1)
+	go func() {
+		errc <- read(in)
+	}()
+	go func() {
+		defer close(q.ch)
+		for batch := range in {
+			j := job{batch: batch}
+			if q.src != nil {
+				j.rng = modelutil.NewRandFrom(q.src)
+			}
+			q.ch <- j
+		}
+	}()
+	wg := &sync.WaitGroup{}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range q.ch {
+				if ctx.Err() != nil {
+					continue
+				}
+				work(j.batch, j.rng)
+			}
+		}()
+	}
+	wg.Wait()
+	return <-errc
+}
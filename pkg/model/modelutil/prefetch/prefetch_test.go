@@ -0,0 +1,94 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prefetch
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/model/modelutil"
+)
+
+func batches(n int) func(chan []int) error {
+	return func(ch chan []int) error {
+		defer close(ch)
+		for i := 0; i < n; i++ {
+			ch <- []int{i}
+		}
+		return nil
+	}
+}
+
+func TestRun(t *testing.T) {
+	q := New(2)
+	assert.Equal(t, 2, q.Cap())
+
+	var (
+		mu  sync.Mutex
+		sum int
+	)
+	assert.NoError(t, q.Run(context.Background(), 3, batches(100), func(batch []int, _ *modelutil.Rand) {
+		mu.Lock()
+		defer mu.Unlock()
+		sum += batch[0]
+		// the reader can't read ahead over the capacity.
+		assert.True(t, q.Depth() <= q.Cap())
+	}))
+	assert.Equal(t, 4950, sum)
+}
+
+func TestRunError(t *testing.T) {
+	err := New(1).Run(context.Background(), 2, func(ch chan []int) error {
+		defer close(ch)
+		ch <- []int{1}
+		return errors.New("failed to read")
+	}, func([]int, *modelutil.Rand) {})
+	assert.EqualError(t, err, "failed to read")
+}
+
+func TestRunCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var n int
+	assert.NoError(t, New(1).Run(ctx, 1, batches(100), func([]int, *modelutil.Rand) {
+		n++
+		cancel()
+	}))
+	assert.Equal(t, 1, n)
+}
+
+func TestRandSource(t *testing.T) {
+	src := rand.NewSource(1)
+	want := make([]uint64, 100)
+	for i := range want {
+		want[i] = modelutil.NewRandFrom(src).Uint64()
+	}
+
+	q := New(2)
+	q.RandSource(rand.NewSource(1))
+	var mu sync.Mutex
+	got := make([]uint64, 100)
+	assert.NoError(t, q.Run(context.Background(), 3, batches(100), func(batch []int, rng *modelutil.Rand) {
+		mu.Lock()
+		defer mu.Unlock()
+		got[batch[0]] = rng.Uint64()
+	}))
+	// the generators follow the batches regardless of the workers which take them.
+	assert.Equal(t, want, got)
+}
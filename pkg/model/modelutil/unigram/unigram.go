@@ -0,0 +1,59 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package unigram
+
+import (
+	"math"
+	"sort"
+
+	"github.com/ynqa/wego/pkg/corpus/dictionary"
+	"github.com/ynqa/wego/pkg/model/modelutil"
+)
+
+// Sampler draws word ids from the unigram distribution raised to the power.
+// power=0 means to draw them uniformly.
+type Sampler struct {
+	size int
+	cum  []float64
+}
+
+func New(dic *dictionary.Dictionary, power float64) *Sampler {
+	s := &Sampler{
+		size: dic.Len(),
+	}
+	if power == 0 {
+		return s
+	}
+	s.cum = make([]float64, dic.Len())
+	var total float64
+	for i := 0; i < dic.Len(); i++ {
+		total += math.Pow(float64(dic.IDFreq(i)), power)
+		s.cum[i] = total
+	}
+	for i := range s.cum {
+		s.cum[i] /= total
+	}
+	return s
+}
+
+func (s *Sampler) Sample(rng *modelutil.Rand) int {
+	if s.cum == nil {
+		return rng.Intn(s.size)
+	}
+	r := rng.Float64()
+	return sort.Search(len(s.cum)-1, func(i int) bool {
+		return s.cum[i] > r
+	})
+}
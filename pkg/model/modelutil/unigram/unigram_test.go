@@ -0,0 +1,58 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package unigram
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/corpus/dictionary"
+	"github.com/ynqa/wego/pkg/model/modelutil"
+)
+
+func TestSample(t *testing.T) {
+	dic := dictionary.New()
+	dic.Add("a", "a", "a", "a", "a", "a", "a", "a", "a", "b", "c")
+
+	testCases := []struct {
+		name  string
+		power float64
+	}{
+		{
+			name:  "uniform",
+			power: 0,
+		},
+		{
+			name:  "smoothed unigram",
+			power: 0.75,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, rng := New(dic, tc.power), modelutil.NewRand(1)
+			cnt := make([]int, dic.Len())
+			for i := 0; i < 10000; i++ {
+				id := s.Sample(rng)
+				assert.True(t, 0 <= id && id < dic.Len())
+				cnt[id]++
+			}
+			if tc.power > 0 {
+				assert.True(t, cnt[0] > cnt[1] && cnt[0] > cnt[2])
+			}
+		})
+	}
+}
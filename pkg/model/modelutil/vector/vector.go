@@ -16,9 +16,11 @@ package vector
 
 import (
 	"bufio"
-	"bytes"
 	"fmt"
 	"io"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/ynqa/wego/pkg/corpus/dictionary"
@@ -38,31 +40,248 @@ const (
 	Agg    Type = "agg"
 )
 
-func Save(f io.Writer, dic *dictionary.Dictionary, mat *matrix.Matrix, verbose *verbose.Verbose, logBatch int) error {
+type Order = string
+
+const (
+	// ID is the order of the vocabulary ids, i.e. the first appearance in the corpus.
+	ID Order = "id"
+	// Freq is the descending order of the frequency, and the ties are broken by the words.
+	Freq Order = "freq"
+)
+
+func InvalidOrderError(order Order) error {
+	return errors.Errorf("invalid order: %s not in %s|%s", order, ID, Freq)
+}
+
+type Notation = string
+
+const (
+	// Fixed is %f, where Precision is the number of digits after the decimal point.
+	Fixed Notation = "f"
+	// General is %g, where Precision is the number of significant digits, and the large or small exponents use e.
+	General Notation = "g"
+)
+
+func InvalidNotationError(notation Notation) error {
+	return errors.Errorf("invalid notation: %s not in %s|%s", notation, Fixed, General)
+}
+
+// Format is how to save the word vectors, in Order with the values in Notation of Precision.
+// The same vectors are always saved as the same bytes, so the outputs of the runs can be diffed or hashed.
+// Words and Top save only the subset of the vocabulary, which are in Words and in the Top most frequent words if set.
+// PhraseDelim is the delimiter of the words merged into the phrases in the corpus, e.g. new_york,
+// and PhraseJoin writes the phrases with the other delimiter instead of it, e.g. new-york.
+// PhraseMean writes the mean of the vectors of the words in the phrases as well as the vectors of the phrases,
+// as the next line keyed by the phrase with MeanSuffix, e.g. new_york#mean.
+type Format struct {
+	Order       Order
+	Notation    Notation
+	Precision   int
+	Words       []string
+	Top         int
+	PhraseDelim string
+	PhraseJoin  string
+	PhraseMean  bool
+}
+
+// MeanSuffix is appended to the phrase for the mean of the vectors of its words, see Format.
+const MeanSuffix = "#mean"
+
+// ValidPhraseJoin reports whether the phrases joined by join are read back as the words,
+// i.e. join contains no spaces, which separate the word from the values.
+func ValidPhraseJoin(join string) bool {
+	return !strings.ContainsAny(join, " \t\r\n")
+}
+
+// Save writes the lines of the word and its vector, where -0 is written as 0.
+func Save(f io.Writer, dic *dictionary.Dictionary, mat *matrix.Matrix, format Format, verbose *verbose.Verbose, logBatch int) error {
 	if dic.Len() != mat.Row() {
 		return fmt.Errorf("different for length of dic and row of matrix: %d, %d", dic.Len(), mat.Row())
 	}
+	ids, err := order(dic, format.Order)
+	if err != nil {
+		return err
+	}
+	ids, err = filter(dic, ids, format)
+	if err != nil {
+		return err
+	}
+	if !ValidPhraseJoin(format.PhraseJoin) {
+		return errors.Errorf("phrase join must not contain spaces, got %q", format.PhraseJoin)
+	}
+	var fmtByte byte
+	switch format.Notation {
+	case Fixed, "":
+		fmtByte = 'f'
+	case General:
+		fmtByte = 'g'
+	default:
+		return InvalidNotationError(format.Notation)
+	}
 	writer := bufio.NewWriter(f)
-	defer writer.Flush()
 
-	var buf bytes.Buffer
+	var (
+		buf   []byte
+		saved int
+	)
 	clk := clock.New()
-	for i := 0; i < dic.Len(); i++ {
-		word, _ := dic.Word(i)
-		fmt.Fprintf(&buf, "%v ", word)
-		for j := 0; j < mat.Col(); j++ {
-			fmt.Fprintf(&buf, "%f ", mat.Slice(i)[j])
+	for n, i := range ids {
+		word, ok := dic.Word(i)
+		if !ok {
+			// empty bucket of the hashed dictionary
+			continue
+		}
+		saved++
+		vec := mat.Slice(i)
+		words := phrase(word, format.PhraseDelim)
+		if len(words) > 1 && format.PhraseJoin != "" {
+			word = strings.Join(words, format.PhraseJoin)
+		}
+		buf = appendLine(buf[:0], word, vec, fmtByte, format.Precision)
+		if len(words) > 1 && format.PhraseMean {
+			buf = appendLine(buf, word+MeanSuffix, mean(dic, mat, words, vec), fmtByte, format.Precision)
+		}
+		if _, err := writer.Write(buf); err != nil {
+			return err
 		}
-		fmt.Fprintln(&buf)
 		verbose.Do(func() {
-			if i%logBatch == 0 {
-				fmt.Printf("saved %d words %v\r", i, clk.AllElapsed())
+			if n%logBatch == 0 {
+				verbose.Printf("saved %d words %v\r", n, clk.AllElapsed())
 			}
 		})
 	}
-	writer.WriteString(fmt.Sprintf("%v", buf.String()))
+	if err := writer.Flush(); err != nil {
+		return err
+	}
 	verbose.Do(func() {
-		fmt.Printf("saved %d words %v\r\n", dic.Len(), clk.AllElapsed())
+		verbose.Printf("saved %d words %v\r\n", saved, clk.AllElapsed())
 	})
 	return nil
 }
+
+// appendLine appends the word and its values separated by spaces as the line.
+func appendLine(buf []byte, word string, vec []float64, fmtByte byte, prec int) []byte {
+	buf = append(buf, word...)
+	buf = append(buf, ' ')
+	for _, v := range vec {
+		buf = appendFloat(buf, v, fmtByte, prec)
+		buf = append(buf, ' ')
+	}
+	return append(buf, '\n')
+}
+
+func order(dic *dictionary.Dictionary, order Order) ([]int, error) {
+	ids := make([]int, dic.Len())
+	for i := range ids {
+		ids[i] = i
+	}
+	switch order {
+	case ID:
+	case Freq:
+		words := make([]string, len(ids))
+		for i := range ids {
+			words[i], _ = dic.Word(i)
+		}
+		sort.SliceStable(ids, func(i, j int) bool {
+			a, b := ids[i], ids[j]
+			if fa, fb := dic.IDFreq(a), dic.IDFreq(b); fa != fb {
+				return fa > fb
+			}
+			return words[a] < words[b]
+		})
+	default:
+		return nil, InvalidOrderError(order)
+	}
+	return ids, nil
+}
+
+func filter(dic *dictionary.Dictionary, ids []int, format Format) ([]int, error) {
+	if format.Top < 0 {
+		return nil, errors.Errorf("top must be >= 0, got %d", format.Top)
+	} else if len(format.Words) == 0 && format.Top == 0 {
+		return ids, nil
+	}
+	keep := make([]bool, dic.Len())
+	for i := range keep {
+		keep[i] = len(format.Words) == 0
+	}
+	for _, word := range format.Words {
+		if id, ok := dic.ID(word); ok {
+			keep[id] = true
+		}
+	}
+	if format.Top > 0 {
+		freq, _ := order(dic, Freq)
+		rank := 0
+		for _, id := range freq {
+			if _, ok := dic.Word(id); !ok {
+				continue
+			}
+			if rank >= format.Top {
+				keep[id] = false
+			}
+			rank++
+		}
+	}
+	filtered := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if keep[id] {
+			filtered = append(filtered, id)
+		}
+	}
+	return filtered, nil
+}
+
+// phrase splits the phrase into the words, or returns nil if the word is not a phrase.
+func phrase(word, delim string) []string {
+	if delim == "" || !strings.Contains(word, delim) {
+		return nil
+	}
+	var words []string
+	for _, w := range strings.Split(word, delim) {
+		if w != "" {
+			words = append(words, w)
+		}
+	}
+	return words
+}
+
+// mean returns the mean of the vectors of the words in the vocabulary, or def if none of them are.
+func mean(dic *dictionary.Dictionary, mat *matrix.Matrix, words []string, def []float64) []float64 {
+	var (
+		sum   = make([]float64, mat.Col())
+		found int
+	)
+	for _, w := range words {
+		id, ok := dic.ID(w)
+		if !ok {
+			continue
+		}
+		for j, v := range mat.Slice(id) {
+			sum[j] += v
+		}
+		found++
+	}
+	if found == 0 {
+		return def
+	}
+	for j := range sum {
+		sum[j] /= float64(found)
+	}
+	return sum
+}
+
+func appendFloat(buf []byte, v float64, fmtByte byte, precision int) []byte {
+	s := len(buf)
+	buf = strconv.AppendFloat(buf, v, fmtByte, precision, 64)
+	if buf[s] != '-' {
+		return buf
+	}
+	for _, c := range buf[s+1:] {
+		if c != '0' && c != '.' {
+			return buf
+		}
+	}
+	// -0 by rounding.
+	return append(buf[:s], buf[s+1:]...)
+}
@@ -0,0 +1,114 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vector
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/corpus/dictionary"
+	"github.com/ynqa/wego/pkg/model/modelutil/matrix"
+	"github.com/ynqa/wego/pkg/util/verbose"
+)
+
+func TestSave(t *testing.T) {
+	dic := dictionary.New()
+	dic.Add("c", "b", "a", "b", "a")
+	mat := matrix.New(dic.Len(), 2, func(id int, vec []float64) {
+		vec[0], vec[1] = float64(id)+0.123456789, -0.0001
+	})
+
+	testCases := []struct {
+		name   string
+		format Format
+		expect string
+	}{
+		{
+			name:   "id",
+			format: Format{Order: ID, Precision: 6},
+			expect: "c 0.123457 -0.000100 \nb 1.123457 -0.000100 \na 2.123457 -0.000100 \n",
+		},
+		{
+			name:   "freq with ties broken by word",
+			format: Format{Order: Freq, Precision: 2},
+			expect: "a 2.12 0.00 \nb 1.12 0.00 \nc 0.12 0.00 \n",
+		},
+		{
+			name:   "top",
+			format: Format{Order: ID, Precision: 1, Top: 2},
+			expect: "b 1.1 0.0 \na 2.1 0.0 \n",
+		},
+		{
+			name:   "words in top",
+			format: Format{Order: ID, Precision: 1, Words: []string{"c", "a", "unknown"}, Top: 2},
+			expect: "a 2.1 0.0 \n",
+		},
+		{
+			name:   "general",
+			format: Format{Order: ID, Notation: General, Precision: 3},
+			expect: "c 0.123 -0.0001 \nb 1.12 -0.0001 \na 2.12 -0.0001 \n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			assert.NoError(t, Save(&buf, dic, mat, tc.format, verbose.New(false), 1))
+			assert.Equal(t, tc.expect, buf.String())
+		})
+	}
+
+	assert.Error(t, Save(&bytes.Buffer{}, dic, mat, Format{Order: "alpha"}, verbose.New(false), 1))
+	assert.Error(t, Save(&bytes.Buffer{}, dic, mat, Format{Order: ID, Notation: "e"}, verbose.New(false), 1))
+}
+
+func TestSavePhrase(t *testing.T) {
+	dic := dictionary.New()
+	dic.Add("new", "york", "new_york", "_")
+	mat := matrix.New(dic.Len(), 1, func(id int, vec []float64) {
+		vec[0] = float64(id + 1)
+	})
+
+	testCases := []struct {
+		name   string
+		format Format
+		expect string
+	}{
+		{
+			name:   "delim",
+			format: Format{Order: ID, PhraseDelim: "_"},
+			expect: "new 1 \nyork 2 \nnew_york 3 \n_ 4 \n",
+		},
+		{
+			name:   "join and mean",
+			format: Format{Order: ID, PhraseDelim: "_", PhraseJoin: "-", PhraseMean: true},
+			expect: "new 1 \nyork 2 \nnew-york 3 \nnew-york#mean 1.5 \n_ 4 \n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tc.format.Notation = General
+			tc.format.Precision = 3
+			assert.NoError(t, Save(&buf, dic, mat, tc.format, verbose.New(false), 1))
+			assert.Equal(t, tc.expect, buf.String())
+		})
+	}
+
+	assert.Error(t, Save(&bytes.Buffer{}, dic, mat, Format{Order: ID, PhraseDelim: "_", PhraseJoin: " "}, verbose.New(false), 1))
+}
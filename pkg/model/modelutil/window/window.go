@@ -0,0 +1,62 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package window
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/model/modelutil"
+)
+
+// Type is the weighting for the contexts by distance in the window, shared by the models
+// to replicate the results of the others, e.g. Harmonic for GloVe.
+type Type = string
+
+const (
+	// Dynamic shrinks the window at random as word2vec, which weights the contexts linearly by distance.
+	Dynamic Type = "dynamic"
+	// Uniform weights all the contexts in the window equally.
+	Uniform Type = "uniform"
+	// Harmonic weights the contexts by 1/distance as GloVe.
+	Harmonic Type = "harmonic"
+)
+
+func InvalidTypeError(typ Type) error {
+	return errors.Errorf("invalid window type: %s not in %s|%s|%s", typ, Dynamic, Uniform, Harmonic)
+}
+
+func Valid(typ Type) bool {
+	return typ == Dynamic || typ == Uniform || typ == Harmonic
+}
+
+// Shrink returns the number of the positions to skip at both edges of the window,
+// which is random for Dynamic and 0 for the others.
+func Shrink(typ Type, window int, rng *modelutil.Rand) int {
+	if typ == Dynamic {
+		return rng.Intn(window)
+	}
+	return 0
+}
+
+// Weight returns the weight of the context at dist in the window shrunk by Shrink.
+func Weight(typ Type, dist int) float64 {
+	if typ == Harmonic {
+		if dist < 0 {
+			dist = -dist
+		}
+		return 1. / float64(dist)
+	}
+	return 1
+}
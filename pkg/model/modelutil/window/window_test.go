@@ -0,0 +1,65 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package window
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/model/modelutil"
+)
+
+func TestValid(t *testing.T) {
+	for _, typ := range []Type{Dynamic, Uniform, Harmonic} {
+		assert.True(t, Valid(typ))
+	}
+	assert.False(t, Valid("linear"))
+	assert.Error(t, InvalidTypeError("linear"))
+}
+
+func TestShrink(t *testing.T) {
+	rng := modelutil.NewRand(1)
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, 0, Shrink(Uniform, 5, rng))
+		assert.Equal(t, 0, Shrink(Harmonic, 5, rng))
+		del := Shrink(Dynamic, 5, rng)
+		assert.True(t, 0 <= del && del < 5)
+	}
+}
+
+func TestWeight(t *testing.T) {
+	assert.Equal(t, 1., Weight(Dynamic, 3))
+	assert.Equal(t, 1., Weight(Uniform, -3))
+	assert.Equal(t, 0.5, Weight(Harmonic, 2))
+	assert.Equal(t, 0.5, Weight(Harmonic, -2))
+}
+
+// TestDynamic checks that Shrink of Dynamic weights the contexts linearly by distance on average,
+// i.e. (window-dist+1)/window as co.Linear.
+func TestDynamic(t *testing.T) {
+	window, n := 4, 10000
+	rng := modelutil.NewRand(1)
+	in := make([]int, window+1)
+	for i := 0; i < n; i++ {
+		del := Shrink(Dynamic, window, rng)
+		for dist := 1; dist <= window-del; dist++ {
+			in[dist]++
+		}
+	}
+	for dist := 1; dist <= window; dist++ {
+		assert.InDelta(t, float64(window-dist+1)/float64(window), float64(in[dist])/float64(n), 0.02)
+	}
+}
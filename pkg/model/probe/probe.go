@@ -0,0 +1,126 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package probe
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/model"
+	"github.com/ynqa/wego/pkg/search"
+	"github.com/ynqa/wego/pkg/util/fileutil"
+)
+
+const defaultRank = 5
+
+// Probe is a query of the nearest neighbors of Word1, or the similarity between Word1 and Word2 if Word2 is set.
+type Probe struct {
+	Word1, Word2 string
+}
+
+func (p Probe) String() string {
+	if p.Word2 == "" {
+		return p.Word1
+	}
+	return p.Word1 + " " + p.Word2
+}
+
+// Load reads the lines of `word` or `word1 word2`. Empty lines and lines starting with # are skipped.
+func Load(r io.Reader) ([]Probe, error) {
+	var probes []Probe
+	s := fileutil.NewScanner(r, bufio.ScanLines)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		switch len(fields) {
+		case 1:
+			probes = append(probes, Probe{Word1: fields[0]})
+		case 2:
+			probes = append(probes, Probe{Word1: fields[0], Word2: fields[1]})
+		default:
+			return nil, errors.Errorf("line %d must be `word` or `word1 word2`, got %q", n, line)
+		}
+	}
+	if err := s.Err(); err != nil && err != io.EOF {
+		return nil, errors.Wrapf(err, "failed to scan")
+	}
+	return probes, nil
+}
+
+// Prober is the model.Hook to evaluate the probes by Model at the end of each iteration,
+// and writes the results into the writer.
+type Prober struct {
+	model.BaseHook
+	// Model must be set before training.
+	Model model.Model
+	Rank  int
+
+	w      io.Writer
+	probes []Probe
+}
+
+func NewProber(w io.Writer, probes []Probe) *Prober {
+	return &Prober{
+		Rank:   defaultRank,
+		w:      w,
+		probes: probes,
+	}
+}
+
+func (p *Prober) AfterIter(progress model.Progress) {
+	if p.Model == nil {
+		return
+	}
+	embs, err := model.Snapshot(p.Model)
+	if err != nil {
+		fmt.Fprintf(p.w, "iter %d probe: %v\n", progress.Iter, err)
+		return
+	}
+	s, err := search.New(embs...)
+	if err != nil {
+		fmt.Fprintf(p.w, "iter %d probe: %v\n", progress.Iter, err)
+		return
+	}
+	for _, probe := range p.probes {
+		fmt.Fprintf(p.w, "iter %d probe %s: %s\n", progress.Iter, probe, Evaluate(s, probe, p.Rank))
+	}
+}
+
+// Evaluate returns the similarity of the pair, or the neighbors of the word as `word:similarity`.
+func Evaluate(s *search.Searcher, probe Probe, k int) string {
+	if probe.Word2 != "" {
+		sim, err := s.Similarity(probe.Word1, probe.Word2)
+		if err != nil {
+			return err.Error()
+		}
+		return fmt.Sprintf("%f", sim)
+	}
+	neighbors, err := s.SearchInternal(probe.Word1, k)
+	if err != nil {
+		return err.Error()
+	}
+	res := make([]string, len(neighbors))
+	for i, n := range neighbors {
+		res[i] = fmt.Sprintf("%s:%f", n.Word, n.Similarity)
+	}
+	return strings.Join(res, " ")
+}
@@ -0,0 +1,65 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package probe
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/model/word2vec"
+	"github.com/ynqa/wego/pkg/search"
+)
+
+func TestLoad(t *testing.T) {
+	probes, err := Load(strings.NewReader("# comment\na\n\nb c\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []Probe{{Word1: "a"}, {Word1: "b", Word2: "c"}}, probes)
+
+	_, err = Load(strings.NewReader("a b c\n"))
+	assert.Error(t, err)
+}
+
+func TestEvaluate(t *testing.T) {
+	s, err := search.New(
+		embedding.Embedding{Word: "a", Dim: 2, Vector: []float64{1, 0}, Norm: 1},
+		embedding.Embedding{Word: "b", Dim: 2, Vector: []float64{1, 1}, Norm: 1.4142135623730951},
+		embedding.Embedding{Word: "c", Dim: 2, Vector: []float64{0, 1}, Norm: 1},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.000000", Evaluate(s, Probe{Word1: "a", Word2: "c"}, 1))
+	assert.Equal(t, "b:0.707107", Evaluate(s, Probe{Word1: "a"}, 1))
+	assert.Contains(t, Evaluate(s, Probe{Word1: "z"}, 1), "z")
+}
+
+func TestProber(t *testing.T) {
+	buf := &bytes.Buffer{}
+	p := NewProber(buf, []Probe{{Word1: "a"}, {Word1: "a", Word2: "b"}})
+	mod, err := word2vec.New(word2vec.Dim(3), word2vec.MinCount(1), word2vec.Iter(2), word2vec.Hooks(p))
+	assert.NoError(t, err)
+	p.Model = mod
+	corpus := strings.Repeat("a b c d e f g h i j ", 5)
+	assert.NoError(t, mod.Train(context.Background(), strings.NewReader(corpus)))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 4)
+	assert.True(t, strings.HasPrefix(lines[0], "iter 1 probe a: "))
+	assert.Len(t, strings.Fields(strings.TrimPrefix(lines[0], "iter 1 probe a: ")), defaultRank)
+	assert.True(t, strings.HasPrefix(lines[3], "iter 2 probe a b: "))
+}
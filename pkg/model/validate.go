@@ -0,0 +1,83 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidOption is the error of an option, whose Field is the name of the flag, e.g. "dim".
+type ErrInvalidOption struct {
+	Field   string
+	Message string
+}
+
+func (e ErrInvalidOption) Error() string {
+	return e.Message
+}
+
+// InvalidOption returns ErrInvalidOption of field with the message.
+func InvalidOption(field, format string, args ...interface{}) error {
+	return ErrInvalidOption{
+		Field:   field,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// OptionsError aggregates all invalid options so that they are fixed at once before training.
+// errors.As finds the first of them as ErrInvalidOption as well.
+type OptionsError []ErrInvalidOption
+
+func (e OptionsError) Error() string {
+	msgs := make([]string, len(e))
+	for i, opt := range e {
+		msgs[i] = opt.Message
+	}
+	return fmt.Sprintf("invalid options:\n  - %s", strings.Join(msgs, "\n  - "))
+}
+
+func (e OptionsError) As(target interface{}) bool {
+	if t, ok := target.(*ErrInvalidOption); ok && len(e) > 0 {
+		*t = e[0]
+		return true
+	}
+	return false
+}
+
+// Has returns whether the option of field is invalid.
+func (e OptionsError) Has(field string) bool {
+	for _, opt := range e {
+		if opt.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Require adds the message for field if cond is false.
+func (e *OptionsError) Require(cond bool, field, format string, args ...interface{}) {
+	if !cond {
+		*e = append(*e, InvalidOption(field, format, args...).(ErrInvalidOption))
+	}
+}
+
+// Err returns nil if no options are invalid.
+func (e OptionsError) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
@@ -16,9 +16,47 @@ package word2vec
 
 import (
 	"github.com/ynqa/wego/pkg/model/modelutil"
+	"github.com/ynqa/wego/pkg/model/modelutil/lrscale"
 	"github.com/ynqa/wego/pkg/model/modelutil/matrix"
+	"github.com/ynqa/wego/pkg/model/modelutil/window"
 )
 
+// worker is the scratch space owned by a goroutine not to allocate and share buffers per example.
+type worker struct {
+	rng      *modelutil.Rand
+	agg, tmp []float64
+	// picks and inners are the samples of negative sampling and their inner products.
+	picks  []int
+	inners []float64
+	// ids, lrs, ctxs and grads are the contexts of a window to share the samples.
+	ids   []int
+	lrs   []float64
+	ctxs  [][]float64
+	grads [][]float64
+}
+
+func newWorker(dim int, rng *modelutil.Rand) *worker {
+	return &worker{
+		rng: rng,
+		agg: make([]float64, dim),
+		tmp: make([]float64, dim),
+	}
+}
+
+// zeroGrads returns n gradients filled by zero, which are grown on demand.
+func (wk *worker) zeroGrads(n int) [][]float64 {
+	for len(wk.grads) < n {
+		wk.grads = append(wk.grads, make([]float64, len(wk.tmp)))
+	}
+	grads := wk.grads[:n]
+	for _, g := range grads {
+		for i := range g {
+			g[i] = 0
+		}
+	}
+	return grads
+}
+
 type mod interface {
 	trainOne(
 		doc []int,
@@ -26,22 +64,23 @@ type mod interface {
 		lr float64,
 		param *matrix.Matrix,
 		optimizer optimizer,
-	)
+		wk *worker,
+	) float64
 }
 
 type skipGram struct {
-	ch     chan []float64
-	window int
+	window     int
+	windowType window.Type
+	scale      *lrscale.Scale
+	shared     bool
 }
 
-func newSkipGram(opts Options) mod {
-	ch := make(chan []float64, opts.Goroutines)
-	for i := 0; i < opts.Goroutines; i++ {
-		ch <- make([]float64, opts.Dim)
-	}
+func newSkipGram(opts Options, scale *lrscale.Scale) mod {
 	return &skipGram{
-		ch:     ch,
-		window: opts.Window,
+		window:     opts.Window,
+		windowType: opts.WindowType,
+		scale:      scale,
+		shared:     opts.SharedNegatives,
 	}
 }
 
@@ -51,12 +90,14 @@ func (mod *skipGram) trainOne(
 	lr float64,
 	param *matrix.Matrix,
 	optimizer optimizer,
-) {
-	tmp := <-mod.ch
-	defer func() {
-		mod.ch <- tmp
-	}()
-	del := modelutil.NextRandom(mod.window)
+	wk *worker,
+) float64 {
+	if so, ok := optimizer.(sharedOptimizer); ok && mod.shared {
+		return mod.trainShared(doc, pos, lr, param, so, wk)
+	}
+	var loss float64
+	tmp := wk.tmp
+	del := window.Shrink(mod.windowType, mod.window, wk.rng)
 	for a := del; a < mod.window*2+1-del; a++ {
 		if a == mod.window {
 			continue
@@ -70,26 +111,65 @@ func (mod *skipGram) trainOne(
 		}
 		ctxID := doc[c]
 		ctx := param.Slice(ctxID)
-		optimizer.optim(doc[pos], lr, ctx, tmp)
+		loss += optimizer.optim(doc[pos], lr*window.Weight(mod.windowType, a-mod.window), ctx, wk)
+		s := mod.scale.Of(ctxID)
 		for i := 0; i < len(ctx); i++ {
-			ctx[i] += tmp[i]
+			ctx[i] += s * tmp[i]
 		}
 	}
+	return loss
+}
+
+// trainShared collects the contexts in the window of pos to update them with the same samples at once.
+func (mod *skipGram) trainShared(
+	doc []int,
+	pos int,
+	lr float64,
+	param *matrix.Matrix,
+	optimizer sharedOptimizer,
+	wk *worker,
+) float64 {
+	ids, lrs, ctxs := wk.ids[:0], wk.lrs[:0], wk.ctxs[:0]
+	del := window.Shrink(mod.windowType, mod.window, wk.rng)
+	for a := del; a < mod.window*2+1-del; a++ {
+		if a == mod.window {
+			continue
+		}
+		c := pos - mod.window + a
+		if c < 0 || c >= len(doc) {
+			continue
+		}
+		ids = append(ids, doc[c])
+		lrs = append(lrs, lr*window.Weight(mod.windowType, a-mod.window))
+		ctxs = append(ctxs, param.Slice(doc[c]))
+	}
+	wk.ids, wk.lrs, wk.ctxs = ids, lrs, ctxs
+	if len(ctxs) == 0 {
+		return 0
+	}
+
+	grads := wk.zeroGrads(len(ctxs))
+	loss := optimizer.optimShared(doc[pos], lrs, ctxs, grads, wk)
+	for i, ctx := range ctxs {
+		s := mod.scale.Of(ids[i])
+		for j := 0; j < len(ctx); j++ {
+			ctx[j] += s * grads[i][j]
+		}
+	}
+	return loss
 }
 
 type cbow struct {
-	ch     chan []float64
-	window int
+	window     int
+	windowType window.Type
+	scale      *lrscale.Scale
 }
 
-func newCbow(opts Options) mod {
-	ch := make(chan []float64, opts.Goroutines*2)
-	for i := 0; i < opts.Goroutines; i++ {
-		ch <- make([]float64, opts.Dim)
-	}
+func newCbow(opts Options, scale *lrscale.Scale) mod {
 	return &cbow{
-		ch:     ch,
-		window: opts.Window,
+		window:     opts.Window,
+		windowType: opts.WindowType,
+		scale:      scale,
 	}
 }
 
@@ -99,28 +179,26 @@ func (mod *cbow) trainOne(
 	lr float64,
 	param *matrix.Matrix,
 	optimizer optimizer,
-) {
-	agg, tmp := <-mod.ch, <-mod.ch
-	defer func() {
-		mod.ch <- agg
-		mod.ch <- tmp
-	}()
+	wk *worker,
+) float64 {
+	agg, tmp := wk.agg, wk.tmp
 	for i := 0; i < len(agg); i++ {
 		agg[i], tmp[i] = 0, 0
 	}
-	mod.dowith(doc, pos, param, agg, tmp, mod.aggregate)
-	optimizer.optim(doc[pos], lr, agg, tmp)
-	mod.dowith(doc, pos, param, agg, tmp, mod.update)
+	del := window.Shrink(mod.windowType, mod.window, wk.rng)
+	mod.dowith(doc, pos, del, param, agg, tmp, mod.aggregate)
+	loss := optimizer.optim(doc[pos], lr, agg, wk)
+	mod.dowith(doc, pos, del, param, agg, tmp, mod.update)
+	return loss
 }
 
 func (mod *cbow) dowith(
 	doc []int,
-	pos int,
+	pos, del int,
 	param *matrix.Matrix,
 	agg, tmp []float64,
-	fn func(ctx, agg, tmp []float64),
+	fn func(id int, weight float64, ctx, agg, tmp []float64),
 ) {
-	del := modelutil.NextRandom(mod.window)
 	for a := del; a < mod.window*2+1-del; a++ {
 		if a == mod.window {
 			continue
@@ -131,18 +209,19 @@ func (mod *cbow) dowith(
 		}
 		ctxID := doc[c]
 		ctx := param.Slice(ctxID)
-		fn(ctx, agg, tmp)
+		fn(ctxID, window.Weight(mod.windowType, a-mod.window), ctx, agg, tmp)
 	}
 }
 
-func (c *cbow) aggregate(ctx, agg, _ []float64) {
+func (c *cbow) aggregate(_ int, weight float64, ctx, agg, _ []float64) {
 	for i := 0; i < len(ctx); i++ {
-		agg[i] += ctx[i]
+		agg[i] += weight * ctx[i]
 	}
 }
 
-func (c *cbow) update(ctx, _, tmp []float64) {
+func (c *cbow) update(id int, weight float64, ctx, _, tmp []float64) {
+	s := weight * c.scale.Of(id)
 	for i := 0; i < len(ctx); i++ {
-		ctx[i] += tmp[i]
+		ctx[i] += s * tmp[i]
 	}
 }
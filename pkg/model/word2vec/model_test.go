@@ -0,0 +1,159 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package word2vec
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/corpus/dictionary"
+	"github.com/ynqa/wego/pkg/model/modelutil"
+	"github.com/ynqa/wego/pkg/model/modelutil/kernel"
+	"github.com/ynqa/wego/pkg/model/modelutil/matrix"
+)
+
+func newTestTrainOne(opts Options) (func(pos int), []int) {
+	dic := dictionary.New()
+	doc := make([]int, 1000)
+	for i := range doc {
+		w := fmt.Sprintf("w%d", i%100)
+		dic.Add(w)
+		doc[i], _ = dic.ID(w)
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	param := matrix.New(dic.Len(), opts.Dim, func(_ int, vec []float64) {
+		for i := range vec {
+			vec[i] = (rnd.Float64() - 0.5) / float64(opts.Dim)
+		}
+	})
+	k, _ := kernel.Get(kernel.Go)
+	optimizer, err := newNegativeSampling(dic, k, rnd, nil, nil, opts)
+	if err != nil {
+		panic(err)
+	}
+
+	var m mod
+	if opts.ModelType == Cbow {
+		m = newCbow(opts, nil)
+	} else {
+		m = newSkipGram(opts, nil)
+	}
+	wk := newWorker(opts.Dim, modelutil.NewRand(1))
+	return func(pos int) {
+		m.trainOne(doc, pos, opts.Initlr, param, optimizer, wk)
+	}, doc
+}
+
+func testTrainOneOptions() []Options {
+	cbow, skipGram, shared := DefaultOptions(), DefaultOptions(), DefaultOptions()
+	cbow.ModelType = Cbow
+	skipGram.ModelType = SkipGram
+	shared.ModelType = SkipGram
+	shared.SharedNegatives = true
+	return []Options{cbow, skipGram, shared}
+}
+
+func testTrainOneName(opts Options) string {
+	if opts.SharedNegatives {
+		return opts.ModelType + "-shared"
+	}
+	return opts.ModelType
+}
+
+func TestTrainOneAllocs(t *testing.T) {
+	for _, opts := range testTrainOneOptions() {
+		opts := opts
+		t.Run(testTrainOneName(opts), func(t *testing.T) {
+			trainOne, doc := newTestTrainOne(opts)
+			var pos int
+			allocs := testing.AllocsPerRun(100, func() {
+				trainOne(pos % len(doc))
+				pos++
+			})
+			assert.Equal(t, 0., allocs)
+		})
+	}
+}
+
+func BenchmarkTrainOne(b *testing.B) {
+	for _, opts := range testTrainOneOptions() {
+		opts := opts
+		b.Run(testTrainOneName(opts), func(b *testing.B) {
+			trainOne, doc := newTestTrainOne(opts)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				trainOne(i % len(doc))
+			}
+		})
+	}
+}
+
+func TestContextBuckets(t *testing.T) {
+	dic := dictionary.New()
+	for i := 0; i < 100; i++ {
+		dic.Add(fmt.Sprintf("w%d", i))
+	}
+	assert.Nil(t, contextBuckets(dic, 0))
+	buckets := contextBuckets(dic, 10)
+	assert.Len(t, buckets, 100)
+	for _, b := range buckets {
+		assert.True(t, 0 <= b && b < 10)
+	}
+
+	opts := DefaultOptions()
+	opts.ContextBuckets = 10
+	k, _ := kernel.Get(kernel.Go)
+	opt, err := newNegativeSampling(dic, k, rand.New(rand.NewSource(1)), nil, nil, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, opt.(*negativeSampling).ctx.Row())
+	// the negative samples in the bucket of the positive one are skipped.
+	opts.ContextBuckets = 1
+	opt, err = newNegativeSampling(dic, k, rand.New(rand.NewSource(1)), nil, nil, opts)
+	assert.NoError(t, err)
+	ns, wk := opt.(*negativeSampling), newWorker(opts.Dim, modelutil.NewRand(1))
+	ns.optim(0, opts.Initlr, make([]float64, opts.Dim), wk)
+	assert.Equal(t, []int{0}, wk.picks)
+	ns.optimShared(0, []float64{opts.Initlr}, [][]float64{make([]float64, opts.Dim)}, [][]float64{make([]float64, opts.Dim)}, wk)
+	assert.Equal(t, []int{0}, wk.picks)
+	opts.ContextBuckets = 10
+	hs := newHierarchicalSoftmax(dic, opts).(*hierarchicalSoftmax)
+	assert.Len(t, hs.nodeset, 10)
+
+	for _, typ := range []OptimizerType{NegativeSampling, HierarchicalSoftmax} {
+		t.Run(typ, func(t *testing.T) {
+			mod, err := New(ContextBuckets(4), Dim(3), MinCount(1), NegativeSampleSize(2), Optimizer(typ))
+			assert.NoError(t, err)
+			corpus := strings.Repeat("a b c d e f g h i j ", 5)
+			assert.NoError(t, mod.Train(context.Background(), strings.NewReader(corpus)))
+		})
+	}
+}
+
+func TestSharedNegatives(t *testing.T) {
+	mod, err := New(Dim(3), Model(SkipGram), Iter(2), MinCount(1), SharedNegatives(true))
+	assert.NoError(t, err)
+	corpus := strings.Repeat("a b c d e f g h i j ", 5)
+	assert.NoError(t, mod.Train(context.Background(), strings.NewReader(corpus)))
+
+	_, err = New(Model(Cbow), SharedNegatives(true))
+	assert.Error(t, err)
+}
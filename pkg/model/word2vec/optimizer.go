@@ -15,91 +15,220 @@
 package word2vec
 
 import (
+	"hash/fnv"
+	"math"
 	"math/rand"
 
 	"github.com/ynqa/wego/pkg/corpus/dictionary"
 	"github.com/ynqa/wego/pkg/corpus/dictionary/node"
-	"github.com/ynqa/wego/pkg/model/modelutil"
+	"github.com/ynqa/wego/pkg/model/modelutil/kernel"
+	"github.com/ynqa/wego/pkg/model/modelutil/lrscale"
 	"github.com/ynqa/wego/pkg/model/modelutil/matrix"
 )
 
+// optimizer updates the parameters for the context of id, accumulates the gradient of ctx into wk.tmp,
+// and returns the loss.
 type optimizer interface {
-	optim(id int, lr float64, ctx, tmp []float64)
+	optim(id int, lr float64, ctx []float64, wk *worker) float64
+}
+
+// sharedOptimizer updates the parameters for the contexts of id in a window with the same samples,
+// accumulates the gradients of ctxs into grads, and returns the loss.
+type sharedOptimizer interface {
+	optimShared(id int, lrs []float64, ctxs, grads [][]float64, wk *worker) float64
+}
+
+// nll is the negative log-likelihood of the logistic function, i.e. -log(sigmoid(x)).
+func nll(x float64) float64 {
+	if x < 0 {
+		return -x + math.Log1p(math.Exp(x))
+	}
+	return math.Log1p(math.Exp(-x))
+}
+
+// contextBuckets maps the ids of the contexts into the buckets hashed by the words,
+// which the contexts share the vectors of, or returns nil if buckets is 0.
+func contextBuckets(dic *dictionary.Dictionary, buckets int) []int {
+	if buckets == 0 {
+		return nil
+	}
+	ids := make([]int, dic.Len())
+	for id := range ids {
+		word, ok := dic.Word(id)
+		if !ok {
+			// empty bucket of the hashed dictionary
+			ids[id] = id % buckets
+			continue
+		}
+		h := fnv.New32a()
+		h.Write([]byte(word))
+		ids[id] = int(h.Sum32() % uint32(buckets))
+	}
+	return ids
 }
 
 type negativeSampling struct {
 	ctx        *matrix.Matrix
-	sigtable   *sigmoidTable
+	buckets    []int
+	vocab      int
+	kernel     kernel.Kernel
+	sigmoid    *sigmoid
 	sampleSize int
+	scale      *lrscale.Scale
 }
 
-func newNegativeSampling(dic *dictionary.Dictionary, opts Options) optimizer {
+func newNegativeSampling(dic *dictionary.Dictionary, k kernel.Kernel, rnd *rand.Rand, scale *lrscale.Scale, backing *matrix.Backing, opts Options) (optimizer, error) {
+	rows := dic.Len()
+	if opts.ContextBuckets > 0 {
+		rows = opts.ContextBuckets
+	}
+	ctx, err := backing.New("context", rows, opts.Dim, func(_ int, vec []float64) {
+		for i := 0; i < opts.Dim; i++ {
+			vec[i] = (rnd.Float64() - 0.5) / float64(opts.Dim)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
 	return &negativeSampling{
-		ctx: matrix.New(
-			dic.Len(),
-			opts.Dim,
-			func(_ int, vec []float64) {
-				for i := 0; i < opts.Dim; i++ {
-					vec[i] = (rand.Float64() - 0.5) / float64(opts.Dim)
-				}
-			},
-		),
-		sigtable:   newSigmoidTable(),
+		ctx:        ctx,
+		buckets:    contextBuckets(dic, opts.ContextBuckets),
+		vocab:      dic.Len(),
+		kernel:     k,
+		sigmoid:    newSigmoid(opts.Sigmoid),
 		sampleSize: opts.NegativeSampleSize,
+		scale:      scale,
+	}, nil
+}
+
+// row returns the row of the context vector for id.
+func (opt *negativeSampling) row(id int) int {
+	if opt.buckets == nil {
+		return id
 	}
+	return opt.buckets[id]
 }
 
+// optim computes the inner products of all samples first, and then their sigmoids at once.
+// The products are independent of the updates in between unless the same context is sampled twice.
 func (opt *negativeSampling) optim(
 	id int,
 	lr float64,
-	ctx, tmp []float64,
-) {
-	var (
-		label  int
-		picked int
-	)
-	dim := len(ctx)
+	ctx []float64,
+	wk *worker,
+) float64 {
+	picks, inners := wk.picks[:0], wk.inners[:0]
 	for n := -1; n < opt.sampleSize; n++ {
-		if n == -1 {
-			label = 1
-			picked = id
-		} else {
-			label = 0
-			picked = modelutil.NextRandom(opt.ctx.Row())
-			if id == picked {
+		picked := id
+		if n >= 0 {
+			picked = wk.rng.Intn(opt.vocab)
+			// the negative sample sharing the bucket of id would update the positive row.
+			if opt.row(id) == opt.row(picked) {
 				continue
 			}
 		}
-		rnd := opt.ctx.Slice(picked)
-		var inner float64
-		for i := 0; i < dim; i++ {
-			inner += rnd[i] * ctx[i]
+		picks = append(picks, picked)
+		inners = append(inners, opt.kernel.Dot(opt.ctx.Slice(opt.row(picked)), ctx))
+	}
+	wk.picks, wk.inners = picks, inners
+
+	// the first sample is the positive one of id.
+	loss := nll(inners[0])
+	for _, inner := range inners[1:] {
+		loss += nll(-inner)
+	}
+	opt.sigmoid.batch(inners)
+	for i, picked := range picks {
+		var label float64
+		if i == 0 {
+			label = 1
+		}
+		g := (label - inners[i]) * lr
+		rnd := opt.ctx.Slice(opt.row(picked))
+		opt.kernel.Axpy(g, rnd, wk.tmp)
+		opt.kernel.Axpy(g*opt.scale.Of(picked), ctx, rnd)
+	}
+	return loss
+}
+
+// optimShared draws the negative samples once for all ctxs, which turns the updates into the products
+// of the small matrices, i.e. the inner products of ctxs x samples, and the gradients back to both of them,
+// while the rows of the samples stay in cache.
+func (opt *negativeSampling) optimShared(
+	id int,
+	lrs []float64,
+	ctxs, grads [][]float64,
+	wk *worker,
+) float64 {
+	picks := append(wk.picks[:0], id)
+	for n := 0; n < opt.sampleSize; n++ {
+		if picked := wk.rng.Intn(opt.vocab); opt.row(picked) != opt.row(id) {
+			picks = append(picks, picked)
 		}
-		var g float64
-		if inner <= -opt.sigtable.maxExp {
-			g = (float64(label - 0)) * lr
-		} else if inner >= opt.sigtable.maxExp {
-			g = (float64(label - 1)) * lr
+	}
+	m := len(picks)
+	inners := wk.inners[:0]
+	for _, ctx := range ctxs {
+		for _, picked := range picks {
+			inners = append(inners, opt.kernel.Dot(opt.ctx.Slice(opt.row(picked)), ctx))
+		}
+	}
+	wk.picks, wk.inners = picks, inners
+
+	var loss float64
+	for k, inner := range inners {
+		if k%m == 0 {
+			loss += nll(inner)
 		} else {
-			g = (float64(label) - opt.sigtable.sigmoid(inner)) * lr
+			loss += nll(-inner)
+		}
+	}
+	opt.sigmoid.batch(inners)
+	// the sigmoids turn into the gradients of the inner products in place.
+	for i := range ctxs {
+		gs := inners[i*m : (i+1)*m]
+		gs[0] = 1 - gs[0]
+		for j := 1; j < m; j++ {
+			gs[j] = -gs[j]
 		}
-		for i := 0; i < dim; i++ {
-			tmp[i] += g * rnd[i]
-			rnd[i] += g * ctx[i]
+		opt.kernel.Scal(lrs[i], gs)
+		for j, picked := range picks {
+			opt.kernel.Axpy(gs[j], opt.ctx.Slice(opt.row(picked)), grads[i])
+		}
+	}
+	for j, picked := range picks {
+		rnd := opt.ctx.Slice(opt.row(picked))
+		s := opt.scale.Of(picked)
+		for i, ctx := range ctxs {
+			opt.kernel.Axpy(inners[i*m+j]*s, ctx, rnd)
 		}
 	}
+	return loss
 }
 
 type hierarchicalSoftmax struct {
-	sigtable *sigmoidTable
+	sigmoid  *sigmoid
 	nodeset  []*node.Node
+	buckets  []int
 	maxDepth int
 }
 
+// newHierarchicalSoftmax builds the tree over the buckets of the contexts instead of the words if hashed,
+// so the number of the inner nodes is bounded by the buckets.
 func newHierarchicalSoftmax(dic *dictionary.Dictionary, opts Options) optimizer {
+	buckets := contextBuckets(dic, opts.ContextBuckets)
+	nodeset := dic.HuffnamTree(opts.Dim)
+	if buckets != nil {
+		freqs := make([]int, opts.ContextBuckets)
+		for id, b := range buckets {
+			freqs[b] += dic.IDFreq(id)
+		}
+		nodeset = dictionary.HuffmanTree(freqs, opts.Dim)
+	}
 	return &hierarchicalSoftmax{
-		sigtable: newSigmoidTable(),
-		nodeset:  dic.HuffnamTree(opts.Dim),
+		sigmoid:  newSigmoid(opts.Sigmoid),
+		nodeset:  nodeset,
+		buckets:  buckets,
 		maxDepth: opts.MaxDepth,
 	}
 }
@@ -107,8 +236,13 @@ func newHierarchicalSoftmax(dic *dictionary.Dictionary, opts Options) optimizer
 func (opt *hierarchicalSoftmax) optim(
 	id int,
 	lr float64,
-	ctx, tmp []float64,
-) {
+	ctx []float64,
+	wk *worker,
+) float64 {
+	var loss float64
+	if opt.buckets != nil {
+		id = opt.buckets[id]
+	}
 	path := opt.nodeset[id].GetPath(opt.maxDepth)
 	for i := 0; i < len(path)-1; i++ {
 		p := path[i]
@@ -117,13 +251,20 @@ func (opt *hierarchicalSoftmax) optim(
 		for j := 0; j < len(p.Vector); j++ {
 			inner += ctx[j] * p.Vector[j]
 		}
-		if inner <= -opt.sigtable.maxExp || inner >= opt.sigtable.maxExp {
-			return
+		// the label is 1 for the left child.
+		if childCode == 0 {
+			loss += nll(inner)
+		} else {
+			loss += nll(-inner)
+		}
+		if inner <= -maxExp || inner >= maxExp {
+			return loss
 		}
-		g := (1.0 - float64(childCode) - opt.sigtable.sigmoid(inner)) * lr
+		g := (1.0 - float64(childCode) - opt.sigmoid.one(inner)) * lr
 		for j := 0; j < len(p.Vector); j++ {
-			tmp[j] += g * p.Vector[j]
+			wk.tmp[j] += g * p.Vector[j]
 			p.Vector[j] += g * ctx[j]
 		}
 	}
+	return loss
 }
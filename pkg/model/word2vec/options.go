@@ -15,10 +15,16 @@
 package word2vec
 
 import (
-	"fmt"
+	"io"
+	"math/rand"
 	"runtime"
+	"time"
 
-	"github.com/spf13/cobra"
+	"github.com/ynqa/wego/pkg/model"
+	"github.com/ynqa/wego/pkg/model/modelutil/kernel"
+	"github.com/ynqa/wego/pkg/model/modelutil/matrix"
+	"github.com/ynqa/wego/pkg/model/modelutil/vector"
+	"github.com/ynqa/wego/pkg/model/modelutil/window"
 )
 
 type ModelType = string
@@ -28,6 +34,30 @@ const (
 	SkipGram ModelType = "skipgram"
 )
 
+type InputFormat = string
+
+const (
+	Text   InputFormat = "text"
+	CoNLLU InputFormat = "conllu"
+	// Labeled is the text whose lines contain the entities with the label prefix,
+	// they are embedded into the same space as words.
+	Labeled InputFormat = "labeled"
+	// Wikipedia is the MediaWiki XML dump, which may be compressed by bzip2 or gzip.
+	Wikipedia InputFormat = "wikipedia"
+	// WET is the WARC of the plain text used by Common Crawl, which may be gzip compressed.
+	WET InputFormat = "wet"
+)
+
+type ContextType = string
+
+const (
+	// WindowContext is the words around the target word.
+	WindowContext ContextType = "window"
+	// DepContext is the words connected to the target word in the dependency parse,
+	// labeled by the relation (Levy and Goldberg, 2014). It requires CoNLL-U input.
+	DepContext ContextType = "dep"
+)
+
 type OptimizerType = string
 
 const (
@@ -36,106 +66,277 @@ const (
 )
 
 var (
+	defaultBackend            = kernel.Go
+	defaultAutotune           = false
 	defaultBatchSize          = 10000
+	defaultContextBuckets     = 0
+	defaultContextType        = WindowContext
 	defaultDim                = 10
 	defaultDocInMemory        = false
 	defaultGoroutines         = runtime.NumCPU()
+	defaultHashBuckets        = 0
 	defaultInitlr             = 0.025
+	defaultInputFormat        = Text
 	defaultIter               = 15
+	defaultLabelPrefix        = "__label__"
 	defaultLogBatch           = 100000
+	defaultLRFreqPower        = 0.0
+	defaultMatrixBacking      = matrix.Memory
 	defaultMaxCount           = -1
 	defaultMaxDepth           = 100
+	defaultMaxDuration        = time.Duration(0)
+	defaultMaxTokens          = 0
+	defaultMaxVocab           = 0
+	defaultMergeCase          = false
 	defaultMinCount           = 5
 	defaultMinLR              = defaultInitlr * 1.0e-4
 	defaultModelType          = Cbow
 	defaultNegativeSampleSize = 5
 	defaultOptimizerType      = NegativeSampling
+	defaultNotation           = vector.Fixed
+	defaultOrder              = vector.ID
+	defaultPhraseDelim        = ""
+	defaultPhraseJoin         = ""
+	defaultPhraseMean         = false
+	defaultPrecision          = 6
+	defaultPrefetch           = 8
+	defaultSaveTop            = 0
+	defaultSeed               = int64(1)
+	defaultSharedNegatives    = false
+	defaultSigmoid            = SigmoidTable
 	defaultSubsampleThreshold = 1.0e-3
 	defaultToLower            = false
 	defaultUpdateLRBatch      = 100000
 	defaultVerbose            = false
 	defaultWindow             = 5
+	defaultWindowType         = window.Dynamic
 )
 
 type Options struct {
+	Backend            kernel.Type
+	Autotune           bool
 	BatchSize          int
+	ContextBuckets     int
+	ContextType        ContextType
 	Dim                int
 	DocInMemory        bool
+	FreezeWords        []string
 	Goroutines         int
+	HashBuckets        int
+	Hooks              model.Hooks          `json:"-"`
+	InitVectors        map[string][]float64 `json:"-"`
 	Initlr             float64
+	InputFormat        InputFormat
 	Iter               int
+	LRFreqPower        float64
+	LRWeights          map[string]float64
+	LabelPrefix        string
 	LogBatch           int
+	MatrixBacking      string
 	MaxCount           int
 	MaxDepth           int
+	MaxDuration        time.Duration
+	MaxTokens          int
+	MaxVocab           int
+	MergeCase          bool
 	MinCount           int
 	MinLR              float64
 	ModelType          ModelType
 	NegativeSampleSize int
 	OptimizerType      OptimizerType
+	Notation           vector.Notation
+	Order              vector.Order
+	PhraseDelim        string
+	PhraseJoin         string
+	PhraseMean         bool
+	Precision          int
+	// Prefetch is the number of the batches read ahead for the goroutines in streaming.
+	Prefetch           int
+	Progress           io.Writer `json:"-"`
+	SaveTop            int
+	SaveWords          []string
+	Seed               int64
+	SharedNegatives    bool
+	Sigmoid            SigmoidType
+	Source             rand.Source `json:"-"`
 	SubsampleThreshold float64
 	ToLower            bool
 	UpdateLRBatch      int
 	Verbose            bool
 	Window             int
+	WindowType         window.Type
 }
 
 func DefaultOptions() Options {
 	return Options{
+		Backend:            defaultBackend,
+		Autotune:           defaultAutotune,
 		BatchSize:          defaultBatchSize,
+		ContextBuckets:     defaultContextBuckets,
+		ContextType:        defaultContextType,
 		Dim:                defaultDim,
 		DocInMemory:        defaultDocInMemory,
 		Goroutines:         defaultGoroutines,
+		HashBuckets:        defaultHashBuckets,
 		Initlr:             defaultInitlr,
+		InputFormat:        defaultInputFormat,
 		Iter:               defaultIter,
+		LRFreqPower:        defaultLRFreqPower,
+		LabelPrefix:        defaultLabelPrefix,
 		LogBatch:           defaultLogBatch,
+		MatrixBacking:      defaultMatrixBacking,
 		MaxCount:           defaultMaxCount,
 		MaxDepth:           defaultMaxDepth,
+		MaxDuration:        defaultMaxDuration,
+		MaxTokens:          defaultMaxTokens,
+		MaxVocab:           defaultMaxVocab,
+		MergeCase:          defaultMergeCase,
 		MinCount:           defaultMinCount,
 		MinLR:              defaultMinLR,
 		ModelType:          defaultModelType,
 		NegativeSampleSize: defaultNegativeSampleSize,
 		OptimizerType:      defaultOptimizerType,
+		Notation:           defaultNotation,
+		Order:              defaultOrder,
+		PhraseDelim:        defaultPhraseDelim,
+		PhraseJoin:         defaultPhraseJoin,
+		PhraseMean:         defaultPhraseMean,
+		Precision:          defaultPrecision,
+		Prefetch:           defaultPrefetch,
+		SaveTop:            defaultSaveTop,
+		Seed:               defaultSeed,
+		SharedNegatives:    defaultSharedNegatives,
+		Sigmoid:            defaultSigmoid,
 		SubsampleThreshold: defaultSubsampleThreshold,
 		ToLower:            defaultToLower,
 		UpdateLRBatch:      defaultUpdateLRBatch,
 		Verbose:            defaultVerbose,
 		Window:             defaultWindow,
+		WindowType:         defaultWindowType,
 	}
 }
 
-func LoadForCmd(cmd *cobra.Command, opts *Options) {
-	cmd.Flags().IntVar(&opts.BatchSize, "batch", defaultBatchSize, "batch size to train")
-	cmd.Flags().IntVarP(&opts.Dim, "dim", "d", defaultDim, "dimension for word vector")
-	cmd.Flags().IntVar(&opts.Goroutines, "goroutines", defaultGoroutines, "number of goroutine")
-	cmd.Flags().BoolVar(&opts.DocInMemory, "in-memory", defaultDocInMemory, "whether to store the doc in memory")
-	cmd.Flags().Float64Var(&opts.Initlr, "initlr", defaultInitlr, "initial learning rate")
-	cmd.Flags().IntVar(&opts.Iter, "iter", defaultIter, "number of iteration")
-	cmd.Flags().IntVar(&opts.LogBatch, "log-batch", defaultLogBatch, "batch size to log for counting words")
-	cmd.Flags().IntVar(&opts.MaxCount, "max-count", defaultMaxCount, "upper limit to filter words")
-	cmd.Flags().IntVar(&opts.MaxDepth, "max-depth", defaultMaxDepth, "times to track huffman tree, max-depth=0 means to track full path from root to word (for hierarchical softmax only)")
-	cmd.Flags().IntVar(&opts.MinCount, "min-count", defaultMinCount, "lower limit to filter words")
-	cmd.Flags().Float64Var(&opts.MinLR, "min-lr", defaultMinLR, "lower limit of learning rate")
-	cmd.Flags().StringVar(&opts.ModelType, "model", defaultModelType, fmt.Sprintf("which model does it use? one of: %s|%s", Cbow, SkipGram))
-	cmd.Flags().IntVar(&opts.NegativeSampleSize, "sample", defaultNegativeSampleSize, "negative sample size(for negative sampling only)")
-	cmd.Flags().StringVar(&opts.OptimizerType, "optimizer", defaultOptimizerType, fmt.Sprintf("which optimizer does it use? one of: %s|%s", HierarchicalSoftmax, NegativeSampling))
-	cmd.Flags().Float64Var(&opts.SubsampleThreshold, "threshold", defaultSubsampleThreshold, "threshold for subsampling")
-	cmd.Flags().BoolVar(&opts.ToLower, "to-lower", defaultToLower, "whether the words on corpus convert to lowercase or not")
-	cmd.Flags().IntVar(&opts.UpdateLRBatch, "update-lr-batch", defaultUpdateLRBatch, "batch size to update learning rate")
-	cmd.Flags().BoolVar(&opts.Verbose, "verbose", defaultVerbose, "verbose mode")
-	cmd.Flags().IntVarP(&opts.Window, "window", "w", defaultWindow, "context window size")
+// Validate reports all impossible options and combinations at once.
+func (opts Options) Validate() error {
+	var e model.OptionsError
+	e.Require(opts.Dim > 0, "dim", "dim must be > 0, got %d", opts.Dim)
+	for word, vec := range opts.InitVectors {
+		if len(vec) != opts.Dim {
+			e.Require(false, "init-vectors", "init vector of %q has dim %d, expected dim %d", word, len(vec), opts.Dim)
+			break
+		}
+	}
+	e.Require(opts.Window >= 1, "window", "window must be >= 1, got %d", opts.Window)
+	e.Require(window.Valid(opts.WindowType), "window-type", "window-type must be one of %s|%s|%s, got %q", window.Dynamic, window.Uniform, window.Harmonic, opts.WindowType)
+	e.Require(opts.Iter >= 1, "iter", "iter must be >= 1, got %d", opts.Iter)
+	e.Require(opts.Goroutines >= 1, "goroutines", "goroutines must be >= 1, got %d", opts.Goroutines)
+	e.Require(opts.BatchSize >= opts.Goroutines, "batch", "batch %d must be >= goroutines %d, otherwise some goroutines have no words", opts.BatchSize, opts.Goroutines)
+	e.Require(opts.LogBatch > 0, "log-batch", "log-batch must be > 0, got %d", opts.LogBatch)
+	e.Require(opts.UpdateLRBatch > 0, "update-lr-batch", "update-lr-batch must be > 0, got %d", opts.UpdateLRBatch)
+	e.Require(opts.Initlr > 0, "initlr", "initlr must be > 0, got %v", opts.Initlr)
+	e.Require(opts.LRFreqPower >= 0, "lr-freq-power", "lr-freq-power must be >= 0, got %v", opts.LRFreqPower)
+	e.Require(0 <= opts.MinLR && opts.MinLR <= opts.Initlr, "min-lr", "min-lr must be in [0, initlr=%v], got %v", opts.Initlr, opts.MinLR)
+	e.Require(0 <= opts.SubsampleThreshold && opts.SubsampleThreshold < 1, "threshold", "threshold must be in [0, 1), got %v", opts.SubsampleThreshold)
+	e.Require(opts.HashBuckets >= 0, "hash-buckets", "hash-buckets must be >= 0, got %d", opts.HashBuckets)
+	e.Require(opts.ContextBuckets >= 0, "context-buckets", "context-buckets must be >= 0, got %d", opts.ContextBuckets)
+	e.Require(opts.MaxVocab >= 0, "max-vocab", "max-vocab must be >= 0, got %d", opts.MaxVocab)
+	e.Require(opts.MaxVocab == 0 || opts.HashBuckets == 0, "max-vocab", "max-vocab and hash-buckets are exclusive, hash-buckets bounds the vocabulary already")
+	e.Require(!(opts.ToLower && opts.MergeCase), "to-lower", "to-lower and merge-case are exclusive, merge-case keeps the surface forms")
+	e.Require(opts.MaxCount < 0 || opts.MinCount <= opts.MaxCount, "max-count", "max-count %d must be >= min-count %d, or < 0 to disable it", opts.MaxCount, opts.MinCount)
+	e.Require(opts.MaxDuration >= 0, "max-duration", "max-duration must be >= 0, got %v", opts.MaxDuration)
+	e.Require(opts.MaxTokens >= 0, "max-tokens", "max-tokens must be >= 0, got %d", opts.MaxTokens)
+	e.Require(opts.Order == vector.ID || opts.Order == vector.Freq, "order", "order must be one of %s|%s, got %q", vector.ID, vector.Freq, opts.Order)
+	e.Require(opts.Notation == vector.Fixed || opts.Notation == vector.General, "float-format", "float-format must be one of %s|%s, got %q", vector.Fixed, vector.General, opts.Notation)
+	e.Require(vector.ValidPhraseJoin(opts.PhraseJoin), "phrase-join", "phrase-join must not contain spaces, got %q", opts.PhraseJoin)
+	e.Require(0 <= opts.Precision && opts.Precision <= 17, "precision", "precision must be in [0, 17], got %d", opts.Precision)
+	e.Require(opts.Prefetch >= 0, "prefetch", "prefetch must be >= 0, got %d", opts.Prefetch)
+	e.Require(opts.SaveTop >= 0, "save-top", "save-top must be >= 0, got %d", opts.SaveTop)
+	e.Require(!opts.SharedNegatives || (opts.ModelType == SkipGram && opts.OptimizerType == NegativeSampling && opts.ContextType == WindowContext),
+		"shared-negatives",
+		"shared-negatives requires %s model, %s optimizer and %s context", SkipGram, NegativeSampling, WindowContext)
+	e.Require(opts.Sigmoid == SigmoidTable || opts.Sigmoid == SigmoidPoly || opts.Sigmoid == SigmoidExact, "sigmoid", "sigmoid must be one of %s|%s|%s, got %q", SigmoidTable, SigmoidPoly, SigmoidExact, opts.Sigmoid)
+	e.Require(opts.ModelType == Cbow || opts.ModelType == SkipGram, "model", "model must be one of %s|%s, got %q", Cbow, SkipGram, opts.ModelType)
+	switch opts.OptimizerType {
+	case NegativeSampling:
+		e.Require(opts.NegativeSampleSize > 0, "sample", "sample must be > 0 for %s optimizer, got %d", NegativeSampling, opts.NegativeSampleSize)
+	case HierarchicalSoftmax:
+		e.Require(opts.MaxDepth >= 0, "max-depth", "max-depth must be >= 0 for %s optimizer, got %d", HierarchicalSoftmax, opts.MaxDepth)
+	default:
+		e.Require(false, "optimizer", "optimizer must be one of %s|%s, got %q", NegativeSampling, HierarchicalSoftmax, opts.OptimizerType)
+	}
+	e.Require(opts.InputFormat == Text || opts.InputFormat == CoNLLU || opts.InputFormat == Labeled || opts.InputFormat == Wikipedia || opts.InputFormat == WET,
+		"input-format",
+		"input-format must be one of %s|%s|%s|%s|%s, got %q", Text, CoNLLU, Labeled, Wikipedia, WET, opts.InputFormat)
+	switch opts.ContextType {
+	case WindowContext:
+	case DepContext:
+		e.Require(opts.InputFormat == CoNLLU, "context", "%s context requires --input-format %s, got %q", DepContext, CoNLLU, opts.InputFormat)
+	default:
+		e.Require(false, "context", "context must be one of %s|%s, got %q", WindowContext, DepContext, opts.ContextType)
+	}
+	e.Require(opts.InputFormat != Labeled || opts.LabelPrefix != "", "label-prefix", "label-prefix must be set for %s input", Labeled)
+	e.Require(!opts.MergeCase || (opts.InputFormat != Labeled && opts.ContextType != DepContext), "merge-case", "merge-case is not supported for %s input and %s context", Labeled, DepContext)
+	e.Require(opts.MaxVocab == 0 || (opts.InputFormat != Labeled && opts.ContextType != DepContext), "max-vocab", "max-vocab is not supported for %s input and %s context", Labeled, DepContext)
+	if _, err := kernel.Get(opts.Backend); err != nil {
+		e.Require(false, "backend", "%v", err)
+	}
+	if _, err := matrix.ParseBacking(opts.MatrixBacking); err != nil {
+		e.Require(false, "matrix-backing", "%v", err)
+	}
+	return e.Err()
 }
 
 type ModelOption func(*Options)
 
+// WithOptions replaces all options by opts, and the following ModelOptions override them.
+// New(WithOptions(opts)) is the same as NewForOptions(opts).
+func WithOptions(v Options) ModelOption {
+	return ModelOption(func(opts *Options) {
+		*opts = v
+	})
+}
+
+func Backend(typ kernel.Type) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Backend = typ
+	})
+}
+
+func Autotune(v bool) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Autotune = v
+	})
+}
+
 func BatchSize(v int) ModelOption {
 	return ModelOption(func(opts *Options) {
 		opts.BatchSize = v
 	})
 }
 
-func DocInMemory() ModelOption {
+// ContextBuckets hashes the contexts into v buckets for the optimizers.
+func ContextBuckets(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.ContextBuckets = v
+	})
+}
+
+func Context(typ ContextType) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.ContextType = typ
+	})
+}
+
+func DocInMemory(v bool) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.DocInMemory = v
+	})
+}
+
+// FreezeWords keeps the vectors of words unchanged during training.
+func FreezeWords(words ...string) ModelOption {
 	return ModelOption(func(opts *Options) {
-		opts.DocInMemory = true
+		opts.FreezeWords = append(opts.FreezeWords, words...)
 	})
 }
 
@@ -145,6 +346,26 @@ func Goroutines(v int) ModelOption {
 	})
 }
 
+func HashBuckets(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.HashBuckets = v
+	})
+}
+
+// InitVectors warm-starts the vectors of the words in v instead of the random ones,
+// e.g. by the vectors of the previous training.
+func InitVectors(v map[string][]float64) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.InitVectors = v
+	})
+}
+
+func Hooks(hs ...model.Hook) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Hooks = append(opts.Hooks, hs...)
+	})
+}
+
 func Dim(v int) ModelOption {
 	return ModelOption(func(opts *Options) {
 		opts.Dim = v
@@ -157,18 +378,49 @@ func Initlr(v float64) ModelOption {
 	})
 }
 
+func Input(typ InputFormat) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.InputFormat = typ
+	})
+}
+
 func Iter(v int) ModelOption {
 	return ModelOption(func(opts *Options) {
 		opts.Iter = v
 	})
 }
 
+func LRFreqPower(v float64) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.LRFreqPower = v
+	})
+}
+
+// LRWeights scales the learning rate of each word by its weight.
+func LRWeights(v map[string]float64) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.LRWeights = v
+	})
+}
+
+func LabelPrefix(v string) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.LabelPrefix = v
+	})
+}
+
 func LogBatch(v int) ModelOption {
 	return ModelOption(func(opts *Options) {
 		opts.LogBatch = v
 	})
 }
 
+func MatrixBacking(v string) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.MatrixBacking = v
+	})
+}
+
 func MaxCount(v int) ModelOption {
 	return ModelOption(func(opts *Options) {
 		opts.MaxCount = v
@@ -181,6 +433,30 @@ func MaxDepth(v int) ModelOption {
 	})
 }
 
+func MaxDuration(v time.Duration) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.MaxDuration = v
+	})
+}
+
+func MaxTokens(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.MaxTokens = v
+	})
+}
+
+func MaxVocab(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.MaxVocab = v
+	})
+}
+
+func MergeCase(v bool) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.MergeCase = v
+	})
+}
+
 func MinCount(v int) ModelOption {
 	return ModelOption(func(opts *Options) {
 		opts.MinCount = v
@@ -211,15 +487,105 @@ func Optimizer(typ OptimizerType) ModelOption {
 	})
 }
 
+func Notation(v vector.Notation) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Notation = v
+	})
+}
+
+func Order(v vector.Order) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Order = v
+	})
+}
+
+// PhraseDelim is the delimiter of the words merged into the phrases in the corpus, e.g. _ for new_york.
+func PhraseDelim(v string) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.PhraseDelim = v
+	})
+}
+
+// PhraseJoin is the delimiter to save the phrases with instead of PhraseDelim, e.g. - for new-york.
+func PhraseJoin(v string) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.PhraseJoin = v
+	})
+}
+
+func PhraseMean(v bool) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.PhraseMean = v
+	})
+}
+
+func Precision(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Precision = v
+	})
+}
+
+func Prefetch(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Prefetch = v
+	})
+}
+
+// Progress prints the progress in verbose into w, e.g. os.Stderr while the output is written to stdout.
+func Progress(w io.Writer) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Progress = w
+	})
+}
+
+// RandSource injects the source to initialize parameters and to seed the generators per goroutine.
+// It takes priority over Seed.
+func RandSource(src rand.Source) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Source = src
+	})
+}
+
+func SaveTop(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.SaveTop = v
+	})
+}
+
+// SaveWords saves only the vectors of words.
+func SaveWords(words ...string) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.SaveWords = append(opts.SaveWords, words...)
+	})
+}
+
+func Seed(v int64) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Seed = v
+	})
+}
+
+func SharedNegatives(v bool) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.SharedNegatives = v
+	})
+}
+
+func Sigmoid(typ SigmoidType) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Sigmoid = typ
+	})
+}
+
 func SubsampleThreshold(v float64) ModelOption {
 	return ModelOption(func(opts *Options) {
 		opts.SubsampleThreshold = v
 	})
 }
 
-func ToLower() ModelOption {
+func ToLower(v bool) ModelOption {
 	return ModelOption(func(opts *Options) {
-		opts.ToLower = true
+		opts.ToLower = v
 	})
 }
 
@@ -229,9 +595,9 @@ func UpdateLRBatch(v int) ModelOption {
 	})
 }
 
-func Verbose() ModelOption {
+func Verbose(v bool) ModelOption {
 	return ModelOption(func(opts *Options) {
-		opts.Verbose = true
+		opts.Verbose = v
 	})
 }
 
@@ -240,3 +606,9 @@ func Window(v int) ModelOption {
 		opts.Window = v
 	})
 }
+
+func WindowType(typ window.Type) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.WindowType = typ
+	})
+}
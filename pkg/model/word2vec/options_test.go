@@ -0,0 +1,153 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package word2vec
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/model"
+	"github.com/ynqa/wego/pkg/model/modelutil/matrix"
+	"github.com/ynqa/wego/pkg/model/modelutil/vector"
+)
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, DefaultOptions().Validate())
+
+	opts := DefaultOptions()
+	opts.Dim = 0
+	opts.Window = 0
+	opts.SubsampleThreshold = 1
+	opts.Goroutines = 4
+	opts.BatchSize = 2
+	err := opts.Validate()
+	assert.Len(t, err, 4)
+	assert.IsType(t, model.OptionsError{}, err)
+	assert.True(t, err.(model.OptionsError).Has("dim"))
+	var ierr model.ErrInvalidOption
+	assert.True(t, errors.As(err, &ierr))
+	assert.Equal(t, "dim", ierr.Field)
+
+	_, err = New(Dim(0))
+	assert.Error(t, err)
+}
+
+func TestModelOption(t *testing.T) {
+	base := DefaultOptions()
+	base.Dim = 3
+	base.Verbose = true
+	base.DocInMemory = true
+
+	mod, err := New(WithOptions(base), Verbose(false), Window(2))
+	assert.NoError(t, err)
+	opts := mod.Options().(Options)
+	assert.Equal(t, 3, opts.Dim)
+	assert.Equal(t, 2, opts.Window)
+	assert.False(t, opts.Verbose)
+	assert.True(t, opts.DocInMemory)
+
+	mod, err = NewForOptions(base)
+	assert.NoError(t, err)
+	assert.Equal(t, base, mod.Options())
+}
+
+type iterCounter struct {
+	model.BaseHook
+	trained int
+	err     error
+}
+
+func (c *iterCounter) AfterIter(p model.Progress) {
+	c.trained += p.Trained
+}
+
+func (c *iterCounter) AfterTrain(err error) {
+	c.err = err
+}
+
+func TestMaxTokens(t *testing.T) {
+	c := &iterCounter{}
+	mod, err := New(
+		Dim(3),
+		DocInMemory(true),
+		Goroutines(1),
+		Iter(100),
+		MaxTokens(50),
+		MinCount(1),
+		Hooks(c),
+	)
+	assert.NoError(t, err)
+	corpus := strings.Repeat("a b c d e f g h i j ", 2)
+	assert.NoError(t, mod.Train(context.Background(), strings.NewReader(corpus)))
+	assert.NoError(t, c.err)
+	assert.GreaterOrEqual(t, c.trained, 50)
+	assert.Less(t, c.trained, 100*20)
+}
+
+func TestMaxTokensCanceled(t *testing.T) {
+	mod, err := New(Dim(3), DocInMemory(true), MinCount(1), MaxTokens(50))
+	assert.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, context.Canceled, mod.Train(ctx, strings.NewReader("a b c d e f g h i j")))
+}
+
+func TestMatrixBacking(t *testing.T) {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		t.Skip("mmap is not supported")
+	}
+	dir, err := ioutil.TempDir("", "wego-word2vec")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	corpus := strings.Repeat("a b c d e f g h i j ", 5)
+	train := func(backing string) *matrix.Matrix {
+		mod, err := New(Dim(3), DocInMemory(true), Goroutines(1), MinCount(1), MatrixBacking(backing))
+		assert.NoError(t, err)
+		assert.NoError(t, mod.Train(context.Background(), strings.NewReader(corpus)))
+		return mod.WordVector(vector.Single)
+	}
+	mem, mapped := train(matrix.Memory), train("mmap:"+dir)
+	for i := 0; i < mem.Row(); i++ {
+		assert.Equal(t, mem.Slice(i), mapped.Slice(i))
+	}
+	for _, name := range []string{"word.mat", "context.mat"} {
+		_, err := matrix.Load(filepath.Join(dir, name))
+		assert.NoError(t, err)
+	}
+
+	_, err = New(MatrixBacking("disk"))
+	assert.Error(t, err)
+}
+
+func TestAutotune(t *testing.T) {
+	for _, inMemory := range []bool{true, false} {
+		c := &iterCounter{}
+		mod, err := New(Dim(3), DocInMemory(inMemory), Goroutines(4), BatchSize(4), Iter(2), MinCount(1), Autotune(true), Hooks(c))
+		assert.NoError(t, err)
+		corpus := strings.Repeat("a b c d e f g h i j ", 5)
+		assert.NoError(t, mod.Train(context.Background(), strings.NewReader(corpus)))
+		assert.NoError(t, c.err)
+		assert.Equal(t, 2*50, c.trained)
+	}
+}
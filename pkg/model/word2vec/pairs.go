@@ -0,0 +1,110 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package word2vec
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/sync/semaphore"
+
+	"github.com/ynqa/wego/pkg/corpus/cpsutil"
+	"github.com/ynqa/wego/pkg/corpus/pairs"
+	"github.com/ynqa/wego/pkg/model"
+	"github.com/ynqa/wego/pkg/model/modelutil"
+	"github.com/ynqa/wego/pkg/util/clock"
+)
+
+// trainPairs trains skip-gram over the (word, context) pairs, e.g. the dependency contexts,
+// where the contexts have their own vocabulary as the output layer.
+func (w *word2vec) trainPairs(ctx context.Context, p *pairs.Pairs) error {
+	w.pairs = p
+	w.filters = cpsutil.Filters{
+		cpsutil.MaxCount(w.opts.MaxCount),
+		cpsutil.MinCount(w.opts.MinCount),
+	}
+	if err := w.init(p.Dictionary(), p.ContextDictionary()); err != nil {
+		return err
+	}
+
+	parts := w.tuner.Parts(w.opts.Goroutines, p.Len(), w.opts.BatchSize)
+	indexPerThread := modelutil.IndexPerThread(
+		parts,
+		p.Len(),
+	)
+	for i := 1; i <= w.opts.Iter; i++ {
+		trained, observed, clk := make(chan float64), make(chan struct{}), clock.New()
+		go w.observe(i, trained, observed, clk)
+
+		sem := semaphore.NewWeighted(int64(w.opts.Goroutines))
+		wg := &sync.WaitGroup{}
+
+		for i := 0; i < parts; i++ {
+			wg.Add(1)
+			s, e := indexPerThread[i], indexPerThread[i+1]
+			go w.trainPairsPerThread(ctx, s, e, modelutil.NewRandFrom(w.rand), trained, sem, wg)
+		}
+
+		wg.Wait()
+		close(trained)
+		<-observed
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *word2vec) trainPairsPerThread(
+	ctx context.Context,
+	s, e int,
+	rng *modelutil.Rand,
+	trained chan float64,
+	sem *semaphore.Weighted,
+	wg *sync.WaitGroup,
+) error {
+	defer wg.Done()
+
+	if err := sem.Acquire(ctx, 1); err != nil {
+		return err
+	}
+	defer sem.Release(1)
+	defer w.tuner.Acquire(e - s)()
+
+	wk := newWorker(w.opts.Dim, rng)
+	dic, ctxDic := w.pairs.Dictionary(), w.pairs.ContextDictionary()
+	for i := s; i < e; i++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		id, cid := w.pairs.Pair(i)
+		loss := model.Skipped
+		if !w.filters.Any(id, dic) && !w.filters.Any(cid, ctxDic) && w.subsampler.Trial(id, rng) {
+			for j := range wk.tmp {
+				wk.tmp[j] = 0
+			}
+			vec := w.param.Slice(id)
+			loss = w.optimizer.optim(cid, w.currentlr, vec, wk)
+			s := w.scale.Of(id)
+			for j := range vec {
+				vec[j] += s * wk.tmp[j]
+			}
+		}
+		trained <- loss
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package word2vec
+
+import (
+	"math"
+)
+
+type SigmoidType = string
+
+const (
+	// SigmoidTable looks up the precomputed table as the original word2vec.
+	SigmoidTable SigmoidType = "table"
+	// SigmoidPoly computes exp by the polynomial, which has no lookup to break the prefetch of the CPU.
+	SigmoidPoly SigmoidType = "poly"
+	// SigmoidExact computes exp by math.Exp.
+	SigmoidExact SigmoidType = "exact"
+)
+
+// maxExp is the bound of the inner products to be regarded as saturated, i.e. the sigmoid is 0 or 1.
+const maxExp = 6.
+
+// sigmoid computes the logistic function by the strategy, which is 0 and 1 beyond ±maxExp for all strategies
+// as the table, so that they differ only in the precision.
+type sigmoid struct {
+	typ   SigmoidType
+	table *sigmoidTable
+}
+
+func newSigmoid(typ SigmoidType) *sigmoid {
+	return &sigmoid{
+		typ:   typ,
+		table: newSigmoidTable(),
+	}
+}
+
+// batch replaces xs with their sigmoids in place, where the loop for each strategy has no branch
+// on the strategy.
+func (s *sigmoid) batch(xs []float64) {
+	switch s.typ {
+	case SigmoidPoly:
+		for i, x := range xs {
+			xs[i] = 1. / (1. + expPoly(-clip(x)))
+		}
+	case SigmoidExact:
+		for i, x := range xs {
+			xs[i] = 1. / (1. + math.Exp(-clip(x)))
+		}
+	default:
+		for i, x := range xs {
+			switch {
+			case x <= -maxExp:
+				xs[i] = 0
+			case x >= maxExp:
+				xs[i] = 1
+			default:
+				xs[i] = s.table.sigmoid(x)
+			}
+		}
+	}
+}
+
+func (s *sigmoid) one(x float64) float64 {
+	xs := [1]float64{x}
+	s.batch(xs[:])
+	return xs[0]
+}
+
+// clip saturates x beyond ±maxExp by ±inf, whose sigmoids are 0 and 1 exactly.
+func clip(x float64) float64 {
+	if x <= -maxExp {
+		return math.Inf(-1)
+	} else if x >= maxExp {
+		return math.Inf(1)
+	}
+	return x
+}
+
+// expPoly approximates exp(x) by 2^n*p(f) for x*log2(e) = n+f, where p is the Taylor polynomial of 2^f
+// of degree 7 on [0, 1), whose relative error is < 2e-6. It saturates to 0 and +inf for |x| > 700.
+func expPoly(x float64) float64 {
+	if x < -700 {
+		return 0
+	} else if x > 700 {
+		return math.Inf(1)
+	}
+	t := x * math.Log2E
+	n := math.Floor(t)
+	f := (t - n) * math.Ln2
+	p := 1 + f*(1+f*(1./2+f*(1./6+f*(1./24+f*(1./120+f*(1./720+f*(1./5040)))))))
+	return math.Float64frombits(uint64(int64(n)+1023)<<52) * p
+}
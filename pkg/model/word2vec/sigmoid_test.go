@@ -0,0 +1,81 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package word2vec
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSigmoidBatch(t *testing.T) {
+	testCases := []struct {
+		typ   SigmoidType
+		delta float64
+	}{
+		{typ: SigmoidTable, delta: 1e-2},
+		{typ: SigmoidPoly, delta: 1e-5},
+		{typ: SigmoidExact, delta: 1e-15},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.typ, func(t *testing.T) {
+			s := newSigmoid(tc.typ)
+			xs := []float64{-maxExp - 1, -maxExp, -3, -0.5, 0, 0.5, 3, maxExp - 1e-9, maxExp, maxExp + 1}
+			got := append([]float64{}, xs...)
+			s.batch(got)
+			for i, x := range xs {
+				expected := 1 / (1 + math.Exp(-x))
+				switch {
+				case x <= -maxExp:
+					expected = 0
+				case x >= maxExp:
+					expected = 1
+				}
+				assert.InDelta(t, expected, got[i], tc.delta, "sigmoid(%v)", x)
+				assert.Equal(t, got[i], s.one(x))
+			}
+		})
+	}
+}
+
+func TestExpPoly(t *testing.T) {
+	for x := -50.; x <= 50; x += 0.37 {
+		assert.InEpsilon(t, math.Exp(x), expPoly(x), 2e-6, "exp(%v)", x)
+	}
+	assert.Equal(t, 0., expPoly(math.Inf(-1)))
+	assert.True(t, math.IsInf(expPoly(math.Inf(1)), 1))
+}
+
+func BenchmarkSigmoid(b *testing.B) {
+	rnd := rand.New(rand.NewSource(1))
+	xs := make([]float64, 6)
+	in := make([]float64, 1<<12)
+	for i := range in {
+		in[i] = rnd.NormFloat64() * 3
+	}
+	for _, typ := range []SigmoidType{SigmoidTable, SigmoidPoly, SigmoidExact} {
+		s := newSigmoid(typ)
+		b.Run(typ, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				off := (i * len(xs)) % (len(in) - len(xs))
+				copy(xs, in[off:])
+				s.batch(xs)
+			}
+		})
+	}
+}
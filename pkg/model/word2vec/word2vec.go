@@ -16,7 +16,6 @@ package word2vec
 
 import (
 	"context"
-	"fmt"
 	"io"
 	"math/rand"
 	"sync"
@@ -25,11 +24,22 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/ynqa/wego/pkg/corpus"
+	"github.com/ynqa/wego/pkg/corpus/conllu"
+	"github.com/ynqa/wego/pkg/corpus/cpsutil"
+	"github.com/ynqa/wego/pkg/corpus/dictionary"
 	"github.com/ynqa/wego/pkg/corpus/fs"
 	"github.com/ynqa/wego/pkg/corpus/memory"
+	"github.com/ynqa/wego/pkg/corpus/pairs"
+	"github.com/ynqa/wego/pkg/corpus/wet"
+	"github.com/ynqa/wego/pkg/corpus/wiki"
 	"github.com/ynqa/wego/pkg/model"
 	"github.com/ynqa/wego/pkg/model/modelutil"
+	"github.com/ynqa/wego/pkg/model/modelutil/autotune"
+	"github.com/ynqa/wego/pkg/model/modelutil/budget"
+	"github.com/ynqa/wego/pkg/model/modelutil/kernel"
+	"github.com/ynqa/wego/pkg/model/modelutil/lrscale"
 	"github.com/ynqa/wego/pkg/model/modelutil/matrix"
+	"github.com/ynqa/wego/pkg/model/modelutil/prefetch"
 	"github.com/ynqa/wego/pkg/model/modelutil/subsample"
 	"github.com/ynqa/wego/pkg/model/modelutil/vector"
 	"github.com/ynqa/wego/pkg/util/clock"
@@ -40,14 +50,25 @@ type word2vec struct {
 	opts Options
 
 	corpus corpus.Corpus
+	// pairs is the corpus for the dependency contexts or the labeled entities instead.
+	pairs   *pairs.Pairs
+	filters cpsutil.Filters
 
+	backing    *matrix.Backing
 	param      *matrix.Matrix
+	rand       *rand.Rand
 	subsampler *subsample.Subsampler
+	scale      *lrscale.Scale
 	currentlr  float64
 	mod        mod
 	optimizer  optimizer
 
+	// queue is the prefetch of the current iteration in streaming.
+	queue *prefetch.Queue
+	tuner *autotune.Tuner
+
 	verbose *verbose.Verbose
+	budget  *budget.Budget
 }
 
 func New(opts ...ModelOption) (model.Model, error) {
@@ -60,8 +81,10 @@ func New(opts ...ModelOption) (model.Model, error) {
 }
 
 func NewForOptions(opts Options) (model.Model, error) {
-	// TODO: validate Options
-	v := verbose.New(opts.Verbose)
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	v := verbose.NewWriter(opts.Verbose, opts.Progress)
 	return &word2vec{
 		opts: opts,
 
@@ -71,174 +94,358 @@ func NewForOptions(opts Options) (model.Model, error) {
 	}, nil
 }
 
-func (w *word2vec) Train(r io.ReadSeeker) error {
+func (w *word2vec) Options() interface{} {
+	return w.opts
+}
+
+func (w *word2vec) Train(ctx context.Context, r io.Reader) (err error) {
+	w.opts.Hooks.BeforeTrain(w.opts)
+	defer func() {
+		w.opts.Hooks.AfterTrain(err)
+	}()
+	ctx, w.budget = budget.New(ctx, w.opts.MaxDuration, w.opts.MaxTokens)
+	defer func() {
+		err = w.budget.Result(err)
+	}()
+
+	rs, cleanup, err := cpsutil.ReadSeeker(r)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	switch w.opts.InputFormat {
+	case Text:
+	case CoNLLU:
+		if w.opts.ContextType == DepContext {
+			p, err := conllu.LoadPairs(rs, w.opts.ToLower)
+			if err != nil {
+				return err
+			}
+			return w.trainPairs(ctx, p)
+		}
+		pr, pw := io.Pipe()
+		go func() {
+			pw.CloseWithError(conllu.WriteText(pw, rs))
+		}()
+		text, cleanupText, err := cpsutil.ReadSeeker(pr)
+		if err != nil {
+			return err
+		}
+		defer cleanupText()
+		rs = text
+	case Wikipedia, WET:
+		text, cleanupText, err := cpsutil.ReadSeeker(DecodeText(rs, w.opts.InputFormat))
+		if err != nil {
+			return err
+		}
+		defer cleanupText()
+		rs = text
+	case Labeled:
+		p, err := pairs.LoadLabeled(rs, w.opts.LabelPrefix, w.opts.Window, w.opts.ToLower)
+		if err != nil {
+			return err
+		}
+		return w.trainPairs(ctx, p)
+	default:
+		return model.InvalidOption("input-format", "invalid input format: %s not in %s|%s|%s|%s|%s", w.opts.InputFormat, Text, CoNLLU, Labeled, Wikipedia, WET)
+	}
+
 	if w.opts.DocInMemory {
-		w.corpus = memory.New(r, w.opts.ToLower, w.opts.MaxCount, w.opts.MinCount)
+		w.corpus = memory.New(rs, w.opts.ToLower, w.opts.MergeCase, w.opts.MaxCount, w.opts.MinCount, w.opts.MaxVocab, w.opts.HashBuckets)
 	} else {
-		w.corpus = fs.New(r, w.opts.ToLower, w.opts.MaxCount, w.opts.MinCount)
+		w.corpus = fs.New(rs, w.opts.ToLower, w.opts.MergeCase, w.opts.MaxCount, w.opts.MinCount, w.opts.MaxVocab, w.opts.HashBuckets)
 	}
 
 	if err := w.corpus.Load(nil, w.verbose, w.opts.LogBatch); err != nil {
 		return err
 	}
 
-	dic, dim := w.corpus.Dictionary(), w.opts.Dim
+	if err := w.init(w.corpus.Dictionary(), w.corpus.Dictionary()); err != nil {
+		return err
+	}
+
+	if w.opts.DocInMemory {
+		if err := w.train(ctx); err != nil {
+			return err
+		}
+	} else {
+		if err := w.batchTrain(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// init prepares the parameters for the words in dic and the optimizer for the contexts in ctxDic.
+func (w *word2vec) init(dic, ctxDic *dictionary.Dictionary) error {
+	dim := w.opts.Dim
 
-	w.param = matrix.New(
+	w.rand = modelutil.SourceRand(w.opts.Source, w.opts.Seed)
+	backing, err := matrix.ParseBacking(w.opts.MatrixBacking)
+	if err != nil {
+		return err
+	}
+	w.backing = backing
+	if w.opts.Autotune {
+		w.tuner = autotune.New(w.opts.Goroutines, autotune.DefaultInterval)
+	}
+	w.param, err = backing.New(
+		"word",
 		dic.Len(),
 		dim,
-		func(_ int, vec []float64) {
+		func(row int, vec []float64) {
 			for i := 0; i < dim; i++ {
-				vec[i] = (rand.Float64() - 0.5) / float64(dim)
+				vec[i] = (w.rand.Float64() - 0.5) / float64(dim)
 			}
+			modelutil.WarmStart(w.opts.InitVectors, dic, row, vec)
 		},
 	)
+	if err != nil {
+		return err
+	}
 
 	w.subsampler = subsample.New(dic, w.opts.SubsampleThreshold)
+	w.scale = lrscale.New(dic, w.opts.LRFreqPower, w.opts.LRWeights, w.opts.FreezeWords)
 
 	switch w.opts.ModelType {
 	case SkipGram:
-		w.mod = newSkipGram(w.opts)
+		w.mod = newSkipGram(w.opts, w.scale)
 	case Cbow:
-		w.mod = newCbow(w.opts)
+		w.mod = newCbow(w.opts, w.scale)
 	default:
-		return errors.Errorf("invalid model: %s not in %s|%s", w.opts.ModelType, Cbow, SkipGram)
+		return model.InvalidOption("model", "invalid model: %s not in %s|%s", w.opts.ModelType, Cbow, SkipGram)
 	}
 
+	k, err := kernel.Get(w.opts.Backend)
+	if err != nil {
+		return err
+	}
 	switch w.opts.OptimizerType {
 	case NegativeSampling:
-		w.optimizer = newNegativeSampling(
-			w.corpus.Dictionary(),
+		if w.opts.NegativeSampleSize >= ctxDic.Len() {
+			return errors.Errorf("sample %d must be < the vocabulary size %d, lower --sample or --min-count", w.opts.NegativeSampleSize, ctxDic.Len())
+		}
+		w.optimizer, err = newNegativeSampling(
+			ctxDic,
+			k,
+			w.rand,
+			lrscale.New(ctxDic, w.opts.LRFreqPower, w.opts.LRWeights, w.opts.FreezeWords),
+			backing,
 			w.opts,
 		)
+		if err != nil {
+			return err
+		}
 	case HierarchicalSoftmax:
 		w.optimizer = newHierarchicalSoftmax(
-			w.corpus.Dictionary(),
+			ctxDic,
 			w.opts,
 		)
 	default:
-		return errors.Errorf("invalid optimizer: %s not in %s|%s", w.opts.OptimizerType, NegativeSampling, HierarchicalSoftmax)
-	}
-
-	if w.opts.DocInMemory {
-		if err := w.train(); err != nil {
-			return err
-		}
-	} else {
-		if err := w.batchTrain(); err != nil {
-			return err
-		}
+		return model.InvalidOption("optimizer", "invalid optimizer: %s not in %s|%s", w.opts.OptimizerType, NegativeSampling, HierarchicalSoftmax)
 	}
 	return nil
 }
 
-func (w *word2vec) train() error {
+func (w *word2vec) train(ctx context.Context) error {
 	doc := w.corpus.IndexedDoc()
+	parts := w.tuner.Parts(w.opts.Goroutines, len(doc), w.opts.BatchSize)
 	indexPerThread := modelutil.IndexPerThread(
-		w.opts.Goroutines,
+		parts,
 		len(doc),
 	)
 
 	for i := 1; i <= w.opts.Iter; i++ {
-		trained, clk := make(chan struct{}), clock.New()
-		go w.observe(trained, clk)
+		trained, observed, clk := make(chan float64), make(chan struct{}), clock.New()
+		go w.observe(i, trained, observed, clk)
 
 		sem := semaphore.NewWeighted(int64(w.opts.Goroutines))
 		wg := &sync.WaitGroup{}
 
-		for i := 0; i < w.opts.Goroutines; i++ {
+		for i := 0; i < parts; i++ {
 			wg.Add(1)
 			s, e := indexPerThread[i], indexPerThread[i+1]
-			go w.trainPerThread(doc[s:e], trained, sem, wg)
+			go w.trainPerThread(ctx, doc[s:e], modelutil.NewRandFrom(w.rand), trained, sem, wg)
 		}
 
 		wg.Wait()
 		close(trained)
+		<-observed
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-func (w *word2vec) batchTrain() error {
+func (w *word2vec) batchTrain(ctx context.Context) error {
 	for i := 1; i <= w.opts.Iter; i++ {
-		trained, clk := make(chan struct{}), clock.New()
-		go w.observe(trained, clk)
-
-		sem := semaphore.NewWeighted(int64(w.opts.Goroutines))
-		wg := &sync.WaitGroup{}
-
-		in := make(chan []int, w.opts.Goroutines)
-		go w.corpus.BatchWords(in, w.opts.BatchSize)
-		for doc := range in {
-			wg.Add(1)
-			go w.trainPerThread(doc, trained, sem, wg)
-		}
+		w.queue = prefetch.New(w.opts.Prefetch)
+		w.queue.RandSource(w.rand)
+		trained, observed, clk := make(chan float64), make(chan struct{}), clock.New()
+		go w.observe(i, trained, observed, clk)
+
+		err := w.queue.Run(ctx, w.opts.Goroutines, func(in chan []int) error {
+			return w.corpus.BatchWords(in, w.opts.BatchSize)
+		}, func(doc []int, rng *modelutil.Rand) {
+			defer w.tuner.Acquire(len(doc))()
+			w.trainDoc(ctx, doc, rng, trained)
+		})
 
-		wg.Wait()
 		close(trained)
+		<-observed
+		if err != nil {
+			return err
+		} else if err := ctx.Err(); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
+// DecodeText returns the reader of the plain text of r in the document format, i.e. Wikipedia or WET,
+// which is decoded in background.
+func DecodeText(r io.Reader, format InputFormat) io.Reader {
+	pr, pw := io.Pipe()
+	go func() {
+		switch format {
+		case Wikipedia:
+			pw.CloseWithError(wiki.WriteText(pw, r))
+		case WET:
+			pw.CloseWithError(wet.WriteText(pw, r))
+		default:
+			pw.CloseWithError(errors.Errorf("invalid document format: %s not in %s|%s", format, Wikipedia, WET))
+		}
+	}()
+	return pr
+}
+
 func (w *word2vec) trainPerThread(
+	ctx context.Context,
 	doc []int,
-	trained chan struct{},
+	rng *modelutil.Rand,
+	trained chan float64,
 	sem *semaphore.Weighted,
 	wg *sync.WaitGroup,
 ) error {
-	defer func() {
-		wg.Done()
-		sem.Release(1)
-	}()
+	defer wg.Done()
 
-	if err := sem.Acquire(context.Background(), 1); err != nil {
+	if err := sem.Acquire(ctx, 1); err != nil {
 		return err
 	}
+	defer sem.Release(1)
+	defer w.tuner.Acquire(len(doc))()
+
+	return w.trainDoc(ctx, doc, rng, trained)
+}
+
+func (w *word2vec) trainDoc(ctx context.Context, doc []int, rng *modelutil.Rand, trained chan float64) error {
+	wk := newWorker(w.opts.Dim, rng)
 
 	for pos, id := range doc {
-		if w.subsampler.Trial(id) {
-			w.mod.trainOne(doc, pos, w.currentlr, w.param, w.optimizer)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
 		}
-		trained <- struct{}{}
+		loss := model.Skipped
+		if w.subsampler.Trial(id, rng) {
+			loss = w.mod.trainOne(doc, pos, w.currentlr, w.param, w.optimizer, wk)
+		}
+		trained <- loss
 	}
 
 	return nil
 }
 
-func (w *word2vec) observe(trained chan struct{}, clk *clock.Clock) {
-	var cnt int
-	for range trained {
+func (w *word2vec) observe(iter int, trained chan float64, observed chan struct{}, clk *clock.Clock) {
+	defer close(observed)
+	var (
+		cnt  int
+		loss model.LossMeter
+	)
+	progress := func() model.Progress {
+		p := model.Progress{
+			Iter:    iter,
+			Trained: cnt,
+			Total:   w.size(),
+			LR:      w.currentlr,
+			Loss:    loss.Mean(),
+			Elapsed: clk.AllElapsed(),
+		}
+		if w.queue != nil {
+			p.Queue, p.QueueCap = w.queue.Depth(), w.queue.Cap()
+		}
+		p.Goroutines = w.tuner.Limit()
+		return p
+	}
+	for v := range trained {
 		cnt++
+		loss.Add(v)
+		w.budget.Add(1)
 		if cnt%w.opts.UpdateLRBatch == 0 {
 			if w.currentlr < w.opts.MinLR {
 				w.currentlr = w.opts.MinLR
 			} else {
-				w.currentlr = w.opts.Initlr * (1.0 - float64(cnt)/float64(w.corpus.Len()))
+				w.currentlr = w.opts.Initlr * (1.0 - float64(cnt)/float64(w.size()))
 			}
 		}
-		w.verbose.Do(func() {
-			if cnt%w.opts.LogBatch == 0 {
-				fmt.Printf("trained %d words %v\r", cnt, clk.AllElapsed())
-			}
-		})
+		if cnt%w.opts.LogBatch == 0 {
+			w.opts.Hooks.OnProgress(progress())
+			w.verbose.Do(func() {
+				w.verbose.Printf("trained %d words %v\r", cnt, clk.AllElapsed())
+			})
+		}
 	}
+	w.opts.Hooks.AfterIter(progress())
 	w.verbose.Do(func() {
-		fmt.Printf("trained %d words %v\r\n", cnt, clk.AllElapsed())
+		w.verbose.Printf("trained %d words %v\r\n", cnt, clk.AllElapsed())
 	})
 }
 
+func (w *word2vec) size() int {
+	if w.pairs != nil {
+		return w.pairs.Len()
+	}
+	return w.corpus.Len()
+}
+
+func (w *word2vec) Dictionary() *dictionary.Dictionary {
+	if w.pairs != nil {
+		return w.pairs.Dictionary()
+	}
+	return w.corpus.Dictionary()
+}
+
 func (w *word2vec) Save(f io.Writer, typ vector.Type) error {
-	return vector.Save(f, w.corpus.Dictionary(), w.WordVector(typ), w.verbose, w.opts.LogBatch)
+	return vector.Save(f, w.Dictionary(), w.WordVector(typ), vector.Format{
+		Order:       w.opts.Order,
+		Notation:    w.opts.Notation,
+		Precision:   w.opts.Precision,
+		Words:       w.opts.SaveWords,
+		Top:         w.opts.SaveTop,
+		PhraseDelim: w.opts.PhraseDelim,
+		PhraseJoin:  w.opts.PhraseJoin,
+		PhraseMean:  w.opts.PhraseMean,
+	}, w.verbose, w.opts.LogBatch)
+}
+
+// Close unmaps the matrices of the mmap backing, see matrix.Backing.
+func (w *word2vec) Close() error {
+	return w.backing.Close()
 }
 
 func (w *word2vec) WordVector(typ vector.Type) *matrix.Matrix {
 	var mat *matrix.Matrix
-	dic := w.corpus.Dictionary()
+	dic := w.Dictionary()
 	ng, ok := w.optimizer.(*negativeSampling)
-	if typ == vector.Agg && ok {
+	// the contexts of dependency don't share the vocabulary with the words.
+	if typ == vector.Agg && ok && w.pairs == nil {
 		mat = matrix.New(dic.Len(), w.opts.Dim,
 			func(row int, vec []float64) {
 				for i := 0; i < w.opts.Dim; i++ {
-					vec[i] = w.param.Slice(row)[i] + ng.ctx.Slice(row)[i]
+					vec[i] = w.param.Slice(row)[i] + ng.ctx.Slice(ng.row(row))[i]
 				}
 			},
 		)
@@ -0,0 +1,79 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package classify
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/search"
+)
+
+// Classifier ranks the labeled entities for the text by cosine similarity
+// between the entities and the mean of the word vectors in the text.
+type Classifier struct {
+	words  map[string][]float64
+	labels *search.Searcher
+	dim    int
+}
+
+// New splits embs into the entities with the prefix (e.g. `__label__`) and the words.
+func New(embs embedding.Embeddings, prefix string) (*Classifier, error) {
+	if err := embs.Validate(); err != nil {
+		return nil, err
+	}
+	var labels embedding.Embeddings
+	words := make(map[string][]float64)
+	for _, emb := range embs {
+		if strings.HasPrefix(emb.Word, prefix) {
+			labels = append(labels, emb)
+		} else {
+			words[emb.Word] = emb.Vector
+		}
+	}
+	if labels.Empty() {
+		return nil, errors.Errorf("no entities with prefix %s", prefix)
+	}
+	searcher, err := search.New(labels...)
+	if err != nil {
+		return nil, err
+	}
+	return &Classifier{
+		words:  words,
+		labels: searcher,
+		dim:    labels[0].Dim,
+	}, nil
+}
+
+func (c *Classifier) Classify(text string, k int) (search.Neighbors, error) {
+	vec := make([]float64, c.dim)
+	var cnt int
+	for _, word := range strings.Fields(text) {
+		v, ok := c.words[word]
+		if !ok {
+			continue
+		}
+		for i := range vec {
+			vec[i] += v[i]
+		}
+		cnt++
+	}
+	if cnt == 0 {
+		return nil, errors.New("no known words in text")
+	}
+	return c.labels.SearchVector(vec, k)
+}
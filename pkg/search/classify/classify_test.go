@@ -0,0 +1,59 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package classify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/embutil"
+)
+
+func emb(word string, vec ...float64) embedding.Embedding {
+	return embedding.Embedding{
+		Word:   word,
+		Dim:    len(vec),
+		Vector: vec,
+		Norm:   embutil.Norm(vec),
+	}
+}
+
+func TestClassify(t *testing.T) {
+	c, err := New(embedding.Embeddings{
+		emb("__label__sports", 1, 0),
+		emb("__label__music", 0, 1),
+		emb("ball", 1, 0.1),
+		emb("goal", 0.9, 0.2),
+		emb("song", 0.1, 1),
+	}, "__label__")
+	assert.NoError(t, err)
+
+	res, err := c.Classify("ball goal unknown", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "__label__sports", res[0].Word)
+
+	res, err = c.Classify("song", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "__label__music", res[0].Word)
+	assert.Len(t, res, 2)
+
+	_, err = c.Classify("unknown", 1)
+	assert.Error(t, err)
+
+	_, err = New(embedding.Embeddings{emb("a", 1)}, "__label__")
+	assert.Error(t, err)
+}
@@ -16,47 +16,39 @@ package console
 
 import (
 	"fmt"
-	"go/ast"
-	"go/parser"
-	"go/token"
+	"os"
 
 	"github.com/peterh/liner"
 	"github.com/pkg/errors"
-	"github.com/ynqa/wego/pkg/embedding"
-	"github.com/ynqa/wego/pkg/embedding/embutil"
 	"github.com/ynqa/wego/pkg/search"
+	"github.com/ynqa/wego/pkg/search/expr"
 )
 
 type searchparams struct {
-	dim int
-	k   int
-}
-
-type searchcursor struct {
-	w1, w2 string
-	vector []float64
+	k    int
+	cols []search.Column
 }
 
 type Console struct {
 	*liner.State
 	searcher *search.Searcher
-	cursor   *searchcursor
 	params   *searchparams
 }
 
-func New(searcher *search.Searcher, k int) (*Console, error) {
+// New returns the console which shows k neighbors in the columns, search.DefaultColumns by default.
+func New(searcher *search.Searcher, k int, cols ...search.Column) (*Console, error) {
 	if searcher.Items.Empty() {
 		return nil, errors.New("Number of items for searcher must be over 0")
 	}
+	if len(cols) == 0 {
+		cols = search.DefaultColumns
+	}
 	return &Console{
 		State:    liner.NewLiner(),
 		searcher: searcher,
-		cursor: &searchcursor{
-			vector: make([]float64, searcher.Items[0].Dim),
-		},
 		params: &searchparams{
-			dim: searcher.Items[0].Dim,
-			k:   k,
+			k:    k,
+			cols: cols,
 		},
 	}, nil
 }
@@ -81,93 +73,11 @@ func (c *Console) Run() error {
 	}
 }
 
+// eval searches the neighbors of the expression of word vectors, e.g. king - man + woman.
 func (c *Console) eval(l string) error {
-	defer func() {
-		c.cursor.w1 = ""
-		c.cursor.w2 = ""
-		c.cursor.vector = make([]float64, c.params.dim)
-	}()
-
-	expr, err := parser.ParseExpr(l)
-	if err != nil {
-		return err
-	}
-
-	var neighbors search.Neighbors
-	switch e := expr.(type) {
-	case *ast.Ident:
-		neighbors, err = c.searcher.SearchInternal(e.String(), c.params.k)
-		if err != nil {
-			fmt.Printf("failed to search with word=%s\n", e.String())
-		}
-	case *ast.BinaryExpr:
-		if err := c.evalExpr(expr); err != nil {
-			return err
-		}
-		neighbors, err = c.searcher.Search(embedding.Embedding{
-			Vector: c.cursor.vector,
-			Norm:   embutil.Norm(c.cursor.vector),
-		}, c.params.k, c.cursor.w1, c.cursor.w2)
-		if err != nil {
-			fmt.Printf("failed to search with vector=%v\n", c.cursor.vector)
-		}
-	default:
-		return errors.Errorf("invalid type %v", e)
-	}
-	neighbors.Describe()
-	return nil
-}
-
-func (c *Console) evalExpr(expr ast.Expr) error {
-	switch e := expr.(type) {
-	case *ast.BinaryExpr:
-		return c.evalBinaryExpr(e)
-	case *ast.Ident:
-		return nil
-	default:
-		return errors.Errorf("invalid type %v", e)
-	}
-}
-
-func (c *Console) evalBinaryExpr(expr *ast.BinaryExpr) error {
-	xi, err := c.evalAsEmbedding(expr.X)
+	neighbors, err := expr.Search(c.searcher, l, c.params.k)
 	if err != nil {
 		return err
 	}
-	yi, err := c.evalAsEmbedding(expr.Y)
-	if err != nil {
-		return nil
-	}
-	c.cursor.w1 = xi.Word
-	c.cursor.w2 = yi.Word
-	c.cursor.vector, err = arithmetic(xi.Vector, expr.Op, yi.Vector)
-	return err
-}
-
-func (c *Console) evalAsEmbedding(expr ast.Expr) (embedding.Embedding, error) {
-	if err := c.evalExpr(expr); err != nil {
-		return embedding.Embedding{}, err
-	}
-	v, ok := expr.(*ast.Ident)
-	if !ok {
-		return embedding.Embedding{}, errors.Errorf("failed to parse %v", expr)
-	}
-	vi, ok := c.searcher.Items.Find(v.String())
-	if !ok {
-		return embedding.Embedding{}, errors.Errorf("not found word=%s in vector map", v.String())
-	} else if err := vi.Validate(); err != nil {
-		return embedding.Embedding{}, err
-	}
-	return vi, nil
-}
-
-func arithmetic(v1 []float64, op token.Token, v2 []float64) ([]float64, error) {
-	switch op {
-	case token.ADD:
-		return add(v1, v2)
-	case token.SUB:
-		return sub(v1, v2)
-	default:
-		return nil, errors.Errorf("invalid operator %v", op.String())
-	}
+	return neighbors.WriteColumns(os.Stdout, search.Table, c.params.cols)
 }
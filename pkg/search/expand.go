@@ -0,0 +1,169 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/embutil"
+)
+
+// Term is the term of the expanded query with the weight, 1 for the original term
+// and the cosine similarity to it for the others.
+type Term struct {
+	Term   string  `json:"term"`
+	Weight float64 `json:"weight"`
+}
+
+type Terms []Term
+
+// luceneSpecial is the characters to be escaped in the query syntax of Lucene.
+var luceneSpecial = strings.NewReplacer(
+	`\`, `\\`, `+`, `\+`, `-`, `\-`, `&`, `\&`, `|`, `\|`, `!`, `\!`, `(`, `\(`, `)`, `\)`,
+	`{`, `\{`, `}`, `\}`, `[`, `\[`, `]`, `\]`, `^`, `\^`, `"`, `\"`, `~`, `\~`, `*`, `\*`,
+	`?`, `\?`, `:`, `\:`, `/`, `\/`,
+)
+
+// Lucene formats the terms as the boosted query of Lucene or Elasticsearch query_string, e.g. `laptop^1 notebook^0.82`.
+// The weights are rounded to 4 decimal places, and the phrases are quoted.
+func (terms Terms) Lucene() string {
+	clauses := make([]string, len(terms))
+	for i, t := range terms {
+		term := luceneSpecial.Replace(t.Term)
+		if strings.ContainsAny(t.Term, " \t") {
+			term = `"` + term + `"`
+		}
+		clauses[i] = term + "^" + strconv.FormatFloat(math.Round(t.Weight*1e4)/1e4, 'f', -1, 64)
+	}
+	return strings.Join(clauses, " ")
+}
+
+// Expand returns the term followed by its at most k nearest words whose similarity is at least minSim,
+// weighted by the similarity to be fed into BM25 queries. The term may be the phrase as Vector,
+// and the words of the phrase are not included in the expansion.
+func (s *Searcher) Expand(term string, k int, minSim float64) (Terms, error) {
+	if k < 0 {
+		return nil, errors.Errorf("k must be >= 0, got %d", k)
+	}
+	vec, err := s.Vector(term)
+	if err != nil {
+		return nil, err
+	}
+	terms := Terms{{Term: term, Weight: 1}}
+	if k == 0 {
+		return terms, nil
+	}
+	ignore := append([]string{term}, strings.Fields(term)...)
+	neighbors, err := s.Search(embedding.Embedding{Vector: vec, Norm: embutil.Norm(vec)}, k, ignore...)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range neighbors {
+		if n.Similarity < minSim {
+			break
+		}
+		terms = append(terms, Term{Term: n.Word, Weight: n.Similarity})
+	}
+	return terms, nil
+}
+
+// DefaultMaxK is the largest k of the requests to Expander unless MaxK is set.
+const DefaultMaxK = 1000
+
+// Expander serves Expand over HTTP with the defaults of K and MinSim.
+type Expander struct {
+	Searcher *Searcher
+	K        int
+	MinSim   float64
+	// MaxK is the largest k of the requests, DefaultMaxK if 0.
+	MaxK int
+}
+
+type expandRequest struct {
+	Term   string   `json:"term"`
+	K      *int     `json:"k"`
+	MinSim *float64 `json:"min_sim"`
+}
+
+type expandResponse struct {
+	Terms  Terms  `json:"terms"`
+	Lucene string `json:"lucene"`
+}
+
+// ServeHTTP takes the JSON of {"term": term, "k": k, "min_sim": minSim} by POST, or the query parameters
+// term, k and min_sim by GET, and responds {"terms": [{"term": term, "weight": weight}], "lucene": query}.
+// k and min_sim are optional, and k out of [0, MaxK] is a bad request.
+func (e *Expander) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req expandRequest
+	switch r.Method {
+	case http.MethodGet:
+		q := r.URL.Query()
+		req.Term = q.Get("term")
+		if v := q.Get("k"); v != "" {
+			k, err := strconv.Atoi(v)
+			if err != nil {
+				http.Error(w, "invalid k: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			req.K = &k
+		}
+		if v := q.Get("min_sim"); v != "" {
+			minSim, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				http.Error(w, "invalid min_sim: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			req.MinSim = &minSim
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	k, minSim := e.K, e.MinSim
+	if req.K != nil {
+		k = *req.K
+	}
+	if req.MinSim != nil {
+		minSim = *req.MinSim
+	}
+	maxK := e.MaxK
+	if maxK == 0 {
+		maxK = DefaultMaxK
+	}
+	if k < 0 || k > maxK {
+		http.Error(w, fmt.Sprintf("k must be in [0, %d], got %d", maxK, k), http.StatusBadRequest)
+		return
+	}
+	terms, err := e.Searcher.Expand(req.Term, k, minSim)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(expandResponse{Terms: terms, Lucene: terms.Lucene()})
+}
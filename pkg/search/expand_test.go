@@ -0,0 +1,103 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/embutil"
+)
+
+func newExpandSearcher(t *testing.T) *Searcher {
+	vecs := map[string][]float64{
+		"laptop":   {1, 0},
+		"notebook": {0.9, 0.1},
+		"pc":       {0.7, 0.7},
+		"banana":   {-1, 0.2},
+	}
+	var items embedding.Embeddings
+	for _, w := range []string{"laptop", "notebook", "pc", "banana"} {
+		items = append(items, embedding.Embedding{
+			Word:   w,
+			Dim:    2,
+			Vector: vecs[w],
+			Norm:   embutil.Norm(vecs[w]),
+		})
+	}
+	s, err := New(items...)
+	assert.NoError(t, err)
+	return s
+}
+
+func TestExpand(t *testing.T) {
+	s := newExpandSearcher(t)
+	terms, err := s.Expand("laptop", 3, 0.5)
+	assert.NoError(t, err)
+	assert.Len(t, terms, 3)
+	assert.Equal(t, Term{Term: "laptop", Weight: 1}, terms[0])
+	assert.Equal(t, "notebook", terms[1].Term)
+	assert.Equal(t, "pc", terms[2].Term)
+	assert.InDelta(t, 0.707107, terms[2].Weight, 1e-6)
+
+	terms, err = s.Expand("laptop", 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, Terms{{Term: "laptop", Weight: 1}}, terms)
+
+	_, err = s.Expand("tablet", 3, 0)
+	assert.Error(t, err)
+}
+
+func TestLucene(t *testing.T) {
+	terms := Terms{{Term: "laptop", Weight: 1}, {Term: "c++", Weight: 0.5}, {Term: "note book", Weight: 0.25}}
+	assert.Equal(t, `laptop^1 c\+\+^0.5 "note book"^0.25`, terms.Lucene())
+}
+
+func TestExpanderServeHTTP(t *testing.T) {
+	srv := httptest.NewServer(&Expander{Searcher: newExpandSearcher(t), K: 1})
+	defer srv.Close()
+
+	var res expandResponse
+	resp, err := http.Get(srv.URL + "?term=laptop&k=2&min_sim=0.9")
+	assert.NoError(t, err)
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	resp.Body.Close()
+	assert.Len(t, res.Terms, 2)
+	assert.True(t, strings.HasPrefix(res.Lucene, "laptop^1 notebook^0.99"))
+
+	resp, err = http.Post(srv.URL, "application/json", strings.NewReader(`{"term": "laptop"}`))
+	assert.NoError(t, err)
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	resp.Body.Close()
+	assert.Len(t, res.Terms, 2)
+
+	resp, err = http.Get(srv.URL + "?term=tablet")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+
+	for _, k := range []string{"x", "-1", "1000000000000"} {
+		resp, err = http.Get(srv.URL + "?term=laptop&k=" + k)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "k=%s", k)
+	}
+}
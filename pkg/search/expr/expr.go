@@ -0,0 +1,357 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package expr evaluates the algebra of word vectors, e.g. "(paris - france + italy) * 0.8 + rome * 0.2".
+//
+// The expression consists of the words, the numbers, the operators +, -, *, / and the parentheses.
+// The words are separated by spaces or the operators, and quoted by " for the words which contain them,
+// e.g. "new-york". The vectors are added or subtracted with each other, and multiplied or divided by the numbers.
+package expr
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/embutil"
+	"github.com/ynqa/wego/pkg/search"
+)
+
+// ErrSyntax is the error of the malformed expression, which the errors of Parse wrap.
+var ErrSyntax = errors.New("syntax error")
+
+func syntaxError(format string, args ...interface{}) error {
+	return errors.Wrapf(ErrSyntax, format, args...)
+}
+
+// Lookup returns the vector of the word.
+type Lookup func(word string) ([]float64, bool)
+
+type Expr struct {
+	root  node
+	words []string
+}
+
+// Parse parses the expression.
+func Parse(s string) (*Expr, error) {
+	toks, err := lex(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+	root, err := p.sum()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.toks) {
+		return nil, syntaxError("unexpected %q at %d", p.toks[p.pos].text, p.toks[p.pos].at)
+	}
+	return &Expr{root: root, words: p.words}, nil
+}
+
+// Words returns the words in the expression in order, which are usually excluded from the neighbors.
+func (e *Expr) Words() []string {
+	return e.words
+}
+
+// Eval returns the vector of the expression, which must not be a number.
+func (e *Expr) Eval(lookup Lookup) ([]float64, error) {
+	v, err := e.root.eval(lookup)
+	if err != nil {
+		return nil, err
+	}
+	if v.vec == nil {
+		return nil, errors.New("expression must be a vector, got a number")
+	}
+	return v.vec, nil
+}
+
+// Eval evaluates the expression over the words in the searcher.
+func Eval(s *search.Searcher, expression string) ([]float64, error) {
+	e, err := Parse(expression)
+	if err != nil {
+		return nil, err
+	}
+	return e.Eval(lookup(s))
+}
+
+// Search returns the k nearest neighbors to the vector of the expression, excluding its words.
+func Search(s *search.Searcher, expression string, k int) (search.Neighbors, error) {
+	e, err := Parse(expression)
+	if err != nil {
+		return nil, err
+	}
+	vec, err := e.Eval(lookup(s))
+	if err != nil {
+		return nil, err
+	}
+	return s.Search(embedding.Embedding{
+		Vector: vec,
+		Norm:   embutil.Norm(vec),
+	}, k, e.Words()...)
+}
+
+func lookup(s *search.Searcher) Lookup {
+	return func(word string) ([]float64, bool) {
+		item, ok := s.Lookup(word)
+		return item.Vector, ok
+	}
+}
+
+type kind int
+
+const (
+	word kind = iota
+	number
+	op
+)
+
+type token struct {
+	kind kind
+	text string
+	at   int
+}
+
+const operators = "+-*/()"
+
+func lex(s string) ([]token, error) {
+	var toks []token
+	rs := []rune(s)
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case strings.ContainsRune(operators, r):
+			toks = append(toks, token{kind: op, text: string(r), at: i})
+			i++
+		case r == '"':
+			j := i + 1
+			for j < len(rs) && rs[j] != '"' {
+				j++
+			}
+			if j == len(rs) {
+				return nil, syntaxError("unterminated quote at %d", i)
+			}
+			toks = append(toks, token{kind: word, text: string(rs[i+1 : j]), at: i})
+			i = j + 1
+		default:
+			j := i
+			for j < len(rs) && !unicode.IsSpace(rs[j]) && !strings.ContainsRune(operators, rs[j]) && rs[j] != '"' {
+				j++
+			}
+			text := string(rs[i:j])
+			k := word
+			if _, err := strconv.ParseFloat(text, 64); err == nil {
+				k = number
+			}
+			toks = append(toks, token{kind: k, text: text, at: i})
+			i = j
+		}
+	}
+	if len(toks) == 0 {
+		return nil, syntaxError("expression is empty")
+	}
+	return toks, nil
+}
+
+// parser is the recursive descent parser by the grammar:
+//
+//	sum     = product { ("+" | "-") product }
+//	product = unary { ("*" | "/") unary }
+//	unary   = "-" unary | primary
+//	primary = word | number | "(" sum ")"
+type parser struct {
+	toks  []token
+	pos   int
+	words []string
+}
+
+func (p *parser) peek(text string) bool {
+	return p.pos < len(p.toks) && p.toks[p.pos].kind == op && p.toks[p.pos].text == text
+}
+
+func (p *parser) sum() (node, error) {
+	x, err := p.product()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek("+") || p.peek("-") {
+		o := p.toks[p.pos].text
+		p.pos++
+		y, err := p.product()
+		if err != nil {
+			return nil, err
+		}
+		x = binary{op: o, x: x, y: y}
+	}
+	return x, nil
+}
+
+func (p *parser) product() (node, error) {
+	x, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek("*") || p.peek("/") {
+		o := p.toks[p.pos].text
+		p.pos++
+		y, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		x = binary{op: o, x: x, y: y}
+	}
+	return x, nil
+}
+
+func (p *parser) unary() (node, error) {
+	if p.peek("-") {
+		p.pos++
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return binary{op: "*", x: scalar(-1), y: x}, nil
+	}
+	return p.primary()
+}
+
+func (p *parser) primary() (node, error) {
+	if p.pos == len(p.toks) {
+		return nil, syntaxError("unexpected end of expression")
+	}
+	t := p.toks[p.pos]
+	p.pos++
+	switch {
+	case t.kind == word:
+		p.words = append(p.words, t.text)
+		return ident(t.text), nil
+	case t.kind == number:
+		v, _ := strconv.ParseFloat(t.text, 64)
+		return scalar(v), nil
+	case t.text == "(":
+		x, err := p.sum()
+		if err != nil {
+			return nil, err
+		}
+		if !p.peek(")") {
+			return nil, syntaxError("missing ) for ( at %d", t.at)
+		}
+		p.pos++
+		return x, nil
+	default:
+		return nil, syntaxError("unexpected %q at %d", t.text, t.at)
+	}
+}
+
+// value is the vector, or the number if vec is nil.
+type value struct {
+	vec []float64
+	num float64
+}
+
+type node interface {
+	eval(Lookup) (value, error)
+}
+
+type ident string
+
+func (n ident) eval(lookup Lookup) (value, error) {
+	vec, ok := lookup(string(n))
+	if !ok {
+		return value{}, errors.Wrap(search.ErrWordNotFound, string(n))
+	}
+	return value{vec: vec}, nil
+}
+
+type scalar float64
+
+func (n scalar) eval(Lookup) (value, error) {
+	return value{num: float64(n)}, nil
+}
+
+type binary struct {
+	op   string
+	x, y node
+}
+
+func (n binary) eval(lookup Lookup) (value, error) {
+	x, err := n.x.eval(lookup)
+	if err != nil {
+		return value{}, err
+	}
+	y, err := n.y.eval(lookup)
+	if err != nil {
+		return value{}, err
+	}
+	switch {
+	case x.vec == nil && y.vec == nil:
+		switch n.op {
+		case "+":
+			return value{num: x.num + y.num}, nil
+		case "-":
+			return value{num: x.num - y.num}, nil
+		case "*":
+			return value{num: x.num * y.num}, nil
+		default:
+			if y.num == 0 {
+				return value{}, errors.New("division by zero")
+			}
+			return value{num: x.num / y.num}, nil
+		}
+	case x.vec != nil && y.vec != nil:
+		if n.op != "+" && n.op != "-" {
+			return value{}, errors.Errorf("vectors can't be operated by %s", n.op)
+		}
+		if len(x.vec) != len(y.vec) {
+			return value{}, errors.Errorf("dimension of vectors must be the same, got %d and %d", len(x.vec), len(y.vec))
+		}
+		sign := 1.
+		if n.op == "-" {
+			sign = -1
+		}
+		vec := make([]float64, len(x.vec))
+		for i := range vec {
+			vec[i] = x.vec[i] + sign*y.vec[i]
+		}
+		return value{vec: vec}, nil
+	default:
+		vec, num := x.vec, y.num
+		if vec == nil {
+			if n.op != "*" {
+				return value{}, errors.Errorf("number can't be operated by vector with %s", n.op)
+			}
+			vec, num = y.vec, x.num
+		}
+		switch n.op {
+		case "*":
+		case "/":
+			if num == 0 {
+				return value{}, errors.New("division by zero")
+			}
+			num = 1 / num
+		default:
+			return value{}, errors.Errorf("vector and number can't be operated by %s", n.op)
+		}
+		res := make([]float64, len(vec))
+		for i, v := range vec {
+			res[i] = v * num
+		}
+		return value{vec: res}, nil
+	}
+}
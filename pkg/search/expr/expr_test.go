@@ -0,0 +1,99 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/embutil"
+	"github.com/ynqa/wego/pkg/search"
+)
+
+func TestEval(t *testing.T) {
+	vecs := map[string][]float64{
+		"paris":    {1, 0},
+		"france":   {0, 1},
+		"italy":    {1, 1},
+		"rome":     {2, 1},
+		"new-york": {3, 3},
+	}
+	lookup := func(word string) ([]float64, bool) {
+		v, ok := vecs[word]
+		return v, ok
+	}
+
+	for _, c := range []struct {
+		expr  string
+		want  []float64
+		words []string
+	}{
+		{expr: "paris", want: []float64{1, 0}, words: []string{"paris"}},
+		{expr: "(paris - france + italy) * 0.8 + rome * 0.2", want: []float64{2, 0.2}, words: []string{"paris", "france", "italy", "rome"}},
+		{expr: "-paris + 2 * 3 * france / 2", want: []float64{-1, 3}, words: []string{"paris", "france"}},
+		{expr: `"new-york"-paris`, want: []float64{2, 3}, words: []string{"new-york", "paris"}},
+	} {
+		e, err := Parse(c.expr)
+		assert.NoError(t, err, c.expr)
+		vec, err := e.Eval(lookup)
+		assert.NoError(t, err, c.expr)
+		assert.InDeltaSlice(t, c.want, vec, 1e-9, c.expr)
+		assert.Equal(t, c.words, e.Words(), c.expr)
+	}
+
+	for _, expr := range []string{"", "paris +", "(paris", "paris )", `"paris`} {
+		_, err := Parse(expr)
+		assert.True(t, errors.Is(err, ErrSyntax), expr)
+	}
+	for _, expr := range []string{"1 + 2", "paris * france", "2 / paris", "paris / 0", "paris + 1", "tokyo"} {
+		e, err := Parse(expr)
+		assert.NoError(t, err, expr)
+		_, err = e.Eval(lookup)
+		assert.Error(t, err, expr)
+	}
+	e, err := Parse("tokyo")
+	assert.NoError(t, err)
+	_, err = e.Eval(lookup)
+	assert.True(t, errors.Is(err, search.ErrWordNotFound))
+}
+
+func TestSearch(t *testing.T) {
+	var embs embedding.Embeddings
+	for _, w := range []struct {
+		word string
+		vec  []float64
+	}{
+		{"king", []float64{1, 1}},
+		{"man", []float64{1, 0}},
+		{"woman", []float64{0.9, -0.1}},
+		{"queen", []float64{0.9, 0.9}},
+		{"apple", []float64{-1, 0.2}},
+	} {
+		embs = append(embs, embedding.Embedding{Word: w.word, Dim: 2, Vector: w.vec, Norm: embutil.Norm(w.vec)})
+	}
+	s, err := search.New(embs...)
+	assert.NoError(t, err)
+
+	neighbors, err := Search(s, "king - man + woman", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "queen", neighbors[0].Word)
+
+	vec, err := Eval(s, "king * 0.5")
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{0.5, 0.5}, vec)
+}
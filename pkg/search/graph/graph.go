@@ -0,0 +1,152 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"bufio"
+	"encoding/xml"
+	"io"
+	"runtime"
+	"strconv"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/search"
+)
+
+type Format = string
+
+const (
+	EdgeList Format = "edgelist"
+	GraphML  Format = "graphml"
+)
+
+var (
+	defaultFormat     = EdgeList
+	defaultGoroutines = runtime.NumCPU()
+	defaultK          = 10
+)
+
+// Options is for the k-nearest neighbor graph over the vocabulary.
+type Options struct {
+	Format     Format
+	Goroutines int
+	K          int
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Format:     defaultFormat,
+		Goroutines: defaultGoroutines,
+		K:          defaultK,
+	}
+}
+
+// batchPerThread is the number of words searched by a goroutine before their edges are written,
+// so that the edges are never held in memory for the whole vocabulary.
+const batchPerThread = 64
+
+// Write writes the directed edges from each word to its k nearest neighbors, weighted by cosine similarity.
+// EdgeList is the lines of `<source> <target> <weight>`, and GraphML is readable by Gephi.
+func Write(w io.Writer, embs embedding.Embeddings, opts Options) error {
+	switch opts.Format {
+	case EdgeList, GraphML:
+	default:
+		return errors.Errorf("invalid format: %s not in %s|%s", opts.Format, EdgeList, GraphML)
+	}
+	if opts.K <= 0 || opts.Goroutines <= 0 {
+		return errors.Errorf("k and goroutines must be positive, got %d and %d", opts.K, opts.Goroutines)
+	}
+	searcher, err := search.New(embs...)
+	if err != nil {
+		return err
+	}
+
+	buf := bufio.NewWriter(w)
+	if opts.Format == GraphML {
+		writeGraphMLHeader(buf, embs)
+	}
+
+	batch := make([]search.Neighbors, opts.Goroutines*batchPerThread)
+	for s := 0; s < len(embs); s += len(batch) {
+		e := s + len(batch)
+		if e > len(embs) {
+			e = len(embs)
+		}
+		wg := &sync.WaitGroup{}
+		for g := 0; g < opts.Goroutines; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				for i := s + g; i < e; i += opts.Goroutines {
+					// Search never fails for the embeddings which are already validated.
+					batch[i-s], _ = searcher.Search(embs[i], opts.K, embs[i].Word)
+				}
+			}(g)
+		}
+		wg.Wait()
+
+		for i := s; i < e; i++ {
+			for _, n := range batch[i-s] {
+				if opts.Format == GraphML {
+					writeGraphMLEdge(buf, embs[i].Word, n)
+				} else {
+					writeEdge(buf, embs[i].Word, n)
+				}
+			}
+		}
+		if err := buf.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if opts.Format == GraphML {
+		buf.WriteString("  </graph>\n</graphml>\n")
+	}
+	return buf.Flush()
+}
+
+func writeEdge(buf *bufio.Writer, word string, n search.Neighbor) {
+	buf.WriteString(word)
+	buf.WriteByte(' ')
+	buf.WriteString(n.Word)
+	buf.WriteByte(' ')
+	buf.WriteString(strconv.FormatFloat(n.Similarity, 'f', 6, 64))
+	buf.WriteByte('\n')
+}
+
+func writeGraphMLHeader(buf *bufio.Writer, embs embedding.Embeddings) {
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">` + "\n")
+	buf.WriteString(`  <key id="weight" for="edge" attr.name="weight" attr.type="double"/>` + "\n")
+	buf.WriteString(`  <graph id="G" edgedefault="directed">` + "\n")
+	for _, emb := range embs {
+		buf.WriteString(`    <node id="`)
+		xml.EscapeText(buf, []byte(emb.Word))
+		buf.WriteString("\"/>\n")
+	}
+}
+
+func writeGraphMLEdge(buf *bufio.Writer, word string, n search.Neighbor) {
+	buf.WriteString(`    <edge source="`)
+	xml.EscapeText(buf, []byte(word))
+	buf.WriteString(`" target="`)
+	xml.EscapeText(buf, []byte(n.Word))
+	buf.WriteString(`"><data key="weight">`)
+	buf.WriteString(strconv.FormatFloat(n.Similarity, 'f', 6, 64))
+	buf.WriteString("</data></edge>\n")
+}
@@ -0,0 +1,80 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/embutil"
+)
+
+func testEmbeddings() embedding.Embeddings {
+	vecs := [][]float64{{1, 0}, {0.9, 0.1}, {0, 1}}
+	var embs embedding.Embeddings
+	for i, w := range []string{"a", "b", "<c>"} {
+		embs = append(embs, embedding.Embedding{
+			Word:   w,
+			Dim:    2,
+			Vector: vecs[i],
+			Norm:   embutil.Norm(vecs[i]),
+		})
+	}
+	return embs
+}
+
+func TestWriteEdgeList(t *testing.T) {
+	var buf bytes.Buffer
+	opts := DefaultOptions()
+	opts.K = 1
+	opts.Goroutines = 2
+	assert.NoError(t, Write(&buf, testEmbeddings(), opts))
+	assert.Equal(t, "a b 0.993884\nb a 0.993884\n<c> b 0.110432\n", buf.String())
+}
+
+func TestWriteGraphML(t *testing.T) {
+	var buf bytes.Buffer
+	opts := DefaultOptions()
+	opts.Format = GraphML
+	opts.K = 5
+	assert.NoError(t, Write(&buf, testEmbeddings(), opts))
+
+	var doc struct {
+		Graph struct {
+			Nodes []struct {
+				ID string `xml:"id,attr"`
+			} `xml:"node"`
+			Edges []struct {
+				Source string `xml:"source,attr"`
+				Target string `xml:"target,attr"`
+			} `xml:"edge"`
+		} `xml:"graph"`
+	}
+	assert.NoError(t, xml.Unmarshal(buf.Bytes(), &doc))
+	assert.Len(t, doc.Graph.Nodes, 3)
+	assert.Equal(t, "<c>", doc.Graph.Nodes[2].ID)
+	// a and <c> are orthogonal, so the edge between them is dropped.
+	assert.Len(t, doc.Graph.Edges, 4)
+}
+
+func TestWriteInvalidFormat(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Format = "dot"
+	assert.Error(t, Write(&bytes.Buffer{}, testEmbeddings(), opts))
+}
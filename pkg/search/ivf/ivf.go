@@ -0,0 +1,241 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ivf is the inverted file index which partitions the vectors into the lists by
+// the coarse centroids of spherical k-means, and scans only the nprobe nearest lists to the query.
+package ivf
+
+import (
+	"math"
+	"math/rand"
+	"runtime"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/cluster"
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/embutil"
+	"github.com/ynqa/wego/pkg/model"
+	"github.com/ynqa/wego/pkg/search"
+	"github.com/ynqa/wego/pkg/search/searchutil"
+	"github.com/ynqa/wego/pkg/util/parallel"
+	"github.com/ynqa/wego/pkg/vector"
+)
+
+var (
+	defaultGoroutines = runtime.NumCPU()
+	defaultIter       = 10
+	defaultLists      = 0
+	defaultLogBatch   = 100000
+	defaultNProbe     = 8
+	defaultSeed       = int64(0)
+	defaultVerbose    = false
+)
+
+// trainPerList is the number of the samples per list to train the centroids,
+// which bounds the cost of k-means on the large vocabulary.
+const trainPerList = 256
+
+// Options is for the IVF index.
+type Options struct {
+	Goroutines int
+	Iter       int
+	Lists      int
+	LogBatch   int
+	NProbe     int
+	Seed       int64
+	Verbose    bool
+
+	// Hooks is notified of the build as training, where the iterations of k-means
+	// are followed by the iteration to add the vectors into the lists.
+	Hooks model.Hooks `json:"-"`
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Goroutines: defaultGoroutines,
+		Iter:       defaultIter,
+		Lists:      defaultLists,
+		LogBatch:   defaultLogBatch,
+		NProbe:     defaultNProbe,
+		Seed:       defaultSeed,
+		Verbose:    defaultVerbose,
+	}
+}
+
+type Index struct {
+	opts      Options
+	dim       int
+	centroids [][]float64
+	lists     []*search.Searcher
+	words     map[string]embedding.Embedding
+	// positions are the order of the words added from 1, for Neighbor.Position.
+	positions map[string]uint
+}
+
+// New trains the centroids on embs and adds them to the index.
+func New(embs embedding.Embeddings, opts Options) (x *Index, err error) {
+	opts.Hooks.BeforeTrain(opts)
+	defer func() {
+		opts.Hooks.AfterTrain(err)
+	}()
+
+	if embs.Empty() {
+		return nil, errors.New("embeddings are empty")
+	}
+	if err := embs.Validate(); err != nil {
+		return nil, err
+	}
+	if opts.Lists == 0 {
+		opts.Lists = int(math.Sqrt(float64(len(embs))))
+	}
+	if opts.Lists <= 0 || opts.Lists > len(embs) {
+		return nil, errors.Errorf("lists must be in [1, %d], got %d", len(embs), opts.Lists)
+	}
+	if opts.NProbe <= 0 || opts.Iter < 0 || opts.Goroutines <= 0 {
+		return nil, errors.Errorf("nprobe and goroutines must be positive and iter must be non-negative, got %d, %d and %d", opts.NProbe, opts.Goroutines, opts.Iter)
+	}
+	x = &Index{
+		opts:      opts,
+		dim:       embs[0].Dim,
+		centroids: train(embs, opts),
+		lists:     make([]*search.Searcher, opts.Lists),
+		words:     make(map[string]embedding.Embedding, len(embs)),
+		positions: make(map[string]uint, len(embs)),
+	}
+	for i := range x.lists {
+		x.lists[i] = &search.Searcher{}
+	}
+	if err := x.add(opts.Iter+1, embs); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+func (opts Options) runner() parallel.Runner {
+	return parallel.Runner{
+		Goroutines: opts.Goroutines,
+		LogBatch:   opts.LogBatch,
+		Hooks:      opts.Hooks,
+		Verbose:    opts.Verbose,
+		Unit:       "vectors",
+	}
+}
+
+// train runs spherical k-means on the sample of embs, and returns the unit centroids.
+func train(embs embedding.Embeddings, opts Options) [][]float64 {
+	rng := rand.New(rand.NewSource(opts.Seed))
+	sample := make([][]float64, 0, len(embs))
+	for _, i := range rng.Perm(len(embs)) {
+		if len(sample) == opts.Lists*trainPerList {
+			break
+		}
+		sample = append(sample, vector.Unit(embs[i].Vector))
+	}
+	return cluster.Spherical(sample, opts.Lists, opts.Iter, rng, opts.runner().Each)
+}
+
+// Add appends embs into the lists of their nearest centroids without retraining,
+// so the index is built incrementally. The centroids get stale when the new vectors
+// drift from the trained ones, and then New should rebuild the index.
+func (x *Index) Add(embs ...embedding.Embedding) error {
+	return x.add(1, embs)
+}
+
+func (x *Index) add(iter int, embs []embedding.Embedding) error {
+	for _, emb := range embs {
+		if err := emb.Validate(); err != nil {
+			return err
+		}
+		if emb.Dim != x.dim {
+			return errors.Errorf("dimension for all vectors must be the same: %d but got %d", x.dim, emb.Dim)
+		}
+		if _, ok := x.words[emb.Word]; ok {
+			return errors.Errorf("%s is already in the index", emb.Word)
+		}
+	}
+	assign := make([]int, len(embs))
+	x.opts.runner().Each(iter, len(embs), func(i int) {
+		assign[i] = cluster.Nearest(x.centroids, embs[i].Vector)
+	})
+	for i, emb := range embs {
+		list := x.lists[assign[i]]
+		list.Items = append(list.Items, emb)
+		x.words[emb.Word] = emb
+		x.positions[emb.Word] = uint(len(x.words))
+	}
+	return nil
+}
+
+func (x *Index) Len() int {
+	return len(x.words)
+}
+
+func (x *Index) SearchInternal(word string, k int) (search.Neighbors, error) {
+	q, ok := x.words[word]
+	if !ok {
+		return nil, errors.Wrap(search.ErrWordNotFound, word)
+	}
+	return x.Search(q, k, word)
+}
+
+func (x *Index) SearchVector(query []float64, k int) (search.Neighbors, error) {
+	return x.Search(embedding.Embedding{
+		Vector: query,
+		Norm:   embutil.Norm(query),
+	}, k)
+}
+
+// Search returns the approximate k nearest neighbors in the nprobe lists
+// whose centroids are the nearest to the query.
+func (x *Index) Search(query embedding.Embedding, k int, ignoreWord ...string) (search.Neighbors, error) {
+	if len(query.Vector) != x.dim {
+		return nil, errors.Errorf("dimension of query must be %d, got %d", x.dim, len(query.Vector))
+	}
+	if k <= 0 {
+		return nil, errors.Errorf("k must be positive, got %d", k)
+	}
+	lists := make([]int, len(x.centroids))
+	scores := make([]float64, len(x.centroids))
+	for c, centroid := range x.centroids {
+		lists[c], scores[c] = c, searchutil.Cosine(centroid, query.Vector, 1, query.Norm)
+	}
+	sort.SliceStable(lists, func(i, j int) bool {
+		return scores[lists[i]] > scores[lists[j]]
+	})
+	if len(lists) > x.opts.NProbe {
+		lists = lists[:x.opts.NProbe]
+	}
+
+	var neighbors search.Neighbors
+	for _, c := range lists {
+		res, err := x.lists[c].Search(query, k, ignoreWord...)
+		if err != nil {
+			return nil, err
+		}
+		neighbors = append(neighbors, res...)
+	}
+	sort.SliceStable(neighbors, func(i, j int) bool {
+		return neighbors[i].Similarity > neighbors[j].Similarity
+	})
+	if len(neighbors) > k {
+		neighbors = neighbors[:k]
+	}
+	for i := range neighbors {
+		neighbors[i].Rank = uint(i) + 1
+		neighbors[i].Position = x.positions[neighbors[i].Word]
+	}
+	return neighbors, nil
+}
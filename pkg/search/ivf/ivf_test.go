@@ -0,0 +1,137 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivf
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/embutil"
+	"github.com/ynqa/wego/pkg/model"
+	"github.com/ynqa/wego/pkg/search"
+)
+
+func clustered(n, dim int) embedding.Embeddings {
+	rng := rand.New(rand.NewSource(1))
+	centers := make([][]float64, 10)
+	for c := range centers {
+		centers[c] = make([]float64, dim)
+		for d := range centers[c] {
+			centers[c][d] = rng.NormFloat64()
+		}
+	}
+	embs := make(embedding.Embeddings, n)
+	for i := range embs {
+		vec := make([]float64, dim)
+		for d := range vec {
+			vec[d] = centers[i%len(centers)][d] + 0.3*rng.NormFloat64()
+		}
+		embs[i] = embedding.Embedding{
+			Word:   fmt.Sprintf("w%d", i),
+			Dim:    dim,
+			Vector: vec,
+			Norm:   embutil.Norm(vec),
+		}
+	}
+	return embs
+}
+
+func TestSearch(t *testing.T) {
+	embs := clustered(1000, 8)
+	brute, err := search.New(embs...)
+	assert.NoError(t, err)
+
+	opts := DefaultOptions()
+	opts.NProbe = 1000
+	x, err := New(embs, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 1000, x.Len())
+	exact, err := x.SearchInternal("w0", 10)
+	assert.NoError(t, err)
+	want, err := brute.SearchInternal("w0", 10)
+	assert.NoError(t, err)
+	assert.Equal(t, want, exact)
+
+	opts.NProbe = 4
+	x, err = New(embs, opts)
+	assert.NoError(t, err)
+	var hits int
+	for q := 0; q < 100; q++ {
+		word := embs[q].Word
+		got, err := x.SearchInternal(word, 10)
+		assert.NoError(t, err)
+		want, _ := brute.SearchInternal(word, 10)
+		found := make(map[string]bool)
+		for _, n := range got {
+			found[n.Word] = true
+		}
+		for _, n := range want {
+			if found[n.Word] {
+				hits++
+			}
+		}
+	}
+	assert.Greater(t, float64(hits)/1000, 0.9)
+}
+
+func TestAdd(t *testing.T) {
+	embs := clustered(200, 4)
+	x, err := New(embs[:100], DefaultOptions())
+	assert.NoError(t, err)
+	assert.NoError(t, x.Add(embs[100:]...))
+	assert.Equal(t, 200, x.Len())
+
+	res, err := x.SearchVector(embs[150].Vector, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "w150", res[0].Word)
+
+	assert.Error(t, x.Add(embs[0]))
+	_, err = x.SearchInternal("unknown", 1)
+	assert.Error(t, err)
+	_, err = x.SearchVector([]float64{1}, 1)
+	assert.Error(t, err)
+
+	_, err = New(embs, Options{Lists: 201, NProbe: 1})
+	assert.Error(t, err)
+}
+
+type counter struct {
+	model.BaseHook
+	progress []model.Progress
+}
+
+func (c *counter) AfterIter(p model.Progress) {
+	c.progress = append(c.progress, p)
+}
+
+func TestHooks(t *testing.T) {
+	c := &counter{}
+	opts := DefaultOptions()
+	opts.Iter = 3
+	opts.Goroutines = 2
+	opts.Hooks = model.Hooks{c}
+	_, err := New(clustered(100, 4), opts)
+	assert.NoError(t, err)
+	assert.Len(t, c.progress, 4)
+	for i, p := range c.progress {
+		assert.Equal(t, i+1, p.Iter)
+		assert.Equal(t, p.Total, p.Trained)
+	}
+	assert.Equal(t, 100, c.progress[3].Total)
+}
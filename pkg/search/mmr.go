@@ -0,0 +1,104 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"math"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/embedding/embutil"
+	"github.com/ynqa/wego/pkg/search/searchutil"
+)
+
+// mmrCandidates is the ratio of the candidates to k which MMR re-ranks,
+// since the words far from the query are never selected.
+const mmrCandidates = 10
+
+// MMR returns k words by maximal marginal relevance (Carbonell and Goldstein, 1998), which selects
+// the word maximizing lambda*sim(query, word) - (1-lambda)*max(sim(word, selected)) one by one
+// out of the nearest candidates. lambda=1 is the same as Search, and the lower gives the more diverse
+// words rather than the inflections of the same word. Similarity of the neighbors is to the query.
+func (s *Searcher) MMR(query []float64, k int, lambda float64, ignoreWord ...string) (Neighbors, error) {
+	if lambda < 0 || lambda > 1 {
+		return nil, errors.Errorf("lambda must be in [0, 1], got %v", lambda)
+	}
+
+	ignoreWords := make(map[string]struct{}, len(ignoreWord))
+	for _, word := range ignoreWord {
+		ignoreWords[word] = struct{}{}
+	}
+
+	norm := embutil.Norm(query)
+	idx := make([]int, 0, len(s.Items))
+	sims := make([]float64, len(s.Items))
+	for i, item := range s.Items {
+		if _, ok := ignoreWords[item.Word]; ok {
+			continue
+		}
+		sims[i] = searchutil.Cosine(query, item.Vector, norm, item.Norm)
+		idx = append(idx, i)
+	}
+	sort.SliceStable(idx, func(i, j int) bool {
+		return sims[idx[i]] > sims[idx[j]]
+	})
+	if len(idx) > k*mmrCandidates {
+		idx = idx[:k*mmrCandidates]
+	}
+	if k > len(idx) {
+		k = len(idx)
+	}
+
+	// redundancy is the max similarity of the candidate to the selected words.
+	redundancy := make([]float64, len(idx))
+	for i := range redundancy {
+		redundancy[i] = math.Inf(-1)
+	}
+	taken := make([]bool, len(idx))
+	neighbors := make(Neighbors, k)
+	for n := 0; n < k; n++ {
+		best, max := -1, math.Inf(-1)
+		for i, c := range idx {
+			if taken[i] {
+				continue
+			}
+			score := sims[c]
+			if n > 0 {
+				score = lambda*sims[c] - (1-lambda)*redundancy[i]
+			}
+			if score > max {
+				best, max = i, score
+			}
+		}
+		taken[best] = true
+		selected := s.Items[idx[best]]
+		neighbors[n] = Neighbor{
+			Word:       selected.Word,
+			Rank:       uint(n) + 1,
+			Similarity: sims[idx[best]],
+		}
+		for i, c := range idx {
+			if taken[i] {
+				continue
+			}
+			item := s.Items[c]
+			if sim := searchutil.Cosine(selected.Vector, item.Vector, selected.Norm, item.Norm); sim > redundancy[i] {
+				redundancy[i] = sim
+			}
+		}
+	}
+	return s.annotate(neighbors), nil
+}
@@ -0,0 +1,64 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/embutil"
+)
+
+func TestMMR(t *testing.T) {
+	vecs := map[string][]float64{
+		"run":     {1, 0},
+		"runs":    {0.99, 0.14},
+		"running": {0.98, 0.2},
+		"jog":     {0.8, -0.6},
+	}
+	var items embedding.Embeddings
+	for _, w := range []string{"run", "runs", "running", "jog"} {
+		items = append(items, embedding.Embedding{
+			Word:   w,
+			Dim:    2,
+			Vector: vecs[w],
+			Norm:   embutil.Norm(vecs[w]),
+		})
+	}
+	s, err := New(items...)
+	assert.NoError(t, err)
+
+	// lambda=1 is the same as Search.
+	neighbors, err := s.MMR([]float64{1, 0}, 2, 1, "run")
+	assert.NoError(t, err)
+	want, err := s.SearchInternal("run", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, want, neighbors)
+
+	neighbors, err = s.MMR([]float64{1, 0}, 2, 0.5, "run")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"runs", "jog"}, []string{neighbors[0].Word, neighbors[1].Word})
+	assert.InDelta(t, 0.8, neighbors[1].Similarity, 1e-9)
+	assert.Equal(t, uint(2), neighbors[1].Rank)
+
+	neighbors, err = s.MMR([]float64{1, 0}, 10, 0.5)
+	assert.NoError(t, err)
+	assert.Len(t, neighbors, 4)
+
+	_, err = s.MMR([]float64{1, 0}, 2, 1.5)
+	assert.Error(t, err)
+}
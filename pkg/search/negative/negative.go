@@ -0,0 +1,116 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package negative
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/search"
+)
+
+type Format = string
+
+const (
+	// TSV is the lines of `word<TAB>negative<TAB>similarity`.
+	TSV Format = "tsv"
+	// JSONL is the lines of {"word": word, "negatives": [{"word": negative, "rank": rank, "similarity": similarity}]}.
+	JSONL Format = "jsonl"
+)
+
+var (
+	defaultFormat = TSV
+	defaultK      = 10
+	defaultMax    = 0.7
+	defaultMin    = 0.4
+)
+
+// Options is for the hard negatives within the band of the similarity.
+type Options struct {
+	Format Format
+	K      int
+	Max    float64
+	Min    float64
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Format: defaultFormat,
+		K:      defaultK,
+		Max:    defaultMax,
+		Min:    defaultMin,
+	}
+}
+
+// negative is the neighbor in the record without the columns of search, e.g. position and count.
+type negative struct {
+	Word       string  `json:"word"`
+	Rank       uint    `json:"rank"`
+	Similarity float64 `json:"similarity"`
+}
+
+type record struct {
+	Word      string     `json:"word"`
+	Negatives []negative `json:"negatives"`
+}
+
+// Write writes the hard negatives of the words, which are at most K nearest words whose similarity is in [Min, Max]
+// from the hardest. The unknown words are skipped and returned.
+func Write(w io.Writer, s *search.Searcher, words []string, opts Options) ([]string, error) {
+	switch opts.Format {
+	case TSV, JSONL:
+	default:
+		return nil, errors.Errorf("invalid format: %s not in %s|%s", opts.Format, TSV, JSONL)
+	}
+	if opts.K <= 0 {
+		return nil, errors.Errorf("k must be positive, got %d", opts.K)
+	} else if opts.Min > opts.Max {
+		return nil, errors.Errorf("min-sim %v must be <= max-sim %v", opts.Min, opts.Max)
+	}
+
+	var skipped []string
+	buf := bufio.NewWriter(w)
+	enc := json.NewEncoder(buf)
+	for _, word := range words {
+		negatives, err := s.SearchBand(word, opts.K, opts.Min, opts.Max)
+		if err != nil {
+			skipped = append(skipped, word)
+			continue
+		}
+		if opts.Format == JSONL {
+			rec := record{Word: word, Negatives: make([]negative, len(negatives))}
+			for i, n := range negatives {
+				rec.Negatives[i] = negative{Word: n.Word, Rank: n.Rank, Similarity: n.Similarity}
+			}
+			if err := enc.Encode(rec); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		for _, n := range negatives {
+			buf.WriteString(word)
+			buf.WriteByte('\t')
+			buf.WriteString(n.Word)
+			buf.WriteByte('\t')
+			buf.WriteString(strconv.FormatFloat(n.Similarity, 'f', 6, 64))
+			buf.WriteByte('\n')
+		}
+	}
+	return skipped, buf.Flush()
+}
@@ -0,0 +1,72 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package negative
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/embutil"
+	"github.com/ynqa/wego/pkg/search"
+)
+
+func newSearcher(t *testing.T) *search.Searcher {
+	embs := []embedding.Embedding{
+		{Word: "shoes", Dim: 2, Vector: []float64{1, 0}},
+		{Word: "sneakers", Dim: 2, Vector: []float64{0.99, 0.1}},
+		{Word: "socks", Dim: 2, Vector: []float64{0.6, 0.8}},
+		{Word: "hats", Dim: 2, Vector: []float64{0.45, 0.9}},
+		{Word: "kitchen", Dim: 2, Vector: []float64{0, 1}},
+	}
+	for i := range embs {
+		embs[i].Norm = embutil.Norm(embs[i].Vector)
+	}
+	s, err := search.New(embs...)
+	assert.NoError(t, err)
+	return s
+}
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	skipped, err := Write(&buf, newSearcher(t), []string{"shoes", "unknown"}, DefaultOptions())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"unknown"}, skipped)
+	assert.Equal(t, "shoes\tsocks\t0.600000\nshoes\thats\t0.447214\n", buf.String())
+
+	buf.Reset()
+	opts := DefaultOptions()
+	opts.Format = JSONL
+	opts.K = 1
+	_, err = Write(&buf, newSearcher(t), []string{"shoes", "sneakers"}, opts)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.Equal(t, `{"word":"shoes","negatives":[{"word":"socks","rank":1,"similarity":0.6}]}`, lines[0])
+}
+
+func TestWriteInvalid(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Min = 0.8
+	_, err := Write(&bytes.Buffer{}, newSearcher(t), []string{"shoes"}, opts)
+	assert.Error(t, err)
+	opts = DefaultOptions()
+	opts.Format = "csv"
+	_, err = Write(&bytes.Buffer{}, newSearcher(t), []string{"shoes"}, opts)
+	assert.Error(t, err)
+}
@@ -0,0 +1,96 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/embedding/embutil"
+	"github.com/ynqa/wego/pkg/search/searchutil"
+)
+
+// Sample draws k distinct words without replacement with the probability proportional to
+// exp(similarity/temperature) on the query vector. The lower temperature gets closer to Search,
+// and the higher one gives the more diverse words.
+func (s *Searcher) Sample(query []float64, k int, temperature float64, rng *rand.Rand, ignoreWord ...string) (Neighbors, error) {
+	if temperature <= 0 {
+		return nil, errors.Errorf("temperature must be positive: %v", temperature)
+	}
+
+	ignoreWords := make(map[string]struct{}, len(ignoreWord))
+	for _, word := range ignoreWord {
+		ignoreWords[word] = struct{}{}
+	}
+
+	norm := embutil.Norm(query)
+	idx := make([]int, 0, len(s.Items))
+	sims := make([]float64, 0, len(s.Items))
+	max := math.Inf(-1)
+	for i, item := range s.Items {
+		if _, ok := ignoreWords[item.Word]; ok {
+			continue
+		}
+		sim := searchutil.Cosine(query, item.Vector, norm, item.Norm)
+		idx = append(idx, i)
+		sims = append(sims, sim)
+		if sim > max {
+			max = sim
+		}
+	}
+
+	// subtract the max similarity to avoid the overflow of exp.
+	weights := make([]float64, len(sims))
+	var sum float64
+	for i, sim := range sims {
+		weights[i] = math.Exp((sim - max) / temperature)
+		sum += weights[i]
+	}
+
+	if k > len(weights) {
+		k = len(weights)
+	}
+	neighbors := make(Neighbors, k)
+	taken := make([]bool, len(weights))
+	for n := 0; n < k; n++ {
+		i, r := -1, rng.Float64()*sum
+		for j, weight := range weights {
+			if weight > 0 && r < weight {
+				i = j
+				break
+			}
+			r -= weight
+		}
+		// fall back to the most similar word when the weights underflow or the rounding error remains.
+		if i < 0 {
+			for j := range weights {
+				if !taken[j] && (i < 0 || sims[j] > sims[i]) {
+					i = j
+				}
+			}
+		}
+		neighbors[n] = Neighbor{
+			Word:       s.Items[idx[i]].Word,
+			Rank:       uint(n) + 1,
+			Similarity: sims[i],
+		}
+		sum -= weights[i]
+		weights[i] = 0
+		taken[i] = true
+	}
+	return neighbors, nil
+}
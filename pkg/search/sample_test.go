@@ -0,0 +1,69 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/embutil"
+)
+
+func TestSample(t *testing.T) {
+	vecs := map[string][]float64{
+		"apple":     {1, 0},
+		"banana":    {0.8, 0.6},
+		"chocolate": {0, 1},
+		"dragon":    {-1, 0},
+	}
+	var items embedding.Embeddings
+	for _, w := range []string{"apple", "banana", "chocolate", "dragon"} {
+		items = append(items, embedding.Embedding{
+			Word:   w,
+			Dim:    2,
+			Vector: vecs[w],
+			Norm:   embutil.Norm(vecs[w]),
+		})
+	}
+	s, err := New(items...)
+	assert.NoError(t, err)
+	rng := rand.New(rand.NewSource(0))
+
+	// low temperature behaves like argmax.
+	neighbors, err := s.Sample([]float64{1, 0}, 2, 0.001, rng, "apple")
+	assert.NoError(t, err)
+	assert.Equal(t, "banana", neighbors[0].Word)
+	assert.Equal(t, "chocolate", neighbors[1].Word)
+	assert.Equal(t, uint(2), neighbors[1].Rank)
+
+	// high temperature samples every word.
+	counts := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		neighbors, err := s.Sample([]float64{1, 0}, 1, 100, rng)
+		assert.NoError(t, err)
+		counts[neighbors[0].Word]++
+	}
+	assert.Len(t, counts, 4)
+
+	neighbors, err = s.Sample([]float64{1, 0}, 10, 1, rng)
+	assert.NoError(t, err)
+	assert.Len(t, neighbors, 4)
+
+	_, err = s.Sample([]float64{1, 0}, 1, 0, rng)
+	assert.Error(t, err)
+}
@@ -15,8 +15,14 @@
 package search
 
 import (
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/olekukonko/tablewriter"
 	"github.com/pkg/errors"
@@ -24,47 +30,286 @@ import (
 	"github.com/ynqa/wego/pkg/embedding"
 	"github.com/ynqa/wego/pkg/embedding/embutil"
 	"github.com/ynqa/wego/pkg/search/searchutil"
+	"github.com/ynqa/wego/pkg/vector"
+)
+
+// ErrWordNotFound is the error of the word out of the vocabulary, which the errors of the lookups wrap
+// with the word, e.g. errors.Is(err, ErrWordNotFound) to fall back to the other words.
+var ErrWordNotFound = errors.New("word is not found")
+
+// Format is the output format of the neighbors.
+type Format = string
+
+const (
+	Table Format = "table"
+	JSON  Format = "json"
+	CSV   Format = "csv"
+	TSV   Format = "tsv"
 )
 
 // Neighbor stores the word with cosine similarity value on the target.
+// Position is the line of the word in the vectors from 1, which is the frequency rank for the vectors saved by
+// --order freq, and Count is the frequency of the word in Searcher.Counts. Vector is set by Searcher.WithVector.
 type Neighbor struct {
-	Word       string
-	Rank       uint
-	Similarity float64
+	Word       string    `json:"word"`
+	Rank       uint      `json:"rank"`
+	Similarity float64   `json:"similarity"`
+	Position   uint      `json:"position,omitempty"`
+	Count      float64   `json:"count,omitempty"`
+	Vector     []float64 `json:"vector,omitempty"`
 }
 
 type Neighbors []Neighbor
 
-func (neighbors Neighbors) Describe() {
-	table := make([][]string, len(neighbors))
+// selected is Neighbor in JSON of WriteColumns, whose fields out of DefaultColumns are set only by the columns,
+// so that the selected ones are written even if they are zero.
+type selected struct {
+	Word       string     `json:"word"`
+	Rank       uint       `json:"rank"`
+	Similarity float64    `json:"similarity"`
+	Position   *uint      `json:"position,omitempty"`
+	Count      *float64   `json:"count,omitempty"`
+	Vector     *[]float64 `json:"vector,omitempty"`
+}
+
+// Column is the column of the neighbors in the table, csv and tsv.
+type Column = string
+
+const (
+	RankColumn       Column = "rank"
+	WordColumn       Column = "word"
+	SimilarityColumn Column = "similarity"
+	PositionColumn   Column = "position"
+	CountColumn      Column = "count"
+	VectorColumn     Column = "vector"
+)
+
+// DefaultColumns are the columns of Write.
+var DefaultColumns = []Column{RankColumn, WordColumn, SimilarityColumn}
+
+func (n Neighbor) cell(col Column) (string, error) {
+	switch col {
+	case RankColumn:
+		return fmt.Sprintf("%d", n.Rank), nil
+	case WordColumn:
+		return n.Word, nil
+	case SimilarityColumn:
+		return fmt.Sprintf("%f", n.Similarity), nil
+	case PositionColumn:
+		return fmt.Sprintf("%d", n.Position), nil
+	case CountColumn:
+		return strconv.FormatFloat(n.Count, 'f', -1, 64), nil
+	case VectorColumn:
+		vals := make([]string, len(n.Vector))
+		for i, v := range n.Vector {
+			vals[i] = strconv.FormatFloat(v, 'f', 6, 64)
+		}
+		return strings.Join(vals, " "), nil
+	default:
+		return "", errors.Errorf("invalid column: %s not in %s|%s|%s|%s|%s|%s",
+			col, RankColumn, WordColumn, SimilarityColumn, PositionColumn, CountColumn, VectorColumn)
+	}
+}
+
+func (neighbors Neighbors) rows(cols []Column) ([][]string, error) {
+	rows := make([][]string, len(neighbors))
 	for i, n := range neighbors {
-		table[i] = []string{
-			fmt.Sprintf("%d", n.Rank),
-			n.Word,
-			fmt.Sprintf("%f", n.Similarity),
+		rows[i] = make([]string, len(cols))
+		for j, col := range cols {
+			cell, err := n.cell(col)
+			if err != nil {
+				return nil, err
+			}
+			rows[i][j] = cell
+		}
+	}
+	return rows, nil
+}
+
+func (neighbors Neighbors) Describe() {
+	neighbors.Write(os.Stdout, Table)
+}
+
+// Write writes the neighbors in format, e.g. JSON or CSV for the scripts rather than the table for humans.
+func (neighbors Neighbors) Write(w io.Writer, format Format) error {
+	return neighbors.WriteColumns(w, format, DefaultColumns)
+}
+
+// WriteColumns is Write with the columns of the table, csv and tsv, e.g. to filter the rare words by CountColumn.
+// JSON has the fields of DefaultColumns and the others in cols.
+func (neighbors Neighbors) WriteColumns(w io.Writer, format Format, cols []Column) error {
+	if format != JSON && len(cols) == 0 {
+		return errors.New("no columns to write")
+	}
+	rows, err := neighbors.rows(cols)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case Table:
+		writer := tablewriter.NewWriter(w)
+		writer.SetHeader(cols)
+		writer.SetBorder(false)
+		writer.AppendBulk(rows)
+		writer.Render()
+		return nil
+	case JSON:
+		res := make([]selected, len(neighbors))
+		for i := range neighbors {
+			n := &neighbors[i]
+			res[i] = selected{Word: n.Word, Rank: n.Rank, Similarity: n.Similarity}
+			for _, col := range cols {
+				switch col {
+				case PositionColumn:
+					res[i].Position = &n.Position
+				case CountColumn:
+					res[i].Count = &n.Count
+				case VectorColumn:
+					res[i].Vector = &n.Vector
+				}
+			}
+		}
+		return json.NewEncoder(w).Encode(res)
+	case CSV, TSV:
+		writer := csv.NewWriter(w)
+		if format == TSV {
+			writer.Comma = '\t'
 		}
+		writer.Write(cols)
+		writer.WriteAll(rows)
+		return writer.Error()
+	default:
+		return errors.Errorf("invalid format: %s not in %s|%s|%s|%s", format, Table, JSON, CSV, TSV)
 	}
+}
 
-	writer := tablewriter.NewWriter(os.Stdout)
-	writer.SetHeader([]string{"Rank", "Word", "Similarity"})
-	writer.SetBorder(false)
-	writer.AppendBulk(table)
-	writer.Render()
+// Interface is the retrieval of the neighbors which Searcher implements. The applications depend on it
+// instead of *Searcher to test their logic with searchtest.Fake, without loading the vectors.
+type Interface interface {
+	SearchInternal(word string, k int) (Neighbors, error)
+	Analogy(a, b, c string, k int) (Neighbors, error)
+	Similarity(a, b string) (float64, error)
 }
 
+var _ Interface = (*Searcher)(nil)
+
 type Searcher struct {
 	Items embedding.Embeddings
+	// Counts are the frequencies of the words for Neighbor.Count, e.g. loaded from the lines of 'word count'.
+	Counts map[string]float64
+	// WithVector sets Neighbor.Vector of the results.
+	WithVector bool
+
+	// index is the position of the words in Items of the size indexed.
+	index   map[string]int
+	indexed int
 }
 
 func New(embs ...embedding.Embedding) (*Searcher, error) {
 	if err := embedding.Embeddings(embs).Validate(); err != nil {
 		return nil, err
 	}
+	index := make(map[string]int, len(embs))
+	for i, emb := range embs {
+		if _, ok := index[emb.Word]; !ok {
+			index[emb.Word] = i
+		}
+	}
 	return &Searcher{
-		Items: embs,
+		Items:   embs,
+		index:   index,
+		indexed: len(embs),
 	}, nil
 }
 
+// position looks up the word by the index, or scans Items if they are resized after New.
+func (s *Searcher) position(word string) (int, bool) {
+	if s.index == nil || len(s.Items) != s.indexed {
+		for i, item := range s.Items {
+			if item.Word == word {
+				return i, true
+			}
+		}
+		return 0, false
+	}
+	i, ok := s.index[word]
+	if !ok || s.Items[i].Word != word {
+		return 0, false
+	}
+	return i, true
+}
+
+func (s *Searcher) find(word string) (embedding.Embedding, bool) {
+	i, ok := s.position(word)
+	if !ok {
+		return embedding.Embedding{}, false
+	}
+	return s.Items[i], true
+}
+
+// Annotate sets Position, Count and Vector of the neighbors by the words, e.g. for the results of the other indices.
+// The results of Searcher are annotated already.
+func (s *Searcher) Annotate(neighbors Neighbors) {
+	for i := range neighbors {
+		n := &neighbors[i]
+		pos, ok := s.position(n.Word)
+		if !ok {
+			continue
+		}
+		n.Position = uint(pos) + 1
+		n.Count = s.Counts[n.Word]
+		if s.WithVector {
+			n.Vector = s.Items[pos].Vector
+		}
+	}
+}
+
+func (s *Searcher) annotate(neighbors Neighbors) Neighbors {
+	s.Annotate(neighbors)
+	return neighbors
+}
+
+// Lookup returns the item of the word.
+func (s *Searcher) Lookup(word string) (embedding.Embedding, bool) {
+	return s.find(word)
+}
+
+// SearchAmong ranks only the candidates by cosine similarity to the word, e.g. to match the query
+// against the vocabulary of the categories. It looks up the candidates without scanning all words,
+// and the unknown candidates are skipped.
+func (s *Searcher) SearchAmong(word string, candidates []string, k int) (Neighbors, error) {
+	q, ok := s.find(word)
+	if !ok {
+		return nil, errors.Wrap(ErrWordNotFound, word)
+	}
+	seen := make(map[string]struct{}, len(candidates))
+	var neighbors Neighbors
+	for _, c := range candidates {
+		if _, ok := seen[c]; ok || c == word {
+			continue
+		}
+		seen[c] = struct{}{}
+		item, ok := s.find(c)
+		if !ok {
+			continue
+		}
+		neighbors = append(neighbors, Neighbor{
+			Word:       item.Word,
+			Similarity: searchutil.Cosine(q.Vector, item.Vector, q.Norm, item.Norm),
+		})
+	}
+	sort.SliceStable(neighbors, func(i, j int) bool {
+		return neighbors[i].Similarity > neighbors[j].Similarity
+	})
+	if len(neighbors) > k {
+		neighbors = neighbors[:k]
+	}
+	for i := range neighbors {
+		neighbors[i].Rank = uint(i) + 1
+	}
+	return s.annotate(neighbors), nil
+}
+
 func (s *Searcher) SearchInternal(word string, k int) (Neighbors, error) {
 	var q embedding.Embedding
 	for _, item := range s.Items {
@@ -74,7 +319,7 @@ func (s *Searcher) SearchInternal(word string, k int) (Neighbors, error) {
 		}
 	}
 	if q.Word == "" {
-		return nil, errors.Errorf("%s is not found in searcher", word)
+		return nil, errors.Wrap(ErrWordNotFound, word)
 	}
 
 	neighbors, err := s.Search(q, k, word)
@@ -84,6 +329,107 @@ func (s *Searcher) SearchInternal(word string, k int) (Neighbors, error) {
 	return neighbors, nil
 }
 
+// Vector returns the vector of the word, or the mean of the word vectors for the phrase of the words
+// separated by spaces, skipping the unknown words in the phrase.
+func (s *Searcher) Vector(phrase string) ([]float64, error) {
+	if item, ok := s.Items.Find(phrase); ok {
+		return item.Vector, nil
+	}
+	var vec []float64
+	var cnt int
+	for _, word := range strings.Fields(phrase) {
+		item, ok := s.Items.Find(word)
+		if !ok {
+			continue
+		}
+		if vec == nil {
+			vec = make([]float64, item.Dim)
+		}
+		vector.Add(vec, item.Vector)
+		cnt++
+	}
+	if cnt == 0 {
+		return nil, errors.Wrap(ErrWordNotFound, phrase)
+	}
+	vector.Scale(vec, 1/float64(cnt))
+	return vec, nil
+}
+
+// Similarity returns the cosine similarity between a and b, which may be the phrases as Vector.
+func (s *Searcher) Similarity(a, b string) (float64, error) {
+	va, err := s.Vector(a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := s.Vector(b)
+	if err != nil {
+		return 0, err
+	}
+	return searchutil.Cosine(va, vb, embutil.Norm(va), embutil.Norm(vb)), nil
+}
+
+// Analogy returns k nearest words of b - a + c by the unit vectors (a is to b as c is to ?), excluding a, b and c.
+func (s *Searcher) Analogy(a, b, c string, k int) (Neighbors, error) {
+	var query []float64
+	for _, w := range []struct {
+		word string
+		sign float64
+	}{{a, -1}, {b, 1}, {c, 1}} {
+		item, ok := s.find(w.word)
+		if !ok {
+			return nil, errors.Wrap(ErrWordNotFound, w.word)
+		}
+		if query == nil {
+			query = make([]float64, item.Dim)
+		}
+		if item.Norm == 0 {
+			continue
+		}
+		for i, v := range item.Vector {
+			query[i] += w.sign * v / item.Norm
+		}
+	}
+	return s.Search(embedding.Embedding{
+		Vector: query,
+		Norm:   embutil.Norm(query),
+	}, k, a, b, c)
+}
+
+// DoesntMatch ranks the words from the one which least matches the others, by cosine similarity to the mean of
+// the unit vectors of the words. The unknown words are skipped, and at least two words must be known.
+func (s *Searcher) DoesntMatch(words ...string) (Neighbors, error) {
+	var items embedding.Embeddings
+	for _, word := range words {
+		if item, ok := s.Items.Find(word); ok && item.Norm > 0 {
+			items = append(items, item)
+		}
+	}
+	if len(items) < 2 {
+		return nil, errors.Errorf("at least 2 known words are required, got %d in %v", len(items), words)
+	}
+	mean := make([]float64, items[0].Dim)
+	for _, item := range items {
+		for i, v := range item.Vector {
+			mean[i] += v / item.Norm / float64(len(items))
+		}
+	}
+	norm := embutil.Norm(mean)
+	neighbors := make(Neighbors, len(items))
+	for i, item := range items {
+		neighbors[i] = Neighbor{
+			Word:       item.Word,
+			Similarity: searchutil.Cosine(mean, item.Vector, norm, item.Norm),
+		}
+	}
+	sort.SliceStable(neighbors, func(i, j int) bool {
+		return neighbors[i].Similarity < neighbors[j].Similarity
+	})
+	for i := range neighbors {
+		neighbors[i].Rank = uint(i) + 1
+	}
+	return s.annotate(neighbors), nil
+}
+
 func (s *Searcher) SearchVector(query []float64, k int) (Neighbors, error) {
 	return s.Search(embedding.Embedding{
 		Vector: query,
@@ -92,6 +438,15 @@ func (s *Searcher) SearchVector(query []float64, k int) (Neighbors, error) {
 }
 
 func (s *Searcher) Search(query embedding.Embedding, k int, ignoreWord ...string) (Neighbors, error) {
+	if k < 0 {
+		return nil, errors.Errorf("k must be >= 0, got %d", k)
+	} else if k > len(s.Items) {
+		// there are no more neighbors than the words.
+		k = len(s.Items)
+	}
+	if k == 0 {
+		return Neighbors{}, nil
+	}
 	neighbors := make(Neighbors, k)
 
 	// Map to quickly check if a word is to be ignored.
@@ -129,8 +484,39 @@ func (s *Searcher) Search(query embedding.Embedding, k int, ignoreWord ...string
 	for i := 0; i < len(neighbors); i++ {
 		if neighbors[i].Word == "" {
 			k = i
+			break
 		}
 	}
 
-	return neighbors[:k], nil
+	return s.annotate(neighbors[:k]), nil
+}
+
+// SearchBand returns at most k nearest words whose similarity to the word is in [min, max], from the most similar,
+// e.g. the hard negatives which are near but not identical to the word.
+func (s *Searcher) SearchBand(word string, k int, min, max float64) (Neighbors, error) {
+	q, ok := s.find(word)
+	if !ok {
+		return nil, errors.Wrap(ErrWordNotFound, word)
+	}
+	var neighbors Neighbors
+	for _, item := range s.Items {
+		if item.Word == word {
+			continue
+		}
+		sim := searchutil.Cosine(q.Vector, item.Vector, q.Norm, item.Norm)
+		if sim < min || sim > max {
+			continue
+		}
+		neighbors = append(neighbors, Neighbor{Word: item.Word, Similarity: sim})
+	}
+	sort.SliceStable(neighbors, func(i, j int) bool {
+		return neighbors[i].Similarity > neighbors[j].Similarity
+	})
+	if len(neighbors) > k {
+		neighbors = neighbors[:k]
+	}
+	for i := range neighbors {
+		neighbors[i].Rank = uint(i) + 1
+	}
+	return s.annotate(neighbors), nil
 }
@@ -15,9 +15,11 @@
 package search
 
 import (
+	"bytes"
 	"reflect"
 	"testing"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/ynqa/wego/pkg/embedding"
@@ -73,6 +75,7 @@ func TestSearchInternal(t *testing.T) {
 					Word:       "banana",
 					Rank:       1,
 					Similarity: 1.,
+					Position:   2,
 				},
 			},
 		},
@@ -136,6 +139,7 @@ func TestSearchVector(t *testing.T) {
 					Word:       "dragon",
 					Rank:       1,
 					Similarity: 1.,
+					Position:   4,
 				},
 			},
 		},
@@ -149,3 +153,199 @@ func TestSearchVector(t *testing.T) {
 		})
 	}
 }
+
+func TestNeighborsWrite(t *testing.T) {
+	neighbors := Neighbors{
+		{Word: "b", Rank: 1, Similarity: 0.5},
+		{Word: "c,d", Rank: 2, Similarity: 0.25},
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, neighbors.Write(&buf, JSON))
+	assert.JSONEq(t, `[{"word":"b","rank":1,"similarity":0.5},{"word":"c,d","rank":2,"similarity":0.25}]`, buf.String())
+
+	buf.Reset()
+	assert.NoError(t, Neighbors(nil).Write(&buf, JSON))
+	assert.Equal(t, "[]\n", buf.String())
+
+	buf.Reset()
+	assert.NoError(t, neighbors.Write(&buf, CSV))
+	assert.Equal(t, "rank,word,similarity\n1,b,0.500000\n2,\"c,d\",0.250000\n", buf.String())
+
+	buf.Reset()
+	assert.NoError(t, neighbors.Write(&buf, TSV))
+	assert.Equal(t, "rank\tword\tsimilarity\n1\tb\t0.500000\n2\tc,d\t0.250000\n", buf.String())
+
+	assert.Error(t, neighbors.Write(&buf, "xml"))
+}
+
+func TestSimilarity(t *testing.T) {
+	searcher, err := New(
+		embedding.Embedding{Word: "a", Dim: 2, Vector: []float64{1, 0}, Norm: 1},
+		embedding.Embedding{Word: "b", Dim: 2, Vector: []float64{0, 1}, Norm: 1},
+		embedding.Embedding{Word: "c", Dim: 2, Vector: []float64{1, 1}, Norm: embutil.Norm([]float64{1, 1})},
+	)
+	assert.NoError(t, err)
+
+	sim, err := searcher.Similarity("a", "b")
+	assert.NoError(t, err)
+	assert.InDelta(t, 0, sim, 1e-9)
+
+	// the phrase is the mean of the words, skipping the unknown ones.
+	vec, err := searcher.Vector("a b x")
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{0.5, 0.5}, vec)
+	sim, err = searcher.Similarity("a b", "c")
+	assert.NoError(t, err)
+	assert.InDelta(t, 1, sim, 1e-9)
+
+	_, err = searcher.Similarity("a", "x y")
+	assert.Error(t, err)
+}
+
+func TestDoesntMatch(t *testing.T) {
+	embs := []embedding.Embedding{
+		{Word: "a", Dim: 2, Vector: []float64{1, 0.1}},
+		{Word: "b", Dim: 2, Vector: []float64{2, 0}},
+		{Word: "c", Dim: 2, Vector: []float64{0.9, 0.2}},
+		{Word: "d", Dim: 2, Vector: []float64{0, 1}},
+	}
+	for i := range embs {
+		embs[i].Norm = embutil.Norm(embs[i].Vector)
+	}
+	searcher, err := New(embs...)
+	assert.NoError(t, err)
+
+	neighbors, err := searcher.DoesntMatch("a", "b", "x", "d", "c")
+	assert.NoError(t, err)
+	assert.Len(t, neighbors, 4)
+	assert.Equal(t, "d", neighbors[0].Word)
+	assert.Equal(t, uint(1), neighbors[0].Rank)
+	for i := 1; i < len(neighbors); i++ {
+		assert.True(t, neighbors[i-1].Similarity <= neighbors[i].Similarity)
+	}
+
+	_, err = searcher.DoesntMatch("a", "x")
+	assert.Error(t, err)
+}
+
+func TestSearchAmong(t *testing.T) {
+	embs := []embedding.Embedding{
+		{Word: "shoes", Dim: 2, Vector: []float64{1, 0.1}},
+		{Word: "footwear", Dim: 2, Vector: []float64{0.9, 0.2}},
+		{Word: "sneakers", Dim: 2, Vector: []float64{1, 0}},
+		{Word: "kitchen", Dim: 2, Vector: []float64{0, 1}},
+		{Word: "garden", Dim: 2, Vector: []float64{0.3, 1}},
+	}
+	for i := range embs {
+		embs[i].Norm = embutil.Norm(embs[i].Vector)
+	}
+	searcher, err := New(embs...)
+	assert.NoError(t, err)
+
+	neighbors, err := searcher.SearchAmong("shoes", []string{"kitchen", "footwear", "garden", "unknown", "shoes", "garden"}, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"footwear", "garden"}, []string{neighbors[0].Word, neighbors[1].Word})
+	assert.Equal(t, uint(2), neighbors[1].Rank)
+
+	neighbors, err = searcher.SearchAmong("shoes", []string{"kitchen"}, 10)
+	assert.NoError(t, err)
+	assert.Len(t, neighbors, 1)
+
+	// the items appended after New are found by scanning.
+	searcher.Items = append(searcher.Items, embedding.Embedding{Word: "boots", Dim: 2, Vector: []float64{1, 0.05}, Norm: 1})
+	neighbors, err = searcher.SearchAmong("boots", []string{"kitchen", "sneakers"}, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "sneakers", neighbors[0].Word)
+
+	_, err = searcher.SearchAmong("unknown", []string{"kitchen"}, 1)
+	assert.Error(t, err)
+}
+
+func TestSearchBand(t *testing.T) {
+	embs := []embedding.Embedding{
+		{Word: "shoes", Dim: 2, Vector: []float64{1, 0.1}},
+		{Word: "footwear", Dim: 2, Vector: []float64{0.9, 0.2}},
+		{Word: "sneakers", Dim: 2, Vector: []float64{1, 0}},
+		{Word: "kitchen", Dim: 2, Vector: []float64{0, 1}},
+		{Word: "garden", Dim: 2, Vector: []float64{0.3, 1}},
+	}
+	for i := range embs {
+		embs[i].Norm = embutil.Norm(embs[i].Vector)
+	}
+	searcher, err := New(embs...)
+	assert.NoError(t, err)
+
+	neighbors, err := searcher.SearchBand("shoes", 10, 0.3, 0.994)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"footwear", "garden"}, []string{neighbors[0].Word, neighbors[1].Word})
+	assert.Equal(t, uint(2), neighbors[1].Rank)
+
+	neighbors, err = searcher.SearchBand("shoes", 1, 0, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "sneakers", neighbors[0].Word)
+
+	_, err = searcher.SearchBand("unknown", 1, 0, 1)
+	assert.Error(t, err)
+}
+
+func TestErrWordNotFound(t *testing.T) {
+	s, err := New(embedding.Embedding{Word: "apple", Dim: 1, Vector: []float64{1}, Norm: 1})
+	assert.NoError(t, err)
+
+	_, err = s.SearchInternal("banana", 1)
+	assert.True(t, errors.Is(err, ErrWordNotFound))
+	assert.Contains(t, err.Error(), "banana")
+}
+
+func TestWriteColumns(t *testing.T) {
+	s, err := New(
+		embedding.Embedding{Word: "apple", Dim: 2, Vector: []float64{1, 0}, Norm: 1},
+		embedding.Embedding{Word: "banana", Dim: 2, Vector: []float64{1, 1}, Norm: embutil.Norm([]float64{1, 1})},
+	)
+	assert.NoError(t, err)
+	s.Counts = map[string]float64{"banana": 42}
+	s.WithVector = true
+
+	neighbors, err := s.SearchInternal("apple", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), neighbors[0].Position)
+	assert.Equal(t, 42., neighbors[0].Count)
+	assert.Equal(t, []float64{1, 1}, neighbors[0].Vector)
+
+	var buf bytes.Buffer
+	assert.NoError(t, neighbors.WriteColumns(&buf, CSV, []Column{WordColumn, PositionColumn, CountColumn, VectorColumn}))
+	assert.Equal(t, "word,position,count,vector\nbanana,2,42,1.000000 1.000000\n", buf.String())
+	assert.Error(t, neighbors.WriteColumns(&buf, CSV, []Column{"freq"}))
+
+	buf.Reset()
+	assert.NoError(t, neighbors.Write(&buf, JSON))
+	assert.NotContains(t, buf.String(), "position")
+	assert.NotContains(t, buf.String(), "vector")
+	buf.Reset()
+	assert.NoError(t, neighbors.WriteColumns(&buf, JSON, []Column{WordColumn, PositionColumn, CountColumn}))
+	assert.Contains(t, buf.String(), `"position":2,"count":42}`)
+
+	// the selected columns of zero are written, e.g. the count of the word out of Counts.
+	neighbors[0].Count = 0
+	buf.Reset()
+	assert.NoError(t, neighbors.WriteColumns(&buf, JSON, []Column{WordColumn, CountColumn}))
+	assert.Contains(t, buf.String(), `"count":0}`)
+	assert.NotContains(t, buf.String(), "position")
+}
+
+func TestSearchK(t *testing.T) {
+	s, err := New(
+		embedding.Embedding{Word: "apple", Dim: 2, Vector: []float64{1, 0}, Norm: 1},
+		embedding.Embedding{Word: "banana", Dim: 2, Vector: []float64{1, 1}, Norm: embutil.Norm([]float64{1, 1})},
+	)
+	assert.NoError(t, err)
+	// k over the words doesn't allocate more than them.
+	neighbors, err := s.SearchVector([]float64{1, 0}, 1<<40)
+	assert.NoError(t, err)
+	assert.Len(t, neighbors, 2)
+	neighbors, err = s.SearchVector([]float64{1, 0}, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, neighbors)
+	_, err = s.SearchVector([]float64{1, 0}, -1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,106 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package searchtest provides the fakes of search.Interface for the tests of the applications.
+package searchtest
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/embutil"
+	"github.com/ynqa/wego/pkg/search"
+)
+
+// Fake is search.Interface of the canned results. The results are truncated to k and ranked in the given order,
+// and the unknown queries return search.ErrWordNotFound.
+type Fake struct {
+	// Neighbors are the results of SearchInternal by the word.
+	Neighbors map[string][]string
+	// Analogies are the results of Analogy by {a, b, c}.
+	Analogies map[[3]string][]string
+	// Similarities are the results of Similarity by {a, b} in either order.
+	Similarities map[[2]string]float64
+
+	// Calls are the queries in the order received, e.g. "SearchInternal apple".
+	Calls []string
+}
+
+var _ search.Interface = (*Fake)(nil)
+
+func (f *Fake) SearchInternal(word string, k int) (search.Neighbors, error) {
+	f.Calls = append(f.Calls, "SearchInternal "+word)
+	words, ok := f.Neighbors[word]
+	if !ok {
+		return nil, errors.Wrap(search.ErrWordNotFound, word)
+	}
+	return neighbors(words, k), nil
+}
+
+func (f *Fake) Analogy(a, b, c string, k int) (search.Neighbors, error) {
+	f.Calls = append(f.Calls, "Analogy "+a+" "+b+" "+c)
+	words, ok := f.Analogies[[3]string{a, b, c}]
+	if !ok {
+		return nil, errors.Wrapf(search.ErrWordNotFound, "%s %s %s", a, b, c)
+	}
+	return neighbors(words, k), nil
+}
+
+func (f *Fake) Similarity(a, b string) (float64, error) {
+	f.Calls = append(f.Calls, "Similarity "+a+" "+b)
+	if sim, ok := f.Similarities[[2]string{a, b}]; ok {
+		return sim, nil
+	}
+	if sim, ok := f.Similarities[[2]string{b, a}]; ok {
+		return sim, nil
+	}
+	return 0, errors.Wrapf(search.ErrWordNotFound, "%s %s", a, b)
+}
+
+// neighbors ranks the words with the similarities of 1, 1-1/n, ..., so the order is kept by sorting.
+func neighbors(words []string, k int) search.Neighbors {
+	if len(words) > k {
+		words = words[:k]
+	}
+	res := make(search.Neighbors, len(words))
+	for i, word := range words {
+		res[i] = search.Neighbor{
+			Word:       word,
+			Rank:       uint(i) + 1,
+			Similarity: 1 - float64(i)/float64(len(words)),
+		}
+	}
+	return res
+}
+
+// New returns the real search.Searcher of the vectors in memory, in the order of the words for the ties.
+func New(vecs map[string][]float64) (*search.Searcher, error) {
+	words := make([]string, 0, len(vecs))
+	for word := range vecs {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+	embs := make(embedding.Embeddings, len(words))
+	for i, word := range words {
+		embs[i] = embedding.Embedding{
+			Word:   word,
+			Dim:    len(vecs[word]),
+			Vector: vecs[word],
+			Norm:   embutil.Norm(vecs[word]),
+		}
+	}
+	return search.New(embs...)
+}
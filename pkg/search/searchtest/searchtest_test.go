@@ -0,0 +1,71 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package searchtest
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/search"
+)
+
+func TestFake(t *testing.T) {
+	f := &Fake{
+		Neighbors:    map[string][]string{"apple": {"banana", "cherry", "grape"}},
+		Analogies:    map[[3]string][]string{{"man", "king", "woman"}: {"queen"}},
+		Similarities: map[[2]string]float64{{"apple", "banana"}: 0.5},
+	}
+
+	res, err := f.SearchInternal("apple", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, search.Neighbors{
+		{Word: "banana", Rank: 1, Similarity: 1},
+		{Word: "cherry", Rank: 2, Similarity: 0.5},
+	}, res)
+
+	res, err = f.Analogy("man", "king", "woman", 5)
+	assert.NoError(t, err)
+	assert.Equal(t, "queen", res[0].Word)
+
+	sim, err := f.Similarity("banana", "apple")
+	assert.NoError(t, err)
+	assert.Equal(t, 0.5, sim)
+
+	_, err = f.SearchInternal("tokyo", 2)
+	assert.True(t, errors.Is(err, search.ErrWordNotFound))
+	assert.Equal(t, []string{
+		"SearchInternal apple",
+		"Analogy man king woman",
+		"Similarity banana apple",
+		"SearchInternal tokyo",
+	}, f.Calls)
+}
+
+func TestNew(t *testing.T) {
+	s, err := New(map[string][]float64{
+		"man":   {1, 0, 0},
+		"king":  {1, 1, 0},
+		"woman": {0, 0, 1},
+		"queen": {0, 1, 1},
+		"apple": {-1, -1, -1},
+	})
+	assert.NoError(t, err)
+
+	res, err := s.Analogy("man", "king", "woman", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "queen", res[0].Word)
+}
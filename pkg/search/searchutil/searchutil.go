@@ -14,13 +14,59 @@
 
 package searchutil
 
+import (
+	"math"
+
+	"github.com/ynqa/wego/pkg/vector"
+)
+
+// Cosine returns the cosine similarity by the norms n1 and n2 of v1 and v2, as vector.CosineNorms.
 func Cosine(v1, v2 []float64, n1, n2 float64) float64 {
-	if n1 == 0 || n2 == 0 {
-		return 0
+	return vector.CosineNorms(v1, v2, n1, n2)
+}
+
+// Nearest returns the k nearest rows to the row q by the dot product, i.e. by the cosine for the unit rows.
+func Nearest(rows [][]float64, q, k int) []int {
+	ids, sims := make([]int, k), make([]float64, k)
+	for n := range ids {
+		ids[n], sims[n] = -1, math.Inf(-1)
+	}
+	for j, row := range rows {
+		if j == q {
+			continue
+		}
+		var sim float64
+		for i, v := range row {
+			sim += rows[q][i] * v
+		}
+		if sim <= sims[k-1] {
+			continue
+		}
+		// insert into the sorted top k.
+		n := k - 1
+		for ; n > 0 && sims[n-1] < sim; n-- {
+			ids[n], sims[n] = ids[n-1], sims[n-1]
+		}
+		ids[n], sims[n] = j, sim
+	}
+	return ids
+}
+
+// Jaccard returns the Jaccard index of the sets of ids.
+func Jaccard(a, b []int) float64 {
+	set := make(map[int]bool, len(a))
+	for _, id := range a {
+		set[id] = true
+	}
+	var inter int
+	for _, id := range b {
+		if set[id] {
+			inter++
+		}
 	}
-	var dot float64
-	for i := range v1 {
-		dot += v1[i] * v2[i]
+	union := len(set) + len(b) - inter
+	if union == 0 {
+		return 1
 	}
-	return dot / n1 / n2
+	return float64(inter) / float64(union)
 }
@@ -43,3 +43,19 @@ func TestCosine(t *testing.T) {
 		})
 	}
 }
+
+func TestNearest(t *testing.T) {
+	rows := [][]float64{
+		{1, 0},
+		{0.8, 0.6},
+		{0, 1},
+		{-1, 0},
+	}
+	assert.Equal(t, []int{1, 2}, Nearest(rows, 0, 2))
+	assert.Equal(t, []int{1, 0, 3}, Nearest(rows, 2, 3))
+}
+
+func TestJaccard(t *testing.T) {
+	assert.Equal(t, 0.5, Jaccard([]int{1, 2, 3}, []int{2, 3, 4}))
+	assert.Equal(t, 1., Jaccard(nil, nil))
+}
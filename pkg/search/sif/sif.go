@@ -0,0 +1,232 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sif
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/embutil"
+	"github.com/ynqa/wego/pkg/search/searchutil"
+)
+
+var (
+	defaultAlpha      = 1e-3
+	defaultComponents = 1
+	defaultToLower    = false
+)
+
+// Options is for the smooth inverse frequency (SIF) by Arora et al. (2017),
+// "A Simple but Tough-to-Beat Baseline for Sentence Embeddings".
+type Options struct {
+	Alpha      float64
+	Components int
+	ToLower    bool
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Alpha:      defaultAlpha,
+		Components: defaultComponents,
+		ToLower:    defaultToLower,
+	}
+}
+
+// Encoder embeds the sentence into the weighted average of the word vectors
+// with the common components removed.
+type Encoder struct {
+	opts    Options
+	words   map[string][]float64
+	weights map[string]float64
+	dim     int
+	comps   [][]float64
+}
+
+// New builds the encoder from embs and the counts of the words, e.g. `word count` lines
+// read by lrscale.LoadWeights. When counts is nil, the frequency is estimated by Zipf's law
+// on the order of embs, which holds for the vectors sorted by frequency.
+// The components are estimated from the weighted word vectors until Fit is called.
+func New(embs embedding.Embeddings, counts map[string]float64, opts Options) (*Encoder, error) {
+	if embs.Empty() {
+		return nil, errors.New("embeddings are empty")
+	}
+	if err := embs.Validate(); err != nil {
+		return nil, err
+	}
+	if opts.Alpha <= 0 {
+		return nil, errors.Errorf("alpha must be positive, got %f", opts.Alpha)
+	}
+	if opts.Components < 0 || opts.Components >= embs[0].Dim {
+		return nil, errors.Errorf("components must be in [0, %d), got %d", embs[0].Dim, opts.Components)
+	}
+	if counts == nil {
+		counts = make(map[string]float64, len(embs))
+		for i, emb := range embs {
+			counts[emb.Word] = 1 / float64(i+1)
+		}
+	}
+	var total float64
+	for _, c := range counts {
+		total += c
+	}
+
+	e := &Encoder{
+		opts:    opts,
+		words:   make(map[string][]float64, len(embs)),
+		weights: make(map[string]float64, len(embs)),
+		dim:     embs[0].Dim,
+	}
+	rows := make([][]float64, 0, len(embs))
+	for _, emb := range embs {
+		var p float64
+		if total > 0 {
+			p = counts[emb.Word] / total
+		}
+		w := opts.Alpha / (opts.Alpha + p)
+		e.words[emb.Word] = emb.Vector
+		e.weights[emb.Word] = w
+		row := make([]float64, e.dim)
+		for i, v := range emb.Vector {
+			row[i] = w * v
+		}
+		rows = append(rows, row)
+	}
+	if err := e.fit(rows); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Fit estimates the components from the reference sentences, e.g. the corpus of the product,
+// as the paper does over the sentences to be compared.
+func (e *Encoder) Fit(sentences []string) error {
+	var rows [][]float64
+	for _, s := range sentences {
+		if vec, ok := e.average(s); ok {
+			rows = append(rows, vec)
+		}
+	}
+	if len(rows) == 0 {
+		return errors.New("no sentences with known words to fit")
+	}
+	return e.fit(rows)
+}
+
+func (e *Encoder) fit(rows [][]float64) error {
+	e.comps = nil
+	if e.opts.Components > 0 {
+		comps, err := embutil.Principal(rows, e.opts.Components)
+		if err != nil {
+			return err
+		}
+		e.comps = comps
+	}
+	return nil
+}
+
+func (e *Encoder) average(sentence string) ([]float64, bool) {
+	if e.opts.ToLower {
+		sentence = strings.ToLower(sentence)
+	}
+	vec := make([]float64, e.dim)
+	var cnt int
+	for _, word := range strings.Fields(sentence) {
+		v, ok := e.words[word]
+		if !ok {
+			continue
+		}
+		w := e.weights[word]
+		for i := range vec {
+			vec[i] += w * v[i]
+		}
+		cnt++
+	}
+	if cnt == 0 {
+		return nil, false
+	}
+	for i := range vec {
+		vec[i] /= float64(cnt)
+	}
+	return vec, true
+}
+
+// Encode returns the sentence vector, tokenized by spaces and skipping the unknown words.
+func (e *Encoder) Encode(sentence string) ([]float64, error) {
+	vec, ok := e.average(sentence)
+	if !ok {
+		return nil, errors.Errorf("no known words in %q", sentence)
+	}
+	for _, comp := range e.comps {
+		var dot float64
+		for i, v := range vec {
+			dot += v * comp[i]
+		}
+		for i := range vec {
+			vec[i] -= dot * comp[i]
+		}
+	}
+	return vec, nil
+}
+
+// Similarity returns the cosine similarity between the sentence vectors of a and b.
+func (e *Encoder) Similarity(a, b string) (float64, error) {
+	va, err := e.Encode(a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := e.Encode(b)
+	if err != nil {
+		return 0, err
+	}
+	return searchutil.Cosine(va, vb, embutil.Norm(va), embutil.Norm(vb)), nil
+}
+
+type request struct {
+	A string `json:"a"`
+	B string `json:"b"`
+}
+
+type response struct {
+	Similarity float64 `json:"similarity"`
+}
+
+// ServeHTTP takes the JSON of {"a": sentence, "b": sentence} by POST, or the query parameters a and b
+// by GET, and responds {"similarity": value}.
+func (e *Encoder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req request
+	switch r.Method {
+	case http.MethodGet:
+		req.A, req.B = r.URL.Query().Get("a"), r.URL.Query().Get("b")
+	case http.MethodPost:
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	sim, err := e.Similarity(req.A, req.B)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response{Similarity: sim})
+}
@@ -0,0 +1,95 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sif
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/embutil"
+)
+
+func emb(word string, vec ...float64) embedding.Embedding {
+	return embedding.Embedding{
+		Word:   word,
+		Dim:    len(vec),
+		Vector: vec,
+		Norm:   embutil.Norm(vec),
+	}
+}
+
+var embs = embedding.Embeddings{
+	emb("the", 1, 0, 0),
+	emb("cat", 1, 1, 0),
+	emb("dog", 1, 0.9, 0.1),
+	emb("car", 1, 0, 1),
+}
+
+func TestSimilarity(t *testing.T) {
+	e, err := New(embs, map[string]float64{"the": 100, "cat": 1, "dog": 1, "car": 1}, DefaultOptions())
+	assert.NoError(t, err)
+
+	near, err := e.Similarity("the cat", "a dog")
+	assert.NoError(t, err)
+	far, err := e.Similarity("the cat", "the car")
+	assert.NoError(t, err)
+	assert.Greater(t, near, far)
+	assert.Less(t, far, 0.5)
+
+	assert.NoError(t, e.Fit([]string{"the cat", "the dog", "the car"}))
+	vec, err := e.Encode("the cat")
+	assert.NoError(t, err)
+	assert.Len(t, vec, 3)
+
+	_, err = e.Similarity("unknown", "cat")
+	assert.Error(t, err)
+	assert.Error(t, e.Fit([]string{"unknown"}))
+
+	_, err = New(embs, nil, Options{Alpha: 0})
+	assert.Error(t, err)
+	_, err = New(embs, nil, Options{Alpha: 1, Components: 3})
+	assert.Error(t, err)
+}
+
+func TestServeHTTP(t *testing.T) {
+	e, err := New(embs, nil, DefaultOptions())
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"a":"cat","b":"dog"}`)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var res response
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&res))
+	sim, _ := e.Similarity("cat", "dog")
+	assert.InDelta(t, sim, res.Similarity, 1e-9)
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?a=cat&b=car", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?a=cat&b=unknown", nil))
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
@@ -0,0 +1,196 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package synonym
+
+import (
+	"bufio"
+	"io"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/search"
+	"github.com/ynqa/wego/pkg/util/fileutil"
+)
+
+type Format = string
+
+const (
+	// Explicit is the lines of `word => word, synonym1, synonym2`, which keeps the word itself in the expansion.
+	Explicit Format = "explicit"
+	// Equivalent is the lines of `word, synonym1, synonym2`.
+	Equivalent Format = "equivalent"
+)
+
+var (
+	defaultFormat     = Explicit
+	defaultGoroutines = runtime.NumCPU()
+	defaultMax        = 5
+	defaultMaxRank    = 0
+	defaultMinRank    = 1
+	defaultThreshold  = 0.7
+)
+
+// Options is for the synonyms file of Solr or Elasticsearch.
+type Options struct {
+	Format     Format
+	Goroutines int
+	Max        int
+	// MinRank and MaxRank are the band of the frequency ranks, i.e. the order of the word vectors from 1,
+	// of the words and their synonyms. MaxRank 0 means unlimited.
+	MaxRank   int
+	MinRank   int
+	Tags      []string
+	Threshold float64
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Format:     defaultFormat,
+		Goroutines: defaultGoroutines,
+		Max:        defaultMax,
+		MaxRank:    defaultMaxRank,
+		MinRank:    defaultMinRank,
+		Threshold:  defaultThreshold,
+	}
+}
+
+// LoadTags reads the lines of `word tag`, e.g. the most frequent part-of-speech tag of the word.
+// Empty lines and lines starting with # are skipped, and the first tag is kept for the duplicated words.
+func LoadTags(r io.Reader) (map[string]string, error) {
+	tags := make(map[string]string)
+	s := fileutil.NewScanner(r, bufio.ScanLines)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, errors.Errorf("line %d must be `word tag`, got %q", n, line)
+		}
+		if _, ok := tags[fields[0]]; !ok {
+			tags[fields[0]] = fields[1]
+		}
+	}
+	if err := s.Err(); err != nil && err != io.EOF {
+		return nil, errors.Wrapf(err, "failed to scan")
+	}
+	return tags, nil
+}
+
+// Write writes the synonyms of the words, which are the at most Max nearest words over Threshold.
+// If tags is not nil, the words are restricted to the tagged ones (of Tags if set),
+// and the synonyms have the same tag as the word. The words without synonyms are omitted.
+func Write(w io.Writer, embs embedding.Embeddings, tags map[string]string, opts Options) error {
+	switch opts.Format {
+	case Explicit, Equivalent:
+	default:
+		return errors.Errorf("invalid format: %s not in %s|%s", opts.Format, Explicit, Equivalent)
+	}
+	if opts.Max <= 0 || opts.Goroutines <= 0 {
+		return errors.Errorf("max and goroutines must be positive, got %d and %d", opts.Max, opts.Goroutines)
+	} else if opts.MinRank < 1 || opts.MaxRank < 0 {
+		return errors.Errorf("min-rank must be >= 1 and max-rank >= 0, got %d and %d", opts.MinRank, opts.MaxRank)
+	}
+	keep := make(map[string]bool, len(opts.Tags))
+	for _, tag := range opts.Tags {
+		keep[tag] = true
+	}
+
+	// the words are grouped by the tags, so the synonyms are searched in the group of the word.
+	var order []string
+	groups := make(map[string]embedding.Embeddings)
+	var words embedding.Embeddings
+	for i, emb := range embs {
+		rank := i + 1
+		if rank < opts.MinRank || (opts.MaxRank > 0 && rank > opts.MaxRank) {
+			continue
+		}
+		var tag string
+		if tags != nil {
+			var ok bool
+			if tag, ok = tags[emb.Word]; !ok || (len(keep) > 0 && !keep[tag]) {
+				continue
+			}
+		}
+		if _, ok := groups[tag]; !ok {
+			order = append(order, tag)
+		}
+		groups[tag] = append(groups[tag], emb)
+		words = append(words, emb)
+	}
+	searchers := make(map[string]*search.Searcher, len(groups))
+	for _, tag := range order {
+		s, err := search.New(groups[tag]...)
+		if err != nil {
+			return err
+		}
+		searchers[tag] = s
+	}
+
+	buf := bufio.NewWriter(w)
+	batch := make([]search.Neighbors, opts.Goroutines*64)
+	for s := 0; s < len(words); s += len(batch) {
+		e := s + len(batch)
+		if e > len(words) {
+			e = len(words)
+		}
+		wg := &sync.WaitGroup{}
+		for g := 0; g < opts.Goroutines; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				for i := s + g; i < e; i += opts.Goroutines {
+					// Search never fails for the embeddings which are already validated.
+					batch[i-s], _ = searchers[tags[words[i].Word]].Search(words[i], opts.Max, words[i].Word)
+				}
+			}(g)
+		}
+		wg.Wait()
+
+		for i := s; i < e; i++ {
+			var synonyms []string
+			for _, n := range batch[i-s] {
+				if n.Similarity >= opts.Threshold {
+					synonyms = append(synonyms, escape(n.Word))
+				}
+			}
+			if len(synonyms) == 0 {
+				continue
+			}
+			word := escape(words[i].Word)
+			if opts.Format == Explicit {
+				buf.WriteString(word + " => ")
+			}
+			buf.WriteString(strings.Join(append([]string{word}, synonyms...), ", "))
+			buf.WriteByte('\n')
+		}
+		if err := buf.Flush(); err != nil {
+			return err
+		}
+	}
+	return buf.Flush()
+}
+
+var special = strings.NewReplacer(`\`, `\\`, `,`, `\,`, `=>`, `\=>`)
+
+// escape escapes the separators of the synonyms format.
+func escape(word string) string {
+	return special.Replace(word)
+}
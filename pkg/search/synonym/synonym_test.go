@@ -0,0 +1,82 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package synonym
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/embutil"
+)
+
+func newEmbeddings() embedding.Embeddings {
+	words := []string{"the", "laptop", "notebook", "pc", "fast", "quick", "banana"}
+	vecs := [][]float64{{0.5, 0.5}, {1, 0}, {0.95, 0.05}, {0.9, 0.2}, {0.92, 0.1}, {0.9, 0.12}, {-1, 0}}
+	embs := make(embedding.Embeddings, len(words))
+	for i, vec := range vecs {
+		embs[i] = embedding.Embedding{Word: words[i], Dim: 2, Vector: vec, Norm: embutil.Norm(vec)}
+	}
+	return embs
+}
+
+func TestWrite(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Max = 2
+	opts.Threshold = 0.95
+	opts.MinRank = 2
+	opts.MaxRank = 4
+	var buf bytes.Buffer
+	assert.NoError(t, Write(&buf, newEmbeddings(), nil, opts))
+	assert.Equal(t, "laptop => laptop, notebook, pc\nnotebook => notebook, laptop, pc\npc => pc, notebook, laptop\n", buf.String())
+
+	buf.Reset()
+	opts.Format = Equivalent
+	opts.Max = 1
+	assert.NoError(t, Write(&buf, newEmbeddings(), nil, opts))
+	assert.Equal(t, "laptop, notebook\nnotebook, laptop\npc, notebook\n", buf.String())
+}
+
+func TestWriteTags(t *testing.T) {
+	tags, err := LoadTags(strings.NewReader("# pos\nlaptop NOUN\nnotebook NOUN\nfast ADJ\nquick ADJ\nquick NOUN\nthe DET\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "ADJ", tags["quick"])
+
+	opts := DefaultOptions()
+	opts.Threshold = 0.9
+	opts.Tags = []string{"NOUN", "ADJ"}
+	var buf bytes.Buffer
+	assert.NoError(t, Write(&buf, newEmbeddings(), tags, opts))
+	assert.Equal(t, "laptop => laptop, notebook\nnotebook => notebook, laptop\nfast => fast, quick\nquick => quick, fast\n", buf.String())
+
+	_, err = LoadTags(strings.NewReader("laptop\n"))
+	assert.Error(t, err)
+}
+
+func TestWriteInvalid(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Format = "solr"
+	assert.Error(t, Write(&bytes.Buffer{}, newEmbeddings(), nil, opts))
+	opts = DefaultOptions()
+	opts.MinRank = 0
+	assert.Error(t, Write(&bytes.Buffer{}, newEmbeddings(), nil, opts))
+}
+
+func TestEscape(t *testing.T) {
+	assert.Equal(t, `a\,b\=>c\\`, escape(`a,b=>c\`))
+}
@@ -0,0 +1,312 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stream
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/embutil"
+	"github.com/ynqa/wego/pkg/model"
+	"github.com/ynqa/wego/pkg/model/modelutil/vector"
+	"github.com/ynqa/wego/pkg/util/fileutil"
+)
+
+// Source yields the documents of the stream, e.g. the messages consumed from Kafka or NATS.
+type Source interface {
+	// Next blocks until the next document, and returns io.EOF at the end of the stream.
+	Next(context.Context) (string, error)
+}
+
+// Chan is the source of the documents sent to the channel, which ends when the channel is closed.
+type Chan <-chan string
+
+func (c Chan) Next(ctx context.Context) (string, error) {
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case doc, ok := <-c:
+		if !ok {
+			return "", io.EOF
+		}
+		return doc, nil
+	}
+}
+
+// Lines is the source of the lines of r, e.g. stdin piped from the consumer of the message queue.
+func Lines(r io.Reader) Source {
+	return &lines{s: fileutil.NewScanner(r, bufio.ScanLines)}
+}
+
+type lines struct {
+	s *bufio.Scanner
+}
+
+func (l *lines) Next(ctx context.Context) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if l.s.Scan() {
+		return l.s.Text(), nil
+	}
+	if err := l.s.Err(); err != nil && err != io.EOF {
+		return "", errors.Wrapf(err, "failed to scan")
+	}
+	return "", io.EOF
+}
+
+// Snapshot is the embeddings of all words seen in the stream, whose vectors are updated by the latest batch.
+type Snapshot struct {
+	// Seq starts from 1.
+	Seq int
+	// Docs is the number of documents consumed from the start of the stream.
+	Docs int
+	// Batch is the number of the new documents trained for the snapshot.
+	Batch int
+	// Shared is the number of the words of the batch in the previous snapshot, which align the batch to it.
+	Shared     int
+	Embeddings embedding.Embeddings
+	Elapsed    time.Duration
+}
+
+// Publisher delivers the snapshots, e.g. to the file or the external store which the services read.
+type Publisher interface {
+	Publish(context.Context, Snapshot) error
+}
+
+type PublisherFunc func(context.Context, Snapshot) error
+
+func (f PublisherFunc) Publish(ctx context.Context, s Snapshot) error {
+	return f(ctx, s)
+}
+
+// File replaces the file on path by the latest snapshot atomically.
+func File(path string) Publisher {
+	return PublisherFunc(func(_ context.Context, s Snapshot) error {
+		return fileutil.WriteAtomic(path, func(w io.Writer) error {
+			return embedding.Save(w, s.Embeddings)
+		})
+	})
+}
+
+var (
+	defaultEvery      = 10000
+	defaultInterval   = time.Duration(0)
+	defaultVectorType = vector.Single
+	defaultVerbose    = false
+)
+
+type Options struct {
+	// Every is the number of new documents to train and publish the snapshot.
+	Every int
+	// Interval publishes the snapshot of the new documents even if they are fewer than Every, 0 means disabled.
+	Interval   time.Duration
+	VectorType vector.Type
+	Verbose    bool
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Every:      defaultEvery,
+		Interval:   defaultInterval,
+		VectorType: defaultVectorType,
+		Verbose:    defaultVerbose,
+	}
+}
+
+// Run consumes the documents from src and, for every new documents, trains the model created by newModel
+// on them and publishes the snapshot, until the end of src or ctx is done. newModel takes the vectors of
+// the previous snapshot to warm-start the model, and the trained vectors are rotated onto the previous ones
+// by the shared words, so the training costs the new documents only and the snapshots are comparable.
+// The documents are buffered while training, and the rest of them are published at the end of src.
+func Run(ctx context.Context, src Source, newModel func(init map[string][]float64) (model.Model, error), pub Publisher, opts Options) error {
+	if opts.Every < 1 {
+		return errors.Errorf("every must be >= 1, got %d", opts.Every)
+	} else if opts.Interval < 0 {
+		return errors.Errorf("interval must be >= 0, got %v", opts.Interval)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	docs, errs := make(chan string, opts.Every), make(chan error, 1)
+	go func() {
+		defer close(docs)
+		for {
+			doc, err := src.Next(ctx)
+			if err != nil {
+				if err != io.EOF {
+					errs <- err
+				}
+				return
+			}
+			select {
+			case docs <- doc:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var tick <-chan time.Time
+	if opts.Interval > 0 {
+		ticker := time.NewTicker(opts.Interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	st := &state{vecs: make(map[string][]float64)}
+	var (
+		seq, total int
+		batch      strings.Builder
+		pending    int
+	)
+	start := time.Now()
+	publish := func() error {
+		if pending == 0 {
+			return nil
+		}
+		seq++
+		embs, err := train(ctx, batch.String(), newModel, st.vecs, opts.VectorType)
+		if err != nil {
+			return errors.Wrapf(err, "failed to train snapshot %d", seq)
+		}
+		s := Snapshot{
+			Seq:        seq,
+			Docs:       total,
+			Batch:      pending,
+			Shared:     st.update(embs),
+			Embeddings: st.embeddings(),
+			Elapsed:    time.Since(start),
+		}
+		if err := pub.Publish(ctx, s); err != nil {
+			return errors.Wrapf(err, "failed to publish snapshot %d", seq)
+		}
+		if opts.Verbose {
+			fmt.Fprintf(os.Stderr, "published snapshot %d of %d words over %d new docs %v\n", s.Seq, len(s.Embeddings), s.Batch, s.Elapsed)
+		}
+		batch.Reset()
+		pending = 0
+		return nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-tick:
+			if err := publish(); err != nil {
+				return err
+			}
+		case doc, ok := <-docs:
+			if !ok {
+				select {
+				case err := <-errs:
+					return err
+				default:
+				}
+				return publish()
+			}
+			if strings.TrimSpace(doc) == "" {
+				continue
+			}
+			batch.WriteString(doc)
+			batch.WriteByte('\n')
+			total++
+			pending++
+			if pending >= opts.Every {
+				if err := publish(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+func train(ctx context.Context, text string, newModel func(map[string][]float64) (model.Model, error), init map[string][]float64, typ vector.Type) (embedding.Embeddings, error) {
+	mod, err := newModel(init)
+	if err != nil {
+		return nil, err
+	}
+	defer model.Close(mod)
+	if err := mod.Train(ctx, strings.NewReader(text)); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := mod.Save(&buf, typ); err != nil {
+		return nil, err
+	}
+	return embedding.Load(&buf)
+}
+
+// state is the vectors of all words seen in the stream in the order of their first appearance.
+type state struct {
+	words []string
+	vecs  map[string][]float64
+}
+
+// update rotates embs onto the vectors of the shared words by Procrustes, and replaces them by embs.
+// The rotation needs as many shared words as the dimension, otherwise embs are kept as they are,
+// which are close to the previous ones by the warm start anyway. It returns the number of the shared words.
+func (st *state) update(embs embedding.Embeddings) int {
+	var x, y [][]float64
+	for _, emb := range embs {
+		if prev, ok := st.vecs[emb.Word]; ok {
+			x = append(x, emb.Vector)
+			y = append(y, prev)
+		}
+	}
+	if len(embs) > 0 && len(x) >= len(embs[0].Vector) {
+		w := embutil.Procrustes(x, y)
+		for _, emb := range embs {
+			rotated := make([]float64, len(w))
+			for i, vi := range emb.Vector {
+				for j, wij := range w[i] {
+					rotated[j] += vi * wij
+				}
+			}
+			copy(emb.Vector, rotated)
+		}
+	}
+	for _, emb := range embs {
+		if _, ok := st.vecs[emb.Word]; !ok {
+			st.words = append(st.words, emb.Word)
+		}
+		st.vecs[emb.Word] = emb.Vector
+	}
+	return len(x)
+}
+
+func (st *state) embeddings() embedding.Embeddings {
+	embs := make(embedding.Embeddings, len(st.words))
+	for i, word := range st.words {
+		vec := append([]float64(nil), st.vecs[word]...)
+		embs[i] = embedding.Embedding{
+			Word:   word,
+			Dim:    len(vec),
+			Vector: vec,
+			Norm:   embutil.Norm(vec),
+		}
+	}
+	return embs
+}
@@ -0,0 +1,109 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stream
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/model"
+	"github.com/ynqa/wego/pkg/model/word2vec"
+)
+
+func newModel(init map[string][]float64) (model.Model, error) {
+	return word2vec.New(
+		word2vec.InitVectors(init),
+		word2vec.Dim(5),
+		word2vec.Goroutines(1),
+		word2vec.Iter(1),
+		word2vec.MinCount(1),
+		word2vec.NegativeSampleSize(1),
+	)
+}
+
+func TestRun(t *testing.T) {
+	ch := make(chan string)
+	go func() {
+		defer close(ch)
+		for i := 0; i < 7; i++ {
+			ch <- "a b c d e"
+			ch <- ""
+		}
+	}()
+	var snapshots []Snapshot
+	pub := PublisherFunc(func(_ context.Context, s Snapshot) error {
+		snapshots = append(snapshots, s)
+		return nil
+	})
+	var inits []int
+	warm := func(init map[string][]float64) (model.Model, error) {
+		inits = append(inits, len(init))
+		return newModel(init)
+	}
+	opts := DefaultOptions()
+	opts.Every = 3
+	assert.NoError(t, Run(context.Background(), Chan(ch), warm, pub, opts))
+
+	assert.Len(t, snapshots, 3)
+	for i, s := range snapshots {
+		assert.Equal(t, i+1, s.Seq)
+		assert.Len(t, s.Embeddings, 5)
+	}
+	assert.Equal(t, []int{3, 6, 7}, []int{snapshots[0].Docs, snapshots[1].Docs, snapshots[2].Docs})
+	assert.Equal(t, []int{3, 3, 1}, []int{snapshots[0].Batch, snapshots[1].Batch, snapshots[2].Batch})
+	assert.Equal(t, []int{0, 5, 5}, []int{snapshots[0].Shared, snapshots[1].Shared, snapshots[2].Shared})
+	assert.Equal(t, []int{0, 5, 5}, inits)
+}
+
+func TestStateUpdate(t *testing.T) {
+	st := &state{vecs: make(map[string][]float64)}
+	assert.Equal(t, 0, st.update(embedding.Embeddings{
+		{Word: "a", Vector: []float64{1, 0}},
+		{Word: "b", Vector: []float64{0, 1}},
+	}))
+	// the batch rotated by 90 degrees is rotated back onto the previous snapshot, and c is added.
+	assert.Equal(t, 2, st.update(embedding.Embeddings{
+		{Word: "b", Vector: []float64{-1, 0}},
+		{Word: "a", Vector: []float64{0, 1}},
+		{Word: "c", Vector: []float64{0, 2}},
+	}))
+	embs := st.embeddings()
+	assert.Equal(t, []string{"a", "b", "c"}, []string{embs[0].Word, embs[1].Word, embs[2].Word})
+	for i, expected := range [][]float64{{1, 0}, {0, 1}, {2, 0}} {
+		assert.InDeltaSlice(t, expected, embs[i].Vector, 1e-9)
+	}
+	assert.InDelta(t, 2, embs[2].Norm, 1e-9)
+}
+
+func TestRunPublishError(t *testing.T) {
+	pub := PublisherFunc(func(context.Context, Snapshot) error {
+		return errors.New("unavailable")
+	})
+	opts := DefaultOptions()
+	opts.Every = 1
+	err := Run(context.Background(), Lines(strings.NewReader("a b c\n")), newModel, pub, opts)
+	assert.Error(t, err)
+}
+
+func TestRunInvalidOptions(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Every = 0
+	assert.Error(t, Run(context.Background(), Lines(strings.NewReader("")), newModel, nil, opts))
+}
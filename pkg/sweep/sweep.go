@@ -0,0 +1,198 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sweep
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type SearchType = string
+
+const (
+	Grid   SearchType = "grid"
+	Random SearchType = "random"
+)
+
+// Space maps the name of hyperparameter to the candidates of its value.
+type Space map[string][]string
+
+func (s Space) Names() []string {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Trial is a configuration of hyperparameters to train once.
+type Trial struct {
+	ID     int
+	Params map[string]string
+}
+
+// Trials enumerates all combinations of the space for Grid,
+// or draws n combinations at random for Random.
+func Trials(space Space, typ SearchType, n int, rng *rand.Rand) ([]Trial, error) {
+	names := space.Names()
+	for _, name := range names {
+		if len(space[name]) == 0 {
+			return nil, errors.Errorf("no values for %s", name)
+		}
+	}
+
+	var trials []Trial
+	switch typ {
+	case Grid:
+		var walk func(int, map[string]string)
+		walk = func(depth int, params map[string]string) {
+			if depth == len(names) {
+				trials = append(trials, Trial{
+					ID:     len(trials),
+					Params: copyParams(params),
+				})
+				return
+			}
+			for _, v := range space[names[depth]] {
+				params[names[depth]] = v
+				walk(depth+1, params)
+			}
+		}
+		walk(0, make(map[string]string))
+	case Random:
+		if n <= 0 {
+			return nil, errors.Errorf("number of trials must be positive for %s, got %d", Random, n)
+		}
+		for i := 0; i < n; i++ {
+			params := make(map[string]string, len(names))
+			for _, name := range names {
+				vs := space[name]
+				params[name] = vs[rng.Intn(len(vs))]
+			}
+			trials = append(trials, Trial{
+				ID:     i,
+				Params: params,
+			})
+		}
+	default:
+		return nil, errors.Errorf("invalid search type: %s not in %s|%s", typ, Grid, Random)
+	}
+	return trials, nil
+}
+
+func copyParams(params map[string]string) map[string]string {
+	res := make(map[string]string, len(params))
+	for k, v := range params {
+		res[k] = v
+	}
+	return res
+}
+
+// Result holds the metrics of a trial, e.g. {"spearman": 0.6}.
+type Result struct {
+	Trial   Trial
+	Metrics map[string]float64
+	Elapsed time.Duration
+	Err     error
+}
+
+// Func trains and evaluates the trial.
+type Func func(context.Context, Trial) (map[string]float64, error)
+
+// Run runs fn for the trials with at most parallel trials at the same time,
+// and returns the results in the order of trials.
+func Run(ctx context.Context, trials []Trial, parallel int, fn Func) []Result {
+	if parallel <= 0 {
+		parallel = 1
+	}
+	results := make([]Result, len(trials))
+	sem := make(chan struct{}, parallel)
+	wg := &sync.WaitGroup{}
+	for i, trial := range trials {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, trial Trial) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			start := time.Now()
+			metrics, err := fn(ctx, trial)
+			results[i] = Result{
+				Trial:   trial,
+				Metrics: metrics,
+				Elapsed: time.Since(start),
+				Err:     err,
+			}
+		}(i, trial)
+	}
+	wg.Wait()
+	return results
+}
+
+// WriteCSV writes the results with the header of trial id, params, metrics, elapsed seconds and error.
+func WriteCSV(w io.Writer, params []string, results []Result) error {
+	metricSet := make(map[string]struct{})
+	for _, res := range results {
+		for name := range res.Metrics {
+			metricSet[name] = struct{}{}
+		}
+	}
+	metrics := make([]string, 0, len(metricSet))
+	for name := range metricSet {
+		metrics = append(metrics, name)
+	}
+	sort.Strings(metrics)
+
+	writer := csv.NewWriter(w)
+	header := append([]string{"trial"}, params...)
+	header = append(header, metrics...)
+	header = append(header, "elapsed", "error")
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, res := range results {
+		record := []string{fmt.Sprintf("%d", res.Trial.ID)}
+		for _, name := range params {
+			record = append(record, res.Trial.Params[name])
+		}
+		for _, name := range metrics {
+			if v, ok := res.Metrics[name]; ok {
+				record = append(record, fmt.Sprintf("%f", v))
+			} else {
+				record = append(record, "")
+			}
+		}
+		var errMsg string
+		if res.Err != nil {
+			errMsg = res.Err.Error()
+		}
+		record = append(record, fmt.Sprintf("%f", res.Elapsed.Seconds()), errMsg)
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,61 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sweep
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrials(t *testing.T) {
+	space := Space{
+		"dim":    {"10", "20"},
+		"window": {"3", "5", "7"},
+	}
+
+	grid, err := Trials(space, Grid, 0, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 6, len(grid))
+	assert.Equal(t, map[string]string{"dim": "10", "window": "3"}, grid[0].Params)
+	assert.Equal(t, map[string]string{"dim": "20", "window": "7"}, grid[5].Params)
+
+	random, err := Trials(space, Random, 4, rand.New(rand.NewSource(0)))
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(random))
+
+	_, err = Trials(space, "invalid", 0, nil)
+	assert.Error(t, err)
+}
+
+func TestRunAndWriteCSV(t *testing.T) {
+	trials, err := Trials(Space{"dim": {"10", "20"}}, Grid, 0, nil)
+	assert.NoError(t, err)
+
+	results := Run(context.Background(), trials, 2, func(_ context.Context, trial Trial) (map[string]float64, error) {
+		return map[string]float64{"score": float64(len(trial.Params["dim"]))}, nil
+	})
+	assert.Equal(t, 2, len(results))
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteCSV(&buf, []string{"dim"}, results))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, "trial,dim,score,elapsed,error", lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], "0,10,2.000000,"))
+}
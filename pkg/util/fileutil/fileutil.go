@@ -0,0 +1,136 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fileutil
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// MaxTokenSize is the max bytes of a line or a word, e.g. text8 is a single line of 100MB.
+// It fits in int on 32-bit platforms.
+const MaxTokenSize = 1 << 30
+
+var bom = []byte{0xef, 0xbb, 0xbf}
+
+// Stdio is the path of stdin for the inputs and stdout for the outputs.
+const Stdio = "-"
+
+// stdout is the output of Stdio, which is replaced in the tests.
+var stdout = os.Stdout
+
+// NewScanner returns the scanner which skips UTF-8 BOM at the beginning of r, as written by
+// some editors on Windows, and accepts the tokens up to MaxTokenSize.
+// CRLF is handled by bufio.ScanLines and bufio.ScanWords.
+func NewScanner(r io.Reader, split bufio.SplitFunc) *bufio.Scanner {
+	br := bufio.NewReader(r)
+	if head, err := br.Peek(len(bom)); err == nil && bytes.Equal(head, bom) {
+		br.Discard(len(bom))
+	}
+	s := bufio.NewScanner(br)
+	s.Buffer(nil, MaxTokenSize)
+	s.Split(split)
+	return s
+}
+
+// LoadWords reads the words separated by space or newline. Lines starting with # are skipped.
+func LoadWords(r io.Reader) ([]string, error) {
+	var words []string
+	s := NewScanner(r, bufio.ScanLines)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, strings.Fields(line)...)
+	}
+	if err := s.Err(); err != nil && err != io.EOF {
+		return nil, errors.Wrapf(err, "failed to scan")
+	}
+	return words, nil
+}
+
+// Open opens the local file, or stdin for Stdio.
+func Open(path string) (io.ReadCloser, error) {
+	if path == Stdio {
+		return ioutil.NopCloser(os.Stdin), nil
+	}
+	return os.Open(path)
+}
+
+// Exists returns whether the local file exists, and true for Stdio.
+func Exists(path string) bool {
+	if path == Stdio {
+		return true
+	}
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// CheckOverwrite returns the error if path exists unless force is set.
+// Stdio is always overwritten.
+func CheckOverwrite(path string, force bool) error {
+	if path == Stdio {
+		return nil
+	}
+	if _, err := os.Stat(path); err == nil && !force {
+		return errors.Errorf("%s is already existed, set --force to overwrite it", path)
+	}
+	return nil
+}
+
+// WriteAtomic writes the file by fn into a temporary file in the same directory, then renames it to path.
+// So a crash during fn never leaves the truncated file on path, and the existing file is replaced at once.
+// Stdio is written directly.
+func WriteAtomic(path string, fn func(io.Writer) error) (err error) {
+	if path == Stdio {
+		return fn(stdout)
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(dir, "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+	if err := fn(f); err != nil {
+		return err
+	}
+	// TempFile creates the file with 0600.
+	if err := f.Chmod(0644); err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
@@ -0,0 +1,96 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fileutil
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func scan(s *bufio.Scanner) []string {
+	var res []string
+	for s.Scan() {
+		res = append(res, s.Text())
+	}
+	return res
+}
+
+func TestNewScanner(t *testing.T) {
+	assert.Equal(t, []string{"a b", "", "c"}, scan(NewScanner(strings.NewReader("\ufeffa b\r\n\r\nc\r\n"), bufio.ScanLines)))
+	assert.Equal(t, []string{"a", "b", "c"}, scan(NewScanner(strings.NewReader("\ufeffa b\r\nc"), bufio.ScanWords)))
+	// BOM is skipped only at the beginning.
+	assert.Equal(t, []string{"a", "\ufeffb"}, scan(NewScanner(strings.NewReader("a \ufeffb"), bufio.ScanWords)))
+
+	long := strings.Repeat("a ", 1<<17)
+	s := NewScanner(strings.NewReader(long+"\nb"), bufio.ScanLines)
+	assert.Equal(t, []string{long, "b"}, scan(s))
+	assert.NoError(t, s.Err())
+}
+
+func TestWriteAtomic(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wego-fileutil")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "sub", "vectors.txt")
+
+	assert.NoError(t, CheckOverwrite(path, false))
+	assert.NoError(t, WriteAtomic(path, func(w io.Writer) error {
+		_, err := io.WriteString(w, "a 1 2\n")
+		return err
+	}))
+	b, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "a 1 2\n", string(b))
+	assert.Error(t, CheckOverwrite(path, false))
+	assert.NoError(t, CheckOverwrite(path, true))
+
+	// the failure in the middle keeps the existing file and leaves no temporary file.
+	assert.Error(t, WriteAtomic(path, func(w io.Writer) error {
+		io.WriteString(w, "b 1")
+		return errors.New("crash")
+	}))
+	b, err = ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "a 1 2\n", string(b))
+	files, err := ioutil.ReadDir(filepath.Dir(path))
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+}
+
+func TestStdio(t *testing.T) {
+	r, w, err := os.Pipe()
+	assert.NoError(t, err)
+	defer func(s *os.File) { stdout = s }(stdout)
+	stdout = w
+
+	assert.True(t, Exists(Stdio))
+	assert.NoError(t, CheckOverwrite(Stdio, false))
+	assert.NoError(t, WriteAtomic(Stdio, func(w io.Writer) error {
+		_, err := io.WriteString(w, "a 1 2\n")
+		return err
+	}))
+	w.Close()
+	b, err := ioutil.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, "a 1 2\n", string(b))
+}
@@ -0,0 +1,87 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package parallel runs the builds of the indices by goroutines, reporting the progress
+// by model.Hook as the models do during training.
+package parallel
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/ynqa/wego/pkg/model"
+	"github.com/ynqa/wego/pkg/util/clock"
+)
+
+type Runner struct {
+	Goroutines int
+	LogBatch   int
+	Hooks      model.Hooks
+	// Verbose prints the progress into stderr not to mix with the results in stdout.
+	Verbose bool
+	// Unit is the name of what fn processes in the log, e.g. items.
+	Unit string
+}
+
+// Each calls fn for [0, n) by the goroutines, and the progress is notified as the iteration iter.
+func (r Runner) Each(iter, n int, fn func(i int)) {
+	goroutines := r.Goroutines
+	if goroutines <= 0 {
+		goroutines = 1
+	}
+	trained, observed, clk := make(chan struct{}), make(chan struct{}), clock.New()
+	go r.observe(iter, n, trained, observed, clk)
+
+	wg := &sync.WaitGroup{}
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := g; i < n; i += goroutines {
+				fn(i)
+				trained <- struct{}{}
+			}
+		}(g)
+	}
+	wg.Wait()
+	close(trained)
+	<-observed
+}
+
+func (r Runner) observe(iter, total int, trained, observed chan struct{}, clk *clock.Clock) {
+	defer close(observed)
+	var cnt int
+	progress := func() model.Progress {
+		return model.Progress{
+			Iter:    iter,
+			Trained: cnt,
+			Total:   total,
+			Elapsed: clk.AllElapsed(),
+		}
+	}
+	for range trained {
+		cnt++
+		if r.LogBatch > 0 && cnt%r.LogBatch == 0 {
+			r.Hooks.OnProgress(progress())
+			if r.Verbose {
+				fmt.Fprintf(os.Stderr, "built %d/%d %s %v\r", cnt, total, r.Unit, clk.AllElapsed())
+			}
+		}
+	}
+	r.Hooks.AfterIter(progress())
+	if r.Verbose {
+		fmt.Fprintf(os.Stderr, "built %d/%d %s %v\r\n", cnt, total, r.Unit, clk.AllElapsed())
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profile
+
+import (
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	rpprof "runtime/pprof"
+	"runtime/trace"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/model"
+)
+
+// Profiler records CPU profile and execution trace into the files while it runs.
+// It is also model.Hook to run around Train.
+type Profiler struct {
+	CPUProfile string
+	Trace      string
+
+	cpu, trace *os.File
+	err        error
+}
+
+func (p *Profiler) Start() error {
+	if p.CPUProfile != "" {
+		f, err := os.Create(p.CPUProfile)
+		if err != nil {
+			return err
+		}
+		if err := rpprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			return errors.Wrap(err, "failed to start CPU profile")
+		}
+		p.cpu = f
+	}
+	if p.Trace != "" {
+		f, err := os.Create(p.Trace)
+		if err != nil {
+			p.Stop()
+			return err
+		}
+		if err := trace.Start(f); err != nil {
+			f.Close()
+			p.Stop()
+			return errors.Wrap(err, "failed to start trace")
+		}
+		p.trace = f
+	}
+	return nil
+}
+
+func (p *Profiler) Stop() error {
+	var res error
+	if p.cpu != nil {
+		rpprof.StopCPUProfile()
+		res = p.cpu.Close()
+		p.cpu = nil
+	}
+	if p.trace != nil {
+		trace.Stop()
+		if err := p.trace.Close(); res == nil {
+			res = err
+		}
+		p.trace = nil
+	}
+	return res
+}
+
+// Err returns the first error to start or stop as model.Hook.
+func (p *Profiler) Err() error {
+	return p.err
+}
+
+func (p *Profiler) BeforeTrain(interface{}) {
+	p.setErr(p.Start())
+}
+
+func (p *Profiler) OnProgress(model.Progress) {}
+
+func (p *Profiler) AfterIter(model.Progress) {}
+
+func (p *Profiler) AfterTrain(error) {
+	p.setErr(p.Stop())
+}
+
+func (p *Profiler) setErr(err error) {
+	if p.err == nil {
+		p.err = err
+	}
+}
+
+// Serve serves net/http/pprof on addr in background until the server is closed.
+// Addr of the server is the address actually listened, e.g. for ":0".
+func Serve(addr string) (*http.Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to listen %s", addr)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	srv := &http.Server{Addr: ln.Addr().String(), Handler: mux}
+	go srv.Serve(ln)
+	return srv, nil
+}
@@ -0,0 +1,59 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profile
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/model"
+)
+
+func TestProfiler(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wego-profile")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	p := &Profiler{
+		CPUProfile: filepath.Join(dir, "cpu.prof"),
+		Trace:      filepath.Join(dir, "trace.out"),
+	}
+	var hook model.Hook = p
+	hook.BeforeTrain(nil)
+	hook.AfterTrain(nil)
+	assert.NoError(t, p.Err())
+
+	for _, path := range []string{p.CPUProfile, p.Trace} {
+		st, err := os.Stat(path)
+		assert.NoError(t, err)
+		assert.True(t, st.Size() > 0)
+	}
+}
+
+func TestServe(t *testing.T) {
+	srv, err := Serve("127.0.0.1:0")
+	assert.NoError(t, err)
+	defer srv.Close()
+
+	resp, err := http.Get("http://" + srv.Addr + "/debug/pprof/")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
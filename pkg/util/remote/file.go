@@ -0,0 +1,64 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/ynqa/wego/pkg/util/fileutil"
+)
+
+// OpenFile streams the object of path if it's the URI, otherwise opens it by fileutil.Open.
+func OpenFile(path string) (io.ReadCloser, error) {
+	if IsRemote(path) {
+		return Open(path)
+	}
+	return fileutil.Open(path)
+}
+
+// Exists returns true for the URI, which is checked when it's opened.
+func Exists(path string) bool {
+	return IsRemote(path) || fileutil.Exists(path)
+}
+
+// CheckOverwrite is fileutil.CheckOverwrite, where the URI is always overwritten.
+func CheckOverwrite(path string, force bool) error {
+	if IsRemote(path) {
+		return nil
+	}
+	return fileutil.CheckOverwrite(path, force)
+}
+
+// WriteAtomic uploads the object of path written by fn into the temporary file if it's the URI,
+// otherwise writes it by fileutil.WriteAtomic.
+func WriteAtomic(path string, fn func(io.Writer) error) error {
+	if !IsRemote(path) {
+		return fileutil.WriteAtomic(path, fn)
+	}
+	f, err := ioutil.TempFile("", "wego")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		f.Close()
+		os.Remove(f.Name())
+	}()
+	if err := fn(f); err != nil {
+		return err
+	}
+	return Upload(path, f)
+}
@@ -0,0 +1,65 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFile(t *testing.T) {
+	objects := make(map[string]string)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			b, _ := ioutil.ReadAll(r.Body)
+			objects[r.URL.Path] = string(b)
+		case http.MethodGet:
+			w.Write([]byte(objects[r.URL.Path]))
+		}
+	}))
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "remote")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, path := range []string{filepath.Join(dir, "vectors.txt"), srv.URL + "/vectors.txt"} {
+		assert.NoError(t, CheckOverwrite(path, false))
+		assert.NoError(t, WriteAtomic(path, func(w io.Writer) error {
+			_, err := io.WriteString(w, "a 1 2\n")
+			return err
+		}))
+		assert.True(t, Exists(path))
+
+		r, err := OpenFile(path)
+		assert.NoError(t, err)
+		b, err := ioutil.ReadAll(r)
+		assert.NoError(t, err)
+		assert.NoError(t, r.Close())
+		assert.Equal(t, "a 1 2\n", string(b))
+	}
+	assert.Equal(t, "a 1 2\n", objects["/vectors.txt"])
+	// the local file is kept without --force, while the remote object is always overwritten.
+	assert.Error(t, CheckOverwrite(filepath.Join(dir, "vectors.txt"), false))
+	assert.False(t, Exists(filepath.Join(dir, "missing.txt")))
+}
@@ -0,0 +1,192 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package remote reads and writes the objects of s3://, gs://, http:// and https:// URIs.
+//
+// s3:// is signed by $AWS_ACCESS_KEY_ID, $AWS_SECRET_ACCESS_KEY and $AWS_SESSION_TOKEN in $AWS_REGION,
+// and $AWS_ENDPOINT_URL points to the S3 compatible storage, e.g. MinIO.
+// gs:// is authorized by $GOOGLE_OAUTH_ACCESS_TOKEN, e.g. `gcloud auth print-access-token`.
+// The requests are anonymous without the credentials, e.g. for the public buckets.
+//
+// OpenFile, Exists, CheckOverwrite and WriteAtomic take the URIs as well as the local paths of fileutil
+// for the commands, so that the libraries, which open the local files by fileutil, don't link net/http.
+package remote
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	S3    = "s3"
+	GCS   = "gs"
+	HTTP  = "http"
+	HTTPS = "https"
+)
+
+// Client is used for the requests, which may be replaced in tests.
+var Client = http.DefaultClient
+
+// now is the time of the signatures.
+var now = time.Now
+
+// IsRemote returns whether path is the URI of the supported schemes rather than the local file.
+func IsRemote(path string) bool {
+	for _, scheme := range []string{S3, GCS, HTTP, HTTPS} {
+		if strings.HasPrefix(path, scheme+"://") {
+			return true
+		}
+	}
+	return false
+}
+
+// Open streams the object of uri.
+func Open(uri string) (io.ReadCloser, error) {
+	req, err := newRequest(http.MethodGet, uri, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	res, err := do(req)
+	if err != nil {
+		return nil, err
+	}
+	return res.Body, nil
+}
+
+// Upload writes the object of uri from f, which is read from the beginning.
+func Upload(uri string, f *os.File) error {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	req, err := newRequest(http.MethodPut, uri, f, info.Size())
+	if err != nil {
+		return err
+	}
+	res, err := do(req)
+	if err != nil {
+		return err
+	}
+	return res.Body.Close()
+}
+
+func do(req *http.Request) (*http.Response, error) {
+	res, err := Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode/100 != 2 {
+		defer res.Body.Close()
+		msg, _ := ioutil.ReadAll(io.LimitReader(res.Body, 512))
+		return nil, errors.Errorf("%s %s: %s %s", req.Method, req.URL.Redacted(), res.Status, strings.TrimSpace(string(msg)))
+	}
+	return res, nil
+}
+
+func newRequest(method, uri string, body io.Reader, size int64) (*http.Request, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case HTTP, HTTPS:
+		return httpRequest(method, u.String(), body, size)
+	case S3:
+		return s3Request(method, u.Host, strings.TrimPrefix(u.Path, "/"), body, size)
+	case GCS:
+		req, err := httpRequest(method, fmt.Sprintf("https://storage.googleapis.com/%s/%s", u.Host, escapePath(strings.TrimPrefix(u.Path, "/"))), body, size)
+		if err != nil {
+			return nil, err
+		}
+		if token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"); token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		return req, nil
+	default:
+		return nil, errors.Errorf("invalid scheme: %s not in %s|%s|%s|%s", u.Scheme, S3, GCS, HTTP, HTTPS)
+	}
+}
+
+func httpRequest(method, uri string, body io.Reader, size int64) (*http.Request, error) {
+	req, err := http.NewRequest(method, uri, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	return req, nil
+}
+
+func s3Request(method, bucket, key string, body io.Reader, size int64) (*http.Request, error) {
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+	// the custom endpoint is addressed by the path-style as it may not resolve the buckets as the subdomains.
+	uri := fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", bucket, region, escapePath(key))
+	if endpoint := os.Getenv("AWS_ENDPOINT_URL"); endpoint != "" {
+		uri = fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(endpoint, "/"), bucket, escapePath(key))
+	}
+	req, err := httpRequest(method, uri, body, size)
+	if err != nil {
+		return nil, err
+	}
+	keyID, secret := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if keyID != "" && secret != "" {
+		if token := os.Getenv("AWS_SESSION_TOKEN"); token != "" {
+			req.Header.Set("X-Amz-Security-Token", token)
+		}
+		req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+		sign(req, keyID, secret, region, "s3", now())
+	}
+	return req, nil
+}
+
+// escapePath escapes the segments of the object key, keeping the slashes.
+func escapePath(key string) string {
+	segments := strings.Split(key, "/")
+	for i, s := range segments {
+		segments[i] = uriEncode(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+// uriEncode escapes all the bytes but the unreserved characters of RFC 3986, as required by SigV4.
+func uriEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || strings.IndexByte("-_.~", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
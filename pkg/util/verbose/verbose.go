@@ -14,13 +14,32 @@
 
 package verbose
 
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Verbose prints the progress into w if the flag is set.
 type Verbose struct {
 	flag bool
+	w    io.Writer
 }
 
+// New returns the verbose which prints the progress into os.Stdout.
 func New(flag bool) *Verbose {
+	return NewWriter(flag, os.Stdout)
+}
+
+// NewWriter returns the verbose which prints the progress into w, e.g. os.Stderr
+// while the output is written to stdout.
+func NewWriter(flag bool, w io.Writer) *Verbose {
+	if w == nil {
+		w = os.Stdout
+	}
 	return &Verbose{
 		flag: flag,
+		w:    w,
 	}
 }
 
@@ -29,3 +48,8 @@ func (v *Verbose) Do(fn func()) {
 		fn()
 	}
 }
+
+// Printf prints the progress into the writer of v.
+func (v *Verbose) Printf(format string, a ...interface{}) {
+	fmt.Fprintf(v.w, format, a...)
+}
@@ -0,0 +1,152 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package vector is the arithmetic of the dense vectors shared by the packages of wego. The functions of float32
+// have the suffix 32, since the module targets Go 1.15 without generics. The vectors of the arguments have the
+// same length, and the functions don't check it.
+package vector
+
+import (
+	"math"
+)
+
+// Dot returns the dot product of v1 and v2.
+func Dot(v1, v2 []float64) float64 {
+	var res float64
+	for i, v := range v1 {
+		res += v * v2[i]
+	}
+	return res
+}
+
+// Norm returns the Euclidean norm of vec.
+func Norm(vec []float64) float64 {
+	return math.Sqrt(Dot(vec, vec))
+}
+
+// Cosine returns the cosine similarity, or 0 if either is the zero vector.
+func Cosine(v1, v2 []float64) float64 {
+	return CosineNorms(v1, v2, Norm(v1), Norm(v2))
+}
+
+// CosineNorms is Cosine by the norms n1 and n2 of v1 and v2 computed in advance, e.g. embedding.Embedding.Norm.
+func CosineNorms(v1, v2 []float64, n1, n2 float64) float64 {
+	if n1 == 0 || n2 == 0 {
+		return 0
+	}
+	return Dot(v1, v2) / n1 / n2
+}
+
+// Add adds src into dst in place.
+func Add(dst, src []float64) {
+	for i, v := range src {
+		dst[i] += v
+	}
+}
+
+// Scale multiplies vec by a in place.
+func Scale(vec []float64, a float64) {
+	for i := range vec {
+		vec[i] *= a
+	}
+}
+
+// Mean returns the new vector of the mean of vecs, or nil for no vectors.
+func Mean(vecs ...[]float64) []float64 {
+	if len(vecs) == 0 {
+		return nil
+	}
+	res := make([]float64, len(vecs[0]))
+	for _, vec := range vecs {
+		Add(res, vec)
+	}
+	Scale(res, 1/float64(len(vecs)))
+	return res
+}
+
+// Unit returns the new vector of vec scaled to the unit length, or the zero vector for the zero vector.
+func Unit(vec []float64) []float64 {
+	res := make([]float64, len(vec))
+	norm := Norm(vec)
+	if norm == 0 {
+		return res
+	}
+	for i, v := range vec {
+		res[i] = v / norm
+	}
+	return res
+}
+
+// Dot32 is Dot of float32, accumulated in float64.
+func Dot32(v1, v2 []float32) float64 {
+	var res float64
+	for i, v := range v1 {
+		res += float64(v) * float64(v2[i])
+	}
+	return res
+}
+
+// Norm32 is Norm of float32.
+func Norm32(vec []float32) float64 {
+	return math.Sqrt(Dot32(vec, vec))
+}
+
+// Cosine32 is Cosine of float32.
+func Cosine32(v1, v2 []float32) float64 {
+	n1, n2 := Norm32(v1), Norm32(v2)
+	if n1 == 0 || n2 == 0 {
+		return 0
+	}
+	return Dot32(v1, v2) / n1 / n2
+}
+
+// Add32 is Add of float32.
+func Add32(dst, src []float32) {
+	for i, v := range src {
+		dst[i] += v
+	}
+}
+
+// Scale32 is Scale of float32.
+func Scale32(vec []float32, a float32) {
+	for i := range vec {
+		vec[i] *= a
+	}
+}
+
+// Mean32 is Mean of float32.
+func Mean32(vecs ...[]float32) []float32 {
+	if len(vecs) == 0 {
+		return nil
+	}
+	res := make([]float32, len(vecs[0]))
+	for _, vec := range vecs {
+		Add32(res, vec)
+	}
+	Scale32(res, 1/float32(len(vecs)))
+	return res
+}
+
+// Unit32 is Unit of float32, computed in float64.
+func Unit32(vec []float32) []float32 {
+	res := make([]float32, len(vec))
+	norm := Norm32(vec)
+	if norm == 0 {
+		return res
+	}
+	for i, v := range vec {
+		res[i] = float32(float64(v) / norm)
+	}
+	return res
+}
@@ -0,0 +1,54 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVector(t *testing.T) {
+	v1, v2 := []float64{3, 4}, []float64{4, -3}
+	assert.Equal(t, 0., Dot(v1, v2))
+	assert.Equal(t, 5., Norm(v1))
+	assert.Equal(t, 0., Cosine(v1, v2))
+	assert.InDelta(t, 1, Cosine(v1, []float64{6, 8}), 1e-12)
+	assert.Equal(t, 0., Cosine(v1, []float64{0, 0}))
+	assert.InDelta(t, 1, CosineNorms(v1, []float64{6, 8}, 5, 10), 1e-12)
+	assert.Equal(t, 0., CosineNorms(v1, v1, 0, 5))
+	assert.Equal(t, []float64{3.5, 0.5}, Mean(v1, v2))
+	assert.Equal(t, []float64{0.6, 0.8}, Unit(v1))
+	assert.Equal(t, []float64{0, 0}, Unit([]float64{0, 0}))
+
+	dst := []float64{1, 1}
+	Add(dst, v1)
+	Scale(dst, 2)
+	assert.Equal(t, []float64{8, 10}, dst)
+}
+
+func TestVector32(t *testing.T) {
+	v1, v2 := []float32{3, 4}, []float32{4, -3}
+	assert.Equal(t, 0., Dot32(v1, v2))
+	assert.Equal(t, 5., Norm32(v1))
+	assert.Equal(t, 0., Cosine32(v1, v2))
+	assert.Equal(t, []float32{3.5, 0.5}, Mean32(v1, v2))
+	assert.InDeltaSlice(t, []float32{0.6, 0.8}, Unit32(v1), 1e-7)
+
+	dst := []float32{1, 1}
+	Add32(dst, v1)
+	Scale32(dst, 2)
+	assert.Equal(t, []float32{8, 10}, dst)
+}
@@ -15,6 +15,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -53,7 +54,7 @@ func main() {
 				word2vec.MinCount(10),
 				word2vec.Model(word2vec.SkipGram),
 				word2vec.Optimizer(word2vec.NegativeSampling),
-				word2vec.Verbose(),
+				word2vec.Verbose(true),
 				word2vec.Window(5),
 			)),
 		},
@@ -67,7 +68,7 @@ func main() {
 				word2vec.MinCount(10),
 				word2vec.Model(word2vec.SkipGram),
 				word2vec.Optimizer(word2vec.HierarchicalSoftmax),
-				word2vec.Verbose(),
+				word2vec.Verbose(true),
 				word2vec.Window(5),
 			)),
 		},
@@ -81,7 +82,7 @@ func main() {
 				word2vec.MinCount(10),
 				word2vec.Model(word2vec.Cbow),
 				word2vec.Optimizer(word2vec.NegativeSampling),
-				word2vec.Verbose(),
+				word2vec.Verbose(true),
 				word2vec.Window(5),
 			)),
 		},
@@ -95,7 +96,7 @@ func main() {
 				word2vec.MinCount(10),
 				word2vec.Model(word2vec.Cbow),
 				word2vec.Optimizer(word2vec.HierarchicalSoftmax),
-				word2vec.Verbose(),
+				word2vec.Verbose(true),
 				word2vec.Window(5),
 			)),
 		},
@@ -109,7 +110,7 @@ func main() {
 				glove.Iter(3),
 				glove.MinCount(20),
 				glove.Solver(glove.Stochastic),
-				glove.Verbose(),
+				glove.Verbose(true),
 				glove.Window(10),
 			)),
 		},
@@ -123,7 +124,7 @@ func main() {
 				glove.Iter(3),
 				glove.MinCount(20),
 				glove.Solver(glove.AdaGrad),
-				glove.Verbose(),
+				glove.Verbose(true),
 				glove.Window(10),
 			)),
 		},
@@ -136,7 +137,7 @@ func main() {
 				lexvec.Iter(1),
 				lexvec.MinCount(10),
 				lexvec.Relation(lexvec.PPMI),
-				lexvec.Verbose(),
+				lexvec.Verbose(true),
 				lexvec.Window(10),
 			)),
 		},
@@ -159,7 +160,7 @@ func e2e(mod model.Model) error {
 	if err != nil {
 		log.Fatal(err)
 	}
-	if err := mod.Train(input); err != nil {
+	if err := mod.Train(context.Background(), input); err != nil {
 		return err
 	}
 	if err := mod.Save(output, vector.Agg); err != nil {
@@ -20,38 +20,138 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
+	"github.com/ynqa/wego/cmd/benchgen"
+	"github.com/ynqa/wego/cmd/bpe"
+	"github.com/ynqa/wego/cmd/combine"
+	"github.com/ynqa/wego/cmd/convert"
+	"github.com/ynqa/wego/cmd/cooc"
+	"github.com/ynqa/wego/cmd/debias"
+	"github.com/ynqa/wego/cmd/dedup"
+	"github.com/ynqa/wego/cmd/diff"
+	"github.com/ynqa/wego/cmd/doesntmatch"
+	"github.com/ynqa/wego/cmd/eval"
+	"github.com/ynqa/wego/cmd/expand"
+	"github.com/ynqa/wego/cmd/finetune"
+	"github.com/ynqa/wego/cmd/inspect"
+	"github.com/ynqa/wego/cmd/knngraph"
+	"github.com/ynqa/wego/cmd/migrate"
+	"github.com/ynqa/wego/cmd/model/charngram"
 	"github.com/ynqa/wego/cmd/model/glove"
 	"github.com/ynqa/wego/cmd/model/lexvec"
 	"github.com/ynqa/wego/cmd/model/word2vec"
+	"github.com/ynqa/wego/cmd/negatives"
+	"github.com/ynqa/wego/cmd/ngram"
+	"github.com/ynqa/wego/cmd/push"
 	"github.com/ynqa/wego/cmd/query"
 	"github.com/ynqa/wego/cmd/query/console"
+	"github.com/ynqa/wego/cmd/reduce"
+	"github.com/ynqa/wego/cmd/sentsim"
+	"github.com/ynqa/wego/cmd/similarity"
+	"github.com/ynqa/wego/cmd/stream"
+	"github.com/ynqa/wego/cmd/sweep"
+	"github.com/ynqa/wego/cmd/synonyms"
 )
 
 func main() {
 	word2vec := word2vec.New()
 	glove := glove.New()
 	lexvec := lexvec.New()
+	charngram := charngram.New()
 	query := query.New()
 	console := console.New()
+	sweep := sweep.New()
+	benchgen := benchgen.New()
+	knngraph := knngraph.New()
+	eval := eval.New()
+	debias := debias.New()
+	reduce := reduce.New()
+	inspect := inspect.New()
+	finetune := finetune.New()
+	convert := convert.New()
+	push := push.New()
+	similarity := similarity.New()
+	doesntmatch := doesntmatch.New()
+	sentsim := sentsim.New()
+	stream := stream.New()
+	diff := diff.New()
+	expand := expand.New()
+	synonyms := synonyms.New()
+	negatives := negatives.New()
+	bpe := bpe.New()
+	ngram := ngram.New()
+	dedup := dedup.New()
+	migrate := migrate.New()
+	combine := combine.New()
+	cooc := cooc.New()
 
 	cmd := &cobra.Command{
 		Use:   "wego",
 		Short: "tools for embedding words into vector space",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return errors.Errorf("Set sub-command. One of %s|%s|%s|%s|%s",
+			return errors.Errorf("Set sub-command. One of %s|%s|%s|%s|%s|%s|%s|%s|%s|%s|%s|%s|%s|%s|%s|%s|%s|%s|%s|%s|%s|%s|%s|%s|%s|%s|%s|%s|%s|%s",
 				word2vec.Name(),
 				glove.Name(),
 				lexvec.Name(),
+				charngram.Name(),
 				query.Name(),
 				console.Name(),
+				sweep.Name(),
+				benchgen.Name(),
+				knngraph.Name(),
+				eval.Name(),
+				debias.Name(),
+				reduce.Name(),
+				inspect.Name(),
+				finetune.Name(),
+				convert.Name(),
+				push.Name(),
+				similarity.Name(),
+				doesntmatch.Name(),
+				sentsim.Name(),
+				stream.Name(),
+				diff.Name(),
+				expand.Name(),
+				synonyms.Name(),
+				negatives.Name(),
+				bpe.Name(),
+				ngram.Name(),
+				dedup.Name(),
+				migrate.Name(),
+				combine.Name(),
+				cooc.Name(),
 			)
 		},
 	}
 	cmd.AddCommand(word2vec)
 	cmd.AddCommand(glove)
 	cmd.AddCommand(lexvec)
+	cmd.AddCommand(charngram)
 	cmd.AddCommand(query)
 	cmd.AddCommand(console)
+	cmd.AddCommand(sweep)
+	cmd.AddCommand(benchgen)
+	cmd.AddCommand(knngraph)
+	cmd.AddCommand(eval)
+	cmd.AddCommand(debias)
+	cmd.AddCommand(reduce)
+	cmd.AddCommand(inspect)
+	cmd.AddCommand(finetune)
+	cmd.AddCommand(convert)
+	cmd.AddCommand(push)
+	cmd.AddCommand(similarity)
+	cmd.AddCommand(doesntmatch)
+	cmd.AddCommand(sentsim)
+	cmd.AddCommand(stream)
+	cmd.AddCommand(diff)
+	cmd.AddCommand(expand)
+	cmd.AddCommand(synonyms)
+	cmd.AddCommand(negatives)
+	cmd.AddCommand(bpe)
+	cmd.AddCommand(ngram)
+	cmd.AddCommand(dedup)
+	cmd.AddCommand(migrate)
+	cmd.AddCommand(combine)
+	cmd.AddCommand(cooc)
 
 	if err := cmd.Execute(); err != nil {
 		os.Exit(1)